@@ -34,12 +34,13 @@ const (
 
 // 验证失败响应码
 const (
-	INVALID_SIGN     RESPONSE_CODE = "invalid_sign" // 这里指事件的签名错误
-	INVALID_PARAM    RESPONSE_CODE = "invalid_param"
-	INVALID_TOKEN    RESPONSE_CODE = "invalid_token"
-	INVALID_PASSWORD RESPONSE_CODE = "invalid_password"
-	MISSING_PARAM    RESPONSE_CODE = "missing_param"
-	ALREADY_EXIST    RESPONSE_CODE = "already_exist"
+	INVALID_SIGN      RESPONSE_CODE = "invalid_sign" // 这里指事件的签名错误
+	INVALID_PARAM     RESPONSE_CODE = "invalid_param"
+	INVALID_TOKEN     RESPONSE_CODE = "invalid_token"
+	INVALID_PASSWORD  RESPONSE_CODE = "invalid_password"
+	MISSING_PARAM     RESPONSE_CODE = "missing_param"
+	ALREADY_EXIST     RESPONSE_CODE = "already_exist"
+	PAYLOAD_TOO_LARGE RESPONSE_CODE = "payload_too_large" // 请求参数超出大小上限
 )
 
 // 用户相关响应码
@@ -70,6 +71,7 @@ const (
 	UNKNOWN_DATA      RESPONSE_CODE = "unknown_data"
 	EMPTY_DATA        RESPONSE_CODE = "empty_data"
 	DATA_EXIST        RESPONSE_CODE = "data_exist"
+	DUPLICATE_RECORD  RESPONSE_CODE = "duplicate_record" // 唯一字段重复
 	TOO_MANY_REQUESTS RESPONSE_CODE = "TOO_MANY_REQUESTS"
 	LIMIT_REACHED     RESPONSE_CODE = "limit_reached"  // 最大上限
 	FORBIDDEN_CALL    RESPONSE_CODE = "forbidden_call" // 不允许的调用方式
@@ -81,6 +83,7 @@ const (
 	TASK_IN_PROGRESS   RESPONSE_CODE = "task_in_progress"
 	TASK_FAILED        RESPONSE_CODE = "task_failed"
 	TASK_TIMEOUT       RESPONSE_CODE = "task_timeout"
+	TASK_CANCELLED     RESPONSE_CODE = "task_cancelled"
 	TASK_UNKNOWN       RESPONSE_CODE = "task_unknown"
 	TASK_LIMIT_REACHED RESPONSE_CODE = "task_limit_reached" // 任务最大上限
 )
@@ -128,6 +131,7 @@ var responseCodeMessages = map[RESPONSE_CODE]string{
 	TASK_IN_PROGRESS:    "任务进行中",
 	TASK_FAILED:         "任务失败",
 	TASK_TIMEOUT:        "任务超时",
+	TASK_CANCELLED:      "任务已取消",
 	TASK_UNKNOWN:        "任务未知",
 	TASK_LIMIT_REACHED:  "任务达到最大上限",
 	SERVICE_UNAVAILABLE: "服务不可用",
@@ -135,6 +139,8 @@ var responseCodeMessages = map[RESPONSE_CODE]string{
 	CONFLICT:            "资源冲突",
 	NOT_IMPLEMENTED:     "功能未实现",
 	EMPTY_DATA:          "数据为空",
+	PAYLOAD_TOO_LARGE:   "请求参数过大",
+	DUPLICATE_RECORD:    "记录重复",
 }
 
 // MsgForResponseCode 根据响应码获取对应的默认消息文本
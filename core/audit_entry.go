@@ -0,0 +1,77 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"github.com/garrickvan/event-matrix/utils"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/utils/logx"
+)
+
+// AuditEntry 审计记录，保存更新事件前后发生变化的字段值
+type AuditEntry struct {
+	// ID 审计记录的唯一标识符
+	ID string `json:"id" gorm:"primaryKey"`
+	// EventId 触发修改的事件ID
+	EventId string `json:"eventId" gorm:"index"`
+	// EventNode 事件节点标识，由project、context、entity和event组合而成
+	EventNode string `json:"eventNode" gorm:"index"`
+	// RecordId 被修改记录的ID
+	RecordId string `json:"recordId" gorm:"index"`
+	// Before 修改前发生变化的字段值
+	Before map[string]interface{} `json:"before" gorm:"serializer:json"`
+	// After 修改后发生变化的字段值
+	After map[string]interface{} `json:"after" gorm:"serializer:json"`
+	// ChangedBy 修改者的用户ID
+	ChangedBy string `json:"changedBy" gorm:"index"`
+	// ServerId 处理事件的服务器ID
+	ServerId string `json:"serverId"`
+	// CreatedAt 记录创建时间戳
+	CreatedAt int64 `json:"createdAt" gorm:"index"`
+}
+
+// NewAuditEntryFromJson 从JSON字符串创建AuditEntry实例
+// 如果解析失败则返回空的AuditEntry对象
+func NewAuditEntryFromJson(v string) *AuditEntry {
+	var data AuditEntry
+	if err := jsonx.UnmarshalFromStr(v, &data); err != nil {
+		return &AuditEntry{}
+	}
+	return &data
+}
+
+// SaveAuditEntry 保存审计记录
+// 审计记录写入审计日志，由日志提交守护进程单独提交到日志中心
+func SaveAuditEntry(entry *AuditEntry) {
+	if entry == nil {
+		return
+	}
+	if entry.ID == "" {
+		entry.ID = utils.GenID()
+	}
+	if entry.CreatedAt == 0 {
+		entry.CreatedAt = utils.GetNowMilli()
+	}
+	jsonData, err := jsonx.MarshalToBytes(entry)
+	if err != nil {
+		logx.Error("Marshal audit entry to json failed: " + err.Error())
+		return
+	}
+	if logx.AuditLogger == nil {
+		logx.Error("Audit logger is not initialized")
+		return
+	}
+	logx.AuditLogger.Zap().Info(string(jsonData))
+}
@@ -88,6 +88,23 @@ const (
 	// AI_MODEL AI模型配置类型
 	AI_MODEL = "ai_model"
 
+	// 对象存储类型常量
+	// STORAGE_S3 S3兼容的对象存储，如AWS S3、MinIO
+	STORAGE_S3 = "s3"
+
+	// 推送通知类型常量
+	// PUSH_FCM Firebase Cloud Messaging推送
+	PUSH_FCM = "push_fcm"
+	// PUSH_WEBPUSH WebPush浏览器推送
+	PUSH_WEBPUSH = "push_webpush"
+
+	// 日志输出类型常量
+	// LOG_ELASTICSEARCH Elasticsearch日志输出
+	LOG_ELASTICSEARCH = "log_elasticsearch"
+
+	// JWT_HMAC JWT令牌HMAC签名密钥配置类型
+	JWT_HMAC = "jwt_hmac"
+
 	// CUSTOM 自定义配置类型
 	CUSTOM = "custom"
 )
@@ -142,6 +159,26 @@ func IsMQConfigType(mqType string) bool {
 	}
 }
 
+// IsStorageConfigType 判断给定的类型是否为对象存储配置类型
+func IsStorageConfigType(storageType string) bool {
+	switch storageType {
+	case STORAGE_S3:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsPushConfigType 判断给定的类型是否为推送通知配置类型
+func IsPushConfigType(pushType string) bool {
+	switch pushType {
+	case PUSH_FCM, PUSH_WEBPUSH:
+		return true
+	default:
+		return false
+	}
+}
+
 // SharedConfigure 表示系统中的共享配置，用于存储和管理各个工作节点共用的配置信息
 type SharedConfigure struct {
 	Key         string `json:"key" gorm:"primaryKey"`
@@ -15,9 +15,13 @@
 package core
 
 import (
+	"fmt"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/garrickvan/event-matrix/utils"
 	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/utils/logx"
 	"github.com/spf13/cast"
 )
 
@@ -32,6 +36,8 @@ type EntityAttribute struct {
 	Unique       bool   `json:"unique"`
 	Indexed      bool   `json:"indexed"`
 	IsSecrecy    bool   `json:"isSecrecy"` // 保密字段查询时不返回
+	MinLength    int    `json:"minLength"` // 字符类字段的最小长度（按字符计），0表示不限制
+	MaxLength    int    `json:"maxLength"` // 字符类字段的最大长度（按字符计），0表示不限制，超出时截断
 	UpdatedAt    int64  `json:"updatedAt"`
 	CreatedAt    int64  `json:"createdAt"`
 	DeletedAt    int64  `json:"deletedAt" gorm:"index"`
@@ -42,28 +48,36 @@ type EntityAttribute struct {
 type FIELD_TYPE string
 
 const (
-	ID_FIELD_TYPE        FIELD_TYPE = "id"
-	REF_FIELD_TYPE       FIELD_TYPE = "ref"
-	STRING_FIELD_TYPE    FIELD_TYPE = "string"
-	TEXT_FIELD_TYPE      FIELD_TYPE = "text"
-	INT8_FIELD_TYPE      FIELD_TYPE = "int8"
-	INT32_FIELD_TYPE     FIELD_TYPE = "int32"
-	INT64_FIELD_TYPE     FIELD_TYPE = "int64"
-	FLOAT32_FIELD_TYPE   FIELD_TYPE = "float32"
-	FLOAT64_FIELD_TYPE   FIELD_TYPE = "float64"
-	BOOLEAN_FIELD_TYPE   FIELD_TYPE = "boolean"
-	DATETIME_FIELD_TYPE  FIELD_TYPE = "datetime"
-	CONSTANT_FIELD_TYPE  FIELD_TYPE = "constant"
-	UID_FIELD_TYPE       FIELD_TYPE = "uid"
-	URL_FIELD_TYPE       FIELD_TYPE = "url"
-	EMAIL_FIELD_TYPE     FIELD_TYPE = "email"
-	PHONE_FIELD_TYPE     FIELD_TYPE = "phone"
-	CUSTOM_FIELD_TYPE    FIELD_TYPE = "custom"
-	AND_QUERY_FIELD_TYPE FIELD_TYPE = "and_query"
-	OR_QUERY_FIELD_TYPE  FIELD_TYPE = "or_query"
-	ORDER_BY_FIELD_TYPE  FIELD_TYPE = "order_by"
+	ID_FIELD_TYPE         FIELD_TYPE = "id"
+	REF_FIELD_TYPE        FIELD_TYPE = "ref"
+	STRING_FIELD_TYPE     FIELD_TYPE = "string"
+	TEXT_FIELD_TYPE       FIELD_TYPE = "text"
+	INT8_FIELD_TYPE       FIELD_TYPE = "int8"
+	INT16_FIELD_TYPE      FIELD_TYPE = "int16"
+	INT32_FIELD_TYPE      FIELD_TYPE = "int32"
+	INT64_FIELD_TYPE      FIELD_TYPE = "int64"
+	FLOAT32_FIELD_TYPE    FIELD_TYPE = "float32"
+	FLOAT64_FIELD_TYPE    FIELD_TYPE = "float64"
+	BOOLEAN_FIELD_TYPE    FIELD_TYPE = "boolean"
+	DATETIME_FIELD_TYPE   FIELD_TYPE = "datetime"
+	CONSTANT_FIELD_TYPE   FIELD_TYPE = "constant"
+	UID_FIELD_TYPE        FIELD_TYPE = "uid"
+	URL_FIELD_TYPE        FIELD_TYPE = "url"
+	EMAIL_FIELD_TYPE      FIELD_TYPE = "email"
+	PHONE_FIELD_TYPE      FIELD_TYPE = "phone"
+	CUSTOM_FIELD_TYPE     FIELD_TYPE = "custom"
+	AND_QUERY_FIELD_TYPE  FIELD_TYPE = "and_query"
+	OR_QUERY_FIELD_TYPE   FIELD_TYPE = "or_query"
+	ORDER_BY_FIELD_TYPE   FIELD_TYPE = "order_by"
+	DRY_RUN_FIELD_TYPE    FIELD_TYPE = "dry_run"    // 试运行参数，为true时只校验不落库
+	CURSOR_FIELD_TYPE     FIELD_TYPE = "cursor"     // 游标分页参数，Range 为排序字段，默认 id
+	JOIN_QUERY_FIELD_TYPE FIELD_TYPE = "join_query" // 关联查询参数，RangeValue 为关联实体和关联字段的JSON对象
 )
 
+// DEFAULT_VALUE_NOW 日期时间字段的默认值为该值时，新建数据时取当前毫秒时间戳
+const DEFAULT_VALUE_NOW = "$now"
+
+// GetDefaultVal 按字段类型转换默认值，未设置默认值时返回 nil
 func (e *EntityAttribute) GetDefaultVal() interface{} {
 	if e.DefaultValue == "" {
 		return nil
@@ -72,21 +86,49 @@ func (e *EntityAttribute) GetDefaultVal() interface{} {
 	switch FIELD_TYPE(e.FieldType) {
 	case ID_FIELD_TYPE, REF_FIELD_TYPE, STRING_FIELD_TYPE, TEXT_FIELD_TYPE, UID_FIELD_TYPE, URL_FIELD_TYPE, EMAIL_FIELD_TYPE, PHONE_FIELD_TYPE:
 		return e.DefaultValue
-	case INT8_FIELD_TYPE, INT32_FIELD_TYPE, INT64_FIELD_TYPE:
+	case INT8_FIELD_TYPE, INT16_FIELD_TYPE, INT32_FIELD_TYPE, INT64_FIELD_TYPE:
 		return cast.ToInt64(e.DefaultValue)
 	case FLOAT32_FIELD_TYPE, FLOAT64_FIELD_TYPE:
 		return cast.ToFloat64(e.DefaultValue)
 	case BOOLEAN_FIELD_TYPE:
 		return cast.ToBool(e.DefaultValue)
 	case DATETIME_FIELD_TYPE:
+		if e.DefaultValue == DEFAULT_VALUE_NOW {
+			return utils.GetNowMilli()
+		}
 		return cast.ToInt64(e.DefaultValue)
 	default:
 		return e.DefaultValue
 	}
 }
 
-func (e *EntityAttribute) FixValue(v interface{}) interface{} {
-	return FixAttributeValue(v, e.FieldType)
+// FixValue 按字段类型校正值，字符类字段会检查长度限制：
+// 不足 MinLength 返回错误，超出 MaxLength 截断并记录警告
+func (e *EntityAttribute) FixValue(v interface{}) (interface{}, error) {
+	val := FixAttributeValue(v, e.FieldType)
+	str, ok := val.(string)
+	if !ok || !IsStringFieldType(e.FieldType) {
+		return val, nil
+	}
+	size := utf8.RuneCountInString(str)
+	if e.MinLength > 0 && size < e.MinLength {
+		return nil, fmt.Errorf("属性[%s]长度不能小于%d", e.Code, e.MinLength)
+	}
+	if e.MaxLength > 0 && size > e.MaxLength {
+		logx.Warn(fmt.Sprintf("属性[%s]长度%d超过上限%d，已截断", e.Code, size, e.MaxLength))
+		return string([]rune(str)[:e.MaxLength]), nil
+	}
+	return str, nil
+}
+
+// IsStringFieldType 判断字段类型的值是否为字符串
+func IsStringFieldType(typz string) bool {
+	switch FIELD_TYPE(typz) {
+	case ID_FIELD_TYPE, REF_FIELD_TYPE, STRING_FIELD_TYPE, TEXT_FIELD_TYPE, UID_FIELD_TYPE, URL_FIELD_TYPE, EMAIL_FIELD_TYPE, PHONE_FIELD_TYPE:
+		return true
+	default:
+		return false
+	}
 }
 
 func FixAttributeValue(v interface{}, typz string) interface{} {
@@ -97,11 +139,11 @@ func FixAttributeValue(v interface{}, typz string) interface{} {
 	switch FIELD_TYPE(typz) {
 	case ID_FIELD_TYPE, REF_FIELD_TYPE, STRING_FIELD_TYPE, TEXT_FIELD_TYPE, UID_FIELD_TYPE, URL_FIELD_TYPE, EMAIL_FIELD_TYPE, PHONE_FIELD_TYPE:
 		return cast.ToString(v)
-	case INT8_FIELD_TYPE, INT32_FIELD_TYPE, INT64_FIELD_TYPE:
+	case INT8_FIELD_TYPE, INT16_FIELD_TYPE, INT32_FIELD_TYPE, INT64_FIELD_TYPE:
 		return cast.ToInt64(v)
 	case FLOAT32_FIELD_TYPE, FLOAT64_FIELD_TYPE:
 		return cast.ToFloat64(v)
-	case BOOLEAN_FIELD_TYPE:
+	case BOOLEAN_FIELD_TYPE, DRY_RUN_FIELD_TYPE:
 		return cast.ToBool(v)
 	case DATETIME_FIELD_TYPE:
 		return cast.ToInt64(v)
@@ -110,6 +152,7 @@ func FixAttributeValue(v interface{}, typz string) interface{} {
 	}
 }
 
+// FindAttrFromArray 按编码查找实体属性，忽略大小写，未找到时返回nil
 func FindAttrFromArray(code string, attrs []EntityAttribute) *EntityAttribute {
 	if attrs == nil {
 		return nil
@@ -123,6 +166,17 @@ func FindAttrFromArray(code string, attrs []EntityAttribute) *EntityAttribute {
 	return nil
 }
 
+// FindAttrsByFieldType 返回指定字段类型的全部实体属性，忽略大小写，保持原有顺序
+func FindAttrsByFieldType(fieldType string, attrs []EntityAttribute) []EntityAttribute {
+	result := []EntityAttribute{}
+	for _, attr := range attrs {
+		if strings.EqualFold(attr.FieldType, fieldType) {
+			result = append(result, attr)
+		}
+	}
+	return result
+}
+
 func NewEntityAttributeFromJson(v string) *EntityAttribute {
 	var data EntityAttribute
 	err := jsonx.UnmarshalFromBytes([]byte(v), &data)
@@ -149,6 +203,8 @@ func NewEntityAttributeFromMap(v interface{}) *EntityAttribute {
 		Unique:       cast.ToBool(data["unique"]),
 		Indexed:      cast.ToBool(data["indexed"]),
 		IsSecrecy:    cast.ToBool(data["isSecrecy"]),
+		MinLength:    cast.ToInt(data["minLength"]),
+		MaxLength:    cast.ToInt(data["maxLength"]),
 		UpdatedAt:    cast.ToInt64(data["updatedAt"]),
 		CreatedAt:    cast.ToInt64(data["createdAt"]),
 		DeletedAt:    cast.ToInt64(data["deletedAt"]),
@@ -172,6 +228,8 @@ func (e *EntityAttribute) Clone() *EntityAttribute {
 		Unique:       e.Unique,
 		Indexed:      e.Indexed,
 		IsSecrecy:    e.IsSecrecy,
+		MinLength:    e.MinLength,
+		MaxLength:    e.MaxLength,
 		UpdatedAt:    e.UpdatedAt,
 		CreatedAt:    e.CreatedAt,
 		DeletedAt:    e.DeletedAt,
@@ -0,0 +1,107 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEntityAttributeFixValueLength(t *testing.T) {
+	attr := EntityAttribute{Code: "nickname", FieldType: "string", MinLength: 2, MaxLength: 5}
+	cases := []struct {
+		in      interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{"ab", "ab", false},
+		{"abcde", "abcde", false},
+		{"abcdefg", "abcde", false},
+		{"张三李四王五", "张三李四王", false}, // 按字符截断，不破坏多字节字符
+		{"a", nil, true},
+		{"张", nil, true},
+		{123456, "12345", false},
+		{nil, nil, false},
+	}
+	for _, c := range cases {
+		got, err := attr.FixValue(c.in)
+		if (err != nil) != c.wantErr || got != c.want {
+			t.Errorf("FixValue(%v) = %v, %v; want %v, err=%v", c.in, got, err, c.want, c.wantErr)
+		}
+	}
+
+	// 未设置长度限制时不做处理
+	plain := EntityAttribute{Code: "title", FieldType: "text"}
+	if got, err := plain.FixValue(""); err != nil || got != "" {
+		t.Errorf("unexpected result without limits: %v, %v", got, err)
+	}
+	// 非字符类型忽略长度限制
+	num := EntityAttribute{Code: "age", FieldType: "int32", MinLength: 3, MaxLength: 3}
+	if got, err := num.FixValue("7"); err != nil || got != int64(7) {
+		t.Errorf("unexpected result for int field: %v, %v", got, err)
+	}
+}
+
+func TestFindAttrFromArray(t *testing.T) {
+	attrs := []EntityAttribute{
+		{Code: "id", FieldType: "id"},
+		{Code: "nickName", FieldType: "string"},
+	}
+	cases := []struct {
+		code  string
+		attrs []EntityAttribute
+		want  string
+	}{
+		{"id", attrs, "id"},
+		{"nickname", attrs, "nickName"},
+		{"NICKNAME", attrs, "nickName"},
+		{"age", attrs, ""},
+		{"id", nil, ""},
+	}
+	for _, c := range cases {
+		got := FindAttrFromArray(c.code, c.attrs)
+		if (got == nil) != (c.want == "") || (got != nil && got.Code != c.want) {
+			t.Errorf("FindAttrFromArray(%q) = %v, want %q", c.code, got, c.want)
+		}
+	}
+}
+
+func TestFindAttrsByFieldType(t *testing.T) {
+	attrs := []EntityAttribute{
+		{Code: "name", FieldType: "string"},
+		{Code: "age", FieldType: "int32"},
+		{Code: "email", FieldType: "String"},
+	}
+	cases := []struct {
+		fieldType string
+		attrs     []EntityAttribute
+		want      []string
+	}{
+		{"string", attrs, []string{"name", "email"}},
+		{"int32", attrs, []string{"age"}},
+		{"datetime", attrs, []string{}},
+		{"string", nil, []string{}},
+	}
+	for _, c := range cases {
+		got := FindAttrsByFieldType(c.fieldType, c.attrs)
+		codes := []string{}
+		for _, attr := range got {
+			codes = append(codes, attr.Code)
+		}
+		if strings.Join(codes, ",") != strings.Join(c.want, ",") {
+			t.Errorf("FindAttrsByFieldType(%q) = %v, want %v", c.fieldType, codes, c.want)
+		}
+	}
+}
@@ -25,25 +25,27 @@ import (
 // EntityEvent 表示系统中的一个事件实体
 // 它包含了事件的基本信息、执行配置、权限控制等属性
 type EntityEvent struct {
-	ID           string                 `json:"id" gorm:"primaryKey"`   // 事件唯一标识
-	EntityID     string                 `json:"entityId" gorm:"index"`  // 关联的实体ID
-	Name         string                 `json:"name"`                   // 事件名称
-	Code         string                 `json:"code" gorm:"index"`      // 事件代码，用于标识事件类型
-	ExecutorType constant.EXECUTOR_TYPE `json:"executorType"`           // 执行器类型
-	Executor     string                 `json:"executor"`               // 执行器配置
-	Delay        int                    `json:"delay"`                  // 延迟执行时间，单位秒
-	Timeout      int                    `json:"timeout"`                // 执行超时时间，单位秒
-	Params       string                 `json:"params"`                 // 事件参数，JSON格式
-	Mode         constant.EVENT_MODE    `json:"mode"`                   // 事件模式
-	Idempotent   bool                   `json:"idempotent"`             // WILLDO:幂等性
-	Logable      bool                   `json:"logable"`                // 是否启用日志
-	AuthType     constant.AUTH_TYPE     `json:"authType"`               // 认证类型
-	Description  string                 `json:"description"`            // 事件描述
-	CreatedAt    int64                  `json:"createdAt"`              // 创建时间戳
-	UpdatedAt    int64                  `json:"updatedAt"`              // 更新时间戳
-	DeletedAt    int64                  `json:"deletedAt" gorm:"index"` // 删除时间戳
-	DeletedBy    string                 `json:"deletedBy"`              // 删除操作执行者
-	Creator      string                 `json:"creator"`                // 创建者
+	ID           string                 `json:"id" gorm:"primaryKey"`                         // 事件唯一标识
+	EntityID     string                 `json:"entityId" gorm:"index"`                        // 关联的实体ID
+	Name         string                 `json:"name"`                                         // 事件名称
+	Code         string                 `json:"code" gorm:"index"`                            // 事件代码，用于标识事件类型
+	ExecutorType constant.EXECUTOR_TYPE `json:"executorType"`                                 // 执行器类型
+	Executor     string                 `json:"executor"`                                     // 执行器配置
+	Delay        int                    `json:"delay"`                                        // 延迟执行时间，单位秒
+	Timeout      int                    `json:"timeout"`                                      // 执行超时时间，单位秒，同时作为事件的有效期
+	RetryPolicy  *RetryPolicy           `json:"retryPolicy,omitempty" gorm:"serializer:json"` // 任务执行器的重试策略，为空时按立方回退无限重试
+	Params       string                 `json:"params"`                                       // 事件参数，JSON格式
+	MaxParamSize int                    `json:"maxParamSize"`                                 // 请求参数JSON的最大字节数，0表示不限制
+	Mode         constant.EVENT_MODE    `json:"mode"`                                         // 事件模式
+	Idempotent   bool                   `json:"idempotent"`                                   // 是否幂等，内置新建事件开启后需携带 idempotency_key 参数
+	Logable      bool                   `json:"logable"`                                      // 是否启用日志
+	AuthType     constant.AUTH_TYPE     `json:"authType"`                                     // 认证类型
+	Description  string                 `json:"description"`                                  // 事件描述
+	CreatedAt    int64                  `json:"createdAt"`                                    // 创建时间戳
+	UpdatedAt    int64                  `json:"updatedAt"`                                    // 更新时间戳
+	DeletedAt    int64                  `json:"deletedAt" gorm:"index"`                       // 删除时间戳
+	DeletedBy    string                 `json:"deletedBy"`                                    // 删除操作执行者
+	Creator      string                 `json:"creator"`                                      // 创建者
 }
 
 // NewEntityEventFromJson 从JSON字符串创建EntityEvent实例
@@ -74,7 +76,9 @@ func NewEntityEventFromMap(v interface{}) *EntityEvent {
 		Executor:     cast.ToString(data["executor"]),
 		Delay:        cast.ToInt(data["delay"]),
 		Timeout:      cast.ToInt(data["timeout"]),
+		RetryPolicy:  NewRetryPolicyFromValue(data["retryPolicy"]),
 		Params:       cast.ToString(data["params"]),
+		MaxParamSize: cast.ToInt(data["maxParamSize"]),
 		Mode:         constant.EVENT_MODE(cast.ToString(data["mode"])),
 		Logable:      cast.ToBool(data["logable"]),
 		AuthType:     constant.AUTH_TYPE(cast.ToUint8(data["authType"])),
@@ -102,7 +106,9 @@ func (e *EntityEvent) Clone() *EntityEvent {
 		Executor:     e.Executor,
 		Delay:        e.Delay,
 		Timeout:      e.Timeout,
+		RetryPolicy:  e.RetryPolicy.Clone(),
 		Params:       e.Params,
+		MaxParamSize: e.MaxParamSize,
 		Mode:         e.Mode,
 		Logable:      e.Logable,
 		AuthType:     e.AuthType,
@@ -115,6 +121,19 @@ func (e *EntityEvent) Clone() *EntityEvent {
 	}
 }
 
+// ParamSizeExceeded 判断请求参数是否超出大小上限，网关和工作端在处理前均应检查
+func (e *EntityEvent) ParamSizeExceeded(params string) bool {
+	return e != nil && e.MaxParamSize > 0 && len(params) > e.MaxParamSize
+}
+
+// IsEventExpired 按 Timeout 判断事件是否已过期，未配置 Timeout 时不做过期判断
+func (e *EntityEvent) IsEventExpired(event *Event) bool {
+	if e == nil || e.Timeout <= 0 {
+		return false
+	}
+	return event.IsExpired(int64(e.Timeout) * 1000)
+}
+
 // 事件参数
 // EventParam 定义事件参数的结构和验证规则
 type EventParam struct {
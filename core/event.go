@@ -20,6 +20,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/garrickvan/event-matrix/utils"
 	"github.com/garrickvan/event-matrix/utils/fastconv"
 	"github.com/garrickvan/event-matrix/utils/jsonx"
 	"github.com/spf13/cast"
@@ -127,6 +128,17 @@ func (e *Event) Clone() *Event {
 	}
 }
 
+// Renew 复制事件并生成新的ID、创建时间和签名
+// 用于周期触发、重放等重新发送同一事件的场景，避免接收方按过期或重复的事件拒绝
+// 返回参数与当前实例相同的新实例
+func (e *Event) Renew() *Event {
+	dup := e.Clone()
+	dup.ID = utils.GenID()
+	dup.CreatedAt = nowMilli()
+	dup.GenerateSign()
+	return dup
+}
+
 // Raw 获取事件的原始JSON字符串
 // 如果原始字符串为空，则尝试将当前实例序列化为JSON字符串
 // 返回事件的原始JSON字符串
@@ -174,6 +186,19 @@ func (e *Event) GenerateSign() {
 	e.Sign = hex.EncodeToString(signByte[:])
 }
 
+// nowMilli 获取当前毫秒时间戳，测试时可替换以冻结时间
+var nowMilli = utils.GetNowMilli
+
+// IsExpired 判断事件是否已过期
+// 事件创建时间距今超过 ttlMillis 毫秒即为过期，VerifySign 只校验签名，需配合本方法校验有效期
+// 返回事件是否过期的布尔值，事件为nil时视为过期
+func (e *Event) IsExpired(ttlMillis int64) bool {
+	if e == nil {
+		return true
+	}
+	return nowMilli()-e.CreatedAt > ttlMillis
+}
+
 // VerifySign 验证事件签名是否有效
 // 通过重新计算签名并与事件的Sign字段比较来验证
 // 返回签名是否有效的布尔值
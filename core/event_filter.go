@@ -0,0 +1,45 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+// EventFilter 跨实体事件订阅条件，源事件执行成功后，由目标工作者的处理方法做后续处理，
+// 例如用户删除后清理其文章。Source 字段为空时匹配任意值
+type EventFilter struct {
+	// SourceProject 源事件所属项目
+	SourceProject string `json:"sourceProject"`
+	// SourceContext 源事件所属上下文
+	SourceContext string `json:"sourceContext"`
+	// SourceEntity 源事件所属实体
+	SourceEntity string `json:"sourceEntity"`
+	// SourceEvent 源事件名称
+	SourceEvent string `json:"sourceEvent"`
+	// TargetWorkerID 处理事件的工作者ID，工作者未注册时不会分发
+	TargetWorkerID string `json:"targetWorkerId"`
+}
+
+// Match 判断事件是否满足订阅条件
+func (f *EventFilter) Match(e *Event) bool {
+	if f == nil || e == nil {
+		return false
+	}
+	return matchFilterField(f.SourceProject, e.Project) &&
+		matchFilterField(f.SourceContext, e.Context) &&
+		matchFilterField(f.SourceEntity, e.Entity) &&
+		matchFilterField(f.SourceEvent, e.Event)
+}
+
+func matchFilterField(expect, actual string) bool {
+	return expect == "" || expect == actual
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import "testing"
+
+// freezeNowMilli 冻结当前时间，测试结束后恢复
+func freezeNowMilli(t *testing.T, now int64) {
+	origin := nowMilli
+	nowMilli = func() int64 { return now }
+	t.Cleanup(func() { nowMilli = origin })
+}
+
+func TestEventIsExpired(t *testing.T) {
+	freezeNowMilli(t, 1_700_000_010_000)
+	e := &Event{CreatedAt: 1_700_000_000_000}
+
+	cases := []struct {
+		ttl     int64
+		expired bool
+	}{
+		{20_000, false},
+		{10_000, false}, // 刚好到达有效期不算过期
+		{9_999, true},
+		{0, true},
+	}
+	for _, c := range cases {
+		if got := e.IsExpired(c.ttl); got != c.expired {
+			t.Errorf("ttl %d: expected expired=%v, got %v", c.ttl, c.expired, got)
+		}
+	}
+
+	// 创建时间晚于当前时间（时钟偏差）时不过期
+	if (&Event{CreatedAt: 1_700_000_011_000}).IsExpired(0) {
+		t.Error("future event should not be expired")
+	}
+	var nilEvent *Event
+	if !nilEvent.IsExpired(20_000) {
+		t.Error("nil event should be expired")
+	}
+}
+
+func TestEntityEventIsEventExpired(t *testing.T) {
+	freezeNowMilli(t, 1_700_000_010_000)
+	e := &Event{CreatedAt: 1_700_000_000_000}
+
+	if (&EntityEvent{}).IsEventExpired(e) {
+		t.Error("event without timeout should never expire")
+	}
+	var nilEntityEvent *EntityEvent
+	if nilEntityEvent.IsEventExpired(e) {
+		t.Error("nil entity event should not expire events")
+	}
+	if (&EntityEvent{Timeout: 10}).IsEventExpired(e) {
+		t.Error("event within timeout should not expire")
+	}
+	if !(&EntityEvent{Timeout: 5}).IsEventExpired(e) {
+		t.Error("event older than timeout should expire")
+	}
+}
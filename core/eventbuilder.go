@@ -0,0 +1,89 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/garrickvan/event-matrix/utils"
+)
+
+// EventBuilder 事件构建器，避免手动构建事件时遗漏字段或忘记生成签名
+//
+// 用法:
+//
+//	event, err := core.NewEventBuilder("shop", "v1", "order", "item", "create").
+//		WithSource("web").
+//		WithParams(`{"name":"apple"}`).
+//		WithAccessToken(token).
+//		Build()
+type EventBuilder struct {
+	event Event
+}
+
+// NewEventBuilder 创建事件构建器，项目、版本、上下文、实体和事件号均为必填项
+func NewEventBuilder(project, version, context, entity, event string) *EventBuilder {
+	return &EventBuilder{event: Event{
+		Project: project,
+		Version: version,
+		Context: context,
+		Entity:  entity,
+		Event:   event,
+	}}
+}
+
+// WithSource 设置事件来源
+func (b *EventBuilder) WithSource(source string) *EventBuilder {
+	b.event.Source = source
+	return b
+}
+
+// WithParams 设置事件参数，JSON格式字符串
+func (b *EventBuilder) WithParams(params string) *EventBuilder {
+	b.event.Params = params
+	return b
+}
+
+// WithAccessToken 设置访问令牌
+func (b *EventBuilder) WithAccessToken(token string) *EventBuilder {
+	b.event.AccessToken = token
+	return b
+}
+
+// Build 校验必填字段，生成事件ID、创建时间和签名，返回新的事件实例
+// 每次调用都会生成新的ID，构建器可重复使用
+func (b *EventBuilder) Build() (*Event, error) {
+	required := []struct {
+		name  string
+		value string
+	}{
+		{"project", b.event.Project},
+		{"version", b.event.Version},
+		{"context", b.event.Context},
+		{"entity", b.event.Entity},
+		{"event", b.event.Event},
+	}
+	for _, field := range required {
+		if strings.TrimSpace(field.value) == "" {
+			return nil, fmt.Errorf("事件字段[%s]不能为空", field.name)
+		}
+	}
+	e := b.event.Clone()
+	e.ID = utils.GenID()
+	e.CreatedAt = utils.GetNowMilli()
+	e.GenerateSign()
+	return e, nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import "testing"
+
+func TestEventBuilder(t *testing.T) {
+	builder := NewEventBuilder("shop", "v1", "order", "item", "create").
+		WithSource("web").
+		WithParams(`{"name":"apple"}`).
+		WithAccessToken("token")
+	e, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.ID == "" || e.CreatedAt == 0 {
+		t.Errorf("应生成ID和创建时间: %+v", e)
+	}
+	if e.Source != "web" || e.Params != `{"name":"apple"}` || e.AccessToken != "token" {
+		t.Errorf("可选字段未设置: %+v", e)
+	}
+	if !e.VerifySign() {
+		t.Error("构建的事件签名应有效")
+	}
+	other, _ := builder.Build()
+	if other.ID == e.ID {
+		t.Error("每次构建应生成新的ID")
+	}
+
+	if _, err := NewEventBuilder("shop", "v1", " ", "item", "create").Build(); err == nil {
+		t.Error("必填字段为空时应返回错误")
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/spf13/cast"
+)
+
+// ToJSONSchema 将事件参数转换为 JSON Schema 描述，用于生成接口文档
+// 类型由 Type 决定，Range/RangeValue 转换为 enum、minimum、maximum、minLength 等约束
+func (e *EventParam) ToJSONSchema() map[string]interface{} {
+	if e == nil {
+		return map[string]interface{}{}
+	}
+	// 查询参数的值为逗号分隔的字符串，真实类型由实体属性决定
+	// 游标为服务端生成的不透明字符串
+	if e.Type == string(AND_QUERY_FIELD_TYPE) || e.Type == string(OR_QUERY_FIELD_TYPE) || e.Type == string(CURSOR_FIELD_TYPE) {
+		return map[string]interface{}{"type": "string"}
+	}
+	schema := fieldTypeSchema(e.Type)
+	switch schema["type"] {
+	case "integer", "number":
+		applyNumberRange(schema, e.Range, e.RangeValue)
+	case "string":
+		applyStringRange(schema, e.Type, e.Range, e.RangeValue)
+	}
+	return schema
+}
+
+// ToJSONSchema 将实体属性转换为 JSON Schema 描述，属性名称作为描述，默认值作为 default
+func (e *EntityAttribute) ToJSONSchema() map[string]interface{} {
+	if e == nil {
+		return map[string]interface{}{}
+	}
+	schema := fieldTypeSchema(e.FieldType)
+	if e.Name != "" {
+		schema["description"] = e.Name
+	}
+	// 当前时间的默认值在新建时才确定，不写入 Schema
+	if def := e.GetDefaultVal(); def != nil && e.DefaultValue != DEFAULT_VALUE_NOW {
+		schema["default"] = def
+	}
+	if IsStringFieldType(e.FieldType) {
+		if e.MinLength > 0 {
+			schema["minLength"] = e.MinLength
+		}
+		if e.MaxLength > 0 {
+			schema["maxLength"] = e.MaxLength
+		}
+	}
+	return schema
+}
+
+// ToParamSchema 汇总事件的所有参数，生成 object 类型的 JSON Schema
+// 排序参数由事件配置决定，不属于调用方的输入，因此不会出现在结果中
+func (e *EntityEvent) ToParamSchema() map[string]interface{} {
+	properties := map[string]interface{}{}
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if e == nil || e.Params == "" {
+		return schema
+	}
+	params := []EventParam{}
+	if err := jsonx.UnmarshalFromStr(e.Params, &params); err != nil {
+		return schema
+	}
+	required := []string{}
+	for _, p := range params {
+		if p.Name == "" || p.Type == string(ORDER_BY_FIELD_TYPE) || p.Type == string(JOIN_QUERY_FIELD_TYPE) {
+			continue
+		}
+		properties[p.Name] = p.ToJSONSchema()
+		if p.Required {
+			required = append(required, p.Name)
+		}
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// fieldTypeSchema 根据字段类型返回基础的 JSON Schema，未知类型及自定义类型不限制取值类型
+func fieldTypeSchema(typz string) map[string]interface{} {
+	switch typz {
+	case string(ID_FIELD_TYPE), string(REF_FIELD_TYPE), string(STRING_FIELD_TYPE),
+		string(TEXT_FIELD_TYPE), string(UID_FIELD_TYPE), string(CONSTANT_FIELD_TYPE):
+		return map[string]interface{}{"type": "string"}
+	case string(URL_FIELD_TYPE):
+		return map[string]interface{}{"type": "string", "format": "uri"}
+	case string(EMAIL_FIELD_TYPE):
+		return map[string]interface{}{"type": "string", "format": "email"}
+	case string(PHONE_FIELD_TYPE):
+		return map[string]interface{}{"type": "string", "format": "phone"}
+	case string(INT8_FIELD_TYPE):
+		return map[string]interface{}{"type": "integer", "format": "int32", "minimum": -128, "maximum": 127}
+	case string(INT16_FIELD_TYPE):
+		return map[string]interface{}{"type": "integer", "format": "int32", "minimum": -32768, "maximum": 32767}
+	case string(INT32_FIELD_TYPE):
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case string(INT64_FIELD_TYPE):
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case string(DATETIME_FIELD_TYPE):
+		// 时间统一使用时间戳
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case string(FLOAT32_FIELD_TYPE):
+		return map[string]interface{}{"type": "number", "format": "float"}
+	case string(FLOAT64_FIELD_TYPE):
+		return map[string]interface{}{"type": "number", "format": "double"}
+	case string(BOOLEAN_FIELD_TYPE), string(DRY_RUN_FIELD_TYPE):
+		return map[string]interface{}{"type": "boolean"}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// applyNumberRange 按数值类参数的校验规则添加约束，规则与参数校验保持一致
+func applyNumberRange(schema map[string]interface{}, rangeType, rangeValue string) {
+	vals := strings.Split(rangeValue, ",")
+	num := func(s string) interface{} {
+		if schema["type"] == "integer" {
+			return cast.ToInt64(strings.TrimSpace(s))
+		}
+		return cast.ToFloat64(strings.TrimSpace(s))
+	}
+	enum := func() []interface{} {
+		list := make([]interface{}, 0, len(vals))
+		for _, v := range vals {
+			list = append(list, num(v))
+		}
+		return list
+	}
+	if strings.TrimSpace(rangeValue) == "" {
+		return
+	}
+	switch rangeType {
+	case "in":
+		schema["enum"] = enum()
+	case "nin":
+		schema["not"] = map[string]interface{}{"enum": enum()}
+	case "gt":
+		schema["exclusiveMinimum"] = num(vals[0])
+	case "gte":
+		schema["minimum"] = num(vals[0])
+	case "lt":
+		schema["exclusiveMaximum"] = num(vals[0])
+	case "lte":
+		schema["maximum"] = num(vals[0])
+	case "range":
+		if len(vals) >= 2 {
+			schema["exclusiveMinimum"] = num(vals[0])
+			schema["exclusiveMaximum"] = num(vals[1])
+		}
+	case "eq_range":
+		if len(vals) >= 2 {
+			schema["minimum"] = num(vals[0])
+			schema["maximum"] = num(vals[1])
+		}
+	case "out":
+		if len(vals) >= 2 {
+			schema["not"] = map[string]interface{}{"minimum": num(vals[0]), "maximum": num(vals[1])}
+		}
+	case "eq_out":
+		if len(vals) >= 2 {
+			schema["not"] = map[string]interface{}{"exclusiveMinimum": num(vals[0]), "exclusiveMaximum": num(vals[1])}
+		}
+	}
+}
+
+// applyStringRange 按字符串类参数的校验规则添加约束，规则与参数校验保持一致
+func applyStringRange(schema map[string]interface{}, typz, rangeType, rangeValue string) {
+	// 仅 string/id/text 参与范围校验，其他字符串类型由 format 描述
+	if typz != string(STRING_FIELD_TYPE) && typz != string(ID_FIELD_TYPE) && typz != string(TEXT_FIELD_TYPE) {
+		return
+	}
+	if rangeValue == "" {
+		return
+	}
+	vals := strings.Split(rangeValue, ",")
+	enum := func() []interface{} {
+		list := make([]interface{}, 0, len(vals))
+		for _, v := range vals {
+			list = append(list, v)
+		}
+		return list
+	}
+	switch rangeType {
+	case "in":
+		schema["enum"] = enum()
+	case "nin":
+		schema["not"] = map[string]interface{}{"enum": enum()}
+	case "length":
+		if len(vals) == 1 {
+			schema["minLength"] = cast.ToInt(vals[0])
+			schema["maxLength"] = cast.ToInt(vals[0])
+		} else {
+			schema["minLength"] = cast.ToInt(vals[0])
+			schema["maxLength"] = cast.ToInt(vals[1])
+		}
+	case "r_like":
+		schema["pattern"] = "^" + regexp.QuoteMeta(rangeValue)
+	case "l_like":
+		schema["pattern"] = regexp.QuoteMeta(rangeValue) + "$"
+	case "a_like":
+		schema["pattern"] = regexp.QuoteMeta(rangeValue)
+	case "regex":
+		schema["pattern"] = rangeValue
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+)
+
+// assertSchema 将生成的 Schema 序列化后与期望的 JSON 比较
+func assertSchema(t *testing.T, got map[string]interface{}, expected string) {
+	t.Helper()
+	gotStr, err := jsonx.MarshalToStr(got)
+	if err != nil {
+		t.Fatalf("序列化失败: %v", err)
+	}
+	var gotVal, expectedVal interface{}
+	if err := jsonx.UnmarshalFromStr(gotStr, &gotVal); err != nil {
+		t.Fatalf("反序列化失败: %v", err)
+	}
+	if err := jsonx.UnmarshalFromStr(expected, &expectedVal); err != nil {
+		t.Fatalf("期望值不是合法JSON: %v", err)
+	}
+	if !reflect.DeepEqual(gotVal, expectedVal) {
+		t.Errorf("Schema不一致\n期望: %s\n实际: %s", expected, gotStr)
+	}
+}
+
+func TestEventParamToJSONSchema(t *testing.T) {
+	cases := []struct {
+		name     string
+		param    EventParam
+		expected string
+	}{
+		{"字符串", EventParam{Type: "string", Range: "any"}, `{"type":"string"}`},
+		{"整数", EventParam{Type: "int64", Range: "any"}, `{"type":"integer","format":"int64"}`},
+		{"布尔", EventParam{Type: "boolean"}, `{"type":"boolean"}`},
+		{"邮箱", EventParam{Type: "email"}, `{"type":"string","format":"email"}`},
+		{"自定义", EventParam{Type: "custom", RangeValue: "geo"}, `{}`},
+		{"枚举", EventParam{Type: "string", Range: "in", RangeValue: "a,b"}, `{"type":"string","enum":["a","b"]}`},
+		{"排除", EventParam{Type: "int32", Range: "nin", RangeValue: "1,2"}, `{"type":"integer","format":"int32","not":{"enum":[1,2]}}`},
+		{"固定长度", EventParam{Type: "string", Range: "length", RangeValue: "6"}, `{"type":"string","minLength":6,"maxLength":6}`},
+		{"长度区间", EventParam{Type: "text", Range: "length", RangeValue: "1,20"}, `{"type":"string","minLength":1,"maxLength":20}`},
+		{"前缀", EventParam{Type: "string", Range: "r_like", RangeValue: "a.b"}, `{"type":"string","pattern":"^a\\.b"}`},
+		{"正则", EventParam{Type: "string", Range: "regex", RangeValue: `^\d{6}$`}, `{"type":"string","pattern":"^\\d{6}$"}`},
+		{"大于等于", EventParam{Type: "int64", Range: "gte", RangeValue: "18"}, `{"type":"integer","format":"int64","minimum":18}`},
+		{"小于", EventParam{Type: "float64", Range: "lt", RangeValue: "1.5"}, `{"type":"number","format":"double","exclusiveMaximum":1.5}`},
+		{"闭区间", EventParam{Type: "int32", Range: "eq_range", RangeValue: "1,100"}, `{"type":"integer","format":"int32","minimum":1,"maximum":100}`},
+		{"开区间", EventParam{Type: "float32", Range: "range", RangeValue: "0,1"}, `{"type":"number","format":"float","exclusiveMinimum":0,"exclusiveMaximum":1}`},
+		{"区间外", EventParam{Type: "int64", Range: "out", RangeValue: "10,20"}, `{"type":"integer","format":"int64","not":{"minimum":10,"maximum":20}}`},
+		{"int8范围", EventParam{Type: "int8", Range: "any"}, `{"type":"integer","format":"int32","minimum":-128,"maximum":127}`},
+		{"查询参数", EventParam{Type: "and_query", Range: "in", RangeValue: "a,b"}, `{"type":"string"}`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assertSchema(t, c.param.ToJSONSchema(), c.expected)
+		})
+	}
+}
+
+func TestEntityAttributeToJSONSchema(t *testing.T) {
+	attr := EntityAttribute{Name: "年龄", Code: "age", FieldType: "int32", DefaultValue: "18"}
+	assertSchema(t, attr.ToJSONSchema(), `{"type":"integer","format":"int32","description":"年龄","default":18}`)
+
+	attr = EntityAttribute{Code: "homepage", FieldType: "url"}
+	assertSchema(t, attr.ToJSONSchema(), `{"type":"string","format":"uri"}`)
+}
+
+func TestEntityEventToParamSchema(t *testing.T) {
+	event := EntityEvent{
+		Params: `[
+			{"name":"name","type":"string","range":"length","rangeValue":"1,32","required":true},
+			{"name":"age","type":"int32","range":"gte","rangeValue":"0"},
+			{"name":"created_at","type":"order_by","range":"desc"}
+		]`,
+	}
+	expected := `{
+		"type":"object",
+		"properties":{
+			"name":{"type":"string","minLength":1,"maxLength":32},
+			"age":{"type":"integer","format":"int32","minimum":0}
+		},
+		"required":["name"]
+	}`
+	assertSchema(t, event.ToParamSchema(), expected)
+
+	empty := EntityEvent{}
+	assertSchema(t, empty.ToParamSchema(), `{"type":"object","properties":{}}`)
+}
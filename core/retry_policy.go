@@ -0,0 +1,127 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"strings"
+	"time"
+
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/spf13/cast"
+)
+
+// 任务重试的回退方式
+const (
+	BACKOFF_FIXED       = "fixed"       // 固定间隔：base
+	BACKOFF_LINEAR      = "linear"      // 线性增长：base * retries
+	BACKOFF_EXPONENTIAL = "exponential" // 指数增长：base * 2^(retries-1)
+	BACKOFF_CUBIC       = "cubic"       // 立方增长：base * retries³
+)
+
+const (
+	// DEFAULT_BACKOFF_BASE 默认的回退基础时间，单位秒
+	DEFAULT_BACKOFF_BASE = 5
+	// MAX_BACKOFF_DELAY 重试回退的最大延时
+	MAX_BACKOFF_DELAY = time.Hour
+)
+
+// RetryPolicy 任务执行器的重试策略，未配置时按立方回退无限重试
+type RetryPolicy struct {
+	MaxRetries  int    `json:"maxRetries"`  // 最大重试次数，0表示不重试
+	BackoffType string `json:"backoffType"` // 回退方式：fixed、linear、exponential、cubic，默认为cubic
+	BackoffBase int    `json:"backoffBase"` // 回退基础时间，单位秒，小于等于0时为5秒
+}
+
+// NewRetryPolicyFromValue 从map或JSON字符串创建重试策略，为空或解析失败时返回nil
+func NewRetryPolicyFromValue(v interface{}) *RetryPolicy {
+	switch data := v.(type) {
+	case *RetryPolicy:
+		return data.Clone()
+	case map[string]interface{}:
+		return &RetryPolicy{
+			MaxRetries:  cast.ToInt(data["maxRetries"]),
+			BackoffType: cast.ToString(data["backoffType"]),
+			BackoffBase: cast.ToInt(data["backoffBase"]),
+		}
+	case string:
+		if strings.TrimSpace(data) == "" {
+			return nil
+		}
+		policy := &RetryPolicy{}
+		if err := jsonx.UnmarshalFromStr(data, policy); err != nil {
+			return nil
+		}
+		return policy
+	default:
+		return nil
+	}
+}
+
+// Clone 复制重试策略，接收者为nil时返回nil
+func (p *RetryPolicy) Clone() *RetryPolicy {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	return &c
+}
+
+// Exhausted 判断已重试次数是否达到上限，未配置策略时不限制重试次数
+func (p *RetryPolicy) Exhausted(retries int) bool {
+	return p != nil && retries >= p.MaxRetries
+}
+
+// Backoff 计算第 retries 次重试前的回退时间，最大为1小时，未配置策略时按立方回退
+func (p *RetryPolicy) Backoff(retries int) time.Duration {
+	base, typz := int64(DEFAULT_BACKOFF_BASE), BACKOFF_CUBIC
+	if p != nil {
+		if p.BackoffBase > 0 {
+			base = int64(p.BackoffBase)
+		}
+		if p.BackoffType != "" {
+			typz = strings.ToLower(p.BackoffType)
+		}
+	}
+	if retries < 0 {
+		retries = 0
+	}
+	n := int64(retries)
+	var seconds int64
+	switch typz {
+	case BACKOFF_FIXED:
+		seconds = base
+	case BACKOFF_LINEAR:
+		seconds = base * n
+	case BACKOFF_EXPONENTIAL:
+		if retries == 0 {
+			seconds = 0
+		} else if retries > 12 {
+			seconds = int64(MAX_BACKOFF_DELAY / time.Second)
+		} else {
+			seconds = base << (retries - 1)
+		}
+	default:
+		if retries > 20 {
+			seconds = int64(MAX_BACKOFF_DELAY / time.Second)
+		} else {
+			seconds = base * n * n * n
+		}
+	}
+	delay := time.Duration(seconds) * time.Second
+	if delay > MAX_BACKOFF_DELAY {
+		return MAX_BACKOFF_DELAY
+	}
+	return delay
+}
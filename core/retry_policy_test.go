@@ -0,0 +1,85 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	cases := []struct {
+		policy  *RetryPolicy
+		retries int
+		want    time.Duration
+	}{
+		// 未配置策略时保持原有的立方回退
+		{nil, 1, 5 * time.Second},
+		{nil, 2, 40 * time.Second},
+		{nil, 9, time.Hour},
+		{nil, 1000, time.Hour},
+		{&RetryPolicy{BackoffType: BACKOFF_FIXED, BackoffBase: 3}, 7, 3 * time.Second},
+		{&RetryPolicy{BackoffType: BACKOFF_LINEAR, BackoffBase: 2}, 4, 8 * time.Second},
+		{&RetryPolicy{BackoffType: BACKOFF_EXPONENTIAL, BackoffBase: 2}, 1, 2 * time.Second},
+		{&RetryPolicy{BackoffType: BACKOFF_EXPONENTIAL, BackoffBase: 2}, 4, 16 * time.Second},
+		{&RetryPolicy{BackoffType: BACKOFF_EXPONENTIAL, BackoffBase: 2}, 64, time.Hour},
+		{&RetryPolicy{BackoffType: "Linear"}, 3, 15 * time.Second},
+		{&RetryPolicy{BackoffType: "unknown", BackoffBase: 1}, 3, 27 * time.Second},
+	}
+	for _, c := range cases {
+		if got := c.policy.Backoff(c.retries); got != c.want {
+			t.Errorf("%+v.Backoff(%d) = %v, want %v", c.policy, c.retries, got, c.want)
+		}
+	}
+}
+
+func TestRetryPolicyExhausted(t *testing.T) {
+	var none *RetryPolicy
+	if none.Exhausted(100) {
+		t.Error("未配置策略时不限制重试次数")
+	}
+	if !(&RetryPolicy{MaxRetries: 0}).Exhausted(0) {
+		t.Error("最大重试次数为0时不应重试")
+	}
+	p := &RetryPolicy{MaxRetries: 3}
+	if p.Exhausted(2) || !p.Exhausted(3) {
+		t.Error("重试次数上限判断错误")
+	}
+}
+
+func TestNewRetryPolicyFromValue(t *testing.T) {
+	fromMap := NewEntityEventFromMap(map[string]interface{}{
+		"code":        "pay",
+		"retryPolicy": map[string]interface{}{"maxRetries": 5, "backoffType": "linear", "backoffBase": "2"},
+	})
+	if p := fromMap.RetryPolicy; p == nil || p.MaxRetries != 5 || p.BackoffType != BACKOFF_LINEAR || p.BackoffBase != 2 {
+		t.Errorf("从map解析重试策略错误: %+v", p)
+	}
+	fromJson := NewEntityEventFromJson(`{"code":"export","retryPolicy":{"maxRetries":0}}`)
+	if p := fromJson.RetryPolicy; p == nil || p.MaxRetries != 0 {
+		t.Errorf("从JSON解析重试策略错误: %+v", p)
+	}
+	if NewEntityEventFromMap(map[string]interface{}{"code": "plain"}).RetryPolicy != nil {
+		t.Error("未配置重试策略时应为nil")
+	}
+	if p := NewRetryPolicyFromValue(`{"backoffType":"fixed"}`); p == nil || p.BackoffType != BACKOFF_FIXED {
+		t.Errorf("从字符串解析重试策略错误: %+v", p)
+	}
+	clone := fromMap.Clone()
+	clone.RetryPolicy.MaxRetries = 1
+	if fromMap.RetryPolicy.MaxRetries != 5 {
+		t.Error("Clone 应复制重试策略")
+	}
+}
@@ -16,6 +16,7 @@ package core
 
 import (
 	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/utils"
 	"github.com/garrickvan/event-matrix/utils/jsonx"
 	"github.com/spf13/cast"
 )
@@ -34,6 +35,8 @@ const (
 	TaskStatusFailed TaskStatus = 3
 	// TaskStatusTimeout 任务超时状态
 	TaskStatusTimeout TaskStatus = 4
+	// TaskStatusCancelled 任务已取消状态，不会再被执行或重试
+	TaskStatusCancelled TaskStatus = 5
 )
 
 // Code 将TaskStatus转换为对应的响应码
@@ -50,11 +53,22 @@ func (ts TaskStatus) Code() constant.RESPONSE_CODE {
 		return constant.TASK_FAILED
 	case TaskStatusTimeout:
 		return constant.TASK_TIMEOUT
+	case TaskStatusCancelled:
+		return constant.TASK_CANCELLED
 	default:
 		return constant.TASK_UNKNOWN
 	}
 }
 
+const (
+	// TaskPriorityNormal 普通优先级
+	TaskPriorityNormal uint8 = 0
+	// TaskPriorityHigh 高优先级
+	TaskPriorityHigh uint8 = 1
+	// TaskPriorityCritical 紧急优先级，任务队列已满时可抢占低优先级任务
+	TaskPriorityCritical uint8 = 2
+)
+
 // Task 表示系统中的任务对象，用于跟踪和管理事件的执行
 type Task struct {
 	// ID 任务的唯一标识符
@@ -77,6 +91,45 @@ type Task struct {
 	ExecuteAt int64 `json:"executeAt" gorm:"index"`
 	// UpdatedAt 更新时间戳
 	UpdatedAt int64 `json:"updatedAt"`
+	// ReplayCount 重放次数，大于0表示由事件日志重放生成的任务
+	ReplayCount int `json:"replayCount"`
+	// Version 乐观锁版本号，每次更新任务时递增，多实例部署时用于认领任务
+	Version int64 `json:"version" gorm:"default:0"`
+	// Priority 优先级，0普通、1高、2紧急，优先级高的任务先被拉取处理
+	Priority uint8 `json:"priority" gorm:"index;default:0"`
+}
+
+// NewTask 根据事件创建普通优先级的任务
+// 参数:
+//   - event: 任务事件
+//   - executeAt: 计划执行时间戳，小于等于当前时间时立即执行
+//
+// 返回创建的Task实例
+func NewTask(event *Event, executeAt int64) *Task {
+	now := utils.GetNowMilli()
+	return &Task{
+		ID:         utils.GenID(),
+		EventID:    event.ID,
+		EventLabel: event.GetUniqueLabel(),
+		Event:      event.Raw(),
+		CreatedAt:  now,
+		ExecuteAt:  executeAt,
+		Priority:   TaskPriorityNormal,
+	}
+}
+
+// NewHighPriorityTask 根据事件创建高优先级的任务，参数同 NewTask
+func NewHighPriorityTask(event *Event, executeAt int64) *Task {
+	task := NewTask(event, executeAt)
+	task.Priority = TaskPriorityHigh
+	return task
+}
+
+// NewCriticalTask 根据事件创建紧急优先级的任务，参数同 NewTask
+func NewCriticalTask(event *Event, executeAt int64) *Task {
+	task := NewTask(event, executeAt)
+	task.Priority = TaskPriorityCritical
+	return task
 }
 
 // NewTaskFromMap 从map类型数据创建Task实例
@@ -89,16 +142,19 @@ func NewTaskFromMap(v interface{}) *Task {
 	}
 
 	return &Task{
-		ID:         cast.ToString(data["id"]),
-		EventID:    cast.ToString(data["eventId"]),
-		EventLabel: cast.ToString(data["eventLabel"]),
-		Event:      cast.ToString(data["event"]),
-		Status:     TaskStatus(cast.ToInt(data["status"])),
-		Retries:    cast.ToInt(data["retries"]),
-		ExecServer: cast.ToString(data["execServer"]),
-		CreatedAt:  cast.ToInt64(data["createdAt"]),
-		ExecuteAt:  cast.ToInt64(data["executeAt"]),
-		UpdatedAt:  cast.ToInt64(data["updatedAt"]),
+		ID:          cast.ToString(data["id"]),
+		EventID:     cast.ToString(data["eventId"]),
+		EventLabel:  cast.ToString(data["eventLabel"]),
+		Event:       cast.ToString(data["event"]),
+		Status:      TaskStatus(cast.ToInt(data["status"])),
+		Retries:     cast.ToInt(data["retries"]),
+		ExecServer:  cast.ToString(data["execServer"]),
+		CreatedAt:   cast.ToInt64(data["createdAt"]),
+		ExecuteAt:   cast.ToInt64(data["executeAt"]),
+		UpdatedAt:   cast.ToInt64(data["updatedAt"]),
+		ReplayCount: cast.ToInt(data["replayCount"]),
+		Version:     cast.ToInt64(data["version"]),
+		Priority:    cast.ToUint8(data["priority"]),
 	}
 }
 
@@ -122,15 +178,18 @@ func (t *Task) Clone() *Task {
 		return &Task{}
 	}
 	return &Task{
-		ID:         t.ID,
-		EventID:    t.EventID,
-		EventLabel: t.EventLabel,
-		Event:      t.Event,
-		Status:     t.Status,
-		Retries:    t.Retries,
-		ExecServer: t.ExecServer,
-		CreatedAt:  t.CreatedAt,
-		ExecuteAt:  t.ExecuteAt,
-		UpdatedAt:  t.UpdatedAt,
+		ID:          t.ID,
+		EventID:     t.EventID,
+		EventLabel:  t.EventLabel,
+		Event:       t.Event,
+		Status:      t.Status,
+		Retries:     t.Retries,
+		ExecServer:  t.ExecServer,
+		CreatedAt:   t.CreatedAt,
+		ExecuteAt:   t.ExecuteAt,
+		UpdatedAt:   t.UpdatedAt,
+		ReplayCount: t.ReplayCount,
+		Version:     t.Version,
+		Priority:    t.Priority,
 	}
 }
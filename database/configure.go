@@ -15,6 +15,8 @@
 // Package database 提供数据库配置和管理功能
 package database
 
+import "time"
+
 // DB_TYPE 定义支持的数据库类型
 type DB_TYPE string
 
@@ -72,4 +74,35 @@ type DBConf struct {
 	// true: 跳过默认事务，可能提高性能但降低数据安全性
 	// false: 使用默认事务，保证数据一致性
 	SkipDefaultTransaction bool `yaml:"skip_default_transaction" json:"skip_default_transaction"`
+
+	// ConnectTimeout 建立连接的超时时间（秒）
+	// 小于等于0时使用默认值10秒；注意：对于 SQLite 该字段无效
+	ConnectTimeout int `yaml:"connect_timeout" json:"connect_timeout"`
+
+	// QueryTimeout 单条语句的执行超时时间（秒）
+	// 小于等于0时使用默认值30秒，调用方已设置超时的上下文不受影响
+	QueryTimeout int `yaml:"query_timeout" json:"query_timeout"`
+}
+
+const (
+	// DEFAULT_CONNECT_TIMEOUT 默认的建立连接超时时间（秒）
+	DEFAULT_CONNECT_TIMEOUT = 10
+	// DEFAULT_QUERY_TIMEOUT 默认的语句执行超时时间（秒）
+	DEFAULT_QUERY_TIMEOUT = 30
+)
+
+// GetConnectTimeout 返回建立连接的超时时间，未设置时返回默认值
+func (c *DBConf) GetConnectTimeout() time.Duration {
+	if c.ConnectTimeout <= 0 {
+		return DEFAULT_CONNECT_TIMEOUT * time.Second
+	}
+	return time.Duration(c.ConnectTimeout) * time.Second
+}
+
+// GetQueryTimeout 返回语句执行的超时时间，未设置时返回默认值
+func (c *DBConf) GetQueryTimeout() time.Duration {
+	if c.QueryTimeout <= 0 {
+		return DEFAULT_QUERY_TIMEOUT * time.Second
+	}
+	return time.Duration(c.QueryTimeout) * time.Second
 }
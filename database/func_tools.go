@@ -17,6 +17,7 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 
 	"github.com/garrickvan/event-matrix/utils/logx"
 	"gorm.io/gorm"
@@ -166,6 +167,49 @@ func RawQuerySqlExec(db *gorm.DB, sqlStatement string, params map[string]interfa
 	return results, nil
 }
 
+// RawSqlToSQL 生成绑定参数后的SQL语句但不执行，用于预览原生SQL
+//
+// 参数:
+//   - db: GORM数据库连接实例
+//   - sqlStatement: 要预览的SQL语句，可包含命名参数
+//   - params: 命名参数的值映射
+//
+// 返回值:
+//   - string: 绑定参数后的SQL语句
+func RawSqlToSQL(db *gorm.DB, sqlStatement string, params map[string]interface{}) string {
+	args := make([]interface{}, 0, len(params))
+	for k, v := range params {
+		args = append(args, sql.Named(k, v))
+	}
+	return db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Exec(sqlStatement, args...)
+	})
+}
+
+// errDryRunRollback 试运行事务的回滚标记
+var errDryRunRollback = errors.New("dry run rollback")
+
+// DryRunTransaction 在事务中执行操作后总是回滚，用于试运行
+//
+// 参数:
+//   - db: GORM数据库连接实例
+//   - fc: 在事务中执行的操作
+//
+// 返回值:
+//   - error: 操作本身或开启事务的错误，操作成功时为nil
+func DryRunTransaction(db *gorm.DB, fc func(tx *gorm.DB) error) error {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := fc(tx); err != nil {
+			return err
+		}
+		return errDryRunRollback
+	})
+	if errors.Is(err, errDryRunRollback) {
+		return nil
+	}
+	return err
+}
+
 // TransactionRawSqlExec 在事务中执行非查询SQL语句
 // 提供自动提交或回滚事务的功能
 //
@@ -0,0 +1,142 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestCheckFieldExistsSqlite(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "check.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	if err := db.Exec("CREATE TABLE shop_user (id TEXT PRIMARY KEY, name TEXT)").Error; err != nil {
+		t.Fatalf("建表失败: %v", err)
+	}
+	if !CheckFieldExists(db, "shop_user", "name") {
+		t.Error("已存在的字段应返回true")
+	}
+	if CheckFieldExists(db, "shop_user", "age") {
+		t.Error("不存在的字段应返回false")
+	}
+	if CheckFieldExists(db, "not_exist", "name") {
+		t.Error("不存在的表应返回false")
+	}
+	// 传入指定了表名的实例也能正常查询
+	if !CheckFieldExists(db.Table("shop_user"), "shop_user", "id") {
+		t.Error("指定表名的实例查询失败")
+	}
+}
+
+func TestCheckFieldExistsMysql(t *testing.T) {
+	conn := &fakeMysqlDriver{exists: true}
+	sql.Register("fake-mysql-check-field", conn)
+	sqlDB, err := sql.Open("fake-mysql-check-field", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+
+	if !CheckFieldExists(db, "shop_user", "name") {
+		t.Error("已存在的字段应返回true")
+	}
+	query, args := conn.last()
+	if !strings.Contains(query, "information_schema.columns") || !strings.Contains(query, "DATABASE()") {
+		t.Errorf("mysql应查询information_schema: %s", query)
+	}
+	if len(args) != 2 || args[0] != "shop_user" || args[1] != "name" {
+		t.Errorf("查询参数错误: %v", args)
+	}
+
+	conn.exists = false
+	if CheckFieldExists(db, "shop_user", "age") {
+		t.Error("不存在的字段应返回false")
+	}
+}
+
+// fakeMysqlDriver 记录收到的查询，并按 exists 返回 EXISTS 查询的结果
+type fakeMysqlDriver struct {
+	mu     sync.Mutex
+	exists bool
+	query  string
+	args   []driver.Value
+}
+
+func (d *fakeMysqlDriver) Open(string) (driver.Conn, error) { return &fakeMysqlConn{d: d}, nil }
+
+func (d *fakeMysqlDriver) last() (string, []driver.Value) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.query, d.args
+}
+
+type fakeMysqlConn struct{ d *fakeMysqlDriver }
+
+func (c *fakeMysqlConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeMysqlStmt{d: c.d, query: query}, nil
+}
+func (c *fakeMysqlConn) Close() error              { return nil }
+func (c *fakeMysqlConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type fakeMysqlStmt struct {
+	d     *fakeMysqlDriver
+	query string
+}
+
+func (s *fakeMysqlStmt) Close() error  { return nil }
+func (s *fakeMysqlStmt) NumInput() int { return -1 }
+func (s *fakeMysqlStmt) Exec([]driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+func (s *fakeMysqlStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	s.d.query, s.d.args = s.query, args
+	return &fakeMysqlRows{exists: s.d.exists}, nil
+}
+
+type fakeMysqlRows struct {
+	exists bool
+	done   bool
+}
+
+func (r *fakeMysqlRows) Columns() []string { return []string{"exists"} }
+func (r *fakeMysqlRows) Close() error      { return nil }
+func (r *fakeMysqlRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	if r.exists {
+		dest[0] = int64(1)
+	} else {
+		dest[0] = int64(0)
+	}
+	return nil
+}
@@ -30,6 +30,7 @@ type GormDBManager struct {
 	dbs                        sync.Map      // 并发安全的数据库连接存储，键为数据库名称，值为*gorm.DB实例
 	dbConfs                    sync.Map      // 并发安全的数据库配置存储，键为数据库名称，值为*DBConf实例
 	checkOnce                  sync.Once     // 确保健康检查协程只启动一次
+	closeOnce                  sync.Once     // 确保管理器只关闭一次
 	stopChan                   chan struct{} // 用于停止健康检查协程的信号通道
 	mu                         sync.RWMutex  // 用于保护defaultHealthCheckInterval的读写锁
 	defaultHealthCheckInterval time.Duration // 默认的健康检查间隔时间，可动态调整
@@ -207,24 +208,32 @@ func (m *GormDBManager) getHealthCheckInterval() time.Duration {
 }
 
 // Close 关闭所有数据库连接并停止健康检查
-// 在应用程序退出前调用，确保资源正确释放
+// 在应用程序退出前调用，确保资源正确释放，重复调用不会报错
 // 返回：
-//   - error: 关闭过程中的错误，目前始终返回nil
+//   - error: 关闭连接过程中的错误，多个数据库关闭失败时合并返回
 //
 // 注意：调用此方法后，管理器将不再可用，需要创建新的实例
 func (m *GormDBManager) Close() error {
-	close(m.stopChan)
+	var errs []error
+	m.closeOnce.Do(func() {
+		close(m.stopChan)
 
-	m.dbs.Range(func(key, value interface{}) bool {
-		dbName := key.(string)
-		db := value.(*gorm.DB)
+		m.dbs.Range(func(key, value interface{}) bool {
+			dbName := key.(string)
+			db := value.(*gorm.DB)
 
-		if sqlDB, err := db.DB(); err == nil {
-			sqlDB.Close()
-		}
-		m.dbs.Delete(dbName)
-		return true
+			sqlDB, err := db.DB()
+			if err == nil {
+				err = sqlDB.Close()
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("关闭数据库%s失败: %w", dbName, err))
+			}
+			m.dbs.Delete(dbName)
+			// 同时移除配置，避免关闭后被自动重新连接
+			m.dbConfs.Delete(dbName)
+			return true
+		})
 	})
-
-	return nil
+	return errors.Join(errs...)
 }
@@ -0,0 +1,67 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"testing"
+)
+
+func TestGormDBManagerClose(t *testing.T) {
+	m := NewGormDBManager()
+	dir := t.TempDir()
+	for _, name := range []string{"shop", "crm"} {
+		if err := m.RegisterDB(&DBConf{Type: SQLITE, Location: dir, DBName: name}); err != nil {
+			t.Fatalf("注册数据库%s失败: %v", name, err)
+		}
+	}
+	shop, err := m.Use("shop").DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	crm, err := m.Use("crm").DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 建立实际连接
+	if err := shop.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	if err := crm.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	if shop.Stats().OpenConnections == 0 {
+		t.Fatal("关闭前应存在打开的连接")
+	}
+
+	if err := m.Close(); err != nil {
+		t.Fatalf("关闭失败: %v", err)
+	}
+	if n := shop.Stats().OpenConnections; n != 0 {
+		t.Errorf("shop 仍有 %d 个打开的连接", n)
+	}
+	if n := crm.Stats().OpenConnections; n != 0 {
+		t.Errorf("crm 仍有 %d 个打开的连接", n)
+	}
+	if shop.Ping() == nil {
+		t.Error("关闭后的连接不应可用")
+	}
+	if m.HasDB("shop") || m.HasDB("crm") {
+		t.Error("关闭后不应保留数据库")
+	}
+	// 重复关闭不报错
+	if err := m.Close(); err != nil {
+		t.Fatalf("重复关闭失败: %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// MIGRATE_PAGE_SIZE 迁移数据时每页读取的行数
+const MIGRATE_PAGE_SIZE = 1000
+
+// migratePrimaryKey 迁移数据时用于排序和删除源数据的主键字段
+const migratePrimaryKey = "id"
+
+// MigrateTransform 迁移数据时的行转换函数，返回 nil 表示跳过该行
+type MigrateTransform func(row map[string]interface{}) map[string]interface{}
+
+// MigrateTableData 将源表数据分页迁移到目标表
+// 源库和目标库各开启一个事务，任意一页失败时两个事务都回滚；全部成功后先提交目标库再提交源库，
+// 源库提交失败时目标库数据已写入，但源数据不会丢失
+//
+// 参数:
+//   - srcDB: 源数据库连接实例
+//   - srcTable: 源表名，需包含 id 主键
+//   - dstDB: 目标数据库连接实例，与源库为同一实例时只开启一个事务
+//   - dstTable: 目标表名
+//   - transform: 行转换函数，为nil时原样写入
+//   - deleteSrc: 每页写入成功后是否删除源表中对应的行
+//
+// 返回值:
+//   - int64: 写入目标表的行数
+//   - error: 迁移过程中的错误，成功则为nil
+func MigrateTableData(srcDB *gorm.DB, srcTable string, dstDB *gorm.DB, dstTable string, transform MigrateTransform, deleteSrc bool) (int64, error) {
+	if srcDB == nil || dstDB == nil {
+		return 0, errors.New("源数据库或目标数据库不存在")
+	}
+	if srcTable == "" || dstTable == "" {
+		return 0, errors.New("源表名和目标表名不能为空")
+	}
+	if srcDB == dstDB && srcTable == dstTable {
+		return 0, errors.New("源表和目标表不能相同")
+	}
+
+	var migrated int64
+	if srcDB == dstDB {
+		err := srcDB.Transaction(func(tx *gorm.DB) error {
+			n, err := migratePages(tx, srcTable, tx, dstTable, transform, deleteSrc)
+			migrated = n
+			return err
+		})
+		return migrated, err
+	}
+
+	srcTx := srcDB.Begin()
+	if srcTx.Error != nil {
+		return 0, srcTx.Error
+	}
+	dstTx := dstDB.Begin()
+	if dstTx.Error != nil {
+		srcTx.Rollback()
+		return 0, dstTx.Error
+	}
+	migrated, err := migratePages(srcTx, srcTable, dstTx, dstTable, transform, deleteSrc)
+	if err != nil {
+		dstTx.Rollback()
+		srcTx.Rollback()
+		return 0, err
+	}
+	if err := dstTx.Commit().Error; err != nil {
+		srcTx.Rollback()
+		return 0, err
+	}
+	if err := srcTx.Commit().Error; err != nil {
+		return migrated, fmt.Errorf("目标表已写入 %d 行，但提交源库事务失败: %w", migrated, err)
+	}
+	return migrated, nil
+}
+
+// migratePages 按主键顺序分页读取源表，转换后写入目标表
+func migratePages(srcTx *gorm.DB, srcTable string, dstTx *gorm.DB, dstTable string, transform MigrateTransform, deleteSrc bool) (int64, error) {
+	var migrated int64
+	offset := 0
+	for {
+		rows := []map[string]interface{}{}
+		err := srcTx.Table(srcTable).Order(migratePrimaryKey).Offset(offset).Limit(MIGRATE_PAGE_SIZE).Find(&rows).Error
+		if err != nil {
+			return migrated, err
+		}
+		if len(rows) == 0 {
+			return migrated, nil
+		}
+
+		ids := make([]interface{}, 0, len(rows))
+		data := make([]map[string]interface{}, 0, len(rows))
+		for _, row := range rows {
+			id, has := row[migratePrimaryKey]
+			if deleteSrc && !has {
+				return migrated, errors.New("源表缺少主键字段: " + migratePrimaryKey)
+			}
+			ids = append(ids, id)
+			if transform != nil {
+				row = transform(row)
+			}
+			if row != nil {
+				data = append(data, row)
+			}
+		}
+		if len(data) > 0 {
+			if err := dstTx.Table(dstTable).Create(&data).Error; err != nil {
+				return migrated, err
+			}
+			migrated += int64(len(data))
+		}
+
+		if deleteSrc {
+			// 已删除的行不会再被读到，偏移量保持不变
+			err := srcTx.Table(srcTable).Where(migratePrimaryKey+" IN ?", ids).Delete(map[string]interface{}{}).Error
+			if err != nil {
+				return migrated, err
+			}
+		} else {
+			offset += len(rows)
+		}
+		if len(rows) < MIGRATE_PAGE_SIZE {
+			return migrated, nil
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func openMigrateDB(t *testing.T, name string, ddl ...string) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), name+".db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	for _, stmt := range ddl {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("建表失败: %v", err)
+		}
+	}
+	return db
+}
+
+func seedMigrateRows(t *testing.T, db *gorm.DB, table string, n int) {
+	rows := make([]map[string]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		rows = append(rows, map[string]interface{}{"id": fmt.Sprintf("%05d", i), "name": fmt.Sprintf("user-%d", i)})
+	}
+	if err := db.Table(table).CreateInBatches(&rows, 500).Error; err != nil {
+		t.Fatalf("写入测试数据失败: %v", err)
+	}
+}
+
+func countRows(t *testing.T, db *gorm.DB, table string) int64 {
+	var n int64
+	if err := db.Table(table).Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestMigrateTableDataAcrossDB(t *testing.T) {
+	src := openMigrateDB(t, "src", "CREATE TABLE shop_user (id TEXT PRIMARY KEY, name TEXT)")
+	dst := openMigrateDB(t, "dst", "CREATE TABLE shop_member (id TEXT PRIMARY KEY, nickname TEXT)")
+	total := MIGRATE_PAGE_SIZE*2 + 500
+	seedMigrateRows(t, src, "shop_user", total)
+
+	// 字段重命名，并跳过第一行
+	migrated, err := MigrateTableData(src, "shop_user", dst, "shop_member", func(row map[string]interface{}) map[string]interface{} {
+		if row["id"] == "00000" {
+			return nil
+		}
+		return map[string]interface{}{"id": row["id"], "nickname": row["name"]}
+	}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migrated != int64(total-1) || countRows(t, dst, "shop_member") != int64(total-1) {
+		t.Fatalf("迁移行数错误: %d", migrated)
+	}
+	if n := countRows(t, src, "shop_user"); n != 0 {
+		t.Errorf("源数据应全部删除, 剩余 %d", n)
+	}
+	var nickname string
+	dst.Table("shop_member").Select("nickname").Where("id = ?", "02499").Scan(&nickname)
+	if nickname != "user-2499" {
+		t.Errorf("转换后的字段错误: %s", nickname)
+	}
+}
+
+func TestMigrateTableDataSameDB(t *testing.T) {
+	db := openMigrateDB(t, "same",
+		"CREATE TABLE shop_user (id TEXT PRIMARY KEY, name TEXT)",
+		"CREATE TABLE shop_user_v2 (id TEXT PRIMARY KEY, name TEXT)")
+	seedMigrateRows(t, db, "shop_user", MIGRATE_PAGE_SIZE+1)
+
+	migrated, err := MigrateTableData(db, "shop_user", db, "shop_user_v2", nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migrated != MIGRATE_PAGE_SIZE+1 || countRows(t, db, "shop_user_v2") != MIGRATE_PAGE_SIZE+1 {
+		t.Fatalf("迁移行数错误: %d", migrated)
+	}
+	if countRows(t, db, "shop_user") != MIGRATE_PAGE_SIZE+1 {
+		t.Error("未要求删除时应保留源数据")
+	}
+	if _, err := MigrateTableData(db, "shop_user", db, "shop_user", nil, false); err == nil {
+		t.Error("源表和目标表相同时应返回错误")
+	}
+}
+
+func TestMigrateTableDataRollback(t *testing.T) {
+	src := openMigrateDB(t, "src", "CREATE TABLE shop_user (id TEXT PRIMARY KEY, name TEXT)")
+	dst := openMigrateDB(t, "dst", "CREATE TABLE shop_member (id TEXT PRIMARY KEY, nickname TEXT)")
+	seedMigrateRows(t, src, "shop_user", MIGRATE_PAGE_SIZE+10)
+
+	// 第二页写入不存在的字段，整体回滚
+	_, err := MigrateTableData(src, "shop_user", dst, "shop_member", func(row map[string]interface{}) map[string]interface{} {
+		if row["id"] == fmt.Sprintf("%05d", MIGRATE_PAGE_SIZE) {
+			return map[string]interface{}{"id": row["id"], "missing": row["name"]}
+		}
+		return map[string]interface{}{"id": row["id"], "nickname": row["name"]}
+	}, true)
+	if err == nil {
+		t.Fatal("写入失败时应返回错误")
+	}
+	if n := countRows(t, dst, "shop_member"); n != 0 {
+		t.Errorf("目标库应回滚, 实际 %d 行", n)
+	}
+	if n := countRows(t, src, "shop_user"); n != MIGRATE_PAGE_SIZE+10 {
+		t.Errorf("源库应回滚, 实际 %d 行", n)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 语句执行前后用于保存超时上下文的键
+const (
+	queryTimeoutCancelKey = "event_matrix:query_timeout_cancel"
+	queryTimeoutOriginKey = "event_matrix:query_timeout_origin"
+)
+
+// registerQueryTimeout 为每条语句设置执行超时
+// GORM 没有事务开始的回调，因此在各类语句执行前给上下文加上超时，执行后恢复原上下文，
+// 对SQLite等不支持服务端语句超时的数据库同样有效；调用方已设置超时的上下文不受影响
+func registerQueryTimeout(db *gorm.DB, timeout time.Duration) error {
+	if timeout <= 0 {
+		return nil
+	}
+	before := queryTimeoutBefore(timeout)
+	cb := db.Callback()
+	// Rows() 返回后仍需读取结果，不能提前取消，超时后上下文自动释放
+	regs := []func() error{
+		func() error {
+			return cb.Create().Before("gorm:create").Register("event_matrix:timeout_before_create", before)
+		},
+		func() error {
+			return cb.Create().After("gorm:create").Register("event_matrix:timeout_after_create", queryTimeoutAfter(true))
+		},
+		func() error {
+			return cb.Query().Before("gorm:query").Register("event_matrix:timeout_before_query", before)
+		},
+		func() error {
+			return cb.Query().After("gorm:query").Register("event_matrix:timeout_after_query", queryTimeoutAfter(true))
+		},
+		func() error {
+			return cb.Update().Before("gorm:update").Register("event_matrix:timeout_before_update", before)
+		},
+		func() error {
+			return cb.Update().After("gorm:update").Register("event_matrix:timeout_after_update", queryTimeoutAfter(true))
+		},
+		func() error {
+			return cb.Delete().Before("gorm:delete").Register("event_matrix:timeout_before_delete", before)
+		},
+		func() error {
+			return cb.Delete().After("gorm:delete").Register("event_matrix:timeout_after_delete", queryTimeoutAfter(true))
+		},
+		func() error { return cb.Raw().Before("gorm:raw").Register("event_matrix:timeout_before_raw", before) },
+		func() error {
+			return cb.Raw().After("gorm:raw").Register("event_matrix:timeout_after_raw", queryTimeoutAfter(true))
+		},
+		func() error { return cb.Row().Before("gorm:row").Register("event_matrix:timeout_before_row", before) },
+		func() error {
+			return cb.Row().After("gorm:row").Register("event_matrix:timeout_after_row", queryTimeoutAfter(false))
+		},
+	}
+	for _, reg := range regs {
+		if err := reg(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func queryTimeoutBefore(timeout time.Duration) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		origin := db.Statement.Context
+		if origin == nil {
+			origin = context.Background()
+		}
+		if _, ok := origin.Deadline(); ok {
+			return
+		}
+		ctx, cancel := context.WithTimeout(origin, timeout)
+		db.Statement.Context = ctx
+		db.InstanceSet(queryTimeoutOriginKey, origin)
+		db.InstanceSet(queryTimeoutCancelKey, cancel)
+	}
+}
+
+func queryTimeoutAfter(cancelAfter bool) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		// 恢复原上下文，避免同一实例再次执行时沿用已取消的上下文
+		if origin, ok := db.InstanceGet(queryTimeoutOriginKey); ok {
+			db.Statement.Context = origin.(context.Context)
+		}
+		if cancel, ok := db.InstanceGet(queryTimeoutCancelKey); ok && cancelAfter {
+			cancel.(context.CancelFunc)()
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDBConfTimeoutDefaults(t *testing.T) {
+	conf := DBConf{}
+	if conf.GetConnectTimeout() != 10*time.Second || conf.GetQueryTimeout() != 30*time.Second {
+		t.Fatalf("默认超时错误: %v %v", conf.GetConnectTimeout(), conf.GetQueryTimeout())
+	}
+	conf = DBConf{ConnectTimeout: 3, QueryTimeout: 5}
+	if conf.GetConnectTimeout() != 3*time.Second || conf.GetQueryTimeout() != 5*time.Second {
+		t.Fatalf("自定义超时错误: %v %v", conf.GetConnectTimeout(), conf.GetQueryTimeout())
+	}
+}
+
+func TestTimeoutDSN(t *testing.T) {
+	conf := DBConf{Location: "127.0.0.1", Port: 5432, UserName: "u", Password: "p", ConnectTimeout: 3, QueryTimeout: 5}
+	if dsn := pgDSN(conf, "shop"); !strings.HasSuffix(dsn, "connect_timeout=3 statement_timeout=5000") ||
+		!strings.Contains(dsn, "dbname=shop") {
+		t.Errorf("PostgreSQL连接字符串错误: %s", dsn)
+	}
+	if dsn := mysqlDSN(conf, "shop"); !strings.HasSuffix(dsn, "/shop?charset=utf8mb4&parseTime=True&loc=Local&timeout=3s&max_execution_time=5000") {
+		t.Errorf("MySQL连接字符串错误: %s", dsn)
+	}
+	// 未设置时使用默认值
+	if dsn := pgDSN(DBConf{}, "shop"); !strings.HasSuffix(dsn, "connect_timeout=10 statement_timeout=30000") {
+		t.Errorf("PostgreSQL默认超时错误: %s", dsn)
+	}
+}
+
+func TestSqliteQueryTimeout(t *testing.T) {
+	db, err := getGormDB(DBConf{Type: SQLITE, Location: t.TempDir(), DBName: "timeout", QueryTimeout: 1})
+	if err != nil {
+		t.Fatalf("连接数据库失败: %v", err)
+	}
+	defer func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	}()
+	if err := db.Exec("CREATE TABLE shop_user (id INTEGER PRIMARY KEY, name TEXT)").Error; err != nil {
+		t.Fatalf("建表失败: %v", err)
+	}
+
+	// 无限递归的查询应在超时后被中断
+	start := time.Now()
+	var n int64
+	err = db.Raw("WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c) SELECT count(*) FROM c").Scan(&n).Error
+	if err == nil {
+		t.Fatal("超时的查询应返回错误")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("查询未按时中断, 耗时 %v", elapsed)
+	}
+
+	// 超时后其它语句不受影响
+	if err := db.Table("shop_user").Create(map[string]interface{}{"id": 1, "name": "alice"}).Error; err != nil {
+		t.Fatalf("写入失败: %v", err)
+	}
+	// 同一实例多次执行时不沿用已取消的上下文
+	query := db.Table("shop_user").Where("id = ?", 1)
+	var count int64
+	for i := 0; i < 2; i++ {
+		if err := query.Count(&count).Error; err != nil || count != 1 {
+			t.Fatalf("第%d次查询失败: count=%d err=%v", i+1, count, err)
+		}
+	}
+}
@@ -40,6 +40,20 @@ const (
 	myDSNTmpl = "%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local"
 )
 
+// pgDSN 构建PostgreSQL连接字符串，附加连接超时和语句超时参数
+func pgDSN(dbConf DBConf, dbName string) string {
+	dsn := fmt.Sprintf(pgDSNTmpl, dbConf.Location, dbConf.UserName, dbConf.Password, dbName, dbConf.Port)
+	return fmt.Sprintf("%s connect_timeout=%d statement_timeout=%d",
+		dsn, int(dbConf.GetConnectTimeout().Seconds()), dbConf.GetQueryTimeout().Milliseconds())
+}
+
+// mysqlDSN 构建MySQL连接字符串，附加连接超时和查询超时参数
+func mysqlDSN(dbConf DBConf, dbName string) string {
+	dsn := fmt.Sprintf(myDSNTmpl, dbConf.UserName, dbConf.Password, dbConf.Location, dbConf.Port, dbName)
+	return fmt.Sprintf("%s&timeout=%ds&max_execution_time=%d",
+		dsn, int(dbConf.GetConnectTimeout().Seconds()), dbConf.GetQueryTimeout().Milliseconds())
+}
+
 // getGormDB 根据数据库配置创建GORM数据库连接
 //
 // 参数:
@@ -69,10 +83,13 @@ func getGormDB(dbConf DBConf) (*gorm.DB, error) {
 		// 确保SQLite数据库目录存在
 		utils.MakeDir(dbConf.Location)
 		dbPath := dbConf.Location + "/" + string(dbConf.DBName) + ".db"
-		db, err := gorm.Open(sqlite.Open(dbPath), &gormConfig)
+		db, err := gorm.Open(sqlite.Dialector{DriverName: sqliteDriverName, DSN: dbPath}, &gormConfig)
 		if err != nil {
 			return nil, fmt.Errorf("连接SQLite数据库失败，路径：%s，错误：%w", dbPath, err)
 		}
+		if err := registerQueryTimeout(db, dbConf.GetQueryTimeout()); err != nil {
+			return nil, err
+		}
 		return db, nil
 
 	case string(PGSQL), "postgres":
@@ -82,7 +99,7 @@ func getGormDB(dbConf DBConf) (*gorm.DB, error) {
 			return nil, err
 		}
 		// 构建连接字符串并创建连接
-		dsn := fmt.Sprintf(pgDSNTmpl, dbConf.Location, dbConf.UserName, dbConf.Password, dbConf.DBName, dbConf.Port)
+		dsn := pgDSN(dbConf, dbConf.DBName)
 		db, err := gorm.Open(postgres.New(postgres.Config{
 			DSN:                  dsn,
 			PreferSimpleProtocol: false, // 设置为true将禁用隐式预处理语句
@@ -90,6 +107,9 @@ func getGormDB(dbConf DBConf) (*gorm.DB, error) {
 		if err != nil {
 			return nil, err
 		}
+		if err := registerQueryTimeout(db, dbConf.GetQueryTimeout()); err != nil {
+			return nil, err
+		}
 		// 配置连接池参数
 		dbClient, err := db.DB()
 		dbClient.SetMaxIdleConns(dbConf.MaxIdleConns) // 设置最大空闲连接数
@@ -105,11 +125,14 @@ func getGormDB(dbConf DBConf) (*gorm.DB, error) {
 			return nil, err
 		}
 		// 构建连接字符串并创建连接
-		dsn := fmt.Sprintf(myDSNTmpl, dbConf.UserName, dbConf.Password, dbConf.Location, dbConf.Port, dbConf.DBName)
+		dsn := mysqlDSN(dbConf, dbConf.DBName)
 		db, err := gorm.Open(mysql.Open(dsn), &gormConfig)
 		if err != nil {
 			return nil, err
 		}
+		if err := registerQueryTimeout(db, dbConf.GetQueryTimeout()); err != nil {
+			return nil, err
+		}
 		// 配置连接池参数
 		dbClient, err := db.DB()
 		dbClient.SetMaxIdleConns(dbConf.MaxIdleConns)
@@ -130,7 +153,7 @@ func getGormDB(dbConf DBConf) (*gorm.DB, error) {
 //   - error: 操作过程中的错误，成功则为nil
 func makeSurePGDBExists(dbConf DBConf) error {
 	// 连接到postgres默认数据库
-	dsn := fmt.Sprintf(pgDSNTmpl, dbConf.Location, dbConf.UserName, dbConf.Password, "postgres", dbConf.Port)
+	dsn := pgDSN(dbConf, "postgres")
 	db, err := gorm.Open(postgres.New(postgres.Config{
 		DSN:                  dsn,
 		PreferSimpleProtocol: false, // 设置为true将禁用隐式预处理语句
@@ -164,7 +187,7 @@ func makeSurePGDBExists(dbConf DBConf) error {
 //   - error: 操作过程中的错误，成功则为nil
 func makeSureMySQLDBExists(dbConf DBConf) error {
 	// 连接到MySQL服务器（不指定数据库）
-	dsn := fmt.Sprintf(myDSNTmpl, dbConf.UserName, dbConf.Password, dbConf.Location, dbConf.Port, "")
+	dsn := mysqlDSN(dbConf, "")
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
 	if err != nil {
 		return fmt.Errorf("根数据库MySQL连接失败：%w", err)
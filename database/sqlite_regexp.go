@@ -0,0 +1,46 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"database/sql"
+
+	"github.com/garrickvan/event-matrix/utils"
+	"github.com/mattn/go-sqlite3"
+	"github.com/spf13/cast"
+)
+
+// sqliteDriverName 注册了 regexp 函数的 SQLite 驱动名称，SQLite 本身不提供 REGEXP 操作符的实现
+const sqliteDriverName = "sqlite3_event_matrix"
+
+func init() {
+	sql.Register(sqliteDriverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("regexp", sqliteRegexp, true)
+		},
+	})
+}
+
+// sqliteRegexp 实现 SQLite 的 X REGEXP Y 操作符，SQLite 会以 regexp(Y, X) 的形式调用，值为 NULL 时不匹配
+func sqliteRegexp(pattern string, value interface{}) (bool, error) {
+	if value == nil {
+		return false, nil
+	}
+	re, err := utils.CompileRegexp(pattern)
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(cast.ToString(value)), nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// 通过 WebSocket 向浏览器推送任务状态，浏览器连接 ws://<host>:<port>/ws/task?id=<任务ID>，
+// 任务状态变化时收到 {"id":"...","status":1} 格式的消息，任务结束后连接关闭
+package main
+
+import (
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker"
+	"github.com/garrickvan/event-matrix/worker/plugins/taskcenter"
+	"github.com/garrickvan/event-matrix/worker/public/wsimpl"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+// taskStatusMessage 推送给浏览器的任务状态
+type taskStatusMessage struct {
+	ID     string          `json:"id"`
+	Status core.TaskStatus `json:"status"`
+}
+
+func main() {
+	wsSvr := wsimpl.NewWebSocketPublicServer()
+	svr := worker.NewTwoWayWorkerServer(worker.TwoWayWorkerServerSettings{
+		CfgKey:                  "demo_worker",
+		PublicServer:            wsSvr,
+		IntranetSecret:          utils.GetEnv("IntranetSecret"),
+		IntranetSecretAlgor:     utils.GetEnv("IntranetSecretAlgor"),
+		GatewayIntranetEndpoint: utils.GetEnv("GatewayIntranetEndpoint"),
+	})
+	// 初始化任务中心插件
+	tc := taskcenter.NewTaskCenter(svr, "sql_task", 500)
+	if err := tc.Setup(); err != nil {
+		logx.Log().Error(err.Error())
+	}
+	// 每秒查询一次任务状态，状态变化时推送，任务结束后关闭连接
+	err := wsSvr.RegisterWSHandler("/ws/task", func(conn wsimpl.WSConn, ctx types.WorkerContext) {
+		hc, ok := ctx.CtxImpl().(*app.RequestContext)
+		if !ok {
+			return
+		}
+		taskID := hc.Query("id")
+		var last *core.TaskStatus
+		for {
+			task := core.Task{}
+			err := svr.Repo().Use(taskcenter.TaskDB).Where("id = ?", taskID).First(&task).Error
+			if err != nil {
+				conn.WriteJSON(map[string]string{"error": "任务不存在"})
+				return
+			}
+			if last == nil || task.Status != *last {
+				if err := conn.WriteJSON(taskStatusMessage{ID: task.ID, Status: task.Status}); err != nil {
+					return
+				}
+				status := task.Status
+				last = &status
+			}
+			if task.Status != core.TaskStatusPending && task.Status != core.TaskStatusInProgress {
+				return
+			}
+			time.Sleep(time.Second)
+		}
+	})
+	if err != nil {
+		logx.Log().Error(err.Error())
+	}
+	// 注册worker
+	if err := svr.RegisterWorker(types.NewWorker("demo", "0.0.1", "user", "user_info", "sql_demo", 60)); err != nil {
+		logx.Log().Error(err.Error())
+	}
+	svr.Start()
+}
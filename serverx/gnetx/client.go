@@ -24,40 +24,44 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/garrickvan/event-matrix/constant"
 	"github.com/garrickvan/event-matrix/serverx"
 	"github.com/garrickvan/event-matrix/utils/buffertool"
-	"github.com/garrickvan/event-matrix/utils/fastconv"
+	"github.com/garrickvan/event-matrix/utils/logx"
 )
 
 const (
 	PING_TIMEOUT = 3 * time.Second // ping包响应超时时间
 )
 
+// ErrMessageTooLarge 请求消息超出最大消息大小限制
+var ErrMessageTooLarge = errors.New("message size exceeds limit")
+
 // gnetConnection 表示一个网络连接，并记录了该连接最后一次使用的时间
 // 用于连接池的连接管理和过期检测
 type gnetConnection struct {
 	net.Conn           // 内嵌标准网络连接
 	lastUsed time.Time // 最后一次使用时间
+	version  uint8     // 与服务端协商的协议版本，新建连接时握手确定
 }
 
-// pingPkg 预先构建的ping请求包，用于发送心跳检测
-// 通过闭包在包初始化时构建，避免重复创建
-func pingPkg() []byte {
+// pingPkg 构建指定协议版本的ping请求包，用于发送心跳检测
+func pingPkg(version uint8) []byte {
 	req := &RequestPacketImpl{
 		PayloadType: serverx.CONTENT_TYPE_PING,
 	}
 	data, _ := req.Marshal()
-	header := buildRpcHeader(data, false)
+	header := buildVersionedRpcHeader(data, COMPRESSION_NONE, version)
 	return append(header, data...)
 }
 
 // Ping 发送ping包到连接并检查响应
 // 用于验证连接是否仍然可用
 func (c *gnetConnection) Ping() error {
-	if _, err := c.Write(pingPkg()); err != nil {
+	if _, err := c.Write(pingPkg(c.version)); err != nil {
 		return err
 	}
 	c.SetReadDeadline(time.Now().Add(PING_TIMEOUT))
@@ -65,7 +69,7 @@ func (c *gnetConnection) Ping() error {
 	if _, err := io.ReadFull(c, receivedHeader); err != nil {
 		return fmt.Errorf("error reading ping response header: %v", err)
 	}
-	length, isCompressed, err := parseHeader(receivedHeader)
+	length, compression, err := parseHeader(receivedHeader)
 	if err != nil {
 		return fmt.Errorf("error parsing ping response header: %v", err)
 	}
@@ -75,7 +79,7 @@ func (c *gnetConnection) Ping() error {
 		return fmt.Errorf("error reading ping message: %v", err)
 	}
 	var response serverx.ResponsePacket
-	response, err = UnPackResponse(bodyBuf, isCompressed)
+	response, err = UnPackResponse(bodyBuf, compression)
 	if err != nil {
 		return fmt.Errorf("error unmarshalling ping response: %v", err)
 	}
@@ -92,6 +96,9 @@ func (c *gnetConnection) Ping() error {
 type endpointPool struct {
 	pool chan *gnetConnection // 连接池通道
 	mu   sync.Mutex           // 互斥锁，保护连接池操作
+
+	created int64 // 累计新建的连接数，原子操作
+	evicted int64 // 累计淘汰的连接数，原子操作
 }
 
 // Client 是一个网络客户端，负责管理连接池和发送请求
@@ -102,7 +109,10 @@ type Client struct {
 	connPools         sync.Map      // 连接池映射，key为endpoint
 	stopChan          chan struct{} // 停止信号通道
 	statementIp       string        // 客户端声明的IP地址
-	compress          bool          // 是否启用压缩
+	compression       COMPRESSION   // 请求使用的压缩算法
+	maxMessageSize    int           // 单条请求消息最大字节数（含消息头），为0时使用 DEFAULT_MAX_MESSAGE_SIZE
+
+	dialer func(endpoint string) (net.Conn, error) // 自定义连接创建方法，为空时使用TCP
 }
 
 // NewClient 创建一个新的Client实例，并初始化连接池清理机制
@@ -136,9 +146,27 @@ func NewClient(maxIdleConns int, connectionExpired time.Duration, writeTimeout t
 	return c
 }
 
-// SetCompress 设置是否启用压缩
+// SetCompress 设置是否启用压缩，启用时使用Snappy压缩
 func (c *Client) SetCompress(compress bool) {
-	c.compress = compress
+	c.compression = compressionOf(compress)
+}
+
+// SetCompression 设置请求使用的压缩算法，服务端按请求的压缩算法返回响应
+func (c *Client) SetCompression(compression COMPRESSION) {
+	c.compression = compression
+}
+
+// SetMaxMessageSize 设置单条请求消息最大字节数（含消息头），应与服务端的限制一致，小于等于0时使用默认值
+func (c *Client) SetMaxMessageSize(size int) {
+	if size <= 0 {
+		size = DEFAULT_MAX_MESSAGE_SIZE
+	}
+	c.maxMessageSize = size
+}
+
+// SetDialer 设置自定义的连接创建方法，用于测试（如 net.Pipe）或自定义网络环境
+func (c *Client) SetDialer(dialer func(endpoint string) (net.Conn, error)) {
+	c.dialer = dialer
 }
 
 // getConn 从连接池获取一个有效的连接，若无则新建连接
@@ -182,6 +210,7 @@ func (c *Client) tryGetSingleConn(pool *endpointPool) *gnetConnection {
 			return conn
 		}
 		conn.Close()
+		atomic.AddInt64(&pool.evicted, 1)
 	}
 	return nil
 }
@@ -206,6 +235,7 @@ func (c *Client) tryBatchConn(pool *endpointPool, batchSize int) *gnetConnection
 			}
 		} else {
 			conn.Close()
+			atomic.AddInt64(&pool.evicted, 1)
 		}
 	}
 
@@ -215,6 +245,7 @@ func (c *Client) tryBatchConn(pool *endpointPool, batchSize int) *gnetConnection
 		case pool.pool <- conn:
 		default:
 			conn.Close()
+			atomic.AddInt64(&pool.evicted, 1)
 		}
 	}
 
@@ -224,14 +255,28 @@ func (c *Client) tryBatchConn(pool *endpointPool, batchSize int) *gnetConnection
 // createNewConn 创建新连接
 // 当连接池中无可用连接时，创建一个新的TCP连接
 func (c *Client) createNewConn(endpoint string) (*gnetConnection, error) {
-	rawConn, err := net.DialTimeout("tcp", endpoint, 5*time.Second)
+	var rawConn net.Conn
+	var err error
+	if c.dialer != nil {
+		rawConn, err = c.dialer(endpoint)
+	} else {
+		rawConn, err = net.DialTimeout("tcp", endpoint, 5*time.Second)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("error connecting to server: %v", err)
 	}
-	return &gnetConnection{
+	conn := &gnetConnection{
 		Conn:     rawConn,
 		lastUsed: time.Now(),
-	}, nil
+	}
+	if err := conn.handshake(); err != nil {
+		rawConn.Close()
+		return nil, fmt.Errorf("error negotiating protocol version: %v", err)
+	}
+	if poolAny, ok := c.connPools.Load(endpoint); ok {
+		atomic.AddInt64(&poolAny.(*endpointPool).created, 1)
+	}
+	return conn, nil
 }
 
 // putConn 将连接放回连接池，若连接池已满则关闭连接
@@ -251,6 +296,7 @@ func (c *Client) putConn(endpoint string, conn *gnetConnection) {
 	case pool.pool <- conn:
 	default:
 		conn.Close()
+		atomic.AddInt64(&pool.evicted, 1)
 	}
 }
 
@@ -286,6 +332,7 @@ func (c *Client) cleanup() {
 				validConns = append(validConns, conn)
 			} else {
 				conn.Close()
+				atomic.AddInt64(&pool.evicted, 1)
 			}
 		}
 
@@ -297,6 +344,7 @@ func (c *Client) cleanup() {
 				case pool.pool <- conn:
 				default:
 					conn.Close()
+					atomic.AddInt64(&pool.evicted, 1)
 				}
 			}
 		}
@@ -346,11 +394,11 @@ func (c *Client) Post(endpoint string, typz serverx.CONTENT_TYPE, payload []byte
 	msg := &RequestPacketImpl{
 		PayloadType: typz,
 		XData:       xdata,
-		Payload:     fastconv.BytesToString(payload),
 		SourceIP:    c.statementIp,
 		CallChain:   strings.Join(callChain, constant.SPLIT_CHAR),
 	}
-	response, err = c.sendRequest(endpoint, msg, c.compress)
+	msg.SetBody(payload)
+	response, err = c.sendRequest(endpoint, msg, c.compression)
 	if response == nil && err == nil {
 		return nil, errors.New("nil response")
 	}
@@ -365,12 +413,12 @@ func (c *Client) Post(endpoint string, typz serverx.CONTENT_TYPE, payload []byte
 // 参数：
 //   - endpoint: 目标端点地址
 //   - msg: 请求消息
-//   - compressed: 是否启用压缩
+//   - compression: 压缩算法
 //
 // 返回值：
 //   - *ResponsePacketImpl: 响应消息
 //   - error: 错误信息
-func (c *Client) sendRequest(endpoint string, msg *RequestPacketImpl, compressed bool) (serverx.ResponsePacket, error) {
+func (c *Client) sendRequest(endpoint string, msg *RequestPacketImpl, compression COMPRESSION) (serverx.ResponsePacket, error) {
 	conn, err := c.getConn(endpoint)
 	if err != nil {
 		return nil, err
@@ -386,7 +434,7 @@ func (c *Client) sendRequest(endpoint string, msg *RequestPacketImpl, compressed
 		}
 	}()
 
-	resp, err := send(conn.Conn, msg, compressed, c.writeTimeout)
+	resp, err := send(conn.Conn, msg, compression, conn.version, c.writeTimeout, c.maxMessageSize)
 	if err != nil {
 		return nil, err
 	}
@@ -398,18 +446,29 @@ func (c *Client) sendRequest(endpoint string, msg *RequestPacketImpl, compressed
 // 参数：
 //   - conn: 网络连接
 //   - msg: 请求消息
-//   - compressed: 是否启用压缩
+//   - compression: 压缩算法
+//   - version: 协议版本，为连接上协商的版本
 //   - timeout: 超时时间
+//   - maxSize: 请求消息最大字节数（含消息头），小于等于0时使用 DEFAULT_MAX_MESSAGE_SIZE
 //
 // 返回值：
 //   - *ResponsePacketImpl: 响应消息
-//   - error: 错误信息
-func send(conn net.Conn, msg *RequestPacketImpl, compressed bool, timeout time.Duration) (serverx.ResponsePacket, error) {
+//   - error: 错误信息，消息超出大小限制时为 ErrMessageTooLarge，此时不发送
+func send(conn net.Conn, msg *RequestPacketImpl, compression COMPRESSION, version uint8, timeout time.Duration, maxSize int) (serverx.ResponsePacket, error) {
+	var msgBytes []byte = msg.PackWith(compression)
+	if maxSize <= 0 {
+		maxSize = DEFAULT_MAX_MESSAGE_SIZE
+	}
+	// 超出服务端限制的消息会被直接断开，发送前拦截
+	if size := HEADER_LEN + len(msgBytes); size > maxSize {
+		logx.Error(fmt.Sprintf("Request to %s is %d bytes, exceeds max message size %d bytes, not sent", conn.RemoteAddr(), size, maxSize))
+		return nil, fmt.Errorf("%w: %d bytes, limit %d bytes", ErrMessageTooLarge, size, maxSize)
+	}
+
 	conn.SetDeadline(time.Now().Add(timeout))
 	defer conn.SetDeadline(time.Time{})
 
-	var msgBytes []byte = msg.Pack(compressed)
-	sendHeader := buildRpcHeader(msgBytes, compressed)
+	sendHeader := buildVersionedRpcHeader(msgBytes, compression, version)
 
 	if _, err := conn.Write(sendHeader); err != nil {
 		return nil, fmt.Errorf("error writing header: %v", err)
@@ -429,7 +488,7 @@ func send(conn net.Conn, msg *RequestPacketImpl, compressed bool, timeout time.D
 	if _, err := io.ReadFull(conn, receivedHeader); err != nil {
 		return nil, fmt.Errorf("error reading response header: %v", err)
 	}
-	length, isCompressed, err := parseHeader(receivedHeader)
+	length, compression, err := parseHeader(receivedHeader)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing response header: %v", err)
 	}
@@ -441,7 +500,7 @@ func send(conn net.Conn, msg *RequestPacketImpl, compressed bool, timeout time.D
 		return nil, fmt.Errorf("error reading response message: %v", err)
 	}
 
-	resp, err := UnPackResponse(bodyBuf, isCompressed)
+	resp, err := UnPackResponse(bodyBuf, compression)
 	if err != nil {
 		return nil, err
 	}
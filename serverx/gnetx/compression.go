@@ -0,0 +1,136 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnetx
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/golang/snappy"
+)
+
+// COMPRESSION 消息体压缩算法，对应消息头的压缩标志字节
+type COMPRESSION uint8
+
+const (
+	// COMPRESSION_NONE 不压缩
+	COMPRESSION_NONE COMPRESSION = 0x00
+	// COMPRESSION_SNAPPY Snappy压缩，旧版本客户端的压缩方式
+	COMPRESSION_SNAPPY COMPRESSION = 0x01
+	// COMPRESSION_GZIP Gzip压缩，便于非Go客户端接入
+	COMPRESSION_GZIP COMPRESSION = 0x02
+)
+
+// compressionOf 将是否压缩转换为压缩算法，压缩时使用Snappy，兼容旧接口
+func compressionOf(compressed bool) COMPRESSION {
+	if compressed {
+		return COMPRESSION_SNAPPY
+	}
+	return COMPRESSION_NONE
+}
+
+// valid 判断是否为支持的压缩算法
+func (c COMPRESSION) valid() bool {
+	switch c {
+	case COMPRESSION_NONE, COMPRESSION_SNAPPY, COMPRESSION_GZIP:
+		return true
+	default:
+		return false
+	}
+}
+
+// String 返回压缩算法名称
+func (c COMPRESSION) String() string {
+	switch c {
+	case COMPRESSION_NONE:
+		return "none"
+	case COMPRESSION_SNAPPY:
+		return "snappy"
+	case COMPRESSION_GZIP:
+		return "gzip"
+	default:
+		return fmt.Sprintf("unknown(0x%02x)", uint8(c))
+	}
+}
+
+// compressData 按压缩算法压缩数据
+func compressData(data []byte, c COMPRESSION) ([]byte, error) {
+	switch c {
+	case COMPRESSION_NONE:
+		return data, nil
+	case COMPRESSION_SNAPPY:
+		return snappy.Encode(nil, data), nil
+	case COMPRESSION_GZIP:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, errors.New("unsupported compression: " + c.String())
+	}
+}
+
+// decompressData 按压缩算法解压数据，limit 大于0时解压后超过 limit 字节返回 ErrMessageTooLarge，
+// 防止体积很小的压缩包解压后耗尽内存
+func decompressData(data []byte, c COMPRESSION, limit int) ([]byte, error) {
+	switch c {
+	case COMPRESSION_NONE:
+		return data, nil
+	case COMPRESSION_SNAPPY:
+		if limit > 0 {
+			n, err := snappy.DecodedLen(data)
+			if err != nil {
+				return nil, fmt.Errorf("snappy decompress failed: %v", err)
+			}
+			if n > limit {
+				return nil, fmt.Errorf("%w: decompressed size %d bytes, limit %d bytes", ErrMessageTooLarge, n, limit)
+			}
+		}
+		out, err := snappy.Decode(nil, data)
+		if err != nil {
+			return nil, fmt.Errorf("snappy decompress failed: %v", err)
+		}
+		return out, nil
+	case COMPRESSION_GZIP:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("gzip decompress failed: %v", err)
+		}
+		defer r.Close()
+		var reader io.Reader = r
+		if limit > 0 {
+			// 多读1字节用于判断是否超出限制
+			reader = io.LimitReader(r, int64(limit)+1)
+		}
+		out, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("gzip decompress failed: %v", err)
+		}
+		if limit > 0 && len(out) > limit {
+			return nil, fmt.Errorf("%w: decompressed size exceeds %d bytes", ErrMessageTooLarge, limit)
+		}
+		return out, nil
+	default:
+		return nil, errors.New("unsupported compression: " + c.String())
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnetx
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/garrickvan/event-matrix/serverx"
+	"github.com/panjf2000/gnet/v2"
+)
+
+// captureConn 记录异步写入数据的测试连接
+type captureConn struct {
+	gnet.Conn
+	mu  sync.Mutex
+	out []byte
+}
+
+func (c *captureConn) Context() interface{} { return nil }
+
+func (c *captureConn) AsyncWrite(buf []byte, callback gnet.AsyncCallback) error {
+	c.mu.Lock()
+	c.out = append([]byte(nil), buf...)
+	c.mu.Unlock()
+	return nil
+}
+
+func TestResponsePacketCompressionRoundTrip(t *testing.T) {
+	for _, compression := range []COMPRESSION{COMPRESSION_NONE, COMPRESSION_SNAPPY, COMPRESSION_GZIP} {
+		resp := &ResponsePacketImpl{StatusCode: http.StatusOK, Payload: `{"hello":"world"}`}
+		got, err := UnPackResponse(resp.PackWith(compression), compression)
+		if err != nil {
+			t.Fatalf("%s 解包失败: %v", compression, err)
+		}
+		if got.Status() != http.StatusOK || got.TemporaryData() != resp.Payload {
+			t.Errorf("%s 响应解包错误: %d %s", compression, got.Status(), got.TemporaryData())
+		}
+	}
+}
+
+func TestDecompressDataLimit(t *testing.T) {
+	// 高压缩比的数据，压缩后远小于限制，解压后超出限制
+	raw := bytes.Repeat([]byte("a"), 64*1024)
+	limit := 8 * 1024
+	for _, compression := range []COMPRESSION{COMPRESSION_SNAPPY, COMPRESSION_GZIP} {
+		data, err := compressData(raw, compression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) >= limit {
+			t.Fatalf("%s 压缩后数据过大: %d", compression, len(data))
+		}
+		if _, err := decompressData(data, compression, limit); !errors.Is(err, ErrMessageTooLarge) {
+			t.Errorf("%s 解压超出限制应返回 ErrMessageTooLarge，实际 %v", compression, err)
+		}
+		if out, err := decompressData(data, compression, len(raw)); err != nil || len(out) != len(raw) {
+			t.Errorf("%s 未超出限制应正常解压: %d %v", compression, len(out), err)
+		}
+	}
+}
+
+func TestParseHeaderCompressionFlag(t *testing.T) {
+	for _, compression := range []COMPRESSION{COMPRESSION_NONE, COMPRESSION_SNAPPY, COMPRESSION_GZIP} {
+		_, got, err := parseHeader(buildRpcHeader([]byte("data"), compression))
+		if err != nil || got != compression {
+			t.Errorf("压缩标志 %s 解析错误: %s %v", compression, got, err)
+		}
+	}
+	if _, _, err := parseHeader(buildRpcHeader([]byte("data"), COMPRESSION(0x03))); err == nil {
+		t.Error("未知的压缩标志应返回错误")
+	}
+}
+
+func TestAsyncProcessMirrorsCompression(t *testing.T) {
+	s := NewIntranetServer("compression", 0, "", "NONE",
+		func(req serverx.RequestPacket, c gnet.Conn, routerImpl interface{}) serverx.ResponsePacket {
+			return &ResponsePacketImpl{StatusCode: http.StatusOK, Payload: req.TemporaryData()}
+		}, nil)
+	for _, compression := range []COMPRESSION{COMPRESSION_NONE, COMPRESSION_SNAPPY, COMPRESSION_GZIP} {
+		conn := &captureConn{}
+		pkg := &RequestPacketImpl{PayloadType: serverx.CONTENT_TYPE_JSON, Payload: `{"k":"v"}`, XData: "1"}
+		data := pkg.PackWith(compression)
+		s.asyncProcess(conn, append(buildRpcHeader(data, compression), data...), func() {}, compression)
+
+		_, respCompression, err := parseHeader(conn.out[:HEADER_LEN])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if respCompression != compression {
+			t.Errorf("响应应使用请求的压缩算法 %s，实际 %s", compression, respCompression)
+		}
+		resp, err := UnPackResponse(conn.out[HEADER_LEN:], respCompression)
+		if err != nil || resp.Status() != http.StatusOK || resp.TemporaryData() != `{"k":"v"}` {
+			t.Errorf("%s 响应错误: %v %v", compression, resp, err)
+		}
+	}
+}
@@ -36,7 +36,7 @@ var testPacket = &RequestPacketImpl{
 func BenchmarkUnPackRequestPacket(b *testing.B) {
 	data := testPacket.Pack(false)
 	for i := 0; i < b.N; i++ {
-		_, err := UnPackRequest(data, false)
+		_, err := UnPackRequest(data, COMPRESSION_NONE, 0, 0)
 		if err != nil {
 			b.Fatalf("UnPackRequestPacket failed: %v", err)
 		}
@@ -57,7 +57,7 @@ func BenchmarkUnPackRequestCompressed(b *testing.B) {
 	data := testPacket.Pack(true)
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err := UnPackRequest(data, true)
+		_, err := UnPackRequest(data, COMPRESSION_SNAPPY, 0, 0)
 		if err != nil {
 			b.Fatalf("UnPackRequestCompressed failed: %v", err)
 		}
@@ -138,7 +138,7 @@ func TestSend(t *testing.T) {
 	}
 
 	// 调用被测试函数
-	resp, err := send(conn, &req, true, 5*time.Second)
+	resp, err := send(conn, &req, COMPRESSION_SNAPPY, PROTOCOL_VERSION, 5*time.Second, 0)
 	if err != nil {
 		t.Fatalf("Send failed: %v", err)
 	}
@@ -0,0 +1,156 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnetx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/garrickvan/event-matrix/serverx"
+	"github.com/garrickvan/event-matrix/utils/buffertool"
+	"github.com/panjf2000/gnet/v2"
+)
+
+const (
+	MIN_PROTOCOL_VERSION = 1               // 支持的最低协议版本号
+	HANDSHAKE_TIMEOUT    = 3 * time.Second // 版本协商响应超时时间
+)
+
+// connState 服务端连接的用户数据，记录连接上使用的协议版本
+type connState struct {
+	version uint32 // 连接使用的协议版本，为0时尚未确定
+}
+
+// connStateOf 返回连接的状态，连接未初始化用户数据时返回nil
+func connStateOf(c gnet.Conn) *connState {
+	state, _ := c.Context().(*connState)
+	return state
+}
+
+// connVersion 返回连接上响应使用的协议版本：优先使用协商结果，其次使用客户端首个请求的版本，否则使用当前版本
+func connVersion(c gnet.Conn) uint8 {
+	if state := connStateOf(c); state != nil {
+		if v := atomic.LoadUint32(&state.version); v != 0 {
+			return uint8(v)
+		}
+	}
+	return PROTOCOL_VERSION
+}
+
+// versionSupported 判断协议版本是否在支持范围内
+func versionSupported(version uint8) bool {
+	return version >= MIN_PROTOCOL_VERSION && version <= PROTOCOL_VERSION
+}
+
+// negotiateVersion 根据客户端支持的版本范围选择双方都支持的最高版本
+func negotiateVersion(min, max uint8) (uint8, error) {
+	if min > max {
+		return 0, fmt.Errorf("invalid protocol version range: %d-%d", min, max)
+	}
+	agreed := max
+	if agreed > PROTOCOL_VERSION {
+		agreed = PROTOCOL_VERSION
+	}
+	if agreed < min || agreed < MIN_PROTOCOL_VERSION {
+		return 0, fmt.Errorf("no common protocol version, client %d-%d, server %d-%d",
+			min, max, MIN_PROTOCOL_VERSION, PROTOCOL_VERSION)
+	}
+	return agreed, nil
+}
+
+// formatVersionRange 将版本范围编码为握手请求的扩展数据，格式为 "最低版本,最高版本"
+func formatVersionRange(min, max uint8) string {
+	return strconv.Itoa(int(min)) + "," + strconv.Itoa(int(max))
+}
+
+// parseVersionRange 解析握手请求扩展数据中的版本范围
+func parseVersionRange(xdata string) (uint8, uint8, error) {
+	parts := strings.Split(xdata, ",")
+	if len(parts) != 2 {
+		return 0, 0, errors.New("invalid protocol version range")
+	}
+	min, err := strconv.ParseUint(strings.TrimSpace(parts[0]), 10, 8)
+	if err != nil {
+		return 0, 0, errors.New("invalid protocol version range")
+	}
+	max, err := strconv.ParseUint(strings.TrimSpace(parts[1]), 10, 8)
+	if err != nil {
+		return 0, 0, errors.New("invalid protocol version range")
+	}
+	return uint8(min), uint8(max), nil
+}
+
+// handshakeResponse 处理版本协商请求，返回响应和协商的版本，协商失败时版本为0
+// 协商的版本通过响应消息头的协议版本字节告知客户端，响应不带负载，无需加密
+func handshakeResponse(req serverx.RequestPacket) (serverx.ResponsePacket, uint8) {
+	min, max, err := parseVersionRange(req.Extend())
+	if err == nil {
+		var agreed uint8
+		if agreed, err = negotiateVersion(min, max); err == nil {
+			return &ResponsePacketImpl{StatusCode: http.StatusOK}, agreed
+		}
+	}
+	return &ResponsePacketImpl{
+		StatusCode:  http.StatusUpgradeRequired,
+		ContentType: serverx.CONTENT_TYPE_STRING,
+		Payload:     err.Error(),
+	}, 0
+}
+
+// handshake 与服务端协商协议版本，结果记录在连接上，后续请求使用协商的版本
+// 握手请求使用最低版本的消息头，旧版本服务端不认识握手请求时按其响应头的版本继续通信
+func (c *gnetConnection) handshake() error {
+	req := &RequestPacketImpl{
+		PayloadType: serverx.CONTENT_TYPE_HANDSHAKE,
+		XData:       formatVersionRange(MIN_PROTOCOL_VERSION, PROTOCOL_VERSION),
+	}
+	data, err := req.Marshal()
+	if err != nil {
+		return err
+	}
+	c.SetDeadline(time.Now().Add(HANDSHAKE_TIMEOUT))
+	defer c.SetDeadline(time.Time{})
+	if _, err := c.Write(append(buildVersionedRpcHeader(data, COMPRESSION_NONE, MIN_PROTOCOL_VERSION), data...)); err != nil {
+		return err
+	}
+	receivedHeader := make([]byte, HEADER_LEN)
+	if _, err := io.ReadFull(c, receivedHeader); err != nil {
+		return fmt.Errorf("error reading handshake response header: %v", err)
+	}
+	length, compression, version, err := parseVersionedHeader(receivedHeader)
+	if err != nil {
+		return fmt.Errorf("error parsing handshake response header: %v", err)
+	}
+	bodyBuf, release := buffertool.GetBuffer(int(length))
+	defer release()
+	if _, err := io.ReadFull(c, bodyBuf); err != nil {
+		return fmt.Errorf("error reading handshake response: %v", err)
+	}
+	resp, err := UnPackResponse(bodyBuf, compression)
+	if err != nil {
+		return fmt.Errorf("error unmarshalling handshake response: %v", err)
+	}
+	if resp != nil && resp.Status() == http.StatusUpgradeRequired {
+		return fmt.Errorf("protocol version negotiation failed: %s", resp.TemporaryData())
+	}
+	c.version = version
+	return nil
+}
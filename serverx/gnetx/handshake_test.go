@@ -0,0 +1,143 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnetx
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/garrickvan/event-matrix/serverx"
+	"github.com/panjf2000/gnet/v2"
+)
+
+func TestNegotiateVersion(t *testing.T) {
+	if v, err := negotiateVersion(MIN_PROTOCOL_VERSION, PROTOCOL_VERSION+3); err != nil || v != PROTOCOL_VERSION {
+		t.Fatalf("较新的客户端应协商为服务端最高版本: %d %v", v, err)
+	}
+	if v, err := negotiateVersion(MIN_PROTOCOL_VERSION, MIN_PROTOCOL_VERSION); err != nil || v != MIN_PROTOCOL_VERSION {
+		t.Fatalf("旧客户端应协商为其最高版本: %d %v", v, err)
+	}
+	if _, err := negotiateVersion(PROTOCOL_VERSION+1, PROTOCOL_VERSION+2); err == nil {
+		t.Fatal("没有共同版本时应协商失败")
+	}
+	if _, err := negotiateVersion(2, 1); err == nil {
+		t.Fatal("非法的版本范围应协商失败")
+	}
+}
+
+func TestHeaderVersionRange(t *testing.T) {
+	data := []byte("data")
+	if _, _, v, err := parseVersionedHeader(buildVersionedRpcHeader(data, COMPRESSION_NONE, MIN_PROTOCOL_VERSION)); err != nil || v != MIN_PROTOCOL_VERSION {
+		t.Fatalf("支持范围内的版本应解析成功: %d %v", v, err)
+	}
+	if _, _, err := parseHeader(buildVersionedRpcHeader(data, COMPRESSION_NONE, PROTOCOL_VERSION+1)); err == nil {
+		t.Fatal("超出支持范围的版本应解析失败")
+	}
+}
+
+func TestClientHandshakeWithServeConn(t *testing.T) {
+	client := NewClient(1, time.Minute, time.Second)
+	defer client.Close()
+	client.SetDialer(func(endpoint string) (net.Conn, error) {
+		clientConn, serverConn := net.Pipe()
+		go ServeConn(serverConn, "", "NONE", func(req serverx.RequestPacket) serverx.ResponsePacket {
+			return &ResponsePacketImpl{StatusCode: http.StatusOK}
+		})
+		return clientConn, nil
+	})
+	conn, err := client.getConn("pipe")
+	if err != nil {
+		t.Fatalf("建立连接失败: %v", err)
+	}
+	defer conn.Close()
+	if conn.version != PROTOCOL_VERSION {
+		t.Fatalf("协商的版本错误: %d", conn.version)
+	}
+	if err := conn.Ping(); err != nil {
+		t.Fatalf("协商后ping失败: %v", err)
+	}
+}
+
+// versionConn 记录异步写入数据并保存用户数据的测试连接
+type versionConn struct {
+	gnet.Conn
+	ctx interface{}
+	out []byte
+}
+
+func (c *versionConn) Context() interface{}       { return c.ctx }
+func (c *versionConn) SetContext(ctx interface{}) { c.ctx = ctx }
+
+func (c *versionConn) AsyncWrite(buf []byte, callback gnet.AsyncCallback) error {
+	c.out = append([]byte(nil), buf...)
+	return nil
+}
+
+func TestServerHandshake(t *testing.T) {
+	s := NewIntranetServer("handshake", 0, "", "NONE", nil, nil)
+	conn := &versionConn{ctx: &connState{}}
+
+	req := &RequestPacketImpl{
+		PayloadType: serverx.CONTENT_TYPE_HANDSHAKE,
+		XData:       formatVersionRange(MIN_PROTOCOL_VERSION, PROTOCOL_VERSION+1),
+	}
+	data, _ := req.Marshal()
+	s.asyncProcess(conn, append(buildRpcHeader(data, COMPRESSION_NONE), data...), func() {}, COMPRESSION_NONE)
+	_, _, version, err := parseVersionedHeader(conn.out[:HEADER_LEN])
+	if err != nil || version != PROTOCOL_VERSION {
+		t.Fatalf("握手响应头应携带协商的版本: %d %v", version, err)
+	}
+	if connVersion(conn) != PROTOCOL_VERSION {
+		t.Fatalf("协商的版本应记录在连接上: %d", connVersion(conn))
+	}
+
+	// 没有共同版本时返回426
+	req.XData = formatVersionRange(PROTOCOL_VERSION+1, PROTOCOL_VERSION+2)
+	data, _ = req.Marshal()
+	s.asyncProcess(conn, append(buildRpcHeader(data, COMPRESSION_NONE), data...), func() {}, COMPRESSION_NONE)
+	resp, err := UnPackResponse(conn.out[HEADER_LEN:], COMPRESSION_NONE)
+	if err != nil || resp.Status() != http.StatusUpgradeRequired {
+		t.Fatalf("协商失败应返回426: %v %v", resp, err)
+	}
+}
+
+func TestClientMaxMessageSize(t *testing.T) {
+	client := NewClient(1, time.Minute, time.Second)
+	defer client.Close()
+	var handled int32
+	client.SetDialer(func(endpoint string) (net.Conn, error) {
+		clientConn, serverConn := net.Pipe()
+		go ServeConn(serverConn, "", "NONE", func(req serverx.RequestPacket) serverx.ResponsePacket {
+			atomic.AddInt32(&handled, 1)
+			return &ResponsePacketImpl{StatusCode: http.StatusOK}
+		})
+		return clientConn, nil
+	})
+	client.SetMaxMessageSize(256)
+
+	if _, err := client.Post("pipe", serverx.CONTENT_TYPE_STRING, make([]byte, 512), "", nil); !errors.Is(err, ErrMessageTooLarge) {
+		t.Fatalf("超出大小限制的请求应返回 ErrMessageTooLarge: %v", err)
+	}
+	if resp, err := client.Post("pipe", serverx.CONTENT_TYPE_STRING, []byte("ok"), "", nil); err != nil || resp.Status() != http.StatusOK {
+		t.Fatalf("未超出限制的请求应正常发送: %v %v", resp, err)
+	}
+	if n := atomic.LoadInt32(&handled); n != 1 {
+		t.Fatalf("超出限制的请求不应发送到服务端，服务端处理了 %d 个请求", n)
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnetx
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/serverx"
+	"github.com/garrickvan/event-matrix/utils/encryptx"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+)
+
+// LoadTestConfig 流量回放配置
+type LoadTestConfig struct {
+	Endpoint    string        // 目标服务地址，如 127.0.0.1:8001
+	File        string        // RecordInterceptor 录制的JSONL流量文件
+	Concurrency int           // 并发数，默认1
+	QPS         int           // 每秒请求数上限，0表示不限制
+	Rounds      int           // 流量文件回放轮数，默认1
+	Secret      string        // 内域通信密钥，录制的数据为明文，回放时重新加密
+	Algor       string        // 内域通信加密算法
+	Compress    bool          // 是否启用压缩
+	Timeout     time.Duration // 单个请求超时时间，默认30秒
+}
+
+// LoadTestReport 流量回放结果
+type LoadTestReport struct {
+	Total      int           // 请求总数
+	Errors     int           // 失败数，包括网络错误和状态码不小于400的响应
+	Duration   time.Duration // 总耗时
+	Throughput float64       // 吞吐量，单位请求/秒
+	ErrorRate  float64       // 错误率
+	P50        time.Duration // 延迟中位数
+	P95        time.Duration // 95分位延迟
+	P99        time.Duration // 99分位延迟
+}
+
+// String 输出可读的压测报告
+func (r *LoadTestReport) String() string {
+	return fmt.Sprintf(
+		"total: %d, errors: %d, error rate: %.2f%%, duration: %s, throughput: %.2f req/s, p50: %s, p95: %s, p99: %s",
+		r.Total, r.Errors, r.ErrorRate*100, r.Duration, r.Throughput, r.P50, r.P95, r.P99,
+	)
+}
+
+// LoadTester 流量回放工具，按配置的并发数和QPS回放录制的请求并统计延迟
+type LoadTester struct {
+	cfg  LoadTestConfig
+	send func(req *RequestPacketImpl) (serverx.ResponsePacket, error)
+}
+
+// NewLoadTester 创建流量回放工具
+func NewLoadTester(cfg LoadTestConfig) *LoadTester {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.Rounds <= 0 {
+		cfg.Rounds = 1
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	lt := &LoadTester{cfg: cfg}
+	client := NewClient(cfg.Concurrency, 5*time.Minute, cfg.Timeout)
+	client.SetCompress(cfg.Compress)
+	lt.send = func(req *RequestPacketImpl) (serverx.ResponsePacket, error) {
+		payload, err := encryptx.Encrypt(req.RawBody(), cfg.Secret, cfg.Algor)
+		if err != nil {
+			return nil, err
+		}
+		var callChain []string
+		if req.CallChain != "" {
+			callChain = strings.Split(req.CallChain, constant.SPLIT_CHAR)
+		}
+		return client.Post(cfg.Endpoint, req.PayloadType, payload, req.XData, callChain)
+	}
+	return lt
+}
+
+// LoadRecords 读取JSONL格式的流量文件，跳过空行，格式错误时返回所在行号
+func LoadRecords(file string) ([]*RequestPacketImpl, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	records := make([]*RequestPacketImpl, 0)
+	scanner := bufio.NewScanner(f)
+	// 序列化后的请求可能因转义超过单个请求的大小限制，预留更大的行缓冲
+	scanner.Buffer(make([]byte, 64*1024), 4*DEFAULT_MAX_MESSAGE_SIZE)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		record := &RequestPacketImpl{}
+		if err := jsonx.UnmarshalFromStr(line, record); err != nil {
+			return nil, fmt.Errorf("invalid record at line %d: %w", lineNo, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Run 执行流量回放，ctx 取消时停止发送新的请求并返回已完成部分的统计结果
+func (lt *LoadTester) Run(ctx context.Context) (*LoadTestReport, error) {
+	records, err := LoadRecords(lt.cfg.File)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("no records in traffic file")
+	}
+	return lt.replay(ctx, records), nil
+}
+
+func (lt *LoadTester) replay(ctx context.Context, records []*RequestPacketImpl) *LoadTestReport {
+	jobs := make(chan *RequestPacketImpl, lt.cfg.Concurrency)
+	var (
+		mu        sync.Mutex
+		latencies = make([]time.Duration, 0, len(records)*lt.cfg.Rounds)
+		errCount  int
+		wg        sync.WaitGroup
+	)
+	for i := 0; i < lt.cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for req := range jobs {
+				// 每次发送使用副本，避免发送时改写时间戳影响其他协程
+				msg := *req
+				start := time.Now()
+				resp, err := lt.send(&msg)
+				cost := time.Since(start)
+				mu.Lock()
+				latencies = append(latencies, cost)
+				if err != nil || resp == nil || resp.Status() >= 400 {
+					errCount++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	var ticker *time.Ticker
+	if lt.cfg.QPS > 0 {
+		ticker = time.NewTicker(time.Second / time.Duration(lt.cfg.QPS))
+		defer ticker.Stop()
+	}
+	begin := time.Now()
+dispatch:
+	for round := 0; round < lt.cfg.Rounds; round++ {
+		for _, req := range records {
+			if ticker != nil {
+				select {
+				case <-ctx.Done():
+					break dispatch
+				case <-ticker.C:
+				}
+			}
+			select {
+			case <-ctx.Done():
+				break dispatch
+			case jobs <- req:
+			}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return buildLoadTestReport(latencies, errCount, time.Since(begin))
+}
+
+// buildLoadTestReport 根据延迟样本计算统计结果
+func buildLoadTestReport(latencies []time.Duration, errCount int, duration time.Duration) *LoadTestReport {
+	report := &LoadTestReport{
+		Total:    len(latencies),
+		Errors:   errCount,
+		Duration: duration,
+	}
+	if report.Total == 0 {
+		return report
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	report.P50 = percentile(latencies, 0.50)
+	report.P95 = percentile(latencies, 0.95)
+	report.P99 = percentile(latencies, 0.99)
+	report.ErrorRate = float64(errCount) / float64(report.Total)
+	if duration > 0 {
+		report.Throughput = float64(report.Total) / duration.Seconds()
+	}
+	return report
+}
+
+// percentile 使用最近秩法计算分位数，sorted 需已升序排列
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	idx := int(math.Ceil(float64(len(sorted))*p)) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
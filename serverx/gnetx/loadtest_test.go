@@ -0,0 +1,153 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnetx
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/garrickvan/event-matrix/serverx"
+)
+
+func TestRecordInterceptorRotate(t *testing.T) {
+	dir := t.TempDir()
+	recorder := NewRecordInterceptor(dir, 200)
+
+	// 未启用时不录制
+	recorder.Record(testPacket)
+	if files, _ := filepath.Glob(filepath.Join(dir, "*.jsonl")); len(files) != 0 {
+		t.Fatalf("未启用时不应生成流量文件: %v", files)
+	}
+
+	recorder.Enable()
+	recorder.Record(&RequestPacketImpl{PayloadType: serverx.CONTENT_TYPE_PING})
+	for i := 0; i < 3; i++ {
+		recorder.Record(testPacket)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if len(files) != 3 {
+		t.Fatalf("单条记录超过文件上限时每条记录应单独成文件，实际 %d 个文件", len(files))
+	}
+	total := 0
+	for _, f := range files {
+		records, err := LoadRecords(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range records {
+			if r.Payload != testPacket.Payload || r.CallChain != testPacket.CallChain {
+				t.Errorf("录制内容不一致: %+v", r)
+			}
+		}
+		total += len(records)
+	}
+	if total != 3 {
+		t.Errorf("期望录制3条请求，实际 %d", total)
+	}
+}
+
+func TestLoadRecordsInvalidLine(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "traffic.jsonl")
+	content := `{"pt":1,"pl":"{}"}` + "\n\n" + "not json\n"
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadRecords(file); err == nil {
+		t.Fatal("格式错误的记录应返回错误")
+	}
+}
+
+func TestLoadTesterReplay(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "traffic.jsonl")
+	recorder := NewRecordInterceptor(filepath.Dir(file), 0)
+	recorder.Enable()
+	for i := 0; i < 10; i++ {
+		recorder.Record(testPacket)
+	}
+	recorder.Close()
+	files, _ := filepath.Glob(filepath.Join(filepath.Dir(file), "traffic.*.jsonl"))
+	if len(files) != 1 {
+		t.Fatalf("期望1个流量文件，实际 %d", len(files))
+	}
+
+	lt := NewLoadTester(LoadTestConfig{File: files[0], Concurrency: 4, Rounds: 2})
+	var calls int64
+	lt.send = func(req *RequestPacketImpl) (serverx.ResponsePacket, error) {
+		n := atomic.AddInt64(&calls, 1)
+		if req.Payload != testPacket.Payload {
+			return nil, errors.New("unexpected payload")
+		}
+		switch {
+		case n%5 == 0:
+			return nil, errors.New("network error")
+		case n%10 == 1:
+			return &ResponsePacketImpl{StatusCode: 500}, nil
+		}
+		return &ResponsePacketImpl{StatusCode: 200}, nil
+	}
+
+	report, err := lt.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Total != 20 || calls != 20 {
+		t.Fatalf("期望回放20次，实际 total=%d calls=%d", report.Total, calls)
+	}
+	if report.Errors != 6 {
+		t.Errorf("期望6次失败，实际 %d", report.Errors)
+	}
+	if report.ErrorRate != 0.3 {
+		t.Errorf("错误率计算错误: %v", report.ErrorRate)
+	}
+	if report.P50 > report.P95 || report.P95 > report.P99 || report.Throughput <= 0 {
+		t.Errorf("统计结果异常: %s", report)
+	}
+}
+
+func TestLoadTesterQPSLimit(t *testing.T) {
+	lt := NewLoadTester(LoadTestConfig{Concurrency: 2, QPS: 50})
+	lt.send = func(req *RequestPacketImpl) (serverx.ResponsePacket, error) {
+		return &ResponsePacketImpl{StatusCode: 200}, nil
+	}
+	records := make([]*RequestPacketImpl, 10)
+	for i := range records {
+		records[i] = testPacket
+	}
+	report := lt.replay(context.Background(), records)
+	// 50 QPS 发送10个请求至少需要约200ms
+	if report.Duration < 180*time.Millisecond {
+		t.Errorf("QPS限制未生效，耗时 %s", report.Duration)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	samples := make([]time.Duration, 100)
+	for i := range samples {
+		samples[i] = time.Duration(i+1) * time.Millisecond
+	}
+	report := buildLoadTestReport(samples, 0, time.Second)
+	if report.P50 != 50*time.Millisecond || report.P95 != 95*time.Millisecond || report.P99 != 99*time.Millisecond {
+		t.Errorf("分位数计算错误: %s", report)
+	}
+}
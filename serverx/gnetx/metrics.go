@@ -0,0 +1,134 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnetx
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// METRICS_NAMESPACE 内域服务指标名前缀
+const METRICS_NAMESPACE = "event_matrix_intranet"
+
+// METRICS_PATH 内域服务指标路径
+const METRICS_PATH = "/metrics"
+
+// intranetMetrics 内域服务的 Prometheus 指标，按事件类型区分，服务创建时即开始采集
+type intranetMetrics struct {
+	registry *prometheus.Registry
+	requests *prometheus.CounterVec   // 请求数
+	errors   *prometheus.CounterVec   // 错误数，包括处理失败和状态码不小于400的响应
+	latency  *prometheus.HistogramVec // 请求耗时
+	server   *http.Server             // 指标服务，未启动时为空
+}
+
+func newIntranetMetrics(serverId string) *intranetMetrics {
+	labels := prometheus.Labels{"server_id": serverId}
+	m := &intranetMetrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   METRICS_NAMESPACE,
+			Name:        "requests_total",
+			Help:        "内域请求总数",
+			ConstLabels: labels,
+		}, []string{"type"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   METRICS_NAMESPACE,
+			Name:        "errors_total",
+			Help:        "内域请求错误总数",
+			ConstLabels: labels,
+		}, []string{"type"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   METRICS_NAMESPACE,
+			Name:        "request_duration_seconds",
+			Help:        "内域请求耗时",
+			ConstLabels: labels,
+			Buckets:     prometheus.DefBuckets,
+		}, []string{"type"}),
+	}
+	m.registry.MustRegister(m.requests, m.errors, m.latency)
+	return m
+}
+
+// observe 记录一次请求
+func (m *intranetMetrics) observe(eventType uint16, failed bool, latency time.Duration) {
+	label := strconv.Itoa(int(eventType))
+	m.requests.WithLabelValues(label).Inc()
+	if failed {
+		m.errors.WithLabelValues(label).Inc()
+	}
+	m.latency.WithLabelValues(label).Observe(latency.Seconds())
+}
+
+// MetricsRegistry 返回内域服务的指标注册表，可用于注册额外指标或合并到其他指标服务
+func (s *IntranetServer) MetricsRegistry() *prometheus.Registry {
+	return s.metrics.registry
+}
+
+// ServeMetrics 在独立端口上启动 Prometheus 指标服务，路径为 /metrics，服务停止时一并关闭
+//
+// 指标按内域事件类型（type 标签）区分：
+//   - event_matrix_intranet_requests_total: 请求数
+//   - event_matrix_intranet_errors_total: 错误数
+//   - event_matrix_intranet_request_duration_seconds: 请求耗时
+//
+// Prometheus 抓取配置示例：
+//
+//	scrape_configs:
+//	  - job_name: event-matrix-intranet
+//	    metrics_path: /metrics
+//	    static_configs:
+//	      - targets: ["worker-1:9101"]
+func (s *IntranetServer) ServeMetrics(port int) error {
+	if port <= 0 {
+		return errors.New("invalid metrics port")
+	}
+	if s.metrics.server != nil {
+		return errors.New("metrics server already started")
+	}
+	mux := http.NewServeMux()
+	mux.Handle(METRICS_PATH, promhttp.HandlerFor(s.metrics.registry, promhttp.HandlerOpts{}))
+	srv := &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	s.metrics.server = srv
+	go func() {
+		logx.Info("Starting intranet metrics server on port: ", port)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logx.Error("Intranet metrics server stopped: ", err)
+		}
+	}()
+	return nil
+}
+
+// stopMetrics 关闭指标服务
+func (s *IntranetServer) stopMetrics() {
+	if s.metrics.server == nil {
+		return
+	}
+	if err := s.metrics.server.Close(); err != nil {
+		logx.Error("Close intranet metrics server failed: ", err)
+	}
+	s.metrics.server = nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnetx
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garrickvan/event-matrix/serverx"
+	"github.com/panjf2000/gnet/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestIntranetMetrics(t *testing.T) {
+	s := NewIntranetServer("metrics", 0, "", "NONE",
+		func(req serverx.RequestPacket, c gnet.Conn, routerImpl interface{}) serverx.ResponsePacket {
+			if req.Extend() == "2" {
+				return &ResponsePacketImpl{StatusCode: http.StatusInternalServerError}
+			}
+			return &ResponsePacketImpl{StatusCode: http.StatusOK}
+		}, nil)
+	conn := &statsConn{}
+	for _, xdata := range []string{"1", "1", "2"} {
+		pkg := &RequestPacketImpl{PayloadType: serverx.CONTENT_TYPE_JSON, Payload: "{}", XData: xdata}
+		data := pkg.Pack(false)
+		s.asyncProcess(conn, append(buildRpcHeader(data, COMPRESSION_NONE), data...), func() {}, COMPRESSION_NONE)
+	}
+
+	if v := testutil.ToFloat64(s.metrics.requests.WithLabelValues("1")); v != 2 {
+		t.Errorf("类型1请求数错误: %v", v)
+	}
+	if v := testutil.ToFloat64(s.metrics.errors.WithLabelValues("2")); v != 1 {
+		t.Errorf("类型2错误数错误: %v", v)
+	}
+	if n := testutil.CollectAndCount(s.metrics.latency); n != 2 {
+		t.Errorf("期望2个类型的耗时指标，实际 %d", n)
+	}
+
+	if err := s.ServeMetrics(18193); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+	if err := s.ServeMetrics(18193); err == nil {
+		t.Error("重复启动指标服务应返回错误")
+	}
+	var body string
+	for i := 0; i < 20; i++ {
+		resp, err := http.Get("http://127.0.0.1:18193" + METRICS_PATH)
+		if err == nil {
+			data, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			body = string(data)
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if !strings.Contains(body, `event_matrix_intranet_requests_total{server_id="metrics",type="1"} 2`) {
+		t.Errorf("指标输出缺少请求数: %s", body)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnetx
+
+import (
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoolStats 客户端到单个端点的连接池统计
+type PoolStats struct {
+	Idle    int   `json:"idle"`    // 池中空闲的连接数
+	Created int64 `json:"created"` // 累计新建的连接数
+	Evicted int64 `json:"evicted"` // 累计从池中淘汰的连接数，包括过期、探活失败和池满时关闭的连接
+}
+
+// Stats 返回各端点连接池的统计，key 为端点地址。连接池因空闲过期被移除后，其统计一并清除
+func (c *Client) Stats() map[string]PoolStats {
+	stats := make(map[string]PoolStats)
+	c.connPools.Range(func(key, value interface{}) bool {
+		pool := value.(*endpointPool)
+		stats[key.(string)] = PoolStats{
+			Idle:    len(pool.pool),
+			Created: atomic.LoadInt64(&pool.created),
+			Evicted: atomic.LoadInt64(&pool.evicted),
+		}
+		return true
+	})
+	return stats
+}
+
+// poolStatsCollector 以 Prometheus 指标导出连接池统计，每次抓取时读取最新数据
+type poolStatsCollector struct {
+	stats   func() map[string]PoolStats
+	idle    *prometheus.Desc
+	created *prometheus.Desc
+	evicted *prometheus.Desc
+}
+
+// NewPoolStatsCollector 创建连接池统计的指标采集器，可注册到 MetricsRegistry 一并通过 /metrics 导出
+//
+// 指标按端点（endpoint 标签）区分：
+//   - event_matrix_intranet_client_idle_conns: 空闲连接数
+//   - event_matrix_intranet_client_created_conns_total: 累计新建连接数
+//   - event_matrix_intranet_client_evicted_conns_total: 累计淘汰连接数
+func NewPoolStatsCollector(stats func() map[string]PoolStats) prometheus.Collector {
+	labels := []string{"endpoint"}
+	return &poolStatsCollector{
+		stats:   stats,
+		idle:    prometheus.NewDesc(METRICS_NAMESPACE+"_client_idle_conns", "内域客户端连接池空闲连接数", labels, nil),
+		created: prometheus.NewDesc(METRICS_NAMESPACE+"_client_created_conns_total", "内域客户端累计新建连接数", labels, nil),
+		evicted: prometheus.NewDesc(METRICS_NAMESPACE+"_client_evicted_conns_total", "内域客户端累计淘汰连接数", labels, nil),
+	}
+}
+
+func (p *poolStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- p.idle
+	ch <- p.created
+	ch <- p.evicted
+}
+
+func (p *poolStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	for endpoint, s := range p.stats() {
+		ch <- prometheus.MustNewConstMetric(p.idle, prometheus.GaugeValue, float64(s.Idle), endpoint)
+		ch <- prometheus.MustNewConstMetric(p.created, prometheus.CounterValue, float64(s.Created), endpoint)
+		ch <- prometheus.MustNewConstMetric(p.evicted, prometheus.CounterValue, float64(s.Evicted), endpoint)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnetx
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garrickvan/event-matrix/serverx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestClientPoolStats(t *testing.T) {
+	client := NewClient(2, time.Minute, time.Second)
+	defer client.Close()
+	client.SetDialer(func(endpoint string) (net.Conn, error) {
+		clientConn, serverConn := net.Pipe()
+		go ServeConn(serverConn, "", "NONE", func(req serverx.RequestPacket) serverx.ResponsePacket {
+			return &ResponsePacketImpl{StatusCode: http.StatusOK}
+		})
+		return clientConn, nil
+	})
+
+	// 同时借出3个连接，池容量为2，归还时第3个被淘汰
+	for round := 0; round < 3; round++ {
+		conns := make([]*gnetConnection, 0, 3)
+		for i := 0; i < 3; i++ {
+			conn, err := client.getConn("pipe")
+			if err != nil {
+				t.Fatalf("获取连接失败: %v", err)
+			}
+			conns = append(conns, conn)
+		}
+		for _, conn := range conns {
+			client.putConn("pipe", conn)
+		}
+		stats := client.Stats()["pipe"]
+		if stats.Idle != 2 {
+			t.Fatalf("第%d轮: 空闲连接数错误: %+v", round, stats)
+		}
+		if stats.Created-stats.Evicted != int64(stats.Idle) {
+			t.Fatalf("第%d轮: 新建与淘汰的连接数不一致: %+v", round, stats)
+		}
+	}
+	// 首轮新建3个，之后每轮复用2个、新建1个
+	if stats := client.Stats()["pipe"]; stats.Created != 5 || stats.Evicted != 3 {
+		t.Fatalf("累计统计错误: %+v", stats)
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(NewPoolStatsCollector(client.Stats))
+	expected := `
+# HELP event_matrix_intranet_client_idle_conns 内域客户端连接池空闲连接数
+# TYPE event_matrix_intranet_client_idle_conns gauge
+event_matrix_intranet_client_idle_conns{endpoint="pipe"} 2
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "event_matrix_intranet_client_idle_conns"); err != nil {
+		t.Fatal(err)
+	}
+}
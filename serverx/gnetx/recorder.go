@@ -0,0 +1,134 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnetx
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/garrickvan/event-matrix/serverx"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/utils/logx"
+)
+
+// 默认单个流量文件的最大字节数
+const defaultRecordMaxBytes int64 = 64 << 20
+
+// RecordInterceptor 流量录制器，将解密后的请求以JSONL格式写入文件，供 LoadTester 回放
+// 单个文件超过 maxBytes 后自动切换新文件，文件名格式：{dir}/traffic.{timestamp}.{seq}.jsonl
+type RecordInterceptor struct {
+	mu       sync.Mutex
+	enabled  atomic.Bool
+	dir      string
+	maxBytes int64
+	file     *os.File
+	written  int64
+	seq      int
+}
+
+// NewRecordInterceptor 创建流量录制器，创建后默认不启用
+// 参数：
+//   - dir: 流量文件存储目录
+//   - maxBytes: 单个文件的最大字节数，小于等于0时使用默认值64MB
+func NewRecordInterceptor(dir string, maxBytes int64) *RecordInterceptor {
+	if maxBytes <= 0 {
+		maxBytes = defaultRecordMaxBytes
+	}
+	return &RecordInterceptor{dir: dir, maxBytes: maxBytes}
+}
+
+// Enable 启用录制
+func (r *RecordInterceptor) Enable() { r.enabled.Store(true) }
+
+// Disable 停止录制，已打开的文件保持打开以便再次启用
+func (r *RecordInterceptor) Disable() { r.enabled.Store(false) }
+
+// Enabled 是否正在录制
+func (r *RecordInterceptor) Enabled() bool { return r.enabled.Load() }
+
+// Record 录制一个请求，未启用时直接返回，ping请求不录制
+// 请求数据可能来自缓冲池，序列化在调用方协程内同步完成
+func (r *RecordInterceptor) Record(req serverx.RequestPacket) {
+	if !r.Enabled() || req == nil || req.Type() == serverx.CONTENT_TYPE_PING {
+		return
+	}
+	pkg, ok := req.(*RequestPacketImpl)
+	if !ok {
+		return
+	}
+	line, err := jsonx.MarshalToBytes(pkg)
+	if err != nil {
+		logx.Debug("record request failed: ", err)
+		return
+	}
+	line = append(line, '\n')
+	if err := r.write(line); err != nil {
+		logx.Error("write traffic file failed: ", err)
+	}
+}
+
+func (r *RecordInterceptor) write(line []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil || r.written+int64(len(line)) > r.maxBytes {
+		if err := r.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := r.file.Write(line)
+	r.written += int64(n)
+	return err
+}
+
+// rotate 关闭当前文件并创建新的流量文件，调用方需持有锁
+func (r *RecordInterceptor) rotate() error {
+	if r.file != nil {
+		_ = r.file.Close()
+		r.file = nil
+	}
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		return err
+	}
+	r.seq++
+	name := fmt.Sprintf("traffic.%s.%04d.jsonl", time.Now().Format("20060102_150405"), r.seq)
+	file, err := os.OpenFile(filepath.Join(r.dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	r.file = file
+	r.written = 0
+	return nil
+}
+
+// Close 停止录制并关闭当前文件
+func (r *RecordInterceptor) Close() error {
+	r.Disable()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	if err != nil {
+		return errors.New("close traffic file failed: " + err.Error())
+	}
+	return nil
+}
@@ -42,6 +42,7 @@ type RequestContext struct {
 	response    *ResponsePacketImpl  // 响应包
 	tmpData     interface{}          // 临时数据存储
 	callChains  string               // 调用链信息，用于防止循环调用
+	streamed    bool                 // 是否已通过 BatchResponse 写出响应
 }
 
 // NewRequestContext 创建一个新的RequestContext实例
@@ -58,7 +59,7 @@ func NewRequestContext(conn gnet.Conn, req serverx.RequestPacket) *RequestContex
 
 	if req != nil {
 		ip = strings.Clone(req.IP())
-		body = fastconv.StringToBytes(req.TemporaryData()) // 底层用了sys.Pool的buffer，临时数据仅在当前请求的生命周期内有效，长期保存需要拷贝
+		body = req.RawBody() // 底层用了sys.Pool的buffer，临时数据仅在当前请求的生命周期内有效，长期保存需要拷贝
 	}
 	return &RequestContext{
 		conn:        conn,
@@ -178,6 +179,32 @@ func (r *RequestContext) ResponseBuiltinJson(code constant.RESPONSE_CODE) error
 	return nil
 }
 
+// BatchResponse 内域响应为单个数据包，逐行序列化后合并为NDJSON字符串一次写出
+func (r *RequestContext) BatchResponse(iter func(yield func(row map[string]interface{}) bool)) error {
+	var builder strings.Builder
+	var err error
+	iter(func(row map[string]interface{}) bool {
+		line, e := jsonx.MarshalToStr(row)
+		if e != nil {
+			err = e
+			return false
+		}
+		builder.WriteString(line)
+		builder.WriteByte('\n')
+		return true
+	})
+	r.streamed = true
+	r.response.StatusCode = r.status
+	r.response.ContentType = serverx.CONTENT_TYPE_STRING
+	r.response.Payload = builder.String()
+	return err
+}
+
+// Streamed 返回是否已通过 BatchResponse 写出响应
+func (r *RequestContext) Streamed() bool {
+	return r.streamed
+}
+
 // Event 返回请求关联的事件对象
 // 如果未设置则创建一个新的事件对象
 func (r *RequestContext) Event() *core.Event {
@@ -25,23 +25,23 @@ import (
 	"github.com/garrickvan/event-matrix/serverx"
 	"github.com/garrickvan/event-matrix/utils/fastconv"
 	"github.com/garrickvan/event-matrix/utils/logx"
-	"github.com/golang/snappy"
 )
 
 // RequestPacketImpl 定义客户端请求的数据包结构
 type RequestPacketImpl struct {
-	PayloadType serverx.CONTENT_TYPE `json:"pt"` // 内容类型：PING、JSON或STRING
-	XData       string               `json:"xd"` // 扩展数据，用于扩展协议
-	Payload     string               `json:"pl"` // Payload 内容，请求的主体数据，存在buff池里的临时数据
-	SourceIP    string               `json:"ip"` // SourceIP 客户端IP地址，不指定则根据gnet自动获取
-	CallChain   string               `json:"cc"` // CallChain 调用链，上层注入的调用信息，防止内部接口的循环调用
-	Timestamp   int64                `json:"ts"` // 时间戳，Unix毫秒时间戳
+	PayloadType serverx.CONTENT_TYPE `json:"pt"`           // 内容类型：PING、JSON、STRING或BINARY
+	XData       string               `json:"xd"`           // 扩展数据，用于扩展协议
+	Payload     string               `json:"pl"`           // Payload 内容，请求的主体数据，存在buff池里的临时数据
+	RawPayload  []byte               `json:"rp,omitempty"` // 二进制负载，PayloadType为BINARY时代替Payload，存在buff池里的临时数据
+	SourceIP    string               `json:"ip"`           // SourceIP 客户端IP地址，不指定则根据gnet自动获取
+	CallChain   string               `json:"cc"`           // CallChain 调用链，上层注入的调用信息，防止内部接口的循环调用
+	Timestamp   int64                `json:"ts"`           // 时间戳，Unix毫秒时间戳
 }
 
 // Marshal 将RequestPacket序列化为二进制格式
 func (r *RequestPacketImpl) Marshal() ([]byte, error) {
 	// 检查负载长度是否超出限制
-	if len(r.Payload) > math.MaxUint32 {
+	if len(r.RawBody()) > math.MaxUint32 {
 		return nil, errors.New("payload too long")
 	}
 
@@ -51,7 +51,7 @@ func (r *RequestPacketImpl) Marshal() ([]byte, error) {
 
 	// 预先转换所有字段
 	xDataBytes := fastconv.StringToBytes(r.XData)
-	payloadBytes := fastconv.StringToBytes(r.Payload)
+	payloadBytes := r.RawBody()
 	sourceIPBytes := fastconv.StringToBytes(r.SourceIP)
 	callChainBytes := fastconv.StringToBytes(r.CallChain)
 	timestampBytes := make([]byte, 8)
@@ -133,7 +133,7 @@ func (r *RequestPacketImpl) Unmarshal(data []byte) error {
 	if end := offset + int(lengths[2]); end > totalLen {
 		return errors.New("invalid Payload length")
 	} else {
-		r.Payload = fastconv.BytesToString(body[offset:end])
+		r.SetBody(body[offset:end])
 		offset = end
 	}
 
@@ -163,8 +163,13 @@ func (r *RequestPacketImpl) Unmarshal(data []byte) error {
 	return nil
 }
 
-// Pack 序列化请求数据包
+// Pack 序列化请求数据包，compressed 为true时使用Snappy压缩
 func (p *RequestPacketImpl) Pack(compressed bool) []byte {
+	return p.PackWith(compressionOf(compressed))
+}
+
+// PackWith 使用指定的压缩算法序列化请求数据包
+func (p *RequestPacketImpl) PackWith(compression COMPRESSION) []byte {
 	// 填充时间戳
 	p.Timestamp = time.Now().UnixMilli()
 	data, err := p.Marshal()
@@ -173,23 +178,29 @@ func (p *RequestPacketImpl) Pack(compressed bool) []byte {
 		return nil
 	}
 
-	if compressed {
-		// 压缩数据
-		data = snappy.Encode(nil, data)
+	// 压缩数据
+	data, err = compressData(data, compression)
+	if err != nil {
+		logx.Debug("compress request packet failed: ", err.Error())
+		return nil
 	}
 	return data
 }
 
-// unPackRequest 反序列化请求数据包
-func UnPackRequest(data []byte, compressed bool) (serverx.RequestPacket, error) {
+// UnPackRequest 按消息头中的压缩算法反序列化请求数据包
+// tolerance 为请求包时间戳的容忍窗口，早于该窗口或超前当前时间 MAX_FUTURE_TIMESTAMP 以上的请求包被拒绝，
+// 小于等于0时使用 MESSAGE_SEND_TIMEOUT；maxSize 为解压后数据的最大字节数，小于等于0时使用 DEFAULT_MAX_MESSAGE_SIZE，
+// 超出时返回 ErrMessageTooLarge
+func UnPackRequest(data []byte, compression COMPRESSION, tolerance time.Duration, maxSize int) (serverx.RequestPacket, error) {
 	var packet RequestPacketImpl
-	var err error
+	if maxSize <= 0 {
+		maxSize = DEFAULT_MAX_MESSAGE_SIZE
+	}
 
-	if compressed { // 解压缩数据
-		data, err = snappy.Decode(nil, data)
-		if err != nil {
-			return nil, fmt.Errorf("snappy decompress failed: %v", err)
-		}
+	// 解压缩数据
+	data, err := decompressData(data, compression, maxSize)
+	if err != nil {
+		return nil, err
 	}
 
 	// 反序列化
@@ -197,13 +208,30 @@ func UnPackRequest(data []byte, compressed bool) (serverx.RequestPacket, error)
 		return nil, fmt.Errorf("json unmarshal failed: %v", err)
 	}
 
-	// 检查消息是否过期
-	if time.Since(time.UnixMilli(packet.Timestamp)) > MESSAGE_SEND_TIMEOUT {
-		return nil, errors.New("request packet expired")
+	if err := checkPacketTimestamp(packet.Timestamp, tolerance); err != nil {
+		return nil, err
 	}
 	return &packet, nil
 }
 
+// checkPacketTimestamp 检查请求包时间戳是否在容忍窗口内，接近窗口上限（超过90%）时记录警告，便于发现时钟漂移
+func checkPacketTimestamp(timestamp int64, tolerance time.Duration) error {
+	if tolerance <= 0 {
+		tolerance = MESSAGE_SEND_TIMEOUT
+	}
+	age := time.Since(time.UnixMilli(timestamp))
+	if age > tolerance {
+		return errors.New("request packet expired")
+	}
+	if age < -MAX_FUTURE_TIMESTAMP {
+		return errors.New("request packet timestamp is in the future")
+	}
+	if age > tolerance*9/10 {
+		logx.Warn(fmt.Sprintf("request packet is close to expiring: age %v, tolerance %v", age, tolerance))
+	}
+	return nil
+}
+
 // Type 返回请求包的内容类型
 func (r *RequestPacketImpl) Type() serverx.CONTENT_TYPE {
 	return r.PayloadType
@@ -211,9 +239,31 @@ func (r *RequestPacketImpl) Type() serverx.CONTENT_TYPE {
 
 // TemporaryData 返回请求包的临时数据
 func (r *RequestPacketImpl) TemporaryData() string {
+	if r.PayloadType == serverx.CONTENT_TYPE_BINARY {
+		return fastconv.BytesToString(r.RawPayload)
+	}
 	return r.Payload
 }
 
+// RawBody 返回请求包的原始负载字节，二进制负载直接返回 RawPayload
+func (r *RequestPacketImpl) RawBody() []byte {
+	if r.PayloadType == serverx.CONTENT_TYPE_BINARY {
+		return r.RawPayload
+	}
+	return fastconv.StringToBytes(r.Payload)
+}
+
+// SetBody 按内容类型设置负载，二进制负载保存到 RawPayload，其余类型零拷贝转换为字符串保存到 Payload
+func (r *RequestPacketImpl) SetBody(data []byte) {
+	if r.PayloadType == serverx.CONTENT_TYPE_BINARY {
+		r.RawPayload = data
+		r.Payload = ""
+		return
+	}
+	r.Payload = fastconv.BytesToString(data)
+	r.RawPayload = nil
+}
+
 // IP 返回请求包的来源IP
 func (r *RequestPacketImpl) IP() string {
 	return r.SourceIP
@@ -0,0 +1,132 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnetx
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/garrickvan/event-matrix/serverx"
+	"github.com/garrickvan/event-matrix/utils/encryptx"
+	"github.com/panjf2000/gnet/v2"
+)
+
+// binaryPayload 包含非法UTF-8序列和零字节的二进制数据
+var binaryPayload = []byte{0x00, 0xff, 0xfe, 0x80, 0x0a, 0xc3, 0x28, 0x00}
+
+func TestBinaryRequestPacketRoundTrip(t *testing.T) {
+	for _, compression := range []COMPRESSION{COMPRESSION_NONE, COMPRESSION_SNAPPY, COMPRESSION_GZIP} {
+		pkg := &RequestPacketImpl{PayloadType: serverx.CONTENT_TYPE_BINARY, XData: "1"}
+		pkg.SetBody(binaryPayload)
+		req, err := UnPackRequest(pkg.PackWith(compression), compression, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if req.Type() != serverx.CONTENT_TYPE_BINARY || !bytes.Equal(req.RawBody(), binaryPayload) {
+			t.Errorf("二进制负载解包错误(compression=%s): %v", compression, req.RawBody())
+		}
+	}
+	// 非二进制类型的 RawBody 与 TemporaryData 一致
+	str := &RequestPacketImpl{PayloadType: serverx.CONTENT_TYPE_STRING, Payload: "hello"}
+	if string(str.RawBody()) != "hello" {
+		t.Errorf("字符串负载的原始字节错误: %s", str.RawBody())
+	}
+}
+
+func TestBinaryRequestDecryption(t *testing.T) {
+	const secret, algor = "binary-secret", "AES-256"
+	var received []byte
+	s := NewIntranetServer("binary", 0, secret, algor,
+		func(req serverx.RequestPacket, c gnet.Conn, routerImpl interface{}) serverx.ResponsePacket {
+			ctx := NewRequestContext(c, req)
+			received = append([]byte(nil), ctx.Body()...)
+			return &ResponsePacketImpl{StatusCode: http.StatusOK}
+		}, nil)
+	encrypted, err := encryptx.Encrypt(binaryPayload, secret, algor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := &RequestPacketImpl{PayloadType: serverx.CONTENT_TYPE_BINARY, XData: "1"}
+	pkg.SetBody(encrypted)
+	data := pkg.Pack(false)
+	s.asyncProcess(&statsConn{}, append(buildRpcHeader(data, COMPRESSION_NONE), data...), func() {}, COMPRESSION_NONE)
+	if !bytes.Equal(received, binaryPayload) {
+		t.Errorf("解密后的二进制负载错误: %v", received)
+	}
+}
+
+func TestHMACRequestAuthentication(t *testing.T) {
+	const secret, algor = "hmac-secret", encryptx.HMAC_SHA256
+	s := NewIntranetServer("hmac", 0, secret, algor,
+		func(req serverx.RequestPacket, c gnet.Conn, routerImpl interface{}) serverx.ResponsePacket {
+			return &ResponsePacketImpl{StatusCode: http.StatusOK, Payload: string(NewRequestContext(c, req).Body())}
+		}, nil)
+	process := func(body []byte) serverx.ResponsePacket {
+		pkg := &RequestPacketImpl{PayloadType: serverx.CONTENT_TYPE_BINARY, XData: "1"}
+		pkg.SetBody(body)
+		data := pkg.Pack(false)
+		conn := &captureConn{}
+		s.asyncProcess(conn, append(buildRpcHeader(data, COMPRESSION_NONE), data...), func() {}, COMPRESSION_NONE)
+		resp, err := UnPackResponse(conn.out[HEADER_LEN:], COMPRESSION_NONE)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	signed, err := encryptx.Encrypt(binaryPayload, secret, algor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := process(signed)
+	if resp.Status() != http.StatusOK {
+		t.Fatalf("签名正确的请求应处理成功，实际 %d", resp.Status())
+	}
+	// 响应同样追加校验码
+	body, err := encryptx.Decrypt([]byte(resp.TemporaryData()), secret, algor)
+	if err != nil || !bytes.Equal(body, binaryPayload) {
+		t.Errorf("响应校验失败: %v %v", body, err)
+	}
+
+	signed[0] ^= 0x01
+	if resp := process(signed); resp.Status() != http.StatusForbidden {
+		t.Errorf("被篡改的请求应返回403，实际 %d", resp.Status())
+	}
+}
+
+func TestCheckPacketTimestamp(t *testing.T) {
+	now := time.Now()
+	if err := checkPacketTimestamp(now.UnixMilli(), 0); err != nil {
+		t.Fatalf("current timestamp should pass: %v", err)
+	}
+	if err := checkPacketTimestamp(now.Add(-MESSAGE_SEND_TIMEOUT-time.Second).UnixMilli(), 0); err == nil {
+		t.Fatal("expired timestamp should be rejected with default tolerance")
+	}
+	if err := checkPacketTimestamp(now.Add(time.Second).UnixMilli(), 0); err == nil {
+		t.Fatal("future timestamp should be rejected")
+	}
+	if err := checkPacketTimestamp(now.Add(50*time.Millisecond).UnixMilli(), 0); err != nil {
+		t.Fatalf("small clock skew should pass: %v", err)
+	}
+	old := now.Add(-3 * time.Second).UnixMilli()
+	if err := checkPacketTimestamp(old, 10*time.Second); err != nil {
+		t.Fatalf("timestamp within custom tolerance should pass: %v", err)
+	}
+	if err := checkPacketTimestamp(old, time.Second); err == nil {
+		t.Fatal("timestamp beyond custom tolerance should be rejected")
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package gnetx
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/garrickvan/event-matrix/serverx"
+)
+
+// RequestTypeResolver 从请求包中解析事件类型，用于按类型统计请求
+type RequestTypeResolver func(req serverx.RequestPacket) uint16
+
+// EventTypeStats 单个事件类型的请求统计，计数字段使用原子操作更新
+type EventTypeStats struct {
+	Type           uint16 `json:"type"`             // 事件类型
+	Requests       int64  `json:"requests"`         // 请求数
+	Errors         int64  `json:"errors"`           // 错误数，包括处理失败和状态码不小于400的响应
+	TotalLatencyMs int64  `json:"total_latency_ms"` // 累计处理耗时，单位毫秒
+}
+
+// ErrorRate 返回错误率，没有请求时返回0
+func (st *EventTypeStats) ErrorRate() float64 {
+	if st.Requests == 0 {
+		return 0
+	}
+	return float64(st.Errors) / float64(st.Requests)
+}
+
+// AvgLatencyMs 返回平均处理耗时，单位毫秒，没有请求时返回0
+func (st *EventTypeStats) AvgLatencyMs() float64 {
+	if st.Requests == 0 {
+		return 0
+	}
+	return float64(st.TotalLatencyMs) / float64(st.Requests)
+}
+
+// record 记录一次请求
+func (st *EventTypeStats) record(failed bool, latency time.Duration) {
+	atomic.AddInt64(&st.Requests, 1)
+	if failed {
+		atomic.AddInt64(&st.Errors, 1)
+	}
+	atomic.AddInt64(&st.TotalLatencyMs, latency.Milliseconds())
+}
+
+// snapshot 返回统计的只读副本
+func (st *EventTypeStats) snapshot() EventTypeStats {
+	return EventTypeStats{
+		Type:           st.Type,
+		Requests:       atomic.LoadInt64(&st.Requests),
+		Errors:         atomic.LoadInt64(&st.Errors),
+		TotalLatencyMs: atomic.LoadInt64(&st.TotalLatencyMs),
+	}
+}
+
+// defaultTypeResolver 默认将请求扩展数据解析为数字类型，无法解析时返回0
+func defaultTypeResolver(req serverx.RequestPacket) uint16 {
+	t, err := strconv.ParseUint(req.Extend(), 10, 16)
+	if err != nil {
+		return 0
+	}
+	return uint16(t)
+}
+
+// SetRequestTypeResolver 设置事件类型解析函数，未设置时将请求扩展数据按数字解析
+func (s *IntranetServer) SetRequestTypeResolver(resolver RequestTypeResolver) {
+	s.typeResolver = resolver
+}
+
+// resolveType 解析请求的事件类型
+func (s *IntranetServer) resolveType(req serverx.RequestPacket) uint16 {
+	if s.typeResolver != nil {
+		return s.typeResolver(req)
+	}
+	return defaultTypeResolver(req)
+}
+
+// typeStats 获取事件类型对应的统计，不存在时创建
+func (s *IntranetServer) typeStats(eventType uint16) *EventTypeStats {
+	if st, ok := s.perTypeCounter.Load(eventType); ok {
+		return st.(*EventTypeStats)
+	}
+	st, _ := s.perTypeCounter.LoadOrStore(eventType, &EventTypeStats{Type: eventType})
+	return st.(*EventTypeStats)
+}
+
+// isErrorResponse 判断响应是否计为错误
+func isErrorResponse(resp serverx.ResponsePacket) bool {
+	return resp == nil || resp.Status() >= http.StatusBadRequest
+}
+
+// RequestStats 返回按事件类型统计的请求数、错误数和累计耗时，按类型升序排列
+func (s *IntranetServer) RequestStats() []EventTypeStats {
+	stats := []EventTypeStats{}
+	s.perTypeCounter.Range(func(_, value interface{}) bool {
+		stats = append(stats, value.(*EventTypeStats).snapshot())
+		return true
+	})
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Type < stats[j].Type })
+	return stats
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package gnetx
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/garrickvan/event-matrix/serverx"
+	"github.com/panjf2000/gnet/v2"
+)
+
+// statsConn 仅实现异步写入的测试连接
+type statsConn struct {
+	gnet.Conn
+	mu     sync.Mutex
+	writes int
+}
+
+func (c *statsConn) Context() interface{} { return nil }
+
+func (c *statsConn) AsyncWrite(buf []byte, callback gnet.AsyncCallback) error {
+	c.mu.Lock()
+	c.writes++
+	c.mu.Unlock()
+	return nil
+}
+
+func TestRequestStatsPerType(t *testing.T) {
+	s := NewIntranetServer("stats", 0, "", "NONE",
+		func(req serverx.RequestPacket, c gnet.Conn, routerImpl interface{}) serverx.ResponsePacket {
+			switch req.Extend() {
+			case "2":
+				return &ResponsePacketImpl{StatusCode: http.StatusInternalServerError}
+			case "3":
+				panic("router panic")
+			}
+			return &ResponsePacketImpl{StatusCode: http.StatusOK}
+		}, nil)
+	conn := &statsConn{}
+	process := func(xdata string) {
+		pkg := &RequestPacketImpl{PayloadType: serverx.CONTENT_TYPE_JSON, Payload: "{}", XData: xdata}
+		data := pkg.Pack(false)
+		msg := append(buildRpcHeader(data, COMPRESSION_NONE), data...)
+		s.asyncProcess(conn, msg, func() {}, COMPRESSION_NONE)
+	}
+	for i := 0; i < 3; i++ {
+		process("1")
+	}
+	process("2")
+	process("2")
+	process("3")
+	// ping请求不计入按类型统计
+	ping := (&RequestPacketImpl{PayloadType: serverx.CONTENT_TYPE_PING}).Pack(false)
+	s.asyncProcess(conn, append(buildRpcHeader(ping, COMPRESSION_NONE), ping...), func() {}, COMPRESSION_NONE)
+
+	stats := s.RequestStats()
+	if len(stats) != 3 {
+		t.Fatalf("期望3种事件类型，实际 %d: %+v", len(stats), stats)
+	}
+	expected := []struct {
+		typ      uint16
+		requests int64
+		errors   int64
+	}{{1, 3, 0}, {2, 2, 2}, {3, 1, 1}}
+	for i, e := range expected {
+		st := stats[i]
+		if st.Type != e.typ || st.Requests != e.requests || st.Errors != e.errors {
+			t.Errorf("类型 %d 统计错误: %+v", e.typ, st)
+		}
+	}
+	if stats[1].ErrorRate() != 1 || stats[0].ErrorRate() != 0 {
+		t.Errorf("错误率计算错误: %v %v", stats[0].ErrorRate(), stats[1].ErrorRate())
+	}
+	if s.ErrorCount() != 1 {
+		t.Errorf("仅panic计入全局错误数，实际 %d", s.ErrorCount())
+	}
+	if conn.writes != 7 {
+		t.Errorf("每个请求都应写回响应，实际 %d", conn.writes)
+	}
+}
+
+func TestRequestStatsTypeResolver(t *testing.T) {
+	s := NewIntranetServer("stats", 0, "", "NONE", nil, nil)
+	req := &RequestPacketImpl{XData: `{"type":5}`}
+	if s.resolveType(req) != 0 {
+		t.Fatal("默认解析无法识别的扩展数据应返回0")
+	}
+	s.SetRequestTypeResolver(func(req serverx.RequestPacket) uint16 { return 5 })
+	if s.resolveType(req) != 5 {
+		t.Fatal("应使用自定义的类型解析函数")
+	}
+	s.typeStats(5).record(false, 0)
+	s.typeStats(5).record(true, 0)
+	if st := s.RequestStats(); len(st) != 1 || st[0].Requests != 2 || st[0].Errors != 1 {
+		t.Errorf("同一类型应累加统计: %+v", st)
+	}
+}
@@ -25,7 +25,6 @@ import (
 	"github.com/garrickvan/event-matrix/serverx"
 	"github.com/garrickvan/event-matrix/utils/fastconv"
 	"github.com/garrickvan/event-matrix/utils/logx"
-	"github.com/golang/snappy"
 )
 
 // ResponsePacketImpl 定义服务器响应的消息结构
@@ -135,8 +134,13 @@ func (rm *ResponsePacketImpl) Unmarshal(data []byte) error {
 	return nil
 }
 
-// Pack 序列化响应消息
+// Pack 序列化响应消息，compressed 为true时使用Snappy压缩
 func (p *ResponsePacketImpl) Pack(compressed bool) []byte {
+	return p.PackWith(compressionOf(compressed))
+}
+
+// PackWith 使用指定的压缩算法序列化响应消息
+func (p *ResponsePacketImpl) PackWith(compression COMPRESSION) []byte {
 	// 填充时间戳
 	p.Timestamp = time.Now().UnixMilli()
 	data, err := p.Marshal()
@@ -145,24 +149,23 @@ func (p *ResponsePacketImpl) Pack(compressed bool) []byte {
 		return nil
 	}
 
-	if compressed {
-		// 压缩数据
-		data = snappy.Encode(nil, data)
+	// 压缩数据
+	data, err = compressData(data, compression)
+	if err != nil {
+		logx.Debug("compress response message failed: ", err.Error())
+		return nil
 	}
 	return data
 }
 
-// UnPackResponse 反序列化响应消息
-func UnPackResponse(data []byte, compressed bool) (serverx.ResponsePacket, error) {
+// UnPackResponse 按消息头中的压缩算法反序列化响应消息
+func UnPackResponse(data []byte, compression COMPRESSION) (serverx.ResponsePacket, error) {
 	var pkg ResponsePacketImpl
-	var err error
-
-	if compressed {
-		// 解压缩数据
-		data, err = snappy.Decode(nil, data)
-		if err != nil {
-			return nil, fmt.Errorf("snappy decompress failed: %v", err)
-		}
+
+	// 解压缩数据，响应来自已连接的服务端，不受请求消息大小限制
+	data, err := decompressData(data, compression, 0)
+	if err != nil {
+		return nil, err
 	}
 
 	// 反序列化
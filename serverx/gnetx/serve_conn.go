@@ -0,0 +1,155 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnetx
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/garrickvan/event-matrix/serverx"
+	"github.com/garrickvan/event-matrix/utils/encryptx"
+	"github.com/garrickvan/event-matrix/utils/fastconv"
+	"github.com/garrickvan/event-matrix/utils/logx"
+)
+
+// ServeConn 在普通的 net.Conn 上按内域协议处理请求，直到连接关闭
+// 与 IntranetServer 共用请求的解包、解密和响应的加密、打包流程，但按顺序同步处理，
+// 适用于测试（如 net.Pipe）和不依赖gnet事件循环的轻量场景
+// 参数：
+//   - conn: 网络连接
+//   - secret: 内域通信密钥
+//   - algor: 加密算法
+//   - handler: 请求处理函数，返回nil时响应501
+func ServeConn(conn net.Conn, secret, algor string, handler func(req serverx.RequestPacket) serverx.ResponsePacket) error {
+	defer conn.Close()
+	header := make([]byte, HEADER_LEN)
+	var version uint8 // 连接使用的协议版本，在首个请求或版本协商时确定
+	for {
+		if _, err := io.ReadFull(conn, header); err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrClosedPipe) || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		bodyLen, compression, reqVersion, err := parseVersionedHeader(header)
+		if err != nil {
+			conn.Write(invalidHeaderResponse)
+			return err
+		}
+		if version == 0 {
+			version = reqVersion
+		}
+		if int(bodyLen)+HEADER_LEN > DEFAULT_MAX_MESSAGE_SIZE {
+			return errors.New("message size exceeds limit")
+		}
+		body := make([]byte, bodyLen)
+		if _, err := io.ReadFull(conn, body); err != nil {
+			return err
+		}
+
+		req, resp, agreed := decodeRequest(body, compression, 0, 0, secret, algor)
+		if agreed != 0 {
+			version = agreed
+		}
+		if resp == nil {
+			if resp = handler(req); resp == nil {
+				resp = unimplementedResponse()
+			}
+		} else if req != nil && isControlRequest(req) {
+			compression = COMPRESSION_NONE
+		}
+		respData, respCompression, err := encodeResponse(resp, compression, secret, algor)
+		if err != nil {
+			return err
+		}
+		if _, err := conn.Write(append(buildVersionedRpcHeader(respData, respCompression, version), respData...)); err != nil {
+			return err
+		}
+	}
+}
+
+// decodeRequest 解包并解密单个请求，IntranetServer 与 ServeConn 共用
+// 返回值：
+//   - req: 解包后的请求，解包失败时为nil
+//   - resp: 无需路由处理时的直接响应，包括 ping、版本协商请求和解包、解密失败的请求，需路由处理时为nil
+//   - agreed: 版本协商请求协商出的协议版本，其他请求为0
+func decodeRequest(
+	body []byte, compression COMPRESSION, tolerance time.Duration, maxSize int, secret, algor string,
+) (req serverx.RequestPacket, resp serverx.ResponsePacket, agreed uint8) {
+	req, err := UnPackRequest(body, compression, tolerance, maxSize)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrMessageTooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		return nil, errorResponse(status, err.Error()), 0
+	}
+	switch req.Type() {
+	case serverx.CONTENT_TYPE_PING:
+		return req, pingResponse, 0
+	case serverx.CONTENT_TYPE_HANDSHAKE:
+		resp, agreed = handshakeResponse(req)
+		return req, resp, agreed
+	}
+	decrypted, err := encryptx.Decrypt(req.RawBody(), secret, algor)
+	if err != nil {
+		return req, errorResponse(http.StatusForbidden, "decryption failed"), 0
+	}
+	pkg, ok := req.(*RequestPacketImpl)
+	if !ok {
+		return req, errorResponse(http.StatusBadRequest, "invalid request implementation"), 0
+	}
+	pkg.SetBody(decrypted)
+	return req, nil, 0
+}
+
+// isControlRequest 判断是否为 ping、版本协商等不经过路由处理的控制请求，控制请求的响应不压缩
+func isControlRequest(req serverx.RequestPacket) bool {
+	return req.Type() == serverx.CONTENT_TYPE_PING || req.Type() == serverx.CONTENT_TYPE_HANDSHAKE
+}
+
+// encodeResponse 加密响应数据并按指定的压缩算法打包，返回打包数据和实际使用的压缩算法
+func encodeResponse(resp serverx.ResponsePacket, compression COMPRESSION, secret, algor string) ([]byte, COMPRESSION, error) {
+	if len(resp.TemporaryData()) != 0 {
+		encrypted, err := encryptx.Encrypt(fastconv.StringToBytes(resp.TemporaryData()), secret, algor)
+		if err != nil {
+			return nil, compression, err
+		}
+		if pkg, ok := resp.(*ResponsePacketImpl); ok {
+			pkg.Payload = fastconv.BytesToString(encrypted)
+		} else {
+			logx.Debug("Invalid response implementation")
+		}
+	}
+	data, compression := packResponse(resp, compression)
+	return data, compression, nil
+}
+
+// errorResponse 构建字符串类型的错误响应
+func errorResponse(status int, msg string) *ResponsePacketImpl {
+	return &ResponsePacketImpl{
+		StatusCode:  status,
+		ContentType: serverx.CONTENT_TYPE_STRING,
+		Payload:     msg,
+	}
+}
+
+// unimplementedResponse 路由未处理请求时的响应
+func unimplementedResponse() *ResponsePacketImpl {
+	return errorResponse(http.StatusNotImplemented, "unimplemented intranet request")
+}
@@ -18,6 +18,7 @@ package gnetx
 import (
 	"fmt"
 	"net/http"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -26,6 +27,9 @@ import (
 	"github.com/panjf2000/gnet/v2"
 )
 
+// DEFAULT_MAX_CONNECTIONS 默认最大连接数
+const DEFAULT_MAX_CONNECTIONS = 10000
+
 // IntranetServer 是内域服务器的实现，基于gnet框架
 type IntranetServer struct {
 	gnet.BuiltinEventEngine // 继承gnet的事件引擎
@@ -41,10 +45,20 @@ type IntranetServer struct {
 
 	router     IntranetServerRouter // 请求路由函数
 	routerImpl interface{}          // 工作服务器实现
+	recorder   *RecordInterceptor   // 流量录制器，为空时不录制
+
+	connCount       int64         // 当前连接数
+	maxConnections  int64         // 最大连接数，超出时拒绝新连接
+	maxMessageSize  int           // 单条消息最大字节数（含消息头），为0时使用 DEFAULT_MAX_MESSAGE_SIZE
+	packetTolerance time.Duration // 请求包时间戳的容忍窗口，为0时使用 MESSAGE_SEND_TIMEOUT
+	readyAt         int64         // 就绪时间（UnixNano），在此之前拒绝新连接，为0时立即就绪
+	draining        int32         // 是否已停止接受新连接，非0时拒绝新连接，已建立的连接继续处理
+	reqCounter      int64         // 请求计数器
+	errorCounter    int64         // 错误计数器
 
-	connCount    int64 // 当前连接数
-	reqCounter   int64 // 请求计数器
-	errorCounter int64 // 错误计数器
+	perTypeCounter sync.Map            // 按事件类型统计，键为事件类型，值为*EventTypeStats
+	typeResolver   RequestTypeResolver // 事件类型解析函数，为空时使用默认解析
+	metrics        *intranetMetrics    // Prometheus 指标
 }
 
 // IntranetServerRouter 是处理请求的路由函数类型
@@ -77,9 +91,43 @@ func NewIntranetServer(serverId string, port int, secret, algor string, router I
 		algorithm:      algor,      // 加密算法
 		router:         router,     // 请求路由函数
 		routerImpl:     routerImpl, // 工作服务器实现
+		maxConnections: DEFAULT_MAX_CONNECTIONS,
+		metrics:        newIntranetMetrics(serverId),
 	}
 }
 
+// SetMaxConnections 设置最大连接数，小于等于0时使用默认值，需在启动前调用
+func (s *IntranetServer) SetMaxConnections(max int) {
+	if max <= 0 {
+		max = DEFAULT_MAX_CONNECTIONS
+	}
+	s.maxConnections = int64(max)
+}
+
+// SetMaxMessageSize 设置单条消息最大字节数（含消息头），小于等于0时使用默认值，需在启动前调用
+func (s *IntranetServer) SetMaxMessageSize(size int) {
+	if size <= 0 {
+		size = DEFAULT_MAX_MESSAGE_SIZE
+	}
+	s.maxMessageSize = size
+}
+
+// MaxMessageSize 返回单条消息最大字节数（含消息头）
+func (s *IntranetServer) MaxMessageSize() int {
+	if s.maxMessageSize <= 0 {
+		return DEFAULT_MAX_MESSAGE_SIZE
+	}
+	return s.maxMessageSize
+}
+
+// SetPacketTimestampTolerance 设置请求包时间戳的容忍窗口，小于等于0时使用 MESSAGE_SEND_TIMEOUT，需在启动前调用
+func (s *IntranetServer) SetPacketTimestampTolerance(tolerance time.Duration) {
+	if tolerance < 0 {
+		tolerance = 0
+	}
+	s.packetTolerance = tolerance
+}
+
 // ServerId 返回服务器ID
 func (s *IntranetServer) ServerId() string { return s.serverId }
 
@@ -135,6 +183,11 @@ func (s *IntranetServer) Start() error {
 	return nil
 }
 
+// SetRecordInterceptor 设置流量录制器，录制解密后的请求用于压测回放
+func (s *IntranetServer) SetRecordInterceptor(recorder *RecordInterceptor) {
+	s.recorder = recorder
+}
+
 // OnStart 设置服务器启动时的回调函数
 func (s *IntranetServer) OnStart(handler serverx.OnStartFunc) {
 	s.onStartHandler = handler
@@ -142,6 +195,7 @@ func (s *IntranetServer) OnStart(handler serverx.OnStartFunc) {
 
 // Stop 停止服务器
 func (s *IntranetServer) Stop() error {
+	s.stopMetrics()
 	// 如果设置了停止回调函数，并且回调函数返回true，则直接返回
 	if s.onStopHandler != nil && s.onStopHandler(s) {
 		return nil
@@ -187,10 +241,39 @@ func (s *IntranetServer) Impl() interface{} {
 //   - []byte: 要发送给客户端的数据
 //   - gnet.Action: 后续动作
 func (s *IntranetServer) OnOpen(c gnet.Conn) ([]byte, gnet.Action) {
-	atomic.AddInt64(&s.connCount, 1) // 增加连接数
+	// 增加连接数，超出上限或尚未就绪时返回503响应并关闭连接，连接数在OnClose中减少
+	if atomic.AddInt64(&s.connCount, 1) > s.maxConnections {
+		logx.Warn("Too many connections, reject: ", c.RemoteAddr())
+		return serviceUnavailableResponse, gnet.Close
+	}
+	if !s.Ready() {
+		logx.Debug("Server not ready, reject: ", c.RemoteAddr())
+		return serviceUnavailableResponse, gnet.Close
+	}
+	// 连接的用户数据记录协议版本，在首个请求或版本协商时确定
+	c.SetContext(&connState{})
 	return nil, gnet.None
 }
 
+// SetReadyAt 设置就绪时间，在此之前拒绝新连接，可用于等待缓存预热等启动准备完成
+func (s *IntranetServer) SetReadyAt(t time.Time) {
+	atomic.StoreInt64(&s.readyAt, t.UnixNano())
+}
+
+// StopAccepting 停止接受新连接，已建立的连接上的请求继续处理，用于停机前排空请求
+func (s *IntranetServer) StopAccepting() {
+	atomic.StoreInt32(&s.draining, 1)
+}
+
+// Ready 返回服务器是否已就绪，可接受新连接
+func (s *IntranetServer) Ready() bool {
+	if atomic.LoadInt32(&s.draining) != 0 {
+		return false
+	}
+	readyAt := atomic.LoadInt64(&s.readyAt)
+	return readyAt == 0 || time.Now().UnixNano() >= readyAt
+}
+
 // OnClose 当连接关闭时调用
 //
 // 参数：
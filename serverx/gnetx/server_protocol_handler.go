@@ -26,30 +26,30 @@ import (
 
 	"github.com/garrickvan/event-matrix/serverx"
 	"github.com/garrickvan/event-matrix/utils/buffertool"
-	"github.com/garrickvan/event-matrix/utils/encryptx"
-	"github.com/garrickvan/event-matrix/utils/fastconv"
 	"github.com/garrickvan/event-matrix/utils/logx"
 	"github.com/panjf2000/gnet/v2"
 )
 
 const (
-	maxBufferSize         = 1024 * 1024     // 最大缓冲区大小，1MB
-	StatusGnetHeaderError = 40000           // GNet 协议头错误状态码
-	HEADER_LEN            = 8               // 消息头长度，包含4字节长度、1字节压缩标志、1字节协议版本、2字节CRC校验
-	PROTOCOL_VERSION      = 1               // 协议版本号
-	MESSAGE_SEND_TIMEOUT  = 5 * time.Second // 发送超时时间，单位秒
+	DEFAULT_MAX_MESSAGE_SIZE = 1024 * 1024            // 默认的单条消息最大字节数（含消息头），1MB
+	StatusGnetHeaderError    = 40000                  // GNet 协议头错误状态码
+	HEADER_LEN               = 8                      // 消息头长度，包含4字节长度、1字节压缩标志、1字节协议版本、2字节CRC校验
+	PROTOCOL_VERSION         = 1                      // 当前协议版本号，也是支持的最高版本
+	MESSAGE_SEND_TIMEOUT     = 5 * time.Second        // 发送超时时间，单位秒，同时作为请求包时间戳的默认容忍窗口
+	MAX_FUTURE_TIMESTAMP     = 100 * time.Millisecond // 请求包时间戳最多允许超前当前时间的范围，超出视为伪造的重放请求
 )
 
-// buildRpcHeader 构建RPC消息头（包含CRC校验）
-func buildRpcHeader(data []byte, compressed bool) []byte {
+// buildRpcHeader 使用当前协议版本构建RPC消息头（包含CRC校验），压缩标志为消息体使用的压缩算法
+func buildRpcHeader(data []byte, compression COMPRESSION) []byte {
+	return buildVersionedRpcHeader(data, compression, PROTOCOL_VERSION)
+}
+
+// buildVersionedRpcHeader 使用指定协议版本构建RPC消息头（包含CRC校验）
+func buildVersionedRpcHeader(data []byte, compression COMPRESSION, version uint8) []byte {
 	header := make([]byte, HEADER_LEN)
 	binary.BigEndian.PutUint32(header[:4], uint32(len(data))) // 添加消息长度
-	if compressed {                                           // 添加压缩标志
-		header[4] = 0x01
-	} else {
-		header[4] = 0x00
-	}
-	header[5] = PROTOCOL_VERSION // 添加协议版本号
+	header[4] = byte(compression)                             // 添加压缩标志
+	header[5] = version                                       // 添加协议版本号
 
 	// 计算前6字节的CRC16校验值
 	crc := crc16(header[:6])
@@ -58,10 +58,17 @@ func buildRpcHeader(data []byte, compressed bool) []byte {
 	return header
 }
 
-// parseHeader 解析RPC消息头（带CRC校验）
-func parseHeader(header []byte) (uint32, bool, error) {
+// parseHeader 解析RPC消息头（带CRC校验），返回消息体长度和压缩算法
+func parseHeader(header []byte) (uint32, COMPRESSION, error) {
+	dataLength, compression, _, err := parseVersionedHeader(header)
+	return dataLength, compression, err
+}
+
+// parseVersionedHeader 解析RPC消息头（带CRC校验），返回消息体长度、压缩算法和协议版本，
+// 协议版本在支持范围内即可通过，便于服务端先于客户端升级
+func parseVersionedHeader(header []byte) (uint32, COMPRESSION, uint8, error) {
 	if len(header) != HEADER_LEN {
-		return 0, false, errors.New("invalid header length")
+		return 0, COMPRESSION_NONE, 0, errors.New("invalid header length")
 	}
 
 	// 验证CRC校验码
@@ -70,27 +77,33 @@ func parseHeader(header []byte) (uint32, bool, error) {
 	actualCRC := crc16(dataPart)
 
 	if actualCRC != expectedCRC {
-		return 0, false, errors.New("header CRC check failed")
+		return 0, COMPRESSION_NONE, 0, errors.New("header CRC check failed")
 	}
 
 	// 解析长度和压缩标志
 	dataLength := binary.BigEndian.Uint32(header[:4])
-	var isCompressed bool
-	switch header[4] {
-	case 0x00:
-		isCompressed = false
-	case 0x01:
-		isCompressed = true
-	default:
-		return 0, false, errors.New("invalid compression flag")
+	compression := COMPRESSION(header[4])
+	if !compression.valid() {
+		return 0, COMPRESSION_NONE, 0, errors.New("invalid compression flag")
 	}
 
 	// 检查协议版本
-	if header[5] != PROTOCOL_VERSION {
-		return 0, false, errors.New("unsupported protocol version")
+	version := header[5]
+	if !versionSupported(version) {
+		return 0, COMPRESSION_NONE, 0, errors.New("unsupported protocol version")
 	}
 
-	return dataLength, isCompressed, nil
+	return dataLength, compression, version, nil
+}
+
+// packResponse 使用指定的压缩算法序列化响应，返回序列化数据和实际使用的压缩算法，
+// 不支持指定算法的响应实现回退为 Pack 的Snappy压缩
+func packResponse(resp serverx.ResponsePacket, compression COMPRESSION) ([]byte, COMPRESSION) {
+	if p, ok := resp.(interface{ PackWith(COMPRESSION) []byte }); ok {
+		return p.PackWith(compression), compression
+	}
+	compressed := compression != COMPRESSION_NONE
+	return resp.Pack(compressed), compressionOf(compressed)
 }
 
 // crc16 CRC16-CCITT算法实现（多项式0x1021，初始值0xFFFF）
@@ -117,7 +130,19 @@ var invalidHeaderResponse = func() []byte {
 		ContentType: serverx.CONTENT_TYPE_STRING,
 	}
 	data := r.Pack(false)
-	header := buildRpcHeader(data, false)
+	header := buildRpcHeader(data, COMPRESSION_NONE)
+	return append(header, data...)
+}()
+
+// serviceUnavailableResponse 预构建的服务不可用响应消息
+// 当连接数达到上限或服务尚未就绪时返回此响应并关闭连接
+var serviceUnavailableResponse = func() []byte {
+	r := ResponsePacketImpl{
+		StatusCode:  http.StatusServiceUnavailable,
+		ContentType: serverx.CONTENT_TYPE_STRING,
+	}
+	data := r.Pack(false)
+	header := buildRpcHeader(data, COMPRESSION_NONE)
 	return append(header, data...)
 }()
 
@@ -135,6 +160,15 @@ var serverBusyResponse = &ResponsePacketImpl{
 	Payload:     "server busy, memory usage exceeds limit, please try again later",
 }
 
+// messageTooLargeResponse 构建消息超出大小限制时的响应
+func messageTooLargeResponse(size, limit int) *ResponsePacketImpl {
+	return &ResponsePacketImpl{
+		StatusCode:  http.StatusRequestEntityTooLarge,
+		ContentType: serverx.CONTENT_TYPE_STRING,
+		Payload:     fmt.Sprintf("message size %d bytes exceeds limit %d bytes", size, limit),
+	}
+}
+
 // OnTraffic 处理网络流量的核心方法
 // 实现了消息的接收、解析和处理流程
 func (s *IntranetServer) OnTraffic(c gnet.Conn) gnet.Action {
@@ -151,8 +185,8 @@ func (s *IntranetServer) OnTraffic(c gnet.Conn) gnet.Action {
 			return gnet.Close
 		}
 
-		// 解析消息头，获取消息体长度和压缩标志
-		bodyLen, compressed, err := parseHeader(header)
+		// 解析消息头，获取消息体长度、压缩算法和协议版本
+		bodyLen, compression, version, err := parseVersionedHeader(header)
 		if err != nil {
 			atomic.AddInt64(&s.errorCounter, 1)
 			// 处理无效的消息头
@@ -167,11 +201,20 @@ func (s *IntranetServer) OnTraffic(c gnet.Conn) gnet.Action {
 			}
 			return gnet.Close
 		}
+		// 未协商版本的连接按客户端首个请求的版本响应，兼容不支持握手的旧客户端
+		if state := connStateOf(c); state != nil {
+			atomic.CompareAndSwapUint32(&state.version, 0, uint32(version))
+		}
 		fullLen := HEADER_LEN + int(bodyLen)
 
-		// 检查消息大小是否超出限制
-		if fullLen > maxBufferSize {
-			logx.Error("Message size", fullLen, " exceeds limit")
+		// 检查消息大小是否超出限制，超出时响应413后关闭连接，未读取的消息体无法跳过
+		if limit := s.MaxMessageSize(); fullLen > limit {
+			atomic.AddInt64(&s.errorCounter, 1)
+			logx.Error(fmt.Sprintf("Message from %s is %d bytes, exceeds max message size %d bytes, closing connection", c.RemoteAddr(), fullLen, limit))
+			respData, respCompression := packResponse(messageTooLargeResponse(fullLen, limit), compression)
+			if _, err = c.Write(append(buildVersionedRpcHeader(respData, respCompression, connVersion(c)), respData...)); err == nil {
+				c.Flush()
+			}
 			return gnet.Close
 		}
 
@@ -200,7 +243,7 @@ func (s *IntranetServer) OnTraffic(c gnet.Conn) gnet.Action {
 		//     bufRelease()
 		//     s.sendResponse(c, serverBusyResponse, false)
 		// }
-		go s.asyncProcess(c, bodyBuf, bufRelease, compressed)
+		go s.asyncProcess(c, bodyBuf, bufRelease, compression)
 
 		// 丢弃已处理的消息
 		if _, err = c.Discard(fullLen); err != nil {
@@ -212,53 +255,59 @@ func (s *IntranetServer) OnTraffic(c gnet.Conn) gnet.Action {
 
 // asyncProcess 异步处理请求
 // 负责解包、解密、路由处理和响应发送的完整流程
-func (s *IntranetServer) asyncProcess(c gnet.Conn, msg []byte, bufRelease func(), compressed bool) {
+func (s *IntranetServer) asyncProcess(c gnet.Conn, msg []byte, bufRelease func(), compression COMPRESSION) {
+	var (
+		stats  *EventTypeStats // 当前请求所属事件类型的统计，解包成功后赋值
+		failed bool
+		start  = time.Now()
+	)
 	defer func() {
 		if r := recover(); r != nil {
+			failed = true
 			atomic.AddInt64(&s.errorCounter, 1)
 			logx.Error(fmt.Sprintf("Process panic: %v\n%s", r, debug.Stack()))
-			s.sendErrorResponse(c, http.StatusInternalServerError, "server error", compressed)
+			s.sendErrorResponse(c, http.StatusInternalServerError, "server error", compression)
+		}
+		if stats != nil {
+			latency := time.Since(start)
+			stats.record(failed, latency)
+			s.metrics.observe(stats.Type, failed, latency)
 		}
 		bufRelease() // 释放缓冲区资源，此处导致底层的字符串内存会回收至缓冲池，所以Body是临时数据
 	}()
 
-	// 解包请求
-	req, err := UnPackRequest(msg[HEADER_LEN:], compressed)
-	if err != nil {
-		logx.Debug(err)
+	// 解包、解密请求，ping、版本协商请求和处理失败的请求直接响应
+	req, resp, agreed := decodeRequest(
+		msg[HEADER_LEN:], compression, s.packetTolerance, s.MaxMessageSize(), s.intranetSecret, s.algorithm,
+	)
+	if req == nil {
+		logx.Debug(resp.TemporaryData())
 		atomic.AddInt64(&s.errorCounter, 1)
-		s.sendErrorResponse(c, http.StatusBadRequest, err.Error(), compressed)
+		s.sendResponse(c, resp, compression)
 		return
 	}
-
-	// 处理ping请求
-	if req.Type() == serverx.CONTENT_TYPE_PING {
-		s.sendResponse(c, pingResponse, false)
+	if isControlRequest(req) {
+		// 版本协商成功时将协商的版本记录在连接的用户数据上
+		if agreed != 0 {
+			if state := connStateOf(c); state != nil {
+				atomic.StoreUint32(&state.version, uint32(agreed))
+			}
+		}
+		s.sendResponse(c, resp, COMPRESSION_NONE)
 		return
 	}
-
-	// 解密请求数据
-	decrypted, err := encryptx.Decrypt(
-		fastconv.StringToBytes(req.TemporaryData()),
-		s.intranetSecret,
-		s.algorithm,
-	)
-	if err != nil {
+	stats = s.typeStats(s.resolveType(req))
+	if resp != nil {
+		failed = true
 		atomic.AddInt64(&s.errorCounter, 1)
-		s.sendErrorResponse(c, http.StatusForbidden, "decryption failed", compressed)
+		s.sendResponse(c, resp, compression)
 		return
 	}
-
-	// 解包请求数据
-	if pkg, ok := req.(*RequestPacketImpl); ok {
-		pkg.Payload = fastconv.BytesToString(decrypted)
-	} else {
-		atomic.AddInt64(&s.errorCounter, 1)
-		s.sendErrorResponse(c, http.StatusBadRequest, "invalid request implementation", compressed)
-		return
+	if s.recorder != nil {
+		s.recorder.Record(req)
 	}
 	// 调用路由函数处理请求
-	resp := s.router(req, c, s.routerImpl)
+	resp = s.router(req, c, s.routerImpl)
 	if resp == nil {
 		ctx := NewRequestContext(c, req)
 		if s.unHandleFunc != nil {
@@ -266,43 +315,26 @@ func (s *IntranetServer) asyncProcess(c gnet.Conn, msg []byte, bufRelease func()
 			resp = ctx.response
 		}
 		if resp == nil {
-			resp = &ResponsePacketImpl{
-				StatusCode:  http.StatusNotImplemented,
-				ContentType: serverx.CONTENT_TYPE_STRING,
-				Payload:     "unimplemented intranet request",
-			}
+			resp = unimplementedResponse()
 		}
 	}
+	failed = isErrorResponse(resp)
 	// 发送响应
-	s.sendResponse(c, resp, compressed)
+	s.sendResponse(c, resp, compression)
 }
 
 // sendResponse 发送响应
 // 负责加密响应数据并异步写入连接
-func (s *IntranetServer) sendResponse(c gnet.Conn, resp serverx.ResponsePacket, compressed bool) {
-	if len(resp.TemporaryData()) != 0 {
-		// 加密响应数据
-		encrypted, err := encryptx.Encrypt(
-			fastconv.StringToBytes(resp.TemporaryData()),
-			s.intranetSecret,
-			s.algorithm,
-		)
-		if err != nil {
-			logx.Error("Encrypt response failed: ", err)
-			return
-		}
-		if pkg, ok := resp.(*ResponsePacketImpl); ok {
-			pkg.Payload = fastconv.BytesToString(encrypted)
-		} else {
-			logx.Debug("Invalid response implementation")
-		}
+func (s *IntranetServer) sendResponse(c gnet.Conn, resp serverx.ResponsePacket, compression COMPRESSION) {
+	// 加密并打包响应数据，使用与请求相同的压缩算法
+	respData, compression, err := encodeResponse(resp, compression, s.intranetSecret, s.algorithm)
+	if err != nil {
+		logx.Error("Encrypt response failed: ", err)
+		return
 	}
 
-	// 打包响应数据
-	var respData []byte = resp.Pack(compressed)
-
-	// 构建响应头并发送响应
-	header := buildRpcHeader(respData, compressed)
+	// 使用连接的协议版本构建响应头并发送响应
+	header := buildVersionedRpcHeader(respData, compression, connVersion(c))
 	fullData := append(header, respData...)
 
 	if err := c.AsyncWrite(fullData, func(c gnet.Conn, err error) error {
@@ -318,11 +350,6 @@ func (s *IntranetServer) sendResponse(c gnet.Conn, resp serverx.ResponsePacket,
 
 // sendErrorResponse 发送错误响应
 // 封装错误信息为响应消息并发送
-func (s *IntranetServer) sendErrorResponse(c gnet.Conn, status int, msg string, compressed bool) {
-	resp := &ResponsePacketImpl{
-		StatusCode:  status,
-		ContentType: serverx.CONTENT_TYPE_STRING,
-		Payload:     msg,
-	}
-	s.sendResponse(c, resp, compressed)
+func (s *IntranetServer) sendErrorResponse(c gnet.Conn, status int, msg string, compression COMPRESSION) {
+	s.sendResponse(c, errorResponse(status, msg), compression)
 }
@@ -0,0 +1,158 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package gnetx
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/panjf2000/gnet/v2"
+)
+
+// stormConn 模拟新建连接
+type stormConn struct {
+	gnet.Conn
+}
+
+func (c *stormConn) SetContext(ctx interface{}) {}
+
+func (c *stormConn) RemoteAddr() net.Addr {
+	return &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 10000}
+}
+
+func TestOnOpenConnectionLimit(t *testing.T) {
+	s := NewIntranetServer("limit", 0, "", "NONE", nil, nil)
+	s.SetMaxConnections(50)
+
+	var accepted, rejected int64
+	var wg sync.WaitGroup
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			out, action := s.OnOpen(&stormConn{})
+			if action == gnet.Close {
+				atomic.AddInt64(&rejected, 1)
+				resp, err := UnPackResponse(out[HEADER_LEN:], COMPRESSION_NONE)
+				if err != nil || resp.Status() != http.StatusServiceUnavailable {
+					t.Errorf("拒绝连接时应返回503响应: %v %v", resp, err)
+				}
+				s.OnClose(&stormConn{}, nil)
+				return
+			}
+			atomic.AddInt64(&accepted, 1)
+		}()
+	}
+	wg.Wait()
+	if accepted != 50 || rejected != 150 {
+		t.Fatalf("期望接受50个、拒绝150个连接，实际接受 %d 拒绝 %d", accepted, rejected)
+	}
+	if s.ConnectionCount() != 50 {
+		t.Fatalf("被拒绝的连接关闭后连接数应为50，实际 %d", s.ConnectionCount())
+	}
+
+	// 释放连接后可再次接入
+	s.OnClose(&stormConn{}, nil)
+	if _, action := s.OnOpen(&stormConn{}); action != gnet.None {
+		t.Fatal("连接数低于上限时应接受新连接")
+	}
+}
+
+func TestSetMaxConnectionsDefault(t *testing.T) {
+	s := NewIntranetServer("limit", 0, "", "NONE", nil, nil)
+	s.SetMaxConnections(0)
+	if s.maxConnections != DEFAULT_MAX_CONNECTIONS {
+		t.Fatalf("非正数应使用默认最大连接数，实际 %d", s.maxConnections)
+	}
+}
+
+func TestOnOpenReadiness(t *testing.T) {
+	s := NewIntranetServer("ready", 0, "", "NONE", nil, nil)
+	if _, action := s.OnOpen(&stormConn{}); action != gnet.None || !s.Ready() {
+		t.Fatal("未设置就绪时间时应立即接受连接")
+	}
+	s.OnClose(&stormConn{}, nil)
+
+	s.SetReadyAt(time.Now().Add(100 * time.Millisecond))
+	out, action := s.OnOpen(&stormConn{})
+	if action != gnet.Close {
+		t.Fatal("就绪前应拒绝连接")
+	}
+	if resp, err := UnPackResponse(out[HEADER_LEN:], COMPRESSION_NONE); err != nil || resp.Status() != http.StatusServiceUnavailable {
+		t.Fatalf("就绪前应返回503响应: %v %v", resp, err)
+	}
+	s.OnClose(&stormConn{}, nil)
+
+	time.Sleep(150 * time.Millisecond)
+	if _, action := s.OnOpen(&stormConn{}); action != gnet.None {
+		t.Fatal("到达就绪时间后应接受连接")
+	}
+	if s.ConnectionCount() != 1 {
+		t.Errorf("连接数统计错误: %d", s.ConnectionCount())
+	}
+}
+
+func TestStopAccepting(t *testing.T) {
+	s := NewIntranetServer("drain", 0, "", "NONE", nil, nil)
+	s.StopAccepting()
+	if s.Ready() {
+		t.Fatal("停止接受新连接后不应就绪")
+	}
+	if _, action := s.OnOpen(&stormConn{}); action != gnet.Close {
+		t.Fatal("停止接受新连接后应拒绝连接")
+	}
+}
+
+// trafficConn 模拟收到数据的连接，记录写出的响应
+type trafficConn struct {
+	stormConn
+	inbound []byte
+	written []byte
+}
+
+func (c *trafficConn) Context() interface{} { return nil }
+func (c *trafficConn) InboundBuffered() int { return len(c.inbound) }
+func (c *trafficConn) Flush() error         { return nil }
+func (c *trafficConn) Peek(n int) ([]byte, error) {
+	return c.inbound[:n], nil
+}
+func (c *trafficConn) Write(b []byte) (int, error) {
+	c.written = append(c.written, b...)
+	return len(b), nil
+}
+
+func TestOnTrafficMaxMessageSize(t *testing.T) {
+	s := NewIntranetServer("size", 0, "", "NONE", nil, nil)
+	if s.MaxMessageSize() != DEFAULT_MAX_MESSAGE_SIZE {
+		t.Fatalf("未设置时应使用默认最大消息大小，实际 %d", s.MaxMessageSize())
+	}
+	s.SetMaxMessageSize(64)
+
+	body := make([]byte, 100)
+	c := &trafficConn{inbound: append(buildRpcHeader(body, COMPRESSION_NONE), body...)}
+	if action := s.OnTraffic(c); action != gnet.Close {
+		t.Fatal("消息超出大小限制时应关闭连接")
+	}
+	if len(c.written) < HEADER_LEN {
+		t.Fatal("关闭连接前应返回413响应")
+	}
+	resp, err := UnPackResponse(c.written[HEADER_LEN:], COMPRESSION_NONE)
+	if err != nil || resp.Status() != http.StatusRequestEntityTooLarge {
+		t.Fatalf("应返回413响应: %v %v", resp, err)
+	}
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/protocol/http1/resp"
 	"github.com/garrickvan/event-matrix/constant"
 	"github.com/garrickvan/event-matrix/core"
 	"github.com/garrickvan/event-matrix/serverx"
@@ -36,6 +37,7 @@ type RequestContext struct {
 	entityEvent *core.EntityEvent   // 实体事件对象
 	hertzCtx    *app.RequestContext // Hertz框架的请求上下文
 	tmpData     interface{}         // 临时数据存储
+	streamed    bool                // 是否已流式写出响应
 }
 
 // NewRequestContext 创建一个新的RequestContext实例
@@ -98,6 +100,11 @@ func (r *RequestContext) Header(key string) string {
 	return r.hertzCtx.Request.Header.Get(key)
 }
 
+// Query 返回URL查询参数中指定键的值
+func (r *RequestContext) Query(key string) string {
+	return r.hertzCtx.Query(key)
+}
+
 // SetHeader 设置响应头中的键值对
 func (r *RequestContext) SetHeader(key, value string) {
 	r.hertzCtx.Response.Header.Set(key, value)
@@ -217,6 +224,37 @@ func (r *RequestContext) OpenSSEResponse(cross bool) network.ExtWriter {
 	return r.hertzCtx.GetResponse().GetHijackWriter()
 }
 
+// BatchResponse 以分块传输逐行写出NDJSON响应，每写出一行立即刷新到连接
+func (r *RequestContext) BatchResponse(iter func(yield func(row map[string]interface{}) bool)) error {
+	r.hertzCtx.Response.Header.Set("Content-Type", serverx.NDJSON_CONTENT_TYPE)
+	if r.status == 0 {
+		r.hertzCtx.SetStatusCode(http.StatusOK)
+	} else {
+		r.hertzCtx.SetStatusCode(r.status)
+	}
+	writer := resp.NewChunkedBodyWriter(&r.hertzCtx.Response, r.hertzCtx.GetWriter())
+	r.hertzCtx.Response.HijackWriter(writer)
+	r.streamed = true
+	var err error
+	iter(func(row map[string]interface{}) bool {
+		line, e := jsonx.MarshalToBytes(row)
+		if e == nil {
+			_, e = writer.Write(append(line, '\n'))
+		}
+		if e == nil {
+			e = writer.Flush()
+		}
+		err = e
+		return e == nil
+	})
+	return err
+}
+
+// Streamed 返回是否已通过 BatchResponse 写出响应
+func (r *RequestContext) Streamed() bool {
+	return r.streamed
+}
+
 // CtxImpl 返回底层的Hertz请求上下文
 // 用于需要直接操作底层框架时的类型断言
 func (r *RequestContext) CtxImpl() interface{} {
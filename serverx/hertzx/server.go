@@ -23,6 +23,7 @@ import (
 
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/config"
 	"github.com/cloudwego/hertz/pkg/common/hlog"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
 	"github.com/garrickvan/event-matrix/serverx"
@@ -48,16 +49,17 @@ type PublicServer struct {
 // 参数：
 //   - port: 监听端口
 //   - serverId: 服务器唯一标识
+//   - opts: 额外的Hertz配置项，如 server.WithTLS
 //
 // 返回值：
 //   - *PublicServer: 新创建的服务器实例
-func NewPublicServer(port int, serverId string) *PublicServer {
+func NewPublicServer(port int, serverId string, opts ...config.Option) *PublicServer {
 	hlog.SetLevel(hlog.LevelWarn)
 	ps := &PublicServer{
 		serverId: serverId,
 		port:     port,
 		hz: server.Default(
-			server.WithHostPorts(fmt.Sprintf(":%d", port)),
+			append([]config.Option{server.WithHostPorts(fmt.Sprintf(":%d", port))}, opts...)...,
 		),
 		unHandle: defaultUnHandle,
 	}
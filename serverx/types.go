@@ -76,6 +76,10 @@ const (
 	CONTENT_TYPE_JSON CONTENT_TYPE = 1
 	// CONTENT_TYPE_STRING 表示普通字符串内容
 	CONTENT_TYPE_STRING CONTENT_TYPE = 2
+	// CONTENT_TYPE_BINARY 表示二进制内容，如图片、protobuf消息，负载不做字符串转换
+	CONTENT_TYPE_BINARY CONTENT_TYPE = 3
+	// CONTENT_TYPE_HANDSHAKE 表示协议版本协商类型，客户端在新建连接时发送
+	CONTENT_TYPE_HANDSHAKE CONTENT_TYPE = 4
 )
 
 // RequestContext 定义了请求上下文接口，封装了请求和响应的处理方法
@@ -135,8 +139,15 @@ type RequestContext interface {
 
 	// CtxImpl 获取底层实现对象，用于类型断言获取具体实现
 	CtxImpl() interface{}
+
+	// BatchResponse 以NDJSON格式逐行写出响应，每行一个JSON对象，避免在内存中构建完整的结果数组
+	// iter 每产出一行调用一次 yield，yield 返回 false 时写出已失败，iter 应停止迭代
+	BatchResponse(iter func(yield func(row map[string]interface{}) bool)) error
 }
 
+// NDJSON_CONTENT_TYPE 逐行JSON响应的内容类型
+const NDJSON_CONTENT_TYPE = "application/x-ndjson"
+
 // RequestPacket 定义处理请求包的接口
 type RequestPacket interface {
 	// Pack 将请求包序列化为二进制数据
@@ -154,6 +165,9 @@ type RequestPacket interface {
 	// TemporaryData 获取请求包的临时数据，当前请求结束即回收
 	TemporaryData() string
 
+	// RawBody 获取请求包的原始负载字节，二进制负载不经过字符串转换，当前请求结束即回收
+	RawBody() []byte
+
 	// IP 获取请求包的来源IP
 	IP() string
 
@@ -0,0 +1,189 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// eventctl 在命令行向运行中的工作端提交测试事件，无需经过网关
+//
+// 用法：
+//
+//	go run ./tools/eventctl --endpoint 127.0.0.1:8001 --event event.json
+//	go run ./tools/eventctl --event event.json --dry-run
+//
+// 事件文件为 core.Event 的JSON格式，params 可以直接写成对象；
+// 未填写的 id、createdAt、source 会自动补全，签名总是重新生成。
+// 密钥和加密算法默认读取环境变量 IntranetSecret、IntranetSecretAlgor
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/serverx"
+	"github.com/garrickvan/event-matrix/utils"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/intranet/dispatcher"
+	"github.com/garrickvan/event-matrix/worker/types"
+	"github.com/spf13/cast"
+)
+
+// options 命令行参数
+type options struct {
+	endpoint  string
+	secret    string
+	algor     string
+	eventFile string
+	compress  bool
+	dryRun    bool
+	verbose   bool
+	timeout   time.Duration
+
+	// dialer 自定义连接创建方法，为空时使用TCP，测试时替换为 net.Pipe
+	dialer func(endpoint string) (net.Conn, error)
+}
+
+func main() {
+	opts := options{}
+	flag.StringVar(&opts.endpoint, "endpoint", "127.0.0.1:8001", "工作端内域地址")
+	flag.StringVar(&opts.secret, "secret", utils.GetEnv("IntranetSecret"), "内域通信密钥")
+	flag.StringVar(&opts.algor, "algor", utils.GetEnv("IntranetSecretAlgor"), "内域通信加密算法")
+	flag.StringVar(&opts.eventFile, "event", "", "事件JSON文件路径")
+	flag.BoolVar(&opts.compress, "compress", false, "是否启用压缩")
+	flag.BoolVar(&opts.dryRun, "dry-run", false, "只输出签名后的事件，不发送")
+	flag.BoolVar(&opts.verbose, "verbose", false, "输出请求和响应头信息")
+	flag.DurationVar(&opts.timeout, "timeout", 10*time.Second, "请求超时时间")
+	flag.Parse()
+
+	if opts.eventFile == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	logx.InitRuntimeLogger(filepath.Join(os.TempDir(), "eventctl"), "error", "eventctl", time.Hour)
+	if err := run(opts, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "eventctl:", err)
+		os.Exit(1)
+	}
+}
+
+// run 读取并签名事件，dry-run 时输出事件，否则发送到工作端并输出响应
+func run(opts options, out io.Writer) error {
+	data, err := os.ReadFile(opts.eventFile)
+	if err != nil {
+		return err
+	}
+	event, err := loadEvent(data)
+	if err != nil {
+		return err
+	}
+	signed, err := jsonx.MarshalToStr(event)
+	if err != nil {
+		return err
+	}
+	if opts.dryRun {
+		fmt.Fprintln(out, prettyJson(signed))
+		return nil
+	}
+
+	if !utils.IsEndpoint(opts.endpoint) {
+		return errors.New("invalid endpoint: " + opts.endpoint)
+	}
+	dispatcher.InitClient(1, time.Minute, opts.timeout, opts.endpoint, "127.0.0.1", opts.secret, opts.algor, opts.compress)
+	if opts.dialer != nil {
+		dispatcher.SetDialer(opts.dialer)
+	}
+	if opts.verbose {
+		fmt.Fprintf(out, "> endpoint: %s\n", opts.endpoint)
+		fmt.Fprintf(out, "> intranet-type: %d\n", types.W_T_W_EVENT_CALL)
+		fmt.Fprintf(out, "> compress: %v\n", opts.compress)
+		fmt.Fprintf(out, "> event: %s\n", event.GetFullEventLabel())
+	}
+	start := time.Now()
+	resp, err := dispatcher.Event(opts.endpoint, types.W_T_W_EVENT_CALL, signed, nil)
+	if err != nil {
+		return err
+	}
+	if opts.verbose {
+		fmt.Fprintf(out, "< status: %d\n", resp.Status())
+		fmt.Fprintf(out, "< content-type: %s\n", contentTypeName(resp.Type()))
+		fmt.Fprintf(out, "< cost: %s\n", time.Since(start))
+	}
+	fmt.Fprintln(out, prettyJson(resp.TemporaryData()))
+	if resp.Status() >= 400 {
+		return fmt.Errorf("request failed with status %d", resp.Status())
+	}
+	return nil
+}
+
+// loadEvent 解析事件文件并补全签名所需字段
+func loadEvent(data []byte) (*core.Event, error) {
+	raw := map[string]interface{}{}
+	if err := jsonx.UnmarshalFromBytes(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid event file: %w", err)
+	}
+	// 允许 params 直接写成JSON对象
+	if params, ok := raw["params"]; ok && params != nil {
+		if _, isStr := params.(string); !isStr {
+			paramsStr, err := jsonx.MarshalToStr(params)
+			if err != nil {
+				return nil, err
+			}
+			raw["params"] = paramsStr
+		}
+	}
+	event := core.NewEventFromMap(raw)
+	if event.Project == "" || event.Version == "" || event.Context == "" || event.Entity == "" || event.Event == "" {
+		return nil, errors.New("event requires project, version, context, entity and event")
+	}
+	if event.ID == "" {
+		event.ID = utils.GenID()
+	}
+	if event.Source == "" {
+		event.Source = "eventctl"
+	}
+	if cast.ToInt64(event.CreatedAt) == 0 {
+		event.CreatedAt = time.Now().UnixMilli()
+	}
+	event.GenerateSign()
+	return event, nil
+}
+
+// prettyJson 格式化JSON字符串，非JSON内容原样返回
+func prettyJson(s string) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(s), "", "  "); err != nil {
+		return s
+	}
+	return buf.String()
+}
+
+func contentTypeName(t serverx.CONTENT_TYPE) string {
+	switch t {
+	case serverx.CONTENT_TYPE_JSON:
+		return "json"
+	case serverx.CONTENT_TYPE_STRING:
+		return "string"
+	case serverx.CONTENT_TYPE_PING:
+		return "ping"
+	default:
+		return cast.ToString(int(t))
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/serverx"
+	"github.com/garrickvan/event-matrix/serverx/gnetx"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/types"
+	"github.com/spf13/cast"
+)
+
+const testSecret = "eventctl-test-secret"
+
+func TestMain(m *testing.M) {
+	dir, _ := os.MkdirTemp("", "eventctl")
+	logx.InitRuntimeLogger(dir, "error", "eventctl-test", time.Hour)
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func writeEventFile(t *testing.T) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "event.json")
+	content := `{"project":"shop","version":"v1","context":"user","entity":"account","event":"query","params":{"name":"tom"}}`
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+// pipeDialer 返回 net.Pipe 的一端，另一端由 gnetx.ServeConn 按内域协议处理
+func pipeDialer(t *testing.T, handler func(req serverx.RequestPacket) serverx.ResponsePacket) func(string) (net.Conn, error) {
+	return func(endpoint string) (net.Conn, error) {
+		client, server := net.Pipe()
+		go func() {
+			if err := gnetx.ServeConn(server, testSecret, "", handler); err != nil {
+				t.Log("serve conn:", err)
+			}
+		}()
+		return client, nil
+	}
+}
+
+func TestRunSendsSignedEvent(t *testing.T) {
+	var received *core.Event
+	handler := func(req serverx.RequestPacket) serverx.ResponsePacket {
+		if req.Extend() != cast.ToString(int(types.W_T_W_EVENT_CALL)) {
+			return &gnetx.ResponsePacketImpl{StatusCode: http.StatusBadRequest, ContentType: serverx.CONTENT_TYPE_STRING, Payload: "unexpected type"}
+		}
+		event, err := core.NewEventFromStr(req.TemporaryData())
+		if err != nil || !event.VerifySign() {
+			return &gnetx.ResponsePacketImpl{StatusCode: http.StatusUnauthorized, ContentType: serverx.CONTENT_TYPE_STRING, Payload: "invalid sign"}
+		}
+		received = event
+		return &gnetx.ResponsePacketImpl{
+			StatusCode:  http.StatusOK,
+			ContentType: serverx.CONTENT_TYPE_JSON,
+			Payload:     `{"code":"OK","list":[{"name":"tom"}]}`,
+		}
+	}
+
+	out := &bytes.Buffer{}
+	err := run(options{
+		endpoint:  "127.0.0.1:18001",
+		secret:    testSecret,
+		eventFile: writeEventFile(t),
+		verbose:   true,
+		timeout:   3 * time.Second,
+		dialer:    pipeDialer(t, handler),
+	}, out)
+	if err != nil {
+		t.Fatalf("run failed: %v, output: %s", err, out.String())
+	}
+	if received == nil {
+		t.Fatal("server did not receive event")
+	}
+	if received.Source != "eventctl" || received.ID == "" || received.Params != `{"name":"tom"}` {
+		t.Errorf("unexpected event: %+v", received)
+	}
+	output := out.String()
+	for _, want := range []string{"> intranet-type: 1", "< status: 200", "< content-type: json", `"code": "OK"`} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestRunReturnsErrorOnFailedStatus(t *testing.T) {
+	handler := func(req serverx.RequestPacket) serverx.ResponsePacket {
+		return &gnetx.ResponsePacketImpl{StatusCode: http.StatusNotFound, ContentType: serverx.CONTENT_TYPE_STRING, Payload: "event not found"}
+	}
+	out := &bytes.Buffer{}
+	err := run(options{
+		endpoint:  "127.0.0.1:18001",
+		secret:    testSecret,
+		eventFile: writeEventFile(t),
+		timeout:   3 * time.Second,
+		dialer:    pipeDialer(t, handler),
+	}, out)
+	if err == nil {
+		t.Fatal("expected error for 404 response")
+	}
+	if !strings.Contains(out.String(), "event not found") {
+		t.Errorf("unexpected output: %s", out.String())
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	out := &bytes.Buffer{}
+	if err := run(options{eventFile: writeEventFile(t), dryRun: true}, out); err != nil {
+		t.Fatal(err)
+	}
+	event, err := core.NewEventFromStr(out.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !event.VerifySign() {
+		t.Error("dry-run event sign is invalid")
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// loadtest 回放 RecordInterceptor 录制的内域流量，输出延迟分位数、吞吐量和错误率
+//
+// 用法：
+//
+//	go run ./tools/loadtest -endpoint 127.0.0.1:8001 -file traffic.jsonl -c 50 -qps 2000
+//
+// 密钥和加密算法默认读取环境变量 IntranetSecret、IntranetSecretAlgor
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/garrickvan/event-matrix/serverx/gnetx"
+	"github.com/garrickvan/event-matrix/utils"
+)
+
+func main() {
+	cfg := gnetx.LoadTestConfig{}
+	flag.StringVar(&cfg.Endpoint, "endpoint", "127.0.0.1:8001", "目标服务的内域地址")
+	flag.StringVar(&cfg.File, "file", "", "录制的JSONL流量文件")
+	flag.IntVar(&cfg.Concurrency, "c", 10, "并发数")
+	flag.IntVar(&cfg.QPS, "qps", 0, "每秒请求数上限，0表示不限制")
+	flag.IntVar(&cfg.Rounds, "rounds", 1, "回放轮数")
+	flag.StringVar(&cfg.Secret, "secret", utils.GetEnv("IntranetSecret"), "内域通信密钥")
+	flag.StringVar(&cfg.Algor, "algor", utils.GetEnv("IntranetSecretAlgor"), "内域通信加密算法")
+	flag.BoolVar(&cfg.Compress, "compress", false, "是否启用压缩")
+	flag.DurationVar(&cfg.Timeout, "timeout", 30*time.Second, "单个请求超时时间")
+	flag.Parse()
+
+	if cfg.File == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Ctrl+C 停止发送新请求，输出已完成部分的结果
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := gnetx.NewLoadTester(cfg).Run(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "load test failed:", err)
+		os.Exit(1)
+	}
+	fmt.Println(report.String())
+}
@@ -15,15 +15,19 @@
 package cachex
 
 import (
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/dgraph-io/ristretto"
+	"github.com/dgraph-io/ristretto/z"
 )
 
 // LocalCache 基于ristretto实现的本地缓存
 type LocalCache struct {
 	cache      *ristretto.Cache
 	defaultTTL time.Duration
+	keys       sync.Map // 键哈希 -> 已写入的键，ristretto只保存键的哈希，需要单独记录以便排查问题
 }
 
 // InitCache 初始化本地缓存
@@ -41,6 +45,9 @@ func (lc *LocalCache) InitCache(maxMen int64, defaultTimeout int) error {
 		MaxCost:            maxMen,      // 50 * (1 << 20) maximum cost of cache (50 M).
 		BufferItems:        64,          // number of keys per Get buffer.
 		IgnoreInternalCost: false,
+		// 淘汰、过期或被策略拒绝时同步移除记录的键，避免keys无限增长
+		OnEvict:  lc.untrack,
+		OnReject: lc.untrack,
 	})
 	if err != nil {
 		return err
@@ -66,6 +73,35 @@ func (lc *LocalCache) Get(key string) (interface{}, bool) {
 	return nil, false
 }
 
+// GetWithExpiry 获取缓存值及其过期的绝对时间
+// 参数:
+//
+//	key: 缓存键
+//
+// 返回:
+//
+//	interface{}: 缓存值
+//	time.Time: 过期时间，永不过期时为零值
+//	bool: 是否命中缓存
+func (lc *LocalCache) GetWithExpiry(key string) (interface{}, time.Time, bool) {
+	if lc.cache == nil {
+		return nil, time.Time{}, false
+	}
+	val, ok := lc.cache.Get(key)
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	ttl, ok := lc.cache.GetTTL(key)
+	if !ok {
+		// 读取期间已过期
+		return nil, time.Time{}, false
+	}
+	if ttl <= 0 {
+		return val, time.Time{}, true
+	}
+	return val, time.Now().Add(ttl), true
+}
+
 // GetOrHook 获取缓存值，若不存在则调用hook函数获取并缓存
 // 参数:
 //
@@ -77,6 +113,21 @@ func (lc *LocalCache) Get(key string) (interface{}, bool) {
 //	interface{}: 缓存值或hook返回值
 //	bool: 是否成功获取值
 func (lc *LocalCache) GetOrHook(key string, hook func() interface{}) (interface{}, bool) {
+	return lc.GetOrHookWithTTL(key, hook, lc.defaultTTL)
+}
+
+// GetOrHookWithTTL 获取缓存值，若不存在则调用hook函数获取并按指定的TTL缓存
+// 参数:
+//
+//	key: 缓存键
+//	hook: 获取数据的回调函数
+//	ttl: 过期时间，小于等于0时使用默认TTL
+//
+// 返回:
+//
+//	interface{}: 缓存值或hook返回值
+//	bool: 是否成功获取值
+func (lc *LocalCache) GetOrHookWithTTL(key string, hook func() interface{}, ttl time.Duration) (interface{}, bool) {
 	if lc.cache == nil {
 		return nil, false
 	}
@@ -87,7 +138,10 @@ func (lc *LocalCache) GetOrHook(key string, hook func() interface{}) (interface{
 	if data == nil {
 		return nil, false
 	}
-	lc.cache.SetWithTTL(key, data, 0, lc.defaultTTL)
+	if ttl <= 0 {
+		ttl = lc.defaultTTL
+	}
+	lc.track(key, func() bool { return lc.cache.SetWithTTL(key, data, 0, ttl) })
 	return data, true
 }
 
@@ -102,7 +156,7 @@ func (lc *LocalCache) GetOrHook(key string, hook func() interface{}) (interface{
 //	bool: 是否设置成功
 func (lc *LocalCache) Put(key string, value interface{}) bool {
 	if lc.cache != nil {
-		return lc.cache.SetWithTTL(key, value, 0, lc.defaultTTL)
+		return lc.track(key, func() bool { return lc.cache.SetWithTTL(key, value, 0, lc.defaultTTL) })
 	}
 	return false
 }
@@ -119,7 +173,7 @@ func (lc *LocalCache) Put(key string, value interface{}) bool {
 //	bool: 是否设置成功
 func (lc *LocalCache) PutWithTTL(key string, value interface{}, ttl time.Duration) bool {
 	if lc.cache != nil {
-		return lc.cache.SetWithTTL(key, value, 0, ttl)
+		return lc.track(key, func() bool { return lc.cache.SetWithTTL(key, value, 0, ttl) })
 	}
 	return false
 }
@@ -135,7 +189,7 @@ func (lc *LocalCache) PutWithTTL(key string, value interface{}, ttl time.Duratio
 //	bool: 是否设置成功
 func (lc *LocalCache) PutPermanent(key string, value interface{}) bool {
 	if lc.cache != nil {
-		return lc.cache.Set(key, value, 0)
+		return lc.track(key, func() bool { return lc.cache.Set(key, value, 0) })
 	}
 	return false
 }
@@ -147,6 +201,7 @@ func (lc *LocalCache) PutPermanent(key string, value interface{}) bool {
 func (lc *LocalCache) Del(key string) {
 	if lc.cache != nil {
 		lc.cache.Del(key)
+		lc.keys.Delete(keyHash(key))
 	}
 }
 
@@ -154,6 +209,10 @@ func (lc *LocalCache) Del(key string) {
 func (lc *LocalCache) Flush() {
 	if lc.cache != nil {
 		lc.cache.Clear()
+		lc.keys.Range(func(k, _ interface{}) bool {
+			lc.keys.Delete(k)
+			return true
+		})
 	}
 }
 
@@ -164,3 +223,59 @@ func (lc *LocalCache) Flush() {
 func (lc *LocalCache) GetCacheInstance() *ristretto.Cache {
 	return lc.cache
 }
+
+// Keys 获取当前缓存中的所有键(按字典序排序)
+// 已过期或被淘汰的键会在调用时被清理，仅用于调试排查，不建议在热路径中调用
+// 返回:
+//
+//	[]string: 缓存键列表
+func (lc *LocalCache) Keys() []string {
+	keys := make([]string, 0)
+	if lc.cache == nil {
+		return keys
+	}
+	// 等待异步写入完成，避免刚写入的键被误判为已淘汰
+	lc.cache.Wait()
+	lc.keys.Range(func(h, k interface{}) bool {
+		key := k.(string)
+		if _, exists := lc.cache.Get(key); exists {
+			keys = append(keys, key)
+		} else {
+			lc.keys.Delete(h)
+		}
+		return true
+	})
+	sort.Strings(keys)
+	return keys
+}
+
+// Size 获取当前缓存中的键数量
+// 返回:
+//
+//	int: 键数量
+func (lc *LocalCache) Size() int {
+	return len(lc.Keys())
+}
+
+// track 记录写入的键
+// 先记录再写入，避免异步淘汰回调早于记录执行而遗留已被淘汰的键
+func (lc *LocalCache) track(key string, set func() bool) bool {
+	h := keyHash(key)
+	lc.keys.Store(h, key)
+	if !set() {
+		lc.keys.Delete(h)
+		return false
+	}
+	return true
+}
+
+// untrack 移除被ristretto淘汰、过期清理或拒绝写入的键
+func (lc *LocalCache) untrack(item *ristretto.Item) {
+	lc.keys.Delete(item.Key)
+}
+
+// keyHash 计算与ristretto一致的键哈希
+func keyHash(key string) uint64 {
+	h, _ := z.KeyToHash(key)
+	return h
+}
@@ -0,0 +1,139 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cachex
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLocalCacheKeys(t *testing.T) {
+	lc := &LocalCache{}
+	if err := lc.InitCache(1<<20, 60); err != nil {
+		t.Fatalf("failed to init local cache: %v", err)
+	}
+	if keys := lc.Keys(); len(keys) != 0 {
+		t.Fatalf("expected empty keys, got %v", keys)
+	}
+
+	for _, k := range []string{"b", "a", "c"} {
+		key := k
+		lc.GetOrHook(key, func() interface{} { return key })
+	}
+	// hook返回nil时不应写入缓存
+	lc.GetOrHook("nil", func() interface{} { return nil })
+
+	if keys := lc.Keys(); !reflect.DeepEqual(keys, []string{"a", "b", "c"}) {
+		t.Fatalf("unexpected keys: %v", keys)
+	}
+
+	lc.Del("b")
+	if keys := lc.Keys(); !reflect.DeepEqual(keys, []string{"a", "c"}) {
+		t.Fatalf("unexpected keys after Del: %v", keys)
+	}
+	if lc.Size() != 2 {
+		t.Fatalf("expected size 2, got %d", lc.Size())
+	}
+
+	lc.Flush()
+	if lc.Size() != 0 {
+		t.Fatalf("expected size 0 after Flush, got %d", lc.Size())
+	}
+}
+
+func TestLocalCacheGetOrHookWithTTL(t *testing.T) {
+	lc := &LocalCache{}
+	if err := lc.InitCache(1<<20, 60); err != nil {
+		t.Fatalf("failed to init local cache: %v", err)
+	}
+	calls := map[string]int{}
+	get := func(key string, ttl time.Duration) {
+		lc.GetOrHookWithTTL(key, func() interface{} {
+			calls[key]++
+			return key
+		}, ttl)
+		lc.GetCacheInstance().Wait()
+	}
+	get("short", time.Second)
+	get("default", 0)
+	get("short", time.Second)
+	get("default", 0)
+	if calls["short"] != 1 || calls["default"] != 1 {
+		t.Fatalf("expected cache hits before expiry, got %v", calls)
+	}
+
+	time.Sleep(1100 * time.Millisecond)
+	get("short", time.Second)
+	get("default", 0)
+	if calls["short"] != 2 {
+		t.Fatalf("expected refetch after short ttl expired, got %d", calls["short"])
+	}
+	if calls["default"] != 1 {
+		t.Fatalf("expected default ttl entry still cached, got %d", calls["default"])
+	}
+}
+
+func TestLocalCacheGetWithExpiry(t *testing.T) {
+	lc := &LocalCache{}
+	if err := lc.InitCache(1<<20, 60); err != nil {
+		t.Fatalf("failed to init local cache: %v", err)
+	}
+	before := time.Now()
+	lc.PutWithTTL("short", "v", 5*time.Second)
+	lc.PutPermanent("forever", "v")
+	lc.GetCacheInstance().Wait()
+
+	val, expiry, ok := lc.GetWithExpiry("short")
+	if !ok || val != "v" {
+		t.Fatalf("expected cached value, got %v, %v", val, ok)
+	}
+	want := before.Add(5 * time.Second)
+	if d := expiry.Sub(want); d < -time.Second || d > time.Second {
+		t.Fatalf("expected expiry near %v, got %v", want, expiry)
+	}
+
+	if _, expiry, ok := lc.GetWithExpiry("forever"); !ok || !expiry.IsZero() {
+		t.Fatalf("expected zero expiry for permanent entry, got %v, %v", expiry, ok)
+	}
+	if _, _, ok := lc.GetWithExpiry("missing"); ok {
+		t.Fatalf("expected miss for unknown key")
+	}
+}
+
+func TestLocalCacheEvictionUntracksKeys(t *testing.T) {
+	lc := &LocalCache{}
+	if err := lc.InitCache(4096, 60); err != nil {
+		t.Fatalf("failed to init local cache: %v", err)
+	}
+	for i := 0; i < 2000; i++ {
+		lc.Put(fmt.Sprintf("key-%d", i), i)
+	}
+	lc.GetCacheInstance().Wait()
+
+	// 不调用Keys，淘汰回调本身应已移除被淘汰的键
+	tracked := 0
+	lc.keys.Range(func(_, k interface{}) bool {
+		tracked++
+		if _, ok := lc.Get(k.(string)); !ok {
+			t.Errorf("evicted key %v is still tracked", k)
+		}
+		return true
+	})
+	if tracked == 0 || tracked >= 2000 {
+		t.Fatalf("expected evictions to trim tracked keys, got %d", tracked)
+	}
+}
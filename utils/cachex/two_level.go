@@ -0,0 +1,148 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cachex
+
+import (
+	"time"
+)
+
+// RedisCache 二级缓存接口，通常由Redis客户端适配实现
+// 序列化和反序列化由具体实现负责，TwoLevelCache只关心取值和写值
+type RedisCache interface {
+	// Get 获取缓存值，第二个返回值表示是否命中
+	Get(key string) (interface{}, bool)
+	// Set 设置缓存值，ttl为0时表示使用实现方的默认过期时间
+	Set(key string, value interface{}, ttl time.Duration) bool
+	// Del 删除缓存值
+	Del(key string)
+}
+
+// TwoLevelCache 二级缓存，L1为本地内存缓存，L2为Redis等远程缓存
+// 用于降低工作服务器重启后本地缓存为空带来的冷启动延迟
+type TwoLevelCache struct {
+	l1 *LocalCache
+	l2 RedisCache
+}
+
+// NewTwoLevelCache 创建二级缓存
+// 参数:
+//
+//	l1: 本地缓存，不能为空
+//	l2: 远程缓存，为nil时退化为仅使用本地缓存
+//
+// 返回:
+//
+//	*TwoLevelCache: 二级缓存实例
+func NewTwoLevelCache(l1 *LocalCache, l2 RedisCache) *TwoLevelCache {
+	return &TwoLevelCache{
+		l1: l1,
+		l2: l2,
+	}
+}
+
+// Get 获取缓存值，依次查找L1和L2，L2命中时回填L1
+// 参数:
+//
+//	key: 缓存键
+//
+// 返回:
+//
+//	interface{}: 缓存值
+//	bool: 是否命中缓存
+func (tc *TwoLevelCache) Get(key string) (interface{}, bool) {
+	if tc.l1 != nil {
+		if data, exists := tc.l1.Get(key); exists && data != nil {
+			return data, true
+		}
+	}
+	if tc.l2 != nil {
+		if data, exists := tc.l2.Get(key); exists && data != nil {
+			if tc.l1 != nil {
+				tc.l1.Put(key, data)
+			}
+			return data, true
+		}
+	}
+	return nil, false
+}
+
+// GetOrHook 获取缓存值，L1、L2均未命中时调用hook函数获取并写入两级缓存
+// 参数:
+//
+//	key: 缓存键
+//	hook: 获取数据的回调函数
+//
+// 返回:
+//
+//	interface{}: 缓存值或hook返回值
+//	bool: 是否成功获取值
+func (tc *TwoLevelCache) GetOrHook(key string, hook func() interface{}) (interface{}, bool) {
+	if data, exists := tc.Get(key); exists {
+		return data, true
+	}
+	data := hook()
+	if data == nil {
+		return nil, false
+	}
+	tc.Put(key, data)
+	return data, true
+}
+
+// Put 同时设置两级缓存的值(使用默认TTL)
+// 参数:
+//
+//	key: 缓存键
+//	value: 缓存值
+//
+// 返回:
+//
+//	bool: 是否至少有一级缓存设置成功
+func (tc *TwoLevelCache) Put(key string, value interface{}) bool {
+	ok := false
+	if tc.l1 != nil {
+		ok = tc.l1.Put(key, value)
+	}
+	if tc.l2 != nil {
+		var ttl time.Duration
+		if tc.l1 != nil {
+			ttl = tc.l1.defaultTTL
+		}
+		ok = tc.l2.Set(key, value, ttl) || ok
+	}
+	return ok
+}
+
+// Del 同时删除两级缓存的值
+// 参数:
+//
+//	key: 缓存键
+func (tc *TwoLevelCache) Del(key string) {
+	if tc.l1 != nil {
+		tc.l1.Del(key)
+	}
+	if tc.l2 != nil {
+		tc.l2.Del(key)
+	}
+}
+
+// L1 获取一级本地缓存
+func (tc *TwoLevelCache) L1() *LocalCache {
+	return tc.l1
+}
+
+// L2 获取二级远程缓存
+func (tc *TwoLevelCache) L2() RedisCache {
+	return tc.l2
+}
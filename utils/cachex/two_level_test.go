@@ -0,0 +1,133 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cachex
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// mapRedisCache 基于map的二级缓存，模拟Redis
+type mapRedisCache struct {
+	mu   sync.Mutex
+	data map[string]interface{}
+	gets int
+}
+
+func newMapRedisCache() *mapRedisCache {
+	return &mapRedisCache{data: make(map[string]interface{})}
+}
+
+func (m *mapRedisCache) Get(key string) (interface{}, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gets++
+	v, ok := m.data[key]
+	return v, ok
+}
+
+func (m *mapRedisCache) Set(key string, value interface{}, ttl time.Duration) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = value
+	return true
+}
+
+func (m *mapRedisCache) Del(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, key)
+}
+
+func newTestLocalCache(t *testing.T) *LocalCache {
+	lc := &LocalCache{}
+	if err := lc.InitCache(1<<20, 60); err != nil {
+		t.Fatalf("failed to init local cache: %v", err)
+	}
+	return lc
+}
+
+func TestTwoLevelCacheHookFallback(t *testing.T) {
+	l1 := newTestLocalCache(t)
+	l2 := newMapRedisCache()
+	tc := NewTwoLevelCache(l1, l2)
+
+	calls := 0
+	hook := func() interface{} {
+		calls++
+		return "value"
+	}
+	v, ok := tc.GetOrHook("k", hook)
+	if !ok || v != "value" {
+		t.Fatalf("unexpected result: %v, %v", v, ok)
+	}
+	l1.GetCacheInstance().Wait()
+
+	// hook结果应同时写入两级缓存
+	if _, ok := l1.Get("k"); !ok {
+		t.Fatal("expected value in L1")
+	}
+	if _, ok := l2.Get("k"); !ok {
+		t.Fatal("expected value in L2")
+	}
+
+	// 再次获取不应调用hook
+	if v, ok := tc.GetOrHook("k", hook); !ok || v != "value" || calls != 1 {
+		t.Fatalf("unexpected result: %v, %v, calls=%d", v, ok, calls)
+	}
+}
+
+func TestTwoLevelCacheL2HitBackfillsL1(t *testing.T) {
+	l1 := newTestLocalCache(t)
+	l2 := newMapRedisCache()
+	l2.Set("k", "from-redis", 0)
+	tc := NewTwoLevelCache(l1, l2)
+
+	v, ok := tc.GetOrHook("k", func() interface{} {
+		t.Fatal("hook should not be called on L2 hit")
+		return nil
+	})
+	if !ok || v != "from-redis" {
+		t.Fatalf("unexpected result: %v, %v", v, ok)
+	}
+	l1.GetCacheInstance().Wait()
+
+	if v, ok := l1.Get("k"); !ok || v != "from-redis" {
+		t.Fatalf("expected L1 backfilled, got %v, %v", v, ok)
+	}
+	gets := l2.gets
+	if _, ok := tc.Get("k"); !ok {
+		t.Fatal("expected hit")
+	}
+	if l2.gets != gets {
+		t.Fatal("L2 should not be queried on L1 hit")
+	}
+}
+
+func TestTwoLevelCacheWithoutL2(t *testing.T) {
+	tc := NewTwoLevelCache(newTestLocalCache(t), nil)
+	if v, ok := tc.GetOrHook("k", func() interface{} { return 1 }); !ok || v != 1 {
+		t.Fatalf("unexpected result: %v, %v", v, ok)
+	}
+	if _, ok := tc.GetOrHook("nil", func() interface{} { return nil }); ok {
+		t.Fatal("nil hook result should not be cached")
+	}
+	tc.L1().GetCacheInstance().Wait()
+	tc.Del("k")
+	if _, ok := tc.Get("k"); ok {
+		t.Fatal("expected miss after Del")
+	}
+}
@@ -27,6 +27,38 @@ import (
 	"github.com/garrickvan/event-matrix/utils/fastconv"
 )
 
+// 内域通信支持的加密算法，算法名称不区分大小写
+const (
+	NONE        = "NONE"
+	AES_128     = "AES-128"
+	AES_192     = "AES-192"
+	AES_256     = "AES-256"
+	AES_256_GCM = "AES-256-GCM" // 带认证的加密，密文被篡改时解密失败
+)
+
+// IsSupportedAlgor 判断是否为支持的加密算法，不支持的算法会按 AES-128 处理
+func IsSupportedAlgor(aesAlgor string) bool {
+	switch strings.ToUpper(aesAlgor) {
+	case "AES-128", "AES128", "AES", "AES-128-CBC", "AES128CBC",
+		"AES-192", "AES192", "AES-192-CBC", "AES192CBC",
+		"AES-256", "AES256", "AES-256-CBC", "AES256CBC",
+		"AES-256-GCM", "AES256GCM",
+		"HMAC-SHA256", "HMAC_SHA256", "HMACSHA256",
+		"NONE", "", "NULL", "NULL-CBC":
+		return true
+	}
+	return false
+}
+
+// isGCM 判断是否使用 GCM 模式
+func isGCM(aesAlgor string) bool {
+	switch strings.ToUpper(aesAlgor) {
+	case "AES-256-GCM", "AES256GCM":
+		return true
+	}
+	return false
+}
+
 // generateHash 根据给定的密钥和AES算法生成哈希值
 func generateHash(key []byte, aesAlgor string) []byte {
 	if len(key) == 0 {
@@ -42,7 +74,7 @@ func generateHash(key []byte, aesAlgor string) []byte {
 		hasher := sha256.New224()
 		hasher.Write(key)
 		return hasher.Sum(nil)[:24]
-	case "AES-256", "AES256", "AES-256-CBC", "AES256CBC":
+	case "AES-256", "AES256", "AES-256-CBC", "AES256CBC", "AES-256-GCM", "AES256GCM":
 		hasher := sha256.New()
 		hasher.Write(key)
 		return hasher.Sum(nil)
@@ -55,8 +87,14 @@ func generateHash(key []byte, aesAlgor string) []byte {
 	}
 }
 
-// Encrypt 使用给定的密钥和AES算法加密明文，返回密文
+// Encrypt 使用给定的密钥和AES算法加密明文，返回密文；HMAC-SHA256 时返回追加了校验码的明文
 func Encrypt(plaintext []byte, key, aesAlgor string) ([]byte, error) {
+	if isHMAC(aesAlgor) {
+		if key == "" {
+			return plaintext, nil
+		}
+		return signHMAC(plaintext, key), nil
+	}
 	keyBytes := generateHash(fastconv.StringToBytes(key), aesAlgor)
 	// If key is empty or aesAlgor is "NONE", return plaintext as is.
 	if len(keyBytes) == 0 {
@@ -66,6 +104,9 @@ func Encrypt(plaintext []byte, key, aesAlgor string) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
+	if isGCM(aesAlgor) {
+		return encryptGCM(block, plaintext)
+	}
 	padded := make([]byte, aes.BlockSize+len(plaintext))
 	iv := padded[:aes.BlockSize]
 	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
@@ -76,12 +117,25 @@ func Encrypt(plaintext []byte, key, aesAlgor string) ([]byte, error) {
 	return padded, nil
 }
 
-// Decrypt 使用给定的密钥和AES算法解密密文，返回明文
+// Decrypt 使用给定的密钥和AES算法解密密文，返回明文；HMAC-SHA256 时校验并去除校验码
 func Decrypt(ciphertext []byte, key, aesAlgor string) ([]byte, error) {
+	if isHMAC(aesAlgor) {
+		if key == "" {
+			return ciphertext, nil
+		}
+		return verifyHMAC(ciphertext, key)
+	}
 	keyBytes := generateHash(fastconv.StringToBytes(key), aesAlgor)
 	if len(keyBytes) == 0 {
 		return ciphertext, nil
 	}
+	if isGCM(aesAlgor) {
+		block, err := aes.NewCipher(keyBytes)
+		if err != nil {
+			return nil, err
+		}
+		return decryptGCM(block, ciphertext)
+	}
 	if len(ciphertext) < aes.BlockSize {
 		return nil, errors.New("ciphertext too short, must be at least 16 bytes")
 	}
@@ -95,3 +149,29 @@ func Decrypt(ciphertext []byte, key, aesAlgor string) ([]byte, error) {
 	stream.XORKeyStream(ciphertext, ciphertext)
 	return ciphertext, nil
 }
+
+// encryptGCM 使用 GCM 模式加密，随机 nonce 放在密文前
+func encryptGCM(block cipher.Block, plaintext []byte) ([]byte, error) {
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize(), gcm.NonceSize()+len(plaintext)+gcm.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// decryptGCM 解密 GCM 模式的密文，密文被篡改或密钥不一致时返回错误
+func decryptGCM(block cipher.Block, ciphertext []byte) ([]byte, error) {
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize()+gcm.Overhead() {
+		return nil, errors.New("ciphertext too short for AES-GCM")
+	}
+	nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, sealed, nil)
+}
@@ -0,0 +1,73 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryptx
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	for _, algor := range []string{NONE, AES_128, AES_192, AES_256, AES_256_GCM, "aes256gcm"} {
+		ciphertext, err := Encrypt(append([]byte{}, testPlaintext...), testKey, algor)
+		if err != nil {
+			t.Fatalf("%s: encrypt failed: %v", algor, err)
+		}
+		plaintext, err := Decrypt(ciphertext, testKey, algor)
+		if err != nil {
+			t.Fatalf("%s: decrypt failed: %v", algor, err)
+		}
+		if !bytes.Equal(plaintext, testPlaintext) {
+			t.Fatalf("%s: unexpected plaintext %q", algor, plaintext)
+		}
+	}
+}
+
+func TestAES256GCMRejectsTampering(t *testing.T) {
+	ciphertext, err := Encrypt(testPlaintext, testKey, AES_256_GCM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 相同明文每次加密使用不同的 nonce
+	other, _ := Encrypt(testPlaintext, testKey, AES_256_GCM)
+	if bytes.Equal(ciphertext, other) {
+		t.Fatal("expected random nonce for each encryption")
+	}
+
+	tampered := append([]byte{}, ciphertext...)
+	tampered[len(tampered)-1] ^= 0x01
+	if _, err := Decrypt(tampered, testKey, AES_256_GCM); err == nil {
+		t.Fatal("expected tampered ciphertext to be rejected")
+	}
+	if _, err := Decrypt(ciphertext, "wrong-key", AES_256_GCM); err == nil {
+		t.Fatal("expected decryption with wrong key to fail")
+	}
+	if _, err := Decrypt(ciphertext[:10], testKey, AES_256_GCM); err == nil {
+		t.Fatal("expected short ciphertext to be rejected")
+	}
+}
+
+func TestIsSupportedAlgor(t *testing.T) {
+	for _, algor := range []string{"", NONE, AES_128, "aes", AES_192, AES_256, AES_256_GCM, "AES256GCM"} {
+		if !IsSupportedAlgor(algor) {
+			t.Errorf("%q should be supported", algor)
+		}
+	}
+	for _, algor := range []string{"DES", "AES-128-GCM", "CHACHA20"} {
+		if IsSupportedAlgor(algor) {
+			t.Errorf("%q should not be supported", algor)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryptx
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"strings"
+
+	"github.com/garrickvan/event-matrix/utils/fastconv"
+)
+
+// HMAC_SHA256 仅做消息认证不加密，消息体以明文传输并在末尾追加 HMAC-SHA256 校验码，
+// 开销低于对称加密，适用于可信的内网环境
+const HMAC_SHA256 = "HMAC-SHA256"
+
+// isHMAC 判断是否使用 HMAC-SHA256 消息认证
+func isHMAC(algor string) bool {
+	switch strings.ToUpper(algor) {
+	case "HMAC-SHA256", "HMAC_SHA256", "HMACSHA256":
+		return true
+	}
+	return false
+}
+
+// signHMAC 在消息末尾追加 HMAC-SHA256 校验码
+func signHMAC(plaintext []byte, key string) []byte {
+	mac := hmac.New(sha256.New, fastconv.StringToBytes(key))
+	mac.Write(plaintext)
+	signed := make([]byte, len(plaintext), len(plaintext)+sha256.Size)
+	copy(signed, plaintext)
+	return mac.Sum(signed)
+}
+
+// verifyHMAC 校验并去除消息末尾的 HMAC-SHA256 校验码，消息被篡改或密钥不一致时返回错误
+func verifyHMAC(signed []byte, key string) ([]byte, error) {
+	if len(signed) < sha256.Size {
+		return nil, errors.New("message too short for HMAC-SHA256")
+	}
+	plaintext, sum := signed[:len(signed)-sha256.Size], signed[len(signed)-sha256.Size:]
+	mac := hmac.New(sha256.New, fastconv.StringToBytes(key))
+	mac.Write(plaintext)
+	if !hmac.Equal(sum, mac.Sum(nil)) {
+		return nil, errors.New("HMAC-SHA256 verification failed")
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryptx
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestHMACSHA256RoundTrip(t *testing.T) {
+	for _, algor := range []string{HMAC_SHA256, "HMAC_SHA256", "hmac-sha256"} {
+		if !IsSupportedAlgor(algor) {
+			t.Fatalf("%q should be supported", algor)
+		}
+		signed, err := Encrypt(testPlaintext, testKey, algor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 仅认证不加密，消息体保持明文
+		if len(signed) != len(testPlaintext)+32 || !bytes.HasPrefix(signed, testPlaintext) {
+			t.Fatalf("%s: unexpected signed message %q", algor, signed)
+		}
+		plaintext, err := Decrypt(signed, testKey, algor)
+		if err != nil {
+			t.Fatalf("%s: verify failed: %v", algor, err)
+		}
+		if !bytes.Equal(plaintext, testPlaintext) {
+			t.Fatalf("%s: unexpected plaintext %q", algor, plaintext)
+		}
+	}
+}
+
+func TestHMACSHA256RejectsTampering(t *testing.T) {
+	signed, err := Encrypt(testPlaintext, testKey, HMAC_SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := append([]byte{}, signed...)
+	tampered[0] ^= 0x01
+	if _, err := Decrypt(tampered, testKey, HMAC_SHA256); err == nil {
+		t.Fatal("expected tampered message to be rejected")
+	}
+	if _, err := Decrypt(signed, "wrong-key", HMAC_SHA256); err == nil {
+		t.Fatal("expected verification with wrong key to fail")
+	}
+	if _, err := Decrypt(signed[:10], testKey, HMAC_SHA256); err == nil {
+		t.Fatal("expected short message to be rejected")
+	}
+}
+
+// benchmarkSealOpen 测量指定算法加密并解密一条消息的吞吐量
+func benchmarkSealOpen(b *testing.B, algor string) {
+	payload := bytes.Repeat(testPlaintext, 25) // 约1KB
+	b.SetBytes(int64(len(payload)))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sealed, err := Encrypt(payload, testKey, algor)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := Decrypt(sealed, testKey, algor); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkHMACSHA256(b *testing.B) {
+	benchmarkSealOpen(b, HMAC_SHA256)
+}
+
+func BenchmarkAES256GCM(b *testing.B) {
+	benchmarkSealOpen(b, AES_256_GCM)
+}
@@ -72,6 +72,36 @@ func IsEmail(email string) bool {
 	return reg.MatchString(email)
 }
 
+// regexpCacheLimit 正则表达式缓存的最大数量，超过后不再缓存新的表达式，避免外部传入的表达式导致内存无限增长
+const regexpCacheLimit = 1024
+
+var (
+	regexpCache     sync.Map
+	regexpCacheSize int64
+	regexpCacheMu   sync.Mutex
+)
+
+// CompileRegexp 编译正则表达式并缓存结果，相同的表达式只编译一次。
+// pattern: 正则表达式
+// 返回: 编译后的正则表达式，表达式不合法时返回错误
+func CompileRegexp(pattern string) (*regexp.Regexp, error) {
+	if re, ok := regexpCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexpCacheMu.Lock()
+	defer regexpCacheMu.Unlock()
+	if regexpCacheSize < regexpCacheLimit {
+		if _, loaded := regexpCache.LoadOrStore(pattern, re); !loaded {
+			regexpCacheSize++
+		}
+	}
+	return re, nil
+}
+
 // IsPhoneNumber 验证全球手机号格式，支持中国和其他国家手机号。
 // 如果没有+号前缀，默认视为中国手机号并自动添加+86前缀。
 // 使用E.164格式验证：+{国家代码}{本地号码}
@@ -79,9 +109,7 @@ func IsEmail(email string) bool {
 // 返回: 如果是有效手机号返回true，否则返回false
 func IsPhoneNumber(phoneNumber string) bool {
 	// 如果没有 + 号，默认视为中国手机号，补充 +86
-	if !strings.HasPrefix(phoneNumber, "+") {
-		phoneNumber = "+86" + phoneNumber
-	}
+	phoneNumber = NormalizePhoneNumber(phoneNumber)
 	// 使用正则表达式验证手机号格式
 	// E.164 格式：+{国家代码}{本地号码}
 	phoneNumberRegex := `^(\+)[1-9]\d{0,3}\d{3,15}$`
@@ -94,6 +122,37 @@ func IsPhoneNumber(phoneNumber string) bool {
 	return match
 }
 
+// NormalizePhoneNumber 将手机号规范为带+号前缀的格式，没有+号前缀时视为中国手机号并添加+86前缀，
+// 与 IsPhoneNumber 的默认规则保持一致
+func NormalizePhoneNumber(phoneNumber string) string {
+	if !strings.HasPrefix(phoneNumber, "+") {
+		return "+86" + phoneNumber
+	}
+	return phoneNumber
+}
+
+// HasPhoneCountryCode 判断手机号的国家代码是否在允许列表中，countryCodes 为逗号分隔的E.164国家代码，如 "+1,+44,+86"，
+// 国家代码可省略+号；允许列表为空时不做限制
+func HasPhoneCountryCode(phoneNumber, countryCodes string) bool {
+	if strings.TrimSpace(countryCodes) == "" {
+		return true
+	}
+	phoneNumber = NormalizePhoneNumber(phoneNumber)
+	for _, code := range strings.Split(countryCodes, ",") {
+		code = strings.TrimSpace(code)
+		if code == "" {
+			continue
+		}
+		if !strings.HasPrefix(code, "+") {
+			code = "+" + code
+		}
+		if strings.HasPrefix(phoneNumber, code) {
+			return true
+		}
+	}
+	return false
+}
+
 // GenID 生成一个唯一标识符，基于 ULID (Universally Unique Lexicographically Sortable Identifier)。
 // 生成的ID具有以下特性：
 // - 按时间排序
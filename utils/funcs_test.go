@@ -126,3 +126,52 @@ func TestIsEndpoint(t *testing.T) {
 		})
 	}
 }
+
+func TestCompileRegexp(t *testing.T) {
+	re1, err := CompileRegexp(`^[a-z]+$`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	re2, _ := CompileRegexp(`^[a-z]+$`)
+	if re1 != re2 {
+		t.Error("相同的表达式应使用缓存")
+	}
+	if !re1.MatchString("abc") || re1.MatchString("abc1") {
+		t.Error("正则匹配结果错误")
+	}
+	if _, err := CompileRegexp(`(`); err == nil {
+		t.Error("不合法的表达式应返回错误")
+	}
+}
+
+func TestInternationalPhone(t *testing.T) {
+	const allowed = "+1,+44,+86"
+	tests := []struct {
+		name    string
+		phone   string
+		codes   string
+		allowed bool
+		valid   bool
+	}{
+		{"US number", "+12025550123", allowed, true, true},
+		{"UK number", "+447911123456", allowed, true, true},
+		{"China number with prefix", "+8613800138000", allowed, true, true},
+		{"China number without prefix", "13800138000", allowed, true, true},
+		{"Codes without plus", "+447911123456", "1,44", true, true},
+		{"Country not allowed", "+33612345678", allowed, false, true},
+		{"China not allowed", "13800138000", "+1,+44", false, true},
+		{"Empty allowlist", "+33612345678", "", true, true},
+		{"Invalid format", "+44abc", allowed, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasPhoneCountryCode(tt.phone, tt.codes); got != tt.allowed {
+				t.Errorf("HasPhoneCountryCode(%q, %q) = %v, want %v", tt.phone, tt.codes, got, tt.allowed)
+			}
+			if got := IsPhoneNumber(tt.phone); got != tt.valid {
+				t.Errorf("IsPhoneNumber(%q) = %v, want %v", tt.phone, got, tt.valid)
+			}
+		})
+	}
+}
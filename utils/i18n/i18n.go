@@ -0,0 +1,122 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package i18n 提供错误提示等文本的多语言翻译
+// 内置中文和英文的消息文件，部署时可通过 LoadMessageFile 追加或覆盖翻译
+package i18n
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+)
+
+// DEFAULT_LOCALE 默认语言，未指定语言或找不到翻译时使用
+const DEFAULT_LOCALE = "zh"
+
+//go:embed messages/*.json
+var builtinMessages embed.FS
+
+var (
+	mu       sync.RWMutex
+	messages = map[string]map[string]string{} // 语言 -> 消息键 -> 消息模板
+)
+
+func init() {
+	entries, err := builtinMessages.ReadDir("messages")
+	if err != nil {
+		panic("读取内置多语言消息失败: " + err.Error())
+	}
+	for _, entry := range entries {
+		data, err := builtinMessages.ReadFile(path.Join("messages", entry.Name()))
+		if err != nil {
+			panic("读取内置多语言消息失败: " + err.Error())
+		}
+		if err := loadMessages(strings.TrimSuffix(entry.Name(), ".json"), data); err != nil {
+			panic("解析内置多语言消息失败: " + entry.Name() + " " + err.Error())
+		}
+	}
+}
+
+// LoadMessageFile 加载JSON格式的消息文件，内容为消息键到消息模板的映射
+// 已存在的消息键会被覆盖
+func LoadMessageFile(locale, filePath string) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	return loadMessages(NormalizeLocale(locale), data)
+}
+
+func loadMessages(locale string, data []byte) error {
+	msgs := map[string]string{}
+	if err := jsonx.UnmarshalFromBytes(data, &msgs); err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if messages[locale] == nil {
+		messages[locale] = map[string]string{}
+	}
+	for k, v := range msgs {
+		messages[locale][k] = v
+	}
+	return nil
+}
+
+// NormalizeLocale 规范化语言标识，支持 Accept-Language 格式，例如 "en-US,en;q=0.9" 返回 "en"
+// 为空时返回默认语言
+func NormalizeLocale(locale string) string {
+	locale = strings.TrimSpace(locale)
+	if i := strings.IndexAny(locale, ",;"); i >= 0 {
+		locale = locale[:i]
+	}
+	if i := strings.IndexAny(locale, "-_"); i >= 0 {
+		locale = locale[:i]
+	}
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if locale == "" || locale == "*" {
+		return DEFAULT_LOCALE
+	}
+	return locale
+}
+
+// Lookup 查找指定语言的翻译，不回退到默认语言
+func Lookup(key, locale string) (string, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	msg, ok := messages[NormalizeLocale(locale)][key]
+	return msg, ok
+}
+
+// Translate 翻译消息键，找不到指定语言时使用默认语言，仍找不到则返回消息键本身
+func Translate(key, locale string) string {
+	if msg, ok := Lookup(key, locale); ok {
+		return msg
+	}
+	if msg, ok := Lookup(key, DEFAULT_LOCALE); ok {
+		return msg
+	}
+	return key
+}
+
+// Translatef 翻译消息键并使用参数格式化消息模板
+func Translatef(key, locale string, args ...interface{}) string {
+	return fmt.Sprintf(Translate(key, locale), args...)
+}
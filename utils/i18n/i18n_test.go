@@ -0,0 +1,73 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalizeLocale(t *testing.T) {
+	cases := map[string]string{
+		"":                       DEFAULT_LOCALE,
+		"*":                      DEFAULT_LOCALE,
+		"en":                     "en",
+		"en-US,en;q=0.9":         "en",
+		"zh_CN":                  "zh",
+		" ZH-Hans ;q=0.8":        "zh",
+		"fr-FR,fr;q=0.9,en;q=.8": "fr",
+	}
+	for in, want := range cases {
+		if got := NormalizeLocale(in); got != want {
+			t.Errorf("NormalizeLocale(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	if got := Translatef("param.missing", "zh-CN", "name"); got != "缺少必要参数: name" {
+		t.Errorf("中文翻译错误: %s", got)
+	}
+	if got := Translatef("param.missing", "en-US,en;q=0.9", "name"); got != "Missing required parameter: name" {
+		t.Errorf("英文翻译错误: %s", got)
+	}
+	// 不支持的语言回退到默认语言
+	if got := Translatef("param.missing", "fr", "name"); got != "缺少必要参数: name" {
+		t.Errorf("未回退到默认语言: %s", got)
+	}
+	if got := Translate("not.exist", "en"); got != "not.exist" {
+		t.Errorf("找不到翻译时应返回消息键: %s", got)
+	}
+	if _, ok := Lookup("code.invalid_param", "zh"); ok {
+		t.Error("Lookup 不应回退到其它语言")
+	}
+}
+
+func TestLoadMessageFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "fr.json")
+	if err := os.WriteFile(file, []byte(`{"param.missing":"Paramètre manquant: %s"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadMessageFile("fr-FR", file); err != nil {
+		t.Fatalf("加载消息文件失败: %v", err)
+	}
+	if got := Translatef("param.missing", "fr", "name"); got != "Paramètre manquant: name" {
+		t.Errorf("加载的翻译未生效: %s", got)
+	}
+	if got := Translate("param.invalid", "fr"); got != "参数值不符合要求: %s" {
+		t.Errorf("缺失的翻译应回退到默认语言: %s", got)
+	}
+}
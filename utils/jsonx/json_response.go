@@ -25,13 +25,17 @@ import (
 // JsonResponse 定义了标准的JSON响应结构
 // 用于在API接口中返回统一格式的响应数据
 type JsonResponse struct {
-	Code      string        `json:"code"`      // 响应码，表示操作结果状态
-	CreatedAt int64         `json:"createdAt"` // 响应创建时间戳（毫秒）
-	Message   string        `json:"message"`   // 响应消息，对状态的文字描述
-	List      []interface{} `json:"list"`      // 响应数据列表
-	Total     int64         `json:"total"`     // 数据总数（用于分页）
-	Size      int           `json:"size"`      // 当前页数据大小
-	Page      int           `json:"page"`      // 当前页码
+	Code       string                   `json:"code"`                  // 响应码，表示操作结果状态
+	CreatedAt  int64                    `json:"createdAt"`             // 响应创建时间戳（毫秒）
+	Message    string                   `json:"message"`               // 响应消息，对状态的文字描述
+	List       []interface{}            `json:"list"`                  // 响应数据列表
+	Total      int64                    `json:"total"`                 // 数据总数（用于分页）
+	Size       int                      `json:"size"`                  // 当前页数据大小
+	Page       int                      `json:"page"`                  // 当前页码
+	DryRun     bool                     `json:"dry_run,omitempty"`     // 是否为试运行结果，试运行时数据不会落库
+	NextCursor string                   `json:"next_cursor,omitempty"` // 游标分页时下一页的游标，为空表示没有更多数据
+	Sql        string                   `json:"sql,omitempty"`         // SQL预览模式下生成的SQL语句，SQL未被执行
+	Explain    []map[string]interface{} `json:"explain,omitempty"`     // 查询计划，请求要求查看查询计划时返回
 }
 
 // SetSizeInfo 设置分页相关信息
@@ -16,11 +16,36 @@
 package jsonx
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/base64"
 	"errors"
+	"hash"
 	"strings"
+	"time"
+
+	"github.com/spf13/cast"
 )
 
+// JWTClaims JWT令牌中的声明集合
+type JWTClaims map[string]interface{}
+
+// UserId 返回令牌中直接携带的用户ID（uid声明）
+func (c JWTClaims) UserId() string {
+	return strings.TrimSpace(cast.ToString(c["uid"]))
+}
+
+// UserCode 返回令牌中的用户编码（u声明），需要通过网关换取用户ID
+func (c JWTClaims) UserCode() string {
+	return strings.TrimSpace(cast.ToString(c["u"]))
+}
+
+// ExpiresAt 返回令牌过期时间（exp声明，秒级时间戳），未设置时返回0
+func (c JWTClaims) ExpiresAt() int64 {
+	return cast.ToInt64(c["exp"])
+}
+
 // GetJwtTokenClaims 从JWT令牌中提取声明（claims）信息
 // 不验证令牌签名，仅解析payload部分获取包含的数据
 //
@@ -48,3 +73,63 @@ func GetJwtTokenClaims(token string) (map[string]interface{}, error) {
 	}
 	return claims, nil
 }
+
+// jwtHashes 支持的HMAC签名算法
+var jwtHashes = map[string]func() hash.Hash{
+	"HS256": sha256.New,
+	"HS384": sha512.New384,
+	"HS512": sha512.New,
+}
+
+// ParseJwtToken 使用HMAC密钥验证JWT令牌签名并解析声明，无需访问网关
+// 支持HS256、HS384、HS512签名算法，并校验exp、nbf声明
+//
+// 参数：
+//   - token: 完整的JWT令牌字符串
+//   - secret: HMAC签名密钥
+//
+// 返回：
+//   - JWTClaims: 验证通过后的令牌声明
+//   - error: 格式错误、签名不匹配或令牌已过期时返回错误
+func ParseJwtToken(token string, secret []byte) (JWTClaims, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("JWT密钥不能为空")
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("JWT格式异常")
+	}
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errors.New("Error decoding header: " + err.Error())
+	}
+	header := map[string]interface{}{}
+	if err := UnmarshalFromBytes(headerBytes, &header); err != nil {
+		return nil, errors.New("Error decoding header JSON: " + err.Error())
+	}
+	newHash, ok := jwtHashes[cast.ToString(header["alg"])]
+	if !ok {
+		return nil, errors.New("不支持的JWT签名算法：" + cast.ToString(header["alg"]))
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("Error decoding signature: " + err.Error())
+	}
+	mac := hmac.New(newHash, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return nil, errors.New("JWT签名验证失败")
+	}
+	claims, err := GetJwtTokenClaims(token)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	if exp := cast.ToInt64(claims["exp"]); exp > 0 && now >= exp {
+		return nil, errors.New("JWT令牌已过期")
+	}
+	if nbf := cast.ToInt64(claims["nbf"]); nbf > 0 && now < nbf {
+		return nil, errors.New("JWT令牌尚未生效")
+	}
+	return claims, nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonx
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+// signHS256 生成测试用的HS256令牌
+func signHS256(t *testing.T, claims map[string]interface{}, secret string) string {
+	t.Helper()
+	payload, err := MarshalToStr(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) +
+		"." + base64.RawURLEncoding.EncodeToString([]byte(payload))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestParseJwtToken(t *testing.T) {
+	secret := []byte("s3cret")
+	exp := time.Now().Add(time.Hour).Unix()
+	token := signHS256(t, map[string]interface{}{"uid": "user-1", "u": "code-1", "exp": exp}, "s3cret")
+
+	claims, err := ParseJwtToken(token, secret)
+	if err != nil {
+		t.Fatalf("ParseJwtToken failed: %v", err)
+	}
+	if claims.UserId() != "user-1" || claims.UserCode() != "code-1" || claims.ExpiresAt() != exp {
+		t.Errorf("unexpected claims: %v", claims)
+	}
+
+	if _, err := ParseJwtToken(token, []byte("other")); err == nil {
+		t.Error("token signed with another secret should be rejected")
+	}
+	expired := signHS256(t, map[string]interface{}{"uid": "user-1", "exp": time.Now().Add(-time.Minute).Unix()}, "s3cret")
+	if _, err := ParseJwtToken(expired, secret); err == nil {
+		t.Error("expired token should be rejected")
+	}
+	none := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString([]byte(`{"uid":"user-1"}`)) + "."
+	if _, err := ParseJwtToken(none, secret); err == nil {
+		t.Error("unsigned token should be rejected")
+	}
+	if _, err := ParseJwtToken("invalid", secret); err == nil {
+		t.Error("malformed token should be rejected")
+	}
+}
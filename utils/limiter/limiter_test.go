@@ -126,3 +126,38 @@ func TestUnblockRateLimiter(t *testing.T) {
 		time.Sleep(1 * time.Second)
 	}
 }
+
+// TestTokenBucketLimiter 测试令牌桶限流器的突发、补充和按键隔离
+func TestTokenBucketLimiter(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	l := NewTokenBucketLimiter(2, 3)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.Allow("a"); !ok {
+			t.Fatalf("request %d within burst should be allowed", i)
+		}
+	}
+	ok, wait := l.Allow("a")
+	if ok || wait != 500*time.Millisecond {
+		t.Fatalf("expected rejection with 500ms wait, got %v %v", ok, wait)
+	}
+	// 其他键使用独立的令牌桶
+	if ok, _ := l.Allow("b"); !ok {
+		t.Fatal("other key should be allowed")
+	}
+	// 半秒补充一个令牌
+	now = now.Add(500 * time.Millisecond)
+	if ok, _ := l.Allow("a"); !ok {
+		t.Fatal("refilled token should be allowed")
+	}
+	if ok, _ := l.Allow("a"); ok {
+		t.Fatal("bucket should be empty again")
+	}
+	// 空闲桶补满后被清理
+	now = now.Add(TOKEN_BUCKET_SWEEP_GAP)
+	l.Allow("c")
+	if _, has := l.buckets["a"]; has {
+		t.Fatal("idle bucket should be swept")
+	}
+}
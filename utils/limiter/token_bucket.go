@@ -0,0 +1,106 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package limiter
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimiter 是按键限流的非阻塞限流器
+type RateLimiter interface {
+	// Allow 检查键对应的请求是否允许通过，不允许时返回建议的重试等待时间
+	Allow(key string) (bool, time.Duration)
+}
+
+// tokenBucket 单个键的令牌桶
+type tokenBucket struct {
+	tokens float64   // 当前令牌数
+	last   time.Time // 上次补充令牌的时间
+}
+
+// TokenBucketLimiter 基于令牌桶算法的非阻塞限流器
+// 特点:
+// - 每个键维护独立的令牌桶，令牌按 rps 匀速补充，最多累积 burst 个
+// - 桶内无令牌时直接拒绝，并返回下一个令牌可用前的等待时间
+// - 长时间未访问且已补满的桶会被定期清理，适合以调用方IP等无界集合为键
+type TokenBucketLimiter struct {
+	rps     float64 // 每秒补充的令牌数
+	burst   float64 // 桶容量
+	buckets map[string]*tokenBucket
+	sweepAt time.Time // 下次清理空闲桶的时间
+	mu      sync.Mutex
+	now     func() time.Time // 单元测试时可替换
+}
+
+// TOKEN_BUCKET_SWEEP_GAP 清理空闲令牌桶的间隔
+const TOKEN_BUCKET_SWEEP_GAP = time.Minute
+
+// NewTokenBucketLimiter 创建令牌桶限流器
+// rps: 每秒允许的请求数，小于等于0时按1处理
+// burst: 允许的突发请求数，小于 1 时取 rps
+func NewTokenBucketLimiter(rps int, burst int) *TokenBucketLimiter {
+	if rps <= 0 {
+		rps = 1
+	}
+	if burst < 1 {
+		burst = rps
+	}
+	return &TokenBucketLimiter{
+		rps:     float64(rps),
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// Allow 从键对应的令牌桶中取一个令牌
+// 返回: 是否允许通过，以及被拒绝时下一个令牌可用前的等待时间
+func (l *TokenBucketLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	} else if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(l.burst, b.tokens+elapsed*l.rps)
+		b.last = now
+	}
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / l.rps * float64(time.Second))
+	return false, wait
+}
+
+// sweep 清理已补满的空闲令牌桶，补满的桶与新建的桶等价，删除不影响限流结果
+func (l *TokenBucketLimiter) sweep(now time.Time) {
+	if now.Before(l.sweepAt) {
+		return
+	}
+	l.sweepAt = now.Add(TOKEN_BUCKET_SWEEP_GAP)
+	fullAfter := time.Duration(l.burst / l.rps * float64(time.Second))
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= fullAfter {
+			delete(l.buckets, key)
+		}
+	}
+}
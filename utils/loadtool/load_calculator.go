@@ -168,3 +168,11 @@ func Init(sampleSize int) {
 func GetLoadRate() float64 {
 	return _calculator.CalculateLoadRate()
 }
+
+// CurrentLoadRate 返回当前设备的负载率，综合 CPU、内存和磁盘的占用，未调用 Init 时返回 0
+func CurrentLoadRate() float64 {
+	if _calculator == nil {
+		return 0
+	}
+	return _calculator.CalculateLoadRate()
+}
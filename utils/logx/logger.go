@@ -29,6 +29,7 @@ import (
 const (
 	LogTypeEvent   = "event"     // 事件日志类型
 	LogTypeRuntime = "runtime"   // 运行时日志类型
+	LogTypeAudit   = "audit"     // 审计日志类型
 	LogSuffix      = "slice_log" // 日志文件切片后缀
 )
 
@@ -36,7 +37,7 @@ const (
 type Logger struct {
 	logger   *zap.Logger
 	baseDir  string
-	logLevel zapcore.Level
+	logLevel zap.AtomicLevel
 	logType  string
 	serverId string
 	writer   *RotatingWriter
@@ -57,6 +58,7 @@ type LogEntry struct {
 var (
 	runtimeLogger *Logger // 全局运行时日志记录器，用于记录系统运行时信息
 	EventLogger   *Logger // 全局事件日志记录器，用于记录业务事件信息
+	AuditLogger   *Logger // 全局审计日志记录器，用于记录数据修改的审计信息
 )
 
 // NewLogger 创建一个新的日志记录器实例
@@ -90,10 +92,10 @@ func NewLogger(baseDir, logType, logLevel, serverId string, slicePeriod time.Dur
 		EncodeTime:   utcTimeEncoder,
 		EncodeCaller: zapcore.ShortCallerEncoder,
 	}
-	level := getLevelFromStr(logLevel)
+	level := zap.NewAtomicLevelAt(getLevelFromStr(logLevel))
 	var writeSyncer zapcore.WriteSyncer
 	// 开发模式下同时输出到控制台
-	if level == zap.DebugLevel {
+	if level.Level() == zap.DebugLevel {
 		writeSyncer = zapcore.NewMultiWriteSyncer(zapcore.AddSync(writer), zapcore.AddSync(os.Stderr))
 	} else {
 		writeSyncer = zapcore.AddSync(writer)
@@ -125,6 +127,11 @@ func utcTimeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
 	enc.AppendInt64(t.UTC().UnixNano() / int64(time.Millisecond))
 }
 
+// LevelFromStr 将字符串形式的日志级别转换为zap的日志级别枚举，未知级别返回warn级别
+func LevelFromStr(level string) zapcore.Level {
+	return getLevelFromStr(level)
+}
+
 // getLevelFromStr 将字符串形式的日志级别转换为zap的日志级别枚举
 // 支持debug、info、warn/warning、error、fatal五个级别
 // 默认返回warn级别
@@ -162,6 +169,16 @@ func InitEventLogger(baseDir, serverId string, slicePeriod time.Duration) {
 	EventLogger = NewLogger(baseDir, LogTypeEvent, "info", serverId, slicePeriod)
 }
 
+// InitAuditLogger 初始化全局审计日志记录器
+// 审计日志与事件日志分开切片，由日志中心通过独立的通道接收
+// 如果已存在审计日志记录器，会先关闭原有实例再创建新实例
+func InitAuditLogger(baseDir, serverId string, slicePeriod time.Duration) {
+	if AuditLogger != nil {
+		AuditLogger.shutdown()
+	}
+	AuditLogger = NewLogger(baseDir, LogTypeAudit, "info", serverId, slicePeriod)
+}
+
 // InitRuntimeLogger 初始化全局运行时日志记录器
 // 运行时日志记录器用于记录系统运行时的各种信息，支持不同日志级别
 // 如果已存在运行时日志记录器，会先关闭原有实例再创建新实例
@@ -172,6 +189,15 @@ func InitRuntimeLogger(baseDir, logLevel, serverId string, slicePeriod time.Dura
 	runtimeLogger = NewLogger(baseDir, LogTypeRuntime, logLevel, serverId, slicePeriod)
 }
 
+// SetRuntimeLogLevel 修改全局运行时日志记录器的日志级别，不重建日志文件，用于配置热更新
+// 调整为debug级别时不会额外输出到控制台；运行时日志记录器未初始化时忽略
+func SetRuntimeLogLevel(logLevel string) {
+	if runtimeLogger == nil {
+		return
+	}
+	runtimeLogger.logLevel.SetLevel(getLevelFromStr(logLevel))
+}
+
 // LogType 返回日志记录器的日志类型
 func (log *Logger) LogType() string {
 	return log.logType
@@ -238,7 +264,7 @@ func IsDebugging() bool {
 	if runtimeLogger == nil {
 		return false
 	}
-	return runtimeLogger.logLevel <= zap.DebugLevel
+	return runtimeLogger.logLevel.Level() <= zap.DebugLevel
 }
 
 // log 内部日志打印函数
@@ -251,7 +277,7 @@ func IsDebugging() bool {
 func log(level zapcore.Level, prefix string, a ...any) {
 	l := level
 	if runtimeLogger != nil {
-		l = runtimeLogger.logLevel
+		l = runtimeLogger.logLevel.Level()
 	}
 	if l <= level {
 		a = append([]any{prefix}, a...)
@@ -270,7 +296,7 @@ func log(level zapcore.Level, prefix string, a ...any) {
 func logf(level zapcore.Level, prefix, format string, a ...any) {
 	l := level
 	if runtimeLogger != nil {
-		l = runtimeLogger.logLevel
+		l = runtimeLogger.logLevel.Level()
 	}
 	if l <= level {
 		fmt.Printf(prefix+" "+format+"\n", a...)
@@ -0,0 +1,110 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package secretx 从外部密钥管理服务读取密钥，如 Vault、AWS SSM，避免在配置或环境变量中明文保存
+package secretx
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"sync"
+)
+
+// SCHEME_SEPARATOR 密钥引用的协议分隔符，如 vault://secret/data/event-matrix#intranet_secret
+const SCHEME_SEPARATOR = "://"
+
+// SecretProvider 密钥提供者，key 的格式由具体实现决定
+type SecretProvider interface {
+	Get(key string) (string, error)
+}
+
+// EnvSecretProvider 从环境变量读取密钥
+type EnvSecretProvider struct{}
+
+// Get 读取名为 key 的环境变量，未设置或为空时返回错误
+func (EnvSecretProvider) Get(key string) (string, error) {
+	val := os.Getenv(key)
+	if val == "" {
+		return "", errors.New("环境变量未设置: " + key)
+	}
+	return val, nil
+}
+
+// builtinSchemes 内置支持的密钥引用协议，ssm 需注册提供者后才能解析
+var builtinSchemes = map[string]bool{"vault": true, "env": true, "ssm": true}
+
+var (
+	providers = map[string]SecretProvider{}
+	mu        sync.RWMutex
+)
+
+// RegisterProvider 注册密钥引用协议对应的提供者，重复注册时覆盖。
+// vault 协议默认使用 VAULT_ADDR、VAULT_TOKEN 环境变量创建的 VaultSecretProvider，
+// ssm 等其他协议需自行实现 SecretProvider 后注册
+func RegisterProvider(scheme string, provider SecretProvider) {
+	mu.Lock()
+	defer mu.Unlock()
+	providers[strings.ToLower(scheme)] = provider
+}
+
+// IsReference 判断值是否为内置或已注册协议的密钥引用，如 vault://、env://，
+// 其他包含 :// 的值视为普通密钥
+func IsReference(value string) bool {
+	scheme, _, ok := strings.Cut(value, SCHEME_SEPARATOR)
+	if !ok {
+		return false
+	}
+	scheme = strings.ToLower(scheme)
+	if builtinSchemes[scheme] {
+		return true
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	_, registered := providers[scheme]
+	return registered
+}
+
+// Resolve 解析密钥引用，返回实际的密钥；不是密钥引用时原样返回
+func Resolve(value string) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+	scheme, key, _ := strings.Cut(value, SCHEME_SEPARATOR)
+	scheme = strings.ToLower(scheme)
+	provider, err := providerOf(scheme)
+	if err != nil {
+		return "", err
+	}
+	return provider.Get(key)
+}
+
+// providerOf 获取协议对应的提供者，vault 协议未注册时按环境变量创建
+func providerOf(scheme string) (SecretProvider, error) {
+	mu.RLock()
+	provider, ok := providers[scheme]
+	mu.RUnlock()
+	if ok {
+		return provider, nil
+	}
+	switch scheme {
+	case "vault":
+		RegisterProvider(scheme, NewVaultSecretProvider("", ""))
+	case "env":
+		RegisterProvider(scheme, EnvSecretProvider{})
+	default:
+		return nil, errors.New("未注册密钥提供者: " + scheme)
+	}
+	return providerOf(scheme)
+}
@@ -0,0 +1,73 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	if v, err := Resolve("plain-secret"); err != nil || v != "plain-secret" {
+		t.Fatalf("非密钥引用应原样返回: %s %v", v, err)
+	}
+	t.Setenv("EM_TEST_SECRET", "from-env")
+	if v, err := Resolve("env://EM_TEST_SECRET"); err != nil || v != "from-env" {
+		t.Fatalf("读取环境变量失败: %s %v", v, err)
+	}
+	if _, err := Resolve("ssm://event-matrix/intranet"); err == nil {
+		t.Fatal("未注册的协议应返回错误")
+	}
+	// 未知协议的值按普通密钥处理
+	for _, v := range []string{"abc://def", "p@ss://word"} {
+		if got, err := Resolve(v); err != nil || got != v {
+			t.Fatalf("未知协议应原样返回: %s %s %v", v, got, err)
+		}
+	}
+}
+
+func TestVaultSecretProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		if r.URL.Path != "/v1/secret/data/event-matrix" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"intranet_secret":"s3cr3t"},"metadata":{"version":1}}}`))
+	}))
+	defer srv.Close()
+
+	v := NewVaultSecretProvider(srv.URL, "token")
+	if got, err := v.Get("secret/data/event-matrix#intranet_secret"); err != nil || got != "s3cr3t" {
+		t.Fatalf("读取 Vault 密钥失败: %s %v", got, err)
+	}
+	if _, err := v.Get("secret/data/event-matrix#missing"); err == nil {
+		t.Error("字段不存在时应返回错误")
+	}
+	if _, err := NewVaultSecretProvider(srv.URL, "bad").Get("secret/data/event-matrix"); err == nil {
+		t.Error("令牌无效时应返回错误")
+	}
+
+	RegisterProvider("vault", v)
+	if got, err := Resolve("vault://secret/data/event-matrix#intranet_secret"); err != nil || got != "s3cr3t" {
+		t.Fatalf("按 vault 协议解析失败: %s %v", got, err)
+	}
+}
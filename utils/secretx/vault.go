@@ -0,0 +1,102 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/spf13/cast"
+)
+
+// VAULT_DEFAULT_FIELD 密钥引用未指定字段时读取的字段名
+const VAULT_DEFAULT_FIELD = "value"
+
+// VaultSecretProvider 通过 Vault HTTP API 读取 KV 引擎中的密钥，兼容 KV v1 和 v2。
+// key 的格式为 路径#字段，路径为 /v1/ 之后的部分，如 secret/data/event-matrix#intranet_secret，
+// 未指定字段时读取 value 字段
+type VaultSecretProvider struct {
+	addr   string
+	token  string
+	client *http.Client
+}
+
+// NewVaultSecretProvider 创建 Vault 密钥提供者，addr、token 为空时分别读取 VAULT_ADDR、VAULT_TOKEN 环境变量
+func NewVaultSecretProvider(addr, token string) *VaultSecretProvider {
+	if addr == "" {
+		addr = os.Getenv("VAULT_ADDR")
+	}
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	return &VaultSecretProvider{
+		addr:   strings.TrimRight(addr, "/"),
+		token:  token,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// vaultResponse KV v2 的密钥在 data.data 中，KV v1 直接在 data 中
+type vaultResponse struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []string               `json:"errors"`
+}
+
+// Get 读取 Vault 中的密钥
+func (v *VaultSecretProvider) Get(key string) (string, error) {
+	if v.addr == "" {
+		return "", errors.New("未配置 Vault 地址")
+	}
+	path, field, _ := strings.Cut(key, "#")
+	if field == "" {
+		field = VAULT_DEFAULT_FIELD
+	}
+	req, err := http.NewRequest(http.MethodGet, v.addr+"/v1/"+strings.TrimLeft(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	body := vaultResponse{}
+	if err := jsonx.UnmarshalFromBytes(raw, &body); err != nil {
+		return "", fmt.Errorf("解析 Vault 响应失败: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("读取 Vault 密钥失败: %d %s", resp.StatusCode, strings.Join(body.Errors, "; "))
+	}
+	data := body.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		data = nested
+	}
+	value := cast.ToString(data[field])
+	if value == "" {
+		return "", fmt.Errorf("Vault 密钥 %s 中没有字段 %s", path, field)
+	}
+	return value, nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/serverx"
+	"github.com/garrickvan/event-matrix/serverx/gnetx"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/worker/intranet/dispatcher"
+	"github.com/garrickvan/event-matrix/worker/public/hertzimpl"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+func TestRegisterWorkerWithAliases(t *testing.T) {
+	ws := newRegisterTestServer(t)
+	aliasReqs := make(chan string, 1)
+	dispatcher.SetDialer(func(endpoint string) (net.Conn, error) {
+		client, server := net.Pipe()
+		go gnetx.ServeConn(server, "", "NONE", func(req serverx.RequestPacket) serverx.ResponsePacket {
+			if body := req.TemporaryData(); strings.Contains(body, `"aliases"`) {
+				aliasReqs <- strings.Clone(body)
+			}
+			return &gnetx.ResponsePacketImpl{StatusCode: http.StatusOK, ContentType: serverx.CONTENT_TYPE_STRING, Payload: string(constant.SUCCESS)}
+		})
+		return client, nil
+	})
+
+	w := types.NewWorker("shop", "1.0.0", "order", "item", "", 0)
+	w.AliasEntities = []string{"goods", " product ", "item", "goods"}
+	w.SyncSchema = false
+	if err := ws.RegisterWorker(w); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, entity := range []string{"item", "goods", "product"} {
+		p := types.PathToEntity{Project: "shop", Version: "1.0.0", Context: "order", Entity: entity}
+		if got := ws.GetWorkerByEvent(p); got != w {
+			t.Errorf("实体 %s 应映射到同一个工作者", entity)
+		}
+	}
+	if workers := ws.registeredWorkers(); len(workers) != 1 {
+		t.Errorf("别名不应重复返回工作者: %d", len(workers))
+	}
+
+	select {
+	case body := <-aliasReqs:
+		for _, label := range []string{w.ID, "shop.order.goods@1.0.0", "shop.order.product@1.0.0"} {
+			if !strings.Contains(body, label) {
+				t.Errorf("别名注册请求缺少 %s: %s", label, body)
+			}
+		}
+	default:
+		t.Fatal("应向网关注册实体别名")
+	}
+}
+
+func TestPublicEventThroughAlias(t *testing.T) {
+	ws := newRegisterTestServer(t)
+	mock := types.NewMockWorkerServer(nil)
+	t.Cleanup(func() { mock.Stop() })
+	ws.domainCache = mock.DomainCache()
+	ws.Cfg().PublicPort = 18081
+	pub := hertzimpl.NewWorkerPublicServer(ws.Cfg(), ws)
+	ws.public = pub
+	h := pub.Impl().(*server.Hertz)
+
+	entity := types.PathToEntity{Project: "shop", Version: "1.0.0", Context: "order", Entity: "item"}
+	mock.SetEntityEvents(entity, []core.EntityEvent{{Code: "detail", ExecutorType: constant.CUSTOM_EXECUTOR, Executor: "detail"}})
+	received := make(chan *core.Event, 1)
+	w := types.NewWorker("shop", "1.0.0", "order", "item", "", 0)
+	w.AliasEntities = []string{"goods"}
+	w.SyncSchema = false
+	w.AddCustomExecutor("detail", func(ctx types.WorkerContext) (*jsonx.JsonResponse, int) {
+		received <- ctx.Event()
+		return jsonx.DefaultJson(constant.SUCCESS), http.StatusOK
+	})
+	if err := ws.RegisterWorker(w); err != nil {
+		t.Fatal(err)
+	}
+	if _, found := ws.FindWorkerExecutor("shop.order.goods->detail@1.0.0"); !found {
+		t.Fatal("应为实体别名注册路由")
+	}
+
+	event := &core.Event{Project: "shop", Version: "1.0.0", Context: "order", Entity: "goods", Event: "detail", Params: "{}"}
+	event.GenerateSign()
+	body := []byte(event.Raw())
+	resp := ut.PerformRequest(h.Engine, "POST", "/", &ut.Body{Body: bytes.NewReader(body), Len: len(body)}).Result()
+	if resp.StatusCode() != http.StatusOK || !strings.Contains(string(resp.Body()), string(constant.SUCCESS)) {
+		t.Fatalf("通过别名调用失败: %d %s", resp.StatusCode(), resp.Body())
+	}
+	select {
+	case got := <-received:
+		if got.Entity != "item" {
+			t.Errorf("别名事件应改写为原实体，实际 %s", got.Entity)
+		}
+	default:
+		t.Fatal("别名事件应由工作者的执行器处理")
+	}
+}
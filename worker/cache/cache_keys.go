@@ -17,9 +17,12 @@ package cache
 
 import "strings"
 
-// ConstantCacheKey 生成常量缓存键
+// CONSTANT_CACHE_KEY_PREFIX 常量缓存键的前缀
+const CONSTANT_CACHE_KEY_PREFIX = "constant:"
+
+// ConstantCacheKey 生成常量缓存键，常量的缓存和失效都使用该键
 func ConstantCacheKey(project, dict string) string {
-	return strings.Join([]string{"constant", project, dict}, ":")
+	return CONSTANT_CACHE_KEY_PREFIX + project + ":" + dict
 }
 
 // EntityCacheKey 生成实体缓存键
@@ -17,6 +17,9 @@ package cache
 import (
 	"fmt"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/garrickvan/event-matrix/constant"
 	"github.com/garrickvan/event-matrix/core"
@@ -27,10 +30,25 @@ import (
 	"github.com/garrickvan/event-matrix/worker/types"
 )
 
+// dispatchEvent 向网关发起内部调用，单元测试时可替换
+var dispatchEvent = dispatcher.Event
+
+// warmUpConcurrency 缓存预热的最大并发数
+const warmUpConcurrency = 8
+
+// 领域缓存的数据类别，与缓存键的前缀一致，用于按类别设置过期时间
+const (
+	DOMAIN_CACHE_ENTITY       = "entity"
+	DOMAIN_CACHE_ENTITY_ATTR  = "entity_attr"
+	DOMAIN_CACHE_ENTITY_EVENT = "entity_event"
+	DOMAIN_CACHE_CONSTANT     = "constant"
+)
+
 // DomainCacheImpl 实现了域缓存的功能
 type DomainCacheImpl struct {
 	cache *cachex.LocalCache // 本地缓存实例
 	ws    types.WorkerServer // 工作服务器实例
+	ttls  sync.Map           // 按数据类别设置的过期时间，key为类别，value为time.Duration
 }
 
 // NewDomainCacheImpl 创建一个新的域缓存实例
@@ -46,6 +64,25 @@ func NewDomainCacheImpl(maxMen int64, defaultTimeout int, wm types.WorkerServer)
 	}, err
 }
 
+// WithTTL 设置某类数据的缓存过期时间（秒），未设置或小于等于0时使用默认过期时间
+// 如常量字典很少变化可缓存数小时，开发中的事件定义可只缓存几秒，只对之后写入的缓存项生效
+func (dc *DomainCacheImpl) WithTTL(kind string, ttl int) *DomainCacheImpl {
+	if ttl <= 0 {
+		dc.ttls.Delete(kind)
+		return dc
+	}
+	dc.ttls.Store(kind, time.Duration(ttl)*time.Second)
+	return dc
+}
+
+// ttl 获取某类数据的缓存过期时间，返回0表示使用默认过期时间
+func (dc *DomainCacheImpl) ttl(kind string) time.Duration {
+	if v, ok := dc.ttls.Load(kind); ok {
+		return v.(time.Duration)
+	}
+	return 0
+}
+
 // EntityEvent 根据事件路径获取实体事件
 func (dc *DomainCacheImpl) EntityEvent(e types.PathToEvent) *core.EntityEvent {
 	// 版本为0.0.0的事件, 视为内部事件，直接返回空
@@ -70,13 +107,13 @@ func (dc *DomainCacheImpl) Entity(e types.PathToEntity) *core.Entity {
 		return nil
 	}
 	key := EntityCacheKey(e.Project, e.Context, e.Entity, e.Version)
-	entity, found := dc.cache.GetOrHook(key, func() interface{} {
+	entity, found := dc.cache.GetOrHookWithTTL(key, func() interface{} {
 		w := dc.ws.GetWorkerByEvent(e)
 		if w == nil {
 			w = &types.Worker{}
 		}
 		p := types.PathToEntityFromWorker(w)
-		resp, err := dispatcher.Event(dc.ws.GatewayIntranetEndpoint(), types.W_T_G_GET_ENTITY, p.ToStrArg(), nil)
+		resp, err := dispatchEvent(dc.ws.GatewayIntranetEndpoint(), types.W_T_G_GET_ENTITY, p.ToStrArg(), nil)
 		if err != nil || resp.Status() != http.StatusOK {
 			logx.Debug("内部调用错误： " + err.Error())
 			return nil
@@ -92,7 +129,7 @@ func (dc *DomainCacheImpl) Entity(e types.PathToEntity) *core.Entity {
 			return nil
 		}
 		return &entity
-	})
+	}, dc.ttl(DOMAIN_CACHE_ENTITY))
 	if found {
 		if entity, ok := entity.(*core.Entity); ok && entity != nil {
 			return entity
@@ -110,8 +147,8 @@ func (dc *DomainCacheImpl) EntityAttrs(e types.PathToEntity) []core.EntityAttrib
 	}
 
 	key := EntityAttrCacheKey(e.Project, e.Context, e.Entity, e.Version)
-	data, found := dc.cache.GetOrHook(key, func() interface{} {
-		resp, err := dispatcher.Event(dc.ws.GatewayIntranetEndpoint(), types.W_T_G_GET_ENTITY_ATTRS, e.ToStrArg(), nil)
+	data, found := dc.cache.GetOrHookWithTTL(key, func() interface{} {
+		resp, err := dispatchEvent(dc.ws.GatewayIntranetEndpoint(), types.W_T_G_GET_ENTITY_ATTRS, e.ToStrArg(), nil)
 		if err != nil || resp == nil || resp.Status() != http.StatusOK {
 			logx.Error(fmt.Sprintf("获取属性失败 [%s] 错误: %v, 响应: %+v", e.ToStrArg(), err, resp))
 			return emptyEntityAttrs
@@ -130,7 +167,7 @@ func (dc *DomainCacheImpl) EntityAttrs(e types.PathToEntity) []core.EntityAttrib
 			}
 		}
 		return attrs
-	})
+	}, dc.ttl(DOMAIN_CACHE_ENTITY_ATTR))
 
 	if !found {
 		logx.Debug("属性未缓存: " + key)
@@ -142,6 +179,18 @@ func (dc *DomainCacheImpl) EntityAttrs(e types.PathToEntity) []core.EntityAttrib
 	return emptyEntityAttrs
 }
 
+// EntityAttrsWithExpiry 根据实体路径获取实体属性及其缓存的过期时间，未缓存或永不过期时过期时间为零值
+func (dc *DomainCacheImpl) EntityAttrsWithExpiry(e types.PathToEntity) ([]core.EntityAttribute, time.Time) {
+	attrs := dc.EntityAttrs(e)
+	if e.IsIncomplete() {
+		return attrs, time.Time{}
+	}
+	// 首次加载时缓存为异步写入，等待写入完成后再读取过期时间
+	dc.cache.GetCacheInstance().Wait()
+	_, expiry, _ := dc.cache.GetWithExpiry(EntityAttrCacheKey(e.Project, e.Context, e.Entity, e.Version))
+	return attrs, expiry
+}
+
 var emptyEntityEvents = make([]core.EntityEvent, 0)
 
 // EntityEvents 根据实体路径获取实体事件
@@ -151,8 +200,8 @@ func (dc *DomainCacheImpl) EntityEvents(e types.PathToEntity) []core.EntityEvent
 	}
 
 	key := EntityEventCacheKey(e.Project, e.Context, e.Entity, e.Version)
-	data, found := dc.cache.GetOrHook(key, func() interface{} {
-		resp, err := dispatcher.Event(dc.ws.GatewayIntranetEndpoint(), types.W_T_G_GET_ENTITY_EVENTS, e.ToStrArg(), nil)
+	data, found := dc.cache.GetOrHookWithTTL(key, func() interface{} {
+		resp, err := dispatchEvent(dc.ws.GatewayIntranetEndpoint(), types.W_T_G_GET_ENTITY_EVENTS, e.ToStrArg(), nil)
 		if err != nil || resp == nil || resp.Status() != http.StatusOK {
 			logx.Error(fmt.Sprintf("获取事件失败 [%s] 错误: %v, 响应: %+v", e.ToStrArg(), err, resp))
 			return emptyEntityEvents
@@ -171,7 +220,7 @@ func (dc *DomainCacheImpl) EntityEvents(e types.PathToEntity) []core.EntityEvent
 			}
 		}
 		return events
-	})
+	}, dc.ttl(DOMAIN_CACHE_ENTITY_EVENT))
 
 	if !found {
 		logx.Debug("事件未缓存: " + key)
@@ -189,9 +238,9 @@ func (dc *DomainCacheImpl) Constants(project, dict string) []core.ConstantDict {
 		return nil
 	}
 	key := ConstantCacheKey(project, dict)
-	ins, find := dc.cache.GetOrHook(key, func() interface{} {
+	ins, find := dc.cache.GetOrHookWithTTL(key, func() interface{} {
 		paramStr := project + constant.SPLIT_CHAR + dict
-		resp, err := dispatcher.Event(dc.ws.GatewayIntranetEndpoint(), types.W_T_G_GET_CONSTANTS, paramStr, nil)
+		resp, err := dispatchEvent(dc.ws.GatewayIntranetEndpoint(), types.W_T_G_GET_CONSTANTS, paramStr, nil)
 		if err != nil {
 			logx.Log().Error("内部调用错误： " + err.Error())
 			return nil
@@ -207,7 +256,7 @@ func (dc *DomainCacheImpl) Constants(project, dict string) []core.ConstantDict {
 			return nil
 		}
 		return constantDicts
-	})
+	}, dc.ttl(DOMAIN_CACHE_CONSTANT))
 	if find && ins != nil {
 		if constants, ok := ins.([]core.ConstantDict); ok && constants != nil {
 			return constants
@@ -216,6 +265,91 @@ func (dc *DomainCacheImpl) Constants(project, dict string) []core.ConstantDict {
 	return nil
 }
 
+// WarmUp 预热领域缓存，并发拉取每个工作者对应实体的属性、事件和实体信息
+// 单个工作者预热失败只记录警告，不会中断整体预热，全部完成后返回失败汇总
+func (dc *DomainCacheImpl) WarmUp(workers []*types.Worker) error {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed []string
+	)
+	sem := make(chan struct{}, warmUpConcurrency)
+	for _, w := range workers {
+		p := types.PathToEntityFromWorker(w)
+		if p.IsIncomplete() {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(w *types.Worker, p types.PathToEntity) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			ok := len(dc.EntityAttrs(p)) > 0
+			ok = len(dc.EntityEvents(p)) > 0 && ok
+			ok = dc.Entity(p) != nil && ok
+			if !ok {
+				label := w.GetVersionEntityLabel()
+				logx.Log().Warn("领域缓存预热失败: " + label)
+				mu.Lock()
+				failed = append(failed, label)
+				mu.Unlock()
+			}
+		}(w, p)
+	}
+	wg.Wait()
+	if len(failed) > 0 {
+		return fmt.Errorf("领域缓存预热失败 %d 个: %v", len(failed), failed)
+	}
+	return nil
+}
+
+// DebugDump 返回当前缓存内容的快照，仅在调试模式下可用，非调试模式返回nil
+func (dc *DomainCacheImpl) DebugDump() map[string]interface{} {
+	if !logx.IsDebugging() {
+		return nil
+	}
+	dump := make(map[string]interface{})
+	for _, key := range dc.cache.Keys() {
+		if v, ok := dc.cache.Get(key); ok {
+			dump[key] = v
+		}
+	}
+	return dump
+}
+
+// Invalidate 使指定键的缓存失效
+// key 为实体路径参数时，失效该实体的实体信息、属性和事件缓存，否则直接删除该键
+func (dc *DomainCacheImpl) Invalidate(key string) {
+	p := types.PathToEntityFromStrArg(key)
+	if p.IsIncomplete() {
+		dc.cache.Del(key)
+		return
+	}
+	dc.cache.Del(EntityCacheKey(p.Project, p.Context, p.Entity, p.Version))
+	dc.cache.Del(EntityAttrCacheKey(p.Project, p.Context, p.Entity, p.Version))
+	dc.cache.Del(EntityEventCacheKey(p.Project, p.Context, p.Entity, p.Version))
+}
+
+// InvalidateConstants 使常量缓存失效，dict 为空时失效项目下的全部常量，project 也为空时失效全部常量
+func (dc *DomainCacheImpl) InvalidateConstants(project, dict string) {
+	if project != "" && dict != "" {
+		dc.cache.Del(ConstantCacheKey(project, dict))
+		return
+	}
+	for _, key := range dc.cache.Keys() {
+		rest, ok := strings.CutPrefix(key, CONSTANT_CACHE_KEY_PREFIX)
+		if !ok {
+			continue
+		}
+		keyProject, keyDict, _ := strings.Cut(rest, ":")
+		if (project == "" || keyProject == project) && (dict == "" || keyDict == dict) {
+			dc.cache.Del(key)
+		}
+	}
+}
+
 // Impl 返回本地缓存实例
 func (dc *DomainCacheImpl) Impl() *cachex.LocalCache {
 	return dc.cache
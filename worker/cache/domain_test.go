@@ -0,0 +1,236 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/garrickvan/event-matrix/serverx"
+	"github.com/garrickvan/event-matrix/serverx/gnetx"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+// fakeWorkerServer 仅实现领域缓存依赖的方法
+type fakeWorkerServer struct {
+	types.WorkerServer
+}
+
+func (f *fakeWorkerServer) GatewayIntranetEndpoint() string { return "127.0.0.1:0" }
+
+func (f *fakeWorkerServer) GetWorkerByEvent(p types.PathToEntity) *types.Worker {
+	return &types.Worker{Project: p.Project, Context: p.Context, Entity: p.Entity, VersionLabel: p.Version}
+}
+
+// fakeGateway 模拟网关返回领域数据，并记录每种事件的调用次数
+type fakeGateway struct {
+	mu    sync.Mutex
+	calls map[types.INTRANET_EVENT_TYPE]int
+}
+
+func (g *fakeGateway) event(endpoint string, typz types.INTRANET_EVENT_TYPE, strOrJson interface{}, request serverx.RequestContext) (serverx.ResponsePacket, error) {
+	g.mu.Lock()
+	g.calls[typz]++
+	g.mu.Unlock()
+	var payload string
+	switch typz {
+	case types.W_T_G_GET_ENTITY:
+		payload = `{"id":"e1","code":"user"}`
+	case types.W_T_G_GET_ENTITY_ATTRS:
+		payload = `[{"id":"a1","code":"name","fieldType":"string"}]`
+	case types.W_T_G_GET_ENTITY_EVENTS:
+		payload = `[{"id":"ev1","code":"query"}]`
+	case types.W_T_G_GET_CONSTANTS:
+		payload = `[{"id":"c1","value":"1","dict":"status"}]`
+	}
+	return &gnetx.ResponsePacketImpl{StatusCode: http.StatusOK, Payload: payload}, nil
+}
+
+func (g *fakeGateway) total() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	n := 0
+	for _, c := range g.calls {
+		n += c
+	}
+	return n
+}
+
+func newTestDomainCache(t *testing.T) (*DomainCacheImpl, *fakeGateway) {
+	gw := &fakeGateway{calls: map[types.INTRANET_EVENT_TYPE]int{}}
+	origin := dispatchEvent
+	dispatchEvent = gw.event
+	t.Cleanup(func() { dispatchEvent = origin })
+
+	dc, err := NewDomainCacheImpl(1<<20, 60, &fakeWorkerServer{})
+	if err != nil {
+		t.Fatalf("failed to create domain cache: %v", err)
+	}
+	return dc, gw
+}
+
+func TestDomainCacheWarmUp(t *testing.T) {
+	dc, gw := newTestDomainCache(t)
+	workers := []*types.Worker{
+		{Project: "p", Context: "c", Entity: "user", VersionLabel: "1.0.0"},
+		{Project: "p", Context: "c", Entity: "order", VersionLabel: "1.0.0"},
+		{Project: "p", Context: "c", Entity: "skipped"}, // 缺少版本，不参与预热
+	}
+	if err := dc.WarmUp(workers); err != nil {
+		t.Fatalf("warm up failed: %v", err)
+	}
+	if n := gw.total(); n != 6 {
+		t.Fatalf("expected 6 gateway calls during warm up, got %d", n)
+	}
+	dc.Impl().GetCacheInstance().Wait()
+
+	// 预热后再次访问应全部命中缓存
+	for _, w := range workers[:2] {
+		p := types.PathToEntityFromWorker(w)
+		if len(dc.EntityAttrs(p)) != 1 || len(dc.EntityEvents(p)) != 1 || dc.Entity(p) == nil {
+			t.Fatalf("unexpected cached data for %s", w.GetVersionEntityLabel())
+		}
+	}
+	if n := gw.total(); n != 6 {
+		t.Fatalf("expected cache hits after warm up, got %d gateway calls", n)
+	}
+}
+
+func TestDomainCacheWithTTL(t *testing.T) {
+	dc, gw := newTestDomainCache(t)
+	// 事件定义只缓存1秒，属性使用默认的60秒
+	dc.WithTTL(DOMAIN_CACHE_ENTITY_EVENT, 1)
+	p := types.PathToEntity{Project: "p", Version: "1.0.0", Context: "c", Entity: "user"}
+
+	for i := 0; i < 2; i++ {
+		dc.EntityEvents(p)
+		dc.EntityAttrs(p)
+		dc.Impl().GetCacheInstance().Wait()
+	}
+	if gw.calls[types.W_T_G_GET_ENTITY_EVENTS] != 1 || gw.calls[types.W_T_G_GET_ENTITY_ATTRS] != 1 {
+		t.Fatalf("expected cache hits before expiry, got %v", gw.calls)
+	}
+
+	time.Sleep(1100 * time.Millisecond)
+	dc.EntityEvents(p)
+	dc.EntityAttrs(p)
+	if n := gw.calls[types.W_T_G_GET_ENTITY_EVENTS]; n != 2 {
+		t.Fatalf("expected events refetched after short ttl expired, got %d calls", n)
+	}
+	if n := gw.calls[types.W_T_G_GET_ENTITY_ATTRS]; n != 1 {
+		t.Fatalf("expected attrs still cached with default ttl, got %d calls", n)
+	}
+
+	// ttl 小于等于0时恢复默认过期时间
+	dc.WithTTL(DOMAIN_CACHE_ENTITY_EVENT, 0)
+	if ttl := dc.ttl(DOMAIN_CACHE_ENTITY_EVENT); ttl != 0 {
+		t.Fatalf("expected default ttl after reset, got %v", ttl)
+	}
+}
+
+func TestDomainCacheEntityAttrsWithExpiry(t *testing.T) {
+	dc, _ := newTestDomainCache(t)
+	p := types.PathToEntity{Project: "p", Version: "1.0.0", Context: "c", Entity: "user"}
+
+	before := time.Now()
+	attrs, expiry := dc.EntityAttrsWithExpiry(p)
+	if len(attrs) != 1 {
+		t.Fatalf("expected 1 attr, got %d", len(attrs))
+	}
+	want := before.Add(60 * time.Second)
+	if d := expiry.Sub(want); d < -time.Second || d > time.Second {
+		t.Fatalf("expected expiry near %v, got %v", want, expiry)
+	}
+
+	// 路径不完整时不返回过期时间
+	if _, expiry := dc.EntityAttrsWithExpiry(types.PathToEntity{Project: "p"}); !expiry.IsZero() {
+		t.Fatalf("expected zero expiry for incomplete path, got %v", expiry)
+	}
+}
+
+func TestDomainCacheInvalidate(t *testing.T) {
+	dc, gw := newTestDomainCache(t)
+	user := types.PathToEntity{Project: "p", Version: "1.0.0", Context: "c", Entity: "user"}
+	order := types.PathToEntity{Project: "p", Version: "1.0.0", Context: "c", Entity: "order"}
+	for _, p := range []types.PathToEntity{user, order} {
+		dc.Entity(p)
+		dc.EntityAttrs(p)
+		dc.EntityEvents(p)
+	}
+	dc.Impl().GetCacheInstance().Wait()
+	if n := gw.total(); n != 6 {
+		t.Fatalf("expected 6 gateway calls, got %d", n)
+	}
+
+	// 只失效 user 实体的缓存
+	dc.Invalidate(user.ToStrArg())
+	for _, p := range []types.PathToEntity{user, order} {
+		dc.Entity(p)
+		dc.EntityAttrs(p)
+		dc.EntityEvents(p)
+	}
+	if n := gw.total(); n != 9 {
+		t.Fatalf("expected only invalidated entity refetched, got %d gateway calls", n)
+	}
+}
+
+func TestDomainCacheInvalidateConstants(t *testing.T) {
+	dc, gw := newTestDomainCache(t)
+	load := func() {
+		dc.Constants("p", "status")
+		dc.Constants("p", "level")
+		dc.Constants("q", "status")
+		dc.Impl().GetCacheInstance().Wait()
+	}
+	constantCalls := func() int {
+		gw.mu.Lock()
+		defer gw.mu.Unlock()
+		return gw.calls[types.W_T_G_GET_CONSTANTS]
+	}
+	load()
+	if n := constantCalls(); n != 3 {
+		t.Fatalf("expected 3 constant fetches, got %d", n)
+	}
+
+	// 只失效指定字典
+	dc.InvalidateConstants("p", "status")
+	load()
+	if n := constantCalls(); n != 4 {
+		t.Fatalf("expected only p:status refetched, got %d", n)
+	}
+
+	// 只失效指定项目
+	dc.InvalidateConstants("p", "")
+	load()
+	if n := constantCalls(); n != 6 {
+		t.Fatalf("expected project p constants refetched, got %d", n)
+	}
+
+	// 失效全部常量，实体缓存不受影响
+	user := types.PathToEntity{Project: "p", Version: "1.0.0", Context: "c", Entity: "user"}
+	dc.Entity(user)
+	dc.Impl().GetCacheInstance().Wait()
+	dc.InvalidateConstants("", "")
+	load()
+	dc.Entity(user)
+	if n := constantCalls(); n != 9 {
+		t.Fatalf("expected all constants refetched, got %d", n)
+	}
+	if n := gw.calls[types.W_T_G_GET_ENTITY]; n != 1 {
+		t.Fatalf("entity cache should survive constant invalidation, got %d fetches", n)
+	}
+}
@@ -15,13 +15,19 @@
 package cache
 
 import (
+	"sync"
+
 	"github.com/garrickvan/event-matrix/utils/cachex"
+	"github.com/garrickvan/event-matrix/utils/logx"
 	"github.com/garrickvan/event-matrix/worker/types"
+	"golang.org/x/sync/singleflight"
 )
 
 type DefaultCacheImpl struct {
-	cache *cachex.LocalCache
-	ws    types.WorkerServer
+	cache   *cachex.LocalCache
+	ws      types.WorkerServer
+	loaders sync.Map           // 实体标识 -> types.CacheLoader
+	loading singleflight.Group // 合并同一个键的并发加载，避免缓存击穿
 }
 
 func NewDefaultCacheImpl(maxMen int64, defaultTimeout int, ws types.WorkerServer) (*DefaultCacheImpl, error) {
@@ -39,3 +45,44 @@ func NewDefaultCacheImpl(maxMen int64, defaultTimeout int, ws types.WorkerServer
 func (c *DefaultCacheImpl) Impl() *cachex.LocalCache {
 	return c.cache
 }
+
+// RegisterLoader 注册实体的缓存加载器，重复注册时覆盖，fn 为 nil 时移除加载器
+func (c *DefaultCacheImpl) RegisterLoader(entityLabel string, fn types.CacheLoader) {
+	if fn == nil {
+		c.loaders.Delete(entityLabel)
+		return
+	}
+	c.loaders.Store(entityLabel, fn)
+}
+
+// Get 获取缓存值，未命中时调用键对应实体的加载器，同一个键的并发请求只加载一次
+func (c *DefaultCacheImpl) Get(key string) (interface{}, bool) {
+	if data, ok := c.cache.Get(key); ok && data != nil {
+		return data, true
+	}
+	label, bizKey, ok := types.SplitReadThroughCacheKey(key)
+	if !ok {
+		return nil, false
+	}
+	fn, has := c.loaders.Load(label)
+	if !has {
+		return nil, false
+	}
+	data, err, _ := c.loading.Do(key, func() (interface{}, error) {
+		// 等待期间其他请求可能已加载完成
+		if data, ok := c.cache.Get(key); ok && data != nil {
+			return data, nil
+		}
+		data, err := fn.(types.CacheLoader)(bizKey)
+		if err != nil || data == nil {
+			return nil, err
+		}
+		c.cache.Put(key, data)
+		return data, nil
+	})
+	if err != nil {
+		logx.Warn("缓存加载失败: " + key + ", " + err.Error())
+		return nil, false
+	}
+	return data, data != nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+func TestDefaultCacheReadThrough(t *testing.T) {
+	c, err := NewDefaultCacheImpl(1<<20, 60, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	label := "shop.order.item@1.0.0"
+	var calls int32
+	c.RegisterLoader(label, func(key string) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(20 * time.Millisecond)
+		switch key {
+		case "missing":
+			return nil, nil
+		case "broken":
+			return nil, errors.New("db down")
+		}
+		return "item-" + key, nil
+	})
+
+	// 并发未命中只调用一次加载器
+	key := types.ReadThroughCacheKey(label, "1")
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, ok := c.Get(key); !ok || v != "item-1" {
+				t.Errorf("unexpected value: %v %v", v, ok)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("expected loader to run once, got %d", n)
+	}
+
+	// 加载结果已写入缓存
+	c.Impl().GetCacheInstance().Wait()
+	if v, ok := c.Impl().Get(key); !ok || v != "item-1" {
+		t.Fatalf("loaded value should be cached: %v %v", v, ok)
+	}
+	if _, ok := c.Get(key); !ok || atomic.LoadInt32(&calls) != 1 {
+		t.Error("cached value should not call loader again")
+	}
+
+	for _, k := range []string{"missing", "broken"} {
+		if _, ok := c.Get(types.ReadThroughCacheKey(label, k)); ok {
+			t.Errorf("%s: expected miss", k)
+		}
+	}
+	// 未注册加载器和不含实体标识的键只查缓存
+	if _, ok := c.Get(types.ReadThroughCacheKey("shop.order.other@1.0.0", "1")); ok {
+		t.Error("unregistered entity should miss")
+	}
+	if _, ok := c.Get("plain"); ok {
+		t.Error("plain key should miss")
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/types"
+	"github.com/spf13/cast"
+)
+
+// aggregateFuncs 聚合执行器支持的聚合函数，key 为参数中的函数名，value 为对应的SQL函数
+var aggregateFuncs = map[string]string{
+	"count": "COUNT",
+	"sum":   "SUM",
+	"avg":   "AVG",
+	"min":   "MIN",
+	"max":   "MAX",
+}
+
+// AggregateExecutor 聚合查询，aggregate 参数形如 count,sum,avg:price，冒号前为聚合函数，冒号后为目标字段，
+// count 可不指定字段；筛选条件复用 and_query/or_query 参数，group_by 参数指定分组字段，
+// 不分组时返回一个聚合结果对象，分组时每组返回一个对象
+func AggregateExecutor(ctx types.WorkerContext) (*jsonx.JsonResponse, int) {
+	event := ctx.Event()
+	if event == nil {
+		return jsonx.DefaultJson(constant.EVENT_NOT_EXIST), http.StatusOK
+	}
+	entityAttrs, paramSettings, params, errJson := ctx.ValidatedParams()
+	if errJson != nil {
+		return errJson, http.StatusOK
+	}
+	funcs, column, errJson := parseAggregateParam(cast.ToString(params["aggregate"]), entityAttrs)
+	if errJson != nil {
+		return errJson, http.StatusOK
+	}
+	groupBy := strings.TrimSpace(cast.ToString(params["group_by"]))
+	if groupBy != "" {
+		if attr := core.FindAttrFromArray(groupBy, entityAttrs); attr == nil || !aggregatableAttr(attr) {
+			errRespone := jsonx.DefaultJson(constant.INVALID_PARAM)
+			errRespone.Message = "无效的分组字段: " + groupBy
+			return errRespone, http.StatusOK
+		}
+	}
+
+	selects := make([]string, 0, len(funcs)+1)
+	if groupBy != "" {
+		selects = append(selects, groupBy)
+	}
+	for _, fn := range funcs {
+		if fn == "count" {
+			selects = append(selects, "COUNT(*) AS count")
+			continue
+		}
+		selects = append(selects, aggregateFuncs[fn]+"("+column+") AS "+fn)
+	}
+	deleted := SoftDeleteQueryParam(params)
+	query := buildQuerySchema(ctx, event, paramSettings, params, entityAttrs, deleted).Select(strings.Join(selects, ", "))
+	if groupBy != "" {
+		query = query.Group(groupBy).Order(groupBy)
+	}
+	rows := make([]map[string]interface{}, 0)
+	if err := query.Find(&rows).Error; err != nil {
+		logx.Log().Error("聚合查询错误：" + err.Error())
+		return jsonx.DefaultJson(constant.FAIL_TO_QUERY), http.StatusOK
+	}
+
+	result := jsonx.DefaultJsonWithMsg(constant.SUCCESS, "查询成功")
+	for _, row := range rows {
+		for _, fn := range funcs {
+			row[fn] = normalizeAggregateValue(fn, row[fn])
+		}
+		result.List = append(result.List, row)
+	}
+	result.Size = len(result.List)
+	result.Total = int64(result.Size)
+	return result, http.StatusOK
+}
+
+// parseAggregateParam 解析 aggregate 参数，返回去重后的聚合函数和目标字段，字段必须是实体属性，防止SQL注入
+func parseAggregateParam(aggregate string, entityAttrs []core.EntityAttribute) ([]string, string, *jsonx.JsonResponse) {
+	aggregate = strings.TrimSpace(aggregate)
+	if aggregate == "" {
+		errRespone := jsonx.DefaultJson(constant.MISSING_PARAM)
+		errRespone.Message = "缺少必须参数aggregate"
+		return nil, "", errRespone
+	}
+	fnPart, column, _ := strings.Cut(aggregate, ":")
+	column = strings.TrimSpace(column)
+
+	funcs := []string{}
+	needColumn := false
+	for _, fn := range strings.Split(fnPart, ",") {
+		fn = strings.ToLower(strings.TrimSpace(fn))
+		if _, ok := aggregateFuncs[fn]; !ok {
+			errRespone := jsonx.DefaultJson(constant.INVALID_PARAM)
+			errRespone.Message = "不支持的聚合函数: " + fn
+			return nil, "", errRespone
+		}
+		if utils.InStrArray(fn, funcs) {
+			continue
+		}
+		funcs = append(funcs, fn)
+		if fn != "count" {
+			needColumn = true
+		}
+	}
+	if !needColumn {
+		return funcs, column, nil
+	}
+
+	attr := core.FindAttrFromArray(column, entityAttrs)
+	if attr == nil || !aggregatableAttr(attr) {
+		errRespone := jsonx.DefaultJson(constant.INVALID_PARAM)
+		errRespone.Message = "无效的聚合字段: " + column
+		return nil, "", errRespone
+	}
+	// 求和与平均值只支持数值字段
+	if (utils.InStrArray("sum", funcs) || utils.InStrArray("avg", funcs)) && !numericAttr(attr) {
+		errRespone := jsonx.DefaultJson(constant.INVALID_PARAM)
+		errRespone.Message = "sum 和 avg 只支持数值字段: " + column
+		return nil, "", errRespone
+	}
+	return funcs, column, nil
+}
+
+// aggregatableAttr 判断字段是否可用于聚合或分组，自定义字段和保密字段不可用
+func aggregatableAttr(attr *core.EntityAttribute) bool {
+	return attr.FieldType != string(core.CUSTOM_FIELD_TYPE) && !attr.IsSecrecy
+}
+
+// numericAttr 判断字段是否为数值类型
+func numericAttr(attr *core.EntityAttribute) bool {
+	switch core.FIELD_TYPE(attr.FieldType) {
+	case core.INT8_FIELD_TYPE, core.INT16_FIELD_TYPE, core.INT32_FIELD_TYPE, core.INT64_FIELD_TYPE,
+		core.FLOAT32_FIELD_TYPE, core.FLOAT64_FIELD_TYPE, core.DATETIME_FIELD_TYPE:
+		return true
+	}
+	return false
+}
+
+// normalizeAggregateValue 统一不同数据库返回的聚合结果类型，count 为整数，sum 和 avg 为浮点数
+func normalizeAggregateValue(fn string, value interface{}) interface{} {
+	switch fn {
+	case "count":
+		return cast.ToInt64(value)
+	case "sum", "avg":
+		if value == nil {
+			return nil
+		}
+		return cast.ToFloat64(value)
+	}
+	return value
+}
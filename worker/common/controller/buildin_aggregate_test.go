@@ -0,0 +1,89 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+func newAggregateTestServer(t *testing.T) *types.MockWorkerServer {
+	ws := newQueryTestServer(t)
+	ws.SetEntityEvents(queryEntity, []core.EntityEvent{
+		{
+			Code:   "stats",
+			Params: `[{"name":"aggregate","type":"string"},{"name":"group_by","type":"string"},{"name":"age","type":"and_query","range":"gt"}]`,
+		},
+	})
+	db := ws.Repo().Use(queryEntity.Project)
+	if err := db.Exec("INSERT INTO shop_user (id, name, age, password, deleted_at) VALUES ('5', 'bob', 35, 'p5', 0)").Error; err != nil {
+		t.Fatal(err)
+	}
+	return ws
+}
+
+func TestAggregateExecutor(t *testing.T) {
+	ws := newAggregateTestServer(t)
+	ctx := types.NewMockRequestContext(ws, newQueryEvent("stats", `{"aggregate":"count,sum,avg,min,max:age","age":20}`))
+
+	resp, _ := AggregateExecutor(ctx)
+	if resp.Code != string(constant.SUCCESS) || len(resp.List) != 1 {
+		t.Fatalf("聚合失败: %s %s %v", resp.Code, resp.Message, resp.List)
+	}
+	// 已删除的 dave 和不满足条件的 alice 不参与聚合：25, 32, 35
+	row := resp.List[0].(map[string]interface{})
+	if row["count"] != int64(3) || row["sum"] != float64(92) || row["min"] == nil || row["max"] == nil {
+		t.Fatalf("聚合结果错误: %v", row)
+	}
+	if avg := row["avg"].(float64); avg < 30.66 || avg > 30.67 {
+		t.Errorf("平均值错误: %v", avg)
+	}
+}
+
+func TestAggregateExecutorGroupBy(t *testing.T) {
+	ws := newAggregateTestServer(t)
+	ctx := types.NewMockRequestContext(ws, newQueryEvent("stats", `{"aggregate":"count,sum:age","group_by":"name"}`))
+
+	resp, _ := AggregateExecutor(ctx)
+	if resp.Code != string(constant.SUCCESS) || len(resp.List) != 3 {
+		t.Fatalf("分组聚合失败: %s %s %v", resp.Code, resp.Message, resp.List)
+	}
+	bob := resp.List[1].(map[string]interface{})
+	if bob["name"] != "bob" || bob["count"] != int64(2) || bob["sum"] != float64(60) {
+		t.Errorf("分组结果错误: %v", bob)
+	}
+}
+
+func TestAggregateExecutorInvalidParams(t *testing.T) {
+	ws := newAggregateTestServer(t)
+	cases := map[string]string{
+		"缺少聚合参数":  `{}`,
+		"未知聚合函数":  `{"aggregate":"median:age"}`,
+		"注入字段":    `{"aggregate":"sum:age) FROM shop_user; --"}`,
+		"保密字段":    `{"aggregate":"max:password"}`,
+		"非数值字段求和": `{"aggregate":"sum:name"}`,
+		"未知分组字段":  `{"aggregate":"count","group_by":"1; DROP TABLE shop_user"}`,
+		"保密字段分组":  `{"aggregate":"count","group_by":"password"}`,
+	}
+	for name, params := range cases {
+		resp, _ := AggregateExecutor(types.NewMockRequestContext(ws, newQueryEvent("stats", params)))
+		if resp.Code == string(constant.SUCCESS) {
+			t.Errorf("%s: 应返回错误", name)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/common"
+	"github.com/garrickvan/event-matrix/worker/types"
+	"github.com/spf13/cast"
+	"gorm.io/gorm"
+)
+
+// DEFAULT_BULK_BATCH_SIZE 批量创建时每批插入的默认数据条数
+const DEFAULT_BULK_BATCH_SIZE = 500
+
+// bulkRecordContext 批量创建时单条数据的上下文，事件参数替换为该条数据，用于复用参数校验
+type bulkRecordContext struct {
+	types.WorkerContext
+	event *core.Event
+}
+
+func (c *bulkRecordContext) Event() *core.Event {
+	return c.event
+}
+
+// BulkCreateExecutor 批量创建数据，事件参数为数据数组，每条数据按事件的参数设置校验，
+// 全部校验通过后按 batch_size 分批插入，任一条数据不合法时不插入任何数据
+func BulkCreateExecutor(ctx types.WorkerContext) (*jsonx.JsonResponse, int) {
+	event := ctx.Event()
+	if event == nil {
+		return jsonx.DefaultJson(constant.EVENT_NOT_EXIST), http.StatusOK
+	}
+	records := []map[string]interface{}{}
+	if err := jsonx.UnmarshalFromStr(event.Params, &records); err != nil {
+		logx.Debug(event.GetFullEventLabel()+"批量创建参数解析失败: ", err)
+		return jsonx.DefaultJson(constant.INVALID_PARAM), http.StatusOK
+	}
+	if len(records) == 0 {
+		errRespone := jsonx.DefaultJson(constant.MISSING_PARAM)
+		errRespone.Message = "批量创建的数据不能为空"
+		return errRespone, http.StatusOK
+	}
+
+	var entityAttrs []core.EntityAttribute
+	var paramSettings []core.EventParam
+	newRecords := make([]map[string]interface{}, 0, len(records))
+	// 批次内唯一字段查重，key 为字段编码
+	seen := map[string]map[string]struct{}{}
+	for i, record := range records {
+		params, err := jsonx.MarshalToStr(record)
+		if err != nil {
+			return jsonx.DefaultJson(constant.INVALID_PARAM), http.StatusOK
+		}
+		recordEvent := *event
+		recordEvent.Params = params
+		var validated map[string]interface{}
+		var errJson *jsonx.JsonResponse
+		entityAttrs, paramSettings, validated, errJson = common.ParseAndValidateParams(&bulkRecordContext{ctx, &recordEvent})
+		if errJson != nil {
+			return bulkRecordError(errJson, i), http.StatusOK
+		}
+		newData, errJson := buildCreateData(ctx, entityAttrs, validated)
+		if errJson != nil {
+			return bulkRecordError(errJson, i), http.StatusOK
+		}
+		for _, attr := range entityAttrs {
+			if !attr.Unique || attr.Code == "id" || newData[attr.Code] == nil {
+				continue
+			}
+			if seen[attr.Code] == nil {
+				seen[attr.Code] = map[string]struct{}{}
+			}
+			key := cast.ToString(newData[attr.Code])
+			if _, ok := seen[attr.Code][key]; ok {
+				return bulkRecordError(duplicateRecordJson(attr), i), http.StatusOK
+			}
+			seen[attr.Code][key] = struct{}{}
+		}
+		if errJson := checkUniqueAttrs(ctx.Server().Repo().Use(event.Project), event, entityAttrs, newData); errJson != nil {
+			return bulkRecordError(errJson, i), http.StatusOK
+		}
+		newRecords = append(newRecords, newData)
+	}
+
+	// 分批插入，数据库连接关闭了默认事务，需显式开启事务，任一批失败时全部回滚
+	var rowsAffected int64
+	err := ctx.Server().Repo().Use(event.Project).Transaction(func(tx *gorm.DB) error {
+		result := tx.Table(event.GetTabelName()).CreateInBatches(newRecords, bulkBatchSize(paramSettings))
+		rowsAffected = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		logx.Error(event.GetFullEventLabel() + "批量创建失败: " + err.Error())
+		return jsonx.DefaultJson(constant.FAIL_TO_CREATE), http.StatusOK
+	}
+	if rowsAffected == 0 {
+		return jsonx.DefaultJson(constant.FAIL_TO_CREATE), http.StatusOK
+	}
+	// 过滤保密字段的数据
+	for _, data := range newRecords {
+		for _, attr := range entityAttrs {
+			if attr.IsSecrecy {
+				delete(data, attr.Code)
+			}
+		}
+	}
+	resp := jsonx.DefaultJson(constant.SUCCESS)
+	jsonx.SetJsonList[map[string]interface{}](resp, newRecords, int64(len(newRecords)), 1)
+	return resp, http.StatusOK
+}
+
+// bulkBatchSize 读取名为 batch_size 的参数设置，RangeValue 为每批插入的条数，未设置时使用默认值
+func bulkBatchSize(paramSettings []core.EventParam) int {
+	setting, ok := core.FindParamFromArray("batch_size", paramSettings)
+	if !ok {
+		return DEFAULT_BULK_BATCH_SIZE
+	}
+	size := cast.ToInt(strings.TrimSpace(setting.RangeValue))
+	if size <= 0 {
+		return DEFAULT_BULK_BATCH_SIZE
+	}
+	return size
+}
+
+// bulkRecordError 在错误信息前标注出错数据的序号，序号从1开始
+func bulkRecordError(errJson *jsonx.JsonResponse, index int) *jsonx.JsonResponse {
+	errJson.Message = fmt.Sprintf("第%d条数据: %s", index+1, errJson.Message)
+	return errJson
+}
@@ -0,0 +1,169 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+var bulkEntity = types.PathToEntity{Project: "demo", Version: "v1", Context: "shop", Entity: "staff"}
+
+func newBulkTestServer(t *testing.T) *types.MockWorkerServer {
+	t.Helper()
+	ws := types.NewMockWorkerServer(nil)
+	t.Cleanup(func() { ws.Stop() })
+
+	ws.SetEntityAttrs(bulkEntity, []core.EntityAttribute{
+		{Code: "id", FieldType: "id"},
+		{Code: "name", Name: "姓名", FieldType: "string"},
+		{Code: "email", Name: "邮箱", FieldType: "email", Unique: true},
+		{Code: "password", FieldType: "string", IsSecrecy: true},
+	})
+	ws.SetEntityEvents(bulkEntity, []core.EntityEvent{{
+		Code:   "bulk_create",
+		Params: `[{"name":"name","type":"string","range":"any","required":true},{"name":"email","type":"email","range":"any"},{"name":"batch_size","type":"int","rangeValue":"2"}]`,
+	}})
+	err := ws.Repo().Use(bulkEntity.Project).
+		Exec("CREATE TABLE shop_staff (id TEXT PRIMARY KEY, name TEXT, email TEXT UNIQUE, password TEXT)").Error
+	if err != nil {
+		t.Fatalf("建表失败: %v", err)
+	}
+	return ws
+}
+
+func bulkCreate(ws *types.MockWorkerServer, params string) *types.MockRequestContext {
+	return types.NewMockRequestContext(ws, &core.Event{
+		Project: bulkEntity.Project,
+		Version: bulkEntity.Version,
+		Context: bulkEntity.Context,
+		Entity:  bulkEntity.Entity,
+		Event:   "bulk_create",
+		Params:  params,
+	})
+}
+
+func countStaff(t *testing.T, ws *types.MockWorkerServer) int64 {
+	t.Helper()
+	var count int64
+	if err := ws.Repo().Use(bulkEntity.Project).Table("shop_staff").Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func TestBulkCreateExecutor(t *testing.T) {
+	ws := newBulkTestServer(t)
+	resp, _ := BulkCreateExecutor(bulkCreate(ws, `[
+		{"name":"alice","email":"a@test.io","password":"p1"},
+		{"name":"bob","email":"b@test.io","password":"p2"},
+		{"name":"carol","password":"p3"}
+	]`))
+	if resp.Code != string(constant.SUCCESS) || resp.Total != 3 || len(resp.List) != 3 {
+		t.Fatalf("批量创建失败: %+v", resp)
+	}
+	for _, item := range resp.List {
+		row := item.(map[string]interface{})
+		if row["id"] == nil || row["id"] == "" {
+			t.Errorf("未生成id: %v", row)
+		}
+		if _, ok := row["password"]; ok {
+			t.Errorf("保密字段不应返回: %v", row)
+		}
+	}
+	if n := countStaff(t, ws); n != 3 {
+		t.Fatalf("期望插入3条数据, got %d", n)
+	}
+}
+
+func TestBulkCreateExecutorRejectsInvalidRecord(t *testing.T) {
+	ws := newBulkTestServer(t)
+	cases := []struct {
+		params string
+		code   constant.RESPONSE_CODE
+		msg    string
+	}{
+		{`[]`, constant.MISSING_PARAM, ""},
+		{`{"name":"alice"}`, constant.INVALID_PARAM, ""},
+		// 必填参数为空
+		{`[{"name":"alice"},{"name":null}]`, constant.MISSING_PARAM, "第2条"},
+		// 参数校验不通过
+		{`[{"name":"alice"},{"name":"bob"},{"name":"carol","email":"bad"}]`, constant.INVALID_PARAM, "第3条"},
+		// 批次内唯一字段重复
+		{`[{"name":"alice","email":"a@test.io"},{"name":"bob","email":"a@test.io"}]`, constant.DUPLICATE_RECORD, "第2条"},
+	}
+	for _, c := range cases {
+		resp, _ := BulkCreateExecutor(bulkCreate(ws, c.params))
+		if resp.Code != string(c.code) || !strings.Contains(resp.Message, c.msg) {
+			t.Errorf("%s: 期望 %s %s, got %s %s", c.params, c.code, c.msg, resp.Code, resp.Message)
+		}
+	}
+	if n := countStaff(t, ws); n != 0 {
+		t.Fatalf("校验失败时不应插入数据, got %d", n)
+	}
+
+	// 与库中已有数据重复
+	if resp, _ := BulkCreateExecutor(bulkCreate(ws, `[{"name":"alice","email":"a@test.io"}]`)); resp.Code != string(constant.SUCCESS) {
+		t.Fatalf("插入失败: %+v", resp)
+	}
+	resp, _ := BulkCreateExecutor(bulkCreate(ws, `[{"name":"bob","email":"b@test.io"},{"name":"carol","email":"a@test.io"}]`))
+	if resp.Code != string(constant.DUPLICATE_RECORD) {
+		t.Fatalf("期望返回重复记录, got %+v", resp)
+	}
+	if n := countStaff(t, ws); n != 1 {
+		t.Fatalf("重复时不应插入数据, got %d", n)
+	}
+}
+
+func TestBulkCreateExecutorRollsBackFailedBatch(t *testing.T) {
+	ws := newBulkTestServer(t)
+	db := ws.Repo().Use(bulkEntity.Project)
+	// 与生产配置一致，关闭默认事务
+	db.Config.SkipDefaultTransaction = true
+	// 第二批插入时由数据库拒绝，第一批应一并回滚
+	err := db.Exec(`CREATE TRIGGER reject_boom BEFORE INSERT ON shop_staff
+		WHEN NEW.name = 'boom' BEGIN SELECT RAISE(ABORT, 'rejected'); END`).Error
+	if err != nil {
+		t.Fatalf("创建触发器失败: %v", err)
+	}
+	resp, _ := BulkCreateExecutor(bulkCreate(ws, `[{"name":"alice"},{"name":"bob"},{"name":"boom"}]`))
+	if resp.Code != string(constant.FAIL_TO_CREATE) {
+		t.Fatalf("期望创建失败, got %+v", resp)
+	}
+	if n := countStaff(t, ws); n != 0 {
+		t.Fatalf("任一批失败时应全部回滚, got %d", n)
+	}
+}
+
+func TestBulkBatchSize(t *testing.T) {
+	cases := []struct {
+		settings []core.EventParam
+		want     int
+	}{
+		{nil, DEFAULT_BULK_BATCH_SIZE},
+		{[]core.EventParam{{Name: "batch_size", Type: "int"}}, DEFAULT_BULK_BATCH_SIZE},
+		{[]core.EventParam{{Name: "batch_size", Type: "int", RangeValue: "-1"}}, DEFAULT_BULK_BATCH_SIZE},
+		{[]core.EventParam{{Name: "batch_size", Type: "int", RangeValue: " 100 "}}, 100},
+	}
+	for _, c := range cases {
+		if got := bulkBatchSize(c.settings); got != c.want {
+			t.Errorf("%+v: want %d, got %d", c.settings, c.want, got)
+		}
+	}
+}
@@ -22,33 +22,91 @@ import (
 	"github.com/garrickvan/event-matrix/core"
 	"github.com/garrickvan/event-matrix/utils"
 	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/utils/logx"
 	"github.com/garrickvan/event-matrix/worker/types"
+	"gorm.io/gorm"
 )
 
+// CreateExecutor 新建数据，事件开启幂等性时按请求参数 idempotency_key 只执行一次
 func CreateExecutor(ctx types.WorkerContext) (*jsonx.JsonResponse, int) {
+	return idempotentExecute(ctx, createExecutor)
+}
+
+func createExecutor(ctx types.WorkerContext) (*jsonx.JsonResponse, int) {
 	event := ctx.Event()
 	if event == nil {
 		return jsonx.DefaultJson(constant.EVENT_NOT_EXIST), http.StatusOK
 	}
-	entityAttrs, _, params, errJson := ctx.ValidatedParams()
+	entityAttrs, paramSettings, params, errJson := ctx.ValidatedParams()
 	if errJson != nil {
 		return errJson, http.StatusOK
 	}
-	// 构建数据
+	dryRun := isDryRun(paramSettings, params)
+	newData, errJson := buildCreateData(ctx, entityAttrs, params)
+	if errJson != nil {
+		return errJson, http.StatusOK
+	}
+	// 唯一数据查重，避免插入时数据库返回包含表结构信息的错误
+	db := ctx.Server().Repo().Use(event.Project)
+	if errJson := checkUniqueAttrs(db, event, entityAttrs, newData); errJson != nil {
+		return errJson, http.StatusOK
+	}
+	// 保存数据
+	result := execWrite(db, dryRun, func(tx *gorm.DB) *gorm.DB {
+		return tx.Table(event.GetTabelName()).Create(newData)
+	})
+	if result.Error != nil {
+		return jsonx.DefaultJson(constant.FAIL_TO_CREATE), http.StatusOK
+	}
+	if result.RowsAffected == 0 {
+		return jsonx.DefaultJson(constant.FAIL_TO_CREATE), http.StatusOK
+	}
+	// 过滤保密字段的数据
+	for _, attr := range entityAttrs {
+		if attr.IsSecrecy {
+			delete(newData, attr.Code)
+		}
+	}
+	// 返回结果
+	resp := jsonx.DefaultJson(constant.SUCCESS)
+	resp.DryRun = dryRun
+	jsonx.SetJsonList[map[string]interface{}](resp, []map[string]interface{}{newData}, 1, 1)
+	return resp, http.StatusOK
+}
+
+// buildCreateData 按实体属性构建待插入的数据，未传入的字段使用默认值，并补充 id、创建人和时间字段
+func buildCreateData(ctx types.WorkerContext, entityAttrs []core.EntityAttribute, params map[string]interface{}) (map[string]interface{}, *jsonx.JsonResponse) {
 	newData := map[string]interface{}{}
 	for _, attr := range entityAttrs {
 		var preVal interface{}
 		// 从参数中获取值
 		if val, ok := params[attr.Code]; ok {
 			preVal = val
+			// 未配置参数校验的字段也需满足属性的长度限制
+			if attr.FieldType != string(core.CUSTOM_FIELD_TYPE) {
+				fixed, err := attr.FixValue(val)
+				if err != nil {
+					errRespone := jsonx.DefaultJson(constant.INVALID_PARAM)
+					errRespone.Message = err.Error()
+					return nil, errRespone
+				}
+				preVal = fixed
+			}
 		} else {
 			// 若参数中没有值，则使用默认值
 			if attr.FieldType == string(core.CUSTOM_FIELD_TYPE) {
 				if parser, ok := ctx.Server().Repo().GetCustomFieldParser(attr.ValueSource); ok && parser != nil {
 					preVal = parser.DefaultValue()
 				}
-			} else {
-				preVal = attr.GetDefaultVal()
+			} else if def := attr.GetDefaultVal(); def != nil {
+				// 默认值同样需满足属性的长度限制
+				fixed, err := attr.FixValue(def)
+				if err != nil {
+					errRespone := jsonx.DefaultJson(constant.INVALID_PARAM)
+					errRespone.Message = err.Error()
+					return nil, errRespone
+				}
+				preVal = fixed
 			}
 		}
 		// 补充必要参数
@@ -64,54 +122,49 @@ func CreateExecutor(ctx types.WorkerContext) (*jsonx.JsonResponse, int) {
 	if id, ok := newData["id"]; !ok || id == nil || id == "" {
 		newData["id"] = utils.GenID() // 使用UUID生成器生成唯一ID
 	}
-	// 唯一数据查重
 	for _, attr := range entityAttrs {
-		if attr.Unique && attr.Code != "id" {
-			val := newData[attr.Code]
-			if alreadyExist(event, ctx, attr, val) {
-				errRespone := jsonx.DefaultJson(constant.ALREADY_EXIST)
-				errRespone.Message = fmt.Sprintf("属性[%s]已存在", attr.Name)
-				return errRespone, http.StatusOK
-			}
-		}
-		if attr.Code == "updated_at" && attr.FieldType == string(core.DATETIME_FIELD_TYPE) {
-			newData[attr.Code] = utils.GetNowMilli()
+		if attr.FieldType != string(core.DATETIME_FIELD_TYPE) {
+			continue
 		}
-		if attr.Code == "created_at" && attr.FieldType == string(core.DATETIME_FIELD_TYPE) {
+		switch attr.Code {
+		case "updated_at", "created_at":
 			newData[attr.Code] = utils.GetNowMilli()
-		}
-		if attr.Code == "deleted_at" && attr.FieldType == string(core.DATETIME_FIELD_TYPE) {
+		case "deleted_at":
 			newData[attr.Code] = 0
 		}
 	}
-	// 保存数据
-	result := ctx.Server().Repo().Use(event.Project).Table(event.GetTabelName()).Create(newData)
-	if result.Error != nil {
-		return jsonx.DefaultJson(constant.FAIL_TO_CREATE), http.StatusOK
-	}
-	if result.RowsAffected == 0 {
-		return jsonx.DefaultJson(constant.FAIL_TO_CREATE), http.StatusOK
-	}
-	// 过滤保密字段的数据
+	return newData, nil
+}
+
+// checkUniqueAttrs 检查唯一字段在库中是否已存在，存在时返回重复记录的响应
+func checkUniqueAttrs(db *gorm.DB, event *core.Event, entityAttrs []core.EntityAttribute, newData map[string]interface{}) *jsonx.JsonResponse {
 	for _, attr := range entityAttrs {
-		if attr.IsSecrecy {
-			delete(newData, attr.Code)
+		if !attr.Unique || attr.Code == "id" || newData[attr.Code] == nil {
+			continue
+		}
+		exist, err := alreadyExist(db, event, attr, newData[attr.Code])
+		if err != nil {
+			logx.Error("唯一字段查重失败: " + err.Error())
+			return jsonx.DefaultJson(constant.FAIL_TO_CREATE)
+		}
+		if exist {
+			return duplicateRecordJson(attr)
 		}
 	}
-	// 返回结果
-	resp := jsonx.DefaultJson(constant.SUCCESS)
-	jsonx.SetJsonList[map[string]interface{}](resp, []map[string]interface{}{newData}, 1, 1)
-	return resp, http.StatusOK
+	return nil
 }
 
-func alreadyExist(event *core.Event, ctx types.WorkerContext, attr core.EntityAttribute, val interface{}) bool {
+func alreadyExist(db *gorm.DB, event *core.Event, attr core.EntityAttribute, val interface{}) (bool, error) {
 	count := int64(0)
-	queryMap := map[string]interface{}{
-		attr.Code: val,
-	}
-	ctx.Server().Repo().Use(event.Project).Table(event.GetTabelName()).Where(queryMap).Count(&count)
-	if count > 0 {
-		return true
-	}
-	return false
+	err := db.Table(event.GetTabelName()).
+		Where(attr.Code+" = ?", val).Count(&count).Error
+	return count > 0, err
+}
+
+// duplicateRecordJson 构建唯一字段重复的响应，list 中返回冲突的字段编码
+func duplicateRecordJson(attr core.EntityAttribute) *jsonx.JsonResponse {
+	resp := jsonx.DefaultJson(constant.DUPLICATE_RECORD)
+	resp.Message = fmt.Sprintf("属性[%s]已存在", attr.Name)
+	jsonx.SetJsonList(resp, []map[string]interface{}{{"field": attr.Code}}, 1, 1)
+	return resp
 }
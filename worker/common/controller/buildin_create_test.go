@@ -0,0 +1,195 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/worker/types"
+	"github.com/spf13/cast"
+)
+
+var createEntity = types.PathToEntity{Project: "demo", Version: "v1", Context: "shop", Entity: "member"}
+
+func newCreateTestServer(t *testing.T) *types.MockWorkerServer {
+	t.Helper()
+	ws := types.NewMockWorkerServer(nil)
+	t.Cleanup(func() { ws.Stop() })
+
+	ws.SetEntityAttrs(createEntity, []core.EntityAttribute{
+		{Code: "id", FieldType: "id"},
+		{Code: "name", Name: "昵称", FieldType: "string"},
+		{Code: "email", Name: "邮箱", FieldType: "email", Unique: true},
+	})
+	ws.SetEntityEvents(createEntity, []core.EntityEvent{{Code: "create"}})
+	err := ws.Repo().Use(createEntity.Project).
+		Exec("CREATE TABLE shop_member (id TEXT PRIMARY KEY, name TEXT, email TEXT UNIQUE)").Error
+	if err != nil {
+		t.Fatalf("建表失败: %v", err)
+	}
+	return ws
+}
+
+func createMember(ws *types.MockWorkerServer, params string) string {
+	ctx := types.NewMockRequestContext(ws, &core.Event{
+		Project: createEntity.Project,
+		Version: createEntity.Version,
+		Context: createEntity.Context,
+		Entity:  createEntity.Entity,
+		Event:   "create",
+		Params:  params,
+	})
+	resp, _ := CreateExecutor(ctx)
+	if resp.Code != string(constant.SUCCESS) {
+		return resp.Code + ":" + resp.Message
+	}
+	return resp.Code
+}
+
+func TestCreateExecutorUniqueCheck(t *testing.T) {
+	ws := newCreateTestServer(t)
+	ok := string(constant.SUCCESS)
+
+	if got := createMember(ws, `{"name":"alice","email":"a@test.io"}`); got != ok {
+		t.Fatalf("first insert failed: %s", got)
+	}
+	// 非唯一字段重复不做检查
+	if got := createMember(ws, `{"name":"alice","email":"b@test.io"}`); got != ok {
+		t.Fatalf("duplicate non-unique field should be accepted: %s", got)
+	}
+	// 唯一字段为空时不做检查
+	for i := 0; i < 2; i++ {
+		if got := createMember(ws, `{"name":"anonymous"}`); got != ok {
+			t.Fatalf("empty unique field should be accepted: %s", got)
+		}
+	}
+
+	ctx := types.NewMockRequestContext(ws, &core.Event{
+		Project: createEntity.Project, Version: createEntity.Version, Context: createEntity.Context,
+		Entity: createEntity.Entity, Event: "create", Params: `{"name":"bob","email":"a@test.io"}`,
+	})
+	resp, _ := CreateExecutor(ctx)
+	if resp.Code != string(constant.DUPLICATE_RECORD) {
+		t.Fatalf("expected duplicate record, got %s: %s", resp.Code, resp.Message)
+	}
+	if len(resp.List) != 1 || resp.List[0].(map[string]interface{})["field"] != "email" {
+		t.Fatalf("expected conflicting field in list, got %v", resp.List)
+	}
+	if !strings.Contains(resp.Message, "邮箱") || strings.Contains(resp.Message, "shop_member") {
+		t.Fatalf("unexpected message: %s", resp.Message)
+	}
+}
+
+func TestCreateExecutorIdempotent(t *testing.T) {
+	ws := newCreateTestServer(t)
+	ws.SetEntityEvents(createEntity, []core.EntityEvent{{Code: "create", Idempotent: true, Timeout: 60}})
+	create := func(params string) *jsonx.JsonResponse {
+		ctx := types.NewMockRequestContext(ws, &core.Event{
+			Project: createEntity.Project, Version: createEntity.Version, Context: createEntity.Context,
+			Entity: createEntity.Entity, Event: "create", Params: params,
+		})
+		resp, _ := CreateExecutor(ctx)
+		return resp
+	}
+	count := func() int64 {
+		var n int64
+		ws.Repo().Use(createEntity.Project).Table("shop_member").Count(&n)
+		return n
+	}
+
+	if resp := create(`{"name":"alice"}`); resp.Code != string(constant.MISSING_PARAM) {
+		t.Fatalf("expected missing idempotency key, got %s", resp.Code)
+	}
+	if resp := create(`{"name":"alice","idempotency_key":1}`); resp.Code != string(constant.MISSING_PARAM) {
+		t.Fatalf("expected non-string idempotency key rejected, got %s", resp.Code)
+	}
+
+	// 相同请求键并发提交只新建一条数据，且都返回同一条数据
+	var wg sync.WaitGroup
+	ids := make([]interface{}, 5)
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp := create(`{"name":"alice","idempotency_key":"k1"}`)
+			if resp.Code == string(constant.SUCCESS) {
+				ids[i] = resp.List[0].(map[string]interface{})["id"]
+			}
+		}(i)
+	}
+	wg.Wait()
+	resp := create(`{"name":"alice","idempotency_key":"k1"}`)
+	if resp.Code != string(constant.SUCCESS) {
+		t.Fatalf("replay failed: %s", resp.Code)
+	}
+	first := resp.List[0].(map[string]interface{})["id"]
+	for _, id := range ids {
+		if id != first {
+			t.Fatalf("expected all responses to share id %v, got %v", first, ids)
+		}
+	}
+	if n := count(); n != 1 {
+		t.Fatalf("expected 1 row, got %d", n)
+	}
+
+	// 不同请求键正常新建
+	if resp := create(`{"name":"bob","idempotency_key":"k2"}`); resp.Code != string(constant.SUCCESS) {
+		t.Fatalf("second key failed: %s", resp.Code)
+	}
+	if n := count(); n != 2 {
+		t.Fatalf("expected 2 rows, got %d", n)
+	}
+}
+
+func TestCreateExecutorDefaultValue(t *testing.T) {
+	ws := newCreateTestServer(t)
+	ws.SetEntityAttrs(createEntity, []core.EntityAttribute{
+		{Code: "id", FieldType: "id"},
+		{Code: "name", FieldType: "string", DefaultValue: "anonymous", MaxLength: 4},
+		{Code: "level", FieldType: "int32", DefaultValue: "3"},
+		{Code: "joined_at", FieldType: "datetime", DefaultValue: core.DEFAULT_VALUE_NOW},
+	})
+	err := ws.Repo().Use(createEntity.Project).
+		Exec("ALTER TABLE shop_member ADD COLUMN level INTEGER").Error
+	if err == nil {
+		err = ws.Repo().Use(createEntity.Project).Exec("ALTER TABLE shop_member ADD COLUMN joined_at INTEGER").Error
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := types.NewMockRequestContext(ws, &core.Event{
+		Project: createEntity.Project, Version: createEntity.Version, Context: createEntity.Context,
+		Entity: createEntity.Entity, Event: "create", Params: `{}`,
+	})
+	before := utils.GetNowMilli()
+	resp, _ := CreateExecutor(ctx)
+	if resp.Code != string(constant.SUCCESS) {
+		t.Fatalf("create failed: %s %s", resp.Code, resp.Message)
+	}
+	row := resp.List[0].(map[string]interface{})
+	// 默认值按属性校正类型和长度
+	if row["name"] != "anon" || row["level"] != int64(3) {
+		t.Fatalf("unexpected defaults: %v", row)
+	}
+	if joined := cast.ToInt64(row["joined_at"]); joined < before {
+		t.Fatalf("$now should be replaced by current time, got %v", row["joined_at"])
+	}
+}
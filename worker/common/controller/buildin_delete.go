@@ -15,6 +15,7 @@
 package controller
 
 import (
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -22,15 +23,21 @@ import (
 	"github.com/garrickvan/event-matrix/core"
 	"github.com/garrickvan/event-matrix/utils"
 	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/utils/logx"
 	"github.com/garrickvan/event-matrix/worker/types"
 	"github.com/spf13/cast"
+	"gorm.io/gorm"
 )
 
+// DEFAULT_MAX_DELETE_SIZE 单次删除或恢复的默认最大数量
+const DEFAULT_MAX_DELETE_SIZE = 200
+
 func DeleteExecutor(ctx types.WorkerContext) (*jsonx.JsonResponse, int) {
 	entityAttrs, paramSettings, params, errJson := ctx.ValidatedParams()
 	if errJson != nil {
 		return errJson, http.StatusOK
 	}
+	dryRun := isDryRun(paramSettings, params)
 	// 检查必要参数ids是否存在
 	_, hasIDs := core.FindParamFromArray("ids", paramSettings)
 	if !hasIDs {
@@ -57,25 +64,16 @@ func DeleteExecutor(ctx types.WorkerContext) (*jsonx.JsonResponse, int) {
 		errRespone.Message = "少传必要参数[ids]"
 		return errRespone, http.StatusOK
 	}
-	if len(idsArray) > 200 {
+	if limit := maxDeleteSize(paramSettings); limit > 0 && len(idsArray) > limit {
 		errRespone := jsonx.DefaultJson(constant.INVALID_PARAM)
-		errRespone.Message = "参数[ids]数量过多"
+		errRespone.Message = fmt.Sprintf("参数[ids]数量过多，最多%d个", limit)
 		return errRespone, http.StatusOK
 	}
 	// 生成软删除参数
 	updateParams := map[string]interface{}{
 		"deleted_at": utils.GetNowMilli(),
 	}
-	hasDeletedAt := false
-	hasDeletedBy := false
-	for _, attr := range entityAttrs {
-		if attr.Code == "deleted_at" && attr.FieldType == string(core.DATETIME_FIELD_TYPE) {
-			hasDeletedAt = true
-		}
-		if attr.Code == "deleted_by" && attr.FieldType == string(core.UID_FIELD_TYPE) {
-			hasDeletedBy = true
-		}
-	}
+	hasDeletedAt, hasDeletedBy := RequiresSoftDeleteFields(entityAttrs)
 	// 检查是否定义了删除时间
 	if !hasDeletedAt {
 		errRespone := jsonx.DefaultJson(constant.MISSING_PARAM)
@@ -94,8 +92,9 @@ func DeleteExecutor(ctx types.WorkerContext) (*jsonx.JsonResponse, int) {
 		return jsonx.DefaultJson(constant.EVENT_NOT_EXIST), http.StatusOK
 	}
 	// 删除数据
-	table := ctx.Server().Repo().Use(event.Project).Table(event.GetTabelName())
-	result := table.Where("id IN?", idsArray).Updates(updateParams)
+	result := execWrite(ctx.Server().Repo().Use(event.Project), dryRun, func(tx *gorm.DB) *gorm.DB {
+		return tx.Table(event.GetTabelName()).Where("id IN?", idsArray).Updates(updateParams)
+	})
 	if result.Error != nil {
 		errRespone := jsonx.DefaultJson(constant.FAIL_TO_DELETE)
 		errRespone.Message = result.Error.Error()
@@ -109,6 +108,7 @@ func DeleteExecutor(ctx types.WorkerContext) (*jsonx.JsonResponse, int) {
 	// 响应结果
 	resp := jsonx.DefaultJsonWithMsg(constant.SUCCESS, "全部删除成功")
 	resp.Total = result.RowsAffected
+	resp.DryRun = dryRun
 	return resp, http.StatusOK
 }
 
@@ -117,6 +117,7 @@ func RestoreExecutor(ctx types.WorkerContext) (*jsonx.JsonResponse, int) {
 	if errJson != nil {
 		return errJson, http.StatusOK
 	}
+	dryRun := isDryRun(paramSettings, params)
 	// 检查必要参数ids是否存在
 	_, hasIDs := core.FindParamFromArray("ids", paramSettings)
 	if !hasIDs {
@@ -143,25 +144,16 @@ func RestoreExecutor(ctx types.WorkerContext) (*jsonx.JsonResponse, int) {
 		errRespone.Message = "少传必要参数[ids]"
 		return errRespone, http.StatusOK
 	}
-	if len(idsArray) > 200 {
+	if limit := maxDeleteSize(paramSettings); limit > 0 && len(idsArray) > limit {
 		errRespone := jsonx.DefaultJson(constant.INVALID_PARAM)
-		errRespone.Message = "参数[ids]数量过多"
+		errRespone.Message = fmt.Sprintf("参数[ids]数量过多，最多%d个", limit)
 		return errRespone, http.StatusOK
 	}
 	// 生成伪删除参数
 	updateParams := map[string]interface{}{
 		"deleted_at": 0,
 	}
-	hasDeletedAt := false
-	hasDeletedBy := false
-	for _, attr := range entityAttrs {
-		if attr.Code == "deleted_at" && attr.FieldType == string(core.DATETIME_FIELD_TYPE) {
-			hasDeletedAt = true
-		}
-		if attr.Code == "deleted_by" && attr.FieldType == string(core.UID_FIELD_TYPE) {
-			hasDeletedBy = true
-		}
-	}
+	hasDeletedAt, hasDeletedBy := RequiresSoftDeleteFields(entityAttrs)
 	// 检查是否定义了删除时间
 	if !hasDeletedAt {
 		errRespone := jsonx.DefaultJson(constant.MISSING_PARAM)
@@ -177,8 +169,9 @@ func RestoreExecutor(ctx types.WorkerContext) (*jsonx.JsonResponse, int) {
 		return jsonx.DefaultJson(constant.EVENT_NOT_EXIST), http.StatusOK
 	}
 	// 恢复数据
-	table := ctx.Server().Repo().Use(event.Project).Table(event.GetTabelName())
-	result := table.Where("id IN?", idsArray).Updates(updateParams)
+	result := execWrite(ctx.Server().Repo().Use(event.Project), dryRun, func(tx *gorm.DB) *gorm.DB {
+		return tx.Table(event.GetTabelName()).Where("id IN?", idsArray).Updates(updateParams)
+	})
 	if result.Error != nil {
 		errRespone := jsonx.DefaultJson(constant.FAIL_TO_PROCESS)
 		errRespone.Message = result.Error.Error()
@@ -192,5 +185,24 @@ func RestoreExecutor(ctx types.WorkerContext) (*jsonx.JsonResponse, int) {
 	// 响应结果
 	resp := jsonx.DefaultJsonWithMsg(constant.SUCCESS, "全部恢复成功")
 	resp.Total = result.RowsAffected
+	resp.DryRun = dryRun
 	return resp, http.StatusOK
 }
+
+// maxDeleteSize 读取名为 max_delete_size 的参数设置，RangeValue 为单次删除或恢复的最大数量，
+// 未设置或无效时使用默认值。设置为0时不限制数量，一次请求可能更新大量数据，需谨慎使用
+func maxDeleteSize(paramSettings []core.EventParam) int {
+	setting, ok := core.FindParamFromArray("max_delete_size", paramSettings)
+	if !ok {
+		return DEFAULT_MAX_DELETE_SIZE
+	}
+	value := strings.TrimSpace(setting.RangeValue)
+	size, err := cast.ToIntE(value)
+	if value == "" || err != nil || size < 0 {
+		return DEFAULT_MAX_DELETE_SIZE
+	}
+	if size == 0 {
+		logx.Warn("参数[max_delete_size]为0，不限制单次删除或恢复的数量")
+	}
+	return size
+}
@@ -15,14 +15,18 @@
 package controller
 
 import (
+	"context"
+	"encoding/base64"
 	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/garrickvan/event-matrix/constant"
 	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils"
 	"github.com/garrickvan/event-matrix/utils/jsonx"
 	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/common"
 	"github.com/garrickvan/event-matrix/worker/types"
 	"github.com/spf13/cast"
 	"gorm.io/gorm"
@@ -38,6 +42,7 @@ func QueryExecutor(ctx types.WorkerContext) (*jsonx.JsonResponse, int) {
 		return errJson, http.StatusOK
 	}
 
+	dryRun := isDryRun(paramSettings, params)
 	_, hasPage := core.FindParamFromArray("page", paramSettings)
 	_, hasSize := core.FindParamFromArray("page_size", paramSettings)
 	if !hasPage {
@@ -50,13 +55,19 @@ func QueryExecutor(ctx types.WorkerContext) (*jsonx.JsonResponse, int) {
 		errRespone.Message = "缺少必须参数page_size"
 		return errRespone, http.StatusOK
 	}
-	deleted, ok := params["deleted"]
-	if !ok {
-		deleted = false
+	deleted := SoftDeleteQueryParam(params)
+	cursorSetting, useCursor := findCursorParam(paramSettings)
+	cursor := ""
+	if useCursor {
+		cursor = cast.ToString(params[cursorSetting.Name])
+	}
+	// 带游标时按键集分页，不再查询总数
+	if cursor != "" {
+		return cursorQuery(ctx, event, cursorSetting, cursor, paramSettings, params, entityAttrs, deleted, dryRun)
 	}
 	// 构建查询条件
 	var count int64
-	countQuery := buildQuerySchema(ctx, event, paramSettings, params, entityAttrs, cast.ToBool(deleted))
+	countQuery := buildQuerySchema(ctx, event, paramSettings, params, entityAttrs, deleted)
 	// 查询总数
 	countQuery = countQuery.Count(&count)
 	if countQuery.Error != nil {
@@ -64,44 +75,320 @@ func QueryExecutor(ctx types.WorkerContext) (*jsonx.JsonResponse, int) {
 		return jsonx.DefaultJson(constant.FAIL_TO_QUERY), http.StatusOK
 	}
 	result := jsonx.DefaultJsonWithMsg(constant.SUCCESS, "查询成功")
-	if count == 0 {
-		result.Message = "查询结果为空"
-		return result, http.StatusOK
-	}
+	result.DryRun = dryRun
 	// 构建查询分页信息
 	page := cast.ToInt(params["page"])
 	pageSize := cast.ToInt(params["page_size"])
-	query := buildQuerySchema(ctx, event, paramSettings, params, entityAttrs, cast.ToBool(deleted))
-	// 排序
-	for _, v := range paramSettings {
-		order_by := ""
-		if v.Type == "order_by" {
-			order_by += v.Name + " " + v.Range + " "
-		}
-		if order_by != "" {
-			query = query.Order(order_by)
-		}
+	query := buildQuerySchema(ctx, event, paramSettings, params, entityAttrs, deleted)
+	// 声明了游标参数时首页也按游标字段排序，保证与后续页的顺序一致
+	if useCursor {
+		query = query.Order(cursorColumn(cursorSetting))
 	}
+	query = orderQuery(query, paramSettings)
 	// 分页
 	query = query.Offset((page - 1) * pageSize).Limit(pageSize)
+	attachExplain(ctx, query, result)
+	if count == 0 && !common.IsStreamQuery(ctx) {
+		result.Message = "查询结果为空"
+		return result, http.StatusOK
+	}
+	if common.IsStreamQuery(ctx) {
+		return streamQuery(ctx, query, entityAttrs, result, count)
+	}
 	queryData := make([]map[string]interface{}, 0)
 	query = query.Find(&queryData)
 	if query.Error != nil {
 		logx.Log().Error("查询错误：" + query.Error.Error())
 		return jsonx.DefaultJson(constant.FAIL_TO_QUERY), http.StatusOK
 	}
-	// 构建返回结果
+	if useCursor {
+		result.NextCursor = nextCursor(queryData, cursorSetting, pageSize)
+	}
+	formatQueryData(ctx, queryData, entityAttrs)
+	jsonx.SetJsonList[map[string]interface{}](result, queryData, count, page)
+	return result, http.StatusOK
+}
+
+// cursorQuery 键集分页查询，以 WHERE 游标字段 > 游标值 代替 OFFSET，便于数据库使用索引
+func cursorQuery(
+	ctx types.WorkerContext,
+	event *core.Event,
+	cursorSetting *core.EventParam,
+	cursor string,
+	paramSettings []core.EventParam,
+	params map[string]interface{},
+	entityAttrs []core.EntityAttribute,
+	deleted bool,
+	dryRun bool,
+) (*jsonx.JsonResponse, int) {
+	column := cursorColumn(cursorSetting)
+	last, err := decodeCursor(cursor)
+	if err != nil {
+		errRespone := jsonx.DefaultJson(constant.INVALID_PARAM)
+		errRespone.Message = "无效的游标参数" + cursorSetting.Name
+		return errRespone, http.StatusOK
+	}
+	var lastVal interface{} = last
+	if attr := core.FindAttrFromArray(column, entityAttrs); attr != nil {
+		lastVal = core.FixAttributeValue(last, attr.FieldType)
+	}
+	pageSize := cast.ToInt(params["page_size"])
+	query := buildQuerySchema(ctx, event, paramSettings, params, entityAttrs, deleted)
+	query = query.Where(column+" > ?", lastVal).Order(column)
+	query = orderQuery(query, paramSettings).Limit(pageSize)
+	result := jsonx.DefaultJsonWithMsg(constant.SUCCESS, "查询成功")
+	result.DryRun = dryRun
+	attachExplain(ctx, query, result)
+	queryData := make([]map[string]interface{}, 0)
+	query = query.Find(&queryData)
+	if query.Error != nil {
+		logx.Log().Error("查询错误：" + query.Error.Error())
+		return jsonx.DefaultJson(constant.FAIL_TO_QUERY), http.StatusOK
+	}
+	result.NextCursor = nextCursor(queryData, cursorSetting, pageSize)
+	if len(queryData) == 0 {
+		result.Message = "查询结果为空"
+	}
+	formatQueryData(ctx, queryData, entityAttrs)
+	// 游标分页不统计总数
+	jsonx.SetJsonList[map[string]interface{}](result, queryData, 0, 0)
+	return result, http.StatusOK
+}
+
+// orderQuery 按 order_by 类型的参数设置追加排序，多个排序参数按定义顺序依次生效
+func orderQuery(query *gorm.DB, paramSettings []core.EventParam) *gorm.DB {
+	orders := make([]string, 0, 2)
+	for _, v := range paramSettings {
+		if v.Type != string(core.ORDER_BY_FIELD_TYPE) {
+			continue
+		}
+		direction, ok := orderDirection(query, v.Range)
+		if !ok {
+			logx.Warn("不支持的排序方式: " + v.Range + " 字段: " + v.Name)
+			continue
+		}
+		orders = append(orders, v.Name+" "+direction)
+	}
+	for _, order := range orders {
+		query = query.Order(order)
+	}
+	return query
+}
+
+// orderDirection 规范化 order_by 参数的 Range，支持 asc、desc 以及附加 nulls first / nulls last，
+// 为空时按 asc 排序，MySQL 不支持 nulls 排序，忽略该部分
+func orderDirection(db *gorm.DB, rangeVal string) (string, bool) {
+	parts := strings.Fields(strings.ToLower(rangeVal))
+	if len(parts) == 0 {
+		return "asc", true
+	}
+	if parts[0] != "asc" && parts[0] != "desc" {
+		return "", false
+	}
+	switch len(parts) {
+	case 1:
+		return parts[0], true
+	case 3:
+		if parts[1] != "nulls" || (parts[2] != "first" && parts[2] != "last") {
+			return "", false
+		}
+		if db.Dialector.Name() == "mysql" {
+			logx.Warn("MySQL 不支持 nulls " + parts[2] + " 排序，已忽略")
+			return parts[0], true
+		}
+		return strings.Join(parts, " "), true
+	default:
+		return "", false
+	}
+}
+
+// formatQueryData 移除保密字段，自定义字段按解析器的展示格式返回
+func formatQueryData(ctx types.WorkerContext, queryData []map[string]interface{}, entityAttrs []core.EntityAttribute) {
+	formatters := customFieldFormatters(ctx, entityAttrs)
 	for i := 0; i < len(queryData); i++ {
-		for _, v := range entityAttrs {
-			if v.IsSecrecy {
-				delete(queryData[i], v.Code)
+		formatQueryRow(queryData[i], entityAttrs, formatters)
+	}
+}
+
+// formatQueryRow 移除单行数据的保密字段，并格式化自定义字段
+func formatQueryRow(row map[string]interface{}, entityAttrs []core.EntityAttribute, formatters map[string]types.CustomFieldParser) {
+	for _, v := range entityAttrs {
+		if v.IsSecrecy {
+			delete(row, v.Code)
+		}
+	}
+	for code, parser := range formatters {
+		if val, ok := row[code]; ok && val != nil {
+			row[code] = parser.Format(val)
+		}
+	}
+}
+
+// streamQuery 使用游标逐行读取查询结果并通过 BatchResponse 写出，不在内存中保存整页数据，
+// 返回的结果只用于记录日志，不再写出
+func streamQuery(
+	ctx types.WorkerContext,
+	query *gorm.DB,
+	entityAttrs []core.EntityAttribute,
+	result *jsonx.JsonResponse,
+	count int64,
+) (*jsonx.JsonResponse, int) {
+	// 超过流式查询的超时时间后中断读取，以错误记录结束流
+	timeoutCtx, cancel := context.WithTimeout(query.Statement.Context, common.StreamQueryTimeout(ctx.EntityEvent()))
+	defer cancel()
+	rows, err := query.WithContext(timeoutCtx).Rows()
+	if err != nil {
+		logx.Error("查询错误：" + err.Error())
+		return jsonx.DefaultJson(constant.FAIL_TO_QUERY), http.StatusOK
+	}
+	defer rows.Close()
+	formatters := customFieldFormatters(ctx, entityAttrs)
+	var scanErr error
+	writeErr := ctx.BatchResponse(func(yield func(row map[string]interface{}) bool) {
+		for rows.Next() {
+			row := map[string]interface{}{}
+			if scanErr = query.ScanRows(rows, &row); scanErr != nil {
+				break
 			}
+			formatQueryRow(row, entityAttrs, formatters)
+			if !yield(row) {
+				return
+			}
+		}
+		if scanErr == nil {
+			scanErr = rows.Err()
 		}
+		// 已写出部分数据时无法再改写状态码，以一条错误记录结束流，使客户端能识别结果不完整
+		if scanErr != nil {
+			yield(streamErrorRecord(constant.FAIL_TO_QUERY))
+		}
+	})
+	if scanErr != nil {
+		logx.Error("流式查询读取失败：" + scanErr.Error())
+		return jsonx.DefaultJson(constant.FAIL_TO_QUERY), http.StatusOK
 	}
-	jsonx.SetJsonList[map[string]interface{}](result, queryData, count, page)
+	if writeErr != nil {
+		logx.Warn("流式查询写出失败：" + writeErr.Error())
+		return jsonx.DefaultJson(constant.FAIL_TO_QUERY), http.StatusOK
+	}
+	result.Total = count
 	return result, http.StatusOK
 }
 
+// streamErrorRecord 流式查询中途失败时写出的结束记录，字段与JSON响应的 code、message 一致
+func streamErrorRecord(code constant.RESPONSE_CODE) map[string]interface{} {
+	return map[string]interface{}{"code": string(code), "message": constant.MsgForResponseCode(code)}
+}
+
+// queryExplainer 支持返回查询计划的请求上下文，由公共服务在配置开启 AllowExplain 时根据请求头设置
+type queryExplainer interface {
+	Explain() bool
+}
+
+// isExplain 判断请求是否需要返回查询计划
+func isExplain(ctx types.WorkerContext) bool {
+	e, ok := ctx.(queryExplainer)
+	return ok && e.Explain()
+}
+
+// attachExplain 请求需要查询计划时，在执行查询前将查询计划附加到响应中，获取失败不影响查询
+func attachExplain(ctx types.WorkerContext, query *gorm.DB, result *jsonx.JsonResponse) {
+	if !isExplain(ctx) {
+		return
+	}
+	plan, err := explainQuery(query)
+	if err != nil {
+		logx.Warn("获取查询计划失败：" + err.Error())
+		return
+	}
+	result.Explain = plan
+}
+
+// explainQuery 获取查询的执行计划，SQLite 使用 EXPLAIN QUERY PLAN，MySQL、PostgreSQL 使用 EXPLAIN
+func explainQuery(query *gorm.DB) ([]map[string]interface{}, error) {
+	// 以DryRun生成带占位符的SQL及参数，由驱动绑定参数，避免内联参数值
+	stmt := query.Session(&gorm.Session{DryRun: true}).Find(&[]map[string]interface{}{}).Statement
+	prefix := "EXPLAIN "
+	if strings.Contains(query.Dialector.Name(), "sqlite") {
+		prefix = "EXPLAIN QUERY PLAN "
+	}
+	plan := make([]map[string]interface{}, 0)
+	if err := query.Session(&gorm.Session{NewDB: true}).Raw(prefix+stmt.SQL.String(), stmt.Vars...).Scan(&plan).Error; err != nil {
+		return nil, err
+	}
+	// 驱动未提供列类型时扫描结果为指针，统一转为可序列化的值
+	for _, row := range plan {
+		for k, v := range row {
+			if p, ok := v.(*interface{}); ok {
+				v = *p
+			}
+			if b, ok := v.([]byte); ok {
+				v = string(b)
+			}
+			row[k] = v
+		}
+	}
+	return plan, nil
+}
+
+// findCursorParam 查找事件定义的游标分页参数
+func findCursorParam(paramSettings []core.EventParam) (*core.EventParam, bool) {
+	for i := range paramSettings {
+		if paramSettings[i].Type == string(core.CURSOR_FIELD_TYPE) {
+			return &paramSettings[i], true
+		}
+	}
+	return nil, false
+}
+
+// cursorColumn 游标参数的 Range 为排序字段，未设置时使用 id
+func cursorColumn(setting *core.EventParam) string {
+	column := strings.TrimSpace(setting.Range)
+	if column == "" {
+		return "id"
+	}
+	return column
+}
+
+// nextCursor 根据本页最后一条数据生成下一页游标，数据不足一页时说明已无更多数据
+func nextCursor(queryData []map[string]interface{}, setting *core.EventParam, pageSize int) string {
+	if len(queryData) == 0 || len(queryData) < pageSize {
+		return ""
+	}
+	last, ok := queryData[len(queryData)-1][cursorColumn(setting)]
+	if !ok || last == nil {
+		return ""
+	}
+	return encodeCursor(cast.ToString(last))
+}
+
+// encodeCursor 游标对调用方不透明，使用 URL 安全的 base64 编码
+func encodeCursor(val string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(val))
+}
+
+func decodeCursor(cursor string) (string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// customFieldFormatters 获取非保密自定义字段的解析器，key为字段编码
+func customFieldFormatters(ctx types.WorkerContext, entityAttrs []core.EntityAttribute) map[string]types.CustomFieldParser {
+	formatters := map[string]types.CustomFieldParser{}
+	for _, v := range entityAttrs {
+		if v.IsSecrecy || v.FieldType != string(core.CUSTOM_FIELD_TYPE) {
+			continue
+		}
+		if parser, ok := ctx.Server().Repo().GetCustomFieldParser(strings.TrimSpace(v.ValueSource)); ok && parser != nil {
+			formatters[v.Code] = parser
+		}
+	}
+	return formatters
+}
+
 func buildQuerySchema(
 	ctx types.WorkerContext,
 	event *core.Event,
@@ -115,6 +402,10 @@ func buildQuerySchema(
 		if v.Name == "page" || v.Name == "page_size" || v.Name == "deleted" {
 			continue
 		}
+		if v.Type == string(core.DRY_RUN_FIELD_TYPE) || v.Type == string(core.CURSOR_FIELD_TYPE) ||
+			v.Type == string(core.JOIN_QUERY_FIELD_TYPE) {
+			continue
+		}
 		if v.Type == "order_by" {
 			continue
 		}
@@ -127,12 +418,7 @@ func buildQuerySchema(
 			continue
 		}
 	}
-	if deleted {
-		db = db.Where("deleted_at != 0")
-	} else {
-		db = db.Where("deleted_at = 0")
-	}
-	return db
+	return joinQuery(ctx, event, SoftDeleteFilter(db, deleted), paramSettings, entityAttrs)
 }
 
 func buildQuery(db *gorm.DB, setting *core.EventParam, params map[string]interface{}, entityAttrs []core.EntityAttribute, isAnd bool) *gorm.DB {
@@ -169,6 +455,8 @@ func buildQuery(db *gorm.DB, setting *core.EventParam, params map[string]interfa
 		return queryLLike(db, attr, arg, isAnd)
 	case "a_like":
 		return queryALike(db, attr, arg, isAnd)
+	case "regex":
+		return queryRegex(db, attr, arg, isAnd)
 	case "gt":
 		return queryGt(db, attr, arg, isAnd)
 	case "gte":
@@ -185,6 +473,10 @@ func buildQuery(db *gorm.DB, setting *core.EventParam, params map[string]interfa
 		return queryOut(db, attr, arg, isAnd)
 	case "eq_out":
 		return queryEqOut(db, attr, arg, isAnd)
+	case "is_null":
+		return queryNull(db, attr, arg, isAnd, true)
+	case "not_null":
+		return queryNull(db, attr, arg, isAnd, false)
 	default:
 		logx.Log().Warn("未支持的And查询范围类型: " + setting.Range + " 字段: " + setting.Name)
 	}
@@ -793,3 +1085,59 @@ func queryEqOut(db *gorm.DB, attr *core.EntityAttribute, arg interface{}, isAnd
 		return db
 	}
 }
+
+// queryNull 按字段是否为 NULL 查询，参数值为 true 时生效，主键字段不会为 NULL，不支持该查询
+func queryNull(db *gorm.DB, attr *core.EntityAttribute, arg interface{}, isAnd bool, isNull bool) *gorm.DB {
+	if arg == nil || !cast.ToBool(arg) {
+		return db
+	}
+	if attr.FieldType == string(core.ID_FIELD_TYPE) {
+		logx.Warn(fmt.Sprintf("未支持的%s %s查询字段类型: %s 字段: %s", ifThenElse(isAnd, "And", "Or"), ifThenElse(isNull, "is_null", "not_null"), attr.FieldType, attr.Code))
+		return db
+	}
+	cond := attr.Code + " IS NULL"
+	if !isNull {
+		cond = attr.Code + " IS NOT NULL"
+	}
+	if isAnd {
+		return db.Where(cond)
+	}
+	return db.Or(cond)
+}
+
+// queryRegex 按正则表达式查询，根据数据库类型使用对应的操作符：MySQL、SQLite 为 REGEXP，PostgreSQL 为 ~
+// 各数据库的正则语法略有差异，仅保证常用语法可用，表达式需能被 Go 正则编译
+func queryRegex(db *gorm.DB, attr *core.EntityAttribute, arg interface{}, isAnd bool) *gorm.DB {
+	if arg == nil {
+		return db
+	}
+	pattern := cast.ToString(arg)
+	if _, err := utils.CompileRegexp(pattern); err != nil {
+		logx.Warn("正则查询表达式错误: " + err.Error() + " 字段: " + attr.Code)
+		return db
+	}
+	switch attr.FieldType {
+	case "string", "id", "constant", "text", "ref", "uid", "url", "email", "phone":
+	default:
+		if isAnd {
+			logx.Warn("未支持的And regex查询字段类型: " + attr.FieldType + " 字段: " + attr.Code)
+		} else {
+			logx.Warn("未支持的Or regex查询字段类型: " + attr.FieldType + " 字段: " + attr.Code)
+		}
+		return db
+	}
+	var op string
+	switch db.Dialector.Name() {
+	case "mysql", "sqlite":
+		op = " REGEXP ?"
+	case "postgres":
+		op = " ~ ?"
+	default:
+		logx.Warn("数据库不支持正则查询: " + db.Dialector.Name() + " 字段: " + attr.Code)
+		return db
+	}
+	if isAnd {
+		return db.Where(attr.Code+op, pattern)
+	}
+	return db.Or(attr.Code+op, pattern)
+}
@@ -0,0 +1,92 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/types"
+	"gorm.io/gorm"
+)
+
+// JOIN_COLUMN_SEPARATOR 关联实体的字段在结果中以 实体名__字段编码 命名，避免与本实体的外键字段重名
+const JOIN_COLUMN_SEPARATOR = "__"
+
+// joinQuerySetting join_query 参数的 RangeValue，如 {"join_entity":"order","join_on_local":"id","join_on_foreign":"user_id"}
+type joinQuerySetting struct {
+	JoinEntity    string `json:"join_entity"`     // 关联的实体，与本实体属于同一项目、版本和上下文
+	JoinOnLocal   string `json:"join_on_local"`   // 本实体的关联字段
+	JoinOnForeign string `json:"join_on_foreign"` // 关联实体的关联字段
+}
+
+// joinQuery 按 join_query 参数左关联另一个实体表，查询结果附带关联实体的非保密字段。
+// 已过滤的本实体查询和关联结果均包装为子查询，之后的条件、排序和分页不会出现字段名歧义。
+// 参数配置无效时记录日志并按未关联处理
+func joinQuery(
+	ctx types.WorkerContext,
+	event *core.Event,
+	base *gorm.DB,
+	paramSettings []core.EventParam,
+	entityAttrs []core.EntityAttribute,
+) *gorm.DB {
+	var setting *core.EventParam
+	for i := range paramSettings {
+		if paramSettings[i].Type == string(core.JOIN_QUERY_FIELD_TYPE) {
+			setting = &paramSettings[i]
+			break
+		}
+	}
+	if setting == nil {
+		return base
+	}
+	join := joinQuerySetting{}
+	if err := jsonx.UnmarshalFromStr(setting.RangeValue, &join); err != nil || join.JoinEntity == "" {
+		logx.Warn(event.GetFullEventLabel() + "关联查询参数无效: " + setting.RangeValue)
+		return base
+	}
+	if core.FindAttrFromArray(join.JoinOnLocal, entityAttrs) == nil {
+		logx.Warn(event.GetFullEventLabel() + "关联字段不是本实体的属性: " + join.JoinOnLocal)
+		return base
+	}
+	foreignAttrs := ctx.Server().DomainCache().EntityAttrs(types.PathToEntity{
+		Project: event.Project,
+		Version: event.Version,
+		Context: event.Context,
+		Entity:  join.JoinEntity,
+	})
+	if core.FindAttrFromArray(join.JoinOnForeign, foreignAttrs) == nil {
+		logx.Warn(event.GetFullEventLabel() + "关联字段不是实体[" + join.JoinEntity + "]的属性: " + join.JoinOnForeign)
+		return base
+	}
+	selects := []string{"l.*"}
+	for _, attr := range foreignAttrs {
+		if attr.IsSecrecy {
+			continue
+		}
+		selects = append(selects, fmt.Sprintf("f.%s AS %s%s%s", attr.Code, join.JoinEntity, JOIN_COLUMN_SEPARATOR, attr.Code))
+	}
+	on := fmt.Sprintf("LEFT JOIN %s_%s AS f ON l.%s = f.%s", event.Context, join.JoinEntity, join.JoinOnLocal, join.JoinOnForeign)
+	// 不关联已软删除的数据
+	if hasDeletedAt, _ := RequiresSoftDeleteFields(foreignAttrs); hasDeletedAt {
+		on += " AND f.deleted_at = 0"
+	}
+	db := ctx.Server().Repo().Use(event.Project)
+	joined := db.Table("(?) AS l", base).Select(strings.Join(selects, ", ")).Joins(on)
+	return db.Table("(?) AS "+event.GetTabelName(), joined)
+}
@@ -0,0 +1,539 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/worker/types"
+	"github.com/spf13/cast"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var queryEntity = types.PathToEntity{Project: "demo", Version: "v1", Context: "shop", Entity: "user"}
+
+func newQueryTestServer(t *testing.T) *types.MockWorkerServer {
+	t.Helper()
+	ws := types.NewMockWorkerServer(nil)
+	t.Cleanup(func() { ws.Stop() })
+
+	ws.SetEntityAttrs(queryEntity, []core.EntityAttribute{
+		{Code: "id", FieldType: "id"},
+		{Code: "name", FieldType: "string"},
+		{Code: "age", FieldType: "int32"},
+		{Code: "password", FieldType: "string", IsSecrecy: true},
+	})
+	ws.SetEntityEvents(queryEntity, []core.EntityEvent{
+		{
+			Code:   "query",
+			Params: `[{"name":"page","type":"int"},{"name":"page_size","type":"int"},{"name":"age","type":"and_query","range":"gt"}]`,
+		},
+		{
+			Code:   "query_no_page",
+			Params: `[{"name":"age","type":"and_query","range":"gt"}]`,
+		},
+	})
+
+	db := ws.Repo().Use(queryEntity.Project)
+	if db == nil {
+		t.Fatal("获取测试数据库失败")
+	}
+	err := db.Exec("CREATE TABLE shop_user (id TEXT PRIMARY KEY, name TEXT, age INTEGER, password TEXT, deleted_at INTEGER DEFAULT 0)").Error
+	if err != nil {
+		t.Fatalf("建表失败: %v", err)
+	}
+	rows := []map[string]interface{}{
+		{"id": "1", "name": "alice", "age": 18, "password": "p1", "deleted_at": 0},
+		{"id": "2", "name": "bob", "age": 25, "password": "p2", "deleted_at": 0},
+		{"id": "3", "name": "carol", "age": 32, "password": "p3", "deleted_at": 0},
+		{"id": "4", "name": "dave", "age": 40, "password": "p4", "deleted_at": 1700000000},
+	}
+	if err := db.Table("shop_user").Create(rows).Error; err != nil {
+		t.Fatalf("写入测试数据失败: %v", err)
+	}
+	return ws
+}
+
+func newQueryEvent(event, params string) *core.Event {
+	return &core.Event{
+		Project: queryEntity.Project,
+		Version: queryEntity.Version,
+		Context: queryEntity.Context,
+		Entity:  queryEntity.Entity,
+		Event:   event,
+		Params:  params,
+	}
+}
+
+func TestQueryExecutorWithMockServer(t *testing.T) {
+	ws := newQueryTestServer(t)
+	ctx := types.NewMockRequestContext(ws, newQueryEvent("query", `{"page":1,"page_size":10,"age":20}`))
+
+	resp, status := QueryExecutor(ctx)
+	if status != 200 {
+		t.Fatalf("状态码错误: %d", status)
+	}
+	if resp.Code != string(constant.SUCCESS) {
+		t.Fatalf("查询失败: %s %s", resp.Code, resp.Message)
+	}
+	// 已删除的 dave 和不满足条件的 alice 不应返回
+	if resp.Total != 2 || len(resp.List) != 2 {
+		t.Fatalf("期望返回2条数据，实际 total=%d list=%d", resp.Total, len(resp.List))
+	}
+	for _, item := range resp.List {
+		row := item.(map[string]interface{})
+		if _, ok := row["password"]; ok {
+			t.Errorf("保密字段不应返回: %v", row)
+		}
+	}
+}
+
+// streamQueryCtx 模拟URL查询参数带 stream=true 的请求上下文
+type streamQueryCtx struct {
+	*types.MockRequestContext
+}
+
+func (streamQueryCtx) Query(key string) string {
+	if key == "stream" {
+		return "true"
+	}
+	return ""
+}
+
+func TestQueryExecutorStream(t *testing.T) {
+	ws := newQueryTestServer(t)
+	ctx := streamQueryCtx{types.NewMockRequestContext(ws, newQueryEvent("query", `{"page":1,"page_size":10,"age":20}`))}
+
+	resp, _ := QueryExecutor(ctx)
+	if resp.Code != string(constant.SUCCESS) || resp.Total != 2 {
+		t.Fatalf("流式查询失败: %s %s total=%d", resp.Code, resp.Message, resp.Total)
+	}
+	if len(resp.List) != 0 {
+		t.Errorf("流式查询的返回值不应包含数据: %v", resp.List)
+	}
+	if !ctx.Streamed() {
+		t.Fatal("应通过 BatchResponse 写出结果")
+	}
+	lines := strings.Split(strings.TrimSpace(string(ctx.ResponseBody())), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("期望写出2行，实际: %q", ctx.ResponseBody())
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "{") || strings.Contains(line, "password") {
+			t.Errorf("行数据错误: %s", line)
+		}
+	}
+}
+
+// explainQueryCtx 模拟公共服务开启了查询计划的请求上下文
+type explainQueryCtx struct {
+	*types.MockRequestContext
+}
+
+func (explainQueryCtx) Explain() bool { return true }
+
+func TestQueryExecutorExplain(t *testing.T) {
+	ws := newQueryTestServer(t)
+	resp, _ := QueryExecutor(explainQueryCtx{types.NewMockRequestContext(ws, newQueryEvent("query", `{"page":1,"page_size":10,"age":20}`))})
+	if resp.Code != string(constant.SUCCESS) || resp.Total != 2 || len(resp.List) != 2 {
+		t.Fatalf("查询失败: %s %s total=%d", resp.Code, resp.Message, resp.Total)
+	}
+	if len(resp.Explain) == 0 || !strings.Contains(fmt.Sprint(resp.Explain), "shop_user") {
+		t.Fatalf("应返回查询计划: %v", resp.Explain)
+	}
+	// 查询结果为空时同样返回查询计划
+	resp, _ = QueryExecutor(explainQueryCtx{types.NewMockRequestContext(ws, newQueryEvent("query", `{"page":1,"page_size":10,"age":99}`))})
+	if resp.Total != 0 || len(resp.Explain) == 0 {
+		t.Fatalf("空结果应返回查询计划: total=%d %v", resp.Total, resp.Explain)
+	}
+
+	resp, _ = QueryExecutor(types.NewMockRequestContext(ws, newQueryEvent("query", `{"page":1,"page_size":10,"age":20}`)))
+	if resp.Explain != nil {
+		t.Fatalf("未要求时不应返回查询计划: %v", resp.Explain)
+	}
+}
+
+func TestExplainQueryBindsVars(t *testing.T) {
+	ws := newQueryTestServer(t)
+	db := ws.Repo().Use(queryEntity.Project)
+	name := "x'; DROP TABLE shop_user; --"
+	plan, err := explainQuery(db.Table("shop_user").Where("name = ?", name))
+	if err != nil || len(plan) == 0 {
+		t.Fatalf("获取查询计划失败: %v %v", err, plan)
+	}
+	var count int64
+	if err := db.Table("shop_user").Count(&count).Error; err != nil || count != 4 {
+		t.Fatalf("参数应由驱动绑定而非拼接到SQL中: count=%d err=%v", count, err)
+	}
+}
+
+func TestQueryExecutorPagination(t *testing.T) {
+	ws := newQueryTestServer(t)
+	ctx := types.NewMockRequestContext(ws, newQueryEvent("query", `{"page":2,"page_size":2}`))
+
+	resp, _ := QueryExecutor(ctx)
+	if resp.Code != string(constant.SUCCESS) {
+		t.Fatalf("查询失败: %s %s", resp.Code, resp.Message)
+	}
+	if resp.Total != 3 || len(resp.List) != 1 || resp.Page != 2 {
+		t.Fatalf("分页结果错误: total=%d list=%d page=%d", resp.Total, len(resp.List), resp.Page)
+	}
+}
+
+func TestQueryExecutorMissingPageParam(t *testing.T) {
+	ws := newQueryTestServer(t)
+	ctx := types.NewMockRequestContext(ws, newQueryEvent("query_no_page", `{"age":20}`))
+
+	resp, _ := QueryExecutor(ctx)
+	if resp.Code != string(constant.MISSING_PARAM) {
+		t.Fatalf("期望返回缺少参数，实际: %s", resp.Code)
+	}
+}
+
+func TestQueryExecutorUnknownEvent(t *testing.T) {
+	ws := newQueryTestServer(t)
+	ctx := types.NewMockRequestContext(ws, newQueryEvent("not_exist", `{}`))
+
+	resp, _ := QueryExecutor(ctx)
+	if resp.Code != string(constant.ENTITY_NOT_EXIST) {
+		t.Fatalf("期望返回实体不存在，实际: %s", resp.Code)
+	}
+}
+
+type upperFieldParser struct{}
+
+func (upperFieldParser) FieldParserName() string                     { return "upper" }
+func (upperFieldParser) Validate(interface{}) error                  { return nil }
+func (upperFieldParser) ParseParam(s string) interface{}             { return s }
+func (upperFieldParser) CreateColumn(*gorm.DB, string, string) error { return nil }
+func (upperFieldParser) DefaultValue() interface{}                   { return nil }
+func (upperFieldParser) Description() string                         { return "大写展示" }
+func (upperFieldParser) Format(value interface{}) string {
+	return strings.ToUpper(cast.ToString(value))
+}
+
+func TestQueryExecutorFormatsCustomField(t *testing.T) {
+	ws := newQueryTestServer(t)
+	ws.Repo().RegisterCustomFieldParser(upperFieldParser{})
+	ws.SetEntityAttrs(queryEntity, []core.EntityAttribute{
+		{Code: "id", FieldType: "id"},
+		{Code: "name", FieldType: string(core.CUSTOM_FIELD_TYPE), ValueSource: "upper"},
+		{Code: "age", FieldType: "int32"},
+		{Code: "password", FieldType: string(core.CUSTOM_FIELD_TYPE), ValueSource: "upper", IsSecrecy: true},
+	})
+	ctx := types.NewMockRequestContext(ws, newQueryEvent("query", `{"page":1,"page_size":10,"age":20}`))
+
+	resp, _ := QueryExecutor(ctx)
+	if resp.Code != string(constant.SUCCESS) {
+		t.Fatalf("查询失败: %s %s", resp.Code, resp.Message)
+	}
+	names := map[string]bool{}
+	for _, item := range resp.List {
+		row := item.(map[string]interface{})
+		if _, ok := row["password"]; ok {
+			t.Errorf("保密的自定义字段不应返回: %v", row)
+		}
+		names[cast.ToString(row["name"])] = true
+	}
+	if !names["BOB"] || !names["CAROL"] {
+		t.Fatalf("自定义字段应按解析器格式返回, got %v", names)
+	}
+}
+
+func TestBuildQueryNull(t *testing.T) {
+	ws := types.NewMockWorkerServer(nil)
+	t.Cleanup(func() { ws.Stop() })
+	base := ws.Repo().Use("null_query")
+
+	toSQL := func(db *gorm.DB) string {
+		return db.Find(&[]map[string]interface{}{}).Statement.SQL.String()
+	}
+	cases := []struct {
+		fieldType string
+		rangeType string
+		queryType string
+		arg       interface{}
+		want      string
+	}{
+		{"string", "is_null", "and_query", true, "WHERE nickname IS NULL"},
+		{"ref", "is_null", "and_query", "true", "WHERE nickname IS NULL"},
+		{"uid", "not_null", "and_query", true, "WHERE nickname IS NOT NULL"},
+		{"datetime", "not_null", "and_query", 1, "WHERE nickname IS NOT NULL"},
+		{"ref", "is_null", "or_query", true, "WHERE nickname IS NULL"},
+		{"datetime", "not_null", "or_query", true, "WHERE nickname IS NOT NULL"},
+	}
+	for _, c := range cases {
+		setting := &core.EventParam{Name: "nickname", Type: c.queryType, Range: c.rangeType}
+		attrs := []core.EntityAttribute{{Code: "nickname", FieldType: c.fieldType}}
+		db := base.Session(&gorm.Session{DryRun: true}).Table("shop_user")
+		sql := toSQL(buildQuery(db, setting, map[string]interface{}{"nickname": c.arg}, attrs, c.queryType == "and_query"))
+		if !strings.Contains(sql, c.want) {
+			t.Errorf("%s %s %s 生成的SQL错误: %s", c.fieldType, c.queryType, c.rangeType, sql)
+		}
+	}
+
+	// 与其他条件组合时 Or 查询生成 OR 连接
+	db := base.Session(&gorm.Session{DryRun: true}).Table("shop_user").Where("age > ?", 18)
+	setting := &core.EventParam{Name: "nickname", Type: "or_query", Range: "is_null"}
+	sql := toSQL(buildQuery(db, setting, map[string]interface{}{"nickname": true}, []core.EntityAttribute{{Code: "nickname", FieldType: "string"}}, false))
+	if !strings.Contains(sql, "OR nickname IS NULL") {
+		t.Errorf("Or 查询应以 OR 连接: %s", sql)
+	}
+
+	// 参数值为 false 或主键字段时不生成条件
+	for _, c := range []struct {
+		fieldType string
+		arg       interface{}
+	}{{"string", false}, {"string", nil}, {"id", true}} {
+		db := base.Session(&gorm.Session{DryRun: true}).Table("shop_user")
+		setting := &core.EventParam{Name: "nickname", Type: "and_query", Range: "is_null"}
+		sql := toSQL(buildQuery(db, setting, map[string]interface{}{"nickname": c.arg}, []core.EntityAttribute{{Code: "nickname", FieldType: c.fieldType}}, true))
+		if strings.Contains(sql, "NULL") {
+			t.Errorf("%s %v 不应生成 NULL 条件: %s", c.fieldType, c.arg, sql)
+		}
+	}
+}
+
+func TestQueryExecutorIsNull(t *testing.T) {
+	ws := newQueryTestServer(t)
+	ws.SetEntityEvents(queryEntity, []core.EntityEvent{{
+		Code:   "query_unnamed",
+		Params: `[{"name":"page","type":"int"},{"name":"page_size","type":"int"},{"name":"name","type":"and_query","range":"is_null"}]`,
+	}})
+	if err := ws.Repo().Use(queryEntity.Project).Exec("UPDATE shop_user SET name = NULL WHERE id = '2'").Error; err != nil {
+		t.Fatal(err)
+	}
+	ctx := types.NewMockRequestContext(ws, newQueryEvent("query_unnamed", `{"page":1,"page_size":10,"name":true}`))
+	resp, _ := QueryExecutor(ctx)
+	if resp == nil || resp.Code != string(constant.SUCCESS) {
+		t.Fatalf("查询失败: %+v", resp)
+	}
+	if len(resp.List) != 1 || cast.ToString(resp.List[0].(map[string]interface{})["id"]) != "2" {
+		t.Fatalf("期望只返回 name 为 NULL 的记录, got %+v", resp.List)
+	}
+}
+
+func TestQueryExecutorCursor(t *testing.T) {
+	ws := newQueryTestServer(t)
+	ws.SetEntityEvents(queryEntity, []core.EntityEvent{{
+		Code:   "query_cursor",
+		Params: `[{"name":"page","type":"int"},{"name":"page_size","type":"int"},{"name":"cursor","type":"cursor","range":"id"}]`,
+	}})
+
+	// 首页不带游标，按普通分页返回总数和下一页游标
+	ctx := types.NewMockRequestContext(ws, newQueryEvent("query_cursor", `{"page":1,"page_size":2}`))
+	resp, _ := QueryExecutor(ctx)
+	if resp.Code != string(constant.SUCCESS) || resp.Total != 3 || len(resp.List) != 2 {
+		t.Fatalf("首页结果错误: %+v", resp)
+	}
+	if resp.NextCursor == "" {
+		t.Fatal("首页应返回下一页游标")
+	}
+
+	// 带游标时从上一页最后一条之后继续，且不统计总数
+	ctx = types.NewMockRequestContext(ws, newQueryEvent("query_cursor", `{"page":1,"page_size":2,"cursor":"`+resp.NextCursor+`"}`))
+	resp, _ = QueryExecutor(ctx)
+	if resp.Code != string(constant.SUCCESS) || len(resp.List) != 1 || resp.Total != 0 {
+		t.Fatalf("游标分页结果错误: %+v", resp)
+	}
+	if id := cast.ToString(resp.List[0].(map[string]interface{})["id"]); id != "3" {
+		t.Fatalf("期望返回 id=3, got %s", id)
+	}
+	if resp.NextCursor != "" {
+		t.Fatalf("最后一页不应返回游标: %s", resp.NextCursor)
+	}
+
+	ctx = types.NewMockRequestContext(ws, newQueryEvent("query_cursor", `{"page":1,"page_size":2,"cursor":"%%%"}`))
+	resp, _ = QueryExecutor(ctx)
+	if resp.Code != string(constant.INVALID_PARAM) {
+		t.Fatalf("无效游标应返回参数错误: %+v", resp)
+	}
+}
+
+func TestCursorCodec(t *testing.T) {
+	for _, v := range []string{"1", "1700000000000", "a/b+c="} {
+		got, err := decodeCursor(encodeCursor(v))
+		if err != nil || got != v {
+			t.Errorf("游标编解码错误: %s -> %s %v", v, got, err)
+		}
+	}
+	setting := &core.EventParam{Name: "cursor", Type: "cursor"}
+	rows := []map[string]interface{}{{"id": "1"}, {"id": "2"}}
+	if c := nextCursor(rows, setting, 3); c != "" {
+		t.Errorf("不足一页不应返回游标: %s", c)
+	}
+	if c := nextCursor(rows, setting, 2); c != encodeCursor("2") {
+		t.Errorf("游标应指向最后一条: %s", c)
+	}
+}
+
+func TestBuildQueryRegex(t *testing.T) {
+	attrs := []core.EntityAttribute{{Code: "zip", FieldType: "string"}}
+	params := map[string]interface{}{"zip": `^\d{6}$`}
+	dialects := map[string]gorm.Dialector{
+		"zip REGEXP": mysql.New(mysql.Config{DSN: "u:p@tcp(127.0.0.1:1)/db", SkipInitializeWithVersion: true}),
+		"zip ~":      postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1"}),
+	}
+	for want, dialector := range dialects {
+		db, err := gorm.Open(dialector, &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		setting := &core.EventParam{Name: "zip", Type: "and_query", Range: "regex"}
+		sql := buildQuery(db.Table("addr"), setting, params, attrs, true).Find(&[]map[string]interface{}{}).Statement.SQL.String()
+		if !strings.Contains(sql, want) {
+			t.Errorf("%s 生成的SQL错误: %s", dialector.Name(), sql)
+		}
+	}
+
+	// 表达式不合法或字段类型不支持时不生成条件
+	ws := types.NewMockWorkerServer(nil)
+	t.Cleanup(func() { ws.Stop() })
+	base := ws.Repo().Use("regex_query")
+	setting := &core.EventParam{Name: "zip", Type: "and_query", Range: "regex"}
+	for _, c := range []struct {
+		fieldType string
+		arg       string
+	}{{"string", "("}, {"int32", "1"}} {
+		db := base.Session(&gorm.Session{DryRun: true}).Table("addr")
+		sql := buildQuery(db, setting, map[string]interface{}{"zip": c.arg}, []core.EntityAttribute{{Code: "zip", FieldType: c.fieldType}}, true).
+			Find(&[]map[string]interface{}{}).Statement.SQL.String()
+		if strings.Contains(sql, "REGEXP") {
+			t.Errorf("%s %s 不应生成正则条件: %s", c.fieldType, c.arg, sql)
+		}
+	}
+}
+
+func TestQueryExecutorRegex(t *testing.T) {
+	ws := newQueryTestServer(t)
+	ws.SetEntityEvents(queryEntity, []core.EntityEvent{{
+		Code:   "query_regex",
+		Params: `[{"name":"page","type":"int"},{"name":"page_size","type":"int"},{"name":"name","type":"and_query","range":"regex"}]`,
+	}})
+	ctx := types.NewMockRequestContext(ws, newQueryEvent("query_regex", `{"page":1,"page_size":10,"name":"^(bob|carol)$"}`))
+	resp, _ := QueryExecutor(ctx)
+	if resp.Code != string(constant.SUCCESS) || resp.Total != 2 {
+		t.Fatalf("正则查询结果错误: %+v", resp)
+	}
+}
+
+func TestOrderQuery(t *testing.T) {
+	ws := types.NewMockWorkerServer(nil)
+	t.Cleanup(func() { ws.Stop() })
+	base := ws.Repo().Use("order_query")
+
+	cases := []struct {
+		params []core.EventParam
+		want   string
+	}{
+		{[]core.EventParam{{Name: "status", Type: "order_by", Range: "asc"}, {Name: "created_at", Type: "order_by", Range: "desc"}},
+			"ORDER BY status asc,created_at desc"},
+		{[]core.EventParam{{Name: "age", Type: "order_by"}}, "ORDER BY age asc"},
+		{[]core.EventParam{{Name: "age", Type: "order_by", Range: "DESC  NULLS last"}}, "ORDER BY age desc nulls last"},
+		// 不支持的排序方式被忽略
+		{[]core.EventParam{{Name: "age", Type: "order_by", Range: "desc; drop table x"}, {Name: "name", Type: "order_by", Range: "asc nulls first"}},
+			"ORDER BY name asc nulls first"},
+	}
+	for _, c := range cases {
+		db := base.Session(&gorm.Session{DryRun: true}).Table("shop_user")
+		sql := orderQuery(db, c.params).Find(&[]map[string]interface{}{}).Statement.SQL.String()
+		if !strings.HasSuffix(sql, c.want) {
+			t.Errorf("生成的SQL错误: %s, 期望: %s", sql, c.want)
+		}
+	}
+}
+
+func TestQueryExecutorMultiOrder(t *testing.T) {
+	ws := newQueryTestServer(t)
+	db := ws.Repo().Use(queryEntity.Project)
+	if err := db.Exec("INSERT INTO shop_user (id, name, age, deleted_at) VALUES ('5', 'erin', 25, 0)").Error; err != nil {
+		t.Fatal(err)
+	}
+	ws.SetEntityEvents(queryEntity, []core.EntityEvent{{
+		Code:   "query_sorted",
+		Params: `[{"name":"page","type":"int"},{"name":"page_size","type":"int"},{"name":"age","type":"order_by","range":"desc"},{"name":"name","type":"order_by","range":"asc"}]`,
+	}})
+	ctx := types.NewMockRequestContext(ws, newQueryEvent("query_sorted", `{"page":1,"page_size":10}`))
+	resp, _ := QueryExecutor(ctx)
+	if resp.Code != string(constant.SUCCESS) || len(resp.List) != 4 {
+		t.Fatalf("查询失败: %+v", resp)
+	}
+	names := make([]string, 0, len(resp.List))
+	for _, item := range resp.List {
+		names = append(names, cast.ToString(item.(map[string]interface{})["name"]))
+	}
+	if got := strings.Join(names, ","); got != "carol,bob,erin,alice" {
+		t.Fatalf("排序错误: %s", got)
+	}
+}
+
+func TestQueryExecutorJoin(t *testing.T) {
+	ws := newQueryTestServer(t)
+	orderEntity := types.PathToEntity{Project: "demo", Version: "v1", Context: "shop", Entity: "order"}
+	ws.SetEntityAttrs(orderEntity, []core.EntityAttribute{
+		{Code: "id", FieldType: "id"},
+		{Code: "user_id", FieldType: "string"},
+		{Code: "amount", FieldType: "int32"},
+		{Code: "card_no", FieldType: "string", IsSecrecy: true},
+		{Code: "deleted_at", FieldType: "datetime"},
+	})
+	ws.SetEntityEvents(queryEntity, []core.EntityEvent{{
+		Code: "query_join",
+		Params: `[{"name":"page","type":"int"},{"name":"page_size","type":"int"},{"name":"age","type":"and_query","range":"gt"},` +
+			`{"name":"name","type":"order_by","range":"asc"},` +
+			`{"name":"orders","type":"join_query","rangeValue":"{\"join_entity\":\"order\",\"join_on_local\":\"id\",\"join_on_foreign\":\"user_id\"}"}]`,
+	}})
+	db := ws.Repo().Use(queryEntity.Project)
+	if err := db.Exec("CREATE TABLE shop_order (id TEXT PRIMARY KEY, user_id TEXT, amount INTEGER, card_no TEXT, deleted_at INTEGER DEFAULT 0)").Error; err != nil {
+		t.Fatalf("建表失败: %v", err)
+	}
+	orders := []map[string]interface{}{
+		{"id": "o1", "user_id": "2", "amount": 100, "card_no": "c1", "deleted_at": 0},
+		{"id": "o2", "user_id": "2", "amount": 200, "card_no": "c2", "deleted_at": 0},
+		{"id": "o3", "user_id": "3", "amount": 300, "card_no": "c3", "deleted_at": 1700000000},
+	}
+	if err := db.Table("shop_order").Create(orders).Error; err != nil {
+		t.Fatalf("写入测试数据失败: %v", err)
+	}
+
+	resp, _ := QueryExecutor(types.NewMockRequestContext(ws, newQueryEvent("query_join", `{"page":1,"page_size":10,"age":20}`)))
+	if resp.Code != string(constant.SUCCESS) {
+		t.Fatalf("关联查询失败: %s %s", resp.Code, resp.Message)
+	}
+	// bob 关联两个订单，carol 的订单已删除，只保留本身一行
+	if resp.Total != 3 || len(resp.List) != 3 {
+		t.Fatalf("期望返回3条数据，实际 total=%d list=%d", resp.Total, len(resp.List))
+	}
+	amounts := []int{}
+	for _, item := range resp.List {
+		row := item.(map[string]interface{})
+		if _, ok := row["order__card_no"]; ok {
+			t.Errorf("关联实体的保密字段不应返回: %v", row)
+		}
+		if _, ok := row["password"]; ok {
+			t.Errorf("保密字段不应返回: %v", row)
+		}
+		amounts = append(amounts, cast.ToInt(row["order__amount"]))
+	}
+	if amounts[0]+amounts[1] != 300 || amounts[2] != 0 {
+		t.Errorf("关联数据错误: %v", amounts)
+	}
+}
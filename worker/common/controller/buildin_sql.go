@@ -23,6 +23,7 @@ import (
 	"github.com/garrickvan/event-matrix/database"
 	"github.com/garrickvan/event-matrix/utils/jsonx"
 	"github.com/garrickvan/event-matrix/worker/types"
+	"gorm.io/gorm"
 )
 
 func SqlExecutor(ctx types.WorkerContext) (*jsonx.JsonResponse, int) {
@@ -32,6 +33,8 @@ func SqlExecutor(ctx types.WorkerContext) (*jsonx.JsonResponse, int) {
 	if errJson != nil {
 		return errJson, http.StatusOK
 	}
+	dryRun := isDryRun(paramSettings, params)
+	removeDryRunParams(paramSettings, params)
 	sqlSet, hasSql := core.FindParamFromArray("sql", paramSettings)
 	if !hasSql || strings.TrimSpace(sqlSet.RangeValue) == "" {
 		errRespone := jsonx.DefaultJson(constant.MISSING_PARAM)
@@ -52,15 +55,18 @@ func SqlExecutor(ctx types.WorkerContext) (*jsonx.JsonResponse, int) {
 	if event == nil {
 		return jsonx.DefaultJson(constant.EVENT_NOT_EXIST), http.StatusOK
 	}
+	if isSqlPreview(ctx) {
+		return previewSql(sqlStatement, params, event, ctx), http.StatusOK
+	}
 	switch sqlType {
 	case "normal":
-		return execSql(sqlStatement, params, event, ctx, false), http.StatusOK
+		return execSql(sqlStatement, params, event, ctx, false, dryRun), http.StatusOK
 	case "transaction":
-		return execSql(sqlStatement, params, event, ctx, true), http.StatusOK
+		return execSql(sqlStatement, params, event, ctx, true, dryRun), http.StatusOK
 	case "query":
-		return execQuerySql(sqlStatement, params, event, ctx, false), http.StatusOK
+		return execQuerySql(sqlStatement, params, event, ctx, false, dryRun), http.StatusOK
 	case "transaction-query":
-		return execQuerySql(sqlStatement, params, event, ctx, true), http.StatusOK
+		return execQuerySql(sqlStatement, params, event, ctx, true, dryRun), http.StatusOK
 	}
 	// 未知的SQL类型
 	return jsonx.DefaultJsonWithMsg(constant.FAIL_TO_PROCESS, "未知的SQL类型"), http.StatusOK
@@ -72,12 +78,20 @@ func execSql(
 	event *core.Event,
 	ctx types.WorkerContext,
 	isTransaction bool,
+	dryRun bool,
 ) *jsonx.JsonResponse {
 	var count int64
 	var err error
 	db := ctx.Server().Repo().Use(event.Project).Table(event.GetTabelName())
 
-	if isTransaction {
+	if dryRun {
+		// 试运行时总是回滚
+		err = database.DryRunTransaction(db, func(tx *gorm.DB) error {
+			var txErr error
+			count, txErr = database.RawSqlExec(tx, sqlStatement, params)
+			return txErr
+		})
+	} else if isTransaction {
 		count, err = database.TransactionRawSqlExec(db, sqlStatement, params)
 	} else {
 		count, err = database.RawSqlExec(db, sqlStatement, params)
@@ -88,6 +102,7 @@ func execSql(
 	} else {
 		resp := jsonx.DefaultJson(constant.SUCCESS)
 		resp.Total = count
+		resp.DryRun = dryRun
 		return resp
 	}
 }
@@ -98,12 +113,20 @@ func execQuerySql(
 	event *core.Event,
 	ctx types.WorkerContext,
 	isTransaction bool,
+	dryRun bool,
 ) *jsonx.JsonResponse {
 	var err error
 	var rows []map[string]interface{}
 	db := ctx.Server().Repo().Use(event.Project).Table(event.GetTabelName())
 
-	if isTransaction {
+	if dryRun {
+		// 试运行时总是回滚
+		err = database.DryRunTransaction(db, func(tx *gorm.DB) error {
+			var txErr error
+			rows, txErr = database.RawQuerySqlExec(tx, sqlStatement, params)
+			return txErr
+		})
+	} else if isTransaction {
 		rows, err = database.TransactionRawQuerySqlExec(db, sqlStatement, params)
 	} else {
 		rows, err = database.RawQuerySqlExec(db, sqlStatement, params)
@@ -113,9 +136,24 @@ func execQuerySql(
 		return jsonx.DefaultJsonWithMsg(constant.FAIL_TO_PROCESS, err.Error())
 	}
 	resp := jsonx.DefaultJsonWithMsg(constant.SUCCESS, "查询成功")
+	resp.DryRun = dryRun
 	for _, row := range rows {
 		resp.List = append(resp.List, row)
 	}
 	resp.Size = len(resp.List)
 	return resp
 }
+
+// previewSql 生成绑定参数后的SQL语句并返回，不访问数据
+func previewSql(
+	sqlStatement string,
+	params map[string]interface{},
+	event *core.Event,
+	ctx types.WorkerContext,
+) *jsonx.JsonResponse {
+	db := ctx.Server().Repo().Use(event.Project).Table(event.GetTabelName())
+	resp := jsonx.DefaultJsonWithMsg(constant.SUCCESS, "SQL预览，未执行")
+	resp.DryRun = true
+	resp.Sql = database.RawSqlToSQL(db, sqlStatement, params)
+	return resp
+}
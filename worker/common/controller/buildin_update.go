@@ -15,7 +15,6 @@
 package controller
 
 import (
-	"fmt"
 	"net/http"
 
 	"github.com/garrickvan/event-matrix/constant"
@@ -24,6 +23,7 @@ import (
 	"github.com/garrickvan/event-matrix/utils/jsonx"
 	"github.com/garrickvan/event-matrix/worker/types"
 	"github.com/spf13/cast"
+	"gorm.io/gorm"
 )
 
 func UpdateExecutor(ctx types.WorkerContext) (*jsonx.JsonResponse, int) {
@@ -35,6 +35,7 @@ func UpdateExecutor(ctx types.WorkerContext) (*jsonx.JsonResponse, int) {
 	if errJson != nil {
 		return errJson, http.StatusOK
 	}
+	dryRun := isDryRun(paramSettings, params)
 	// 检查是否定义了ID参数
 	_, hasID := core.FindParamFromArray("id", paramSettings)
 	if !hasID {
@@ -48,6 +49,38 @@ func UpdateExecutor(ctx types.WorkerContext) (*jsonx.JsonResponse, int) {
 		return errRespone, http.StatusOK
 	}
 	// 构建更新数据
+	db := ctx.Server().Repo().Use(event.Project)
+	updateData, errJson := buildUpdateData(db, event, entityAttrs, params, cast.ToString(id))
+	if errJson != nil {
+		return errJson, http.StatusOK
+	}
+	// 更新数据到数据库
+	result := execWrite(db, dryRun, func(tx *gorm.DB) *gorm.DB {
+		return tx.Table(event.GetTabelName()).Where("id = ?", id).Updates(updateData)
+	})
+	if result.Error != nil {
+		return jsonx.DefaultJson(constant.FAIL_TO_UPDATE), http.StatusOK
+	}
+	if result.RowsAffected == 0 {
+		errJson := jsonx.DefaultJsonWithMsg(constant.FAIL_TO_UPDATE, "更新失败，未找到对应记录")
+		return errJson, http.StatusOK
+	}
+	// 过滤保密字段
+	for _, attr := range entityAttrs {
+		if attr.IsSecrecy {
+			delete(updateData, attr.Code)
+		}
+	}
+	// 返回更新结果
+	updateData["id"] = id
+	resp := jsonx.DefaultJson(constant.SUCCESS)
+	resp.DryRun = dryRun
+	jsonx.SetJsonList[map[string]interface{}](resp, []map[string]interface{}{updateData}, 1, 1)
+	return resp, http.StatusOK
+}
+
+// buildUpdateData 按实体属性构建待更新的数据，跳过 id 与未定义的属性，检查唯一字段冲突并刷新更新时间
+func buildUpdateData(db *gorm.DB, event *core.Event, entityAttrs []core.EntityAttribute, params map[string]interface{}, id string) (map[string]interface{}, *jsonx.JsonResponse) {
 	updateData := map[string]interface{}{}
 	for key, val := range params {
 		if key == "id" {
@@ -62,53 +95,37 @@ func UpdateExecutor(ctx types.WorkerContext) (*jsonx.JsonResponse, int) {
 			updateData[key] = val
 		} else {
 			// 对基础的数据类型进行值修正
-			updateData[key] = attr.FixValue(val)
+			fixed, err := attr.FixValue(val)
+			if err != nil {
+				errRespone := jsonx.DefaultJson(constant.INVALID_PARAM)
+				errRespone.Message = err.Error()
+				return nil, errRespone
+			}
+			updateData[key] = fixed
 		}
 	}
 	// 检查唯一字段是否冲突
 	for _, attr := range entityAttrs {
-		if attr.Unique && attr.Code != "id" {
-			val := updateData[attr.Code]
-			if alreadyExistWithID(event, ctx, attr, val, cast.ToString(id)) {
-				errRespone := jsonx.DefaultJson(constant.ALREADY_EXIST)
-				errRespone.Message = fmt.Sprintf("属性[%s]已存在", attr.Name)
-				return errRespone, http.StatusOK
+		// 未更新的唯一字段无需查重
+		if val, ok := updateData[attr.Code]; ok && val != nil && attr.Unique && attr.Code != "id" {
+			if alreadyExistWithID(db, event, attr, val, id) {
+				return nil, duplicateRecordJson(attr)
 			}
 		}
 		if attr.Code == "updated_at" && attr.FieldType == string(core.DATETIME_FIELD_TYPE) {
 			updateData[attr.Code] = utils.GetNowMilli()
 		}
 	}
-	// 更新数据到数据库
-	result := ctx.Server().Repo().Use(event.Project).Table(event.GetTabelName()).Where("id = ?", id).Updates(updateData)
-	if result.Error != nil {
-		return jsonx.DefaultJson(constant.FAIL_TO_UPDATE), http.StatusOK
-	}
-	if result.RowsAffected == 0 {
-		errJson := jsonx.DefaultJsonWithMsg(constant.FAIL_TO_UPDATE, "更新失败，未找到对应记录")
-		return errJson, http.StatusOK
-	}
-	// 过滤保密字段
-	for _, attr := range entityAttrs {
-		if attr.IsSecrecy {
-			delete(updateData, attr.Code)
-		}
-	}
-	// 返回更新结果
-	updateData["id"] = id
-	resp := jsonx.DefaultJson(constant.SUCCESS)
-	jsonx.SetJsonList[map[string]interface{}](resp, []map[string]interface{}{updateData}, 1, 1)
-	return resp, http.StatusOK
+	return updateData, nil
 }
 
-func alreadyExistWithID(event *core.Event, ctx types.WorkerContext, attr core.EntityAttribute, val interface{}, id string) bool {
+func alreadyExistWithID(db *gorm.DB, event *core.Event, attr core.EntityAttribute, val interface{}, id string) bool {
 	count := int64(0)
 	queryMap := map[string]interface{}{
 		attr.Code: val,
 	}
 	// 检查是否存在相同的属性值，但过滤掉相同的ID
-	ctx.Server().Repo().Use(event.Project).
-		Table(event.GetTabelName()).
+	db.Table(event.GetTabelName()).
 		Where(queryMap).
 		Where("id != ?", id). // 可以更新相同ID的记录
 		Count(&count)
@@ -0,0 +1,114 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/database"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/types"
+	"github.com/spf13/cast"
+	"gorm.io/gorm"
+)
+
+// errUpsertAborted 构建写入数据失败时中断事务，具体的错误响应由调用方返回
+var errUpsertAborted = errors.New("upsert aborted")
+
+// UpsertExecutor 按 id 更新已存在的记录，未传入 id 或记录不存在时新增记录，
+// 查询与写入在同一事务中完成，避免并发请求重复插入
+func UpsertExecutor(ctx types.WorkerContext) (*jsonx.JsonResponse, int) {
+	event := ctx.Event()
+	if event == nil {
+		return jsonx.DefaultJson(constant.EVENT_NOT_EXIST), http.StatusOK
+	}
+	entityAttrs, paramSettings, params, errJson := ctx.ValidatedParams()
+	if errJson != nil {
+		return errJson, http.StatusOK
+	}
+	dryRun := isDryRun(paramSettings, params)
+	id := cast.ToString(params["id"])
+
+	var (
+		data    map[string]interface{}
+		updated bool
+		failed  *jsonx.JsonResponse
+	)
+	upsert := func(tx *gorm.DB) error {
+		if id != "" {
+			count := int64(0)
+			if err := tx.Table(event.GetTabelName()).Where("id = ?", id).Count(&count).Error; err != nil {
+				return err
+			}
+			updated = count > 0
+		}
+		if updated {
+			// 只更新传入了非空值的字段
+			changes := map[string]interface{}{}
+			for key, val := range params {
+				if val != nil {
+					changes[key] = val
+				}
+			}
+			data, failed = buildUpdateData(tx, event, entityAttrs, changes, id)
+			if failed != nil {
+				return errUpsertAborted
+			}
+			return tx.Table(event.GetTabelName()).Where("id = ?", id).Updates(data).Error
+		}
+		data, failed = buildCreateData(ctx, entityAttrs, params)
+		if failed != nil {
+			return errUpsertAborted
+		}
+		if failed = checkUniqueAttrs(tx, event, entityAttrs, data); failed != nil {
+			return errUpsertAborted
+		}
+		return tx.Table(event.GetTabelName()).Create(data).Error
+	}
+
+	db := ctx.Server().Repo().Use(event.Project)
+	var err error
+	if dryRun {
+		err = database.DryRunTransaction(db, upsert)
+	} else {
+		err = db.Transaction(upsert)
+	}
+	if failed != nil {
+		return failed, http.StatusOK
+	}
+	if err != nil {
+		logx.Error(event.GetFullEventLabel() + "写入失败: " + err.Error())
+		if updated {
+			return jsonx.DefaultJson(constant.FAIL_TO_UPDATE), http.StatusOK
+		}
+		return jsonx.DefaultJson(constant.FAIL_TO_CREATE), http.StatusOK
+	}
+	// 过滤保密字段
+	for _, attr := range entityAttrs {
+		if attr.IsSecrecy {
+			delete(data, attr.Code)
+		}
+	}
+	if updated {
+		data["id"] = id
+	}
+	resp := jsonx.DefaultJson(constant.SUCCESS)
+	resp.DryRun = dryRun
+	jsonx.SetJsonList[map[string]interface{}](resp, []map[string]interface{}{data}, 1, 1)
+	return resp, http.StatusOK
+}
@@ -0,0 +1,54 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/garrickvan/event-matrix/constant"
+)
+
+func TestUpsertExecutor(t *testing.T) {
+	ws := newDryRunTestServer(t)
+
+	// 记录已存在时更新
+	resp, _ := UpsertExecutor(dryRunCtx(ws, "upsert", `{"id":"1","name":"banana"}`))
+	if resp.Code != string(constant.SUCCESS) {
+		t.Fatalf("更新失败: %s %s", resp.Code, resp.Message)
+	}
+	if count, name, _ := goodsRow(t, ws); count != 1 || name != "banana" {
+		t.Fatalf("期望更新已有记录, count=%d name=%s", count, name)
+	}
+
+	// 指定的 id 不存在时按该 id 新增
+	resp, _ = UpsertExecutor(dryRunCtx(ws, "upsert", `{"id":"2","name":"cherry"}`))
+	if resp.Code != string(constant.SUCCESS) {
+		t.Fatalf("新增失败: %s %s", resp.Code, resp.Message)
+	}
+	row := map[string]interface{}{}
+	ws.Repo().Use(dryRunEntity.Project).Table("shop_goods").Where("id = ?", "2").Take(&row)
+	if row["name"] != "cherry" {
+		t.Fatalf("期望新增id为2的记录, 实际: %v", row)
+	}
+
+	// 未传入 id 时生成新记录
+	resp, _ = UpsertExecutor(dryRunCtx(ws, "upsert", `{"name":"durian"}`))
+	if resp.Code != string(constant.SUCCESS) {
+		t.Fatalf("新增失败: %s %s", resp.Code, resp.Message)
+	}
+	if count, _, _ := goodsRow(t, ws); count != 3 {
+		t.Fatalf("期望共3条记录, 实际: %d", count)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/database"
+	"github.com/garrickvan/event-matrix/worker/types"
+	"github.com/spf13/cast"
+	"gorm.io/gorm"
+)
+
+// isDryRun 事件定义了 dry_run 类型的参数且请求中该参数为true时，只校验和执行但不落库
+func isDryRun(paramSettings []core.EventParam, params map[string]interface{}) bool {
+	for _, setting := range paramSettings {
+		if setting.Type == string(core.DRY_RUN_FIELD_TYPE) {
+			return cast.ToBool(params[setting.Name])
+		}
+	}
+	return false
+}
+
+// sqlPreviewer 支持SQL预览的请求上下文，由公共服务根据请求头或查询参数设置
+type sqlPreviewer interface {
+	SqlPreview() bool
+}
+
+// isSqlPreview 判断请求是否只需预览SQL而不执行
+func isSqlPreview(ctx types.WorkerContext) bool {
+	p, ok := ctx.(sqlPreviewer)
+	return ok && p.SqlPreview()
+}
+
+// removeDryRunParams 移除试运行参数，避免作为SQL参数传入
+func removeDryRunParams(paramSettings []core.EventParam, params map[string]interface{}) {
+	for _, setting := range paramSettings {
+		if setting.Type == string(core.DRY_RUN_FIELD_TYPE) {
+			delete(params, setting.Name)
+		}
+	}
+}
+
+// execWrite 执行写操作，试运行时在总是回滚的事务中执行，返回结果与正常执行一致
+func execWrite(db *gorm.DB, dryRun bool, fc func(tx *gorm.DB) *gorm.DB) *gorm.DB {
+	if !dryRun {
+		return fc(db)
+	}
+	var result *gorm.DB
+	err := database.DryRunTransaction(db, func(tx *gorm.DB) error {
+		result = fc(tx)
+		return result.Error
+	})
+	// 开启事务失败时操作未执行
+	if result == nil {
+		return &gorm.DB{Error: err}
+	}
+	return result
+}
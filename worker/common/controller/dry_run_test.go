@@ -0,0 +1,153 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+var dryRunEntity = types.PathToEntity{Project: "demo", Version: "v1", Context: "shop", Entity: "goods"}
+
+func newDryRunTestServer(t *testing.T) *types.MockWorkerServer {
+	t.Helper()
+	ws := types.NewMockWorkerServer(nil)
+	t.Cleanup(func() { ws.Stop() })
+
+	ws.SetEntityAttrs(dryRunEntity, []core.EntityAttribute{
+		{Code: "id", FieldType: "id"},
+		{Code: "name", FieldType: "string"},
+		{Code: "deleted_at", FieldType: "datetime"},
+	})
+	ws.SetEntityEvents(dryRunEntity, []core.EntityEvent{
+		{Code: "create", Params: `[{"name":"dry_run","type":"dry_run"}]`},
+		{Code: "update", Params: `[{"name":"id","type":"id"},{"name":"dry_run","type":"dry_run"}]`},
+		{Code: "delete", Params: `[{"name":"ids","type":"string"},{"name":"dry_run","type":"dry_run"}]`},
+		{Code: "upsert", Params: `[{"name":"id","type":"id"},{"name":"dry_run","type":"dry_run"}]`},
+		{Code: "sql", Params: `[{"name":"name","type":"string"},{"name":"dry_run","type":"dry_run"},` +
+			`{"name":"sql","type":"string","range":"normal","rangeValue":"UPDATE shop_goods SET name = @name"}]`},
+	})
+	db := ws.Repo().Use(dryRunEntity.Project)
+	err := db.Exec("CREATE TABLE shop_goods (id TEXT PRIMARY KEY, name TEXT, deleted_at INTEGER DEFAULT 0)").Error
+	if err != nil {
+		t.Fatalf("建表失败: %v", err)
+	}
+	if err := db.Exec("INSERT INTO shop_goods (id, name, deleted_at) VALUES ('1', 'apple', 0)").Error; err != nil {
+		t.Fatalf("写入测试数据失败: %v", err)
+	}
+	return ws
+}
+
+func dryRunCtx(ws *types.MockWorkerServer, event, params string) *types.MockRequestContext {
+	return types.NewMockRequestContext(ws, &core.Event{
+		Project: dryRunEntity.Project,
+		Version: dryRunEntity.Version,
+		Context: dryRunEntity.Context,
+		Entity:  dryRunEntity.Entity,
+		Event:   event,
+		Params:  params,
+	})
+}
+
+func goodsRow(t *testing.T, ws *types.MockWorkerServer) (count int64, name string, deletedAt int64) {
+	t.Helper()
+	db := ws.Repo().Use(dryRunEntity.Project).Table("shop_goods")
+	if err := db.Count(&count).Error; err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	row := map[string]interface{}{}
+	ws.Repo().Use(dryRunEntity.Project).Table("shop_goods").Where("id = ?", "1").Take(&row)
+	return count, row["name"].(string), row["deleted_at"].(int64)
+}
+
+func TestExecutorsDryRun(t *testing.T) {
+	ws := newDryRunTestServer(t)
+	executors := []struct {
+		name   string
+		exec   types.WorkerExecutor
+		event  string
+		params string
+	}{
+		{"create", CreateExecutor, "create", `{"name":"banana","dry_run":true}`},
+		{"update", UpdateExecutor, "update", `{"id":"1","name":"banana","dry_run":true}`},
+		{"upsert", UpsertExecutor, "upsert", `{"id":"1","name":"banana","dry_run":true}`},
+		{"delete", DeleteExecutor, "delete", `{"ids":"1","dry_run":true}`},
+		{"sql", SqlExecutor, "sql", `{"name":"banana","dry_run":true}`},
+	}
+	for _, e := range executors {
+		resp, _ := e.exec(dryRunCtx(ws, e.event, e.params))
+		if resp.Code != string(constant.SUCCESS) {
+			t.Fatalf("%s: 试运行失败: %s %s", e.name, resp.Code, resp.Message)
+		}
+		if !resp.DryRun {
+			t.Errorf("%s: 响应应标记为试运行", e.name)
+		}
+		data, _ := jsonx.MarshalToStr(resp)
+		if !strings.Contains(data, `"dry_run":true`) {
+			t.Errorf("%s: 响应JSON缺少dry_run: %s", e.name, data)
+		}
+		count, name, deletedAt := goodsRow(t, ws)
+		if count != 1 || name != "apple" || deletedAt != 0 {
+			t.Fatalf("%s: 试运行不应落库, count=%d name=%s deleted_at=%d", e.name, count, name, deletedAt)
+		}
+	}
+
+	// 非试运行正常落库，响应中不包含dry_run
+	resp, _ := UpdateExecutor(dryRunCtx(ws, "update", `{"id":"1","name":"banana","dry_run":false}`))
+	if resp.Code != string(constant.SUCCESS) || resp.DryRun {
+		t.Fatalf("正常更新失败: %+v", resp)
+	}
+	if data, _ := jsonx.MarshalToStr(resp); strings.Contains(data, "dry_run") {
+		t.Errorf("正常执行的响应不应包含dry_run: %s", data)
+	}
+	if _, name, _ := goodsRow(t, ws); name != "banana" {
+		t.Fatalf("正常更新未落库, name=%s", name)
+	}
+}
+
+func TestDryRunFailureStillReported(t *testing.T) {
+	ws := newDryRunTestServer(t)
+	// 试运行时数据库错误照常返回
+	resp, _ := UpdateExecutor(dryRunCtx(ws, "update", `{"id":"not-exist","name":"banana","dry_run":true}`))
+	if resp.Code != string(constant.FAIL_TO_UPDATE) {
+		t.Fatalf("期望更新失败，实际: %s", resp.Code)
+	}
+}
+
+// sqlPreviewCtx 模拟公共服务设置了SQL预览标记的请求上下文
+type sqlPreviewCtx struct {
+	*types.MockRequestContext
+}
+
+func (sqlPreviewCtx) SqlPreview() bool { return true }
+
+func TestSqlExecutorPreview(t *testing.T) {
+	ws := newDryRunTestServer(t)
+	resp, _ := SqlExecutor(sqlPreviewCtx{dryRunCtx(ws, "sql", `{"name":"banana"}`)})
+	if resp.Code != string(constant.SUCCESS) || !resp.DryRun {
+		t.Fatalf("SQL预览失败: %+v", resp)
+	}
+	if !strings.Contains(resp.Sql, "UPDATE shop_goods SET name =") || !strings.Contains(resp.Sql, "banana") {
+		t.Fatalf("预览SQL不正确: %s", resp.Sql)
+	}
+	if _, name, _ := goodsRow(t, ws); name != "apple" {
+		t.Fatalf("SQL预览不应执行, name=%s", name)
+	}
+}
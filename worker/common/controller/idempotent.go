@@ -0,0 +1,109 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/worker/types"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// IDEMPOTENCY_KEY_PARAM 幂等事件必须携带的请求键参数名
+	IDEMPOTENCY_KEY_PARAM = "idempotency_key"
+	// IDEMPOTENCY_CACHE_PREFIX 幂等响应在默认缓存中的键前缀
+	IDEMPOTENCY_CACHE_PREFIX = "idempotency|"
+)
+
+// idempotentCalls 合并同一个请求键的并发执行，避免重复写入
+var idempotentCalls singleflight.Group
+
+// idempotentExecute 对开启了幂等性的事件按请求键执行一次，
+// 相同请求键在事件有效期内直接返回首次执行成功的响应，不再访问数据库
+func idempotentExecute(
+	ctx types.WorkerContext,
+	exec func(ctx types.WorkerContext) (*jsonx.JsonResponse, int),
+) (*jsonx.JsonResponse, int) {
+	entityEvent := ctx.EntityEvent()
+	if entityEvent == nil || !entityEvent.Idempotent {
+		return exec(ctx)
+	}
+	_, paramSettings, params, errJson := ctx.ValidatedParams()
+	if errJson != nil {
+		return errJson, http.StatusOK
+	}
+	// 试运行不落库，不参与幂等控制
+	if isDryRun(paramSettings, params) {
+		return exec(ctx)
+	}
+	key, ok := params[IDEMPOTENCY_KEY_PARAM].(string)
+	if !ok || key == "" {
+		errRespone := jsonx.DefaultJson(constant.MISSING_PARAM)
+		errRespone.Message = "幂等事件缺少字符串参数[" + IDEMPOTENCY_KEY_PARAM + "]"
+		return errRespone, http.StatusOK
+	}
+	cache := ctx.Server().Cache()
+	cacheKey := idempotencyCacheKey(ctx.Event(), ctx.UserId(), key)
+	if cached, ok := cache.Impl().Get(cacheKey); ok && cached != nil {
+		return copyResponse(cached.(*jsonx.JsonResponse)), http.StatusOK
+	}
+	type result struct {
+		resp   *jsonx.JsonResponse
+		status int
+	}
+	v, _, _ := idempotentCalls.Do(cacheKey, func() (interface{}, error) {
+		// 等待期间其他请求可能已执行完成
+		if cached, ok := cache.Impl().Get(cacheKey); ok && cached != nil {
+			return result{cached.(*jsonx.JsonResponse), http.StatusOK}, nil
+		}
+		resp, status := exec(ctx)
+		// 只缓存成功的响应，失败时允许客户端使用相同的请求键重试
+		if resp != nil && resp.Code == string(constant.SUCCESS) {
+			impl := cache.Impl()
+			if entityEvent.Timeout > 0 {
+				impl.PutWithTTL(cacheKey, copyResponse(resp), time.Duration(entityEvent.Timeout)*time.Second)
+			} else {
+				impl.Put(cacheKey, copyResponse(resp))
+			}
+			// 缓存写入是异步的，等待写入完成后再放行后续请求
+			if instance := impl.GetCacheInstance(); instance != nil {
+				instance.Wait()
+			}
+		}
+		return result{resp, status}, nil
+	})
+	r := v.(result)
+	return copyResponse(r.resp), r.status
+}
+
+// idempotencyCacheKey 生成幂等响应的缓存键，请求键只在同一个用户的同一个事件内有效，
+// 避免其他用户使用相同的请求键读取到缓存的响应
+func idempotencyCacheKey(event *core.Event, userId, key string) string {
+	return IDEMPOTENCY_CACHE_PREFIX + event.GetUniqueLabel() + "|" + userId + "|" + key
+}
+
+// copyResponse 复制响应，避免后续过滤器修改缓存中的响应
+func copyResponse(resp *jsonx.JsonResponse) *jsonx.JsonResponse {
+	if resp == nil {
+		return nil
+	}
+	dup := *resp
+	return &dup
+}
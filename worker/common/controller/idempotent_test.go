@@ -0,0 +1,59 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+func TestIdempotentExecuteScopedByUser(t *testing.T) {
+	ws := types.NewMockWorkerServer(nil)
+	defer ws.Stop()
+
+	calls := 0
+	exec := func(ctx types.WorkerContext) (*jsonx.JsonResponse, int) {
+		calls++
+		resp := jsonx.DefaultJson(constant.SUCCESS)
+		resp.Message = ctx.UserId()
+		return resp, http.StatusOK
+	}
+	run := func(userId string) *jsonx.JsonResponse {
+		ctx := types.NewMockRequestContext(ws, &core.Event{
+			Project: "demo", Version: "v1", Context: "shop", Entity: "order", Event: "pay",
+			Params: `{"idempotency_key":"k1"}`,
+		})
+		ctx.SetEntityEvent(&core.EntityEvent{Code: "pay", Idempotent: true})
+		ctx.SetUserId(userId)
+		resp, _ := idempotentExecute(ctx, exec)
+		return resp
+	}
+
+	if resp := run("u1"); resp.Message != "u1" || calls != 1 {
+		t.Fatalf("首次执行错误: %+v %d", resp, calls)
+	}
+	if resp := run("u1"); resp.Message != "u1" || calls != 1 {
+		t.Fatalf("同一用户的相同请求键应返回缓存的响应: %+v %d", resp, calls)
+	}
+	// 其他用户使用相同的请求键不能读取到该用户的响应
+	if resp := run("u2"); resp.Message != "u2" || calls != 2 {
+		t.Fatalf("不同用户的请求键应相互独立: %+v %d", resp, calls)
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/spf13/cast"
+	"gorm.io/gorm"
+)
+
+// SoftDeleteQueryParam 读取查询参数中的 deleted，未传时视为 false，即只查询未删除的数据
+func SoftDeleteQueryParam(params map[string]interface{}) bool {
+	deleted, ok := params["deleted"]
+	if !ok {
+		return false
+	}
+	return cast.ToBool(deleted)
+}
+
+// SoftDeleteFilter 按 deleted_at 字段过滤软删除数据，deleted 为 true 时只保留已删除的数据，否则只保留未删除的数据
+func SoftDeleteFilter(db *gorm.DB, deleted bool) *gorm.DB {
+	if deleted {
+		return db.Where("deleted_at != 0")
+	}
+	return db.Where("deleted_at = 0")
+}
+
+// RequiresSoftDeleteFields 检查实体是否定义了软删除字段，
+// deleted_at 须为 datetime 类型，deleted_by 须为 uid 类型
+func RequiresSoftDeleteFields(entityAttrs []core.EntityAttribute) (hasDeletedAt, hasDeletedBy bool) {
+	for _, attr := range entityAttrs {
+		if attr.Code == "deleted_at" && attr.FieldType == string(core.DATETIME_FIELD_TYPE) {
+			hasDeletedAt = true
+		}
+		if attr.Code == "deleted_by" && attr.FieldType == string(core.UID_FIELD_TYPE) {
+			hasDeletedBy = true
+		}
+	}
+	return
+}
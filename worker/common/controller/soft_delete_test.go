@@ -0,0 +1,147 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/worker/types"
+	"gorm.io/gorm"
+)
+
+func TestSoftDeleteQueryParam(t *testing.T) {
+	cases := []struct {
+		params map[string]interface{}
+		want   bool
+	}{
+		{map[string]interface{}{}, false},
+		{map[string]interface{}{"deleted": true}, true},
+		{map[string]interface{}{"deleted": "true"}, true},
+		{map[string]interface{}{"deleted": 0}, false},
+	}
+	for _, c := range cases {
+		if got := SoftDeleteQueryParam(c.params); got != c.want {
+			t.Errorf("SoftDeleteQueryParam(%v) = %v, want %v", c.params, got, c.want)
+		}
+	}
+}
+
+func TestSoftDeleteFilter(t *testing.T) {
+	ws := types.NewMockWorkerServer(nil)
+	t.Cleanup(func() { ws.Stop() })
+	base := ws.Repo().Use("soft_delete")
+
+	cases := []struct {
+		deleted bool
+		want    string
+	}{
+		{false, "WHERE deleted_at = 0"},
+		{true, "WHERE deleted_at != 0"},
+	}
+	for _, c := range cases {
+		db := base.Session(&gorm.Session{DryRun: true}).Table("shop_user")
+		sql := SoftDeleteFilter(db, c.deleted).Find(&[]map[string]interface{}{}).Statement.SQL.String()
+		if !strings.Contains(sql, c.want) {
+			t.Errorf("deleted=%v 生成的SQL错误: %s", c.deleted, sql)
+		}
+	}
+}
+
+func TestRequiresSoftDeleteFields(t *testing.T) {
+	cases := []struct {
+		name      string
+		attrs     []core.EntityAttribute
+		deletedAt bool
+		deletedBy bool
+	}{
+		{"无软删除字段", []core.EntityAttribute{{Code: "id", FieldType: "id"}}, false, false},
+		{"只有删除时间", []core.EntityAttribute{{Code: "deleted_at", FieldType: "datetime"}}, true, false},
+		{"完整软删除字段", []core.EntityAttribute{
+			{Code: "deleted_at", FieldType: "datetime"},
+			{Code: "deleted_by", FieldType: "uid"},
+		}, true, true},
+		{"字段类型不符", []core.EntityAttribute{
+			{Code: "deleted_at", FieldType: "string"},
+			{Code: "deleted_by", FieldType: "string"},
+		}, false, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			deletedAt, deletedBy := RequiresSoftDeleteFields(c.attrs)
+			if deletedAt != c.deletedAt || deletedBy != c.deletedBy {
+				t.Errorf("got (%v, %v), want (%v, %v)", deletedAt, deletedBy, c.deletedAt, c.deletedBy)
+			}
+		})
+	}
+}
+
+func TestSoftDeleteWithoutDeletedAt(t *testing.T) {
+	ws := types.NewMockWorkerServer(nil)
+	t.Cleanup(func() { ws.Stop() })
+	entity := types.PathToEntity{Project: "demo", Version: "v1", Context: "shop", Entity: "tag"}
+	ws.SetEntityAttrs(entity, []core.EntityAttribute{
+		{Code: "id", FieldType: "id"},
+		{Code: "name", FieldType: "string"},
+	})
+	params := `[{"name":"ids","type":"string","range":"any"}]`
+	ws.SetEntityEvents(entity, []core.EntityEvent{
+		{Code: "delete", Params: params},
+		{Code: "restore", Params: params},
+	})
+
+	cases := []struct {
+		event    string
+		executor types.WorkerExecutor
+	}{
+		{"delete", DeleteExecutor},
+		{"restore", RestoreExecutor},
+	}
+	for _, c := range cases {
+		ctx := types.NewMockRequestContext(ws, &core.Event{
+			Project: entity.Project,
+			Version: entity.Version,
+			Context: entity.Context,
+			Entity:  entity.Entity,
+			Event:   c.event,
+			Params:  `{"ids":"1,2"}`,
+		})
+		resp, _ := c.executor(ctx)
+		if resp.Code != string(constant.MISSING_PARAM) || !strings.Contains(resp.Message, "deleted_at") {
+			t.Errorf("%s 应拒绝没有 deleted_at 的实体: %+v", c.event, resp)
+		}
+	}
+}
+
+func TestMaxDeleteSize(t *testing.T) {
+	cases := []struct {
+		settings []core.EventParam
+		want     int
+	}{
+		{nil, DEFAULT_MAX_DELETE_SIZE},
+		{[]core.EventParam{{Name: "max_delete_size", Type: "int"}}, DEFAULT_MAX_DELETE_SIZE},
+		{[]core.EventParam{{Name: "max_delete_size", Type: "int", RangeValue: "-1"}}, DEFAULT_MAX_DELETE_SIZE},
+		{[]core.EventParam{{Name: "max_delete_size", Type: "int", RangeValue: "abc"}}, DEFAULT_MAX_DELETE_SIZE},
+		{[]core.EventParam{{Name: "max_delete_size", Type: "int", RangeValue: " 500 "}}, 500},
+		{[]core.EventParam{{Name: "max_delete_size", Type: "int", RangeValue: "0"}}, 0},
+	}
+	for _, c := range cases {
+		if got := maxDeleteSize(c.settings); got != c.want {
+			t.Errorf("%+v: want %d, got %d", c.settings, c.want, got)
+		}
+	}
+}
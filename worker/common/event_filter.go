@@ -0,0 +1,54 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+// DispatchEventFilters 将执行成功的事件分发给匹配的跨实体订阅，
+// newCtx 为每个处理方法创建独立的上下文，避免覆盖源事件的响应
+func DispatchEventFilters(ws types.WorkerServer, event *core.Event, newCtx func() types.WorkerContext) {
+	entries := ws.EventFilters()
+	if len(entries) == 0 || event == nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.Handler == nil || !entry.Filter.Match(event) {
+			continue
+		}
+		if entry.Filter.TargetWorkerID != "" && !ws.HasWorker(entry.Filter.TargetWorkerID) {
+			continue
+		}
+		if err := callEventFilterHandler(entry.Handler, newCtx()); err != nil {
+			logx.Error(fmt.Sprintf("跨实体事件处理失败[%s -> %s]: %v",
+				event.GetUniqueLabel(), entry.Filter.TargetWorkerID, err))
+		}
+	}
+}
+
+// callEventFilterHandler 调用处理方法，将 panic 转为错误
+func callEventFilterHandler(handler types.EventFilterHandler, ctx types.WorkerContext) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx)
+}
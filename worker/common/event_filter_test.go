@@ -0,0 +1,73 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+func TestDispatchEventFilters(t *testing.T) {
+	svr := types.NewMockWorkerServer(nil)
+	defer svr.Stop()
+	svr.RegisterWorker(&types.Worker{ID: "posts-worker", Project: "blog", Context: "content", Entity: "post"})
+
+	var cleaned []string
+	userDeleted := core.EventFilter{
+		SourceProject: "blog", SourceContext: "account", SourceEntity: "user", SourceEvent: "delete",
+		TargetWorkerID: "posts-worker",
+	}
+	// 出错和 panic 的处理方法不影响后续订阅
+	svr.RegisterEventFilter(userDeleted, func(wc types.WorkerContext) error {
+		return errors.New("cleanup failed")
+	})
+	svr.RegisterEventFilter(userDeleted, func(wc types.WorkerContext) error {
+		panic("cleanup panic")
+	})
+	svr.RegisterEventFilter(userDeleted, func(wc types.WorkerContext) error {
+		cleaned = append(cleaned, wc.Event().Params)
+		return nil
+	})
+	// 目标工作者未注册时不分发
+	svr.RegisterEventFilter(core.EventFilter{SourceEntity: "user", TargetWorkerID: "missing-worker"},
+		func(wc types.WorkerContext) error {
+			t.Error("handler of unregistered worker should not be called")
+			return nil
+		})
+	// 源字段为空时匹配任意事件
+	var matched int
+	svr.RegisterEventFilter(core.EventFilter{SourceEntity: "user"}, func(wc types.WorkerContext) error {
+		matched++
+		return nil
+	})
+
+	newCtx := func(e *core.Event) func() types.WorkerContext {
+		return func() types.WorkerContext { return types.NewMockRequestContext(svr, e) }
+	}
+	deleted := &core.Event{Project: "blog", Context: "account", Entity: "user", Event: "delete", Params: `{"id":"u1"}`}
+	DispatchEventFilters(svr, deleted, newCtx(deleted))
+	updated := &core.Event{Project: "blog", Context: "account", Entity: "user", Event: "update", Params: `{"id":"u2"}`}
+	DispatchEventFilters(svr, updated, newCtx(updated))
+
+	if len(cleaned) != 1 || cleaned[0] != `{"id":"u1"}` {
+		t.Fatalf("unexpected cleanup calls: %v", cleaned)
+	}
+	if matched != 2 {
+		t.Fatalf("wildcard filter should match both events, got %d", matched)
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+// NotifyEventProcessed 异步调用所有事件处理完成回调，不阻塞响应
+func NotifyEventProcessed(ws types.WorkerServer, event *core.Event, statusCode int, start time.Time) {
+	hooks := ws.EventProcessedHooks()
+	if len(hooks) == 0 {
+		return
+	}
+	durationMs := time.Since(start).Milliseconds()
+	for _, hook := range hooks {
+		if hook == nil {
+			continue
+		}
+		go func(hook types.EventProcessedHook) {
+			defer func() {
+				if r := recover(); r != nil {
+					logx.Error(fmt.Sprintf("事件处理完成回调异常: %v", r))
+				}
+			}()
+			hook(event, statusCode, durationMs)
+		}(hook)
+	}
+}
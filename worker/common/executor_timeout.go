@@ -0,0 +1,82 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package common
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+// timeoutWorkerContext 带截止时间的工作上下文，执行器可通过 ExecutorContext 获取并感知取消
+type timeoutWorkerContext struct {
+	types.WorkerContext
+	ctx context.Context
+}
+
+// Context 返回执行器的截止时间上下文
+func (c *timeoutWorkerContext) Context() context.Context {
+	return c.ctx
+}
+
+// ExecutorContext 返回执行器的上下文，由 WithTimeout 包装的执行器超时后该上下文会被取消
+// 调用外部服务或执行耗时计算的自定义执行器应监听该上下文，超时后尽快返回
+func ExecutorContext(wc types.WorkerContext) context.Context {
+	if c, ok := wc.(interface{ Context() context.Context }); ok {
+		return c.Context()
+	}
+	return context.Background()
+}
+
+// WithTimeout 包装执行器，在独立的 goroutine 中执行，超过 timeout 时取消上下文并返回 504 和 EVENT_TIMEOUT
+// 超时后执行器仍可能继续运行，其返回结果会被丢弃，timeout 小于等于0时直接返回原执行器
+func WithTimeout(executor types.WorkerExecutor, timeout time.Duration) types.WorkerExecutor {
+	if executor == nil || timeout <= 0 {
+		return executor
+	}
+	return func(wc types.WorkerContext) (*jsonx.JsonResponse, int) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		type result struct {
+			resp   *jsonx.JsonResponse
+			status int
+		}
+		done := make(chan result, 1)
+		go func() {
+			defer func() {
+				if r := recover(); r != nil {
+					logx.Error(fmt.Sprintf("worker execution panicked: %v\n%s", r, debug.Stack()))
+					done <- result{localizedJson(constant.FAIL_TO_PROCESS, wc.Locale()), http.StatusInternalServerError}
+				}
+			}()
+			resp, status := executor(&timeoutWorkerContext{WorkerContext: wc, ctx: ctx})
+			done <- result{resp, status}
+		}()
+
+		select {
+		case r := <-done:
+			return r.resp, r.status
+		case <-ctx.Done():
+			return localizedJson(constant.EVENT_TIMEOUT, wc.Locale()), http.StatusGatewayTimeout
+		}
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package common
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+func TestWithTimeout(t *testing.T) {
+	ws := types.NewMockWorkerServer(nil)
+	defer ws.Stop()
+	ctx := types.NewMockRequestContext(ws, &core.Event{Project: "shop", Version: "v1", Context: "order", Entity: "item", Event: "sync"})
+
+	fast := WithTimeout(func(wc types.WorkerContext) (*jsonx.JsonResponse, int) {
+		return jsonx.DefaultJson(constant.SUCCESS), http.StatusOK
+	}, 50*time.Millisecond)
+	if resp, status := fast(ctx); status != http.StatusOK || resp.Code != string(constant.SUCCESS) {
+		t.Fatalf("未超时应返回执行器结果: %d %+v", status, resp)
+	}
+
+	cancelled := make(chan struct{})
+	slow := WithTimeout(func(wc types.WorkerContext) (*jsonx.JsonResponse, int) {
+		<-ExecutorContext(wc).Done()
+		close(cancelled)
+		return jsonx.DefaultJson(constant.SUCCESS), http.StatusOK
+	}, 50*time.Millisecond)
+	resp, status := slow(ctx)
+	if status != http.StatusGatewayTimeout || resp.Code != string(constant.EVENT_TIMEOUT) {
+		t.Fatalf("超时应返回504: %d %+v", status, resp)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("超时后应取消执行器上下文")
+	}
+
+	panicked := WithTimeout(func(wc types.WorkerContext) (*jsonx.JsonResponse, int) {
+		panic("executor panic")
+	}, 50*time.Millisecond)
+	if resp, status := panicked(ctx); status != http.StatusInternalServerError || resp.Code != string(constant.FAIL_TO_PROCESS) {
+		t.Fatalf("执行器异常应返回500: %d %+v", status, resp)
+	}
+
+	if ExecutorContext(ctx).Done() != nil {
+		t.Error("未包装的上下文不应有截止时间")
+	}
+}
+
+func TestHandleExecutorWithTimeout(t *testing.T) {
+	ws := types.NewMockWorkerServer(nil)
+	defer ws.Stop()
+	entity := types.PathToEntity{Project: "shop", Version: "v1", Context: "order", Entity: "item"}
+	ws.SetEntityEvents(entity, []core.EntityEvent{{Code: "sync", Timeout: 1}})
+	ctx := types.NewMockRequestContext(ws, &core.Event{Project: "shop", Version: "v1", Context: "order", Entity: "item", Event: "sync"})
+
+	executor := WithTimeout(func(wc types.WorkerContext) (*jsonx.JsonResponse, int) {
+		<-ExecutorContext(wc).Done()
+		return jsonx.DefaultJson(constant.SUCCESS), http.StatusOK
+	}, ExecutorTimeout(ctx.EntityEvent()))
+	if err := HandleExecutor(executor, ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ctx.StatusCode() != http.StatusGatewayTimeout {
+		t.Fatalf("包装后的执行器应先于兜底超时返回504，实际 %d", ctx.StatusCode())
+	}
+
+	if ExecutorTimeout(&core.EntityEvent{}) != DEFAULT_EXECUTOR_TIMEOUT*time.Second {
+		t.Error("未配置超时时应使用默认超时时间")
+	}
+}
+
+// streamCtx 模拟URL查询参数带 stream=true 的请求上下文
+type streamCtx struct {
+	*types.MockRequestContext
+}
+
+func (streamCtx) Query(key string) string {
+	if key == "stream" {
+		return "true"
+	}
+	return ""
+}
+
+func TestHandleExecutorStreamQueryTimeout(t *testing.T) {
+	ws := types.NewMockWorkerServer(nil)
+	defer ws.Stop()
+	entity := types.PathToEntity{Project: "shop", Version: "v1", Context: "order", Entity: "item"}
+	ws.SetEntityEvents(entity, []core.EntityEvent{
+		{Code: "query", Timeout: 1, ExecutorType: constant.BUILD_IN_EXECUTOR, Executor: "query"},
+		{Code: "sync", Timeout: 1, ExecutorType: constant.CUSTOM_EXECUTOR, Executor: "sync"},
+	})
+	newCtx := func(event string) streamCtx {
+		return streamCtx{types.NewMockRequestContext(ws, &core.Event{Project: "shop", Version: "v1", Context: "order", Entity: "item", Event: event})}
+	}
+	executor := func(wc types.WorkerContext) (*jsonx.JsonResponse, int) {
+		time.Sleep(ExecutorTimeout(wc.EntityEvent()) + 2*executorTimeoutGrace)
+		wc.BatchResponse(func(yield func(row map[string]interface{}) bool) {
+			yield(map[string]interface{}{"id": "1"})
+		})
+		return jsonx.DefaultJson(constant.SUCCESS), http.StatusOK
+	}
+
+	// 内置查询执行器的流式查询按放宽后的超时时间等待写出完成
+	ctx := newCtx("query")
+	if err := HandleExecutor(executor, ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ctx.StatusCode() == http.StatusRequestTimeout {
+		t.Fatal("流式查询不应在事件超时时间内被中断")
+	}
+	if string(ctx.ResponseBody()) != "{\"id\":\"1\"}\n" {
+		t.Fatalf("应等待流式写出完成: %q", ctx.ResponseBody())
+	}
+
+	// 其他执行器带 stream=true 时仍按事件超时时间返回 408
+	release := make(chan struct{})
+	blocking := func(wc types.WorkerContext) (*jsonx.JsonResponse, int) {
+		<-release
+		return jsonx.DefaultJson(constant.SUCCESS), http.StatusOK
+	}
+	ctx = newCtx("sync")
+	if err := HandleExecutor(blocking, ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ctx.StatusCode() != http.StatusRequestTimeout {
+		t.Fatalf("非查询执行器不应跳过兜底超时，实际 %d", ctx.StatusCode())
+	}
+	close(release)
+
+	if StreamQueryTimeout(&core.EntityEvent{Timeout: 1}) != STREAM_QUERY_TIMEOUT_FACTOR*time.Second {
+		t.Error("流式查询的超时时间应为事件超时时间的倍数")
+	}
+}
@@ -26,19 +26,36 @@ import (
 	"github.com/garrickvan/event-matrix/utils/jsonx"
 	"github.com/garrickvan/event-matrix/utils/logx"
 	"github.com/garrickvan/event-matrix/worker/types"
+	"github.com/spf13/cast"
 )
 
+// streamedResponder 支持流式写出响应的上下文
+type streamedResponder interface {
+	Streamed() bool
+}
+
+// queryParamReader 可读取URL查询参数的上下文
+type queryParamReader interface {
+	Query(key string) string
+}
+
+// IsStreamQuery URL查询参数 stream=true 时以NDJSON逐行返回查询结果
+func IsStreamQuery(ctx types.WorkerContext) bool {
+	reader, ok := ctx.(queryParamReader)
+	return ok && cast.ToBool(reader.Query("stream"))
+}
+
 func HandleExecutor(funz types.WorkerExecutor, ctx types.WorkerContext) error {
 	// 运行拦截器
-	if stop := runInterceptors(ctx); stop {
+	if stop := RunInterceptors(ctx); stop {
 		return nil
 	}
 	// 执行执行器
 	return executorTimeoutInvoker(funz, ctx)
 }
 
-// 运行拦截器
-func runInterceptors(ctx types.WorkerContext) bool {
+// RunInterceptors 依次运行拦截器，任一拦截器要求中止时返回 true
+func RunInterceptors(ctx types.WorkerContext) bool {
 	for _, intercept := range ctx.Server().Intercepts() {
 		if intercept == nil {
 			continue
@@ -50,18 +67,80 @@ func runInterceptors(ctx types.WorkerContext) bool {
 	return false
 }
 
-// 执行器超时调用
+// RunFilters 依次运行过滤器，任一过滤器要求中止时返回 true
+func RunFilters(ctx types.WorkerContext, jsResp *jsonx.JsonResponse) bool {
+	for _, filter := range ctx.Server().Filters() {
+		if filter == nil {
+			continue
+		}
+		if stop := filter(ctx, jsResp); stop {
+			return true
+		}
+	}
+	return false
+}
+
+// RunMiddlewares 按注册顺序依次进入中间件，最内层调用执行器
+func RunMiddlewares(ctx types.WorkerContext, funz types.WorkerExecutor) (*jsonx.JsonResponse, int) {
+	middlewares := ctx.Server().Middlewares()
+	var call func(i int) (*jsonx.JsonResponse, int)
+	call = func(i int) (*jsonx.JsonResponse, int) {
+		for ; i < len(middlewares); i++ {
+			if mw := middlewares[i]; mw != nil {
+				next := i + 1
+				return mw(ctx, func() (*jsonx.JsonResponse, int) { return call(next) })
+			}
+		}
+		return funz(ctx)
+	}
+	return call(0)
+}
+
+// DEFAULT_EXECUTOR_TIMEOUT 事件未配置超时时间时执行器的默认超时时间，单位秒
+const DEFAULT_EXECUTOR_TIMEOUT = 3
+
+// ExecutorTimeout 返回事件执行器的超时时间，未配置时使用默认超时时间
+func ExecutorTimeout(entityEvent *core.EntityEvent) time.Duration {
+	if entityEvent == nil || entityEvent.Timeout <= 0 {
+		return DEFAULT_EXECUTOR_TIMEOUT * time.Second
+	}
+	return time.Duration(entityEvent.Timeout) * time.Second
+}
+
+// STREAM_QUERY_TIMEOUT_FACTOR 流式查询的超时时间相对事件超时时间的倍数
+// 流式查询边读边写，耗时随结果集增长，但仍需有上限，避免慢客户端长期占用连接和数据库游标
+const STREAM_QUERY_TIMEOUT_FACTOR = 10
+
+// StreamQueryTimeout 返回流式查询的超时时间
+func StreamQueryTimeout(entityEvent *core.EntityEvent) time.Duration {
+	return ExecutorTimeout(entityEvent) * STREAM_QUERY_TIMEOUT_FACTOR
+}
+
+// isStreamQueryExecutor 是否为内置查询执行器处理的流式查询，其他执行器忽略 stream 参数
+func isStreamQueryExecutor(ctx types.WorkerContext) bool {
+	entityEvent := ctx.EntityEvent()
+	return entityEvent != nil &&
+		entityEvent.ExecutorType == constant.BUILD_IN_EXECUTOR &&
+		entityEvent.Executor == "query" &&
+		IsStreamQuery(ctx)
+}
+
+// executorTimeoutGrace 执行器超时调用的额外等待时间，使 WithTimeout 包装的执行器先返回 504
+const executorTimeoutGrace = 100 * time.Millisecond
+
+// 执行器超时调用，作为所有执行器的兜底超时，超时返回 408
 func executorTimeoutInvoker(funz types.WorkerExecutor, ctx types.WorkerContext) error {
 	entityEvent := ctx.EntityEvent()
 	event := ctx.Event()
 	bodyBytes := ctx.Body()
-	// 设置默认超时时间为 3 秒
-	if entityEvent.Timeout <= 0 {
-		entityEvent.Timeout = 3
+	t := ExecutorTimeout(entityEvent)
+	if isStreamQueryExecutor(ctx) {
+		// 流式查询边读边写，超时后已发出的响应无法再改写为 408，且请求上下文在返回后会被回收，
+		// 因此放宽到流式查询的超时时间，查询执行器自身也按该时间结束读取
+		t = StreamQueryTimeout(entityEvent)
 	}
-	t := time.Duration(entityEvent.Timeout) * time.Second
 	ip := ctx.IP()
-	timeoutCtx, cancel := context.WithTimeout(context.Background(), t)
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), t+executorTimeoutGrace)
 	defer cancel()
 
 	resultJsResp := make(chan *jsonx.JsonResponse, 1)
@@ -84,7 +163,7 @@ func executorTimeoutInvoker(funz types.WorkerExecutor, ctx types.WorkerContext)
 			}
 		}()
 
-		jsResp, httpStatus := funz(ctx)
+		jsResp, httpStatus := RunMiddlewares(ctx, funz)
 		ctx.SetStatus(httpStatus)
 		resultJsResp <- jsResp
 		close(resultJsResp)
@@ -104,14 +183,13 @@ func executorTimeoutInvoker(funz types.WorkerExecutor, ctx types.WorkerContext)
 			}
 			core.SaveEventLog(ip, comment, event.Source, userId, fastconv.BytesToString(bodyBytes), constant.RESPONSE_CODE(jsResp.Code), event, ctx.Server().ServerId())
 		}
+		// 执行器已通过 BatchResponse 写出响应，返回值只用于记录日志
+		if s, ok := ctx.(streamedResponder); ok && s.Streamed() {
+			return nil
+		}
 		// 运行过滤器
-		for _, filter := range ctx.Server().Filters() {
-			if filter == nil {
-				continue
-			}
-			if stop := filter(ctx, jsResp); stop {
-				return nil
-			}
+		if stop := RunFilters(ctx, jsResp); stop {
+			return nil
 		}
 		// 返回 JSON 响应
 		if jsResp != nil {
@@ -137,3 +215,14 @@ func ValidatedEvent(bodyBytes []byte) (*core.Event, constant.RESPONSE_CODE) {
 	}
 	return event, constant.SUCCESS
 }
+
+// ResolveAliasEvent 事件的实体为工作者的实体别名时改写为工作者的实体名，
+// 使实体事件、执行器路由和数据表都按原实体处理，需在校验签名之后调用
+func ResolveAliasEvent(ws types.WorkerServer, event *core.Event) {
+	if event == nil {
+		return
+	}
+	if w := ws.GetWorkerByEvent(types.PathToEntityFromEvent(event)); w != nil && w.Entity != event.Entity {
+		event.Entity = w.Entity
+	}
+}
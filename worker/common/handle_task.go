@@ -31,7 +31,7 @@ import (
 
 func HandleTask(task types.WorkerTaskExecutor, ctx types.WorkerContext) error {
 	// 运行拦截器
-	if stop := runInterceptors(ctx); stop {
+	if stop := RunInterceptors(ctx); stop {
 		return nil
 	}
 	// 执行任务
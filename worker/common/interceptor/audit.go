@@ -0,0 +1,115 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"reflect"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/types"
+	"github.com/spf13/cast"
+)
+
+// AUDIT_SECRECY_MASK 保密字段在审计记录中的替代值，只记录发生了变化，不记录具体值
+const AUDIT_SECRECY_MASK = "******"
+
+// saveAuditEntry 保存审计记录，测试时可替换
+var saveAuditEntry = core.SaveAuditEntry
+
+// AuditInterceptor 审计中间件，对开启日志的内置更新事件，在更新前读取原记录，
+// 更新成功后对比前后的字段值，将发生变化的字段写入审计记录，试运行不记录
+func AuditInterceptor(wc types.WorkerContext, next func() (*jsonx.JsonResponse, int)) (*jsonx.JsonResponse, int) {
+	entityEvent := wc.EntityEvent()
+	event := wc.Event()
+	if entityEvent == nil || event == nil || !entityEvent.Logable ||
+		entityEvent.ExecutorType != constant.BUILD_IN_EXECUTOR || entityEvent.Executor != "update" {
+		return next()
+	}
+	entityAttrs, _, params, errJson := wc.ValidatedParams()
+	id := cast.ToString(params["id"])
+	if errJson != nil || id == "" {
+		return next()
+	}
+	db := wc.Server().Repo().Use(event.Project)
+	if db == nil {
+		return next()
+	}
+	before := map[string]interface{}{}
+	if err := db.Table(event.GetTabelName()).Where("id = ?", id).Take(&before).Error; err != nil {
+		// 原记录不存在时更新也会失败，无需审计
+		return next()
+	}
+
+	resp, status := next()
+	if resp == nil || resp.Code != string(constant.SUCCESS) || resp.DryRun {
+		return resp, status
+	}
+	after := map[string]interface{}{}
+	if err := db.Table(event.GetTabelName()).Where("id = ?", id).Take(&after).Error; err != nil {
+		logx.Error("审计记录读取更新后的数据失败: " + err.Error())
+		return resp, status
+	}
+	changedBefore, changedAfter := diffFields(before, after, entityAttrs)
+	if len(changedAfter) == 0 {
+		return resp, status
+	}
+	changedBy := wc.UserId()
+	if changedBy == "" {
+		changedBy = "unknown"
+	}
+	saveAuditEntry(&core.AuditEntry{
+		EventId:   event.ID,
+		EventNode: event.GetFullEventLabel(),
+		RecordId:  id,
+		Before:    changedBefore,
+		After:     changedAfter,
+		ChangedBy: changedBy,
+		ServerId:  wc.Server().ServerId(),
+	})
+	return resp, status
+}
+
+// diffFields 逐个字段对比记录修改前后的值，返回发生变化的字段在修改前后的值，保密字段的值使用掩码代替
+func diffFields(before, after map[string]interface{}, entityAttrs []core.EntityAttribute) (map[string]interface{}, map[string]interface{}) {
+	changedBefore := map[string]interface{}{}
+	changedAfter := map[string]interface{}{}
+	for key, newVal := range after {
+		oldVal := normalizeValue(before[key])
+		newVal = normalizeValue(newVal)
+		if reflect.DeepEqual(oldVal, newVal) {
+			continue
+		}
+		if attr := core.FindAttrFromArray(key, entityAttrs); attr != nil && attr.IsSecrecy {
+			oldVal, newVal = AUDIT_SECRECY_MASK, AUDIT_SECRECY_MASK
+		}
+		changedBefore[key] = oldVal
+		changedAfter[key] = newVal
+	}
+	return changedBefore, changedAfter
+}
+
+// normalizeValue 统一数据库驱动返回的值类型，便于比较和序列化
+func normalizeValue(v interface{}) interface{} {
+	if p, ok := v.(*interface{}); ok && p != nil {
+		v = *p
+	}
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return v
+}
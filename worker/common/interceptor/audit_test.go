@@ -0,0 +1,91 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"testing"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/worker/common/controller"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+func TestAuditInterceptor(t *testing.T) {
+	ws := types.NewMockWorkerServer(nil)
+	t.Cleanup(func() { ws.Stop() })
+	entity := types.PathToEntity{Project: "shop", Version: "v1", Context: "shop", Entity: "account"}
+	ws.SetEntityAttrs(entity, []core.EntityAttribute{
+		{Code: "id", FieldType: "id"},
+		{Code: "name", FieldType: "string"},
+		{Code: "password", FieldType: "string", IsSecrecy: true},
+	})
+	params := `[{"name":"id","type":"id"},{"name":"name","type":"string"},{"name":"password","type":"string"}]`
+	ws.SetEntityEvents(entity, []core.EntityEvent{
+		{Code: "update", Executor: "update", Logable: true, Params: params},
+		{Code: "silent_update", Executor: "update", Params: params},
+	})
+	db := ws.Repo().Use(entity.Project)
+	if err := db.Exec("CREATE TABLE shop_account (id TEXT PRIMARY KEY, name TEXT, password TEXT)").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec("INSERT INTO shop_account VALUES ('u1', 'alice', 'secret')").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var entries []*core.AuditEntry
+	origin := saveAuditEntry
+	saveAuditEntry = func(e *core.AuditEntry) { entries = append(entries, e) }
+	t.Cleanup(func() { saveAuditEntry = origin })
+
+	update := func(eventCode, params string) *jsonx.JsonResponse {
+		ctx := types.NewMockRequestContext(ws, &core.Event{
+			ID: "evt-" + eventCode, Project: entity.Project, Version: entity.Version,
+			Context: entity.Context, Entity: entity.Entity, Event: eventCode, Params: params,
+		})
+		ctx.SetUserId("admin")
+		resp, _ := AuditInterceptor(ctx, func() (*jsonx.JsonResponse, int) { return controller.UpdateExecutor(ctx) })
+		return resp
+	}
+
+	if resp := update("update", `{"id":"u1","name":"bob","password":"changed"}`); resp.Code != string(constant.SUCCESS) {
+		t.Fatalf("update failed: %s %s", resp.Code, resp.Message)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 audit entry, got %d", len(entries))
+	}
+	e := entries[0]
+	if e.EventId != "evt-update" || e.RecordId != "u1" || e.ChangedBy != "admin" {
+		t.Fatalf("unexpected audit entry: %+v", e)
+	}
+	if e.Before["name"] != "alice" || e.After["name"] != "bob" {
+		t.Fatalf("unexpected name diff: %v -> %v", e.Before, e.After)
+	}
+	if e.Before["password"] != AUDIT_SECRECY_MASK || e.After["password"] != AUDIT_SECRECY_MASK {
+		t.Fatalf("secrecy field should be masked: %v -> %v", e.Before, e.After)
+	}
+	if _, ok := e.After["id"]; ok {
+		t.Fatalf("unchanged field should not be recorded: %v", e.After)
+	}
+
+	// 值未变化、未开启日志或更新失败时不记录
+	update("update", `{"id":"u1","name":"bob"}`)
+	update("silent_update", `{"id":"u1","name":"carol"}`)
+	update("update", `{"id":"missing","name":"dave"}`)
+	if len(entries) != 1 {
+		t.Fatalf("expected no more audit entries, got %d", len(entries))
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+func TestHandleExecutorMiddlewares(t *testing.T) {
+	ws := types.NewMockWorkerServer(nil)
+	defer ws.Stop()
+	entity := types.PathToEntity{Project: "shop", Version: "v1", Context: "order", Entity: "item"}
+	ws.SetEntityEvents(entity, []core.EntityEvent{{Code: "sync"}})
+	event := &core.Event{Project: "shop", Version: "v1", Context: "order", Entity: "item", Event: "sync"}
+
+	var calls []string
+	record := func(name string) types.WorkerMiddleware {
+		return func(wc types.WorkerContext, next func() (*jsonx.JsonResponse, int)) (*jsonx.JsonResponse, int) {
+			calls = append(calls, name+">")
+			resp, status := next()
+			calls = append(calls, "<"+name)
+			return resp, status
+		}
+	}
+	ws.RegisterMiddleware(record("auth"))
+	ws.RegisterMiddleware(nil)
+	ws.RegisterMiddleware(record("audit"))
+	executor := func(wc types.WorkerContext) (*jsonx.JsonResponse, int) {
+		calls = append(calls, "executor")
+		return jsonx.DefaultJson(constant.SUCCESS), http.StatusOK
+	}
+
+	ctx := types.NewMockRequestContext(ws, event)
+	if err := HandleExecutor(executor, ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(calls, " "); got != "auth> audit> executor <audit <auth" {
+		t.Fatalf("中间件调用顺序错误: %s", got)
+	}
+
+	// 中间件不调用 next 时直接返回其结果，执行器不会被调用
+	calls = nil
+	ws.RegisterMiddleware(func(wc types.WorkerContext, next func() (*jsonx.JsonResponse, int)) (*jsonx.JsonResponse, int) {
+		return jsonx.DefaultJson(constant.FORBIDDEN_CALL), http.StatusForbidden
+	})
+	ctx = types.NewMockRequestContext(ws, event)
+	if err := HandleExecutor(executor, ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ctx.StatusCode() != http.StatusForbidden || strings.Contains(strings.Join(calls, " "), "executor") {
+		t.Fatalf("中间件应能中止执行: %d %v", ctx.StatusCode(), calls)
+	}
+}
@@ -15,13 +15,14 @@
 package common
 
 import (
-	"fmt"
+	"net/http"
 	"net/url"
 	"strings"
 
 	"github.com/garrickvan/event-matrix/constant"
 	"github.com/garrickvan/event-matrix/core"
 	"github.com/garrickvan/event-matrix/utils"
+	"github.com/garrickvan/event-matrix/utils/i18n"
 	"github.com/garrickvan/event-matrix/utils/jsonx"
 	"github.com/garrickvan/event-matrix/utils/logx"
 	"github.com/garrickvan/event-matrix/worker/types"
@@ -46,32 +47,36 @@ func ParseAndValidateParams(ctx types.WorkerContext) (
 
 	event := ctx.Event()
 	if event == nil {
-		return emptyEntityAttrs, emptyParamSettings, emptyParams, jsonx.DefaultJson(constant.EVENT_NOT_EXIST)
+		return emptyEntityAttrs, emptyParamSettings, emptyParams, localizedJson(constant.EVENT_NOT_EXIST, ctx.Locale())
 	}
 
 	entityEvent := ctx.EntityEvent()
 	if entityEvent == nil {
-		return emptyEntityAttrs, emptyParamSettings, emptyParams, jsonx.DefaultJson(constant.ENTITY_NOT_EXIST)
+		return emptyEntityAttrs, emptyParamSettings, emptyParams, localizedJson(constant.ENTITY_NOT_EXIST, ctx.Locale())
 	}
 
 	if entityEvent == nil {
-		return emptyEntityAttrs, emptyParamSettings, emptyParams, jsonx.DefaultJson(constant.EVENT_NOT_EXIST)
+		return emptyEntityAttrs, emptyParamSettings, emptyParams, localizedJson(constant.EVENT_NOT_EXIST, ctx.Locale())
+	}
+	if entityEvent.ParamSizeExceeded(event.Params) {
+		ctx.SetStatus(http.StatusRequestEntityTooLarge)
+		return emptyEntityAttrs, emptyParamSettings, emptyParams, localizedJson(constant.PAYLOAD_TOO_LARGE, ctx.Locale())
 	}
 	entityAttrs := ctx.Server().DomainCache().EntityAttrs(types.PathToEntityFromEvent(event))
 	if entityAttrs == nil || len(entityAttrs) == 0 {
-		return emptyEntityAttrs, emptyParamSettings, emptyParams, jsonx.DefaultJson(constant.ENTITY_NOT_EXIST)
+		return emptyEntityAttrs, emptyParamSettings, emptyParams, localizedJson(constant.ENTITY_NOT_EXIST, ctx.Locale())
 	}
 	params := map[string]interface{}{}
 	err := jsonx.UnmarshalFromStr(event.Params, &params)
 	if err != nil {
 		logx.Debug(event.GetFullEventLabel()+"参数解析失败: ", err)
-		return emptyEntityAttrs, emptyParamSettings, emptyParams, jsonx.DefaultJson(constant.INVALID_PARAM)
+		return emptyEntityAttrs, emptyParamSettings, emptyParams, localizedJson(constant.INVALID_PARAM, ctx.Locale())
 	}
 	paramSettings := []core.EventParam{}
 	err = jsonx.UnmarshalFromStr(entityEvent.Params, &paramSettings)
 	if err != nil {
 		logx.Debug(event.GetFullEventLabel()+"参数设置解析失败: ", err, "\n", entityEvent.Params)
-		return emptyEntityAttrs, emptyParamSettings, emptyParams, jsonx.DefaultJson(constant.INVALID_PARAM)
+		return emptyEntityAttrs, emptyParamSettings, emptyParams, localizedJson(constant.INVALID_PARAM, ctx.Locale())
 	}
 	// 按参数设置进行参数校验
 	for name, param := range params {
@@ -93,8 +98,14 @@ func ParseAndValidateParams(ctx types.WorkerContext) (
 						logx.Log().Warn(event.GetFullEventLabel() + "未找到自定义字段解析器: " + attr.ValueSource)
 					}
 				} else {
-					params[setting.Name] = attr.FixValue(param)
-					param = params[setting.Name]
+					fixed, err := attr.FixValue(param)
+					if err != nil {
+						errJson := localizedJson(constant.INVALID_PARAM, ctx.Locale())
+						errJson.Message = err.Error()
+						return emptyEntityAttrs, emptyParamSettings, emptyParams, errJson
+					}
+					params[setting.Name] = fixed
+					param = fixed
 				}
 			} else {
 				// 非实体属性的数据，则根据参数设置的类型进行数值转换
@@ -116,8 +127,8 @@ func ParseAndValidateParams(ctx types.WorkerContext) (
 		}
 		// 检查是否传入必要参数
 		if setting != nil && setting.Required && param == nil {
-			errResponse := jsonx.DefaultJson(constant.MISSING_PARAM)
-			errResponse.Message = fmt.Sprintf("缺少必要参数: %s", setting.Name)
+			errResponse := localizedJson(constant.MISSING_PARAM, ctx.Locale())
+			errResponse.Message = i18n.Translatef("param.missing", ctx.Locale(), setting.Name)
 			return emptyEntityAttrs, emptyParamSettings, emptyParams, errResponse
 		}
 
@@ -130,6 +141,15 @@ func ParseAndValidateParams(ctx types.WorkerContext) (
 	return entityAttrs, paramSettings, params, nil
 }
 
+// localizedJson 返回内置响应，存在对应语言的翻译时替换提示信息
+func localizedJson(code constant.RESPONSE_CODE, locale string) *jsonx.JsonResponse {
+	resp := jsonx.DefaultJson(code)
+	if msg, ok := i18n.Lookup("code."+string(code), locale); ok {
+		resp.Message = msg
+	}
+	return resp
+}
+
 func validateParam(
 	setting *core.EventParam,
 	param interface{},
@@ -140,26 +160,27 @@ func validateParam(
 	if setting == nil {
 		return nil
 	}
+	locale := ctx.Locale()
 	switch setting.Type {
 	case "string", "id", "text":
-		return stringParamValidate(setting, param, event)
+		return stringParamValidate(setting, param, event, locale)
 	case "ref":
-		return refParamValidate(setting, param, entityAttrs, event)
+		return refParamValidate(setting, param, entityAttrs, event, locale)
 	case "constant":
 		return constantParamValidate(setting, param, event, ctx)
 	case "url":
-		return urlParamValidate(setting, param, event)
+		return urlParamValidate(setting, param, event, locale)
 	case "email":
-		return emailParamValidate(setting, param, event)
+		return emailParamValidate(setting, param, event, locale)
 	case "phone":
-		return phoneParamValidate(setting, param, event)
+		return phoneParamValidate(setting, param, event, locale)
 	case "int8", "int16", "int32", "int64", "datetime", "float32", "float64":
-		return numberParamValidate(setting, param, event)
-	case "boolean":
-		return booleanParamValidate(setting, param, event)
+		return numberParamValidate(setting, param, event, locale)
+	case "boolean", "dry_run":
+		return booleanParamValidate(setting, param, event, locale)
 	case "custom":
 		return customParamValidate(setting, param, entityAttrs, event, ctx)
-	case "and_query", "or_query":
+	case "and_query", "or_query", "cursor":
 		return nil
 	default:
 		logx.Log().Warn(event.GetFullEventLabel() + "未知参数类型: " + setting.Name + " " + setting.Type)
@@ -171,6 +192,7 @@ func stringParamValidate(
 	setting *core.EventParam,
 	param interface{},
 	event *core.Event,
+	locale string,
 ) *jsonx.JsonResponse {
 	str := cast.ToString(param)
 	errJson := jsonx.DefaultJson(constant.INVALID_PARAM)
@@ -181,13 +203,13 @@ func stringParamValidate(
 	case "in":
 		rangeVals := strings.Split(setting.RangeValue, ",")
 		if !utils.InStrArray(str, rangeVals) {
-			errJson.Message = "参数值不符合要求: " + setting.Name
+			errJson.Message = i18n.Translatef("param.invalid", locale, setting.Name)
 			return errJson
 		}
 	case "nin":
 		rangeVals := strings.Split(setting.RangeValue, ",")
 		if utils.InStrArray(str, rangeVals) {
-			errJson.Message = "参数值不符合要求: " + setting.Name
+			errJson.Message = i18n.Translatef("param.invalid", locale, setting.Name)
 			return errJson
 		}
 	case "length":
@@ -195,14 +217,14 @@ func stringParamValidate(
 		if len(rangeVals) == 1 {
 			size := cast.ToInt(rangeVals[0])
 			if len(str) != size {
-				errJson.Message = "参数值长度不符合要求(" + setting.RangeValue + "): " + setting.Name
+				errJson.Message = i18n.Translatef("param.length", locale, setting.RangeValue, setting.Name)
 				return errJson
 			}
 		} else if len(rangeVals) >= 2 {
 			min := cast.ToInt(rangeVals[0])
 			max := cast.ToInt(rangeVals[1])
 			if len(str) < min || len(str) > max {
-				errJson.Message = "参数值长度不符合要求(" + setting.RangeValue + "): " + setting.Name
+				errJson.Message = i18n.Translatef("param.length", locale, setting.RangeValue, setting.Name)
 				return errJson
 			}
 		} else {
@@ -211,19 +233,29 @@ func stringParamValidate(
 	case "r_like":
 		rangeVal := setting.RangeValue
 		if !strings.HasPrefix(str, rangeVal) {
-			errJson.Message = "参数值不是以" + rangeVal + "开头: " + setting.Name
+			errJson.Message = i18n.Translatef("param.prefix", locale, rangeVal, setting.Name)
 			return errJson
 		}
 	case "l_like":
 		rangeVal := setting.RangeValue
 		if !strings.HasSuffix(str, rangeVal) {
-			errJson.Message = "参数值不是以" + rangeVal + "结尾: " + setting.Name
+			errJson.Message = i18n.Translatef("param.suffix", locale, rangeVal, setting.Name)
 			return errJson
 		}
 	case "a_like":
 		rangeVal := setting.RangeValue
 		if !strings.Contains(str, rangeVal) {
-			errJson.Message = "参数值不包含" + rangeVal + ": " + setting.Name
+			errJson.Message = i18n.Translatef("param.contains", locale, rangeVal, setting.Name)
+			return errJson
+		}
+	case "regex":
+		re, err := utils.CompileRegexp(setting.RangeValue)
+		if err != nil {
+			logx.Warn(event.GetFullEventLabel() + "参数正则表达式设置错误: " + setting.Name + " " + err.Error())
+			return nil
+		}
+		if !re.MatchString(str) {
+			errJson.Message = i18n.Translatef("param.regex", locale, setting.RangeValue, setting.Name)
 			return errJson
 		}
 	default:
@@ -237,11 +269,12 @@ func refParamValidate(
 	param interface{},
 	entityAttrs []core.EntityAttribute,
 	event *core.Event,
+	locale string,
 ) *jsonx.JsonResponse {
 	idStr := cast.ToString(param)
 	if len(idStr) == 0 {
 		errJson := jsonx.DefaultJson(constant.INVALID_PARAM)
-		errJson.Message = "参数值不能为空: " + setting.Name
+		errJson.Message = i18n.Translatef("param.empty", locale, setting.Name)
 		return errJson
 	}
 	// 不自动校验ref参数是否存在表中，否则可能导致性能问题
@@ -254,10 +287,11 @@ func constantParamValidate(
 	event *core.Event,
 	ctx types.WorkerContext,
 ) *jsonx.JsonResponse {
+	locale := ctx.Locale()
 	constantVal := cast.ToString(param)
 	if len(constantVal) == 0 {
 		errJson := jsonx.DefaultJson(constant.INVALID_PARAM)
-		errJson.Message = "参数值不能为空: " + setting.Name
+		errJson.Message = i18n.Translatef("param.empty", locale, setting.Name)
 		return errJson
 	}
 	vals := strings.Split(setting.RangeValue, ".")
@@ -277,7 +311,7 @@ func constantParamValidate(
 		}
 	}
 	errJson := jsonx.DefaultJson(constant.INVALID_PARAM)
-	errJson.Message = "常量参数值不在系统定义中: " + setting.Name
+	errJson.Message = i18n.Translatef("param.constant", locale, setting.Name)
 	return errJson
 }
 
@@ -285,29 +319,52 @@ func urlParamValidate(
 	setting *core.EventParam,
 	param interface{},
 	event *core.Event,
+	locale string,
 ) *jsonx.JsonResponse {
 	urlStr := cast.ToString(param)
 	if len(urlStr) > 0 {
-		_, err := url.ParseRequestURI(urlStr)
+		u, err := url.ParseRequestURI(urlStr)
 		if err != nil {
 			errJson := jsonx.DefaultJson(constant.INVALID_PARAM)
-			errJson.Message = "参数值不是有效的URL: " + setting.Name
+			errJson.Message = i18n.Translatef("param.url", locale, setting.Name)
+			return errJson
+		}
+		// domain: 校验URL是否属于白名单域名（含子域名），白名单以逗号分隔
+		if setting.Range == "domain" && !inDomainWhitelist(u.Hostname(), setting.RangeValue) {
+			errJson := jsonx.DefaultJson(constant.INVALID_PARAM)
+			errJson.Message = i18n.Translatef("param.domain", locale, setting.Name)
 			return errJson
 		}
 	}
 	return nil
 }
 
+// inDomainWhitelist 判断主机名是否为白名单中的域名或其子域名
+func inDomainWhitelist(host, whitelist string) bool {
+	host = strings.ToLower(host)
+	for _, domain := range strings.Split(whitelist, ",") {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if domain == "" {
+			continue
+		}
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
 func emailParamValidate(
 	setting *core.EventParam,
 	param interface{},
 	event *core.Event,
+	locale string,
 ) *jsonx.JsonResponse {
 	emailStr := cast.ToString(param)
 	if len(emailStr) > 0 {
 		if !utils.IsEmail(emailStr) {
 			errJson := jsonx.DefaultJson(constant.INVALID_PARAM)
-			errJson.Message = "参数值不是有效的Email地址: " + setting.Name
+			errJson.Message = i18n.Translatef("param.email", locale, setting.Name)
 			return errJson
 		}
 	}
@@ -318,12 +375,19 @@ func phoneParamValidate(
 	setting *core.EventParam,
 	param interface{},
 	event *core.Event,
+	locale string,
 ) *jsonx.JsonResponse {
 	phoneStr := cast.ToString(param)
 	if len(phoneStr) > 0 {
+		// RangeValue 为逗号分隔的国家代码白名单，如 "+1,+44,+86"，为空时不限制国家代码
+		if !utils.HasPhoneCountryCode(phoneStr, setting.RangeValue) {
+			errJson := jsonx.DefaultJson(constant.INVALID_PARAM)
+			errJson.Message = i18n.Translatef("param.phone_country", locale, setting.Name)
+			return errJson
+		}
 		if !utils.IsPhoneNumber(phoneStr) {
 			errJson := jsonx.DefaultJson(constant.INVALID_PARAM)
-			errJson.Message = "参数值不是有效的手机号码: " + setting.Name
+			errJson.Message = i18n.Translatef("param.phone", locale, setting.Name)
 			return errJson
 		}
 	}
@@ -333,6 +397,7 @@ func numberParamValidate(
 	setting *core.EventParam,
 	param interface{},
 	event *core.Event,
+	locale string,
 ) *jsonx.JsonResponse {
 	rangeVals := strings.Split(setting.RangeValue, ",")
 	// 将param转换为float64类型，这样能支持float32和float64
@@ -349,7 +414,7 @@ func numberParamValidate(
 		}
 		if !utils.InFloat64Array(paramFloat, floatVals) {
 			errJson := jsonx.DefaultJson(constant.INVALID_PARAM)
-			errJson.Message = "参数值不符合要求: " + setting.Name
+			errJson.Message = i18n.Translatef("param.invalid", locale, setting.Name)
 			return errJson
 		}
 	case "nin":
@@ -360,7 +425,7 @@ func numberParamValidate(
 		}
 		if utils.InFloat64Array(paramFloat, floatVals) {
 			errJson := jsonx.DefaultJson(constant.INVALID_PARAM)
-			errJson.Message = "参数值不符合要求: " + setting.Name
+			errJson.Message = i18n.Translatef("param.invalid", locale, setting.Name)
 			return errJson
 		}
 	case "gt":
@@ -371,7 +436,7 @@ func numberParamValidate(
 		rangeVal := cast.ToFloat64(rangeVals[0])
 		if paramFloat <= rangeVal {
 			errJson := jsonx.DefaultJson(constant.INVALID_PARAM)
-			errJson.Message = "参数值不符合要求: " + setting.Name
+			errJson.Message = i18n.Translatef("param.invalid", locale, setting.Name)
 			return errJson
 		}
 	case "gte":
@@ -382,7 +447,7 @@ func numberParamValidate(
 		rangeVal := cast.ToFloat64(rangeVals[0])
 		if paramFloat < rangeVal {
 			errJson := jsonx.DefaultJson(constant.INVALID_PARAM)
-			errJson.Message = "参数值不符合要求: " + setting.Name
+			errJson.Message = i18n.Translatef("param.invalid", locale, setting.Name)
 			return errJson
 		}
 	case "lt":
@@ -393,7 +458,7 @@ func numberParamValidate(
 		rangeVal := cast.ToFloat64(rangeVals[0])
 		if paramFloat >= rangeVal {
 			errJson := jsonx.DefaultJson(constant.INVALID_PARAM)
-			errJson.Message = "参数值不符合要求: " + setting.Name
+			errJson.Message = i18n.Translatef("param.invalid", locale, setting.Name)
 			return errJson
 		}
 	case "lte":
@@ -404,7 +469,7 @@ func numberParamValidate(
 		rangeVal := cast.ToFloat64(rangeVals[0])
 		if paramFloat > rangeVal {
 			errJson := jsonx.DefaultJson(constant.INVALID_PARAM)
-			errJson.Message = "参数值不符合要求: " + setting.Name
+			errJson.Message = i18n.Translatef("param.invalid", locale, setting.Name)
 			return errJson
 		}
 	case "range":
@@ -416,7 +481,7 @@ func numberParamValidate(
 		max := cast.ToFloat64(rangeVals[1])
 		if paramFloat <= min || paramFloat >= max {
 			errJson := jsonx.DefaultJson(constant.INVALID_PARAM)
-			errJson.Message = "参数值不符合要求: " + setting.Name
+			errJson.Message = i18n.Translatef("param.invalid", locale, setting.Name)
 			return errJson
 		}
 	case "eq_range":
@@ -428,7 +493,7 @@ func numberParamValidate(
 		max := cast.ToFloat64(rangeVals[1])
 		if paramFloat < min || paramFloat > max {
 			errJson := jsonx.DefaultJson(constant.INVALID_PARAM)
-			errJson.Message = "参数值不符合要求: " + setting.Name
+			errJson.Message = i18n.Translatef("param.invalid", locale, setting.Name)
 			return errJson
 		}
 	case "out":
@@ -440,7 +505,7 @@ func numberParamValidate(
 		max := cast.ToFloat64(rangeVals[1])
 		if paramFloat >= min && paramFloat <= max {
 			errJson := jsonx.DefaultJson(constant.INVALID_PARAM)
-			errJson.Message = "参数值不符合要求: " + setting.Name
+			errJson.Message = i18n.Translatef("param.invalid", locale, setting.Name)
 			return errJson
 		}
 	case "eq_out":
@@ -452,7 +517,7 @@ func numberParamValidate(
 		max := cast.ToFloat64(rangeVals[1])
 		if paramFloat > min && paramFloat < max {
 			errJson := jsonx.DefaultJson(constant.INVALID_PARAM)
-			errJson.Message = "参数值不符合要求: " + setting.Name
+			errJson.Message = i18n.Translatef("param.invalid", locale, setting.Name)
 			return errJson
 		}
 	default:
@@ -465,6 +530,7 @@ func booleanParamValidate(
 	setting *core.EventParam,
 	param interface{},
 	event *core.Event,
+	locale string,
 ) *jsonx.JsonResponse {
 	if param == true || param == false {
 		return nil
@@ -474,7 +540,7 @@ func booleanParamValidate(
 		return nil
 	}
 	errJson := jsonx.DefaultJson(constant.INVALID_PARAM)
-	errJson.Message = "参数值不是有效的布尔值: " + setting.Name
+	errJson.Message = i18n.Translatef("param.boolean", locale, setting.Name)
 	return errJson
 }
 
@@ -485,23 +551,24 @@ func customParamValidate(
 	event *core.Event,
 	ctx types.WorkerContext,
 ) *jsonx.JsonResponse {
+	locale := ctx.Locale()
 	attr := core.FindAttrFromArray(setting.Name, entityAttrs)
 	if attr == nil {
 		errJson := jsonx.DefaultJson(constant.INVALID_PARAM)
-		errJson.Message = "自定义字段的实体属性不存在: " + setting.Name
+		errJson.Message = i18n.Translatef("param.custom_attr_missing", locale, setting.Name)
 		return errJson
 	}
 	if parser, ok := ctx.Server().Repo().GetCustomFieldParser(attr.ValueSource); ok && parser != nil {
 		if err := parser.Validate(param); err != nil {
 			errJson := jsonx.DefaultJson(constant.INVALID_PARAM)
-			errJson.Message = "参数值不符合要求: " + setting.Name
+			errJson.Message = i18n.Translatef("param.invalid", locale, setting.Name)
 			return errJson
 		}
 		return nil
 	} else {
 		logx.Log().Warn(event.GetFullEventLabel() + "未找到自定义字段的解析器: " + setting.Name)
 		errJson := jsonx.DefaultJson(constant.INVALID_PARAM)
-		errJson.Message = "未找到自定义字段的解析器: " + setting.Name
+		errJson.Message = i18n.Translatef("param.custom_parser_missing", locale, setting.Name)
 		return errJson
 	}
 }
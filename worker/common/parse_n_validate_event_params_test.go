@@ -0,0 +1,166 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/i18n"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+func TestUrlParamValidateDomainWhitelist(t *testing.T) {
+	setting := &core.EventParam{Name: "avatar", Type: "url", Range: "domain", RangeValue: "example.com, cdn.test.io"}
+	event := &core.Event{}
+	cases := map[string]bool{
+		"https://example.com/a.png":      true,
+		"https://img.example.com/a.png":  true,
+		"https://cdn.test.io:8443/a.png": true,
+		"https://evil-example.com/a.png": false,
+		"https://example.com.evil.io/a":  false,
+		"https://test.io/a.png":          false,
+		"not a url":                      false,
+	}
+	for u, ok := range cases {
+		if got := urlParamValidate(setting, u, event, i18n.DEFAULT_LOCALE) == nil; got != ok {
+			t.Errorf("%s: expected valid=%v, got %v", u, ok, got)
+		}
+	}
+	// 未设置白名单时仅校验URL格式
+	if urlParamValidate(&core.EventParam{Name: "link", Type: "url"}, "https://any.io", event, i18n.DEFAULT_LOCALE) != nil {
+		t.Fatal("expected url without whitelist to be valid")
+	}
+}
+
+func TestPhoneParamValidateCountryCodes(t *testing.T) {
+	setting := &core.EventParam{Name: "mobile", Type: "phone", RangeValue: "+1,+44"}
+	event := &core.Event{}
+	cases := map[string]bool{
+		"+12025550123":  true,
+		"+447911123456": true,
+		"13800138000":   false,
+		"+33612345678":  false,
+		"+44abc":        false,
+	}
+	for phone, ok := range cases {
+		if got := phoneParamValidate(setting, phone, event, i18n.DEFAULT_LOCALE) == nil; got != ok {
+			t.Errorf("%s: expected valid=%v, got %v", phone, ok, got)
+		}
+	}
+	// 未设置国家代码白名单时保持默认的中国手机号规则
+	if phoneParamValidate(&core.EventParam{Name: "mobile", Type: "phone"}, "13800138000", event, i18n.DEFAULT_LOCALE) != nil {
+		t.Fatal("expected phone without allowlist to be valid")
+	}
+}
+
+func TestParseAndValidateParamsMaxParamSize(t *testing.T) {
+	ws := types.NewMockWorkerServer(nil)
+	defer ws.Stop()
+	entity := types.PathToEntity{Project: "shop", Version: "v1", Context: "user", Entity: "profile"}
+	ws.SetEntityAttrs(entity, []core.EntityAttribute{{Code: "avatar", FieldType: "string"}})
+	ws.SetEntityEvents(entity, []core.EntityEvent{
+		{Code: "limited", Params: `[{"name":"avatar","type":"string","range":"length","rangeValue":"1,20480"}]`, MaxParamSize: 5 * 1024},
+		{Code: "unlimited", Params: `[{"name":"avatar","type":"string","range":"length","rangeValue":"1,20480"}]`},
+	})
+	params := `{"avatar":"` + strings.Repeat("a", 10*1024) + `"}`
+	newCtx := func(code string) *types.MockRequestContext {
+		return types.NewMockRequestContext(ws, &core.Event{
+			Project: entity.Project, Version: entity.Version, Context: entity.Context, Entity: entity.Entity,
+			Event: code, Params: params,
+		})
+	}
+
+	ctx := newCtx("limited")
+	_, _, _, result := ParseAndValidateParams(ctx)
+	if result == nil || result.Code != string(constant.PAYLOAD_TOO_LARGE) {
+		t.Fatalf("expected payload too large, got %+v", result)
+	}
+	if ctx.StatusCode() != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status 413, got %d", ctx.StatusCode())
+	}
+
+	// MaxParamSize 为0时不限制
+	if _, _, parsed, result := ParseAndValidateParams(newCtx("unlimited")); result != nil || len(parsed["avatar"].(string)) != 10*1024 {
+		t.Fatalf("unlimited event should accept large params, got %+v", result)
+	}
+}
+
+func TestParseAndValidateParamsLocale(t *testing.T) {
+	ws := types.NewMockWorkerServer(nil)
+	defer ws.Stop()
+	entity := types.PathToEntity{Project: "shop", Version: "v1", Context: "user", Entity: "profile"}
+	ws.SetEntityAttrs(entity, []core.EntityAttribute{{Code: "email", FieldType: "string"}})
+	ws.SetEntityEvents(entity, []core.EntityEvent{
+		{Code: "bind", Params: `[{"name":"email","type":"email"}]`},
+	})
+	validate := func(locale, params string) string {
+		ctx := types.NewMockRequestContext(ws, &core.Event{
+			Project: entity.Project, Version: entity.Version, Context: entity.Context, Entity: entity.Entity,
+			Event: "bind", Params: params,
+		})
+		if locale != "" {
+			ctx.SetRequestHeader("Accept-Language", locale)
+		}
+		_, _, _, result := ParseAndValidateParams(ctx)
+		if result == nil {
+			t.Fatalf("expected validation error for %s", params)
+		}
+		return result.Message
+	}
+
+	cases := []struct {
+		locale, params, want string
+	}{
+		{"", `{"email":"bad"}`, "参数值不是有效的Email地址: email"},
+		{"zh-CN,zh;q=0.9", `{"email":"bad"}`, "参数值不是有效的Email地址: email"},
+		{"en-US,en;q=0.9", `{"email":"bad"}`, "Parameter value is not a valid email address: email"},
+		{"zh-CN", `not json`, "无效的参数"},
+		{"en", `not json`, "Invalid parameter"},
+	}
+	for _, c := range cases {
+		if got := validate(c.locale, c.params); got != c.want {
+			t.Errorf("locale %q: got %q, want %q", c.locale, got, c.want)
+		}
+	}
+}
+
+func TestStringParamValidateRegex(t *testing.T) {
+	setting := &core.EventParam{Name: "zip", Type: "string", Range: "regex", RangeValue: `^\d{6}$`}
+	event := &core.Event{}
+	cases := map[string]bool{
+		"100080":  true,
+		"10008":   false,
+		"1000800": false,
+		"abcdef":  false,
+	}
+	for v, ok := range cases {
+		errJson := stringParamValidate(setting, v, event, i18n.DEFAULT_LOCALE)
+		if got := errJson == nil; got != ok {
+			t.Errorf("%s: expected valid=%v, got %v", v, ok, got)
+		}
+		if errJson != nil && errJson.Code != string(constant.INVALID_PARAM) {
+			t.Errorf("%s: unexpected code %s", v, errJson.Code)
+		}
+	}
+	// 表达式设置错误时跳过校验
+	bad := &core.EventParam{Name: "zip", Type: "string", Range: "regex", RangeValue: `(`}
+	if stringParamValidate(bad, "any", event, i18n.DEFAULT_LOCALE) != nil {
+		t.Fatal("invalid pattern setting should not reject the param")
+	}
+}
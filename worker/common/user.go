@@ -24,7 +24,6 @@ import (
 	"github.com/garrickvan/event-matrix/utils/logx"
 	"github.com/garrickvan/event-matrix/worker/intranet/dispatcher"
 	"github.com/garrickvan/event-matrix/worker/types"
-	"github.com/spf13/cast"
 )
 
 // 获取用户ID，如果需要认证，则验证用户认证，否则从事件中获取用户ID
@@ -35,23 +34,49 @@ func GetUserId(ctx types.WorkerContext, event *core.Event, needAuth bool, ignore
 	return tryGetUserId(ctx, event), constant.SUCCESS
 }
 
-// 从事件中获取用户code，并根据code获取用户ID
+// jwtSecretProvider 提供本地验证访问令牌所需的HMAC密钥
+type jwtSecretProvider interface {
+	JwtSecret() []byte
+}
+
+// AccessToken 返回请求携带的访问令牌，优先使用事件中的令牌，其次使用 Authorization 请求头中的 Bearer 令牌
+func AccessToken(ctx types.WorkerContext, event *core.Event) string {
+	if event != nil && event.AccessToken != "" {
+		return event.AccessToken
+	}
+	if ctx == nil {
+		return ""
+	}
+	auth := strings.TrimSpace(ctx.Header("Authorization"))
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	return ""
+}
+
+// 从访问令牌中获取用户ID
+// 只信任配置了JWT签名密钥并在本地验证通过的令牌，未配置密钥时无法验证令牌，返回空
 func tryGetUserId(ctx types.WorkerContext, event *core.Event) string {
-	userId := ""
-	if event == nil {
-		return userId
+	token := AccessToken(ctx, event)
+	if token == "" {
+		return ""
+	}
+	var secret []byte
+	if sp, ok := ctx.Server().(jwtSecretProvider); ok {
+		secret = sp.JwtSecret()
+	}
+	if len(secret) == 0 {
+		return ""
+	}
+	claims, err := jsonx.ParseJwtToken(token, secret)
+	if err != nil {
+		logx.Debug("访问令牌验证失败: " + err.Error())
+		return ""
 	}
-	if event.AccessToken != "" {
-		claims, err := jsonx.GetJwtTokenClaims(event.AccessToken)
-		if err != nil && claims != nil {
-			ucode := cast.ToString(claims["u"])
-			ucode = strings.TrimSpace(ucode)
-			if ucode != "" {
-				userId = dispatcher.GetUserIdByCode(ucode)
-			}
-		}
+	if uid := claims.UserId(); uid != "" {
+		return uid
 	}
-	return userId
+	return dispatcher.GetUserIdByCode(claims.UserCode())
 }
 
 // 验证用户认证
@@ -59,6 +84,9 @@ func verifyUserAuth(ctx types.WorkerContext, e *core.Event, ignoreExpired bool)
 	inType := types.W_T_G_VERIFY_EVENT
 	if ignoreExpired {
 		inType = types.W_T_G_VERIFY_EVENT_WITHOUT_EXPIRED
+	} else if ctx.EntityEvent().IsEventExpired(e) {
+		// 超过事件有效期的请求无需再请求网关验证
+		return "", constant.EVENT_TIMEOUT
 	}
 	resp, err := dispatcher.Event(ctx.Server().GatewayIntranetEndpoint(), inType, e.Raw(), ctx)
 	if err != nil {
@@ -0,0 +1,82 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+func signTestToken(payload, secret string) string {
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`)) +
+		"." + base64.RawURLEncoding.EncodeToString([]byte(payload))
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestGetUserIdFromVerifiedToken(t *testing.T) {
+	ws := types.NewMockWorkerServer(&types.WorkerServerConfig{ServerId: "mock-worker", JwtCfgKey: "jwt"})
+	defer ws.Stop()
+	ws.SetSharedConfigure(&core.SharedConfigure{Key: "jwt", Type: core.JWT_HMAC, Value: `{"secret":"s3cret"}`})
+	token := signTestToken(`{"uid":"user-1"}`, "s3cret")
+
+	// 事件中的访问令牌
+	ctx := types.NewMockRequestContext(ws, &core.Event{AccessToken: token})
+	if uid, code := GetUserId(ctx, &core.Event{AccessToken: token}, false, false); uid != "user-1" || code != constant.SUCCESS {
+		t.Errorf("event token: uid = %q, code = %s", uid, code)
+	}
+
+	// Authorization 请求头中的 Bearer 令牌
+	ctx = types.NewMockRequestContext(ws, &core.Event{})
+	ctx.SetRequestHeader("Authorization", "Bearer "+token)
+	if uid, _ := GetUserId(ctx, &core.Event{}, false, false); uid != "user-1" {
+		t.Errorf("bearer token: uid = %q", uid)
+	}
+
+	// 签名不匹配的令牌不能获取用户ID
+	forged := signTestToken(`{"uid":"admin"}`, "guess")
+	if uid, _ := GetUserId(ctx, &core.Event{AccessToken: forged}, false, false); uid != "" {
+		t.Errorf("forged token: uid = %q", uid)
+	}
+
+	// 未配置签名密钥时无法验证令牌，不获取用户ID
+	noSecret := types.NewMockWorkerServer(nil)
+	defer noSecret.Stop()
+	ctx = types.NewMockRequestContext(noSecret, &core.Event{AccessToken: token})
+	if uid, _ := GetUserId(ctx, &core.Event{AccessToken: token}, false, false); uid != "" {
+		t.Errorf("unverified token: uid = %q", uid)
+	}
+}
+
+func TestVerifyUserAuthExpiredEvent(t *testing.T) {
+	ws := types.NewMockWorkerServer(nil)
+	defer ws.Stop()
+	event := &core.Event{CreatedAt: utils.GetNowMilli() - 60_000}
+	ctx := types.NewMockRequestContext(ws, event)
+	ctx.SetEntityEvent(&core.EntityEvent{Timeout: 30})
+
+	// 超过有效期时不请求网关，直接返回超时
+	if uid, code := GetUserId(ctx, event, true, false); uid != "" || code != constant.EVENT_TIMEOUT {
+		t.Errorf("expired event: uid = %q, code = %s", uid, code)
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/types"
+	"gopkg.in/yaml.v3"
+)
+
+// CONFIG_FILE_RELOAD_DELAY 配置文件变更后延迟加载的时间
+const CONFIG_FILE_RELOAD_DELAY = 200 * time.Millisecond
+
+// NewTwoWayWorkerServerFromFile 从本地YAML配置文件创建TwoWayWorkerServer，不依赖配置中心
+// intranetSettings 中的内域密钥、加密算法、网关地址不为空时覆盖配置文件中的同名配置
+// 创建后会监听配置文件，文件变更时热更新可变配置项，加载失败会触发panic
+func NewTwoWayWorkerServerFromFile(yamlPath string, intranetSettings TwoWayWorkerServerSettings) *TwoWayWorkerServer {
+	cfg, err := loadWorkerServerConfigFile(yamlPath, &intranetSettings)
+	if err != nil {
+		panic("WorkerServer配置文件加载失败: " + err.Error())
+	}
+	ws := newWorkerServerFromConfig(cfg, &intranetSettings, nil)
+	if err := ws.watchConfigFile(yamlPath); err != nil {
+		logx.Error("监听WorkerServer配置文件失败: " + err.Error())
+	}
+	return ws
+}
+
+// loadWorkerServerConfigFile 读取并解析YAML配置文件，补充默认值后返回
+func loadWorkerServerConfigFile(yamlPath string, s *TwoWayWorkerServerSettings) (*types.WorkerServerConfig, error) {
+	data, err := os.ReadFile(yamlPath)
+	if err != nil {
+		return nil, err
+	}
+	cfg := types.WorkerServerConfig{}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.PublicHost == "" || cfg.PublicPort == 0 {
+		return nil, errors.New("配置文件中[public_host、public_port]不能为空")
+	}
+	if s != nil {
+		if s.IntranetSecret != "" {
+			cfg.IntranetSecret = s.IntranetSecret
+		}
+		if s.IntranetSecretAlgor != "" {
+			cfg.IntranetSecretAlgor = s.IntranetSecretAlgor
+		}
+		if s.GatewayIntranetEndpoint != "" {
+			cfg.GatewayIntranetEndpoint = s.GatewayIntranetEndpoint
+		}
+	}
+	types.PatchWorkerServerConfig(&cfg)
+	if err := cfg.CheckPublicTLS(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// watchConfigFile 监听配置文件所在目录，兼容编辑器先删除再创建的保存方式
+func (s *TwoWayWorkerServer) watchConfigFile(yamlPath string) error {
+	absPath, err := filepath.Abs(yamlPath)
+	if err != nil {
+		return err
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := watcher.Add(filepath.Dir(absPath)); err != nil {
+		watcher.Close()
+		return err
+	}
+	s.cfgWatcher = watcher
+	go func() {
+		// 合并短时间内的多次写入，避免读到写了一半的文件
+		var debounce <-chan time.Time
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != absPath {
+					continue
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create) != 0 {
+					debounce = time.After(CONFIG_FILE_RELOAD_DELAY)
+				}
+			case <-debounce:
+				debounce = nil
+				if err := s.reloadConfigFile(absPath); err != nil {
+					logx.Error("热更新WorkerServer配置失败: " + err.Error())
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logx.Error("监听WorkerServer配置文件出错: " + err.Error())
+			}
+		}
+	}()
+	return nil
+}
+
+// reloadConfigFile 重新加载配置文件，只更新运行期间可安全修改的配置项
+// 端口、密钥、缓存容量等需要重启才能生效的配置项变更会被忽略
+// 更新时复制当前配置并整体替换，避免与读取配置的请求协程并发读写
+func (s *TwoWayWorkerServer) reloadConfigFile(yamlPath string) error {
+	newCfg, err := loadWorkerServerConfigFile(yamlPath, nil)
+	if err != nil {
+		return err
+	}
+	old := s.Cfg()
+	cfg := *old
+	if newCfg.LogLevel != cfg.LogLevel {
+		cfg.LogLevel = newCfg.LogLevel
+		logx.SetRuntimeLogLevel(cfg.LogLevel)
+	}
+	cfg.HeartbeatReportGap = newCfg.HeartbeatReportGap
+	cfg.NotAcceptUpdateRecordEventFromGateway = newCfg.NotAcceptUpdateRecordEventFromGateway
+	s.cfg.Store(&cfg)
+	if cfg.HeartbeatReportGap != old.HeartbeatReportGap {
+		s.resetHeartbeat()
+	}
+	logx.Info("WorkerServer配置已热更新: " + yamlPath)
+	return nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/garrickvan/event-matrix/constant"
+)
+
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("写入配置文件失败: %v", err)
+	}
+}
+
+func TestNewTwoWayWorkerServerFromFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "worker.yaml")
+	writeConfigFile(t, path, `
+server_id: worker-yaml
+work_mode: query
+public_host: 127.0.0.1
+public_port: 18080
+intranet_port: 18081
+intranet_secret: file-secret
+log_level: info
+log_location: `+filepath.Join(dir, "logs")+`
+heartbeat_report_gap: 30
+`)
+	ws := NewTwoWayWorkerServerFromFile(path, TwoWayWorkerServerSettings{IntranetSecret: "settings-secret"})
+	if ws.cfgWatcher == nil {
+		t.Fatal("应监听配置文件")
+	}
+	// 关闭监听，下面直接调用热更新避免与监听协程并发修改配置
+	ws.cfgWatcher.Close()
+
+	cfg := ws.Cfg()
+	if cfg.ServerId != "worker-yaml" || cfg.PublicPort != 18080 || cfg.IntranetPort != 18081 {
+		t.Fatalf("配置文件解析错误: %+v", cfg)
+	}
+	if cfg.WorkMode != string(constant.QUERY_MODE) || cfg.Version != "0.0.1" {
+		t.Errorf("未补充默认配置: work_mode=%s version=%s", cfg.WorkMode, cfg.Version)
+	}
+	if cfg.IntranetSecret != "settings-secret" {
+		t.Errorf("内域密钥应以启动设置为准, got %s", cfg.IntranetSecret)
+	}
+
+	// 修改可变配置项和不可变配置项
+	writeConfigFile(t, path, `
+server_id: worker-yaml
+public_host: 127.0.0.1
+public_port: 19090
+log_level: warn
+log_location: `+filepath.Join(dir, "logs")+`
+heartbeat_report_gap: 90
+not_accept_update_record_event_from_gateway: true
+`)
+	old := cfg
+	if err := ws.reloadConfigFile(path); err != nil {
+		t.Fatalf("热更新失败: %v", err)
+	}
+	cfg = ws.Cfg()
+	if old.HeartbeatReportGap != 30 || old.LogLevel != "info" {
+		t.Errorf("热更新应替换配置，不应修改原配置: %+v", old)
+	}
+	select {
+	case <-ws.heartbeatReset:
+	default:
+		t.Error("心跳间隔变更时应通知重置心跳定时器")
+	}
+	if cfg.HeartbeatReportGap != 90 || !cfg.NotAcceptUpdateRecordEventFromGateway || cfg.LogLevel != "warn" {
+		t.Fatalf("可变配置项未热更新: %+v", cfg)
+	}
+	if cfg.PublicPort != 18080 {
+		t.Errorf("端口不应热更新, got %d", cfg.PublicPort)
+	}
+}
+
+func TestLoadWorkerServerConfigFileInvalid(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := loadWorkerServerConfigFile(filepath.Join(dir, "missing.yaml"), nil); err == nil {
+		t.Error("配置文件不存在时应返回错误")
+	}
+	path := filepath.Join(dir, "worker.yaml")
+	writeConfigFile(t, path, "server_id: worker-yaml\n")
+	if _, err := loadWorkerServerConfigFile(path, nil); err == nil {
+		t.Error("缺少公网地址时应返回错误")
+	}
+	writeConfigFile(t, path, "public_port: [\n")
+	if _, err := loadWorkerServerConfigFile(path, nil); err == nil {
+		t.Error("YAML格式错误时应返回错误")
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/serverx"
+	"github.com/garrickvan/event-matrix/serverx/gnetx"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/common"
+	"github.com/garrickvan/event-matrix/worker/intranet/dispatcher"
+	"github.com/garrickvan/event-matrix/worker/ruleengine"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+func TestEventProcessedHook(t *testing.T) {
+	type call struct {
+		event      *core.Event
+		statusCode int
+		durationMs int64
+	}
+	calls := make(chan call, 2)
+
+	ws := &TwoWayWorkerServer{}
+	ws.RegisterEventProcessedHook(func(event *core.Event, statusCode int, durationMs int64) {
+		calls <- call{event, statusCode, durationMs}
+	})
+	ws.RegisterEventProcessedHook(func(event *core.Event, statusCode int, durationMs int64) {
+		panic("hook panic should not affect other hooks")
+	})
+	ws.RegisterEventProcessedHook(nil)
+
+	event := &core.Event{Project: "p", Context: "c", Entity: "e", Event: "query"}
+	start := time.Now().Add(-50 * time.Millisecond)
+	common.NotifyEventProcessed(ws, event, http.StatusOK, start)
+
+	select {
+	case c := <-calls:
+		if c.event != event || c.statusCode != http.StatusOK {
+			t.Fatalf("unexpected hook arguments: %+v", c)
+		}
+		if c.durationMs < 50 {
+			t.Fatalf("expected duration >= 50ms, got %d", c.durationMs)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("hook was not called")
+	}
+}
+
+// newRegisterTestServer 创建可注册工作者的服务，网关注册请求由 net.Pipe 模拟，实体为 rejected 时网关拒绝注册
+func newRegisterTestServer(t *testing.T) *TwoWayWorkerServer {
+	logx.InitRuntimeLogger(t.TempDir(), "error", "register-test", time.Hour)
+	dispatcher.InitClient(10, 10*time.Second, 2*time.Second, "127.0.0.1:10001", "127.0.0.1", "", "NONE", false)
+	dispatcher.SetDialer(func(endpoint string) (net.Conn, error) {
+		client, server := net.Pipe()
+		go gnetx.ServeConn(server, "", "NONE", func(req serverx.RequestPacket) serverx.ResponsePacket {
+			payload := string(constant.SUCCESS)
+			if strings.Contains(req.TemporaryData(), `"entity":"rejected"`) {
+				payload = "rejected"
+			}
+			return &gnetx.ResponsePacketImpl{StatusCode: http.StatusOK, ContentType: serverx.CONTENT_TYPE_STRING, Payload: payload}
+		})
+		return client, nil
+	})
+	ws := &TwoWayWorkerServer{
+		cfgKey:             "register-test",
+		workerIds:          map[string]bool{},
+		entityMapToWorkers: map[string]*types.Worker{},
+		failedWorkers:      map[string]*types.Worker{},
+		routers:            map[string]types.WorkerExecutor{},
+		domainCache:        types.NewMockWorkerServer(nil).DomainCache(),
+	}
+	ws.cfg.Store(&types.WorkerServerConfig{ServerId: "register-test", WorkMode: "C"})
+	ws.ruleEngineMgr = ruleengine.NewRuleEngineManager(ws)
+	return ws
+}
+
+func TestOnWorkerRegistered(t *testing.T) {
+	ws := newRegisterTestServer(t)
+	var called []string
+	ws.OnWorkerRegistered(func(w *types.Worker) {
+		called = append(called, w.Entity)
+	})
+	ws.OnWorkerRegistered(func(w *types.Worker) {
+		panic("hook panic should not unregister worker")
+	})
+	ws.OnWorkerRegistered(nil)
+
+	order := types.NewWorker("shop", "1.0.0", "order", "item", "", 0)
+	order.SyncSchema = false
+	if err := ws.RegisterWorker(order); err != nil {
+		t.Fatal(err)
+	}
+	rejected := types.NewWorker("shop", "1.0.0", "order", "rejected", "", 0)
+	rejected.SyncSchema = false
+	if err := ws.RegisterWorker(rejected); err == nil {
+		t.Fatal("网关拒绝时注册应失败")
+	}
+
+	if len(called) != 1 || called[0] != "item" {
+		t.Fatalf("回调应仅在注册成功时调用一次: %v", called)
+	}
+	if !ws.HasWorker(order.ID) {
+		t.Error("回调异常不应影响工作者注册")
+	}
+}
+
+func TestOnEvent(t *testing.T) {
+	ws := &TwoWayWorkerServer{}
+	for _, label := range []string{"", "demo.shop->create", "demo.shop.order", "demo..order->create", "demo.shop.order->create@"} {
+		if err := ws.OnEvent(label, func(*types.EventHookSnapshot) {}); err == nil {
+			t.Fatalf("标签 %q 应注册失败", label)
+		}
+	}
+	if err := ws.OnEvent("demo.shop.order->create", nil); err == nil {
+		t.Fatal("空回调应注册失败")
+	}
+
+	called := make(chan string, 10)
+	register := func(label string) {
+		if err := ws.OnEvent(label, func(*types.EventHookSnapshot) { called <- label }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	register("demo.*.*->*")
+	register("demo.shop.order->create@v1")
+	register("demo.shop.order->create@v2")
+	register("demo.shop.order->update")
+	if err := ws.OnEvent("*.*.*->*", func(*types.EventHookSnapshot) { panic("hook panic should be recovered") }); err != nil {
+		t.Fatal(err)
+	}
+
+	mock := types.NewMockWorkerServer(nil)
+	t.Cleanup(func() { mock.Stop() })
+	newCtx := func(event string) types.WorkerContext {
+		return types.NewMockRequestContext(mock, &core.Event{
+			Project: "demo", Context: "shop", Entity: "order", Event: event, Version: "v1",
+		})
+	}
+	respond := func(code constant.RESPONSE_CODE, dryRun bool) types.WorkerExecutor {
+		return ws.withEventHooks(func(types.WorkerContext) (*jsonx.JsonResponse, int) {
+			resp := jsonx.DefaultJson(code)
+			resp.DryRun = dryRun
+			return resp, http.StatusOK
+		})
+	}
+
+	// 失败和试运行不触发回调
+	respond(constant.FAIL_TO_CREATE, false)(newCtx("create"))
+	respond(constant.SUCCESS, true)(newCtx("create"))
+	respond(constant.SUCCESS, false)(newCtx("create"))
+
+	got := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case label := <-called:
+			got[label] = true
+		case <-time.After(time.Second):
+			t.Fatalf("回调未全部执行: %v", got)
+		}
+	}
+	if !got["demo.*.*->*"] || !got["demo.shop.order->create@v1"] {
+		t.Fatalf("unexpected hooks called: %v", got)
+	}
+	select {
+	case label := <-called:
+		t.Fatalf("不匹配的回调被调用: %s", label)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestOnEventSnapshot(t *testing.T) {
+	ws := &TwoWayWorkerServer{}
+	snapshots := make(chan *types.EventHookSnapshot, 1)
+	if err := ws.OnEvent("demo.shop.order->create", func(snapshot *types.EventHookSnapshot) { snapshots <- snapshot }); err != nil {
+		t.Fatal(err)
+	}
+	mock := types.NewMockWorkerServer(nil)
+	t.Cleanup(func() { mock.Stop() })
+	event := &core.Event{Project: "demo", Context: "shop", Entity: "order", Event: "create", Version: "v1"}
+	ctx := types.NewMockRequestContext(mock, event)
+	ctx.SetUserId("u1")
+	ws.withEventHooks(func(types.WorkerContext) (*jsonx.JsonResponse, int) {
+		return jsonx.DefaultJson(constant.SUCCESS), http.StatusCreated
+	})(ctx)
+	// 请求结束后上下文被复用，不应影响回调收到的快照
+	event.Event = "update"
+
+	select {
+	case snapshot := <-snapshots:
+		if snapshot.Event == event || snapshot.Event.Event != "create" {
+			t.Errorf("回调应收到事件副本: %+v", snapshot.Event)
+		}
+		if snapshot.UserId != "u1" || snapshot.StatusCode != http.StatusCreated {
+			t.Errorf("快照缺少用户ID或状态码: %+v", snapshot)
+		}
+		if snapshot.Response == nil || snapshot.Response.Code != string(constant.SUCCESS) {
+			t.Errorf("快照缺少响应: %+v", snapshot.Response)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("回调未执行")
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/garrickvan/event-matrix/worker/public/hertzimpl"
+)
+
+// healthPublicServer 支持注册健康检查路径的公网服务
+type healthPublicServer interface {
+	RegisterHealthEndpoint(path string, check func() *hertzimpl.HealthReport) error
+}
+
+// RegisterHealthEndpoint 在公网服务上注册健康检查路径，path 为空时使用 /healthz
+// 服务创建时会自动注册默认路径，自定义的公网服务需实现 RegisterHealthEndpoint 方法
+func (s *TwoWayWorkerServer) RegisterHealthEndpoint(path string) error {
+	pub, ok := s.public.(healthPublicServer)
+	if !ok {
+		return errors.New("公网服务不支持注册健康检查路径")
+	}
+	return pub.RegisterHealthEndpoint(path, s.HealthReport)
+}
+
+// HealthReport 汇总服务器健康状态，存在注册失败的工作者时视为不健康
+func (s *TwoWayWorkerServer) HealthReport() *hertzimpl.HealthReport {
+	report := &hertzimpl.HealthReport{
+		Status:  hertzimpl.HEALTH_STATUS_OK,
+		Workers: len(s.workerIds),
+		Version: s.Cfg().Version,
+	}
+	// 失败列表中已重新注册成功的工作者不计入
+	for _, w := range s.failedWorkersSnapshot() {
+		if !s.HasWorker(w.ID) {
+			report.FailedWorkers = append(report.FailedWorkers, w.ID)
+		}
+	}
+	if len(report.FailedWorkers) > 0 {
+		sort.Strings(report.FailedWorkers)
+		report.Status = hertzimpl.HEALTH_STATUS_UNAVAILABLE
+		report.Reason = "工作者注册失败: " + strings.Join(report.FailedWorkers, ",")
+	}
+	return report
+}
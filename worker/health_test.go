@@ -0,0 +1,85 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/worker/public/hertzimpl"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+func TestRegisterHealthEndpoint(t *testing.T) {
+	ws, h := newMetricsTestServer(nil)
+	ws.Cfg().Version = "1.2.3"
+	ws.workerIds = map[string]bool{"w1": true, "w2": true}
+	ws.failedWorkers = map[string]*types.Worker{}
+	if err := ws.RegisterHealthEndpoint(""); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := ut.PerformRequest(h.Engine, "GET", hertzimpl.DEFAULT_HEALTH_PATH, nil).Result()
+	if resp.StatusCode() != 200 {
+		t.Fatalf("unexpected status %d", resp.StatusCode())
+	}
+	report := hertzimpl.HealthReport{}
+	if err := jsonx.UnmarshalFromBytes(resp.Body(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Status != hertzimpl.HEALTH_STATUS_OK || report.Workers != 2 || report.Version != "1.2.3" {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	// 已重新注册成功的工作者不视为失败
+	ws.addFailedWorker(&types.Worker{ID: "w1"})
+	ws.addFailedWorker(&types.Worker{ID: "w3"})
+	resp = ut.PerformRequest(h.Engine, "GET", hertzimpl.DEFAULT_HEALTH_PATH, nil).Result()
+	if resp.StatusCode() != 503 {
+		t.Fatalf("unexpected status %d", resp.StatusCode())
+	}
+	report = hertzimpl.HealthReport{}
+	if err := jsonx.UnmarshalFromBytes(resp.Body(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Status != hertzimpl.HEALTH_STATUS_UNAVAILABLE || report.Reason == "" ||
+		len(report.FailedWorkers) != 1 || report.FailedWorkers[0] != "w3" {
+		t.Fatalf("unexpected report %+v", report)
+	}
+}
+
+func TestHealthReportConcurrentFailedWorkers(t *testing.T) {
+	ws, _ := newMetricsTestServer(nil)
+	ws.workerIds = map[string]bool{}
+	ws.failedWorkers = map[string]*types.Worker{}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			id := fmt.Sprintf("w%d", i)
+			ws.addFailedWorker(&types.Worker{ID: id})
+			ws.remvoeFailedWorker(id)
+		}
+	}()
+	for i := 0; i < 200; i++ {
+		ws.HealthReport()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,108 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/garrickvan/event-matrix/utils"
+	"github.com/garrickvan/event-matrix/utils/loadtool"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/intranet/dispatcher"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+// heartbeatGap 心跳上报间隔，未配置或小于3秒时与注册时一致默认30秒
+func (s *TwoWayWorkerServer) heartbeatGap() time.Duration {
+	gap := s.Cfg().HeartbeatReportGap
+	if gap < 3 {
+		gap = 30
+	}
+	return time.Duration(gap) * time.Second
+}
+
+// startHeartbeat 按心跳间隔向网关上报负载率，Stop 时结束
+func (s *TwoWayWorkerServer) startHeartbeat() {
+	stop := make(chan struct{})
+	s.heartbeatStop = stop
+	reset := s.heartbeatReset
+	go func() {
+		ticker := time.NewTicker(s.heartbeatGap())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-reset:
+				ticker.Reset(s.heartbeatGap())
+			case <-ticker.C:
+				s.reportHeartbeat()
+			}
+		}
+	}()
+}
+
+// resetHeartbeat 通知心跳协程按新的心跳间隔重置定时器，未启动心跳时忽略
+func (s *TwoWayWorkerServer) resetHeartbeat() {
+	select {
+	case s.heartbeatReset <- struct{}{}:
+	default:
+	}
+}
+
+// stopHeartbeat 停止心跳上报
+func (s *TwoWayWorkerServer) stopHeartbeat() {
+	if s.heartbeatStop != nil {
+		close(s.heartbeatStop)
+		s.heartbeatStop = nil
+	}
+}
+
+// buildHeartbeat 读取当前负载率并更新到已注册的工作者，返回待上报的心跳
+func (s *TwoWayWorkerServer) buildHeartbeat() *types.WorkerHeartbeat {
+	workers := s.registeredWorkers()
+	if len(workers) == 0 {
+		return nil
+	}
+	heartbeat := &types.WorkerHeartbeat{
+		ServerId:  s.ServerId(),
+		WorkerIds: make([]string, 0, len(workers)),
+		LoadRate:  loadtool.CurrentLoadRate(),
+		Timestamp: utils.GetNowMilli(),
+	}
+	for _, w := range workers {
+		w.LoadRate = heartbeat.LoadRate
+		w.LastHeartbeat = heartbeat.Timestamp
+		heartbeat.WorkerIds = append(heartbeat.WorkerIds, w.ID)
+	}
+	return heartbeat
+}
+
+// reportHeartbeat 向网关上报一次心跳，失败只记录日志，等待下次上报
+func (s *TwoWayWorkerServer) reportHeartbeat() {
+	heartbeat := s.buildHeartbeat()
+	if heartbeat == nil {
+		return
+	}
+	resp, err := dispatcher.Event(s.Cfg().GatewayIntranetEndpoint, types.W_T_G_HEARTBEAT_WITH_LOAD, heartbeat, nil)
+	if err != nil {
+		logx.Warn("上报心跳到网关失败: " + err.Error())
+		return
+	}
+	if resp.Status() != http.StatusOK {
+		logx.Warn("上报心跳到网关失败: " + resp.TemporaryData())
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/serverx"
+	"github.com/garrickvan/event-matrix/serverx/gnetx"
+	"github.com/garrickvan/event-matrix/worker/intranet/dispatcher"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+func TestReportHeartbeat(t *testing.T) {
+	ws := newRegisterTestServer(t)
+	heartbeats := make(chan string, 1)
+	dispatcher.SetDialer(func(endpoint string) (net.Conn, error) {
+		client, server := net.Pipe()
+		go gnetx.ServeConn(server, "", "NONE", func(req serverx.RequestPacket) serverx.ResponsePacket {
+			if body := req.TemporaryData(); strings.Contains(body, `"worker_ids"`) {
+				heartbeats <- strings.Clone(body)
+			}
+			return &gnetx.ResponsePacketImpl{StatusCode: http.StatusOK, ContentType: serverx.CONTENT_TYPE_STRING, Payload: string(constant.SUCCESS)}
+		})
+		return client, nil
+	})
+
+	// 没有已注册的工作者时不上报
+	ws.reportHeartbeat()
+	select {
+	case body := <-heartbeats:
+		t.Fatalf("没有工作者时不应上报心跳: %s", body)
+	default:
+	}
+
+	w := types.NewWorker("shop", "1.0.0", "order", "item", "", 0)
+	w.SyncSchema = false
+	if err := ws.RegisterWorker(w); err != nil {
+		t.Fatal(err)
+	}
+	ws.reportHeartbeat()
+	select {
+	case body := <-heartbeats:
+		if !strings.Contains(body, w.ID) || !strings.Contains(body, `"load_rate"`) {
+			t.Errorf("心跳缺少工作者ID或负载率: %s", body)
+		}
+	default:
+		t.Fatal("应向网关上报心跳")
+	}
+	if w.LastHeartbeat == 0 {
+		t.Error("上报后应更新工作者的心跳时间")
+	}
+}
@@ -144,7 +144,12 @@ func OnUpdateRecordForDataMgrHandler(ctx types.WorkerContext, paramStr string) e
 			return ctx.SetStatus(http.StatusOK).ResponseBuiltinJson(constant.INVALID_PARAM)
 		}
 	} else {
-		val = attr.FixValue(param.NewValue)
+		fixed, err := attr.FixValue(param.NewValue)
+		if err != nil {
+			resp := jsonx.DefaultJsonWithMsg(constant.INVALID_PARAM, err.Error())
+			return ctx.SetStatus(http.StatusOK).ResponseJson(resp)
+		}
+		val = fixed
 	}
 	if err := db.Where("id = ?", param.RecordId).Updates(map[string]interface{}{param.FieldCode: val}).Error; err != nil {
 		logx.Log().Error("更新实体记录失败：" + err.Error())
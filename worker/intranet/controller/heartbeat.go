@@ -20,6 +20,7 @@ import (
 	"strings"
 
 	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/serverx/gnetx"
 	"github.com/garrickvan/event-matrix/utils/loadtool"
 	"github.com/garrickvan/event-matrix/worker/types"
 )
@@ -34,22 +35,46 @@ func checkWorkerHandler(ctx types.WorkerContext, params string) error {
 	}
 	results := []types.WorkerCheckResult{}
 	devicesLoadRate := loadtool.GetLoadRate()
+	// 汇总插件健康状态
+	pluginsHealth := ctx.Server().PluginsHealth()
+	healthy := true
+	for _, h := range pluginsHealth {
+		if !h.Healthy {
+			healthy = false
+			break
+		}
+	}
 	for _, needCheckId := range wids {
 		has := ctx.Server().HasWorker(needCheckId)
 		results = append(results, types.WorkerCheckResult{
 			WorkerId: needCheckId,
 			Exist:    has,
 			LoadRate: devicesLoadRate,
+			Healthy:  healthy,
+			Plugins:  pluginsHealth,
 		})
 	}
 	return ctx.SetStatus(http.StatusOK).ResponseJson(results)
 }
 
+// requestStatsProvider 提供按事件类型统计请求的上下文
+type requestStatsProvider interface {
+	RequestStats() []gnetx.EventTypeStats
+}
+
 /**
- * 获取设备负载率接口
+ * 获取设备负载率接口，默认仅返回负载率字符串，兼容旧版本网关
+ * 参数为 LOAD_RATE_REPORT_V2 时返回包含按事件类型统计的内域请求的 LoadRateReport
  */
 func getLoadRateHandler(ctx types.WorkerContext, params string) error {
 	loadRate := loadtool.GetLoadRate()
-	loadRateStr := strconv.FormatFloat(loadRate, 'f', -1, 64)
-	return ctx.SetStatus(http.StatusOK).ResponseString(loadRateStr)
+	if params != types.LOAD_RATE_REPORT_V2 {
+		loadRateStr := strconv.FormatFloat(loadRate, 'f', -1, 64)
+		return ctx.SetStatus(http.StatusOK).ResponseString(loadRateStr)
+	}
+	report := types.LoadRateReport{LoadRate: loadRate}
+	if provider, ok := ctx.(requestStatsProvider); ok {
+		report.RequestStats = provider.RequestStats()
+	}
+	return ctx.SetStatus(http.StatusOK).ResponseJson(report)
 }
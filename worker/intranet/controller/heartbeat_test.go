@@ -0,0 +1,45 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+func TestGetLoadRateHandlerFormats(t *testing.T) {
+	ws := types.NewMockWorkerServer(nil)
+
+	// 默认返回负载率字符串，兼容旧版本网关
+	ctx := types.NewMockRequestContext(ws, nil)
+	if err := RootRouter(types.G_T_W_GET_LOADE_RATE, "", ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := strconv.ParseFloat(string(ctx.ResponseBody()), 64); err != nil {
+		t.Fatalf("默认应返回负载率字符串: %s", ctx.ResponseBody())
+	}
+
+	ctx = types.NewMockRequestContext(ws, nil)
+	if err := RootRouter(types.G_T_W_GET_LOADE_RATE, types.LOAD_RATE_REPORT_V2, ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	report := types.LoadRateReport{}
+	if err := jsonx.UnmarshalFromBytes(ctx.ResponseBody(), &report); err != nil {
+		t.Fatalf("v2 应返回 LoadRateReport: %s", ctx.ResponseBody())
+	}
+}
@@ -16,6 +16,7 @@ package controller
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/garrickvan/event-matrix/constant"
 	"github.com/garrickvan/event-matrix/core"
@@ -74,9 +75,27 @@ func handleSharedConfigureChange(ctx types.WorkerContext, payload string) error
 	return handle(ctx.Server(), &cfg)
 }
 
-// resetDomainCacheHandler 重置域缓存
+// resetDomainCacheHandler 重置域缓存，payload 为实体路径参数时只失效该实体的缓存，
+// 为 types.ConstantCacheReset 的JSON时只失效常量缓存，为空时清空全部缓存
 func resetDomainCacheHandler(ctx types.WorkerContext, payload string) error {
-	logx.Debug("接收到重置缓存请求: " + ctx.Server().ServerId())
-	ctx.Server().DomainCache().Impl().Flush()
+	if payload == "" {
+		logx.Debug("接收到重置缓存请求: " + ctx.Server().ServerId())
+		ctx.Server().DomainCache().Impl().Flush()
+		return ctx.SetStatus(http.StatusOK).Response([]byte(constant.SUCCESS))
+	}
+	if strings.HasPrefix(payload, "{") {
+		reset := types.ConstantCacheReset{}
+		if err := jsonx.UnmarshalFromStr(payload, &reset); err != nil {
+			return ctx.SetStatus(http.StatusBadRequest).Response([]byte(constant.INVALID_PARAM))
+		}
+		logx.Debug("接收到常量缓存失效请求: " + ctx.Server().ServerId() + " " + payload)
+		ctx.Server().DomainCache().InvalidateConstants(reset.Project, reset.Dict)
+		return ctx.SetStatus(http.StatusOK).Response([]byte(constant.SUCCESS))
+	}
+	if p := types.PathToEntityFromStrArg(payload); p.IsIncomplete() {
+		return ctx.SetStatus(http.StatusBadRequest).Response([]byte(constant.INVALID_PARAM))
+	}
+	logx.Debug("接收到实体缓存失效请求: " + ctx.Server().ServerId() + " " + payload)
+	ctx.Server().DomainCache().Invalidate(payload)
 	return ctx.SetStatus(http.StatusOK).Response([]byte(constant.SUCCESS))
 }
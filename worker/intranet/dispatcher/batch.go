@@ -0,0 +1,155 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package dispatcher
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/garrickvan/event-matrix/serverx"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+// BATCH_EVENT_CONCURRENCY 批量发送事件时的最大并发数
+const BATCH_EVENT_CONCURRENCY = 16
+
+// ErrBatchEventTimeout 批量发送事件超时，超时时尚未完成的 endpoint 返回此错误
+var ErrBatchEventTimeout = errors.New("batch event timeout")
+
+// BatchEvent 并发向多个 endpoint 发送同一内部事件，用于缓存失效、配置变更等需要通知所有 worker 的场景
+//
+// 参数:
+//   - endpoints: 目标端点列表，重复的端点只发送一次
+//   - typz: 事件类型
+//   - strOrJson: 请求参数，字符串或可序列化为JSON的结构体
+//   - request: 请求上下文，用于传递调用链，可为空
+//
+// 返回值:
+//   - map[string]error: 发送失败的端点及其错误，响应状态码非200也视为失败，全部成功时为空
+func BatchEvent(endpoints []string, typz types.INTRANET_EVENT_TYPE, strOrJson interface{}, request serverx.RequestContext) map[string]error {
+	return BatchEventWithTimeout(endpoints, typz, strOrJson, request, 0)
+}
+
+// BatchEventWithTimeout 与 BatchEvent 相同，但限制整体耗时，timeout 小于等于0时不限制
+// 超时后尚未完成的端点记为 ErrBatchEventTimeout，已发出的请求不会被取消
+func BatchEventWithTimeout(endpoints []string, typz types.INTRANET_EVENT_TYPE, strOrJson interface{}, request serverx.RequestContext, timeout time.Duration) map[string]error {
+	errs := make(map[string]error)
+	pending := make(map[string]bool, len(endpoints))
+	jobs := make(chan string, len(endpoints))
+	for _, endpoint := range endpoints {
+		if pending[endpoint] {
+			continue
+		}
+		pending[endpoint] = true
+		jobs <- endpoint
+	}
+	close(jobs)
+	if len(pending) == 0 {
+		return errs
+	}
+
+	type batchResult struct {
+		endpoint string
+		err      error
+	}
+	results := make(chan batchResult, len(pending))
+	done := make(chan struct{})
+	var once sync.Once
+	stop := func() { once.Do(func() { close(done) }) }
+	defer stop()
+
+	workers := BATCH_EVENT_CONCURRENCY
+	if len(pending) < workers {
+		workers = len(pending)
+	}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for endpoint := range jobs {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				results <- batchResult{endpoint: endpoint, err: sendBatchEvent(endpoint, typz, strOrJson, request)}
+			}
+		}()
+	}
+
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	for len(pending) > 0 {
+		select {
+		case r := <-results:
+			delete(pending, r.endpoint)
+			if r.err != nil {
+				errs[r.endpoint] = r.err
+			}
+		case <-deadline:
+			stop()
+			for endpoint := range pending {
+				errs[endpoint] = ErrBatchEventTimeout
+			}
+			return errs
+		}
+	}
+	return errs
+}
+
+// sendBatchEvent 向单个端点发送事件，响应状态码非200时返回错误
+func sendBatchEvent(endpoint string, typz types.INTRANET_EVENT_TYPE, strOrJson interface{}, request serverx.RequestContext) error {
+	resp, err := Event(endpoint, typz, strOrJson, request)
+	if err != nil {
+		return err
+	}
+	if resp == nil {
+		return errors.New("response is nil")
+	}
+	if resp.Status() != http.StatusOK {
+		return fmt.Errorf("unexpected status %d: %s", resp.Status(), resp.TemporaryData())
+	}
+	return nil
+}
+
+// ResetDomainCache 通知多个 worker 重置领域缓存，返回重置失败的端点及其错误
+func ResetDomainCache(endpoints []string) map[string]error {
+	return BatchEvent(endpoints, types.G_T_W_RESET_DOMAIN_CACHE, "", nil)
+}
+
+// InvalidateConstantsCache 通知多个 worker 失效常量缓存，dict 为空时失效全部常量缓存（指定 project 时仅失效该项目的常量），
+// 常量字典更新后由网关调用，返回失效失败的端点及其错误
+func InvalidateConstantsCache(endpoints []string, project, dict string) map[string]error {
+	payload, err := jsonx.MarshalToStr(types.ConstantCacheReset{Project: project, Dict: dict})
+	if err != nil {
+		failed := make(map[string]error, len(endpoints))
+		for _, endpoint := range endpoints {
+			failed[endpoint] = err
+		}
+		return failed
+	}
+	return BatchEvent(endpoints, types.G_T_W_RESET_DOMAIN_CACHE, payload, nil)
+}
+
+// InvalidateDomainCache 通知多个 worker 失效指定实体的领域缓存，实体、属性或事件定义更新后由网关调用，
+// 返回失效失败的端点及其错误
+func InvalidateDomainCache(endpoints []string, p types.PathToEntity) map[string]error {
+	return BatchEvent(endpoints, types.G_T_W_RESET_DOMAIN_CACHE, p.ToStrArg(), nil)
+}
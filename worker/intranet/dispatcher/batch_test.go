@@ -0,0 +1,118 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package dispatcher
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/garrickvan/event-matrix/serverx"
+	"github.com/garrickvan/event-matrix/serverx/gnetx"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+const batchTestSecret = "batch-test-secret"
+
+// initBatchTestClient 初始化内域客户端，按 endpoint 模拟不同的 worker 行为
+func initBatchTestClient(t *testing.T, received *sync.Map) {
+	logx.InitRuntimeLogger(t.TempDir(), "error", "batch-test", time.Hour)
+	InitClient(10, 10*time.Second, 2*time.Second, "127.0.0.1:10001", "127.0.0.1", batchTestSecret, "aes-256", false)
+	SetDialer(func(endpoint string) (net.Conn, error) {
+		if endpoint == "127.0.0.1:9001" {
+			return nil, errors.New("connection refused")
+		}
+		client, server := net.Pipe()
+		go gnetx.ServeConn(server, batchTestSecret, "aes-256", func(req serverx.RequestPacket) serverx.ResponsePacket {
+			received.Store(endpoint, req.Extend())
+			switch endpoint {
+			case "127.0.0.1:9002":
+				return &gnetx.ResponsePacketImpl{StatusCode: http.StatusInternalServerError, ContentType: serverx.CONTENT_TYPE_STRING, Payload: "flush failed"}
+			case "127.0.0.1:9003":
+				time.Sleep(500 * time.Millisecond)
+			}
+			return &gnetx.ResponsePacketImpl{StatusCode: http.StatusOK, ContentType: serverx.CONTENT_TYPE_STRING, Payload: "SUCCESS"}
+		})
+		return client, nil
+	})
+}
+
+func TestBatchEvent(t *testing.T) {
+	received := &sync.Map{}
+	initBatchTestClient(t, received)
+	endpoints := []string{"127.0.0.1:8001", "127.0.0.1:8002", "127.0.0.1:8001", "127.0.0.1:9001", "127.0.0.1:9002"}
+
+	errs := ResetDomainCache(endpoints)
+	if len(errs) != 2 {
+		t.Fatalf("期望2个端点失败，实际 %v", errs)
+	}
+	if errs["127.0.0.1:9001"] == nil || errs["127.0.0.1:9002"] == nil {
+		t.Errorf("失败端点的错误未聚合: %v", errs)
+	}
+	for _, endpoint := range []string{"127.0.0.1:8001", "127.0.0.1:8002", "127.0.0.1:9002"} {
+		xdata, ok := received.Load(endpoint)
+		if !ok {
+			t.Errorf("端点 %s 未收到事件", endpoint)
+			continue
+		}
+		if xdata != strconv.Itoa(int(types.G_T_W_RESET_DOMAIN_CACHE)) {
+			t.Errorf("端点 %s 收到的事件类型错误: %v", endpoint, xdata)
+		}
+	}
+
+	if errs := BatchEvent(nil, types.G_T_W_RESET_DOMAIN_CACHE, "", nil); len(errs) != 0 {
+		t.Errorf("空端点列表不应返回错误: %v", errs)
+	}
+}
+
+func TestBatchEventWithTimeout(t *testing.T) {
+	initBatchTestClient(t, &sync.Map{})
+	start := time.Now()
+	errs := BatchEventWithTimeout([]string{"127.0.0.1:8001", "127.0.0.1:9003"}, types.G_T_W_RESET_DOMAIN_CACHE, "", nil, 100*time.Millisecond)
+	if time.Since(start) > 400*time.Millisecond {
+		t.Errorf("超时后应立即返回，实际耗时 %v", time.Since(start))
+	}
+	if len(errs) != 1 || !errors.Is(errs["127.0.0.1:9003"], ErrBatchEventTimeout) {
+		t.Fatalf("慢端点应返回超时错误: %v", errs)
+	}
+}
+
+func TestEventWithContext(t *testing.T) {
+	initBatchTestClient(t, &sync.Map{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err := EventWithContext(ctx, "127.0.0.1:8001", types.G_T_W_RESET_DOMAIN_CACHE, "", nil)
+	if err != nil || resp.Status() != http.StatusOK {
+		t.Fatalf("未超时应返回响应: %v %v", resp, err)
+	}
+
+	// 慢端点在截止时间到达时立即返回
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = EventWithContext(ctx, "127.0.0.1:9003", types.G_T_W_RESET_DOMAIN_CACHE, "", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("期望超时错误，实际 %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+		t.Errorf("超时后应立即返回，实际耗时 %v", elapsed)
+	}
+}
@@ -15,9 +15,11 @@
 package dispatcher
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"net"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/garrickvan/event-matrix/serverx"
@@ -59,13 +61,18 @@ func NewIntraServiceClient(
 
 // 向指定的 endpoint 发送 POST 请求，并对请求参数进行加密，响应数据进行解密
 func (c *IntraServiceClient) Post(endpoint string, typz types.INTRANET_EVENT_TYPE, params string, callChain []string) (response serverx.ResponsePacket, err error) {
+	return c.post(endpoint, types.IntranetXData{Type: typz}, params, callChain)
+}
+
+// post 携带扩展数据发送请求，扩展数据中可包含语言和链路追踪上下文
+func (c *IntraServiceClient) post(endpoint string, xd types.IntranetXData, params string, callChain []string) (response serverx.ResponsePacket, err error) {
 	paramsBytes := fastconv.StringToBytes(params)
 	cipherParamsBytes, err := encryptx.Encrypt(paramsBytes, c.secret, c.secretAlgo)
 	if err != nil {
 		logx.Debug("encrypt params failed", err)
 		return nil, err
 	}
-	response, err = c.client.Post(endpoint, serverx.CONTENT_TYPE_STRING, cipherParamsBytes, strconv.Itoa(int(typz)), callChain)
+	response, err = c.client.Post(endpoint, serverx.CONTENT_TYPE_STRING, cipherParamsBytes, xd.String(), callChain)
 	if err != nil {
 		logx.Debug("post request failed:", err, "endpoint:", endpoint)
 		return nil, err
@@ -106,6 +113,24 @@ func InitClient(
 	)
 }
 
+// 设置内域客户端的连接创建方法，需在 InitClient 之后调用，用于命令行工具和测试（如 net.Pipe）
+func SetDialer(dialer func(endpoint string) (net.Conn, error)) {
+	if _client == nil {
+		logx.Warn("client not initialized, dialer is ignored")
+		return
+	}
+	_client.client.SetDialer(dialer)
+}
+
+// 设置内域客户端单条请求消息的最大字节数，需在 InitClient 之后调用，应与对端内域服务的限制一致
+func SetMaxMessageSize(size int) {
+	if _client == nil {
+		logx.Warn("client not initialized, max message size is ignored")
+		return
+	}
+	_client.client.SetMaxMessageSize(size)
+}
+
 // 获取 IntraServiceClient 实例，如果没有初始化则创建一个默认实例
 func client() *IntraServiceClient {
 	if _client == nil {
@@ -130,7 +155,9 @@ func client() *IntraServiceClient {
 //   - err: 返回的错误信息，表示在请求过程中发生的任何错误。
 func Event(endpoint string, typz types.INTRANET_EVENT_TYPE, strOrJson interface{}, request serverx.RequestContext) (response serverx.ResponsePacket, err error) {
 	var chains []string
+	xd := types.IntranetXData{Type: typz}
 	if request != nil {
+		xd.SetTraceHeaders(traceHeaders(request))
 		chains = request.CallChain()
 		e := request.Event()
 		if e != nil {
@@ -146,16 +173,61 @@ func Event(endpoint string, typz types.INTRANET_EVENT_TYPE, strOrJson interface{
 	}
 	// WILLDO: 收集调用链信息，提供给 gateway 进行数据统计
 	if paramStr, ok := strOrJson.(string); ok {
-		return client().Post(endpoint, typz, paramStr, chains)
+		return client().post(endpoint, xd, paramStr, chains)
 	} else {
 		paramStr, err := jsonx.MarshalToStr(strOrJson)
 		if err != nil {
 			return nil, err
 		}
-		return client().Post(endpoint, typz, paramStr, chains)
+		return client().post(endpoint, xd, paramStr, chains)
 	}
 }
 
+// EventWithContext 与 Event 相同，但在 ctx 取消或超时时立即返回 ctx.Err()，
+// 已发出的请求不会被取消，其响应将被丢弃
+func EventWithContext(ctx context.Context, endpoint string, typz types.INTRANET_EVENT_TYPE, strOrJson interface{}, request serverx.RequestContext) (serverx.ResponsePacket, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	type eventResult struct {
+		response serverx.ResponsePacket
+		err      error
+	}
+	result := make(chan eventResult, 1)
+	go func() {
+		response, err := Event(endpoint, typz, strOrJson, request)
+		result <- eventResult{response: response, err: err}
+	}()
+	select {
+	case r := <-result:
+		return r.response, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// traceCarrier 携带链路追踪上下文的请求上下文，如工作上下文
+type traceCarrier interface {
+	TraceContext() context.Context
+}
+
+// traceHeaders 返回需要向下游传递的链路追踪请求头，存在未结束的 Span 时以该 Span 为父级，请求不属于任何链路时返回nil
+func traceHeaders(request serverx.RequestContext) map[string]string {
+	tc, ok := request.(traceCarrier)
+	if !ok {
+		return nil
+	}
+	return types.InjectTraceContext(tc.TraceContext())
+}
+
+// PoolStats 返回内域客户端各端点的连接池统计
+func PoolStats() map[string]gnetx.PoolStats {
+	if _client == nil {
+		return map[string]gnetx.PoolStats{}
+	}
+	return _client.client.Stats()
+}
+
 // 获取指定 endpoint 的负载均衡状态
 func EndpointLoadRate(endpoint string) float64 {
 	resp, err := client().Post(endpoint, types.G_T_W_GET_LOADE_RATE, "", nil)
@@ -167,3 +239,25 @@ func EndpointLoadRate(endpoint string) float64 {
 	}
 	return -1
 }
+
+// EndpointLoadRateReport 获取指定 endpoint 的负载率及按事件类型统计的内域请求
+// 旧版本工作端仅返回负载率，此时 RequestStats 为空
+func EndpointLoadRateReport(endpoint string) (*types.LoadRateReport, error) {
+	resp, err := client().Post(endpoint, types.G_T_W_GET_LOADE_RATE, types.LOAD_RATE_REPORT_V2, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Status() != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.Status(), resp.TemporaryData())
+	}
+	report := &types.LoadRateReport{}
+	if err := jsonx.UnmarshalFromStr(resp.TemporaryData(), report); err == nil {
+		return report, nil
+	}
+	rate, err := cast.ToFloat64E(resp.TemporaryData())
+	if err != nil {
+		return nil, err
+	}
+	report.LoadRate = rate
+	return report, nil
+}
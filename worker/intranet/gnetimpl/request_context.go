@@ -15,9 +15,13 @@
 package gnetimpl
 
 import (
+	"context"
+	"strings"
+
 	"github.com/garrickvan/event-matrix/core"
 	"github.com/garrickvan/event-matrix/serverx"
 	"github.com/garrickvan/event-matrix/serverx/gnetx"
+	"github.com/garrickvan/event-matrix/utils/i18n"
 	"github.com/garrickvan/event-matrix/utils/jsonx"
 	"github.com/garrickvan/event-matrix/worker/common"
 	"github.com/garrickvan/event-matrix/worker/types"
@@ -29,6 +33,9 @@ type WorkerIntranetRequestContext struct {
 
 	svr         *WorkerIntranetServer
 	uid         string                 // 用户ID
+	locale      string                 // 请求语言，来自请求扩展数据
+	xdata       types.IntranetXData    // 请求扩展数据
+	traceCtx    context.Context        // 链路追踪上下文，首次使用时提取
 	attrs       []core.EntityAttribute // 实体属性列表
 	eventParams []core.EventParam      // 事件参数列表
 	params      map[string]interface{} // 请求参数
@@ -39,6 +46,10 @@ func NewWorkerIntranetRequestContext(conn gnet.Conn, req serverx.RequestPacket,
 	ctx := &WorkerIntranetRequestContext{
 		svr: svr,
 	}
+	if req != nil {
+		ctx.xdata = types.ParseIntranetXData(req.Extend())
+		ctx.locale = ctx.xdata.Locale
+	}
 	ctx.RequestContext = *gnetx.NewRequestContext(conn, req)
 	return ctx
 }
@@ -48,6 +59,11 @@ func (c *WorkerIntranetRequestContext) UserId() string {
 	return c.uid
 }
 
+// Locale 返回请求扩展数据中指定的语言
+func (c *WorkerIntranetRequestContext) Locale() string {
+	return i18n.NormalizeLocale(c.locale)
+}
+
 func (c *WorkerIntranetRequestContext) ValidatedParams() (
 	entityAttrs []core.EntityAttribute,
 	entityEventParams []core.EventParam,
@@ -62,6 +78,34 @@ func (c *WorkerIntranetRequestContext) ValidatedParams() (
 	return c.attrs, c.eventParams, c.params, result
 }
 
+// RequestStats 返回内域服务按事件类型统计的请求数据
+func (c *WorkerIntranetRequestContext) RequestStats() []gnetx.EventTypeStats {
+	return c.svr.RequestStats()
+}
+
+// Header 返回请求扩展数据中的链路追踪请求头，内域通信不支持其他请求头
+func (c *WorkerIntranetRequestContext) Header(key string) string {
+	switch key = strings.ToLower(key); key {
+	case types.TRACEPARENT_HEADER, types.TRACESTATE_HEADER, types.BAGGAGE_HEADER:
+		return c.xdata.TraceHeader(key)
+	}
+	return c.RequestContext.Header(key)
+}
+
+// TraceContext 返回当前的链路追踪上下文，首次使用时从请求扩展数据提取，存在未结束的 Span 时以该 Span 为当前 Span
+func (c *WorkerIntranetRequestContext) TraceContext() context.Context {
+	if c.traceCtx == nil {
+		c.traceCtx = types.ExtractTraceContext(c)
+	}
+	return c.traceCtx
+}
+
+// Span 创建链路追踪片段，使用完毕后须调用 End
+func (c *WorkerIntranetRequestContext) Span(name string) types.Span {
+	c.TraceContext()
+	return types.StartSpan(&c.traceCtx, name)
+}
+
 // WorkerServer 返回关联的Worker服务器实例
 func (c *WorkerIntranetRequestContext) Server() types.WorkerServer {
 	return c.svr.ws
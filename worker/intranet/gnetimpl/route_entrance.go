@@ -16,6 +16,7 @@ package gnetimpl
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/garrickvan/event-matrix/constant"
 	"github.com/garrickvan/event-matrix/serverx"
@@ -26,7 +27,6 @@ import (
 	"github.com/garrickvan/event-matrix/worker/intranet/controller"
 	"github.com/garrickvan/event-matrix/worker/types"
 	"github.com/panjf2000/gnet/v2"
-	"github.com/spf13/cast"
 )
 
 func routeEntrance(rp serverx.RequestPacket, con gnet.Conn, iSvr interface{}) serverx.ResponsePacket {
@@ -41,11 +41,7 @@ func routeEntrance(rp serverx.RequestPacket, con gnet.Conn, iSvr interface{}) se
 		svr = s
 	}
 	gc := NewWorkerIntranetRequestContext(con, rp, svr)
-	eventType := types.INTRANET_EVENT_TYPE(0)
-	eventTypeData := cast.ToInt32(rp.Extend())
-	if types.IsIntranetEventType(eventTypeData) {
-		eventType = types.INTRANET_EVENT_TYPE(eventTypeData)
-	}
+	eventType := types.ParseIntranetXData(rp.Extend()).Type
 	// 事件处理
 	if eventType == types.W_T_W_EVENT_CALL {
 		event, status := common.ValidatedEvent(fastconv.StringToBytes(rp.TemporaryData()))
@@ -63,6 +59,7 @@ func routeEntrance(rp serverx.RequestPacket, con gnet.Conn, iSvr interface{}) se
 				Payload:     string(constant.UNSUPPORTED_EVENT),
 			}
 		}
+		common.ResolveAliasEvent(svr.ws, event)
 		// 获取实体事件
 		entityEvent := svr.ws.DomainCache().EntityEvent(types.PathToEventFromEvent(event))
 		if entityEvent == nil {
@@ -72,6 +69,14 @@ func routeEntrance(rp serverx.RequestPacket, con gnet.Conn, iSvr interface{}) se
 				Payload:     string(constant.UNSUPPORTED_EVENT),
 			}
 		}
+		// 参数超出大小上限
+		if entityEvent.ParamSizeExceeded(event.Params) {
+			return &gnetx.ResponsePacketImpl{
+				StatusCode:  http.StatusRequestEntityTooLarge,
+				ContentType: serverx.CONTENT_TYPE_STRING,
+				Payload:     string(constant.PAYLOAD_TOO_LARGE),
+			}
+		}
 		// 鉴权并获取用户ID
 		userId, status := common.GetUserId(gc, event, entityEvent.AuthType == constant.USER_AUTH, true)
 		if status != constant.SUCCESS {
@@ -86,19 +91,24 @@ func routeEntrance(rp serverx.RequestPacket, con gnet.Conn, iSvr interface{}) se
 		gc.ResetEvent(event)
 		gc.ResetEntityEvent(entityEvent)
 		eventUrl := event.GetUniqueLabel()
+		start := time.Now()
 		// 处理任务
 		if entityEvent.ExecutorType == constant.TASK_EXECUTOR {
 			if task, found := gc.Server().FindWorkerTaskExecutor(eventUrl); found && task != nil {
 				err := common.HandleTask(task, gc)
 				if err != nil {
 					logx.Error("internal event task error: %v", err)
+					common.NotifyEventProcessed(svr.ws, event, http.StatusInternalServerError, start)
 					return &gnetx.ResponsePacketImpl{
 						StatusCode:  http.StatusInternalServerError,
 						ContentType: serverx.CONTENT_TYPE_STRING,
 						Payload:     "internal event task error",
 					}
 				}
-				return gc.GetRespon()
+				resp := gc.GetRespon()
+				common.NotifyEventProcessed(svr.ws, event, resp.Status(), start)
+				dispatchEventFilters(resp, con, rp, svr, gc)
+				return resp
 			}
 		}
 		// 处理执行器
@@ -106,13 +116,17 @@ func routeEntrance(rp serverx.RequestPacket, con gnet.Conn, iSvr interface{}) se
 			err := common.HandleExecutor(funz, gc)
 			if err != nil {
 				logx.Error("internal event exec error: %v", err)
+				common.NotifyEventProcessed(svr.ws, event, http.StatusInternalServerError, start)
 				return &gnetx.ResponsePacketImpl{
 					StatusCode:  http.StatusInternalServerError,
 					ContentType: serverx.CONTENT_TYPE_STRING,
 					Payload:     "internal event exec error",
 				}
 			}
-			return gc.GetRespon()
+			resp := gc.GetRespon()
+			common.NotifyEventProcessed(svr.ws, event, resp.Status(), start)
+			dispatchEventFilters(resp, con, rp, svr, gc)
+			return resp
 		}
 		// 未找到执行器或任务, 返回默认未处理信息
 		uf := svr.GetUnHandler()
@@ -123,7 +137,7 @@ func routeEntrance(rp serverx.RequestPacket, con gnet.Conn, iSvr interface{}) se
 	}
 	// 传递特定事件的配置数据
 	if eventType == types.G_T_W_UPDATE_RECORD_FOR_DATA_MGR {
-		gc.SetData(gc.svr.cfg.NotAcceptUpdateRecordEventFromGateway)
+		gc.SetData(gc.svr.currentCfg().NotAcceptUpdateRecordEventFromGateway)
 	}
 	// 内置事件处理
 	err := controller.RootRouter(eventType, rp.TemporaryData(), gc, svr.GetUnHandler())
@@ -134,3 +148,17 @@ func routeEntrance(rp serverx.RequestPacket, con gnet.Conn, iSvr interface{}) se
 	}
 	return gc.GetRespon()
 }
+
+// dispatchEventFilters 事件执行成功后分发给跨实体订阅，每个处理方法使用独立的上下文
+func dispatchEventFilters(resp serverx.ResponsePacket, con gnet.Conn, rp serverx.RequestPacket, svr *WorkerIntranetServer, gc *WorkerIntranetRequestContext) {
+	if resp.Status() != http.StatusOK {
+		return
+	}
+	common.DispatchEventFilters(svr.ws, gc.Event(), func() types.WorkerContext {
+		ctx := NewWorkerIntranetRequestContext(con, rp, svr)
+		ctx.uid = gc.uid
+		ctx.ResetEvent(gc.Event())
+		ctx.ResetEntityEvent(gc.EntityEvent())
+		return ctx
+	})
+}
@@ -15,7 +15,12 @@
 package gnetimpl
 
 import (
+	"time"
+
+	"github.com/garrickvan/event-matrix/serverx"
 	"github.com/garrickvan/event-matrix/serverx/gnetx"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/intranet/dispatcher"
 	"github.com/garrickvan/event-matrix/worker/types"
 )
 
@@ -39,5 +44,39 @@ func NewWorkerIntranetServer(cfg *types.WorkerServerConfig, ws types.WorkerServe
 		cfg.IntranetSecretAlgor,
 		routeEntrance,
 		s)
+	s.SetMaxConnections(cfg.IntranetMaxConnections)
+	s.SetMaxMessageSize(cfg.MaxMessageSizeBytes)
+	s.SetPacketTimestampTolerance(time.Duration(cfg.PacketTimestampToleranceMs) * time.Millisecond)
+	// 按内域事件类型统计请求
+	s.SetRequestTypeResolver(func(req serverx.RequestPacket) uint16 {
+		return uint16(types.ParseIntranetXData(req.Extend()).Type)
+	})
+	// 内域客户端连接池统计随指标服务一并导出
+	s.MetricsRegistry().MustRegister(gnetx.NewPoolStatsCollector(dispatcher.PoolStats))
 	return s
 }
+
+// cfgProvider 可热更新配置的工作服务器
+type cfgProvider interface {
+	Cfg() *types.WorkerServerConfig
+}
+
+// currentCfg 返回当前配置，工作服务器支持热更新配置时以其最新配置为准
+func (s *WorkerIntranetServer) currentCfg() *types.WorkerServerConfig {
+	if p, ok := s.ws.(cfgProvider); ok {
+		if cfg := p.Cfg(); cfg != nil {
+			return cfg
+		}
+	}
+	return s.cfg
+}
+
+// Start 启动内域服务，配置了 IntranetMetricsPort 时同时启动 Prometheus 指标服务
+func (s *WorkerIntranetServer) Start() error {
+	if s.cfg.IntranetMetricsPort > 0 {
+		if err := s.ServeMetrics(s.cfg.IntranetMetricsPort); err != nil {
+			logx.Error("启动内域指标服务失败: " + err.Error())
+		}
+	}
+	return s.IntranetServer.Start()
+}
@@ -0,0 +1,104 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"errors"
+
+	"github.com/garrickvan/event-matrix/worker/public/hertzimpl"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metricsPublicServer 支持注册 Prometheus 指标路径的公网服务
+type metricsPublicServer interface {
+	RegisterMetricsEndpoint(path string, collectors ...prometheus.Collector) error
+}
+
+// intranetStats 提供连接和请求统计的内域服务
+type intranetStats interface {
+	ConnectionCount() int64
+	RequestCount() int64
+}
+
+// queueDepthReporter 提供任务队列深度的插件，如任务中心
+type queueDepthReporter interface {
+	QueueDepth() (int64, error)
+}
+
+// RegisterMetricsEndpoint 在公网服务上注册 Prometheus 指标路径，path 为空时使用 /metrics
+// 内置指标包括公网请求数、请求耗时、内域活跃连接数、内域请求数，加载了任务中心时还包括任务队列深度
+// 需在 Start 之前调用，自定义的公网服务需实现 RegisterMetricsEndpoint 方法
+func (s *TwoWayWorkerServer) RegisterMetricsEndpoint(path string) error {
+	pub, ok := s.public.(metricsPublicServer)
+	if !ok {
+		return errors.New("公网服务不支持注册指标路径")
+	}
+	labels := prometheus.Labels{"server_id": s.Cfg().ServerId}
+	collectors := []prometheus.Collector{
+		&taskQueueCollector{
+			ws: s,
+			desc: prometheus.NewDesc(
+				prometheus.BuildFQName(hertzimpl.METRICS_NAMESPACE, "", "task_queue_depth"),
+				"任务中心待处理的任务数", nil, labels,
+			),
+		},
+	}
+	if stats, ok := s.intranet.(intranetStats); ok {
+		collectors = append(collectors,
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Namespace:   hertzimpl.METRICS_NAMESPACE,
+				Name:        "intranet_active_connections",
+				Help:        "内域活跃连接数",
+				ConstLabels: labels,
+			}, func() float64 { return float64(stats.ConnectionCount()) }),
+			prometheus.NewCounterFunc(prometheus.CounterOpts{
+				Namespace:   hertzimpl.METRICS_NAMESPACE,
+				Name:        "intranet_requests_total",
+				Help:        "内域请求总数",
+				ConstLabels: labels,
+			}, func() float64 { return float64(stats.RequestCount()) }),
+		)
+	}
+	return pub.RegisterMetricsEndpoint(path, collectors...)
+}
+
+// taskQueueCollector 采集任务队列深度，未加载任务中心时不输出
+type taskQueueCollector struct {
+	ws   *TwoWayWorkerServer
+	desc *prometheus.Desc
+}
+
+func (c *taskQueueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *taskQueueCollector) Collect(ch chan<- prometheus.Metric) {
+	var reporter queueDepthReporter
+	for _, plugin := range c.ws.plugins {
+		if r, ok := plugin.(queueDepthReporter); ok {
+			reporter = r
+			break
+		}
+	}
+	if reporter == nil {
+		return
+	}
+	depth, err := reporter.QueueDepth()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(depth))
+}
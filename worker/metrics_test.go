@@ -0,0 +1,94 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/garrickvan/event-matrix/serverx"
+	"github.com/garrickvan/event-matrix/worker/public/hertzimpl"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+// queuePlugin 汇报队列深度的测试插件
+type queuePlugin struct {
+	fakePlugin
+	depth int64
+}
+
+func (p *queuePlugin) QueueDepth() (int64, error) { return p.depth, nil }
+
+// statsIntranet 提供连接统计的测试内域服务
+type statsIntranet struct {
+	serverx.NetworkServer
+}
+
+func (s *statsIntranet) ConnectionCount() int64 { return 3 }
+func (s *statsIntranet) RequestCount() int64    { return 42 }
+
+func newMetricsTestServer(plugins map[types.INTRANET_EVENT_TYPE]types.PluginWorker) (*TwoWayWorkerServer, *server.Hertz) {
+	cfg := &types.WorkerServerConfig{ServerId: "metrics-test", PublicPort: 18080}
+	ws := &TwoWayWorkerServer{plugins: plugins, intranet: &statsIntranet{}}
+	ws.cfg.Store(cfg)
+	pub := hertzimpl.NewWorkerPublicServer(cfg, ws)
+	ws.public = pub
+	return ws, pub.Impl().(*server.Hertz)
+}
+
+func TestRegisterMetricsEndpoint(t *testing.T) {
+	ws, h := newMetricsTestServer(map[types.INTRANET_EVENT_TYPE]types.PluginWorker{
+		32000: &queuePlugin{depth: 7},
+	})
+	if err := ws.RegisterMetricsEndpoint(""); err != nil {
+		t.Fatal(err)
+	}
+	// 普通请求仍由通配路由处理，并计入请求指标
+	ut.PerformRequest(h.Engine, "PUT", "/shop/v1/user/query", nil)
+
+	resp := ut.PerformRequest(h.Engine, "GET", hertzimpl.DEFAULT_METRICS_PATH, nil).Result()
+	if resp.StatusCode() != 200 {
+		t.Fatalf("unexpected status %d", resp.StatusCode())
+	}
+	body := string(resp.Body())
+	for _, want := range []string{
+		`event_matrix_worker_http_requests_total{method="PUT",server_id="metrics-test",status="501"} 1`,
+		`event_matrix_worker_http_request_duration_seconds_count{method="PUT",server_id="metrics-test"} 1`,
+		`event_matrix_worker_http_requests_in_flight{server_id="metrics-test"} 1`,
+		`event_matrix_worker_intranet_active_connections{server_id="metrics-test"} 3`,
+		`event_matrix_worker_intranet_requests_total{server_id="metrics-test"} 42`,
+		`event_matrix_worker_task_queue_depth{server_id="metrics-test"} 7`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q\n%s", want, body)
+		}
+	}
+}
+
+func TestRegisterMetricsEndpointWithoutTaskCenter(t *testing.T) {
+	ws, h := newMetricsTestServer(map[types.INTRANET_EVENT_TYPE]types.PluginWorker{})
+	if err := ws.RegisterMetricsEndpoint("/custom/metrics"); err != nil {
+		t.Fatal(err)
+	}
+	resp := ut.PerformRequest(h.Engine, "GET", "/custom/metrics", nil).Result()
+	if resp.StatusCode() != 200 {
+		t.Fatalf("unexpected status %d", resp.StatusCode())
+	}
+	if strings.Contains(string(resp.Body()), "task_queue_depth") {
+		t.Error("task queue depth should not be exported without task center")
+	}
+}
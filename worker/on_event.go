@@ -0,0 +1,181 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+const (
+	// EVENT_HOOK_WORKERS 执行事件回调的协程数量
+	EVENT_HOOK_WORKERS = 16
+	// EVENT_HOOK_QUEUE_SIZE 等待执行的事件回调队列长度，队列已满时丢弃回调并记录日志
+	EVENT_HOOK_QUEUE_SIZE = 1024
+	// EVENT_HOOK_WILDCARD 事件回调标签中匹配任意值的通配符
+	EVENT_HOOK_WILDCARD = "*"
+)
+
+// eventHook 已注册的内置执行器成功回调
+type eventHook struct {
+	filter  core.EventFilter // 事件匹配条件，为空的字段匹配任意值
+	version string           // 事件版本，为空时匹配任意版本
+	hook    func(snapshot *types.EventHookSnapshot)
+}
+
+// eventHookPool 有界的回调执行协程池，首次提交时启动
+type eventHookPool struct {
+	once  sync.Once
+	queue chan func()
+}
+
+// submit 提交回调，队列已满时返回 false
+func (p *eventHookPool) submit(fn func()) bool {
+	p.once.Do(func() {
+		p.queue = make(chan func(), EVENT_HOOK_QUEUE_SIZE)
+		for i := 0; i < EVENT_HOOK_WORKERS; i++ {
+			go func() {
+				for fn := range p.queue {
+					fn()
+				}
+			}()
+		}
+	})
+	select {
+	case p.queue <- fn:
+		return true
+	default:
+		return false
+	}
+}
+
+// OnEvent 注册内置执行器执行成功后的回调，用于清除关联缓存、发送通知等附带操作，需在服务启动前调用。
+// eventUniqueLabel 形如 demo.shop.order->create@v1，版本可省略，项目、上下文、实体、事件和版本均可使用 * 匹配任意值，
+// 如 demo.*.*->* 匹配 demo 项目下的全部事件。
+// 回调在协程池中异步执行，此时请求可能已经结束，回调收到的是执行成功时的事件快照，不持有请求上下文。
+func (ws *TwoWayWorkerServer) OnEvent(eventUniqueLabel string, hook func(snapshot *types.EventHookSnapshot)) error {
+	if hook == nil {
+		return errors.New("事件回调不能为空")
+	}
+	filter, version, err := parseEventHookLabel(eventUniqueLabel)
+	if err != nil {
+		return err
+	}
+	ws.onEventHooks = append(ws.onEventHooks, eventHook{filter: filter, version: version, hook: hook})
+	return nil
+}
+
+// parseEventHookLabel 将事件标签解析为匹配条件，通配符解析为空值
+func parseEventHookLabel(label string) (core.EventFilter, string, error) {
+	invalid := fmt.Errorf("无效的事件标签: %s，格式应为 项目.上下文.实体->事件[@版本]", label)
+	fullLabel, version, hasVersion := strings.Cut(strings.TrimSpace(label), "@")
+	entityLabel, event, ok := strings.Cut(fullLabel, "->")
+	if !ok {
+		return core.EventFilter{}, "", invalid
+	}
+	parts := strings.Split(entityLabel, ".")
+	if len(parts) != 3 {
+		return core.EventFilter{}, "", invalid
+	}
+	fields := append(parts, event)
+	if hasVersion {
+		fields = append(fields, version)
+	}
+	for i, field := range fields {
+		if field == "" {
+			return core.EventFilter{}, "", invalid
+		}
+		if field == EVENT_HOOK_WILDCARD {
+			fields[i] = ""
+		}
+	}
+	filter := core.EventFilter{
+		SourceProject: fields[0],
+		SourceContext: fields[1],
+		SourceEntity:  fields[2],
+		SourceEvent:   fields[3],
+	}
+	if hasVersion {
+		version = fields[4]
+	}
+	return filter, version, nil
+}
+
+// withEventHooks 包装内置执行器，执行成功且不是试运行时触发匹配的事件回调
+func (ws *TwoWayWorkerServer) withEventHooks(exec types.WorkerExecutor) types.WorkerExecutor {
+	return func(ctx types.WorkerContext) (*jsonx.JsonResponse, int) {
+		resp, status := exec(ctx)
+		if resp != nil && resp.Code == string(constant.SUCCESS) && !resp.DryRun {
+			ws.dispatchEventHooks(ctx, resp, status)
+		}
+		return resp, status
+	}
+}
+
+// dispatchEventHooks 将匹配事件的回调提交到协程池，回调异常只记录日志
+// 提交前复制事件、实体事件和响应，回调执行时请求上下文可能已被回收复用
+func (ws *TwoWayWorkerServer) dispatchEventHooks(ctx types.WorkerContext, resp *jsonx.JsonResponse, status int) {
+	event := ctx.Event()
+	if event == nil || len(ws.onEventHooks) == 0 {
+		return
+	}
+	var snapshot *types.EventHookSnapshot
+	for _, h := range ws.onEventHooks {
+		if !h.filter.Match(event) || (h.version != "" && h.version != event.Version) {
+			continue
+		}
+		if snapshot == nil {
+			snapshot = newEventHookSnapshot(ctx, resp, status)
+			event = snapshot.Event
+		}
+		hook := h.hook
+		submitted := ws.eventHookPool.submit(func() {
+			defer func() {
+				if r := recover(); r != nil {
+					logx.Error(fmt.Sprintf("事件回调异常: %s, %v", event.GetUniqueLabel(), r))
+				}
+			}()
+			hook(snapshot)
+		})
+		if !submitted {
+			logx.Warn("事件回调队列已满，丢弃回调: " + event.GetUniqueLabel())
+		}
+	}
+}
+
+// newEventHookSnapshot 复制回调需要的事件信息，使回调不再引用请求上下文
+func newEventHookSnapshot(ctx types.WorkerContext, resp *jsonx.JsonResponse, status int) *types.EventHookSnapshot {
+	snapshot := &types.EventHookSnapshot{
+		Event:      ctx.Event().Clone(),
+		UserId:     ctx.UserId(),
+		StatusCode: status,
+	}
+	if entityEvent := ctx.EntityEvent(); entityEvent != nil {
+		snapshot.EntityEvent = entityEvent.Clone()
+	}
+	if resp != nil {
+		copied := *resp
+		snapshot.Response = &copied
+	}
+	return snapshot
+}
@@ -0,0 +1,96 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+// SetupPlugins 按插件依赖关系的拓扑顺序依次安装插件
+// 插件的依赖通过 Dependencies 声明，由其他插件的 ReceiveCodes 或已注册的插件提供
+// 依赖缺失或存在循环依赖时返回错误，且不会安装任何插件
+func (ws *TwoWayWorkerServer) SetupPlugins(plugins ...types.PluginWorker) error {
+	sorted, err := ws.sortPluginsByDependencies(plugins)
+	if err != nil {
+		return err
+	}
+	for _, plugin := range sorted {
+		if err := plugin.Setup(); err != nil {
+			return fmt.Errorf("安装插件 %T 失败: %w", plugin, err)
+		}
+	}
+	return nil
+}
+
+// sortPluginsByDependencies 对插件进行拓扑排序，无依赖关系的插件保持传入顺序
+func (ws *TwoWayWorkerServer) sortPluginsByDependencies(plugins []types.PluginWorker) ([]types.PluginWorker, error) {
+	providers := make(map[types.INTRANET_EVENT_TYPE]int)
+	for i, plugin := range plugins {
+		for _, code := range plugin.ReceiveCodes() {
+			providers[code] = i
+		}
+	}
+
+	indegree := make([]int, len(plugins))
+	dependents := make([][]int, len(plugins))
+	for i, plugin := range plugins {
+		seen := make(map[int]bool)
+		for _, dep := range plugin.Dependencies() {
+			j, ok := providers[dep]
+			if !ok {
+				if _, registered := ws.plugins[dep]; registered {
+					continue
+				}
+				return nil, fmt.Errorf("插件 %T 依赖的事件类型 %d 没有对应的插件", plugin, dep)
+			}
+			if j == i || seen[j] {
+				continue
+			}
+			seen[j] = true
+			dependents[j] = append(dependents[j], i)
+			indegree[i]++
+		}
+	}
+
+	sorted := make([]types.PluginWorker, 0, len(plugins))
+	done := make([]bool, len(plugins))
+	for len(sorted) < len(plugins) {
+		next := -1
+		for i := range plugins {
+			if !done[i] && indegree[i] == 0 {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			cycle := []string{}
+			for i, plugin := range plugins {
+				if !done[i] {
+					cycle = append(cycle, fmt.Sprintf("%T", plugin))
+				}
+			}
+			return nil, fmt.Errorf("插件存在循环依赖: [%s]", strings.Join(cycle, ", "))
+		}
+		done[next] = true
+		sorted = append(sorted, plugins[next])
+		for _, d := range dependents[next] {
+			indegree[d]--
+		}
+	}
+	return sorted, nil
+}
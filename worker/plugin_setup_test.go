@@ -0,0 +1,151 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/worker/plugins/logcenter"
+	"github.com/garrickvan/event-matrix/worker/plugins/taskcenter"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+// fakePlugin 记录安装顺序的测试插件
+type fakePlugin struct {
+	name  string
+	codes []types.INTRANET_EVENT_TYPE
+	deps  []types.INTRANET_EVENT_TYPE
+	order *[]string
+}
+
+func (p *fakePlugin) Setup() error {
+	*p.order = append(*p.order, p.name)
+	return nil
+}
+func (p *fakePlugin) ReceiveCodes() []types.INTRANET_EVENT_TYPE { return p.codes }
+func (p *fakePlugin) Dependencies() []types.INTRANET_EVENT_TYPE { return p.deps }
+func (p *fakePlugin) Handle(ctx types.WorkerContext, typz types.INTRANET_EVENT_TYPE) error {
+	return nil
+}
+func (p *fakePlugin) Shutdown() error                 { return nil }
+func (p *fakePlugin) HealthCheck() types.PluginHealth { return types.PluginHealth{Healthy: true} }
+
+func newPluginTestServer() *TwoWayWorkerServer {
+	return &TwoWayWorkerServer{plugins: map[types.INTRANET_EVENT_TYPE]types.PluginWorker{}}
+}
+
+func TestSetupPluginsInDependencyOrder(t *testing.T) {
+	order := []string{}
+	task := &fakePlugin{name: "task", codes: []types.INTRANET_EVENT_TYPE{32000}, deps: []types.INTRANET_EVENT_TYPE{31000}, order: &order}
+	log := &fakePlugin{name: "log", codes: []types.INTRANET_EVENT_TYPE{31000}, order: &order}
+	ai := &fakePlugin{name: "ai", codes: []types.INTRANET_EVENT_TYPE{31500}, deps: []types.INTRANET_EVENT_TYPE{32000, 31000}, order: &order}
+	standalone := &fakePlugin{name: "standalone", codes: []types.INTRANET_EVENT_TYPE{33000}, order: &order}
+
+	ws := newPluginTestServer()
+	if err := ws.SetupPlugins(ai, task, standalone, log); err != nil {
+		t.Fatalf("setup plugins failed: %v", err)
+	}
+	if expected := []string{"standalone", "log", "task", "ai"}; !reflect.DeepEqual(order, expected) {
+		t.Fatalf("expected setup order %v, got %v", expected, order)
+	}
+}
+
+func TestBuiltinPluginsDependencyOrder(t *testing.T) {
+	ws := newPluginTestServer()
+	logCenter := logcenter.NewLogCenter(ws, "runtime_log_cfg", "event_log_cfg")
+	taskCenter := taskcenter.NewTaskCenter(ws, "task_cfg", 10)
+
+	sorted, err := ws.sortPluginsByDependencies([]types.PluginWorker{taskCenter, logCenter})
+	if err != nil {
+		t.Fatalf("sort plugins failed: %v", err)
+	}
+	if !reflect.DeepEqual(sorted, []types.PluginWorker{logCenter, taskCenter}) {
+		t.Fatalf("task center should be set up after log center, got %T %T", sorted[0], sorted[1])
+	}
+
+	// 缺少日志中心时任务中心无法安装
+	if _, err := ws.sortPluginsByDependencies([]types.PluginWorker{taskCenter}); err == nil {
+		t.Fatal("expected error when log center is missing")
+	}
+}
+
+func TestSetupPluginsWithCycle(t *testing.T) {
+	order := []string{}
+	a := &fakePlugin{name: "a", codes: []types.INTRANET_EVENT_TYPE{1}, deps: []types.INTRANET_EVENT_TYPE{2}, order: &order}
+	b := &fakePlugin{name: "b", codes: []types.INTRANET_EVENT_TYPE{2}, deps: []types.INTRANET_EVENT_TYPE{3}, order: &order}
+	c := &fakePlugin{name: "c", codes: []types.INTRANET_EVENT_TYPE{3}, deps: []types.INTRANET_EVENT_TYPE{1}, order: &order}
+
+	ws := newPluginTestServer()
+	if err := ws.SetupPlugins(a, b, c); err == nil {
+		t.Fatal("expected error for circular dependencies")
+	}
+	if len(order) != 0 {
+		t.Fatalf("no plugin should be set up on cycle, got %v", order)
+	}
+}
+
+func TestSetupPluginsWithMissingDependency(t *testing.T) {
+	order := []string{}
+	a := &fakePlugin{name: "a", codes: []types.INTRANET_EVENT_TYPE{1}, deps: []types.INTRANET_EVENT_TYPE{99}, order: &order}
+
+	ws := newPluginTestServer()
+	if err := ws.SetupPlugins(a); err == nil {
+		t.Fatal("expected error for missing dependency")
+	}
+
+	// 依赖已由注册过的插件提供时可以安装
+	ws.plugins[99] = &fakePlugin{name: "registered", order: &order}
+	if err := ws.SetupPlugins(a); err != nil {
+		t.Fatalf("setup plugins failed: %v", err)
+	}
+	if !reflect.DeepEqual(order, []string{"a"}) {
+		t.Fatalf("unexpected setup order: %v", order)
+	}
+}
+
+// watcherPlugin 记录共享配置变更的测试插件
+type watcherPlugin struct {
+	fakePlugin
+	changed []string
+}
+
+func (p *watcherPlugin) OnSharedConfigureChange(cfg *core.SharedConfigure) error {
+	p.changed = append(p.changed, cfg.Key)
+	return nil
+}
+
+func TestApplySharedConfigureChange(t *testing.T) {
+	ws := newPluginTestServer()
+	watcher := &watcherPlugin{}
+	ws.plugins[31000] = watcher
+	ws.plugins[31001] = watcher
+	ws.plugins[32000] = &fakePlugin{}
+
+	cfg := &core.SharedConfigure{Key: "log_center_level", Value: `{"minLevel":"warn"}`}
+	if err := ws.applySharedConfigureChange(ws, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(watcher.changed, []string{"log_center_level"}) {
+		t.Fatalf("同一插件应只通知一次: %v", watcher.changed)
+	}
+	if stored, ok := ws.sharedConfigures.Load("log_center_level"); !ok || stored != cfg {
+		t.Fatal("本地共享配置应被更新")
+	}
+	if err := ws.applySharedConfigureChange(ws, &core.SharedConfigure{}); err == nil {
+		t.Fatal("空配置应返回错误")
+	}
+}
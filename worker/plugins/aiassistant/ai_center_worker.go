@@ -33,6 +33,9 @@ type AiAssistantCenter struct {
 	svr    types.WorkerServer
 
 	cfgs sync.Map // 使用 sync.Map 替代 map
+
+	hookMu         sync.RWMutex
+	tokenUsageHook TokenUsageHook // token用量回调，调用模型时读取，不复制到各助手配置
 }
 
 // AiAssistantWorkerContext 定义了AI助手中心的上下文名称
@@ -83,6 +86,11 @@ func (a *AiAssistantCenter) ReceiveCodes() []types.INTRANET_EVENT_TYPE {
 	return []types.INTRANET_EVENT_TYPE{G_T_W_AI_ASSISTANT_PING, G_T_W_AI_ASSISTANT_CHAT_STRING, G_T_W_AI_ASSISTANT_CHAT_STREAM}
 }
 
+// Dependencies AI助手中心不依赖其他插件
+func (a *AiAssistantCenter) Dependencies() []types.INTRANET_EVENT_TYPE {
+	return nil
+}
+
 // Handle 根据接收到的事件类型调用相应的处理方法
 func (a *AiAssistantCenter) Handle(ctx types.WorkerContext, typz types.INTRANET_EVENT_TYPE) error {
 	switch typz {
@@ -96,6 +104,25 @@ func (a *AiAssistantCenter) Handle(ctx types.WorkerContext, typz types.INTRANET_
 	return ctx.SetStatus(http.StatusNotFound).ResponseString(string(constant.UNSUPPORTED_EVENT))
 }
 
+// HealthCheck 检查AI助手中心的健康状态，汇报已加载的助手配置数
+func (a *AiAssistantCenter) HealthCheck() types.PluginHealth {
+	count := 0
+	a.cfgs.Range(func(_, _ interface{}) bool {
+		count++
+		return true
+	})
+	return types.PluginHealth{
+		Healthy: true,
+		Message: "ok",
+		Details: map[string]interface{}{"assistants": count},
+	}
+}
+
+// Shutdown 关闭AI助手中心，无后台任务，直接返回
+func (a *AiAssistantCenter) Shutdown() error {
+	return nil
+}
+
 // HandlePing 处理AI助手的PING请求，检查配置是否存在并返回结果
 func (a *AiAssistantCenter) HandlePing(ctx types.WorkerContext) error {
 	cfgKeys := fastconv.SafeSplitFromBytes(ctx.Body(), constant.SPLIT_CHAR)
@@ -140,7 +167,7 @@ func (a *AiAssistantCenter) HandleChatString(ctx types.WorkerContext) error {
 	if !ok {
 		return ctx.SetStatus(http.StatusNotFound).ResponseString("assistant not found")
 	}
-	resp, _, err := InvokeAiModel(cfg.(*AiAssistantConfig), &params)
+	resp, _, err := invokeAiModel(cfg.(*AiAssistantConfig), &params, a.getTokenUsageHook())
 	if err != nil {
 		return ctx.SetStatus(http.StatusInternalServerError).ResponseString(err.Error())
 	}
@@ -26,6 +26,11 @@ import (
 
 // InvokeAiModel 调用AI, 输入系统提示和角色提示, 返回响应文本, 响应ID, 错误信息
 func InvokeAiModel(config *AiAssistantConfig, params *AskParams) (response string, nextId string, err error) {
+	return invokeAiModel(config, params, nil)
+}
+
+// invokeAiModel 调用AI，调用结束后将token用量回调给 hook，hook 为空时不回调
+func invokeAiModel(config *AiAssistantConfig, params *AskParams, hook TokenUsageHook) (response string, nextId string, err error) {
 	if config == nil || params == nil {
 		return "", "", fmt.Errorf("config and params are required")
 	}
@@ -39,14 +44,14 @@ func InvokeAiModel(config *AiAssistantConfig, params *AskParams) (response strin
 
 	switch config.Supplier {
 	case AliyunSupplier:
-		return invokeAliyunAiModel(config, params, temperature)
+		return invokeAliyunAiModel(config, params, temperature, hook)
 	}
 
 	return "", "", errors.New("unsupported AI supplier")
 }
 
 // invokeAliyunAiModel 调用阿里云AI服务
-func invokeAliyunAiModel(config *AiAssistantConfig, params *AskParams, temperature float32) (response string, nextId string, err error) {
+func invokeAliyunAiModel(config *AiAssistantConfig, params *AskParams, temperature float32, hook TokenUsageHook) (response string, nextId string, err error) {
 	requestData := AliyunChatRequest{
 		Model:  config.ModelName,
 		Stream: config.Stream,
@@ -85,15 +90,41 @@ func invokeAliyunAiModel(config *AiAssistantConfig, params *AskParams, temperatu
 		return "", "", fmt.Errorf("API request failed with status code: %d", resp.StatusCode)
 	}
 
+	tokenizer := getTokenizer(config.ModelName)
+	result, err := readAliyunStream(resp.Body, tokenizer)
+	if err != nil {
+		return "", "", err
+	}
+	if hook != nil {
+		inputTokens := tokenizer.CountTokens(params.SystemPrompt) + tokenizer.CountTokens(params.RolePrompt)
+		outputTokens := result.outputTokens
+		// 模型返回了实际用量时以实际用量为准
+		if result.usage != nil {
+			inputTokens = result.usage.PromptTokens
+			outputTokens = result.usage.CompletionTokens
+		}
+		hook(config.ModelName, result.requestID, inputTokens, outputTokens)
+	}
+	return result.content, "", nil
+}
+
+// aliyunStreamResult 流式响应的解析结果
+type aliyunStreamResult struct {
+	content      string       // 完整响应文本
+	requestID    string       // 请求ID
+	outputTokens int          // 按数据块累计的输出token数
+	usage        *AliyunUsage // 模型返回的token用量
+}
+
+// readAliyunStream 读取阿里云流式响应，并在数据块到达时累计输出token数
+func readAliyunStream(body io.Reader, tokenizer Tokenizer) (*aliyunStreamResult, error) {
+	result := &aliyunStreamResult{}
 	var responseBuilder bytes.Buffer
-	reader := bufio.NewReader(resp.Body)
+	reader := bufio.NewReader(body)
 	for {
 		line, err := reader.ReadBytes('\n')
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return "", "", fmt.Errorf("failed to read response: %v", err)
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to read response: %v", err)
 		}
 
 		if bytes.HasPrefix(line, []byte("data: ")) {
@@ -109,12 +140,23 @@ func invokeAliyunAiModel(config *AiAssistantConfig, params *AskParams, temperatu
 				fmt.Printf("解析错误: %v\n", err)
 				continue
 			}
+			if streamResponse.ID != "" {
+				result.requestID = streamResponse.ID
+			}
+			if streamResponse.Usage != nil {
+				result.usage = streamResponse.Usage
+			}
 
 			if len(streamResponse.Choices) > 0 {
 				content := streamResponse.Choices[0].Delta.Content
 				responseBuilder.WriteString(content)
+				result.outputTokens += tokenizer.CountTokens(content)
 			}
 		}
+		if err == io.EOF {
+			break
+		}
 	}
-	return responseBuilder.String(), "", nil
+	result.content = responseBuilder.String()
+	return result, nil
 }
@@ -0,0 +1,90 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aiassistant
+
+import (
+	"errors"
+	"sync"
+	"unicode"
+)
+
+// Tokenizer 定义了模型分词器，用于统计文本的token数
+type Tokenizer interface {
+	// CountTokens 返回文本的token数
+	CountTokens(text string) int
+}
+
+// TokenUsageHook 是token用量回调的类型定义，在每次调用模型结束后触发
+// modelKey 为模型名称，requestID 为模型返回的请求ID，inputTokens、outputTokens 分别为输入和输出的token数
+type TokenUsageHook func(modelKey, requestID string, inputTokens, outputTokens int)
+
+// tokenizers 按模型名称注册的分词器
+var tokenizers sync.Map
+
+// RegisterTokenizer 为指定模型注册分词器，未注册的模型使用字符近似估算
+func RegisterTokenizer(modelKey string, tokenizer Tokenizer) {
+	if modelKey == "" || tokenizer == nil {
+		return
+	}
+	tokenizers.Store(modelKey, tokenizer)
+}
+
+// getTokenizer 获取模型的分词器，未注册时返回字符近似分词器
+func getTokenizer(modelKey string) Tokenizer {
+	if t, ok := tokenizers.Load(modelKey); ok {
+		return t.(Tokenizer)
+	}
+	return approximateTokenizer{}
+}
+
+// approximateTokenizer 基于字符的近似分词器
+// 中日韩字符按每个字符1个token计算，其他字符按每4个字符1个token计算
+type approximateTokenizer struct{}
+
+func (approximateTokenizer) CountTokens(text string) int {
+	cjk, others := 0, 0
+	for _, r := range text {
+		if unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+			unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r) {
+			cjk++
+		} else {
+			others++
+		}
+	}
+	return cjk + (others+3)/4
+}
+
+// EstimateTokens 估算发送给模型前提示词的token数
+// modelKey 为模型名称，优先使用注册的分词器，否则使用字符近似估算
+func (a *AiAssistantCenter) EstimateTokens(prompt string, modelKey string) (int, error) {
+	if modelKey == "" {
+		return 0, errors.New("model key is required")
+	}
+	return getTokenizer(modelKey).CountTokens(prompt), nil
+}
+
+// SetTokenUsageHook 设置token用量回调，对所有助手配置生效
+func (a *AiAssistantCenter) SetTokenUsageHook(hook TokenUsageHook) {
+	a.hookMu.Lock()
+	defer a.hookMu.Unlock()
+	a.tokenUsageHook = hook
+}
+
+// getTokenUsageHook 获取当前的token用量回调
+func (a *AiAssistantCenter) getTokenUsageHook() TokenUsageHook {
+	a.hookMu.RLock()
+	defer a.hookMu.RUnlock()
+	return a.tokenUsageHook
+}
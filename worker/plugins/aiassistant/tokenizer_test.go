@@ -0,0 +1,110 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aiassistant
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+// wordTokenizer 按空白分词的模拟分词器
+type wordTokenizer struct{}
+
+func (wordTokenizer) CountTokens(text string) int {
+	return len(strings.Fields(text))
+}
+
+func TestEstimateTokens(t *testing.T) {
+	RegisterTokenizer("mock-model", wordTokenizer{})
+	a := &AiAssistantCenter{}
+
+	n, err := a.EstimateTokens("hello brave new world", "mock-model")
+	if err != nil || n != 4 {
+		t.Fatalf("unexpected estimate: %d, %v", n, err)
+	}
+	// 未注册的模型使用字符近似估算
+	n, err = a.EstimateTokens("你好abcd", "unknown-model")
+	if err != nil || n != 3 {
+		t.Fatalf("unexpected approximate estimate: %d, %v", n, err)
+	}
+	if _, err := a.EstimateTokens("hello", ""); err == nil {
+		t.Fatal("expected error for empty model key")
+	}
+}
+
+func TestReadAliyunStreamCountsTokens(t *testing.T) {
+	stream := strings.Join([]string{
+		`data: {"id":"req-1","choices":[{"delta":{"content":"hello there "}}]}`,
+		`data: {"id":"req-1","choices":[{"delta":{"content":"general kenobi"}}]}`,
+		`data: [DONE]`,
+		``,
+	}, "\n")
+	result, err := readAliyunStream(strings.NewReader(stream), wordTokenizer{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.content != "hello there general kenobi" {
+		t.Fatalf("unexpected content: %q", result.content)
+	}
+	if result.requestID != "req-1" || result.outputTokens != 4 || result.usage != nil {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestReadAliyunStreamUsage(t *testing.T) {
+	stream := strings.Join([]string{
+		`data: {"id":"req-2","choices":[{"delta":{"content":"ok"}}]}`,
+		`data: {"id":"req-2","choices":[],"usage":{"prompt_tokens":7,"completion_tokens":1}}`,
+		`data: [DONE]`,
+	}, "\n")
+	result, err := readAliyunStream(strings.NewReader(stream), wordTokenizer{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.usage == nil || result.usage.PromptTokens != 7 || result.usage.CompletionTokens != 1 {
+		t.Fatalf("unexpected usage: %+v", result.usage)
+	}
+}
+
+func TestSetTokenUsageHook(t *testing.T) {
+	a := &AiAssistantCenter{}
+	if a.getTokenUsageHook() != nil {
+		t.Fatal("expected no hook by default")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			a.SetTokenUsageHook(func(modelKey, requestID string, inputTokens, outputTokens int) {})
+		}()
+		go func() {
+			defer wg.Done()
+			a.getTokenUsageHook()
+		}()
+	}
+	wg.Wait()
+
+	called := false
+	a.SetTokenUsageHook(func(modelKey, requestID string, inputTokens, outputTokens int) {
+		called = true
+	})
+	a.getTokenUsageHook()("mock-model", "req", 1, 1)
+	if !called {
+		t.Fatal("expected hook called")
+	}
+}
@@ -52,9 +52,17 @@ type AliyunMessage struct {
 
 // StreamResponse 定义了流式响应的结构
 type AliyunStreamResponse struct {
+	ID      string       `json:"id"`    // 请求ID
+	Usage   *AliyunUsage `json:"usage"` // token用量，仅在最后一个数据块返回
 	Choices []struct {
 		Delta struct {
 			Content string `json:"content"` // 流式返回的内容片段
 		} `json:"delta"`
 	} `json:"choices"` // 响应的选择列表，通常只有一个选择
 }
+
+// AliyunUsage 定义了模型返回的token用量
+type AliyunUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`     // 输入token数
+	CompletionTokens int `json:"completion_tokens"` // 输出token数
+}
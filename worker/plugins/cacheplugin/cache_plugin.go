@@ -0,0 +1,196 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cacheplugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/worker/intranet/dispatcher"
+	"github.com/garrickvan/event-matrix/worker/types"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/cast"
+)
+
+// CachePlugin 基于Redis的分布式缓存插件，用于多个工作实例间共享数据
+// 安装后可在执行器中通过 ctx.Server().DistributedCache() 访问
+type CachePlugin struct {
+	svr    types.WorkerServer
+	cfgKey string
+	client redisClient
+}
+
+// redisClient 插件使用到的Redis客户端方法，*redis.Client 实现了该接口
+type redisClient interface {
+	Get(ctx context.Context, key string) *redis.StringCmd
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	Del(ctx context.Context, keys ...string) *redis.IntCmd
+	Ping(ctx context.Context) *redis.StatusCmd
+	Close() error
+}
+
+// RedisConfig Redis连接配置，对应类型为 redis 的共享配置
+type RedisConfig struct {
+	Host     string `json:"host"`     // Redis地址
+	Port     int    `json:"port"`     // Redis端口
+	Password string `json:"password"` // 认证密码
+	DB       int    `json:"db"`       // 数据库编号
+	PoolSize int    `json:"poolSize"` // 连接池大小，为0时使用默认值
+}
+
+// distributedCacheSetter 支持设置分布式缓存的工作服务器
+type distributedCacheSetter interface {
+	SetDistributedCache(c types.DistributedCache)
+}
+
+const (
+	G_T_W_CACHE_PING types.INTRANET_EVENT_TYPE = 37000
+
+	cacheOpTimeout = 3 * time.Second
+)
+
+// NewCachePlugin 创建分布式缓存插件，cfgKey 为Redis配置的共享配置键
+func NewCachePlugin(svr types.WorkerServer, cfgKey string) *CachePlugin {
+	return &CachePlugin{
+		svr:    svr,
+		cfgKey: cfgKey,
+	}
+}
+
+// Setup 读取Redis配置并建立连接，将插件设置为工作服务器的分布式缓存
+func (cp *CachePlugin) Setup() error {
+	setter, ok := cp.svr.(distributedCacheSetter)
+	if !ok {
+		return errors.New("工作服务器不支持设置分布式缓存，无法启动缓存插件")
+	}
+	sc := cp.svr.SharedConfigure(cp.cfgKey)
+	if sc == nil {
+		return fmt.Errorf("缺少Redis配置: [%s], 请检查配置，无法启动缓存插件", cp.cfgKey)
+	}
+	if sc.Type != core.CACHE_REDIS {
+		return fmt.Errorf("配置 [%s] 的类型为 %s，缓存插件仅支持 %s", cp.cfgKey, sc.Type, core.CACHE_REDIS)
+	}
+	cfg := &RedisConfig{}
+	if err := jsonx.UnmarshalFromStr(sc.Value, cfg); err != nil {
+		return fmt.Errorf("Redis配置解析失败: %w", err)
+	}
+	client := redis.NewClient(&redis.Options{
+		Addr:     net.JoinHostPort(cfg.Host, cast.ToString(cfg.Port)),
+		Password: cfg.Password,
+		DB:       cfg.DB,
+		PoolSize: cfg.PoolSize,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), cacheOpTimeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return fmt.Errorf("Redis连接失败: %w", err)
+	}
+	cp.client = client
+	cp.svr.RegisterPlugin(cp)
+	setter.SetDistributedCache(cp)
+	dispatcher.ReportConfigUsedBy(cp.cfgKey, cp.svr.ServerId())
+	return nil
+}
+
+// Get 获取缓存值，键不存在时返回 types.ErrCacheMiss
+func (cp *CachePlugin) Get(key string) (string, error) {
+	if cp.client == nil {
+		return "", errors.New("缓存插件未初始化")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cacheOpTimeout)
+	defer cancel()
+	val, err := cp.client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", types.ErrCacheMiss
+	}
+	return val, err
+}
+
+// Set 设置缓存值，ttl为0时表示永不过期
+func (cp *CachePlugin) Set(key, value string, ttl time.Duration) error {
+	if cp.client == nil {
+		return errors.New("缓存插件未初始化")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cacheOpTimeout)
+	defer cancel()
+	return cp.client.Set(ctx, key, value, ttl).Err()
+}
+
+// Del 删除缓存值
+func (cp *CachePlugin) Del(key string) error {
+	if cp.client == nil {
+		return errors.New("缓存插件未初始化")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cacheOpTimeout)
+	defer cancel()
+	return cp.client.Del(ctx, key).Err()
+}
+
+// ReceiveCodes 返回缓存插件能够处理的事件类型列表
+func (cp *CachePlugin) ReceiveCodes() []types.INTRANET_EVENT_TYPE {
+	return []types.INTRANET_EVENT_TYPE{G_T_W_CACHE_PING}
+}
+
+// Dependencies 缓存插件不依赖其他插件
+func (cp *CachePlugin) Dependencies() []types.INTRANET_EVENT_TYPE {
+	return nil
+}
+
+// Handle 根据接收到的事件类型调用相应的处理方法
+func (cp *CachePlugin) Handle(ctx types.WorkerContext, typz types.INTRANET_EVENT_TYPE) error {
+	switch typz {
+	case G_T_W_CACHE_PING:
+		if err := cp.ping(); err != nil {
+			return ctx.SetStatus(http.StatusServiceUnavailable).ResponseString(err.Error())
+		}
+		return ctx.SetStatus(http.StatusOK).ResponseString(string(constant.SUCCESS))
+	default:
+		return ctx.SetStatus(http.StatusForbidden).Response([]byte(constant.UNSUPPORTED_EVENT))
+	}
+}
+
+// HealthCheck 检查Redis连接是否可用
+func (cp *CachePlugin) HealthCheck() types.PluginHealth {
+	if err := cp.ping(); err != nil {
+		return types.PluginHealth{Healthy: false, Message: "Redis不可用：" + err.Error()}
+	}
+	return types.PluginHealth{Healthy: true, Message: "ok"}
+}
+
+// Shutdown 关闭Redis连接
+func (cp *CachePlugin) Shutdown() error {
+	if cp.client == nil {
+		return nil
+	}
+	return cp.client.Close()
+}
+
+// ping 检查Redis连接
+func (cp *CachePlugin) ping() error {
+	if cp.client == nil {
+		return errors.New("缓存插件未初始化")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cacheOpTimeout)
+	defer cancel()
+	return cp.client.Ping(ctx).Err()
+}
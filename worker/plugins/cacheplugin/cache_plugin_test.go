@@ -0,0 +1,130 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cacheplugin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/garrickvan/event-matrix/worker/types"
+	"github.com/redis/go-redis/v9"
+)
+
+// mockRedisClient 基于map的Redis客户端
+type mockRedisClient struct {
+	data   map[string]string
+	ttls   map[string]time.Duration
+	down   bool
+	closed bool
+}
+
+func newMockRedisClient() *mockRedisClient {
+	return &mockRedisClient{data: map[string]string{}, ttls: map[string]time.Duration{}}
+}
+
+func (m *mockRedisClient) Get(ctx context.Context, key string) *redis.StringCmd {
+	if m.down {
+		return redis.NewStringResult("", errors.New("connection refused"))
+	}
+	v, ok := m.data[key]
+	if !ok {
+		return redis.NewStringResult("", redis.Nil)
+	}
+	return redis.NewStringResult(v, nil)
+}
+
+func (m *mockRedisClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
+	m.data[key] = value.(string)
+	m.ttls[key] = expiration
+	return redis.NewStatusResult("OK", nil)
+}
+
+func (m *mockRedisClient) Del(ctx context.Context, keys ...string) *redis.IntCmd {
+	n := 0
+	for _, k := range keys {
+		if _, ok := m.data[k]; ok {
+			delete(m.data, k)
+			n++
+		}
+	}
+	return redis.NewIntResult(int64(n), nil)
+}
+
+func (m *mockRedisClient) Ping(ctx context.Context) *redis.StatusCmd {
+	if m.down {
+		return redis.NewStatusResult("", errors.New("connection refused"))
+	}
+	return redis.NewStatusResult("PONG", nil)
+}
+
+func (m *mockRedisClient) Close() error {
+	m.closed = true
+	return nil
+}
+
+func TestCachePluginGetSetDel(t *testing.T) {
+	client := newMockRedisClient()
+	cp := &CachePlugin{client: client}
+	var _ types.DistributedCache = cp
+
+	if _, err := cp.Get("session"); !errors.Is(err, types.ErrCacheMiss) {
+		t.Fatalf("expected cache miss, got %v", err)
+	}
+	if err := cp.Set("session", "token", time.Minute); err != nil {
+		t.Fatalf("set failed: %v", err)
+	}
+	if v, err := cp.Get("session"); err != nil || v != "token" {
+		t.Fatalf("unexpected get: %q, %v", v, err)
+	}
+	if client.ttls["session"] != time.Minute {
+		t.Fatalf("unexpected ttl: %v", client.ttls["session"])
+	}
+	if err := cp.Del("session"); err != nil {
+		t.Fatalf("del failed: %v", err)
+	}
+	if _, err := cp.Get("session"); !errors.Is(err, types.ErrCacheMiss) {
+		t.Fatalf("expected cache miss after del, got %v", err)
+	}
+}
+
+func TestCachePluginHealth(t *testing.T) {
+	client := newMockRedisClient()
+	cp := &CachePlugin{client: client}
+	if h := cp.HealthCheck(); !h.Healthy {
+		t.Fatalf("expected healthy, got %+v", h)
+	}
+	client.down = true
+	if h := cp.HealthCheck(); h.Healthy {
+		t.Fatal("expected unhealthy when redis is down")
+	}
+	if _, err := cp.Get("k"); err == nil || errors.Is(err, types.ErrCacheMiss) {
+		t.Fatalf("expected connection error, got %v", err)
+	}
+	if err := cp.Shutdown(); err != nil || !client.closed {
+		t.Fatal("expected client closed on shutdown")
+	}
+}
+
+func TestCachePluginNotInitialized(t *testing.T) {
+	cp := NewCachePlugin(nil, "redis")
+	if _, err := cp.Get("k"); err == nil {
+		t.Fatal("expected error when not initialized")
+	}
+	if err := cp.Shutdown(); err != nil {
+		t.Fatalf("shutdown failed: %v", err)
+	}
+}
@@ -0,0 +1,359 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package emailplugin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/intranet/dispatcher"
+	"github.com/garrickvan/event-matrix/worker/types"
+	"github.com/spf13/cast"
+	"gorm.io/gorm"
+)
+
+// EmailPlugin 邮件插件，通过SMTP发送事务性邮件（欢迎邮件、密码重置等）
+// 邮件模板存储在共享配置中，发送失败的邮件写入本地SQLite队列等待重试
+type EmailPlugin struct {
+	worker *types.Worker
+	svr    types.WorkerServer
+	cfg    *EmailConfig
+	queue  *gorm.DB
+
+	// sendMail 实际发送邮件的函数，默认为 smtp.SendMail
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+	mu       sync.Mutex     // 保护 stopped 和 wg 的并发操作
+	stopped  bool           // 是否已关闭
+	stopChan chan struct{}  // 关闭信号通道
+	wg       sync.WaitGroup // 重试守护协程
+}
+
+// EmailConfig 邮件插件的SMTP配置，存储在共享配置中
+type EmailConfig struct {
+	Host          string `json:"host"`          // SMTP服务器地址
+	Port          int    `json:"port"`          // SMTP服务器端口
+	Username      string `json:"username"`      // 认证用户名，为空时不认证
+	Password      string `json:"password"`      // 认证密码
+	From          string `json:"from"`          // 发件人地址
+	QueueDir      string `json:"queueDir"`      // 重试队列SQLite数据库所在目录
+	MaxRetries    int    `json:"maxRetries"`    // 最大重试次数
+	RetryInterval int    `json:"retryInterval"` // 重试间隔，单位秒
+}
+
+// EmailTemplate 邮件模板，存储在共享配置中
+type EmailTemplate struct {
+	Subject string `json:"subject"` // 默认主题，发送时未指定主题则使用该值
+	Body    string `json:"body"`    // html/template 格式的邮件正文
+}
+
+// EmailSendParams 发送邮件的请求参数
+type EmailSendParams struct {
+	To          string                 `json:"to"`          // 收件人，多个收件人用逗号分隔
+	Subject     string                 `json:"subject"`     // 邮件主题
+	TemplateKey string                 `json:"templateKey"` // 模板对应的共享配置键
+	Data        map[string]interface{} `json:"data"`        // 模板数据
+}
+
+const (
+	EmailWorkerContext = "gateway"
+	EmailWorkerEntity  = "email"
+	EmailQueueDBName   = "email_queue"
+
+	EMAIL_SEND_SUCCESS                           = string(constant.SUCCESS)
+	GW_T_W_EMAIL_SEND  types.INTRANET_EVENT_TYPE = 33000
+
+	defaultMaxRetries    = 5
+	defaultRetryInterval = 30
+	retryBatchSize       = 100
+)
+
+var (
+	emailWorker = types.Worker{
+		Project:      core.INTERNAL_PROJECT,
+		VersionLabel: constant.INITIAL_VERSION,
+		Context:      EmailWorkerContext,
+		Entity:       EmailWorkerEntity,
+		SyncSchema:   false,
+	}
+)
+
+// NewEmailPlugin 创建邮件插件实例
+// cfgKey 为SMTP配置对应的共享配置键
+func NewEmailPlugin(svr types.WorkerServer, cfgKey string) *EmailPlugin {
+	emailWorker.CfgKey = cfgKey
+	return &EmailPlugin{
+		worker:   &emailWorker,
+		svr:      svr,
+		sendMail: smtp.SendMail,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Setup 加载SMTP配置，初始化重试队列，并注册邮件插件
+func (ep *EmailPlugin) Setup() error {
+	sc := ep.svr.SharedConfigure(ep.worker.CfgKey)
+	if sc == nil {
+		return fmt.Errorf("缺少邮件配置: [%s], 请检查配置，无法启动邮件服务", ep.worker.CfgKey)
+	}
+	cfg := &EmailConfig{}
+	if err := jsonx.UnmarshalFromStr(sc.Value, cfg); err != nil {
+		return fmt.Errorf("邮件配置解析失败: %w", err)
+	}
+	if err := ep.init(cfg); err != nil {
+		return err
+	}
+	if err := ep.svr.RegisterWorker(ep.worker); err != nil {
+		return err
+	}
+	ep.svr.RegisterPlugin(ep)
+	dispatcher.ReportConfigUsedBy(ep.worker.CfgKey, ep.worker.ID)
+	ep.runDaemons()
+	return nil
+}
+
+// init 校验配置并打开本地重试队列
+func (ep *EmailPlugin) init(cfg *EmailConfig) error {
+	if cfg.Host == "" || cfg.Port <= 0 || cfg.From == "" {
+		return errors.New("邮件配置不完整，host、port、from 均不能为空")
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = defaultMaxRetries
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = defaultRetryInterval
+	}
+	if cfg.QueueDir == "" {
+		cfg.QueueDir = "./data"
+	}
+	queue, err := openQueue(cfg.QueueDir)
+	if err != nil {
+		return err
+	}
+	ep.cfg = cfg
+	ep.queue = queue
+	return nil
+}
+
+// runDaemons 启动重试守护协程
+func (ep *EmailPlugin) runDaemons() {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	if ep.stopped {
+		return
+	}
+	ep.wg.Add(1)
+	go func() {
+		defer ep.wg.Done()
+		interval := time.Duration(ep.cfg.RetryInterval) * time.Second
+		for ep.waitOrStop(interval) {
+			ep.retryQueued()
+		}
+	}()
+}
+
+// waitOrStop 等待指定时间，期间收到关闭信号则返回false
+func (ep *EmailPlugin) waitOrStop(d time.Duration) bool {
+	select {
+	case <-ep.stopChan:
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+// ReceiveCodes 返回邮件插件能够处理的事件类型列表
+func (ep *EmailPlugin) ReceiveCodes() []types.INTRANET_EVENT_TYPE {
+	return []types.INTRANET_EVENT_TYPE{GW_T_W_EMAIL_SEND}
+}
+
+// Dependencies 邮件插件不依赖其他插件
+func (ep *EmailPlugin) Dependencies() []types.INTRANET_EVENT_TYPE {
+	return nil
+}
+
+// Handle 根据接收到的事件类型调用相应的处理方法
+func (ep *EmailPlugin) Handle(ctx types.WorkerContext, typz types.INTRANET_EVENT_TYPE) error {
+	switch typz {
+	case GW_T_W_EMAIL_SEND:
+		return ep.sendHandler(ctx)
+	default:
+		return ctx.SetStatus(http.StatusForbidden).Response([]byte(constant.UNSUPPORTED_EVENT))
+	}
+}
+
+// HealthCheck 检查邮件插件的健康状态，汇报待重试邮件数
+func (ep *EmailPlugin) HealthCheck() types.PluginHealth {
+	if ep.isStopped() {
+		return types.PluginHealth{Healthy: false, Message: "邮件插件已关闭"}
+	}
+	if ep.queue == nil {
+		return types.PluginHealth{Healthy: false, Message: "邮件重试队列不可用"}
+	}
+	var queued int64
+	if err := ep.queue.Model(&QueuedEmail{}).Count(&queued).Error; err != nil {
+		return types.PluginHealth{Healthy: false, Message: "查询重试队列失败：" + err.Error()}
+	}
+	return types.PluginHealth{
+		Healthy: true,
+		Message: "ok",
+		Details: map[string]interface{}{"queued": queued},
+	}
+}
+
+// Shutdown 关闭邮件插件，停止重试并关闭本地队列
+func (ep *EmailPlugin) Shutdown() error {
+	ep.mu.Lock()
+	if ep.stopped {
+		ep.mu.Unlock()
+		return nil
+	}
+	ep.stopped = true
+	close(ep.stopChan)
+	ep.mu.Unlock()
+	ep.wg.Wait()
+	if ep.queue != nil {
+		if db, err := ep.queue.DB(); err == nil {
+			return db.Close()
+		}
+	}
+	return nil
+}
+
+// isStopped 判断邮件插件是否已关闭
+func (ep *EmailPlugin) isStopped() bool {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	return ep.stopped
+}
+
+// Send 使用模板渲染并发送邮件，发送失败时写入本地队列等待重试
+// 参数:
+//
+//	to: 收件人，多个收件人用逗号分隔
+//	subject: 邮件主题，为空时使用模板的默认主题
+//	templateKey: 模板对应的共享配置键
+//	data: 模板数据
+//
+// 返回:
+//
+//	error: 模板渲染失败或写入重试队列失败时返回错误
+func (ep *EmailPlugin) Send(to, subject, templateKey string, data map[string]interface{}) error {
+	if ep.cfg == nil {
+		return errors.New("邮件插件未初始化")
+	}
+	recipients := splitRecipients(to)
+	if len(recipients) == 0 {
+		return errors.New("收件人不能为空")
+	}
+	subject, body, err := ep.render(subject, templateKey, data)
+	if err != nil {
+		return err
+	}
+	msg := &QueuedEmail{
+		To:      strings.Join(recipients, ","),
+		Subject: subject,
+		Body:    body,
+	}
+	if err := ep.deliver(msg); err != nil {
+		logx.Warn("邮件发送失败，已加入重试队列：", err.Error())
+		msg.LastError = err.Error()
+		return ep.enqueue(msg)
+	}
+	return nil
+}
+
+// render 从共享配置中加载模板并渲染主题和正文
+func (ep *EmailPlugin) render(subject, templateKey string, data map[string]interface{}) (string, string, error) {
+	sc := ep.svr.SharedConfigure(templateKey)
+	if sc == nil {
+		return "", "", fmt.Errorf("邮件模板不存在：%s", templateKey)
+	}
+	tmplCfg := &EmailTemplate{}
+	if err := jsonx.UnmarshalFromStr(sc.Value, tmplCfg); err != nil {
+		return "", "", fmt.Errorf("邮件模板解析失败：%w", err)
+	}
+	tmpl, err := template.New(templateKey).Parse(tmplCfg.Body)
+	if err != nil {
+		return "", "", fmt.Errorf("邮件模板编译失败：%w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", "", fmt.Errorf("邮件模板渲染失败：%w", err)
+	}
+	if subject == "" {
+		subject = tmplCfg.Subject
+	}
+	return subject, buf.String(), nil
+}
+
+// deliver 通过SMTP发送邮件
+func (ep *EmailPlugin) deliver(msg *QueuedEmail) error {
+	var auth smtp.Auth
+	if ep.cfg.Username != "" {
+		auth = smtp.PlainAuth("", ep.cfg.Username, ep.cfg.Password, ep.cfg.Host)
+	}
+	addr := net.JoinHostPort(ep.cfg.Host, cast.ToString(ep.cfg.Port))
+	return ep.sendMail(addr, auth, ep.cfg.From, splitRecipients(msg.To), buildMessage(ep.cfg.From, msg))
+}
+
+// sendHandler 处理发送邮件的内网事件
+func (ep *EmailPlugin) sendHandler(ctx types.WorkerContext) error {
+	params := EmailSendParams{}
+	if err := jsonx.UnmarshalFromBytes(ctx.Body(), &params); err != nil {
+		return ctx.SetStatus(http.StatusBadRequest).Response([]byte("邮件参数解析失败：" + err.Error()))
+	}
+	if err := ep.Send(params.To, params.Subject, params.TemplateKey, params.Data); err != nil {
+		return ctx.SetStatus(http.StatusInternalServerError).Response([]byte(err.Error()))
+	}
+	return ctx.SetStatus(http.StatusOK).Response([]byte(EMAIL_SEND_SUCCESS))
+}
+
+// buildMessage 构建html格式的邮件内容
+func buildMessage(from string, msg *QueuedEmail) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("From: " + from + "\r\n")
+	buf.WriteString("To: " + msg.To + "\r\n")
+	buf.WriteString("Subject: " + mime.QEncoding.Encode("UTF-8", msg.Subject) + "\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(msg.Body)
+	return buf.Bytes()
+}
+
+// splitRecipients 拆分逗号分隔的收件人列表
+func splitRecipients(to string) []string {
+	recipients := []string{}
+	for _, r := range strings.Split(to, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			recipients = append(recipients, r)
+		}
+	}
+	return recipients
+}
@@ -0,0 +1,197 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package emailplugin
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+// fakeWorkerServer 仅提供共享配置的工作服务器
+type fakeWorkerServer struct {
+	types.WorkerServer
+	cfgs map[string]*core.SharedConfigure
+}
+
+func (f *fakeWorkerServer) SharedConfigure(sid string) *core.SharedConfigure {
+	return f.cfgs[sid]
+}
+
+// mockSMTPServer 记录收到邮件的简易SMTP服务器
+type mockSMTPServer struct {
+	ln   net.Listener
+	mu   sync.Mutex
+	msgs []string
+}
+
+func newMockSMTPServer(t *testing.T) *mockSMTPServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	s := &mockSMTPServer{ln: ln}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+func (s *mockSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+	reply("220 mock")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 mock")
+		case cmd == "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			s.mu.Lock()
+			s.msgs = append(s.msgs, data.String())
+			s.mu.Unlock()
+			reply("250 ok")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func (s *mockSMTPServer) messages() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.msgs...)
+}
+
+func (s *mockSMTPServer) port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+func newTestPlugin(t *testing.T, port int) *EmailPlugin {
+	svr := &fakeWorkerServer{cfgs: map[string]*core.SharedConfigure{
+		"welcome": {Key: "welcome", Value: `{"subject":"Welcome","body":"<p>Hello {{.name}}</p>"}`},
+	}}
+	ep := NewEmailPlugin(svr, "email")
+	err := ep.init(&EmailConfig{
+		Host:     "127.0.0.1",
+		Port:     port,
+		From:     "noreply@example.com",
+		QueueDir: t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+	t.Cleanup(func() { ep.Shutdown() })
+	return ep
+}
+
+func TestSendRendersTemplate(t *testing.T) {
+	server := newMockSMTPServer(t)
+	ep := newTestPlugin(t, server.port())
+
+	if err := ep.Send("alice@example.com", "", "welcome", map[string]interface{}{"name": "<Alice>"}); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	msgs := server.messages()
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(msgs))
+	}
+	if !strings.Contains(msgs[0], "Subject: Welcome") || !strings.Contains(msgs[0], "<p>Hello &lt;Alice&gt;</p>") {
+		t.Fatalf("unexpected message: %s", msgs[0])
+	}
+}
+
+func TestSendMissingTemplate(t *testing.T) {
+	ep := newTestPlugin(t, 25)
+	if err := ep.Send("alice@example.com", "", "missing", nil); err == nil {
+		t.Fatal("expected error for missing template")
+	}
+}
+
+func TestSendQueuesOnFailureAndRetries(t *testing.T) {
+	server := newMockSMTPServer(t)
+	// 先使用已关闭的端口使发送失败
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	closedPort := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+	ep := newTestPlugin(t, closedPort)
+
+	if err := ep.Send("bob@example.com", "Reset", "welcome", map[string]interface{}{"name": "Bob"}); err != nil {
+		t.Fatalf("send should queue on failure, got: %v", err)
+	}
+	if h := ep.HealthCheck(); h.Details["queued"] != int64(1) {
+		t.Fatalf("expected 1 queued email, got %+v", h)
+	}
+
+	// 失败的重试应增加重试次数
+	ep.retryQueued()
+	queued := QueuedEmail{}
+	ep.queue.First(&queued)
+	if queued.Retries != 1 || queued.LastError == "" {
+		t.Fatalf("unexpected queued email: %+v", queued)
+	}
+
+	// SMTP恢复后重试成功并移出队列
+	ep.cfg.Port = server.port()
+	ep.retryQueued()
+	if msgs := server.messages(); len(msgs) != 1 || !strings.Contains(msgs[0], "Subject: Reset") {
+		t.Fatalf("unexpected messages: %v", msgs)
+	}
+	if h := ep.HealthCheck(); h.Details["queued"] != int64(0) {
+		t.Fatalf("expected empty queue, got %+v", h)
+	}
+}
+
+func TestSplitRecipients(t *testing.T) {
+	got := splitRecipients(" a@example.com, ,b@example.com ")
+	if strings.Join(got, "|") != "a@example.com|b@example.com" {
+		t.Fatalf("unexpected recipients: %v", got)
+	}
+	if len(splitRecipients("")) != 0 {
+		t.Fatal("expected no recipients")
+	}
+}
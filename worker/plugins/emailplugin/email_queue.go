@@ -0,0 +1,95 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package emailplugin
+
+import (
+	"fmt"
+
+	"github.com/garrickvan/event-matrix/utils"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// QueuedEmail 待重试的邮件，存储在本地SQLite数据库中
+type QueuedEmail struct {
+	ID        uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	To        string `json:"to"`
+	Subject   string `json:"subject"`
+	Body      string `json:"body"`
+	Retries   int    `json:"retries"`
+	LastError string `json:"lastError"`
+	CreatedAt int64  `json:"createdAt"`
+	UpdatedAt int64  `json:"updatedAt"`
+}
+
+// TableName 指定重试队列的表名
+func (QueuedEmail) TableName() string {
+	return "email_queue"
+}
+
+// openQueue 打开本地SQLite重试队列并迁移表结构
+func openQueue(dir string) (*gorm.DB, error) {
+	utils.MakeDir(dir)
+	dbPath := dir + "/" + EmailQueueDBName + ".db"
+	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("打开邮件重试队列失败，路径：%s，错误：%w", dbPath, err)
+	}
+	if err := db.AutoMigrate(&QueuedEmail{}); err != nil {
+		return nil, fmt.Errorf("邮件重试队列表迁移失败：%w", err)
+	}
+	return db, nil
+}
+
+// enqueue 将发送失败的邮件写入重试队列
+func (ep *EmailPlugin) enqueue(msg *QueuedEmail) error {
+	now := utils.GetNowMilli()
+	msg.CreatedAt = now
+	msg.UpdatedAt = now
+	if err := ep.queue.Create(msg).Error; err != nil {
+		return fmt.Errorf("邮件写入重试队列失败：%w", err)
+	}
+	return nil
+}
+
+// retryQueued 重试队列中的邮件，发送成功后移出队列，超过最大重试次数的邮件将被丢弃
+func (ep *EmailPlugin) retryQueued() {
+	msgs := []*QueuedEmail{}
+	if err := ep.queue.Order("id").Limit(retryBatchSize).Find(&msgs).Error; err != nil {
+		logx.Error("读取邮件重试队列失败：", err.Error())
+		return
+	}
+	for _, msg := range msgs {
+		if ep.isStopped() {
+			return
+		}
+		err := ep.deliver(msg)
+		if err == nil || msg.Retries+1 >= ep.cfg.MaxRetries {
+			if err != nil {
+				logx.Error("邮件超过最大重试次数，已丢弃：", msg.To, " ", err.Error())
+			}
+			if err := ep.queue.Delete(msg).Error; err != nil {
+				logx.Error("邮件移出重试队列失败：", err.Error())
+			}
+			continue
+		}
+		ep.queue.Model(msg).Updates(map[string]interface{}{
+			"retries":    msg.Retries + 1,
+			"last_error": err.Error(),
+			"updated_at": utils.GetNowMilli(),
+		})
+	}
+}
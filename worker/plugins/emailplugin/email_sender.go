@@ -0,0 +1,76 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package emailplugin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/worker/intranet/dispatcher"
+)
+
+// EmailSender 邮件发送客户端，供自定义执行器通过内网事件调用邮件插件
+type EmailSender struct {
+	emailEndpoint string
+}
+
+var (
+	EmailEndpointEvent = &core.Event{
+		Project: core.INTERNAL_PROJECT,
+		Version: constant.INITIAL_VERSION,
+		Context: EmailWorkerContext,
+		Entity:  EmailWorkerEntity,
+	}
+)
+
+func init() {
+	EmailEndpointEvent.GenerateSign()
+}
+
+// NewEmailSender 创建邮件发送客户端
+func NewEmailSender() *EmailSender {
+	return &EmailSender{}
+}
+
+// Send 请求邮件插件发送邮件
+func (es *EmailSender) Send(to, subject, templateKey string, data map[string]interface{}) error {
+	if es == nil {
+		return errors.New("邮件发送客户端未初始化")
+	}
+	if es.emailEndpoint == "" {
+		endpoint := dispatcher.GetWorkerEndpoint(EmailEndpointEvent)
+		if endpoint == "" {
+			return errors.New("获取邮件服务地址失败，网络错误或邮件插件未启动")
+		}
+		es.emailEndpoint = endpoint
+	}
+	params := &EmailSendParams{
+		To:          to,
+		Subject:     subject,
+		TemplateKey: templateKey,
+		Data:        data,
+	}
+	resp, err := dispatcher.Event(es.emailEndpoint, GW_T_W_EMAIL_SEND, params, nil)
+	if err != nil {
+		es.emailEndpoint = "" // 网络错误，清空emailEndpoint
+		return err
+	}
+	if resp.Status() != http.StatusOK {
+		return errors.New(resp.TemporaryData())
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fileplugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/worker/intranet/dispatcher"
+	"github.com/garrickvan/event-matrix/worker/types"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// FilePlugin 文件存储插件，基于S3兼容的对象存储（AWS S3、MinIO等）提供分片上传、下载和删除
+type FilePlugin struct {
+	svr    types.WorkerServer
+	cfgKey string
+	cfg    *StorageConfig
+	client *minio.Client
+}
+
+// StorageConfig 对象存储配置，对应类型为 s3 的共享配置
+type StorageConfig struct {
+	Endpoint  string `json:"endpoint"`  // 服务地址，如 s3.amazonaws.com、127.0.0.1:9000
+	AccessKey string `json:"accessKey"` // 访问密钥ID
+	SecretKey string `json:"secretKey"` // 访问密钥
+	Region    string `json:"region"`    // 区域，可选
+	UseSSL    bool   `json:"useSSL"`    // 是否使用HTTPS
+	PublicURL string `json:"publicUrl"` // 对外访问地址，为空时使用服务地址拼接
+	PartSize  uint64 `json:"partSize"`  // 分片上传的分片大小，单位字节，为0时使用默认值
+
+	// DisableContentSha256 不对上传内容签名，兼容不支持分块签名的S3实现
+	DisableContentSha256 bool `json:"disableContentSha256"`
+}
+
+// FileStat 文件元信息
+type FileStat struct {
+	Bucket      string `json:"bucket"`
+	Key         string `json:"key"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"contentType"`
+	ETag        string `json:"etag"`
+}
+
+const (
+	G_T_W_FILE_STAT types.INTRANET_EVENT_TYPE = 38000
+
+	defaultPartSize = 16 << 20 // 默认分片大小16MB
+)
+
+// NewFilePlugin 创建文件存储插件，cfgKey 为对象存储配置的共享配置键
+func NewFilePlugin(svr types.WorkerServer, cfgKey string) *FilePlugin {
+	return &FilePlugin{
+		svr:    svr,
+		cfgKey: cfgKey,
+	}
+}
+
+// Setup 读取对象存储配置并创建客户端，注册文件存储插件
+func (fp *FilePlugin) Setup() error {
+	sc := fp.svr.SharedConfigure(fp.cfgKey)
+	if sc == nil {
+		return fmt.Errorf("缺少对象存储配置: [%s], 请检查配置，无法启动文件存储插件", fp.cfgKey)
+	}
+	if !core.IsStorageConfigType(sc.Type) {
+		return fmt.Errorf("配置 [%s] 的类型为 %s，不是对象存储配置", fp.cfgKey, sc.Type)
+	}
+	cfg := &StorageConfig{}
+	if err := jsonx.UnmarshalFromStr(sc.Value, cfg); err != nil {
+		return fmt.Errorf("对象存储配置解析失败: %w", err)
+	}
+	if err := fp.init(cfg); err != nil {
+		return err
+	}
+	fp.svr.RegisterPlugin(fp)
+	dispatcher.ReportConfigUsedBy(fp.cfgKey, fp.svr.ServerId())
+	return nil
+}
+
+// init 根据配置创建对象存储客户端
+func (fp *FilePlugin) init(cfg *StorageConfig) error {
+	if cfg.Endpoint == "" {
+		return errors.New("对象存储配置缺少endpoint")
+	}
+	if cfg.PartSize == 0 {
+		cfg.PartSize = defaultPartSize
+	}
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return fmt.Errorf("创建对象存储客户端失败: %w", err)
+	}
+	fp.cfg = cfg
+	fp.client = client
+	return nil
+}
+
+// Upload 上传文件，大小未知时按分片流式上传
+// 参数:
+//
+//	bucket: 存储桶
+//	key: 对象键
+//	reader: 文件内容
+//	contentType: 文件类型，如 image/png
+//
+// 返回:
+//
+//	string: 文件访问地址
+//	error: 上传失败时返回错误
+func (fp *FilePlugin) Upload(bucket, key string, reader io.Reader, contentType string) (string, error) {
+	if fp.client == nil {
+		return "", errors.New("文件存储插件未初始化")
+	}
+	if bucket == "" || key == "" {
+		return "", errors.New("存储桶和对象键不能为空")
+	}
+	_, err := fp.client.PutObject(context.Background(), bucket, key, reader, -1, minio.PutObjectOptions{
+		ContentType:          contentType,
+		PartSize:             fp.cfg.PartSize,
+		DisableContentSha256: fp.cfg.DisableContentSha256,
+	})
+	if err != nil {
+		return "", fmt.Errorf("文件上传失败: %w", err)
+	}
+	return fp.ObjectURL(bucket, key), nil
+}
+
+// Download 下载文件，调用方负责关闭返回的读取器
+func (fp *FilePlugin) Download(bucket, key string) (io.ReadCloser, error) {
+	if fp.client == nil {
+		return nil, errors.New("文件存储插件未初始化")
+	}
+	obj, err := fp.client.GetObject(context.Background(), bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("文件下载失败: %w", err)
+	}
+	// GetObject 延迟到首次读取时才发起请求，先获取元信息以便及时返回文件不存在等错误
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		return nil, fmt.Errorf("文件下载失败: %w", err)
+	}
+	return obj, nil
+}
+
+// Delete 删除文件
+func (fp *FilePlugin) Delete(bucket, key string) error {
+	if fp.client == nil {
+		return errors.New("文件存储插件未初始化")
+	}
+	if err := fp.client.RemoveObject(context.Background(), bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("文件删除失败: %w", err)
+	}
+	return nil
+}
+
+// Stat 获取文件元信息
+func (fp *FilePlugin) Stat(bucket, key string) (*FileStat, error) {
+	if fp.client == nil {
+		return nil, errors.New("文件存储插件未初始化")
+	}
+	info, err := fp.client.StatObject(context.Background(), bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return &FileStat{
+		Bucket:      bucket,
+		Key:         key,
+		Size:        info.Size,
+		ContentType: info.ContentType,
+		ETag:        info.ETag,
+	}, nil
+}
+
+// ObjectURL 获取文件访问地址
+func (fp *FilePlugin) ObjectURL(bucket, key string) string {
+	base := fp.cfg.PublicURL
+	if base == "" {
+		scheme := "http"
+		if fp.cfg.UseSSL {
+			scheme = "https"
+		}
+		base = scheme + "://" + fp.cfg.Endpoint
+	}
+	return strings.TrimRight(base, "/") + "/" + url.PathEscape(bucket) + "/" + escapeKey(key)
+}
+
+// escapeKey 对对象键逐段转义，保留路径分隔符
+func escapeKey(key string) string {
+	parts := strings.Split(key, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
+
+// ReceiveCodes 返回文件存储插件能够处理的事件类型列表
+func (fp *FilePlugin) ReceiveCodes() []types.INTRANET_EVENT_TYPE {
+	return []types.INTRANET_EVENT_TYPE{G_T_W_FILE_STAT}
+}
+
+// Dependencies 文件存储插件不依赖其他插件
+func (fp *FilePlugin) Dependencies() []types.INTRANET_EVENT_TYPE {
+	return nil
+}
+
+// Handle 根据接收到的事件类型调用相应的处理方法
+func (fp *FilePlugin) Handle(ctx types.WorkerContext, typz types.INTRANET_EVENT_TYPE) error {
+	switch typz {
+	case G_T_W_FILE_STAT:
+		params := FileStat{}
+		if err := jsonx.UnmarshalFromBytes(ctx.Body(), &params); err != nil {
+			return ctx.SetStatus(http.StatusBadRequest).ResponseString("文件参数解析失败：" + err.Error())
+		}
+		stat, err := fp.Stat(params.Bucket, params.Key)
+		if err != nil {
+			return ctx.SetStatus(http.StatusNotFound).ResponseString(err.Error())
+		}
+		return ctx.SetStatus(http.StatusOK).ResponseJson(stat)
+	default:
+		return ctx.SetStatus(http.StatusForbidden).Response([]byte(constant.UNSUPPORTED_EVENT))
+	}
+}
+
+// HealthCheck 检查文件存储插件的健康状态
+func (fp *FilePlugin) HealthCheck() types.PluginHealth {
+	if fp.client == nil {
+		return types.PluginHealth{Healthy: false, Message: "文件存储插件未初始化"}
+	}
+	if fp.client.IsOffline() {
+		return types.PluginHealth{Healthy: false, Message: "对象存储服务不可用"}
+	}
+	return types.PluginHealth{Healthy: true, Message: "ok", Details: map[string]interface{}{"endpoint": fp.cfg.Endpoint}}
+}
+
+// Shutdown 关闭文件存储插件，客户端无需释放，直接返回
+func (fp *FilePlugin) Shutdown() error {
+	return nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fileplugin
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+)
+
+func newTestPlugin(t *testing.T) *FilePlugin {
+	backend := s3mem.New()
+	faker := gofakes3.New(backend)
+	server := httptest.NewServer(faker.Server())
+	t.Cleanup(server.Close)
+	if err := backend.CreateBucket("avatars"); err != nil {
+		t.Fatalf("failed to create bucket: %v", err)
+	}
+
+	fp := NewFilePlugin(nil, "s3")
+	err := fp.init(&StorageConfig{
+		Endpoint:  strings.TrimPrefix(server.URL, "http://"),
+		AccessKey: "key",
+		SecretKey: "secret",
+		Region:    "us-east-1",
+		PartSize:  5 << 20,
+		// 模拟服务器不支持分块签名
+		DisableContentSha256: true,
+	})
+	if err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+	return fp
+}
+
+func TestUploadDownloadDelete(t *testing.T) {
+	fp := newTestPlugin(t)
+	content := []byte("avatar-bytes")
+
+	u, err := fp.Upload("avatars", "users/u1 avatar.png", bytes.NewReader(content), "image/png")
+	if err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+	if !strings.HasSuffix(u, "/avatars/users/u1%20avatar.png") {
+		t.Fatalf("unexpected url: %s", u)
+	}
+
+	rc, err := fp.Download("avatars", "users/u1 avatar.png")
+	if err != nil {
+		t.Fatalf("download failed: %v", err)
+	}
+	got, _ := io.ReadAll(rc)
+	rc.Close()
+	if !bytes.Equal(got, content) {
+		t.Fatalf("unexpected content: %q", got)
+	}
+
+	stat, err := fp.Stat("avatars", "users/u1 avatar.png")
+	if err != nil || stat.Size != int64(len(content)) || stat.ContentType != "image/png" {
+		t.Fatalf("unexpected stat: %+v, %v", stat, err)
+	}
+
+	if err := fp.Delete("avatars", "users/u1 avatar.png"); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if _, err := fp.Download("avatars", "users/u1 avatar.png"); err == nil {
+		t.Fatal("expected error downloading deleted file")
+	}
+}
+
+func TestUploadMultipart(t *testing.T) {
+	fp := newTestPlugin(t)
+	// 大于分片大小，触发分片上传
+	content := bytes.Repeat([]byte("x"), 6<<20)
+	if _, err := fp.Upload("avatars", "reports/big.bin", bytes.NewReader(content), "application/octet-stream"); err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+	stat, err := fp.Stat("avatars", "reports/big.bin")
+	if err != nil || stat.Size != int64(len(content)) {
+		t.Fatalf("unexpected stat: %+v, %v", stat, err)
+	}
+}
+
+func TestObjectURLWithPublicURL(t *testing.T) {
+	fp := &FilePlugin{cfg: &StorageConfig{Endpoint: "s3.local", PublicURL: "https://cdn.example.com/"}}
+	if u := fp.ObjectURL("b", "a/b.png"); u != "https://cdn.example.com/b/a/b.png" {
+		t.Fatalf("unexpected url: %s", u)
+	}
+	if _, err := fp.Upload("b", "k", nil, ""); err == nil {
+		t.Fatal("expected error when not initialized")
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package logcenter
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+)
+
+// ARCHIVE_FILE_NAME 归档文件名，位于按天分区的目录下
+const ARCHIVE_FILE_NAME = "events.jsonl.gz"
+
+// EventLogArchiver 事件日志归档接口，清理过期事件日志前调用，用于长期保存审计日志
+// Archive 返回错误时，本批日志不会从数据库删除
+type EventLogArchiver interface {
+	Archive(logs []core.EventLog) error
+}
+
+// archivePartition 返回日志归档分区路径，按事件完成时间分区，格式为 YYYY/MM/DD
+func archivePartition(log *core.EventLog) string {
+	return time.UnixMilli(log.FinishAt).Format("2006/01/02")
+}
+
+// groupByPartition 按分区对日志分组
+func groupByPartition(logs []core.EventLog) map[string][]core.EventLog {
+	groups := map[string][]core.EventLog{}
+	for i := range logs {
+		partition := archivePartition(&logs[i])
+		groups[partition] = append(groups[partition], logs[i])
+	}
+	return groups
+}
+
+// FileEventLogArchiver 将事件日志以 gzip 压缩的 JSONL 格式归档至本地文件系统
+// 文件路径为 {root}/YYYY/MM/DD/events.jsonl.gz，每次归档追加一个 gzip 分段
+type FileEventLogArchiver struct {
+	root string
+	mu   sync.Mutex
+}
+
+// NewFileEventLogArchiver 创建本地文件归档器，root 为归档根目录
+func NewFileEventLogArchiver(root string) *FileEventLogArchiver {
+	return &FileEventLogArchiver{root: root}
+}
+
+// Archive 按天分区追加写入事件日志
+func (a *FileEventLogArchiver) Archive(logs []core.EventLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	groups := groupByPartition(logs)
+	partitions := make([]string, 0, len(groups))
+	for partition := range groups {
+		partitions = append(partitions, partition)
+	}
+	sort.Strings(partitions)
+	for _, partition := range partitions {
+		file := filepath.Join(a.root, filepath.FromSlash(partition), ARCHIVE_FILE_NAME)
+		if err := appendArchiveFile(file, groups[partition]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendArchiveFile 以新的 gzip 分段追加写入日志，gzip 读取时会自动连接多个分段
+func appendArchiveFile(file string, logs []core.EventLog) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(f)
+	bw := bufio.NewWriter(zw)
+	for i := range logs {
+		line, err := jsonx.MarshalToBytes(&logs[i])
+		if err != nil {
+			f.Close()
+			return err
+		}
+		bw.Write(line)
+		bw.WriteByte('\n')
+	}
+	if err := bw.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// S3EventLogArchiver 将事件日志归档至 S3 兼容的对象存储，对象键为 {prefix}/YYYY/MM/DD/events-{时间戳}.jsonl.gz
+// 目前仅为占位实现，接入对象存储 SDK 后实现 Archive
+type S3EventLogArchiver struct {
+	Bucket string // 存储桶
+	Prefix string // 对象键前缀
+	Region string // 区域
+}
+
+// NewS3EventLogArchiver 创建 S3 归档器
+func NewS3EventLogArchiver(bucket, prefix, region string) *S3EventLogArchiver {
+	return &S3EventLogArchiver{Bucket: bucket, Prefix: prefix, Region: region}
+}
+
+// ObjectKey 返回分区对应的对象键
+func (a *S3EventLogArchiver) ObjectKey(partition string, now time.Time) string {
+	return path.Join(a.Prefix, partition, "events-"+now.Format("20060102150405")+".jsonl.gz")
+}
+
+// Archive 尚未实现，始终返回错误，避免日志在未归档的情况下被删除
+func (a *S3EventLogArchiver) Archive(logs []core.EventLog) error {
+	return errors.New("S3 事件日志归档尚未实现")
+}
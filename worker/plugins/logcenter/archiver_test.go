@@ -0,0 +1,127 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package logcenter
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+// readArchive 读取归档文件中的全部日志
+func readArchive(t *testing.T, file string) []core.EventLog {
+	t.Helper()
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs := []core.EventLog{}
+	scanner := bufio.NewScanner(zr)
+	for scanner.Scan() {
+		log := core.EventLog{}
+		if err := jsonx.UnmarshalFromBytes(scanner.Bytes(), &log); err != nil {
+			t.Fatal(err)
+		}
+		logs = append(logs, log)
+	}
+	return logs
+}
+
+func TestFileEventLogArchiver(t *testing.T) {
+	dir := t.TempDir()
+	day1 := time.Date(2024, 7, 27, 10, 0, 0, 0, time.Local).UnixMilli()
+	day2 := time.Date(2024, 7, 28, 10, 0, 0, 0, time.Local).UnixMilli()
+	archiver := NewFileEventLogArchiver(dir)
+	if err := archiver.Archive([]core.EventLog{{ID: "1", FinishAt: day1}, {ID: "2", FinishAt: day2}}); err != nil {
+		t.Fatal(err)
+	}
+	// 再次归档时追加到同一分区文件
+	if err := archiver.Archive([]core.EventLog{{ID: "3", FinishAt: day1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	logs := readArchive(t, filepath.Join(dir, "2024", "07", "27", ARCHIVE_FILE_NAME))
+	if len(logs) != 2 || logs[0].ID != "1" || logs[1].ID != "3" {
+		t.Errorf("分区 2024/07/27 归档内容错误: %+v", logs)
+	}
+	logs = readArchive(t, filepath.Join(dir, "2024", "07", "28", ARCHIVE_FILE_NAME))
+	if len(logs) != 1 || logs[0].ID != "2" {
+		t.Errorf("分区 2024/07/28 归档内容错误: %+v", logs)
+	}
+}
+
+// failingArchiver 始终归档失败
+type failingArchiver struct{}
+
+func (failingArchiver) Archive(logs []core.EventLog) error { return errors.New("archive failed") }
+
+func TestPurgeEventLogs(t *testing.T) {
+	ws := types.NewMockWorkerServer(nil)
+	defer ws.Stop()
+	db := ws.Repo().Use(EventLogDB)
+	if err := db.AutoMigrate(&core.EventLog{}); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	logs := []core.EventLog{}
+	for i := 0; i < batchSize+5; i++ {
+		logs = append(logs, core.EventLog{ID: fmt.Sprintf("old-%d", i), FinishAt: now.Add(-48 * time.Hour).UnixMilli()})
+	}
+	logs = append(logs, core.EventLog{ID: "new", FinishAt: now.UnixMilli()})
+	if err := db.CreateInBatches(logs, 50).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	lc := NewLogCenter(ws, "", "")
+	lc.SetEventLogRetention(24*time.Hour, 0)
+
+	// 归档失败时不删除
+	lc.SetArchiver(failingArchiver{})
+	if n, err := lc.purgeEventLogs(now); err == nil || n != 0 {
+		t.Fatalf("归档失败时应返回错误且不删除日志: n=%d err=%v", n, err)
+	}
+
+	dir := t.TempDir()
+	lc.SetArchiver(NewFileEventLogArchiver(dir))
+	n, err := lc.purgeEventLogs(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != batchSize+5 {
+		t.Fatalf("期望清理 %d 条日志，实际 %d", batchSize+5, n)
+	}
+	var remain []core.EventLog
+	ws.Repo().Use(EventLogDB).Find(&remain)
+	if len(remain) != 1 || remain[0].ID != "new" {
+		t.Errorf("未过期的日志应保留: %+v", remain)
+	}
+	partition := filepath.FromSlash(now.Add(-48 * time.Hour).Format("2006/01/02"))
+	if archived := readArchive(t, filepath.Join(dir, partition, ARCHIVE_FILE_NAME)); len(archived) != batchSize+5 {
+		t.Errorf("期望归档 %d 条日志，实际 %d", batchSize+5, len(archived))
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logcenter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+const (
+	DEFAULT_ES_RUNTIME_INDEX = "event-matrix-runtime-log" // 运行日志默认写入的索引
+	DEFAULT_ES_EVENT_INDEX   = "event-matrix-event-log"   // 事件日志默认写入的索引
+	DEFAULT_ES_AUDIT_INDEX   = "event-matrix-audit-log"   // 审计记录默认写入的索引
+	DEFAULT_ES_TIMEOUT       = 10 * time.Second           // 批量写入请求的默认超时时间
+)
+
+// ElasticsearchConfig Elasticsearch日志输出配置，对应类型为 LOG_ELASTICSEARCH 的共享配置
+type ElasticsearchConfig struct {
+	Endpoint     string `json:"endpoint"`      // ES地址，如 http://127.0.0.1:9200
+	RuntimeIndex string `json:"runtime_index"` // 运行日志索引，可选
+	EventIndex   string `json:"event_index"`   // 事件日志索引，可选
+	AuditIndex   string `json:"audit_index"`   // 审计记录索引，可选
+	UserName     string `json:"user_name"`     // 基础认证用户名，可选
+	Password     string `json:"password"`      // 基础认证密码，可选
+	APIKey       string `json:"api_key"`       // API Key，设置后优先于基础认证，可选
+}
+
+// ElasticsearchSink 以 _bulk 接口将日志写入Elasticsearch，文档ID为日志ID，重复提交时覆盖写入
+type ElasticsearchSink struct {
+	cfg    *ElasticsearchConfig
+	client *http.Client
+}
+
+// NewElasticsearchSink 创建Elasticsearch日志输出，client 为空时使用默认超时的客户端
+func NewElasticsearchSink(cfg *ElasticsearchConfig, client *http.Client) (*ElasticsearchSink, error) {
+	if cfg == nil || strings.TrimSpace(cfg.Endpoint) == "" {
+		return nil, errors.New("Elasticsearch配置缺少endpoint")
+	}
+	if cfg.RuntimeIndex == "" {
+		cfg.RuntimeIndex = DEFAULT_ES_RUNTIME_INDEX
+	}
+	if cfg.EventIndex == "" {
+		cfg.EventIndex = DEFAULT_ES_EVENT_INDEX
+	}
+	if cfg.AuditIndex == "" {
+		cfg.AuditIndex = DEFAULT_ES_AUDIT_INDEX
+	}
+	if client == nil {
+		client = &http.Client{Timeout: DEFAULT_ES_TIMEOUT}
+	}
+	return &ElasticsearchSink{cfg: cfg, client: client}, nil
+}
+
+// NewElasticsearchSinkFromSharedConfig 根据共享配置创建Elasticsearch日志输出
+func NewElasticsearchSinkFromSharedConfig(ws types.WorkerServer, cfgKey string) (*ElasticsearchSink, error) {
+	sc := ws.SharedConfigure(cfgKey)
+	if sc == nil {
+		return nil, fmt.Errorf("日志输出配置不存在：%s", cfgKey)
+	}
+	if sc.Type != core.LOG_ELASTICSEARCH {
+		return nil, fmt.Errorf("不支持的日志输出配置类型：%s", sc.Type)
+	}
+	cfg := &ElasticsearchConfig{}
+	if err := jsonx.UnmarshalFromStr(sc.Value, cfg); err != nil {
+		return nil, fmt.Errorf("Elasticsearch配置解析失败：%w", err)
+	}
+	return NewElasticsearchSink(cfg, nil)
+}
+
+func (s *ElasticsearchSink) Write(logs []logx.LogEntry) error {
+	docs := make([]esDocument, 0, len(logs))
+	for i := range logs {
+		docs = append(docs, esDocument{id: logs[i].ID, source: &logs[i]})
+	}
+	return s.bulk(s.cfg.RuntimeIndex, docs)
+}
+
+func (s *ElasticsearchSink) WriteEvents(events []core.EventLog) error {
+	docs := make([]esDocument, 0, len(events))
+	for i := range events {
+		docs = append(docs, esDocument{id: events[i].ID, source: &events[i]})
+	}
+	return s.bulk(s.cfg.EventIndex, docs)
+}
+
+func (s *ElasticsearchSink) WriteAudits(audits []core.AuditEntry) error {
+	docs := make([]esDocument, 0, len(audits))
+	for i := range audits {
+		docs = append(docs, esDocument{id: audits[i].ID, source: &audits[i]})
+	}
+	return s.bulk(s.cfg.AuditIndex, docs)
+}
+
+// esDocument 待写入的文档
+type esDocument struct {
+	id     string
+	source interface{}
+}
+
+// esBulkResponse _bulk 接口的响应，只关心是否存在失败的文档
+type esBulkResponse struct {
+	Errors bool `json:"errors"`
+}
+
+// bulk 以 NDJSON 格式批量写入文档
+func (s *ElasticsearchSink) bulk(index string, docs []esDocument) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	body, err := buildBulkBody(index, docs)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimRight(s.cfg.Endpoint, "/")+"/_bulk", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if s.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "ApiKey "+s.cfg.APIKey)
+	} else if s.cfg.UserName != "" {
+		req.SetBasicAuth(s.cfg.UserName, s.cfg.Password)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Elasticsearch批量写入失败：%d %s", resp.StatusCode, string(respBody))
+	}
+	result := esBulkResponse{}
+	if err := jsonx.UnmarshalFromBytes(respBody, &result); err != nil {
+		return fmt.Errorf("Elasticsearch响应解析失败：%w", err)
+	}
+	if result.Errors {
+		return errors.New("Elasticsearch批量写入存在失败的文档")
+	}
+	return nil
+}
+
+// buildBulkBody 生成 _bulk 请求体，每个文档一行操作和一行数据
+func buildBulkBody(index string, docs []esDocument) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, doc := range docs {
+		action, err := jsonx.MarshalToBytes(map[string]interface{}{
+			"index": map[string]string{"_index": index, "_id": doc.id},
+		})
+		if err != nil {
+			return nil, err
+		}
+		source, err := jsonx.MarshalToBytes(doc.source)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(action)
+		buf.WriteByte('\n')
+		buf.Write(source)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
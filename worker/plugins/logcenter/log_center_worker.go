@@ -17,8 +17,11 @@ package logcenter
 import (
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/garrickvan/event-matrix/constant"
 	"github.com/garrickvan/event-matrix/core"
@@ -26,6 +29,8 @@ import (
 	"github.com/garrickvan/event-matrix/utils/logx"
 	"github.com/garrickvan/event-matrix/worker/intranet/dispatcher"
 	"github.com/garrickvan/event-matrix/worker/types"
+	"go.uber.org/zap/zapcore"
+	"gorm.io/gorm"
 )
 
 type LogCenter struct {
@@ -33,6 +38,16 @@ type LogCenter struct {
 	worker *types.Worker
 
 	runtimeDBCfgKey, eventDBCfgKey string
+
+	archiver          EventLogArchiver // 事件日志归档器，为空时清理前不归档
+	eventLogRetention time.Duration    // 事件日志在数据库中的保留时长，小于等于0时不清理
+	purgeInterval     time.Duration    // 清理过期事件日志的间隔
+	stopPurge         chan struct{}    // 停止清理任务
+
+	sinks []LogSink // 日志输出，第一个为默认的数据库输出
+
+	MinLevel string       // 运行日志写入的最低级别，为空时为 info，低于该级别的运行日志不保存
+	levelMu  sync.RWMutex // 保护 MinLevel 的运行时修改
 }
 
 /**
@@ -45,9 +60,15 @@ const (
 	LogCenterWorkerContext = "gateway"
 	LogCenterWorkerEntity  = "log_center"
 
+	// LogLevelCfgKey 日志中心最低日志级别的共享配置键，值形如 {"minLevel":"warn"}
+	LogLevelCfgKey = "log_center_level"
+	// DefaultMinLevel 未设置最低日志级别时使用的级别
+	DefaultMinLevel = "info"
+
 	GW_T_W_RUNTIME_LOG_SUBMIT types.INTRANET_EVENT_TYPE = 31000
 	GW_T_W_EVENT_LOG_SUBMIT   types.INTRANET_EVENT_TYPE = 31001
 	G_T_W_LOG_CENTER_QUERY    types.INTRANET_EVENT_TYPE = 31002
+	GW_T_W_AUDIT_LOG_SUBMIT   types.INTRANET_EVENT_TYPE = 31003
 )
 
 var (
@@ -67,6 +88,8 @@ func NewLogCenter(ws types.WorkerServer, runtimeDBCfgKey, eventDBCfgKey string)
 		worker:          &logCenterWorker,
 		runtimeDBCfgKey: runtimeDBCfgKey,
 		eventDBCfgKey:   eventDBCfgKey,
+		purgeInterval:   DEFAULT_PURGE_INTERVAL,
+		sinks:           []LogSink{&dbLogSink{svr: ws}},
 	}
 }
 func (lc *LogCenter) Setup() error {
@@ -84,6 +107,7 @@ func (lc *LogCenter) Setup() error {
 	}
 	lc.svr.RegisterPlugin(lc)
 	dispatcher.ReportConfigUsedBy(lc.runtimeDBCfgKey, lc.worker.ID)
+	lc.startPurge()
 	return nil
 }
 
@@ -101,14 +125,19 @@ func (lc *LogCenter) initDB() error {
 	if err := lc.svr.Repo().Use(RuntimeLogDB).AutoMigrate(&logx.LogEntry{}); err != nil {
 		return err
 	}
-	if err := lc.svr.Repo().Use(EventLogDB).AutoMigrate(&core.EventLog{}); err != nil {
+	if err := lc.svr.Repo().Use(EventLogDB).AutoMigrate(&core.EventLog{}, &core.AuditEntry{}); err != nil {
 		return err
 	}
 	return nil
 }
 
 func (lc *LogCenter) ReceiveCodes() []types.INTRANET_EVENT_TYPE {
-	return []types.INTRANET_EVENT_TYPE{GW_T_W_RUNTIME_LOG_SUBMIT, GW_T_W_EVENT_LOG_SUBMIT, G_T_W_LOG_CENTER_QUERY}
+	return []types.INTRANET_EVENT_TYPE{GW_T_W_RUNTIME_LOG_SUBMIT, GW_T_W_EVENT_LOG_SUBMIT, G_T_W_LOG_CENTER_QUERY, GW_T_W_AUDIT_LOG_SUBMIT}
+}
+
+// Dependencies 日志中心不依赖其他插件
+func (lc *LogCenter) Dependencies() []types.INTRANET_EVENT_TYPE {
+	return nil
 }
 
 func (lc *LogCenter) Handle(ctx types.WorkerContext, typz types.INTRANET_EVENT_TYPE) error {
@@ -122,17 +151,55 @@ func (lc *LogCenter) Handle(ctx types.WorkerContext, typz types.INTRANET_EVENT_T
 		return lc.handlerEventLog(ctx)
 	case G_T_W_LOG_CENTER_QUERY:
 		return lc.handlerQueryLog(ctx)
+	case GW_T_W_AUDIT_LOG_SUBMIT:
+		return lc.handlerAuditLog(ctx)
 	default:
 		return ctx.SetStatus(http.StatusBadRequest).Response([]byte("日志中心不存在类型: " + fmt.Sprintf("%d", typz)))
 	}
 }
 
+// HealthCheck 检查日志中心的健康状态，汇报本地待提交的日志字节数
+func (lc *LogCenter) HealthCheck() types.PluginHealth {
+	details := map[string]interface{}{}
+	if submitter != nil {
+		details["pending_bytes"] = submitter.PendingBytes()
+	}
+	if lc.svr == nil || lc.svr.Repo() == nil ||
+		!lc.svr.Repo().HasDB(RuntimeLogDB) || !lc.svr.Repo().HasDB(EventLogDB) {
+		return types.PluginHealth{Healthy: false, Message: "日志数据库不可用", Details: details}
+	}
+	return types.PluginHealth{Healthy: true, Message: "ok", Details: details}
+}
+
+// Shutdown 关闭日志中心，停止过期事件日志的清理任务，日志写入均为同步处理，无需额外清理
+func (lc *LogCenter) Shutdown() error {
+	if lc.stopPurge != nil {
+		close(lc.stopPurge)
+		lc.stopPurge = nil
+	}
+	return nil
+}
+
 type LogListParam struct {
 	LogType     string `json:"logType"`
 	SearchField string `json:"searchField"`
 	SearchValue string `json:"searchValue"`
 	Page        int    `json:"page"`
 	Size        int    `json:"size"`
+	StartAt     int64  `json:"startAt"` // 起始时间，Unix毫秒时间戳，为0表示不限制
+	EndAt       int64  `json:"endAt"`   // 结束时间，Unix毫秒时间戳，为0表示不限制
+}
+
+// applyTimeRange 按时间范围过滤日志，StartAt 或 EndAt 非0时生效，未设置的一端不限制
+func (p *LogListParam) applyTimeRange(db *gorm.DB, column string) {
+	if p.StartAt == 0 && p.EndAt == 0 {
+		return
+	}
+	endAt := p.EndAt
+	if endAt == 0 {
+		endAt = math.MaxInt64
+	}
+	db.Where(column+" BETWEEN ? AND ?", p.StartAt, endAt)
 }
 
 const batchSize = 100
@@ -144,39 +211,61 @@ func (lc *LogCenter) handlerRuntimeLog(ctx types.WorkerContext) error {
 	if err != nil {
 		return ctx.SetStatus(http.StatusBadRequest).Response([]byte("添加运行日志失败"))
 	}
-	// 找出已保存的日志并舍弃
-	logIds := []string{}
+	logs = lc.filterLogs(logs)
+	if len(logs) == 0 {
+		return ctx.SetStatus(http.StatusOK).Response([]byte(constant.SUCCESS))
+	}
+	if err := lc.writeLogs(logs); err != nil {
+		logx.Error(err.Error())
+		return ctx.SetStatus(http.StatusInternalServerError).Response([]byte("新增日志失败"))
+	}
+	return ctx.SetStatus(http.StatusOK).Response([]byte(constant.SUCCESS))
+}
+
+// SetMinLevel 设置运行日志写入的最低级别，可在运行时调整
+func (lc *LogCenter) SetMinLevel(level string) {
+	lc.levelMu.Lock()
+	defer lc.levelMu.Unlock()
+	lc.MinLevel = strings.ToLower(strings.TrimSpace(level))
+}
+
+// minLevel 返回运行日志写入的最低级别
+func (lc *LogCenter) minLevel() zapcore.Level {
+	lc.levelMu.RLock()
+	level := lc.MinLevel
+	lc.levelMu.RUnlock()
+	if level == "" {
+		level = DefaultMinLevel
+	}
+	return logx.LevelFromStr(level)
+}
+
+// filterLogs 过滤低于最低级别的运行日志
+func (lc *LogCenter) filterLogs(logs []logx.LogEntry) []logx.LogEntry {
+	threshold := lc.minLevel()
+	kept := logs[:0]
 	for _, log := range logs {
-		logIds = append(logIds, log.ID)
-	}
-	logsInDB := []logx.LogEntry{}
-	ctx.Server().Repo().Use(RuntimeLogDB).Where("id in?", logIds).Find(&logsInDB)
-	if len(logsInDB) > 0 {
-		for _, log := range logsInDB {
-			for i, l := range logs {
-				if l.ID == log.ID {
-					// 移除存在的，避免重复插入
-					logs = append(logs[:i], logs[i+1:]...)
-				}
-			}
+		if logx.LevelFromStr(log.Level) >= threshold {
+			kept = append(kept, log)
 		}
 	}
-	// 新建日志
-	if len(logs) > 0 {
-		// 分批插入
-		for i := 0; i < len(logs); i += batchSize {
-			end := i + batchSize
-			if end > len(logs) {
-				end = len(logs)
-			}
-			db := ctx.Server().Repo().Use(RuntimeLogDB).Create(logs[i:end])
-			if db.Error != nil {
-				logx.Error("新增日志失败: " + db.Error.Error())
-				return ctx.SetStatus(http.StatusInternalServerError).Response([]byte("新增日志失败"))
-			}
-		}
+	return kept
+}
+
+// OnSharedConfigureChange 网关推送日志级别配置变更时调整最低日志级别
+func (lc *LogCenter) OnSharedConfigureChange(cfg *core.SharedConfigure) error {
+	if cfg == nil || cfg.Key != LogLevelCfgKey {
+		return nil
 	}
-	return ctx.SetStatus(http.StatusOK).Response([]byte(constant.SUCCESS))
+	value := struct {
+		MinLevel string `json:"minLevel"`
+	}{}
+	if err := jsonx.UnmarshalFromStr(cfg.Value, &value); err != nil {
+		return errors.New("日志级别配置格式错误: " + err.Error())
+	}
+	lc.SetMinLevel(value.MinLevel)
+	logx.Info("日志中心最低日志级别已调整为: " + value.MinLevel)
+	return nil
 }
 
 func (lc *LogCenter) handlerEventLog(ctx types.WorkerContext) error {
@@ -196,52 +285,31 @@ func (lc *LogCenter) handlerEventLog(ctx types.WorkerContext) error {
 			eventLogs = append(eventLogs, one)
 		}
 	}
-	// 找出已保存的事件
-	eventIds := []string{}
-	for _, event := range eventLogs {
-		eventIds = append(eventIds, event.ID)
-	}
-	eventInDB := []core.EventLog{}
-	ctx.Server().Repo().Use(EventLogDB).Where("id in?", eventIds).Find(&eventInDB)
-	if len(eventInDB) > 0 {
-		for _, event := range eventInDB {
-			for i, e := range eventLogs {
-				if e.ID == event.ID {
-					// 从创建数组移除存在数据库的记录
-					eventLogs = append(eventLogs[:i], eventLogs[i+1:]...)
-				}
-			}
-		}
+	if err := lc.writeEvents(eventLogs); err != nil {
+		logx.Error(err.Error())
+		return ctx.SetStatus(http.StatusInternalServerError).Response([]byte("新增事件失败"))
 	}
-	// 新建事件
-	if len(eventLogs) > 0 {
-		// 分批插入
-		for i := 0; i < len(eventLogs); i += batchSize {
-			end := i + batchSize
-			if end > len(eventLogs) {
-				end = len(eventLogs)
-			}
-			db := ctx.Server().Repo().Use(EventLogDB).Create(eventLogs[i:end])
-			if db.Error != nil {
-				logx.Log().Error("新增事件失败: " + db.Error.Error())
-				return ctx.SetStatus(http.StatusInternalServerError).Response([]byte("新增事件失败"))
-			}
-		}
+	return ctx.SetStatus(http.StatusOK).Response([]byte(constant.SUCCESS))
+}
+
+func (lc *LogCenter) handlerAuditLog(ctx types.WorkerContext) error {
+	// 获取日志信息并转成审计记录
+	logs := []logx.LogEntry{}
+	err := jsonx.UnmarshalFromBytes(ctx.Body(), &logs)
+	if err != nil {
+		return ctx.SetStatus(http.StatusBadRequest).Response([]byte("添加审计记录失败"))
 	}
-	// 更新事件
-	if len(eventInDB) > 0 {
-		// 分批更新
-		for i := 0; i < len(eventInDB); i += batchSize {
-			end := i + batchSize
-			if end > len(eventInDB) {
-				end = len(eventInDB)
-			}
-			db := ctx.Server().Repo().Use(EventLogDB).Save(eventInDB[i:end])
-			if db.Error != nil {
-				logx.Log().Error("更新事件失败: " + db.Error.Error())
-				return ctx.SetStatus(http.StatusInternalServerError).Response([]byte("更新事件失败"))
-			}
+	audits := make([]core.AuditEntry, 0, len(logs))
+	for _, log := range logs {
+		audit := core.AuditEntry{}
+		if err := jsonx.UnmarshalFromStr(log.Msg, &audit); err != nil {
+			return ctx.SetStatus(http.StatusBadRequest).Response([]byte("审计记录格式错误"))
 		}
+		audits = append(audits, audit)
+	}
+	if err := lc.writeAudits(audits); err != nil {
+		logx.Error(err.Error())
+		return ctx.SetStatus(http.StatusInternalServerError).Response([]byte("新增审计记录失败"))
 	}
 	return ctx.SetStatus(http.StatusOK).Response([]byte(constant.SUCCESS))
 }
@@ -255,6 +323,9 @@ func (lc *LogCenter) handlerQueryLog(ctx types.WorkerContext) error {
 	if param.Size < 0 || param.Page <= 0 {
 		return ctx.SetStatus(http.StatusBadRequest).Response([]byte("参数异常，查询日志失败"))
 	}
+	if param.StartAt < 0 || param.EndAt < 0 || (param.EndAt > 0 && param.StartAt > param.EndAt) {
+		return ctx.SetStatus(http.StatusBadRequest).Response([]byte("时间范围异常，查询日志失败"))
+	}
 
 	if param.LogType == logx.LogTypeEvent {
 		return lc.queryEventLog(ctx, &param)
@@ -293,6 +364,7 @@ func (lc *LogCenter) queryEventLog(ctx types.WorkerContext, param *LogListParam)
 			db.Where(param.SearchField+" LIKE ?", "%"+param.SearchValue+"%")
 		}
 	}
+	param.applyTimeRange(db, "finish_at")
 	db.
 		Offset((param.Page - 1) * param.Size).
 		Limit(param.Size).
@@ -310,6 +382,7 @@ func (lc *LogCenter) queryEventLog(ctx types.WorkerContext, param *LogListParam)
 				db.Where(param.SearchField+" LIKE ?", "%"+param.SearchValue+"%")
 			}
 		}
+		param.applyTimeRange(db, "finish_at")
 		db.Count(&count)
 		jsonx.SetJsonList[*core.EventLog](resp, logList, count, param.Page)
 	} else {
@@ -325,6 +398,7 @@ func (lc *LogCenter) queryRuntimeLog(ctx types.WorkerContext, param *LogListPara
 	if param.SearchValue != "" {
 		db.Where(param.SearchField+" LIKE ?", "%"+param.SearchValue+"%")
 	}
+	param.applyTimeRange(db, "created_at")
 	db.
 		Offset((param.Page - 1) * param.Size).
 		Limit(param.Size).
@@ -336,6 +410,7 @@ func (lc *LogCenter) queryRuntimeLog(ctx types.WorkerContext, param *LogListPara
 		if param.SearchValue != "" {
 			db.Where(param.SearchField+" LIKE ?", "%"+param.SearchValue+"%")
 		}
+		param.applyTimeRange(db, "created_at")
 		db.Count(&count)
 		jsonx.SetJsonList[*logx.LogEntry](resp, logList, count, param.Page)
 	} else {
@@ -16,6 +16,7 @@ package logcenter
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -24,6 +25,7 @@ import (
 	"regexp"
 	"runtime/debug"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/garrickvan/event-matrix/constant"
@@ -39,6 +41,7 @@ type LogDaemonSubmitter struct {
 	logSliceInterval  time.Duration
 	logLocation       string
 	stopChan          chan struct{} // 添加 stopChan 通道
+	submitMu          sync.Mutex    // 避免守护进程与 Flush 同时提交同一日志切片
 }
 
 var (
@@ -113,7 +116,28 @@ func (ls *LogDaemonSubmitter) StopDaemon() {
 	close(ls.stopChan) // 关闭 stopChan 通道，通知守护进程停止
 }
 
+// Flush 立即提交已完成切片的日志，不等待下一次守护进程检查，用于停机前提交剩余日志，ctx 到期时停止提交并返回 ctx 的错误
+func (ls *LogDaemonSubmitter) Flush(ctx context.Context) error {
+	ls.submitPending(ctx, 0)
+	return ctx.Err()
+}
+
+// FlushLogSubmitter 刷新当前运行的日志提交守护进程，未创建时直接返回
+func FlushLogSubmitter(ctx context.Context) error {
+	if submitter == nil {
+		return nil
+	}
+	return submitter.Flush(ctx)
+}
+
 func (ls *LogDaemonSubmitter) submitLog() {
+	ls.submitPending(context.Background(), 500*time.Millisecond)
+}
+
+// submitPending 提交日志目录中已完成切片的日志，每提交一个文件后等待 pause，防止日志积压导致一次性提交过于频繁
+func (ls *LogDaemonSubmitter) submitPending(ctx context.Context, pause time.Duration) {
+	ls.submitMu.Lock()
+	defer ls.submitMu.Unlock()
 	if ls.logCenterEndpoint == "" {
 		if LogEndpointEvent == nil {
 			return
@@ -134,13 +158,13 @@ func (ls *LogDaemonSubmitter) submitLog() {
 	}
 	// 遍历文件
 	for _, file := range files {
-		if ls.logCenterEndpoint == "" {
+		if ls.logCenterEndpoint == "" || ctx.Err() != nil {
 			return
 		}
-		if !isValidLogFileName(file.Name(),
+		if !isValidLogFileName(file.Name(), logx.LogSuffix,
 			logx.LogTypeEvent,
 			logx.LogTypeRuntime,
-			logx.LogSuffix) {
+			logx.LogTypeAudit) {
 			// logx.Debug("日志文件:" + file.Name() + " 不符合日志文件名格式，忽略提交")
 			continue
 		}
@@ -154,15 +178,39 @@ func (ls *LogDaemonSubmitter) submitLog() {
 		if strings.HasPrefix(file.Name(), logx.LogTypeEvent) {
 			ls.parsingAndSubmitLog(file, logx.LogTypeEvent)
 		}
-		time.Sleep(500 * time.Millisecond) // 防止日志积压，导致一次性提交过于频繁
+		if strings.HasPrefix(file.Name(), logx.LogTypeAudit) {
+			ls.parsingAndSubmitLog(file, logx.LogTypeAudit)
+		}
+		time.Sleep(pause)
 	}
 }
 
+// PendingBytes 统计日志目录中等待提交的日志切片字节数
+func (ls *LogDaemonSubmitter) PendingBytes() int64 {
+	files, err := os.ReadDir(ls.logLocation)
+	if err != nil {
+		return 0
+	}
+	var total int64
+	for _, file := range files {
+		if !isValidLogFileName(file.Name(), logx.LogSuffix, logx.LogTypeEvent, logx.LogTypeRuntime, logx.LogTypeAudit) {
+			continue
+		}
+		if info, err := file.Info(); err == nil {
+			total += info.Size()
+		}
+	}
+	return total
+}
+
 // 判断文件名是否符合特定格式
-func isValidLogFileName(fileName, prefix1, prefix2, suffix string) bool {
-	// 动态生成正则表达式，用于匹配前缀1或前缀2开头，中间是任意字符（除换行符外，.除外，可根据实际情况调整），最后是后缀
-	// 使用 [^.] 表示匹配除. 之外的任意字符，.* 表示匹配零个或多个前面的表达式，也就是中间可以是任意内容（除换行符外，放宽限制后的效果）
-	pattern := fmt.Sprintf(`^(%s|%s)\..*?\.%s$`, regexp.QuoteMeta(prefix1), regexp.QuoteMeta(prefix2), regexp.QuoteMeta(suffix))
+func isValidLogFileName(fileName, suffix string, prefixes ...string) bool {
+	// 动态生成正则表达式，用于匹配任一前缀开头，中间是任意字符（除换行符外，放宽限制后的效果），最后是后缀
+	quoted := make([]string, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		quoted = append(quoted, regexp.QuoteMeta(prefix))
+	}
+	pattern := fmt.Sprintf(`^(%s)\..*?\.%s$`, strings.Join(quoted, "|"), regexp.QuoteMeta(suffix))
 	re := regexp.MustCompile(pattern)
 	return re.MatchString(fileName)
 }
@@ -207,8 +255,12 @@ func (ls *LogDaemonSubmitter) parsingAndSubmitLog(file os.DirEntry, logType stri
 		logs = append(logs, entry)
 	}
 	logTypeInt := GW_T_W_RUNTIME_LOG_SUBMIT
-	if logType == logx.LogTypeEvent {
+	switch logType {
+	case logx.LogTypeEvent:
 		logTypeInt = GW_T_W_EVENT_LOG_SUBMIT
+	case logx.LogTypeAudit:
+		// 审计日志使用独立的事件类型，不支持审计日志的旧版日志中心拒绝时保留切片，不影响事件日志提交
+		logTypeInt = GW_T_W_AUDIT_LOG_SUBMIT
 	}
 	// 分批提交日志记录
 	for i := 0; i < len(logs); i += batchSize {
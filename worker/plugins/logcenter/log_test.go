@@ -16,6 +16,8 @@ package logcenter
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -37,10 +39,37 @@ func TestFileName(t *testing.T) {
 	}
 
 	for _, fileName := range testFileNames {
-		if isValidLogFileName(fileName, prefix1, prefix2, suffix) {
+		if isValidLogFileName(fileName, suffix, prefix1, prefix2) {
 			fmt.Printf("文件名 %s 符合格式\n", fileName)
 		} else {
 			fmt.Printf("文件名 %s 不符合格式\n", fileName)
 		}
 	}
 }
+
+func TestPendingBytes(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"runtime.202407270237.slice_log": "12345",
+		"event.202407270236.slice_log":   "123",
+		"invalid.202407270237.slice_log": "ignored",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ls := &LogDaemonSubmitter{logLocation: dir}
+	if n := ls.PendingBytes(); n != 8 {
+		t.Fatalf("expected 8 pending bytes, got %d", n)
+	}
+	ls.logLocation = filepath.Join(dir, "missing")
+	if n := ls.PendingBytes(); n != 0 {
+		t.Fatalf("expected 0 pending bytes for missing dir, got %d", n)
+	}
+
+	lc := NewLogCenter(nil, "", "")
+	if h := lc.HealthCheck(); h.Healthy {
+		t.Fatal("log center without database should be unhealthy")
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package logcenter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/logx"
+)
+
+// DEFAULT_PURGE_INTERVAL 默认清理过期事件日志的间隔
+const DEFAULT_PURGE_INTERVAL = time.Hour
+
+// SetArchiver 设置事件日志归档器，清理过期事件日志前先归档，需在 Setup 之前调用
+func (lc *LogCenter) SetArchiver(a EventLogArchiver) {
+	lc.archiver = a
+}
+
+// SetEventLogRetention 设置事件日志在数据库中的保留时长和清理间隔，需在 Setup 之前调用
+// retention 小于等于0时不清理，interval 小于等于0时使用默认间隔
+func (lc *LogCenter) SetEventLogRetention(retention, interval time.Duration) {
+	if interval <= 0 {
+		interval = DEFAULT_PURGE_INTERVAL
+	}
+	lc.eventLogRetention = retention
+	lc.purgeInterval = interval
+}
+
+// startPurge 启动定时清理过期事件日志的任务
+func (lc *LogCenter) startPurge() {
+	if lc.eventLogRetention <= 0 || lc.stopPurge != nil {
+		return
+	}
+	lc.stopPurge = make(chan struct{})
+	go func(stop chan struct{}) {
+		ticker := time.NewTicker(lc.purgeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				n, err := lc.purgeEventLogs(time.Now())
+				if err != nil {
+					logx.Error("清理过期事件日志失败: " + err.Error())
+				}
+				if n > 0 {
+					logx.Info(fmt.Sprintf("已清理过期事件日志 %d 条", n))
+				}
+			}
+		}
+	}(lc.stopPurge)
+}
+
+// purgeEventLogs 分批删除完成时间早于保留期限的事件日志，设置了归档器时先归档，归档失败则停止本次清理
+// 返回已删除的日志条数
+func (lc *LogCenter) purgeEventLogs(now time.Time) (int, error) {
+	cutoff := now.Add(-lc.eventLogRetention).UnixMilli()
+	purged := 0
+	for {
+		logs := []core.EventLog{}
+		err := lc.svr.Repo().Use(EventLogDB).
+			Where("finish_at < ?", cutoff).
+			Order("finish_at").
+			Limit(batchSize).
+			Find(&logs).Error
+		if err != nil {
+			return purged, err
+		}
+		if len(logs) == 0 {
+			return purged, nil
+		}
+		if lc.archiver != nil {
+			if err := lc.archiver.Archive(logs); err != nil {
+				return purged, fmt.Errorf("归档事件日志失败: %w", err)
+			}
+		}
+		ids := make([]string, 0, len(logs))
+		for _, log := range logs {
+			ids = append(ids, log.ID)
+		}
+		if err := lc.svr.Repo().Use(EventLogDB).Where("id in ?", ids).Delete(&core.EventLog{}).Error; err != nil {
+			return purged, err
+		}
+		purged += len(logs)
+		if len(logs) < batchSize {
+			return purged, nil
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logcenter
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+// queryLog 以指定参数调用日志查询处理器，返回状态码和响应
+func queryLog(t *testing.T, lc *LogCenter, ws types.WorkerServer, param LogListParam) (int, *jsonx.JsonResponse) {
+	t.Helper()
+	params, err := jsonx.MarshalToStr(param)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := types.NewMockRequestContext(ws, &core.Event{Params: params})
+	if err := lc.handlerQueryLog(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ctx.StatusCode() != http.StatusOK {
+		return ctx.StatusCode(), nil
+	}
+	resp, err := jsonx.NewJsonResponseFromStr(string(ctx.ResponseBody()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctx.StatusCode(), resp
+}
+
+func TestQueryLogTimeRange(t *testing.T) {
+	ws := types.NewMockWorkerServer(nil)
+	defer ws.Stop()
+	if err := ws.Repo().Use(EventLogDB).AutoMigrate(&core.EventLog{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.Repo().Use(RuntimeLogDB).AutoMigrate(&logx.LogEntry{}); err != nil {
+		t.Fatal(err)
+	}
+	events := []core.EventLog{}
+	runtimes := []logx.LogEntry{}
+	for i := int64(1); i <= 5; i++ {
+		events = append(events, core.EventLog{ID: fmt.Sprintf("e%d", i), FinishAt: i * 1000})
+		runtimes = append(runtimes, logx.LogEntry{ID: fmt.Sprintf("r%d", i), CreatedAt: i * 1000})
+	}
+	if err := ws.Repo().Use(EventLogDB).Create(&events).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.Repo().Use(RuntimeLogDB).Create(&runtimes).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	lc := NewLogCenter(ws, "", "")
+	cases := []struct {
+		name           string
+		startAt, endAt int64
+		total          int64
+	}{
+		{"不限制", 0, 0, 5},
+		{"闭区间", 2000, 4000, 3},
+		{"仅起始时间", 4000, 0, 2},
+		{"仅结束时间", 0, 1000, 1},
+		{"无匹配", 6000, 7000, 0},
+	}
+	for _, logType := range []string{logx.LogTypeEvent, logx.LogTypeRuntime} {
+		for _, c := range cases {
+			param := LogListParam{LogType: logType, Page: 1, Size: 2, StartAt: c.startAt, EndAt: c.endAt}
+			status, resp := queryLog(t, lc, ws, param)
+			if status != http.StatusOK {
+				t.Fatalf("%s/%s: 状态码 %d", logType, c.name, status)
+			}
+			if resp.Total != c.total || int64(len(resp.List)) != min(c.total, 2) {
+				t.Errorf("%s/%s: 期望共 %d 条，实际 total=%d list=%d", logType, c.name, c.total, resp.Total, len(resp.List))
+			}
+		}
+	}
+
+	// 起始时间晚于结束时间
+	if status, _ := queryLog(t, lc, ws, LogListParam{LogType: logx.LogTypeEvent, Page: 1, Size: 2, StartAt: 3000, EndAt: 1000}); status != http.StatusBadRequest {
+		t.Errorf("非法时间范围应返回400，实际 %d", status)
+	}
+}
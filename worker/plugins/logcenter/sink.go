@@ -0,0 +1,251 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logcenter
+
+import (
+	"errors"
+	"time"
+
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+const (
+	DEFAULT_SINK_MAX_RETRIES = 3                      // 日志输出失败时的默认重试次数
+	DEFAULT_SINK_RETRY_DELAY = 200 * time.Millisecond // 日志输出第一次重试前的等待时间
+	MAX_SINK_RETRY_DELAY     = 10 * time.Second       // 日志输出重试的最长等待时间
+)
+
+// LogSink 日志输出接口，日志中心收到的运行日志、事件日志和审计记录会依次写入所有输出
+// 返回错误时日志中心向提交方返回失败，提交方稍后重新提交，输出需按日志ID去重或覆盖写入
+type LogSink interface {
+	Write(logs []logx.LogEntry) error
+	WriteEvents(events []core.EventLog) error
+	WriteAudits(audits []core.AuditEntry) error
+}
+
+// AddSink 添加日志输出，数据库输出默认存在，无需添加
+func (lc *LogCenter) AddSink(sink LogSink) {
+	if sink == nil {
+		return
+	}
+	lc.sinks = append(lc.sinks, sink)
+}
+
+// writeLogs 将运行日志写入所有输出，单个输出失败不影响其他输出
+func (lc *LogCenter) writeLogs(logs []logx.LogEntry) error {
+	var errs []error
+	for _, sink := range lc.sinks {
+		if err := sink.Write(logs); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writeEvents 将事件日志写入所有输出，单个输出失败不影响其他输出
+func (lc *LogCenter) writeEvents(events []core.EventLog) error {
+	var errs []error
+	for _, sink := range lc.sinks {
+		if err := sink.WriteEvents(events); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writeAudits 将审计记录写入所有输出，单个输出失败不影响其他输出
+func (lc *LogCenter) writeAudits(audits []core.AuditEntry) error {
+	if len(audits) == 0 {
+		return nil
+	}
+	var errs []error
+	for _, sink := range lc.sinks {
+		if err := sink.WriteAudits(audits); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type dbLogSink struct {
+	svr types.WorkerServer
+}
+
+func (s *dbLogSink) Write(logs []logx.LogEntry) error {
+	// 找出已保存的日志并舍弃
+	logIds := []string{}
+	for _, log := range logs {
+		logIds = append(logIds, log.ID)
+	}
+	logsInDB := []logx.LogEntry{}
+	s.svr.Repo().Use(RuntimeLogDB).Where("id in?", logIds).Find(&logsInDB)
+	if len(logsInDB) > 0 {
+		// 移除存在的，避免重复插入；不修改传入的切片，其他输出仍需使用
+		saved := make(map[string]struct{}, len(logsInDB))
+		for _, log := range logsInDB {
+			saved[log.ID] = struct{}{}
+		}
+		newLogs := make([]logx.LogEntry, 0, len(logs))
+		for _, l := range logs {
+			if _, has := saved[l.ID]; !has {
+				newLogs = append(newLogs, l)
+			}
+		}
+		logs = newLogs
+	}
+	// 新建日志
+	if len(logs) > 0 {
+		// 分批插入
+		for i := 0; i < len(logs); i += batchSize {
+			end := i + batchSize
+			if end > len(logs) {
+				end = len(logs)
+			}
+			db := s.svr.Repo().Use(RuntimeLogDB).Create(logs[i:end])
+			if db.Error != nil {
+				return errors.New("新增日志失败: " + db.Error.Error())
+			}
+		}
+	}
+	return nil
+}
+
+func (s *dbLogSink) WriteEvents(eventLogs []core.EventLog) error {
+	// 找出已保存的事件
+	eventIds := []string{}
+	for _, event := range eventLogs {
+		eventIds = append(eventIds, event.ID)
+	}
+	eventInDB := []core.EventLog{}
+	s.svr.Repo().Use(EventLogDB).Where("id in?", eventIds).Find(&eventInDB)
+	if len(eventInDB) > 0 {
+		// 从创建数组移除存在数据库的记录；不修改传入的切片，其他输出仍需使用
+		saved := make(map[string]struct{}, len(eventInDB))
+		for _, event := range eventInDB {
+			saved[event.ID] = struct{}{}
+		}
+		newEvents := make([]core.EventLog, 0, len(eventLogs))
+		for _, e := range eventLogs {
+			if _, has := saved[e.ID]; !has {
+				newEvents = append(newEvents, e)
+			}
+		}
+		eventLogs = newEvents
+	}
+	// 新建事件
+	if len(eventLogs) > 0 {
+		// 分批插入
+		for i := 0; i < len(eventLogs); i += batchSize {
+			end := i + batchSize
+			if end > len(eventLogs) {
+				end = len(eventLogs)
+			}
+			db := s.svr.Repo().Use(EventLogDB).Create(eventLogs[i:end])
+			if db.Error != nil {
+				return errors.New("新增事件失败: " + db.Error.Error())
+			}
+		}
+	}
+	// 更新事件
+	if len(eventInDB) > 0 {
+		// 分批更新
+		for i := 0; i < len(eventInDB); i += batchSize {
+			end := i + batchSize
+			if end > len(eventInDB) {
+				end = len(eventInDB)
+			}
+			db := s.svr.Repo().Use(EventLogDB).Save(eventInDB[i:end])
+			if db.Error != nil {
+				return errors.New("更新事件失败: " + db.Error.Error())
+			}
+		}
+	}
+	return nil
+}
+
+func (s *dbLogSink) WriteAudits(audits []core.AuditEntry) error {
+	// 审计记录只新增不更新，已保存的直接舍弃
+	ids := make([]string, 0, len(audits))
+	for _, audit := range audits {
+		ids = append(ids, audit.ID)
+	}
+	savedIds := []string{}
+	s.svr.Repo().Use(EventLogDB).Model(&core.AuditEntry{}).Where("id in ?", ids).Pluck("id", &savedIds)
+	saved := make(map[string]struct{}, len(savedIds))
+	for _, id := range savedIds {
+		saved[id] = struct{}{}
+	}
+	newAudits := make([]core.AuditEntry, 0, len(audits))
+	for _, audit := range audits {
+		if _, has := saved[audit.ID]; !has {
+			newAudits = append(newAudits, audit)
+		}
+	}
+	if len(newAudits) == 0 {
+		return nil
+	}
+	if err := s.svr.Repo().Use(EventLogDB).CreateInBatches(newAudits, batchSize).Error; err != nil {
+		return errors.New("新增审计记录失败: " + err.Error())
+	}
+	return nil
+}
+
+// RetryLogSink 为日志输出添加失败重试，第 n 次重试前等待 baseDelay*2^(n-1)，最长等待 MAX_SINK_RETRY_DELAY
+type RetryLogSink struct {
+	sink       LogSink
+	maxRetries int
+	baseDelay  time.Duration
+	sleep      func(time.Duration) // 等待函数，测试时可替换
+}
+
+// NewRetryLogSink 创建带重试的日志输出，maxRetries 小于0或 baseDelay 小于等于0时使用默认值
+func NewRetryLogSink(sink LogSink, maxRetries int, baseDelay time.Duration) *RetryLogSink {
+	if maxRetries < 0 {
+		maxRetries = DEFAULT_SINK_MAX_RETRIES
+	}
+	if baseDelay <= 0 {
+		baseDelay = DEFAULT_SINK_RETRY_DELAY
+	}
+	return &RetryLogSink{sink: sink, maxRetries: maxRetries, baseDelay: baseDelay, sleep: time.Sleep}
+}
+
+func (s *RetryLogSink) Write(logs []logx.LogEntry) error {
+	return s.retry(func() error { return s.sink.Write(logs) })
+}
+
+func (s *RetryLogSink) WriteEvents(events []core.EventLog) error {
+	return s.retry(func() error { return s.sink.WriteEvents(events) })
+}
+
+func (s *RetryLogSink) WriteAudits(audits []core.AuditEntry) error {
+	return s.retry(func() error { return s.sink.WriteAudits(audits) })
+}
+
+// retry 执行写入，失败时按指数退避重试，全部失败返回最后一次的错误
+func (s *RetryLogSink) retry(write func() error) error {
+	delay := s.baseDelay
+	err := write()
+	for i := 0; err != nil && i < s.maxRetries; i++ {
+		s.sleep(delay)
+		delay *= 2
+		if delay > MAX_SINK_RETRY_DELAY {
+			delay = MAX_SINK_RETRY_DELAY
+		}
+		err = write()
+	}
+	return err
+}
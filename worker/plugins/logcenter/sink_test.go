@@ -0,0 +1,268 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logcenter
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+// memorySink 记录写入的日志，fail 大于0时前 fail 次写入失败
+type memorySink struct {
+	logs   []logx.LogEntry
+	events []core.EventLog
+	audits []core.AuditEntry
+	calls  int
+	fail   int
+}
+
+func (s *memorySink) Write(logs []logx.LogEntry) error {
+	s.calls++
+	if s.calls <= s.fail {
+		return errors.New("sink unavailable")
+	}
+	s.logs = append(s.logs, logs...)
+	return nil
+}
+
+func (s *memorySink) WriteEvents(events []core.EventLog) error {
+	s.calls++
+	if s.calls <= s.fail {
+		return errors.New("sink unavailable")
+	}
+	s.events = append(s.events, events...)
+	return nil
+}
+
+func (s *memorySink) WriteAudits(audits []core.AuditEntry) error {
+	s.calls++
+	if s.calls <= s.fail {
+		return errors.New("sink unavailable")
+	}
+	s.audits = append(s.audits, audits...)
+	return nil
+}
+
+func TestLogCenterSinks(t *testing.T) {
+	ws := types.NewMockWorkerServer(nil)
+	defer ws.Stop()
+	if err := ws.Repo().Use(RuntimeLogDB).AutoMigrate(&logx.LogEntry{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ws.Repo().Use(RuntimeLogDB).Create(&logx.LogEntry{ID: "r1"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	lc := NewLogCenter(ws, "", "")
+	sink := &memorySink{}
+	lc.AddSink(sink)
+	lc.AddSink(nil)
+
+	body, _ := jsonx.MarshalToStr([]logx.LogEntry{{ID: "r1"}, {ID: "r2"}, {ID: "r3"}})
+	ctx := types.NewMockRequestContext(ws, &core.Event{Params: body})
+	if err := lc.handlerRuntimeLog(ctx); err != nil || ctx.StatusCode() != http.StatusOK {
+		t.Fatalf("写入日志失败: %v %d", err, ctx.StatusCode())
+	}
+	var count int64
+	ws.Repo().Use(RuntimeLogDB).Model(&logx.LogEntry{}).Count(&count)
+	if count != 3 {
+		t.Errorf("数据库应去重后写入, 实际 %d 条", count)
+	}
+	// 数据库输出去重不影响其他输出收到的日志
+	if len(sink.logs) != 3 || sink.logs[0].ID != "r1" || sink.logs[2].ID != "r3" {
+		t.Errorf("外部输出收到的日志错误: %v", sink.logs)
+	}
+
+	// 任一输出失败时返回500，提交方稍后重试
+	sink.fail = sink.calls + 1
+	ctx = types.NewMockRequestContext(ws, &core.Event{Params: body})
+	lc.handlerRuntimeLog(ctx)
+	if ctx.StatusCode() != http.StatusInternalServerError {
+		t.Errorf("输出失败时应返回500, 实际 %d", ctx.StatusCode())
+	}
+}
+
+func TestRetryLogSink(t *testing.T) {
+	sink := &memorySink{fail: 3}
+	retry := NewRetryLogSink(sink, 3, 100*time.Millisecond)
+	delays := []time.Duration{}
+	retry.sleep = func(d time.Duration) { delays = append(delays, d) }
+
+	if err := retry.Write([]logx.LogEntry{{ID: "r1"}}); err != nil {
+		t.Fatal(err)
+	}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond}
+	if len(delays) != len(want) {
+		t.Fatalf("重试次数错误: %v", delays)
+	}
+	for i := range want {
+		if delays[i] != want[i] {
+			t.Errorf("第%d次重试等待 %v, 期望 %v", i+1, delays[i], want[i])
+		}
+	}
+
+	// 重试次数用尽后返回最后一次的错误
+	sink = &memorySink{fail: 10}
+	retry = NewRetryLogSink(sink, 2, time.Second)
+	retry.sleep = func(time.Duration) {}
+	if err := retry.WriteEvents([]core.EventLog{{ID: "e1"}}); err == nil || sink.calls != 3 {
+		t.Errorf("重试用尽应返回错误, 调用 %d 次, err=%v", sink.calls, err)
+	}
+}
+
+func TestElasticsearchSink(t *testing.T) {
+	var lines []string
+	var auth, contentType, path string
+	bulkErrors := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, auth, contentType = r.URL.Path, r.Header.Get("Authorization"), r.Header.Get("Content-Type")
+		lines = lines[:0]
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		io.Copy(io.Discard, r.Body)
+		if bulkErrors {
+			w.Write([]byte(`{"took":1,"errors":true,"items":[]}`))
+			return
+		}
+		w.Write([]byte(`{"took":1,"errors":false,"items":[]}`))
+	}))
+	defer server.Close()
+
+	ws := types.NewMockWorkerServer(nil)
+	defer ws.Stop()
+	ws.SetSharedConfigure(&core.SharedConfigure{
+		Key:   "es",
+		Type:  core.LOG_ELASTICSEARCH,
+		Value: `{"endpoint":"` + server.URL + `/","api_key":"k3y"}`,
+	})
+	sink, err := NewElasticsearchSinkFromSharedConfig(ws, "es")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sink.WriteEvents([]core.EventLog{{ID: "e1"}, {ID: "e2"}}); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/_bulk" || auth != "ApiKey k3y" || contentType != "application/x-ndjson" {
+		t.Errorf("请求错误: %s %s %s", path, auth, contentType)
+	}
+	if len(lines) != 4 || !strings.Contains(lines[0], `"_index":"`+DEFAULT_ES_EVENT_INDEX+`"`) ||
+		!strings.Contains(lines[0], `"_id":"e1"`) || !strings.Contains(lines[3], `"id":"e2"`) {
+		t.Errorf("NDJSON 请求体错误: %v", lines)
+	}
+
+	bulkErrors = true
+	if err := sink.Write([]logx.LogEntry{{ID: "r1"}}); err == nil {
+		t.Error("存在失败文档时应返回错误")
+	}
+	if _, err := NewElasticsearchSinkFromSharedConfig(ws, "missing"); err == nil {
+		t.Error("配置不存在时应返回错误")
+	}
+}
+
+func TestLogCenterMinLevel(t *testing.T) {
+	ws := types.NewMockWorkerServer(nil)
+	defer ws.Stop()
+	lc := NewLogCenter(ws, "", "")
+	sink := &memorySink{}
+	lc.sinks = []LogSink{sink}
+	submit := func(levels ...string) {
+		logs := []logx.LogEntry{}
+		for i, level := range levels {
+			logs = append(logs, logx.LogEntry{ID: level + string(rune('0'+i)), Level: level})
+		}
+		body, _ := jsonx.MarshalToStr(logs)
+		ctx := types.NewMockRequestContext(ws, &core.Event{Params: body})
+		if err := lc.handlerRuntimeLog(ctx); err != nil || ctx.StatusCode() != http.StatusOK {
+			t.Fatalf("写入日志失败: %v %d", err, ctx.StatusCode())
+		}
+	}
+
+	// 默认不保存 debug 日志
+	submit("debug", "info", "error")
+	if len(sink.logs) != 2 || sink.logs[0].Level != "info" {
+		t.Fatalf("默认应过滤 debug 日志: %v", sink.logs)
+	}
+	// 全部被过滤时不调用输出
+	calls := sink.calls
+	submit("debug")
+	if sink.calls != calls {
+		t.Fatal("没有需要保存的日志时不应调用输出")
+	}
+
+	err := lc.OnSharedConfigureChange(&core.SharedConfigure{Key: LogLevelCfgKey, Value: `{"minLevel":"WARN"}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink.logs = nil
+	submit("info", "warn", "error")
+	if len(sink.logs) != 2 || sink.logs[0].Level != "warn" {
+		t.Fatalf("调整级别后应只保存 warn 及以上日志: %v", sink.logs)
+	}
+	if err := lc.OnSharedConfigureChange(&core.SharedConfigure{Key: LogLevelCfgKey, Value: "warn"}); err == nil {
+		t.Fatal("格式错误的配置应返回错误")
+	}
+	if err := lc.OnSharedConfigureChange(&core.SharedConfigure{Key: "other", Value: "warn"}); err != nil {
+		t.Fatalf("其他配置应忽略: %v", err)
+	}
+}
+
+func TestLogCenterAuditEntries(t *testing.T) {
+	ws := types.NewMockWorkerServer(nil)
+	defer ws.Stop()
+	if err := ws.Repo().Use(EventLogDB).AutoMigrate(&core.EventLog{}, &core.AuditEntry{}); err != nil {
+		t.Fatal(err)
+	}
+	lc := NewLogCenter(ws, "", "")
+	sink := &memorySink{}
+	lc.AddSink(sink)
+
+	audit, _ := jsonx.MarshalToStr(core.AuditEntry{
+		ID: "a1", EventId: "e1", RecordId: "u1",
+		Before: map[string]interface{}{"name": "alice"},
+		After:  map[string]interface{}{"name": "bob"},
+	})
+	body, _ := jsonx.MarshalToStr([]logx.LogEntry{{ID: "l1", Msg: audit}})
+	for i := 0; i < 2; i++ {
+		ctx := types.NewMockRequestContext(ws, &core.Event{Params: body})
+		if err := lc.Handle(ctx, GW_T_W_AUDIT_LOG_SUBMIT); err != nil || ctx.StatusCode() != http.StatusOK {
+			t.Fatalf("写入审计记录失败: %v %d", err, ctx.StatusCode())
+		}
+	}
+	// 审计记录同样写入其他输出
+	if len(sink.audits) != 2 || sink.audits[0].ID != "a1" || len(sink.events) != 0 {
+		t.Fatalf("输出收到的审计记录错误: %v %v", sink.audits, sink.events)
+	}
+	saved := []core.AuditEntry{}
+	ws.Repo().Use(EventLogDB).Find(&saved)
+	if len(saved) != 1 {
+		t.Fatalf("审计记录应去重后写入, 实际 %d 条", len(saved))
+	}
+	if saved[0].Before["name"] != "alice" || saved[0].After["name"] != "bob" {
+		t.Fatalf("审计记录内容错误: %+v", saved[0])
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notificationplugin
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/spf13/cast"
+)
+
+const (
+	fcmDefaultEndpoint = "https://fcm.googleapis.com"
+	fcmDefaultTokenURI = "https://oauth2.googleapis.com/token"
+	fcmScope           = "https://www.googleapis.com/auth/firebase.messaging"
+)
+
+// FCMConfig FCM推送配置，对应类型为 PUSH_FCM 的共享配置
+// 字段与Firebase服务账号密钥文件保持一致，AccessToken 不为空时直接使用该令牌
+type FCMConfig struct {
+	ProjectID   string `json:"project_id"`   // Firebase项目ID
+	ClientEmail string `json:"client_email"` // 服务账号邮箱
+	PrivateKey  string `json:"private_key"`  // 服务账号PEM格式私钥
+	TokenURI    string `json:"token_uri"`    // OAuth2令牌地址
+	AccessToken string `json:"access_token"` // 静态访问令牌，可选
+	Endpoint    string `json:"endpoint"`     // FCM接口地址，可选
+}
+
+// fcmSender 通过FCM HTTP v1接口发送推送
+type fcmSender struct {
+	cfg    *FCMConfig
+	client *http.Client
+
+	mu        sync.Mutex
+	token     string    // 缓存的访问令牌
+	expiresAt time.Time // 访问令牌过期时间
+}
+
+func newFCMSender(cfg *FCMConfig, client *http.Client) (*fcmSender, error) {
+	if cfg.ProjectID == "" {
+		return nil, errors.New("FCM配置缺少project_id")
+	}
+	if cfg.AccessToken == "" && (cfg.ClientEmail == "" || cfg.PrivateKey == "") {
+		return nil, errors.New("FCM配置缺少服务账号信息")
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = fcmDefaultEndpoint
+	}
+	if cfg.TokenURI == "" {
+		cfg.TokenURI = fcmDefaultTokenURI
+	}
+	return &fcmSender{cfg: cfg, client: client}, nil
+}
+
+// send 向单个设备发送推送
+func (s *fcmSender) send(token, title, body string, data map[string]interface{}) error {
+	accessToken, err := s.accessToken()
+	if err != nil {
+		return err
+	}
+	strData := make(map[string]string, len(data))
+	for k, v := range data {
+		strData[k] = cast.ToString(v)
+	}
+	payload, err := jsonx.MarshalToBytes(map[string]interface{}{
+		"message": map[string]interface{}{
+			"token":        token,
+			"notification": map[string]string{"title": title, "body": body},
+			"data":         strData,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("%s/v1/projects/%s/messages:send", strings.TrimRight(s.cfg.Endpoint, "/"), s.cfg.ProjectID)
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode == http.StatusNotFound || strings.Contains(string(respBody), "UNREGISTERED") {
+		return errInvalidDevice
+	}
+	return fmt.Errorf("FCM推送失败，状态码：%d，响应：%s", resp.StatusCode, respBody)
+}
+
+// accessToken 获取访问令牌，令牌过期前一分钟重新申请
+func (s *fcmSender) accessToken() (string, error) {
+	if s.cfg.AccessToken != "" {
+		return s.cfg.AccessToken, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && time.Now().Before(s.expiresAt.Add(-time.Minute)) {
+		return s.token, nil
+	}
+	assertion, err := s.signAssertion()
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	resp, err := s.client.PostForm(s.cfg.TokenURI, form)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("获取FCM访问令牌失败，状态码：%d，响应：%s", resp.StatusCode, respBody)
+	}
+	result := struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}{}
+	if err := jsonx.UnmarshalFromBytes(respBody, &result); err != nil {
+		return "", err
+	}
+	s.token = result.AccessToken
+	s.expiresAt = time.Now().Add(time.Duration(result.ExpiresIn) * time.Second)
+	return s.token, nil
+}
+
+// signAssertion 使用服务账号私钥签发RS256格式的JWT断言
+func (s *fcmSender) signAssertion() (string, error) {
+	key, err := parseRSAPrivateKey(s.cfg.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now().Unix()
+	header, _ := jsonx.MarshalToBytes(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := jsonx.MarshalToBytes(map[string]interface{}{
+		"iss":   s.cfg.ClientEmail,
+		"scope": fcmScope,
+		"aud":   s.cfg.TokenURI,
+		"iat":   now,
+		"exp":   now + 3600,
+	})
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// parseRSAPrivateKey 解析PEM格式的RSA私钥，支持PKCS#1和PKCS#8
+func parseRSAPrivateKey(pemKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, errors.New("FCM私钥格式错误")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("FCM私钥解析失败：%w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("FCM私钥不是RSA私钥")
+	}
+	return key, nil
+}
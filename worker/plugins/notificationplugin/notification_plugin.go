@@ -0,0 +1,300 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notificationplugin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/intranet/dispatcher"
+	"github.com/garrickvan/event-matrix/worker/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationPlugin 推送通知插件，支持FCM和WebPush
+// 设备令牌存储在 notifications_device 表中，推送凭据从共享配置中加载
+type NotificationPlugin struct {
+	worker      *types.Worker
+	svr         types.WorkerServer
+	pushCfgKeys []string
+	db          *gorm.DB
+	client      *http.Client
+
+	senders map[string]pushSender // 按平台区分的推送发送器
+}
+
+// pushSender 推送发送器
+type pushSender interface {
+	send(token, title, body string, data map[string]interface{}) error
+}
+
+// NotificationDevice 用户设备，Token 对FCM为注册令牌，对WebPush为JSON格式的订阅信息
+type NotificationDevice struct {
+	ID        string `json:"id" gorm:"primaryKey"`
+	UserID    string `json:"userId" gorm:"index"`
+	Platform  string `json:"platform"`
+	Token     string `json:"token" gorm:"size:255;uniqueIndex"`
+	CreatedAt int64  `json:"createdAt"`
+	UpdatedAt int64  `json:"updatedAt"`
+}
+
+// TableName 指定设备表名
+func (NotificationDevice) TableName() string {
+	return "notifications_device"
+}
+
+// NotificationSendParams 发送推送的请求参数
+type NotificationSendParams struct {
+	UserID string                 `json:"userId"`
+	Title  string                 `json:"title"`
+	Body   string                 `json:"body"`
+	Data   map[string]interface{} `json:"data"`
+}
+
+const (
+	NotificationDB                                           = "notifications"
+	NotificationWorkerContext                                = "gateway"
+	NotificationWorkerEntity                                 = "notification"
+	PlatformFCM                                              = "fcm"
+	PlatformWebPush                                          = "webpush"
+	NOTIFICATION_SUCCESS                                     = string(constant.SUCCESS)
+	notificationHTTPTimeout                                  = 10 * time.Second
+	GW_T_W_NOTIFICATION_SEND       types.INTRANET_EVENT_TYPE = 34000
+	GW_T_W_NOTIFICATION_REG_DEVICE types.INTRANET_EVENT_TYPE = 34001
+)
+
+// errInvalidDevice 设备令牌已失效，推送时会将其删除
+var errInvalidDevice = errors.New("设备令牌已失效")
+
+var (
+	notificationWorker = types.Worker{
+		Project:      core.INTERNAL_PROJECT,
+		VersionLabel: constant.INITIAL_VERSION,
+		Context:      NotificationWorkerContext,
+		Entity:       NotificationWorkerEntity,
+		SyncSchema:   false,
+	}
+)
+
+// NewNotificationPlugin 创建推送通知插件
+// cfgKey 为设备数据库的共享配置键，pushCfgKeys 为类型是 PUSH_FCM 或 PUSH_WEBPUSH 的共享配置键
+func NewNotificationPlugin(svr types.WorkerServer, cfgKey string, pushCfgKeys ...string) *NotificationPlugin {
+	notificationWorker.CfgKey = cfgKey
+	return &NotificationPlugin{
+		worker:      &notificationWorker,
+		svr:         svr,
+		pushCfgKeys: pushCfgKeys,
+		client:      &http.Client{Timeout: notificationHTTPTimeout},
+		senders:     make(map[string]pushSender),
+	}
+}
+
+// Setup 初始化设备表和推送凭据，并注册推送通知插件
+func (np *NotificationPlugin) Setup() error {
+	if err := np.svr.Repo().AddDBFromSharedConfig(np.worker.CfgKey); err != nil {
+		return err
+	}
+	if !np.svr.Repo().HasDB(NotificationDB) {
+		return fmt.Errorf("缺少数据库配置: [%s], 请检查配置，无法启动推送通知服务", NotificationDB)
+	}
+	np.db = np.svr.Repo().Use(NotificationDB)
+	if err := np.db.AutoMigrate(&NotificationDevice{}); err != nil {
+		return fmt.Errorf("数据库表迁移失败: %w", err)
+	}
+	for _, key := range np.pushCfgKeys {
+		if err := np.loadPushConfig(key); err != nil {
+			return err
+		}
+	}
+	if err := np.svr.RegisterWorker(np.worker); err != nil {
+		return err
+	}
+	np.svr.RegisterPlugin(np)
+	return nil
+}
+
+// loadPushConfig 根据共享配置类型加载推送凭据
+func (np *NotificationPlugin) loadPushConfig(key string) error {
+	sc := np.svr.SharedConfigure(key)
+	if sc == nil {
+		return fmt.Errorf("推送配置不存在：%s", key)
+	}
+	switch sc.Type {
+	case core.PUSH_FCM:
+		cfg := &FCMConfig{}
+		if err := jsonx.UnmarshalFromStr(sc.Value, cfg); err != nil {
+			return fmt.Errorf("FCM配置解析失败：%w", err)
+		}
+		sender, err := newFCMSender(cfg, np.client)
+		if err != nil {
+			return err
+		}
+		np.senders[PlatformFCM] = sender
+	case core.PUSH_WEBPUSH:
+		cfg := &WebPushConfig{}
+		if err := jsonx.UnmarshalFromStr(sc.Value, cfg); err != nil {
+			return fmt.Errorf("WebPush配置解析失败：%w", err)
+		}
+		sender, err := newWebPushSender(cfg, np.client)
+		if err != nil {
+			return err
+		}
+		np.senders[PlatformWebPush] = sender
+	default:
+		return fmt.Errorf("不支持的推送配置类型：%s", sc.Type)
+	}
+	dispatcher.ReportConfigUsedBy(key, np.worker.ID)
+	return nil
+}
+
+// ReceiveCodes 返回推送通知插件能够处理的事件类型列表
+func (np *NotificationPlugin) ReceiveCodes() []types.INTRANET_EVENT_TYPE {
+	return []types.INTRANET_EVENT_TYPE{GW_T_W_NOTIFICATION_SEND, GW_T_W_NOTIFICATION_REG_DEVICE}
+}
+
+// Dependencies 推送通知插件不依赖其他插件
+func (np *NotificationPlugin) Dependencies() []types.INTRANET_EVENT_TYPE {
+	return nil
+}
+
+// Handle 根据接收到的事件类型调用相应的处理方法
+func (np *NotificationPlugin) Handle(ctx types.WorkerContext, typz types.INTRANET_EVENT_TYPE) error {
+	switch typz {
+	case GW_T_W_NOTIFICATION_SEND:
+		return np.sendHandler(ctx)
+	case GW_T_W_NOTIFICATION_REG_DEVICE:
+		return np.registerDeviceHandler(ctx)
+	default:
+		return ctx.SetStatus(http.StatusForbidden).Response([]byte(constant.UNSUPPORTED_EVENT))
+	}
+}
+
+// HealthCheck 检查推送通知插件的健康状态，汇报已启用的推送平台
+func (np *NotificationPlugin) HealthCheck() types.PluginHealth {
+	platforms := make([]string, 0, len(np.senders))
+	for p := range np.senders {
+		platforms = append(platforms, p)
+	}
+	details := map[string]interface{}{"platforms": platforms}
+	if np.db == nil {
+		return types.PluginHealth{Healthy: false, Message: "设备数据库不可用", Details: details}
+	}
+	return types.PluginHealth{Healthy: true, Message: "ok", Details: details}
+}
+
+// Shutdown 关闭推送通知插件，无后台任务，直接返回
+func (np *NotificationPlugin) Shutdown() error {
+	return nil
+}
+
+// RegisterDevice 注册或更新用户设备，相同令牌重复注册时更新所属用户
+func (np *NotificationPlugin) RegisterDevice(userID, platform, token string) error {
+	if np.db == nil {
+		return errors.New("推送通知插件未初始化")
+	}
+	if userID == "" || token == "" {
+		return errors.New("用户ID和设备令牌不能为空")
+	}
+	if platform != PlatformFCM && platform != PlatformWebPush {
+		return fmt.Errorf("不支持的推送平台：%s", platform)
+	}
+	now := utils.GetNowMilli()
+	device := &NotificationDevice{
+		ID:        utils.GenID(),
+		UserID:    userID,
+		Platform:  platform,
+		Token:     token,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	return np.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "platform", "updated_at"}),
+	}).Create(device).Error
+}
+
+// SendPush 向用户的所有设备发送推送，失效的设备令牌会被删除
+// 参数:
+//
+//	userID: 用户ID
+//	title: 推送标题
+//	body: 推送内容
+//	data: 附加数据
+//
+// 返回:
+//
+//	error: 用户没有设备或存在推送失败的设备时返回错误
+func (np *NotificationPlugin) SendPush(userID, title, body string, data map[string]interface{}) error {
+	if np.db == nil {
+		return errors.New("推送通知插件未初始化")
+	}
+	devices := []*NotificationDevice{}
+	if err := np.db.Where("user_id = ?", userID).Find(&devices).Error; err != nil {
+		return err
+	}
+	if len(devices) == 0 {
+		return fmt.Errorf("用户没有已注册的设备：%s", userID)
+	}
+	var errs []error
+	for _, device := range devices {
+		sender, ok := np.senders[device.Platform]
+		if !ok {
+			errs = append(errs, fmt.Errorf("推送平台未配置：%s", device.Platform))
+			continue
+		}
+		err := sender.send(device.Token, title, body, data)
+		if errors.Is(err, errInvalidDevice) {
+			logx.Debug("删除失效的设备令牌：", device.ID)
+			np.db.Delete(device)
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sendHandler 处理发送推送的内网事件
+func (np *NotificationPlugin) sendHandler(ctx types.WorkerContext) error {
+	params := NotificationSendParams{}
+	if err := jsonx.UnmarshalFromBytes(ctx.Body(), &params); err != nil {
+		return ctx.SetStatus(http.StatusBadRequest).Response([]byte("推送参数解析失败：" + err.Error()))
+	}
+	if err := np.SendPush(params.UserID, params.Title, params.Body, params.Data); err != nil {
+		return ctx.SetStatus(http.StatusInternalServerError).Response([]byte(err.Error()))
+	}
+	return ctx.SetStatus(http.StatusOK).Response([]byte(NOTIFICATION_SUCCESS))
+}
+
+// registerDeviceHandler 处理注册设备的内网事件
+func (np *NotificationPlugin) registerDeviceHandler(ctx types.WorkerContext) error {
+	device := NotificationDevice{}
+	if err := jsonx.UnmarshalFromBytes(ctx.Body(), &device); err != nil {
+		return ctx.SetStatus(http.StatusBadRequest).Response([]byte("设备参数解析失败：" + err.Error()))
+	}
+	if err := np.RegisterDevice(device.UserID, device.Platform, device.Token); err != nil {
+		return ctx.SetStatus(http.StatusInternalServerError).Response([]byte(err.Error()))
+	}
+	return ctx.SetStatus(http.StatusOK).Response([]byte(NOTIFICATION_SUCCESS))
+}
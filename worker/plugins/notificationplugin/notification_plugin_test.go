@@ -0,0 +1,208 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notificationplugin
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/worker/types"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// fakeWorkerServer 仅提供共享配置的工作服务器
+type fakeWorkerServer struct {
+	types.WorkerServer
+	cfgs map[string]*core.SharedConfigure
+}
+
+func (f *fakeWorkerServer) SharedConfigure(sid string) *core.SharedConfigure {
+	return f.cfgs[sid]
+}
+
+func newTestPlugin(t *testing.T, cfgs ...*core.SharedConfigure) *NotificationPlugin {
+	svr := &fakeWorkerServer{cfgs: map[string]*core.SharedConfigure{}}
+	keys := []string{}
+	for _, c := range cfgs {
+		svr.cfgs[c.Key] = c
+		keys = append(keys, c.Key)
+	}
+	np := NewNotificationPlugin(svr, "", keys...)
+	db, err := gorm.Open(sqlite.Open(t.TempDir()+"/notifications.db"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	if err := db.AutoMigrate(&NotificationDevice{}); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	np.db = db
+	for _, key := range keys {
+		if err := np.loadPushConfig(key); err != nil {
+			t.Fatalf("failed to load push config %s: %v", key, err)
+		}
+	}
+	return np
+}
+
+func TestSendPushViaFCM(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})
+
+	var mu sync.Mutex
+	tokenRequests := 0
+	sent := []map[string]interface{}{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/token":
+			tokenRequests++
+			if r.FormValue("assertion") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"access_token":"fcm-token","expires_in":3600}`))
+		case "/v1/projects/demo/messages:send":
+			if r.Header.Get("Authorization") != "Bearer fcm-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			body := map[string]interface{}{}
+			data, _ := io.ReadAll(r.Body)
+			jsonx.UnmarshalFromBytes(data, &body)
+			msg := body["message"].(map[string]interface{})
+			if msg["token"] == "stale" {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error":{"status":"NOT_FOUND","details":[{"errorCode":"UNREGISTERED"}]}}`))
+				return
+			}
+			sent = append(sent, msg)
+			w.Write([]byte(`{"name":"projects/demo/messages/1"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cfg, _ := jsonx.MarshalToStr(&FCMConfig{
+		ProjectID:   "demo",
+		ClientEmail: "svc@demo.iam.gserviceaccount.com",
+		PrivateKey:  string(pemKey),
+		TokenURI:    server.URL + "/token",
+		Endpoint:    server.URL,
+	})
+	np := newTestPlugin(t, &core.SharedConfigure{Key: "fcm", Type: core.PUSH_FCM, Value: cfg})
+	np.RegisterDevice("u1", PlatformFCM, "device-1")
+	np.RegisterDevice("u1", PlatformFCM, "stale")
+
+	if err := np.SendPush("u1", "Hi", "Hello", map[string]interface{}{"count": 3}); err != nil {
+		t.Fatalf("send push failed: %v", err)
+	}
+	if err := np.SendPush("u1", "Hi", "Again", nil); err != nil {
+		t.Fatalf("send push failed: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sent) != 2 || tokenRequests != 1 {
+		t.Fatalf("unexpected sent=%d tokenRequests=%d", len(sent), tokenRequests)
+	}
+	if sent[0]["data"].(map[string]interface{})["count"] != "3" {
+		t.Fatalf("unexpected data: %v", sent[0]["data"])
+	}
+	// 失效的设备令牌应被删除
+	var count int64
+	np.db.Model(&NotificationDevice{}).Where("token = ?", "stale").Count(&count)
+	if count != 0 {
+		t.Fatal("stale device should be removed")
+	}
+}
+
+func TestSendPushViaWebPush(t *testing.T) {
+	vapidKey, _ := ecdh.P256().GenerateKey(rand.Reader)
+	uaKey, _ := ecdh.P256().GenerateKey(rand.Reader)
+	authSecret := make([]byte, 16)
+	rand.Read(authSecret)
+
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "aes128gcm" || !strings.HasPrefix(r.Header.Get("Authorization"), "vapid t=") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	cfg, _ := jsonx.MarshalToStr(&WebPushConfig{
+		VapidPublicKey:  base64.RawURLEncoding.EncodeToString(vapidKey.PublicKey().Bytes()),
+		VapidPrivateKey: base64.RawURLEncoding.EncodeToString(vapidKey.Bytes()),
+		Subject:         "mailto:admin@example.com",
+	})
+	np := newTestPlugin(t, &core.SharedConfigure{Key: "webpush", Type: core.PUSH_WEBPUSH, Value: cfg})
+
+	sub := WebPushSubscription{Endpoint: server.URL + "/push/1"}
+	sub.Keys.P256dh = base64.RawURLEncoding.EncodeToString(uaKey.PublicKey().Bytes())
+	sub.Keys.Auth = base64.RawURLEncoding.EncodeToString(authSecret)
+	token, _ := jsonx.MarshalToStr(&sub)
+	if err := np.RegisterDevice("u2", PlatformWebPush, token); err != nil {
+		t.Fatalf("register device failed: %v", err)
+	}
+	if err := np.SendPush("u2", "Hi", "Hello", nil); err != nil {
+		t.Fatalf("send push failed: %v", err)
+	}
+
+	// 使用浏览器端私钥解密，验证加密格式
+	salt, idLen := received[:16], int(received[20])
+	asPublic := received[21 : 21+idLen]
+	asKey, _ := ecdh.P256().NewPublicKey(asPublic)
+	shared, _ := uaKey.ECDH(asKey)
+	cek, nonce := deriveWebPushKeys(shared, authSecret, uaKey.PublicKey().Bytes(), asPublic, salt)
+	block, _ := aes.NewCipher(cek)
+	gcm, _ := cipher.NewGCM(block)
+	plain, err := gcm.Open(nil, nonce, received[21+idLen:], nil)
+	if err != nil {
+		t.Fatalf("failed to decrypt payload: %v", err)
+	}
+	payload := map[string]interface{}{}
+	if err := jsonx.UnmarshalFromBytes(plain[:len(plain)-1], &payload); err != nil || payload["title"] != "Hi" {
+		t.Fatalf("unexpected payload: %s, %v", plain, err)
+	}
+}
+
+func TestSendPushWithoutDevices(t *testing.T) {
+	np := newTestPlugin(t)
+	if err := np.SendPush("nobody", "Hi", "Hello", nil); err == nil {
+		t.Fatal("expected error for user without devices")
+	}
+	if err := np.RegisterDevice("u1", "sms", "token"); err == nil {
+		t.Fatal("expected error for unsupported platform")
+	}
+}
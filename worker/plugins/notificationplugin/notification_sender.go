@@ -0,0 +1,82 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notificationplugin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/worker/intranet/dispatcher"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+// NotificationSender 推送通知客户端，供自定义执行器通过内网事件调用推送通知插件
+type NotificationSender struct {
+	notificationEndpoint string
+}
+
+var (
+	NotificationEndpointEvent = &core.Event{
+		Project: core.INTERNAL_PROJECT,
+		Version: constant.INITIAL_VERSION,
+		Context: NotificationWorkerContext,
+		Entity:  NotificationWorkerEntity,
+	}
+)
+
+func init() {
+	NotificationEndpointEvent.GenerateSign()
+}
+
+// NewNotificationSender 创建推送通知客户端
+func NewNotificationSender() *NotificationSender {
+	return &NotificationSender{}
+}
+
+// SendPush 请求推送通知插件向用户发送推送
+func (ns *NotificationSender) SendPush(userID, title, body string, data map[string]interface{}) error {
+	params := &NotificationSendParams{UserID: userID, Title: title, Body: body, Data: data}
+	return ns.call(GW_T_W_NOTIFICATION_SEND, params)
+}
+
+// RegisterDevice 请求推送通知插件注册用户设备
+func (ns *NotificationSender) RegisterDevice(userID, platform, token string) error {
+	device := &NotificationDevice{UserID: userID, Platform: platform, Token: token}
+	return ns.call(GW_T_W_NOTIFICATION_REG_DEVICE, device)
+}
+
+func (ns *NotificationSender) call(typz types.INTRANET_EVENT_TYPE, params interface{}) error {
+	if ns == nil {
+		return errors.New("推送通知客户端未初始化")
+	}
+	if ns.notificationEndpoint == "" {
+		endpoint := dispatcher.GetWorkerEndpoint(NotificationEndpointEvent)
+		if endpoint == "" {
+			return errors.New("获取推送通知服务地址失败，网络错误或推送通知插件未启动")
+		}
+		ns.notificationEndpoint = endpoint
+	}
+	resp, err := dispatcher.Event(ns.notificationEndpoint, typz, params, nil)
+	if err != nil {
+		ns.notificationEndpoint = "" // 网络错误，清空notificationEndpoint
+		return err
+	}
+	if resp.Status() != http.StatusOK {
+		return errors.New(resp.TemporaryData())
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notificationplugin
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+)
+
+const (
+	webPushRecordSize = 4096
+	webPushTTL        = "86400"
+)
+
+// WebPushConfig WebPush推送配置，对应类型为 PUSH_WEBPUSH 的共享配置
+type WebPushConfig struct {
+	VapidPublicKey  string `json:"vapidPublicKey"`  // base64url编码的VAPID公钥（未压缩格式）
+	VapidPrivateKey string `json:"vapidPrivateKey"` // base64url编码的VAPID私钥
+	Subject         string `json:"subject"`         // VAPID联系方式，如 mailto:admin@example.com
+}
+
+// WebPushSubscription 浏览器的推送订阅信息，作为WebPush设备的令牌存储
+type WebPushSubscription struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+// webPushSender 按RFC 8291加密负载并通过VAPID认证发送WebPush
+type webPushSender struct {
+	cfg       *WebPushConfig
+	client    *http.Client
+	vapidKey  *ecdsa.PrivateKey
+	publicKey string
+}
+
+func newWebPushSender(cfg *WebPushConfig, client *http.Client) (*webPushSender, error) {
+	pub, err := decodeBase64URL(cfg.VapidPublicKey)
+	if err != nil || len(pub) != 65 || pub[0] != 4 {
+		return nil, errors.New("VAPID公钥格式错误")
+	}
+	priv, err := decodeBase64URL(cfg.VapidPrivateKey)
+	if err != nil || len(priv) != 32 {
+		return nil, errors.New("VAPID私钥格式错误")
+	}
+	key := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(pub[1:33]),
+			Y:     new(big.Int).SetBytes(pub[33:]),
+		},
+		D: new(big.Int).SetBytes(priv),
+	}
+	return &webPushSender{
+		cfg:       cfg,
+		client:    client,
+		vapidKey:  key,
+		publicKey: base64.RawURLEncoding.EncodeToString(pub),
+	}, nil
+}
+
+// send 向单个订阅发送推送，token 为JSON格式的订阅信息
+func (s *webPushSender) send(token, title, body string, data map[string]interface{}) error {
+	sub := WebPushSubscription{}
+	if err := jsonx.UnmarshalFromStr(token, &sub); err != nil || sub.Endpoint == "" {
+		return errInvalidDevice
+	}
+	payload, err := jsonx.MarshalToBytes(map[string]interface{}{
+		"title": title,
+		"body":  body,
+		"data":  data,
+	})
+	if err != nil {
+		return err
+	}
+	content, err := encryptWebPushPayload(&sub, payload)
+	if err != nil {
+		return err
+	}
+	authHeader, err := s.vapidAuthorization(sub.Endpoint)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, sub.Endpoint, bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", webPushTTL)
+	req.Header.Set("Authorization", authHeader)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return errInvalidDevice
+	default:
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("WebPush推送失败，状态码：%d，响应：%s", resp.StatusCode, respBody)
+	}
+}
+
+// vapidAuthorization 生成VAPID认证头，JWT使用ES256签名
+func (s *webPushSender) vapidAuthorization(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	header, _ := jsonx.MarshalToBytes(map[string]string{"typ": "JWT", "alg": "ES256"})
+	claims, _ := jsonx.MarshalToBytes(map[string]interface{}{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": time.Now().Add(12 * time.Hour).Unix(),
+		"sub": s.cfg.Subject,
+	})
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(signingInput))
+	r, ss, err := ecdsa.Sign(rand.Reader, s.vapidKey, hash[:])
+	if err != nil {
+		return "", err
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	ss.FillBytes(sig[32:])
+	jwt := signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+	return "vapid t=" + jwt + ", k=" + s.publicKey, nil
+}
+
+// encryptWebPushPayload 按RFC 8291使用aes128gcm加密推送负载
+func encryptWebPushPayload(sub *WebPushSubscription, payload []byte) ([]byte, error) {
+	uaPublic, err := decodeBase64URL(sub.Keys.P256dh)
+	if err != nil {
+		return nil, errInvalidDevice
+	}
+	authSecret, err := decodeBase64URL(sub.Keys.Auth)
+	if err != nil {
+		return nil, errInvalidDevice
+	}
+	uaKey, err := ecdh.P256().NewPublicKey(uaPublic)
+	if err != nil {
+		return nil, errInvalidDevice
+	}
+	asKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	sharedSecret, err := asKey.ECDH(uaKey)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asKey.PublicKey().Bytes()
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	cek, nonce := deriveWebPushKeys(sharedSecret, authSecret, uaPublic, asPublic, salt)
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// 单条记录，末尾追加0x02作为最后一条记录的分隔符
+	plaintext := append(append([]byte{}, payload...), 0x02)
+	ciphertext := gcm.Seal(nil, nonce, plaintext, nil)
+
+	var buf bytes.Buffer
+	buf.Write(salt)
+	rs := make([]byte, 4)
+	binary.BigEndian.PutUint32(rs, webPushRecordSize)
+	buf.Write(rs)
+	buf.WriteByte(byte(len(asPublic)))
+	buf.Write(asPublic)
+	buf.Write(ciphertext)
+	return buf.Bytes(), nil
+}
+
+// deriveWebPushKeys 按RFC 8291派生内容加密密钥和随机数
+func deriveWebPushKeys(sharedSecret, authSecret, uaPublic, asPublic, salt []byte) ([]byte, []byte) {
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublic...)
+	keyInfo = append(keyInfo, asPublic...)
+	ikm := hkdf(authSecret, sharedSecret, keyInfo, 32)
+	cek := hkdf(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce := hkdf(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+	return cek, nonce
+}
+
+// hkdf 基于HMAC-SHA256的HKDF，输出长度不超过32字节
+func hkdf(salt, secret, info []byte, length int) []byte {
+	extract := hmac.New(sha256.New, salt)
+	extract.Write(secret)
+	prk := extract.Sum(nil)
+	expand := hmac.New(sha256.New, prk)
+	expand.Write(info)
+	expand.Write([]byte{0x01})
+	return expand.Sum(nil)[:length]
+}
+
+// decodeBase64URL 解码base64url字符串，兼容带填充和不带填充的格式
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
@@ -0,0 +1,326 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduleplugin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/intranet/dispatcher"
+	"github.com/garrickvan/event-matrix/worker/types"
+	"github.com/robfig/cron/v3"
+	"gorm.io/gorm"
+)
+
+/**
+  周期性调度插件，与任务中心的一次性、延时任务互补
+  目前为单机设计，多机部署时同一任务会在每台机器上触发
+**/
+
+// SchedulePlugin 类cron调度插件，按配置的周期向目标工作端发送内网事件
+type SchedulePlugin struct {
+	worker *types.Worker
+	svr    types.WorkerServer
+	db     *gorm.DB
+	cron   *cron.Cron
+
+	mu      sync.Mutex              // 保护 entries 的并发操作
+	entries map[string]cron.EntryID // 任务ID到cron条目的映射
+}
+
+// ScheduledJob 周期任务，持久化在 scheduled_jobs 表中，重启后自动恢复
+type ScheduledJob struct {
+	ID        string `json:"id" gorm:"primaryKey"`
+	CronExpr  string `json:"cronExpr"`
+	Event     string `json:"event"` // 事件内容，JSON格式字符串
+	CreatedAt int64  `json:"createdAt"`
+	UpdatedAt int64  `json:"updatedAt"`
+}
+
+// TableName 指定周期任务表名
+func (ScheduledJob) TableName() string {
+	return "scheduled_jobs"
+}
+
+// ScheduleListParams 查询周期任务的分页参数
+type ScheduleListParams struct {
+	Page int `json:"page"`
+	Size int `json:"size"`
+}
+
+const (
+	ScheduleDB            = "schedules"
+	ScheduleWorkerContext = "gateway"
+	ScheduleWorkerEntity  = "schedule"
+
+	SCHEDULE_SUCCESS                                 = string(constant.SUCCESS)
+	GW_T_W_SCHEDULE_ADD    types.INTRANET_EVENT_TYPE = 35000
+	GW_T_W_SCHEDULE_REMOVE types.INTRANET_EVENT_TYPE = 35001
+	G_T_W_SCHEDULE_QUERY   types.INTRANET_EVENT_TYPE = 35002
+)
+
+var (
+	scheduleWorker = types.Worker{
+		Project:      core.INTERNAL_PROJECT,
+		VersionLabel: constant.INITIAL_VERSION,
+		Context:      ScheduleWorkerContext,
+		Entity:       ScheduleWorkerEntity,
+		SyncSchema:   false,
+	}
+
+	// cronParser 支持可选秒字段的cron表达式解析器，同时支持 @every 等描述符
+	cronParser = cron.NewParser(cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+	// 便于测试替换的调度函数
+	getWorkerEndpoint = dispatcher.GetWorkerEndpoint
+	dispatchEvent     = dispatcher.Event
+)
+
+// NewSchedulePlugin 创建调度插件，cfgKey 为周期任务数据库的共享配置键
+func NewSchedulePlugin(svr types.WorkerServer, cfgKey string) *SchedulePlugin {
+	scheduleWorker.CfgKey = cfgKey
+	return &SchedulePlugin{
+		worker:  &scheduleWorker,
+		svr:     svr,
+		cron:    cron.New(cron.WithParser(cronParser)),
+		entries: make(map[string]cron.EntryID),
+	}
+}
+
+// Setup 初始化周期任务表，恢复已持久化的任务，并注册调度插件
+func (sp *SchedulePlugin) Setup() error {
+	if err := sp.svr.Repo().AddDBFromSharedConfig(sp.worker.CfgKey); err != nil {
+		return err
+	}
+	if !sp.svr.Repo().HasDB(ScheduleDB) {
+		return fmt.Errorf("缺少数据库配置: [%s], 请检查配置，无法启动调度服务", ScheduleDB)
+	}
+	if err := sp.init(sp.svr.Repo().Use(ScheduleDB)); err != nil {
+		return err
+	}
+	if err := sp.svr.RegisterWorker(sp.worker); err != nil {
+		return err
+	}
+	sp.svr.RegisterPlugin(sp)
+	sp.cron.Start()
+	return nil
+}
+
+// init 迁移周期任务表并恢复已持久化的任务
+func (sp *SchedulePlugin) init(db *gorm.DB) error {
+	if err := db.AutoMigrate(&ScheduledJob{}); err != nil {
+		return fmt.Errorf("数据库表迁移失败: %w", err)
+	}
+	sp.db = db
+	jobs := []*ScheduledJob{}
+	if err := db.Find(&jobs).Error; err != nil {
+		return fmt.Errorf("读取周期任务失败: %w", err)
+	}
+	for _, job := range jobs {
+		event, err := core.NewEventFromStr(job.Event)
+		if err != nil {
+			logx.Error("周期任务事件解析失败，已跳过：", job.ID, " ", err.Error())
+			continue
+		}
+		if err := sp.schedule(job.ID, job.CronExpr, event); err != nil {
+			logx.Error("周期任务恢复失败，已跳过：", job.ID, " ", err.Error())
+		}
+	}
+	return nil
+}
+
+// ReceiveCodes 返回调度插件能够处理的事件类型列表
+func (sp *SchedulePlugin) ReceiveCodes() []types.INTRANET_EVENT_TYPE {
+	return []types.INTRANET_EVENT_TYPE{GW_T_W_SCHEDULE_ADD, GW_T_W_SCHEDULE_REMOVE, G_T_W_SCHEDULE_QUERY}
+}
+
+// Dependencies 调度插件不依赖其他插件
+func (sp *SchedulePlugin) Dependencies() []types.INTRANET_EVENT_TYPE {
+	return nil
+}
+
+// Handle 根据接收到的事件类型调用相应的处理方法
+func (sp *SchedulePlugin) Handle(ctx types.WorkerContext, typz types.INTRANET_EVENT_TYPE) error {
+	switch typz {
+	case GW_T_W_SCHEDULE_ADD:
+		return sp.addJobHandler(ctx)
+	case GW_T_W_SCHEDULE_REMOVE:
+		return sp.removeJobHandler(ctx)
+	case G_T_W_SCHEDULE_QUERY:
+		return sp.queryJobHandler(ctx)
+	default:
+		return ctx.SetStatus(http.StatusForbidden).Response([]byte(constant.UNSUPPORTED_EVENT))
+	}
+}
+
+// HealthCheck 检查调度插件的健康状态，汇报已调度的任务数
+func (sp *SchedulePlugin) HealthCheck() types.PluginHealth {
+	sp.mu.Lock()
+	details := map[string]interface{}{"jobs": len(sp.entries)}
+	sp.mu.Unlock()
+	if sp.db == nil {
+		return types.PluginHealth{Healthy: false, Message: "周期任务数据库不可用", Details: details}
+	}
+	return types.PluginHealth{Healthy: true, Message: "ok", Details: details}
+}
+
+// Shutdown 停止调度并等待正在执行的任务完成
+func (sp *SchedulePlugin) Shutdown() error {
+	<-sp.cron.Stop().Done()
+	return nil
+}
+
+// AddJob 添加周期任务，按cron表达式周期性地向目标工作端发送事件
+// 参数:
+//
+//	cronExpr: cron表达式，支持可选的秒字段和 @every 等描述符
+//	event: 触发时发送的事件
+//
+// 返回:
+//
+//	string: 任务ID
+//	error: 表达式非法或持久化失败时返回错误
+func (sp *SchedulePlugin) AddJob(cronExpr string, event *core.Event) (string, error) {
+	if sp.db == nil {
+		return "", errors.New("调度插件未初始化")
+	}
+	if event == nil {
+		return "", errors.New("事件不能为空")
+	}
+	if _, err := cronParser.Parse(cronExpr); err != nil {
+		return "", fmt.Errorf("cron表达式非法：%w", err)
+	}
+	now := utils.GetNowMilli()
+	job := &ScheduledJob{
+		ID:        utils.GenID(),
+		CronExpr:  cronExpr,
+		Event:     event.Raw(),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := sp.db.Create(job).Error; err != nil {
+		return "", fmt.Errorf("周期任务保存失败：%w", err)
+	}
+	if err := sp.schedule(job.ID, cronExpr, event); err != nil {
+		sp.db.Delete(job)
+		return "", err
+	}
+	return job.ID, nil
+}
+
+// RemoveJob 移除周期任务
+func (sp *SchedulePlugin) RemoveJob(jobID string) error {
+	if sp.db == nil {
+		return errors.New("调度插件未初始化")
+	}
+	if err := sp.db.Delete(&ScheduledJob{ID: jobID}).Error; err != nil {
+		return fmt.Errorf("周期任务删除失败：%w", err)
+	}
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	entryID, ok := sp.entries[jobID]
+	if !ok {
+		return fmt.Errorf("周期任务不存在：%s", jobID)
+	}
+	sp.cron.Remove(entryID)
+	delete(sp.entries, jobID)
+	return nil
+}
+
+// schedule 将任务加入cron调度器
+func (sp *SchedulePlugin) schedule(jobID, cronExpr string, event *core.Event) error {
+	entryID, err := sp.cron.AddFunc(cronExpr, func() { sp.fire(jobID, event) })
+	if err != nil {
+		return fmt.Errorf("cron表达式非法：%w", err)
+	}
+	sp.mu.Lock()
+	sp.entries[jobID] = entryID
+	sp.mu.Unlock()
+	return nil
+}
+
+// fire 任务触发时将事件发送到目标工作端，每次触发都使用新的事件ID、创建时间和签名
+func (sp *SchedulePlugin) fire(jobID string, event *core.Event) {
+	event = event.Renew()
+	endpoint := getWorkerEndpoint(event)
+	if endpoint == "" {
+		logx.Error("周期任务处理地址为空：", jobID, " ", event.GetUniqueLabel())
+		return
+	}
+	resp, err := dispatchEvent(endpoint, types.W_T_W_EVENT_CALL, event.Raw(), nil)
+	if err != nil {
+		logx.Error("周期任务触发失败：", jobID, " ", err.Error())
+		return
+	}
+	if resp.Status() != http.StatusOK {
+		logx.Error("周期任务处理失败：", jobID, " ", resp.TemporaryData())
+	}
+}
+
+// addJobHandler 处理添加周期任务的内网事件
+func (sp *SchedulePlugin) addJobHandler(ctx types.WorkerContext) error {
+	job := ScheduledJob{}
+	if err := jsonx.UnmarshalFromBytes(ctx.Body(), &job); err != nil {
+		return ctx.SetStatus(http.StatusBadRequest).Response([]byte("周期任务参数解析失败：" + err.Error()))
+	}
+	event, err := core.NewEventFromStr(job.Event)
+	if err != nil {
+		return ctx.SetStatus(http.StatusBadRequest).Response([]byte("周期任务事件解析失败：" + err.Error()))
+	}
+	jobID, err := sp.AddJob(job.CronExpr, event)
+	if err != nil {
+		return ctx.SetStatus(http.StatusInternalServerError).Response([]byte(err.Error()))
+	}
+	return ctx.SetStatus(http.StatusOK).Response([]byte(jobID))
+}
+
+// removeJobHandler 处理移除周期任务的内网事件，请求体为任务ID
+func (sp *SchedulePlugin) removeJobHandler(ctx types.WorkerContext) error {
+	if err := sp.RemoveJob(string(ctx.Body())); err != nil {
+		return ctx.SetStatus(http.StatusInternalServerError).Response([]byte(err.Error()))
+	}
+	return ctx.SetStatus(http.StatusOK).Response([]byte(SCHEDULE_SUCCESS))
+}
+
+// queryJobHandler 处理分页查询周期任务的内网事件
+func (sp *SchedulePlugin) queryJobHandler(ctx types.WorkerContext) error {
+	if sp.db == nil {
+		return ctx.SetStatus(http.StatusForbidden).Response([]byte("调度插件未初始化"))
+	}
+	param := ScheduleListParams{}
+	if err := jsonx.UnmarshalFromBytes(ctx.Body(), &param); err != nil {
+		return ctx.SetStatus(http.StatusBadRequest).Response([]byte("查询周期任务参数解析失败：" + err.Error()))
+	}
+	if param.Page <= 0 {
+		param.Page = 1
+	}
+	if param.Size <= 0 {
+		param.Size = 20
+	}
+	var count int64
+	jobs := []*ScheduledJob{}
+	sp.db.Model(&ScheduledJob{}).Count(&count)
+	sp.db.Order("created_at desc").Offset((param.Page - 1) * param.Size).Limit(param.Size).Find(&jobs)
+	resp := jsonx.DefaultJsonWithMsg(constant.SUCCESS, "查询成功")
+	jsonx.SetJsonList[*ScheduledJob](resp, jobs, count, param.Page)
+	return ctx.SetStatus(http.StatusOK).ResponseJson(resp)
+}
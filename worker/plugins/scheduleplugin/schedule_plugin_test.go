@@ -0,0 +1,157 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduleplugin
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/serverx"
+	"github.com/garrickvan/event-matrix/serverx/gnetx"
+	"github.com/garrickvan/event-matrix/worker/types"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// fakeDispatcher 记录触发的事件
+type fakeDispatcher struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (d *fakeDispatcher) event(endpoint string, typz types.INTRANET_EVENT_TYPE, strOrJson interface{}, request serverx.RequestContext) (serverx.ResponsePacket, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.events = append(d.events, strOrJson.(string))
+	return &gnetx.ResponsePacketImpl{StatusCode: http.StatusOK}, nil
+}
+
+func (d *fakeDispatcher) count() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.events)
+}
+
+func newTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(t.TempDir()+"/schedules.db"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	return db
+}
+
+func newTestPlugin(t *testing.T, db *gorm.DB) (*SchedulePlugin, *fakeDispatcher) {
+	d := &fakeDispatcher{}
+	originEndpoint, originEvent := getWorkerEndpoint, dispatchEvent
+	getWorkerEndpoint = func(event *core.Event) string { return "127.0.0.1:0" }
+	dispatchEvent = d.event
+	t.Cleanup(func() { getWorkerEndpoint, dispatchEvent = originEndpoint, originEvent })
+
+	sp := NewSchedulePlugin(nil, "")
+	if err := sp.init(db); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+	sp.cron.Start()
+	t.Cleanup(func() { sp.Shutdown() })
+	return sp, d
+}
+
+func TestAddJobFires(t *testing.T) {
+	sp, d := newTestPlugin(t, newTestDB(t))
+	event := &core.Event{Project: "p", Context: "c", Entity: "e", Event: "tick"}
+	if _, err := sp.AddJob("@every 1s", event); err != nil {
+		t.Fatalf("add job failed: %v", err)
+	}
+	deadline := time.Now().Add(3 * time.Second)
+	for d.count() == 0 && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if d.count() == 0 {
+		t.Fatal("expected job to fire")
+	}
+	if h := sp.HealthCheck(); !h.Healthy || h.Details["jobs"] != 1 {
+		t.Fatalf("unexpected health: %+v", h)
+	}
+}
+
+func TestFireRenewsEvent(t *testing.T) {
+	sp, d := newTestPlugin(t, newTestDB(t))
+	event := &core.Event{ID: "origin", Project: "p", Context: "c", Entity: "e", Event: "tick", CreatedAt: 1}
+	event.GenerateSign()
+	sp.fire("job", event)
+	sp.fire("job", event)
+	if d.count() != 2 {
+		t.Fatalf("expected 2 fired events, got %d", d.count())
+	}
+	ids := map[string]bool{}
+	for _, raw := range d.events {
+		fired, err := core.NewEventFromStr(raw)
+		if err != nil {
+			t.Fatalf("invalid fired event: %v", err)
+		}
+		if fired.ID == event.ID || fired.CreatedAt == event.CreatedAt || !fired.VerifySign() {
+			t.Fatalf("fired event should be renewed and re-signed: %+v", fired)
+		}
+		ids[fired.ID] = true
+	}
+	if len(ids) != 2 {
+		t.Fatal("each fire should use a new event ID")
+	}
+}
+
+func TestAddJobInvalidExpr(t *testing.T) {
+	sp, _ := newTestPlugin(t, newTestDB(t))
+	if _, err := sp.AddJob("not a cron", &core.Event{}); err == nil {
+		t.Fatal("expected error for invalid cron expression")
+	}
+	var count int64
+	sp.db.Model(&ScheduledJob{}).Count(&count)
+	if count != 0 {
+		t.Fatal("invalid job should not be persisted")
+	}
+}
+
+func TestJobsRestoredAndRemoved(t *testing.T) {
+	db := newTestDB(t)
+	sp, _ := newTestPlugin(t, db)
+	jobID, err := sp.AddJob("0 0 * * *", &core.Event{Project: "p", Event: "daily"})
+	if err != nil {
+		t.Fatalf("add job failed: %v", err)
+	}
+	sp.Shutdown()
+
+	// 重启后从数据库恢复任务
+	restored, _ := newTestPlugin(t, db)
+	if _, ok := restored.entries[jobID]; !ok {
+		t.Fatal("expected job restored after restart")
+	}
+	if err := restored.RemoveJob(jobID); err != nil {
+		t.Fatalf("remove job failed: %v", err)
+	}
+	if len(restored.cron.Entries()) != 0 {
+		t.Fatal("expected cron entry removed")
+	}
+	var count int64
+	db.Model(&ScheduledJob{}).Count(&count)
+	if count != 0 {
+		t.Fatal("expected job deleted from database")
+	}
+	if err := restored.RemoveJob(jobID); err == nil {
+		t.Fatal("expected error removing unknown job")
+	}
+}
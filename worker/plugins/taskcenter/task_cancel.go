@@ -0,0 +1,79 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskcenter
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils"
+	"github.com/garrickvan/event-matrix/worker/types"
+	"gorm.io/gorm"
+)
+
+// cancelableStatuses 可以取消的任务状态，已结束的任务不能取消
+var cancelableStatuses = []core.TaskStatus{
+	core.TaskStatusPending,
+	core.TaskStatusInProgress,
+	core.TaskStatusTimeout,
+}
+
+// CancelTask 取消尚未结束的任务，状态更新为已取消后从处理队列移除，之后不会再被拉取或重试
+// 已发送到工作者的调用无法撤回，其执行结果不再回写任务状态
+func (tc *TaskCenter) CancelTask(taskID string) error {
+	if tc == nil {
+		return errors.New("任务中心插件未初始化")
+	}
+	if taskID == "" {
+		return errors.New("任务ID不能为空")
+	}
+	db := tc.svr.Repo().Use(TaskDB)
+	result := db.Model(&core.Task{}).
+		Where("id = ? AND status IN ?", taskID, cancelableStatuses).
+		Updates(map[string]interface{}{
+			"status":     core.TaskStatusCancelled,
+			"updated_at": utils.GetNowMilli(),
+			"version":    gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 1 {
+		// 状态更新成功后才移出处理队列，更新失败时任务仍按原状态处理
+		tc.inProcessTask.Remove(taskID)
+		return nil
+	}
+	var count int64
+	if err := db.Model(&core.Task{}).Where("id = ?", taskID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("任务不存在：" + taskID)
+	}
+	return errors.New("任务已结束，无法取消：" + taskID)
+}
+
+func (tc *TaskCenter) cancelTaskHandler(ctx types.WorkerContext) error {
+	if tc == nil {
+		return ctx.SetStatus(http.StatusForbidden).Response([]byte("任务中心插件未初始化"))
+	}
+	if err := tc.CancelTask(strings.TrimSpace(string(ctx.Body()))); err != nil {
+		return ctx.SetStatus(http.StatusInternalServerError).Response([]byte("任务取消失败：" + err.Error()))
+	}
+	return ctx.SetStatus(http.StatusOK).Response([]byte(constant.SUCCESS))
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskcenter
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+func TestCancelTask(t *testing.T) {
+	tc := newSharedTaskCenter(t, t.TempDir())
+	db := tc.svr.Repo().Use(TaskDB)
+	now := utils.GetNowMilli()
+	tasks := []core.Task{
+		{ID: "pending", Status: core.TaskStatusPending, ExecuteAt: now},
+		{ID: "running", Status: core.TaskStatusInProgress, ExecuteAt: now},
+		{ID: "done", Status: core.TaskStatusSuccess, ExecuteAt: now},
+	}
+	if err := db.Create(&tasks).Error; err != nil {
+		t.Fatal(err)
+	}
+	running := tasks[1]
+	tc.inProcessTask.Set(running.ID, &running)
+
+	for _, id := range []string{"pending", "running"} {
+		if err := tc.CancelTask(id); err != nil {
+			t.Fatalf("cancel %s: %v", id, err)
+		}
+		task := core.Task{}
+		if err := db.Where("id = ?", id).First(&task).Error; err != nil {
+			t.Fatal(err)
+		}
+		if task.Status != core.TaskStatusCancelled {
+			t.Errorf("task %s status = %d, want cancelled", id, task.Status)
+		}
+	}
+	if tc.inProcessTask.Has("running") {
+		t.Error("cancelled task should be removed from the process queue")
+	}
+	// 取消后执行结果不再回写
+	if err := tc.finishTask("running", core.TaskStatusSuccess, ""); err == nil {
+		t.Error("finishing a cancelled task should fail")
+	}
+
+	// 状态未更新时任务保留在处理队列
+	done := tasks[2]
+	tc.inProcessTask.Set(done.ID, &done)
+	if err := tc.CancelTask("done"); err == nil {
+		t.Error("cancelling a finished task should fail")
+	}
+	if !tc.inProcessTask.Has("done") {
+		t.Error("task should stay in the process queue when the update fails")
+	}
+	tc.inProcessTask.Remove("done")
+	if err := tc.CancelTask("missing"); err == nil {
+		t.Error("cancelling a missing task should fail")
+	}
+
+	// 已取消的任务不会再被拉取
+	origin := handleTask
+	handleTask = func(tc *TaskCenter, task *core.Task) {
+		t.Errorf("cancelled task %s dispatched", task.ID)
+	}
+	t.Cleanup(func() { handleTask = origin })
+	tc.pollPendingTasks(10)
+}
+
+func TestCancelTaskHandler(t *testing.T) {
+	tc := newSharedTaskCenter(t, t.TempDir())
+	task := core.Task{ID: "task-1", Status: core.TaskStatusPending, ExecuteAt: utils.GetNowMilli()}
+	if err := tc.svr.Repo().Use(TaskDB).Create(&task).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := types.NewMockRequestContext(tc.svr, &core.Event{Params: "task-1"})
+	if err := tc.cancelTaskHandler(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ctx.StatusCode() != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", ctx.StatusCode(), ctx.ResponseBody())
+	}
+
+	ctx = types.NewMockRequestContext(tc.svr, &core.Event{Params: "task-1"})
+	if err := tc.cancelTaskHandler(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ctx.StatusCode() != http.StatusInternalServerError {
+		t.Errorf("cancelling twice status = %d, want 500", ctx.StatusCode())
+	}
+}
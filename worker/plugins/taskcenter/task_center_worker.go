@@ -15,9 +15,11 @@
 package taskcenter
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/garrickvan/event-matrix/constant"
@@ -27,6 +29,7 @@ import (
 	"github.com/garrickvan/event-matrix/utils/jsonx"
 	"github.com/garrickvan/event-matrix/utils/logx"
 	"github.com/garrickvan/event-matrix/worker/intranet/dispatcher"
+	"github.com/garrickvan/event-matrix/worker/plugins/logcenter"
 	"github.com/garrickvan/event-matrix/worker/types"
 	cmap "github.com/orcaman/concurrent-map/v2"
 	"github.com/spf13/cast"
@@ -35,14 +38,24 @@ import (
 )
 
 /**
-  目前为单机设计，未考虑到多机部署，如果需要拓展性能，需要考虑重新封装
+  多实例部署时共享任务数据库，通过任务的 version 字段以乐观锁认领任务，保证同一任务只由一个实例处理
 **/
 
 type TaskCenter struct {
 	worker           *types.Worker
 	svr              types.WorkerServer
 	maxInProcessTask int
+	maxReplay        int // 单个事件的最大重放次数
 	inProcessTask    cmap.ConcurrentMap[string, *core.Task]
+
+	parkMu sync.Mutex   // 保护 parked
+	parked []*core.Task // 队列已满时暂存的高优先级任务，已保存为待处理状态
+
+	mu       sync.Mutex     // 保护 stopped、draining 和 wg 的并发操作
+	stopped  bool           // 是否已关闭
+	draining bool           // 是否正在排空，排空时不再接收新任务
+	stopChan chan struct{}  // 关闭信号通道
+	wg       sync.WaitGroup // 守护协程和处理中的任务
 }
 
 type TaskListParams struct {
@@ -60,8 +73,30 @@ const (
 	TASK_ADD_SUCCESS                                      = string(constant.SUCCESS)
 	GW_T_W_TASK_CENTER_ADD_TASK types.INTRANET_EVENT_TYPE = 32000
 	G_T_W_TASK_CENTER_QUERY     types.INTRANET_EVENT_TYPE = 32001
+	// G_T_W_TASK_CENTER_CANCEL_TASK 取消尚未结束的任务，请求体为任务ID
+	G_T_W_TASK_CENTER_CANCEL_TASK types.INTRANET_EVENT_TYPE = 32002
+	// GW_T_W_TASK_CENTER_REPLAY_EVENT 根据事件日志ID重放事件
+	GW_T_W_TASK_CENTER_REPLAY_EVENT types.INTRANET_EVENT_TYPE = 32003
+
+	// DEFAULT_MAX_REPLAY 单个事件默认的最大重放次数
+	DEFAULT_MAX_REPLAY = 3
+
+	// TASK_CLAIM_TIMEOUT 处理中的任务超过该时间未更新时视为认领它的实例已失效，可由其他实例重试
+	TASK_CLAIM_TIMEOUT = 5 * time.Minute
+
+	// TASK_DRAIN_CHECK_INTERVAL 排空时检查处理中任务数的间隔
+	TASK_DRAIN_CHECK_INTERVAL = 50 * time.Millisecond
+
+	// DEFAULT_TASK_TIMEOUT 任务事件未配置超时时间时的默认超时时间，单位秒，与工作者执行任务的默认超时一致
+	DEFAULT_TASK_TIMEOUT = 10
+
+	// taskTimeoutGrace 等待工作者返回任务结果的额外时间，使工作者自身的超时响应先返回
+	taskTimeoutGrace = time.Second
 )
 
+// handleTask 处理已认领的任务，单元测试时可替换
+var handleTask = (*TaskCenter).handlerTask
+
 var (
 	taskCenterWorker = types.Worker{
 		Project:      core.INTERNAL_PROJECT,
@@ -78,7 +113,9 @@ func NewTaskCenter(svr types.WorkerServer, cfgKey string, maxInProcessTask int)
 		worker:           &taskCenterWorker,
 		svr:              svr,
 		maxInProcessTask: maxInProcessTask,
+		maxReplay:        DEFAULT_MAX_REPLAY,
 		inProcessTask:    cmap.New[*core.Task](),
+		stopChan:         make(chan struct{}),
 	}
 	return tc
 }
@@ -104,13 +141,144 @@ func (tc *TaskCenter) Setup() error {
 		return err
 	}
 	tc.svr.RegisterPlugin(tc)
-	go tc.start()      // 处理待启动任务
-	go tc.retrieTask() // 处理重试任务
+	tc.runDaemons()
+	return nil
+}
+
+// runDaemons 启动任务处理守护协程
+func (tc *TaskCenter) runDaemons() {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if tc.stopped {
+		return
+	}
+	tc.wg.Add(2)
+	go func() {
+		defer tc.wg.Done()
+		tc.start() // 处理待启动任务
+	}()
+	go func() {
+		defer tc.wg.Done()
+		tc.retrieTask() // 处理重试任务
+	}()
+}
+
+// Shutdown 关闭任务中心，停止拉取新任务，并等待处理中的任务完成
+func (tc *TaskCenter) Shutdown() error {
+	tc.mu.Lock()
+	if tc.stopped {
+		tc.mu.Unlock()
+		return nil
+	}
+	tc.stopped = true
+	close(tc.stopChan)
+	tc.mu.Unlock()
+	tc.wg.Wait()
 	return nil
 }
 
+// Drain 停止接收新任务，并等待处理中的任务全部完成，ctx 到期时返回 ctx 的错误。
+// 排空期间新增的任务保存为待处理状态，由其他实例或重启后的实例处理
+func (tc *TaskCenter) Drain(ctx context.Context) error {
+	tc.mu.Lock()
+	tc.draining = true
+	tc.mu.Unlock()
+	ticker := time.NewTicker(TASK_DRAIN_CHECK_INTERVAL)
+	defer ticker.Stop()
+	for tc.inProcessTask.Count() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// HealthCheck 检查任务中心的健康状态，汇报待处理任务数和处理中任务数
+func (tc *TaskCenter) HealthCheck() types.PluginHealth {
+	details := map[string]interface{}{
+		"in_process":     tc.inProcessTask.Count(),
+		"max_in_process": tc.maxInProcessTask,
+	}
+	if tc.isStopped() {
+		return types.PluginHealth{Healthy: false, Message: "任务中心已关闭", Details: details}
+	}
+	if tc.svr == nil || tc.svr.Repo() == nil || !tc.svr.Repo().HasDB(TaskDB) {
+		return types.PluginHealth{Healthy: false, Message: "任务数据库不可用", Details: details}
+	}
+	pending, err := tc.QueueDepth()
+	if err != nil {
+		return types.PluginHealth{Healthy: false, Message: "查询待处理任务失败：" + err.Error(), Details: details}
+	}
+	details["queue_depth"] = pending
+	return types.PluginHealth{Healthy: true, Message: "ok", Details: details}
+}
+
+// QueueDepth 返回待处理的任务数
+func (tc *TaskCenter) QueueDepth() (int64, error) {
+	if tc.svr == nil || tc.svr.Repo() == nil || !tc.svr.Repo().HasDB(TaskDB) {
+		return 0, errors.New("任务数据库不可用")
+	}
+	var pending int64
+	err := tc.svr.Repo().Use(TaskDB).Model(&core.Task{}).
+		Where("status = ?", core.TaskStatusPending).Count(&pending).Error
+	return pending, err
+}
+
+// isStopped 判断任务中心是否已关闭
+func (tc *TaskCenter) isStopped() bool {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return tc.stopped
+}
+
+// isAccepting 判断任务中心是否接收新任务，关闭或排空后不再接收
+func (tc *TaskCenter) isAccepting() bool {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	return !tc.stopped && !tc.draining
+}
+
+// dispatch 异步处理任务，任务中心关闭后不再派发
+func (tc *TaskCenter) dispatch(task *core.Task) bool {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if tc.stopped {
+		return false
+	}
+	tc.wg.Add(1)
+	go func() {
+		defer tc.wg.Done()
+		handleTask(tc, task)
+		// 任务完成后空出位置，优先认领暂存的高优先级任务
+		tc.resumeParked()
+	}()
+	return true
+}
+
+// waitOrStop 等待指定时间，期间收到关闭信号则返回false
+func (tc *TaskCenter) waitOrStop(d time.Duration) bool {
+	select {
+	case <-tc.stopChan:
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
 func (tc *TaskCenter) ReceiveCodes() []types.INTRANET_EVENT_TYPE {
-	return []types.INTRANET_EVENT_TYPE{GW_T_W_TASK_CENTER_ADD_TASK, G_T_W_TASK_CENTER_QUERY}
+	return []types.INTRANET_EVENT_TYPE{
+		GW_T_W_TASK_CENTER_ADD_TASK,
+		G_T_W_TASK_CENTER_QUERY,
+		G_T_W_TASK_CENTER_CANCEL_TASK,
+		GW_T_W_TASK_CENTER_REPLAY_EVENT,
+	}
+}
+
+// Dependencies 任务中心重放事件时读取日志中心的事件日志库，需在日志中心之后安装
+func (tc *TaskCenter) Dependencies() []types.INTRANET_EVENT_TYPE {
+	return []types.INTRANET_EVENT_TYPE{logcenter.GW_T_W_EVENT_LOG_SUBMIT}
 }
 
 func (tc *TaskCenter) Handle(ctx types.WorkerContext, typz types.INTRANET_EVENT_TYPE) error {
@@ -119,6 +287,10 @@ func (tc *TaskCenter) Handle(ctx types.WorkerContext, typz types.INTRANET_EVENT_
 		return tc.addTaskHandler(ctx)
 	case G_T_W_TASK_CENTER_QUERY:
 		return tc.queryTaskHandler(ctx)
+	case G_T_W_TASK_CENTER_CANCEL_TASK:
+		return tc.cancelTaskHandler(ctx)
+	case GW_T_W_TASK_CENTER_REPLAY_EVENT:
+		return tc.replayEventHandler(ctx)
 	default:
 		return ctx.SetStatus(http.StatusForbidden).Response([]byte(constant.UNSUPPORTED_EVENT))
 	}
@@ -128,8 +300,83 @@ func (tc *TaskCenter) remainingSize() int {
 	return tc.maxInProcessTask - tc.inProcessTask.Count()
 }
 
+// addTask 保存新任务为处理中并加入处理队列，队列已满时高优先级任务保存为待处理并暂存，有空位时优先处理。
+// 队列已满时不会将优先级最低的任务退回待处理：处理队列中的任务都已派发给执行方，无法撤回，
+// 退回后会被再次认领而重复执行。暂存只是内存中的快速通道，任务已保存为待处理，
+// 重启或被替换后仍由 pollPendingTasks 按优先级认领
 func (tc *TaskCenter) addTask(task *core.Task) bool {
-	if tc == nil {
+	if tc != nil && task.Priority > core.TaskPriorityNormal && tc.isAccepting() && tc.remainingSize() <= 0 {
+		return tc.park(task)
+	}
+	return tc.enqueue(task, func() (bool, error) {
+		return true, tc.saveTaskOnDB(task, core.TaskStatusInProgress)
+	})
+}
+
+// addPendingTask 认领数据库中待处理的任务并加入处理队列，认领失败说明任务已被其他实例处理
+func (tc *TaskCenter) addPendingTask(task *core.Task) bool {
+	return tc.enqueue(task, func() (bool, error) {
+		return tc.claimTask(task, core.TaskStatusInProgress, task.Retries)
+	})
+}
+
+// park 队列已满时将高优先级任务保存为待处理并暂存，处理中的任务结束后先于数据库轮询认领暂存的任务。
+// 已派发的任务不会被抢占，暂存数量以最大处理任务数为上限，超出的任务只保存到数据库，由轮询按优先级认领
+func (tc *TaskCenter) park(task *core.Task) bool {
+	if err := tc.saveTaskOnDB(task, core.TaskStatusPending); err != nil {
+		logx.Error(err.Error())
+		return false
+	}
+	tc.parkMu.Lock()
+	defer tc.parkMu.Unlock()
+	if len(tc.parked) < tc.maxInProcessTask {
+		tc.parked = append(tc.parked, task)
+		return true
+	}
+	// 暂存已满时替换优先级最低的暂存任务，被替换的任务仍由轮询认领
+	lowest := 0
+	for i, t := range tc.parked {
+		if t.Priority < tc.parked[lowest].Priority {
+			lowest = i
+		}
+	}
+	if tc.parked[lowest].Priority < task.Priority {
+		logx.Infof("暂存任务已满，任务 %s 替换暂存任务 %s，被替换的任务保持待处理，由轮询认领", task.ID, tc.parked[lowest].ID)
+		tc.parked[lowest] = task
+	} else {
+		logx.Infof("暂存任务已满，任务 %s 保持待处理，由轮询认领", task.ID)
+	}
+	return true
+}
+
+// resumeParked 按优先级从高到低、计划执行时间从早到晚认领暂存的任务，直到队列填满或没有暂存任务，
+// 认领失败说明任务已被轮询或其他实例处理，直接丢弃
+func (tc *TaskCenter) resumeParked() {
+	for tc.remainingSize() > 0 {
+		tc.parkMu.Lock()
+		if len(tc.parked) == 0 {
+			tc.parkMu.Unlock()
+			return
+		}
+		next := 0
+		for i, t := range tc.parked {
+			if t.Priority > tc.parked[next].Priority ||
+				(t.Priority == tc.parked[next].Priority && t.ExecuteAt < tc.parked[next].ExecuteAt) {
+				next = i
+			}
+		}
+		task := tc.parked[next]
+		tc.parked = append(tc.parked[:next], tc.parked[next+1:]...)
+		tc.parkMu.Unlock()
+		if !tc.addPendingTask(task) && !tc.isAccepting() {
+			return
+		}
+	}
+}
+
+// enqueue 检查容量后持久化任务状态，成功后异步处理任务
+func (tc *TaskCenter) enqueue(task *core.Task, persist func() (bool, error)) bool {
+	if tc == nil || !tc.isAccepting() {
 		return false
 	}
 	// 检查是否有剩余容量
@@ -140,13 +387,19 @@ func (tc *TaskCenter) addTask(task *core.Task) bool {
 		// 任务已存在
 		return true
 	}
-	err := tc.saveTaskOnDB(task, core.TaskStatusInProgress)
+	ok, err := persist()
 	if err != nil {
-		logx.Log().Error(err.Error())
+		logx.Error(err.Error())
+		return false
+	}
+	if !ok {
 		return false
 	}
-	go tc.handlerTask(task)
 	tc.inProcessTask.Set(task.ID, task)
+	if !tc.dispatch(task) {
+		tc.inProcessTask.Remove(task.ID)
+		return false
+	}
 	return true
 }
 
@@ -157,6 +410,12 @@ func (tc *TaskCenter) finishTask(taskID string, status core.TaskStatus, execServ
 	}
 	task.ExecServer = execServer
 	err := tc.updateTaskToDB(task, status, task.Retries)
+	if errors.Is(err, errTaskStale) {
+		// 任务已被取消或超过认领超时后由其他实例重新认领，本实例的结果不再回写
+		logx.Warn("任务已被取消或由其他实例认领，忽略执行结果：" + taskID)
+		tc.inProcessTask.Remove(taskID)
+		return err
+	}
 	if err != nil {
 		return err
 	}
@@ -197,93 +456,115 @@ func (tc *TaskCenter) saveTaskOnDB(task *core.Task, status core.TaskStatus) erro
 		DoUpdates: clause.Assignments(map[string]interface{}{
 			"status":     status,
 			"updated_at": task.UpdatedAt, // 明确指定更新时间
+			"version":    gorm.Expr("version + 1"),
 		}),
 	}).Create(task).Error
 
 	if err != nil {
 		return err
 	}
-	return nil
+	// 任务已存在时版本号在数据库中递增，重新读取使后续的乐观锁更新以数据库中的版本号为准
+	return db.Model(&core.Task{}).Select("version").Where("id = ?", task.ID).Scan(&task.Version).Error
 }
 
+// errTaskStale 数据库中任务的版本号已变化，说明任务已被取消或由其他实例重新认领
+var errTaskStale = errors.New("任务版本已变化")
+
+// updateTaskToDB 以乐观锁更新任务状态，数据库中的版本号与内存中不一致时返回 errTaskStale
 func (tc *TaskCenter) updateTaskToDB(task *core.Task, status core.TaskStatus, retries int) error {
 	db := tc.svr.Repo().Use(TaskDB)
 	return db.Transaction(func(tx *gorm.DB) error {
 		task.Status = status
 		task.UpdatedAt = utils.GetNowMilli()
 		task.Retries = retries
-		result := tx.Model(task).Select("status", "updated_at", "retries", "exec_server").Updates(map[string]interface{}{
+		result := tx.Model(task).Where("version = ?", task.Version).
+			Select("status", "updated_at", "retries", "exec_server", "version").Updates(map[string]interface{}{
 			"status":      status,
 			"updated_at":  utils.GetNowMilli(),
 			"retries":     retries,
 			"exec_server": task.ExecServer,
+			"version":     gorm.Expr("version + 1"),
 		})
 		if result.Error != nil {
 			return result.Error
 		}
+		if result.RowsAffected == 0 {
+			return errTaskStale
+		}
+		task.Version++
 		return nil
 	})
 }
 
+// claimTask 以乐观锁认领任务，仅当数据库中任务的状态和版本号与读取时一致才更新成功，
+// 多个实例同时认领同一任务时只有一个实例返回true
+func (tc *TaskCenter) claimTask(task *core.Task, status core.TaskStatus, retries int) (bool, error) {
+	now := utils.GetNowMilli()
+	result := tc.svr.Repo().Use(TaskDB).Model(&core.Task{}).
+		Where("id = ? AND version = ? AND status = ?", task.ID, task.Version, task.Status).
+		Updates(map[string]interface{}{
+			"status":     status,
+			"updated_at": now,
+			"retries":    retries,
+			"version":    gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected != 1 {
+		return false, nil
+	}
+	task.Status = status
+	task.UpdatedAt = now
+	task.Retries = retries
+	task.Version++
+	return true, nil
+}
+
 func (tc *TaskCenter) start() {
-	pageSize := 100
 	// 每隔3秒从数据库中获取待处理任务，并处理
-	for {
-		time.Sleep(3 * time.Second)
-		pageNo := 1
-		remainingSize := tc.remainingSize()
-		if remainingSize > 0 {
-			// 定义外部的 tasks 切片
-			tasks := []core.Task{}
-			// 分页从数据库中获取任务状态为待处理，且执行时间已到的任务，直到任务队列填满为止
-			for {
-				remainingSize := tc.remainingSize()
-				if remainingSize <= 0 {
-					break
-				}
-				// 获取数据库中符合条件的任务
-				db := tc.svr.Repo().Use(TaskDB).Model(&core.Task{}).
-					Where("status = ? AND execute_at <= ?", core.TaskStatusPending, utils.GetNowMilli()).
-					Limit(pageSize).Offset((pageNo - 1) * pageSize).Find(&tasks)
-
-				// 处理数据库错误
-				if db.Error != nil {
-					logx.Log().Error("从数据库中获取任务失败：" + db.Error.Error())
-					break
-				}
-
-				// 检查是否没有更多任务可获取
-				if len(tasks) == 0 {
-					break
-				}
-
-				// 遍历任务并加入任务队列
-				for _, task := range tasks {
-					// 在加入任务队列前再次检查队列容量
-					if tc.remainingSize() <= 0 {
-						break
-					}
-					// 加入任务
-					tc.addTask(&task)
-				}
-
-				// 如果任务队列已满，停止分页获取任务
-				if tc.remainingSize() <= 0 {
-					break
-				}
+	for tc.waitOrStop(3 * time.Second) {
+		tc.pollPendingTasks(100)
+	}
+}
 
-				// 清空 tasks，防止任务重复处理
-				tasks = []core.Task{}
-				pageNo++
+// pollPendingTasks 按优先级从高到低、计划执行时间从早到晚分批获取待处理且执行时间已到的任务并认领，直到任务队列填满或没有更多任务为止
+// 认领成功或被其他实例认领的任务都会离开待处理状态，因此每次都查询第一页
+func (tc *TaskCenter) pollPendingTasks(pageSize int) {
+	for tc.remainingSize() > 0 {
+		tasks := []core.Task{}
+		db := tc.svr.Repo().Use(TaskDB).Model(&core.Task{}).
+			Where("status = ? AND execute_at <= ?", core.TaskStatusPending, utils.GetNowMilli()).
+			Order("priority DESC, execute_at ASC").
+			Limit(pageSize).Find(&tasks)
+		if db.Error != nil {
+			logx.Error("从数据库中获取任务失败：" + db.Error.Error())
+			return
+		}
+		if len(tasks) == 0 {
+			return
+		}
+		claimed := 0
+		for i := range tasks {
+			// 在加入任务队列前再次检查队列容量
+			if tc.remainingSize() <= 0 {
+				return
+			}
+			if tc.addPendingTask(&tasks[i]) {
+				claimed++
 			}
 		}
+		// 本批任务都未能认领时等待下一轮，避免重复查询同一批任务
+		if claimed == 0 {
+			return
+		}
 	}
 }
 
 /*
 *
 *
-重试机制规则
+默认重试机制规则，事件未配置重试策略时使用，事件配置了 RetryPolicy 时按其回退方式和最大重试次数处理
 Retries	 延迟时间 (秒)	 延迟时间 (分钟)
 1    5    0.08
 2    40   0.72
@@ -297,85 +578,122 @@ Retries	 延迟时间 (秒)	 延迟时间 (分钟)
 10   3600  60.00
 */
 func (tc *TaskCenter) retrieTask() {
-	pageSize := 100
-
-	// 计算重试任务的延时回退时间，最大延时1小时
-	calculateBackoff := func(retries int) int64 {
-		baseDelay := int64(5)                                      // 每次回退的基本时间为5秒
-		maxDelay := int64(3600 * 1000)                             // 最大延时为1小时
-		delay := baseDelay * int64(retries*retries*retries) * 1000 // retries³秒 -> 毫秒
-		// 限制最大延时为1小时
-		if delay > maxDelay {
-			return maxDelay
-		}
-		return delay
+	// 每隔10秒从数据库中获取未在 inProcessTask 中但状态为 InProgress 或 Timeout 的任务，已取消的任务不会被重试，并重新加入任务队列，直到任务队列填满为止或没有更多任务可获取
+	for tc.waitOrStop(10 * time.Second) {
+		tc.retryStaleTasks(100)
 	}
+}
 
-	// 每隔10秒从数据库中获取未在 inProcessTask 中但状态为 InProgress 或 Timeout 的任务，并重新加入任务队列，直到任务队列填满为止或没有更多任务可获取
+// retryStaleTasks 分页获取需要重试的任务，按任务事件的重试策略计算回退时间后认领并重新处理，
+// 重试次数达到上限的任务标记为失败
+func (tc *TaskCenter) retryStaleTasks(pageSize int) {
+	policies := map[string]*core.RetryPolicy{} // 本轮按事件标签缓存的重试策略
+	pageNo := 1
 	for {
-		time.Sleep(10 * time.Second)
-		pageNo := 1
+		// 排空或关闭后不再认领重试任务
+		if !tc.isAccepting() {
+			return
+		}
+		remainingSize := tc.remainingSize()
+		if remainingSize <= 0 {
+			return
+		}
 
-		for {
-			remainingSize := tc.remainingSize()
-			if remainingSize <= 0 {
-				break
-			}
+		tasks := []core.Task{}
+		// 从数据库中分页获取状态为 InProgress 或 Timeout 的任务
+		db := tc.svr.Repo().Use(TaskDB).
+			Where("status IN (?, ?)", core.TaskStatusInProgress, core.TaskStatusTimeout).
+			Limit(pageSize).Offset((pageNo - 1) * pageSize).Find(&tasks)
 
-			tasks := []core.Task{}
-			// 从数据库中分页获取状态为 InProgress 或 Timeout 的任务
-			db := tc.svr.Repo().Use(TaskDB).
-				Where("status IN (?, ?)", core.TaskStatusInProgress, core.TaskStatusTimeout).
-				Limit(pageSize).Offset((pageNo - 1) * pageSize).Find(&tasks)
+		if db.Error != nil {
+			logx.Error(db.Error.Error())
+			return
+		}
 
-			if db.Error != nil {
-				logx.Log().Error(db.Error.Error())
-				break
-			}
+		// 如果没有更多任务了，退出分页循环
+		if len(tasks) == 0 {
+			return
+		}
 
-			// 如果没有更多任务了，退出分页循环
-			if len(tasks) == 0 {
-				break
+		// 遍历任务列表，处理每个任务
+		for _, task := range tasks {
+			// 如果任务在 inProcessTask 中，则跳过
+			if tc.inProcessTask.Has(task.ID) {
+				continue
 			}
-
-			// 遍历任务列表，处理每个任务
-			for _, task := range tasks {
-				exists := tc.inProcessTask.Has(task.ID)
-				// 如果任务不在 inProcessTask 中，则重新添加任务
-				if !exists {
-					// 计算回退机制的延迟时间
-					delay := calculateBackoff(task.Retries)
-
-					// 当前时间戳
-					now := utils.GetNowMilli()
-					// 检查任务的执行时间是否应该延迟处理
-					if now < task.ExecuteAt+delay {
-						continue // 如果未达到延迟时间，跳过该任务
-					}
-					// 检查 remainingSize，避免超出容量
-					remainingSize := tc.remainingSize()
-					if remainingSize <= 0 {
-						break
-					}
-					// 更新任务的 ExecuteAt 以延迟下次重试时间
-					task.ExecuteAt = now + delay
-					// 更新任务状态为 Pending 并增加重试次数，同时更新 ExecuteAt
-					err := tc.updateTaskToDB(&task, core.TaskStatusPending, task.Retries+1)
-					if err != nil {
-						logx.Log().Error(err.Error())
-						continue
-					}
-					// 将任务添加到 inProcessTask
-					tc.inProcessTask.Set(task.ID, &task)
-					// 处理任务（并发处理）
-					go tc.handlerTask(&task)
+			policy, ok := policies[task.EventLabel]
+			if !ok {
+				policy = tc.retryPolicy(&task)
+				policies[task.EventLabel] = policy
+			}
+			// 计算回退机制的延迟时间
+			delay := policy.Backoff(task.Retries).Milliseconds()
+
+			// 当前时间戳
+			now := utils.GetNowMilli()
+			// 回退时间从上次认领（即上次尝试）开始计算，认领不会改写计划执行时间
+			if now < task.UpdatedAt+delay {
+				continue // 如果未达到延迟时间，跳过该任务
+			}
+			// 处理中的任务可能由其他实例处理，超过认领超时仍未更新才重试
+			if task.Status == core.TaskStatusInProgress && now < task.UpdatedAt+TASK_CLAIM_TIMEOUT.Milliseconds() {
+				continue
+			}
+			// 重试次数达到上限，标记为失败
+			if policy.Exhausted(task.Retries) {
+				if _, err := tc.claimTask(&task, core.TaskStatusFailed, task.Retries); err != nil {
+					logx.Error(err.Error())
 				}
+				continue
+			}
+			// 检查 remainingSize，避免超出容量
+			if tc.remainingSize() <= 0 {
+				return
+			}
+			// 认领任务并增加重试次数，认领后状态为处理中，避免其他实例重复处理
+			claimed, err := tc.claimTask(&task, core.TaskStatusInProgress, task.Retries+1)
+			if err != nil {
+				logx.Error(err.Error())
+				continue
+			}
+			if !claimed {
+				continue
+			}
+			// 将任务添加到 inProcessTask
+			tc.inProcessTask.Set(task.ID, &task)
+			// 处理任务（并发处理）
+			if !tc.dispatch(&task) {
+				tc.inProcessTask.Remove(task.ID)
+				return
 			}
-			pageNo++
 		}
+		pageNo++
 	}
 }
 
+// taskTimeout 返回等待工作者执行任务的超时时间，按事件配置的超时时间计算，未配置时使用默认超时时间
+func (tc *TaskCenter) taskTimeout(event *core.Event) time.Duration {
+	timeout := DEFAULT_TASK_TIMEOUT
+	entityEvent := tc.svr.DomainCache().EntityEvent(types.PathToEventFromEvent(event))
+	if entityEvent != nil && entityEvent.Timeout > 0 {
+		timeout = entityEvent.Timeout
+	}
+	return time.Duration(timeout)*time.Second + taskTimeoutGrace
+}
+
+// retryPolicy 返回任务事件配置的重试策略，事件不存在或未配置时返回nil，按默认规则重试
+func (tc *TaskCenter) retryPolicy(task *core.Task) *core.RetryPolicy {
+	event, err := core.NewEventFromStr(task.Event)
+	if err != nil {
+		return nil
+	}
+	entityEvent := tc.svr.DomainCache().EntityEvent(types.PathToEventFromEvent(event))
+	if entityEvent == nil {
+		return nil
+	}
+	return entityEvent.RetryPolicy
+}
+
 func (tc *TaskCenter) handlerTask(task *core.Task) {
 	event, err := core.NewEventFromStr(task.Event)
 	if err != nil {
@@ -390,8 +708,15 @@ func (tc *TaskCenter) handlerTask(task *core.Task) {
 		tc.finishTask(task.ID, core.TaskStatusFailed, "")
 		return
 	}
-	// 发送任务到处理器
-	status, serverId, err := tc.invokeTaskOnWorker(endpoint, event)
+	// 发送任务到处理器，超过事件的超时时间未返回时视为任务超时
+	ctx, cancel := context.WithTimeout(context.Background(), tc.taskTimeout(event))
+	defer cancel()
+	status, serverId, err := tc.invokeTaskOnWorker(ctx, endpoint, event)
+	if errors.Is(err, context.DeadlineExceeded) {
+		logx.Warn("任务执行超时：" + task.ID + " " + event.GetUniqueLabel())
+		tc.finishTask(task.ID, core.TaskStatusTimeout, serverId)
+		return
+	}
 	if err != nil {
 		logx.Log().Error("任务处理失败：" + err.Error())
 		tc.finishTask(task.ID, core.TaskStatusFailed, serverId)
@@ -400,8 +725,8 @@ func (tc *TaskCenter) handlerTask(task *core.Task) {
 	tc.finishTask(task.ID, status, serverId)
 }
 
-func (tc *TaskCenter) invokeTaskOnWorker(workerEndpiont string, event *core.Event) (core.TaskStatus, string, error) {
-	resp, err := dispatcher.Event(workerEndpiont, types.W_T_W_EVENT_CALL, event.Raw(), nil)
+func (tc *TaskCenter) invokeTaskOnWorker(ctx context.Context, workerEndpiont string, event *core.Event) (core.TaskStatus, string, error) {
+	resp, err := dispatcher.EventWithContext(ctx, workerEndpiont, types.W_T_W_EVENT_CALL, event.Raw(), nil)
 	if err != nil {
 		return core.TaskStatusFailed, "", err
 	}
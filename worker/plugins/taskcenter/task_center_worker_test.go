@@ -0,0 +1,71 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskcenter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/garrickvan/event-matrix/core"
+)
+
+func TestTaskCenterShutdown(t *testing.T) {
+	tc := NewTaskCenter(nil, "", 10)
+	tc.runDaemons()
+
+	done := make(chan error, 1)
+	go func() { done <- tc.Shutdown() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("shutdown failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("shutdown should stop daemons promptly")
+	}
+
+	// 关闭后不再接收和派发任务
+	if tc.addTask(&core.Task{ID: "t1"}) {
+		t.Fatal("task should not be accepted after shutdown")
+	}
+	if tc.dispatch(&core.Task{ID: "t2"}) {
+		t.Fatal("task should not be dispatched after shutdown")
+	}
+	if tc.inProcessTask.Count() != 0 {
+		t.Fatalf("expected no in-process tasks, got %d", tc.inProcessTask.Count())
+	}
+	// 重复关闭应直接返回
+	if err := tc.Shutdown(); err != nil {
+		t.Fatalf("second shutdown failed: %v", err)
+	}
+}
+
+func TestTaskCenterHealthCheck(t *testing.T) {
+	tc := NewTaskCenter(nil, "", 10)
+	tc.inProcessTask.Set("t1", &core.Task{ID: "t1"})
+
+	h := tc.HealthCheck()
+	if h.Healthy {
+		t.Fatal("task center without database should be unhealthy")
+	}
+	if h.Details["in_process"] != 1 || h.Details["max_in_process"] != 10 {
+		t.Fatalf("unexpected details: %v", h.Details)
+	}
+
+	tc.Shutdown()
+	if h := tc.HealthCheck(); h.Healthy || h.Message != "任务中心已关闭" {
+		t.Fatalf("unexpected health after shutdown: %+v", h)
+	}
+}
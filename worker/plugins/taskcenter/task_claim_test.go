@@ -0,0 +1,181 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskcenter
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/database"
+	"github.com/garrickvan/event-matrix/utils"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+// newSharedTaskCenter 创建使用指定目录下任务数据库的任务中心，多个实例共享同一个 SQLite 文件
+func newSharedTaskCenter(t *testing.T, dir string) *TaskCenter {
+	t.Helper()
+	svr := types.NewMockWorkerServer(nil)
+	t.Cleanup(func() { svr.Stop() })
+	err := svr.Repo().RegisterDB(&database.DBConf{Type: database.SQLITE, Location: dir, DBName: TaskDB})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svr.Repo().Use(TaskDB).AutoMigrate(&core.Task{}); err != nil {
+		t.Fatal(err)
+	}
+	return NewTaskCenter(svr, "", 1000)
+}
+
+func TestTaskRunsOnceAcrossInstances(t *testing.T) {
+	dir := t.TempDir()
+	tc1 := newSharedTaskCenter(t, dir)
+	tc2 := newSharedTaskCenter(t, dir)
+
+	var mu sync.Mutex
+	runs := map[string]int{}
+	origin := handleTask
+	handleTask = func(tc *TaskCenter, task *core.Task) {
+		mu.Lock()
+		runs[task.ID]++
+		mu.Unlock()
+		tc.finishTask(task.ID, core.TaskStatusSuccess, "")
+	}
+	t.Cleanup(func() { handleTask = origin })
+
+	const total = 200
+	now := utils.GetNowMilli()
+	tasks := make([]core.Task, 0, total)
+	for i := 0; i < total; i++ {
+		tasks = append(tasks, core.Task{ID: fmt.Sprintf("task-%d", i), Status: core.TaskStatusPending, ExecuteAt: now})
+	}
+	if err := tc1.svr.Repo().Use(TaskDB).CreateInBatches(tasks, 50).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// 两个实例同时多次拉取待处理任务
+	var wg sync.WaitGroup
+	for _, tc := range []*TaskCenter{tc1, tc2} {
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func(tc *TaskCenter) {
+				defer wg.Done()
+				tc.pollPendingTasks(20)
+			}(tc)
+		}
+	}
+	wg.Wait()
+	tc1.Shutdown()
+	tc2.Shutdown()
+
+	if len(runs) != total {
+		t.Fatalf("expected %d tasks to run, got %d", total, len(runs))
+	}
+	for id, n := range runs {
+		if n != 1 {
+			t.Errorf("task %s ran %d times", id, n)
+		}
+	}
+	var pending int64
+	tc1.svr.Repo().Use(TaskDB).Model(&core.Task{}).Where("status <> ?", core.TaskStatusSuccess).Count(&pending)
+	if pending != 0 {
+		t.Fatalf("expected all tasks finished, %d left", pending)
+	}
+}
+
+func TestClaimTaskVersionCheck(t *testing.T) {
+	tc := newSharedTaskCenter(t, t.TempDir())
+	task := core.Task{ID: "t1", Status: core.TaskStatusPending}
+	if err := tc.svr.Repo().Use(TaskDB).Create(&task).Error; err != nil {
+		t.Fatal(err)
+	}
+	stale := task
+	ok, err := tc.claimTask(&task, core.TaskStatusInProgress, 0)
+	if err != nil || !ok {
+		t.Fatalf("first claim should succeed: %v %v", ok, err)
+	}
+	if task.Version != 1 || task.Status != core.TaskStatusInProgress {
+		t.Fatalf("unexpected task after claim: %+v", task)
+	}
+	// 使用过期的版本号认领应失败
+	if ok, err := tc.claimTask(&stale, core.TaskStatusInProgress, 0); err != nil || ok {
+		t.Fatalf("stale claim should fail: %v %v", ok, err)
+	}
+}
+
+func TestFinishTaskAfterReclaim(t *testing.T) {
+	dir := t.TempDir()
+	slow := newSharedTaskCenter(t, dir)
+	other := newSharedTaskCenter(t, dir)
+	now := utils.GetNowMilli()
+	if err := slow.svr.Repo().Use(TaskDB).Create(&core.Task{ID: "t1", Status: core.TaskStatusPending, ExecuteAt: now}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	task := core.Task{}
+	slow.svr.Repo().Use(TaskDB).Where("id = ?", "t1").First(&task)
+	if ok, err := slow.claimTask(&task, core.TaskStatusInProgress, 0); !ok || err != nil {
+		t.Fatalf("claim failed: %v %v", ok, err)
+	}
+	slow.inProcessTask.Set(task.ID, &task)
+
+	// 超过认领超时后其他实例重新认领任务
+	reclaimed := core.Task{}
+	other.svr.Repo().Use(TaskDB).Where("id = ?", "t1").First(&reclaimed)
+	if ok, err := other.claimTask(&reclaimed, core.TaskStatusInProgress, 1); !ok || err != nil {
+		t.Fatalf("reclaim failed: %v %v", ok, err)
+	}
+
+	// 原实例的执行结果不应覆盖重新认领后的状态
+	if err := slow.finishTask("t1", core.TaskStatusFailed, ""); err == nil {
+		t.Fatal("finishing a reclaimed task should fail")
+	}
+	if slow.inProcessTask.Has("t1") {
+		t.Error("stale task should be removed from the process queue")
+	}
+	if status := taskStatus(other, "t1"); status != core.TaskStatusInProgress {
+		t.Fatalf("reclaimed task status = %d, want in progress", status)
+	}
+
+	// 重新认领的实例可以正常回写结果
+	other.inProcessTask.Set(reclaimed.ID, &reclaimed)
+	if err := other.finishTask("t1", core.TaskStatusSuccess, ""); err != nil {
+		t.Fatal(err)
+	}
+	if status := taskStatus(other, "t1"); status != core.TaskStatusSuccess {
+		t.Fatalf("task status = %d, want success", status)
+	}
+
+	// 其他实例取消后，原实例的执行结果不应覆盖已取消状态
+	if err := slow.svr.Repo().Use(TaskDB).Create(&core.Task{ID: "t2", Status: core.TaskStatusPending, ExecuteAt: now}).Error; err != nil {
+		t.Fatal(err)
+	}
+	task = core.Task{}
+	slow.svr.Repo().Use(TaskDB).Where("id = ?", "t2").First(&task)
+	if ok, err := slow.claimTask(&task, core.TaskStatusInProgress, 0); !ok || err != nil {
+		t.Fatalf("claim failed: %v %v", ok, err)
+	}
+	slow.inProcessTask.Set(task.ID, &task)
+	if err := other.CancelTask("t2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := slow.finishTask("t2", core.TaskStatusSuccess, ""); err == nil {
+		t.Fatal("finishing a cancelled task should fail")
+	}
+	if status := taskStatus(other, "t2"); status != core.TaskStatusCancelled {
+		t.Fatalf("cancelled task status = %d, want cancelled", status)
+	}
+}
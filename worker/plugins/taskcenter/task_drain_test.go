@@ -0,0 +1,73 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskcenter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils"
+)
+
+func TestDrainWaitsForInProcessTasks(t *testing.T) {
+	tc := newSharedTaskCenter(t, t.TempDir())
+	release := make(chan struct{})
+	var finished int64
+	origin := handleTask
+	handleTask = func(tc *TaskCenter, task *core.Task) {
+		<-release
+		tc.finishTask(task.ID, core.TaskStatusSuccess, "")
+		atomic.AddInt64(&finished, 1)
+	}
+	t.Cleanup(func() { handleTask = origin })
+
+	const total = 10
+	now := utils.GetNowMilli()
+	for i := 0; i < total; i++ {
+		if !tc.addTask(&core.Task{ID: fmt.Sprintf("task-%d", i), ExecuteAt: now}) {
+			t.Fatalf("task-%d should be accepted", i)
+		}
+	}
+
+	// 超时前任务未完成，返回 ctx 的错误
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := tc.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	// 排空期间不再接收新任务，新任务由 addTaskHandler 保存为待处理
+	if tc.addTask(&core.Task{ID: "late", ExecuteAt: now}) {
+		t.Fatal("draining task center should not accept new tasks")
+	}
+
+	close(release)
+	if err := tc.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt64(&finished); n != total {
+		t.Fatalf("expected %d tasks finished before drain returns, got %d", total, n)
+	}
+	tc.Shutdown()
+	var done int64
+	tc.svr.Repo().Use(TaskDB).Model(&core.Task{}).Where("status = ?", core.TaskStatusSuccess).Count(&done)
+	if done != total {
+		t.Fatalf("expected %d tasks persisted as success, got %d", total, done)
+	}
+}
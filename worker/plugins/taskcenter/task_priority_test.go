@@ -0,0 +1,199 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskcenter
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils"
+)
+
+func TestPollPendingTasksByPriority(t *testing.T) {
+	tc := newSharedTaskCenter(t, t.TempDir())
+	tc.maxInProcessTask = 2
+
+	var mu sync.Mutex
+	started := []string{}
+	block := make(chan struct{})
+	origin := handleTask
+	handleTask = func(tc *TaskCenter, task *core.Task) {
+		mu.Lock()
+		started = append(started, task.ID)
+		mu.Unlock()
+		<-block
+		tc.finishTask(task.ID, core.TaskStatusSuccess, "")
+	}
+	t.Cleanup(func() { handleTask = origin })
+
+	now := utils.GetNowMilli()
+	tasks := []core.Task{
+		{ID: "normal-early", Status: core.TaskStatusPending, ExecuteAt: now - 2000},
+		{ID: "high", Status: core.TaskStatusPending, ExecuteAt: now, Priority: core.TaskPriorityHigh},
+		{ID: "critical-late", Status: core.TaskStatusPending, ExecuteAt: now, Priority: core.TaskPriorityCritical},
+		{ID: "critical-early", Status: core.TaskStatusPending, ExecuteAt: now - 1000, Priority: core.TaskPriorityCritical},
+	}
+	if err := tc.svr.Repo().Use(TaskDB).Create(&tasks).Error; err != nil {
+		t.Fatal(err)
+	}
+	tc.pollPendingTasks(10)
+	close(block)
+	tc.Shutdown()
+
+	if len(started) != 2 || taskStatus(tc, "critical-early") == core.TaskStatusPending ||
+		taskStatus(tc, "critical-late") == core.TaskStatusPending {
+		t.Fatalf("队列已满时应先处理紧急任务，实际处理 %v", started)
+	}
+}
+
+// taskStatus 返回数据库中任务的状态
+func taskStatus(tc *TaskCenter, id string) core.TaskStatus {
+	task := core.Task{}
+	tc.svr.Repo().Use(TaskDB).Where("id = ?", id).First(&task)
+	return task.Status
+}
+
+func TestAddTaskParksHighPriority(t *testing.T) {
+	tc := newSharedTaskCenter(t, t.TempDir())
+	tc.maxInProcessTask = 1
+
+	var mu sync.Mutex
+	started := []string{}
+	release := make(chan struct{})
+	origin := handleTask
+	handleTask = func(tc *TaskCenter, task *core.Task) {
+		mu.Lock()
+		started = append(started, task.ID)
+		mu.Unlock()
+		<-release
+		tc.finishTask(task.ID, core.TaskStatusSuccess, "")
+	}
+	t.Cleanup(func() { handleTask = origin })
+
+	now := utils.GetNowMilli()
+	if !tc.addTask(&core.Task{ID: "normal", ExecuteAt: now}) {
+		t.Fatal("队列未满时应接收任务")
+	}
+	// 普通任务在队列已满时不接收，由调用方保存为待处理
+	if tc.addTask(&core.Task{ID: "normal-2", ExecuteAt: now}) {
+		t.Fatal("队列已满时不应接收普通任务")
+	}
+	if !tc.addTask(&core.Task{ID: "high", ExecuteAt: now, Priority: core.TaskPriorityHigh}) ||
+		!tc.addTask(&core.Task{ID: "critical", ExecuteAt: now, Priority: core.TaskPriorityCritical}) {
+		t.Fatal("队列已满时高优先级任务应暂存")
+	}
+	// 已派发的任务不会被抢占
+	if !tc.inProcessTask.Has("normal") || tc.inProcessTask.Count() != 1 {
+		t.Fatalf("已派发的任务不应被退回，当前队列 %v", tc.inProcessTask.Keys())
+	}
+	if status := taskStatus(tc, "critical"); status != core.TaskStatusPending {
+		t.Fatalf("暂存的任务应保存为待处理状态，实际 %d", status)
+	}
+
+	close(release)
+	waitStarted := func(n int) {
+		for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			mu.Lock()
+			got := len(started)
+			mu.Unlock()
+			if got == n && tc.inProcessTask.Count() == 0 {
+				return
+			}
+		}
+	}
+	// 暂存已满时紧急任务替换高优先级任务，空出位置后先处理紧急任务
+	waitStarted(2)
+	if status := taskStatus(tc, "high"); status != core.TaskStatusPending {
+		t.Fatalf("被替换的暂存任务应保持待处理，实际 %d", status)
+	}
+	// 被替换的任务由轮询认领
+	tc.pollPendingTasks(10)
+	waitStarted(3)
+	tc.Shutdown()
+	if len(started) != 3 || started[0] != "normal" || started[1] != "critical" || started[2] != "high" {
+		t.Fatalf("空出位置后应按优先级处理暂存任务，实际 %v", started)
+	}
+	for _, id := range []string{"normal", "high", "critical"} {
+		if status := taskStatus(tc, id); status != core.TaskStatusSuccess {
+			t.Errorf("任务 %s 应只执行一次并完成，实际状态 %d", id, status)
+		}
+	}
+}
+
+func TestReplacedParkedTaskPolled(t *testing.T) {
+	tc := newSharedTaskCenter(t, t.TempDir())
+	tc.maxInProcessTask = 1
+
+	var mu sync.Mutex
+	started := []string{}
+	origin := handleTask
+	handleTask = func(tc *TaskCenter, task *core.Task) {
+		mu.Lock()
+		started = append(started, task.ID)
+		mu.Unlock()
+		tc.finishTask(task.ID, core.TaskStatusSuccess, "")
+	}
+	t.Cleanup(func() { handleTask = origin })
+
+	now := utils.GetNowMilli()
+	if !tc.park(&core.Task{ID: "high", ExecuteAt: now, Priority: core.TaskPriorityHigh}) ||
+		!tc.park(&core.Task{ID: "critical", ExecuteAt: now, Priority: core.TaskPriorityCritical}) {
+		t.Fatal("暂存任务应保存成功")
+	}
+	tc.parkMu.Lock()
+	if len(tc.parked) != 1 || tc.parked[0].ID != "critical" {
+		t.Fatalf("暂存已满时应替换优先级最低的任务，实际 %v", tc.parked)
+	}
+	tc.parkMu.Unlock()
+
+	// 被替换的任务只在数据库中保持待处理，由轮询认领
+	tc.pollPendingTasks(10)
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if taskStatus(tc, "high") == core.TaskStatusSuccess && taskStatus(tc, "critical") == core.TaskStatusSuccess {
+			break
+		}
+		tc.pollPendingTasks(10)
+	}
+	tc.Shutdown()
+	for _, id := range []string{"high", "critical"} {
+		if status := taskStatus(tc, id); status != core.TaskStatusSuccess {
+			t.Errorf("任务 %s 应被轮询认领并完成，实际状态 %d", id, status)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(started) != 2 {
+		t.Fatalf("每个任务应只执行一次，实际 %v", started)
+	}
+}
+
+func TestNewPriorityTask(t *testing.T) {
+	event := &core.Event{ID: "e1", Project: "p", Version: "1.0.0", Context: "c", Entity: "e", Event: "run"}
+	cases := []struct {
+		task *core.Task
+		want uint8
+	}{
+		{core.NewTask(event, 0), core.TaskPriorityNormal},
+		{core.NewHighPriorityTask(event, 0), core.TaskPriorityHigh},
+		{core.NewCriticalTask(event, 0), core.TaskPriorityCritical},
+	}
+	for _, c := range cases {
+		if c.task.Priority != c.want || c.task.ID == "" || c.task.EventID != "e1" || c.task.Event == "" {
+			t.Errorf("unexpected task: %+v", c.task)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskcenter
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/worker/plugins/logcenter"
+	"github.com/garrickvan/event-matrix/worker/types"
+	"gorm.io/gorm"
+)
+
+// ReplayEventParams 重放事件的请求参数
+type ReplayEventParams struct {
+	EventLogID string `json:"eventLogId"`
+}
+
+// SetMaxReplay 设置单个事件的最大重放次数，小于等于0时使用默认值
+func (tc *TaskCenter) SetMaxReplay(n int) {
+	if n <= 0 {
+		n = DEFAULT_MAX_REPLAY
+	}
+	tc.maxReplay = n
+}
+
+// ReplayEvent 根据事件日志ID取出原始事件，生成新的待处理任务重新执行
+// 重放的事件使用新的ID、创建时间和签名，任务的 EventID 仍为原始事件ID，用于统计重放次数，
+// 同一事件的重放次数不能超过最大重放次数，任务由任务中心守护协程调度
+func (tc *TaskCenter) ReplayEvent(eventLogID string) error {
+	if tc == nil || tc.svr == nil {
+		return errors.New("任务中心插件未初始化")
+	}
+	if tc.isStopped() {
+		return errors.New("任务中心已关闭")
+	}
+	if eventLogID == "" {
+		return errors.New("事件日志ID不能为空")
+	}
+	repo := tc.svr.Repo()
+	if !repo.HasDB(logcenter.EventLogDB) || !repo.HasDB(TaskDB) {
+		return errors.New("事件日志或任务数据库不可用")
+	}
+	eventLog := core.EventLog{}
+	err := repo.Use(logcenter.EventLogDB).Where("id = ?", eventLogID).First(&eventLog).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("事件日志不存在：" + eventLogID)
+	}
+	if err != nil {
+		return err
+	}
+	event, err := core.NewEventFromStr(eventLog.EventRaw)
+	if err != nil || event.IsEmpty() {
+		return errors.New("事件日志中的原始事件无效：" + eventLogID)
+	}
+	var replayed int64
+	err = repo.Use(TaskDB).Model(&core.Task{}).
+		Where("event_id = ? AND replay_count > 0", event.ID).Count(&replayed).Error
+	if err != nil {
+		return err
+	}
+	if int(replayed) >= tc.maxReplay {
+		return fmt.Errorf("事件已重放%d次，超过最大重放次数", replayed)
+	}
+	task := core.NewTask(event.Renew(), utils.GetNowMilli())
+	task.EventID = event.ID
+	task.ReplayCount = int(replayed) + 1
+	return tc.saveTaskOnDB(task, core.TaskStatusPending)
+}
+
+func (tc *TaskCenter) replayEventHandler(ctx types.WorkerContext) error {
+	if tc == nil {
+		return ctx.SetStatus(http.StatusForbidden).Response([]byte("任务中心插件未初始化"))
+	}
+	param := ReplayEventParams{}
+	err := jsonx.UnmarshalFromBytes(ctx.Body(), &param)
+	if err != nil {
+		return ctx.SetStatus(http.StatusBadRequest).Response([]byte("重放参数解析失败：" + err.Error()))
+	}
+	if err := tc.ReplayEvent(param.EventLogID); err != nil {
+		return ctx.SetStatus(http.StatusBadRequest).Response([]byte("事件重放失败：" + err.Error()))
+	}
+	return ctx.SetStatus(http.StatusOK).Response([]byte(TASK_ADD_SUCCESS))
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskcenter
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/worker/plugins/logcenter"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+// newReplayTestCenter 创建带有事件日志的任务中心
+func newReplayTestCenter(t *testing.T) *TaskCenter {
+	t.Helper()
+	svr := types.NewMockWorkerServer(nil)
+	t.Cleanup(func() { svr.Stop() })
+	if err := svr.Repo().Use(logcenter.EventLogDB).AutoMigrate(&core.EventLog{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := svr.Repo().Use(TaskDB).AutoMigrate(&core.Task{}); err != nil {
+		t.Fatal(err)
+	}
+	event := &core.Event{
+		ID: "evt-1", Project: "shop", Version: "v1", Context: "user", Entity: "order",
+		Event: "pay", Params: `{"orderId":"o-1"}`, CreatedAt: 1700000000000,
+	}
+	event.GenerateSign()
+	raw, _ := jsonx.MarshalToStr(event)
+	eventLog := core.EventLog{ID: "log-1", EventNode: event.GetUniqueLabel(), EventRaw: raw}
+	if err := svr.Repo().Use(logcenter.EventLogDB).Create(&eventLog).Error; err != nil {
+		t.Fatal(err)
+	}
+	return NewTaskCenter(svr, "", 10)
+}
+
+func TestReplayEvent(t *testing.T) {
+	tc := newReplayTestCenter(t)
+	tc.SetMaxReplay(2)
+
+	for i := 1; i <= 2; i++ {
+		if err := tc.ReplayEvent("log-1"); err != nil {
+			t.Fatalf("replay %d failed: %v", i, err)
+		}
+	}
+	if err := tc.ReplayEvent("log-1"); err == nil {
+		t.Fatal("replay should be rejected after reaching the limit")
+	}
+	if err := tc.ReplayEvent("missing"); err == nil {
+		t.Fatal("replay of unknown event log should fail")
+	}
+
+	tasks := []core.Task{}
+	tc.svr.Repo().Use(TaskDB).Order("replay_count").Find(&tasks)
+	if len(tasks) != 2 {
+		t.Fatalf("expected 2 replay tasks, got %d", len(tasks))
+	}
+	for i, task := range tasks {
+		if task.ReplayCount != i+1 || task.EventID != "evt-1" || task.Status != core.TaskStatusPending {
+			t.Errorf("unexpected task: %+v", task)
+		}
+		event, err := core.NewEventFromStr(task.Event)
+		if err != nil || !event.VerifySign() {
+			t.Errorf("replayed event should have a valid sign: %v", err)
+		}
+		if event.ID == "evt-1" || event.CreatedAt == 1700000000000 || event.Params != `{"orderId":"o-1"}` {
+			t.Errorf("replayed event should be renewed with the same params: %+v", event)
+		}
+	}
+}
+
+func TestReplayEventHandler(t *testing.T) {
+	tc := newReplayTestCenter(t)
+	ctx := types.NewMockRequestContext(tc.svr, &core.Event{Params: `{"eventLogId":"log-1"}`})
+	if err := tc.Handle(ctx, GW_T_W_TASK_CENTER_REPLAY_EVENT); err != nil {
+		t.Fatal(err)
+	}
+	if ctx.StatusCode() != http.StatusOK || string(ctx.ResponseBody()) != TASK_ADD_SUCCESS {
+		t.Fatalf("unexpected response: %d %s", ctx.StatusCode(), ctx.ResponseBody())
+	}
+
+	ctx = types.NewMockRequestContext(tc.svr, &core.Event{Params: `{"eventLogId":"missing"}`})
+	tc.Handle(ctx, GW_T_W_TASK_CENTER_REPLAY_EVENT)
+	if ctx.StatusCode() != http.StatusBadRequest || !strings.Contains(string(ctx.ResponseBody()), "missing") {
+		t.Fatalf("unexpected response: %d %s", ctx.StatusCode(), ctx.ResponseBody())
+	}
+}
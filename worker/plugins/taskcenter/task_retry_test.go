@@ -0,0 +1,103 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package taskcenter
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+func TestRetryStaleTasksWithPolicy(t *testing.T) {
+	tc := newSharedTaskCenter(t, t.TempDir())
+	ws := tc.svr.(*types.MockWorkerServer)
+	entity := types.PathToEntity{Project: "shop", Version: "v1", Context: "order", Entity: "order"}
+	ws.SetEntityEvents(entity, []core.EntityEvent{
+		{Code: "export", RetryPolicy: &core.RetryPolicy{MaxRetries: 0}},
+		{Code: "callback", RetryPolicy: &core.RetryPolicy{MaxRetries: 3, BackoffType: core.BACKOFF_FIXED, BackoffBase: 1}},
+		{Code: "plain"},
+	})
+	eventOf := func(code string) string {
+		e := core.Event{Project: entity.Project, Version: entity.Version, Context: entity.Context, Entity: entity.Entity, Event: code}
+		return e.Raw()
+	}
+	now := utils.GetNowMilli()
+	tasks := []core.Task{
+		// 不重试的任务直接标记为失败
+		{ID: "export", EventLabel: "export", Event: eventOf("export"), Status: core.TaskStatusTimeout, UpdatedAt: now - 60000},
+		// 固定1秒回退，已超过回退时间，应被重试
+		{ID: "callback", EventLabel: "callback", Event: eventOf("callback"), Status: core.TaskStatusTimeout, Retries: 1, ExecuteAt: now - 60000, UpdatedAt: now - 2000},
+		// 达到最大重试次数，标记为失败
+		{ID: "callback-max", EventLabel: "callback", Event: eventOf("callback"), Status: core.TaskStatusTimeout, Retries: 3, UpdatedAt: now - 60000},
+		// 未配置策略，第3次重试需要回退135秒，尚未到期
+		{ID: "plain", EventLabel: "plain", Event: eventOf("plain"), Status: core.TaskStatusTimeout, Retries: 3, ExecuteAt: now - 600000, UpdatedAt: now - 10000},
+	}
+	db := tc.svr.Repo().Use(TaskDB)
+	if err := db.Create(&tasks).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	dispatched := []string{}
+	origin := handleTask
+	handleTask = func(tc *TaskCenter, task *core.Task) {
+		mu.Lock()
+		dispatched = append(dispatched, task.ID)
+		mu.Unlock()
+	}
+	t.Cleanup(func() { handleTask = origin })
+
+	tc.retryStaleTasks(10)
+	tc.Shutdown()
+
+	if len(dispatched) != 1 || dispatched[0] != "callback" {
+		t.Errorf("dispatched = %v, want [callback]", dispatched)
+	}
+	want := map[string]core.TaskStatus{
+		"export":       core.TaskStatusFailed,
+		"callback":     core.TaskStatusInProgress,
+		"callback-max": core.TaskStatusFailed,
+		"plain":        core.TaskStatusTimeout,
+	}
+	for id, status := range want {
+		task := core.Task{}
+		if err := db.Where("id = ?", id).First(&task).Error; err != nil {
+			t.Fatal(err)
+		}
+		if task.Status != status {
+			t.Errorf("task %s status = %d, want %d", id, task.Status, status)
+		}
+	}
+}
+
+func TestTaskTimeout(t *testing.T) {
+	tc := newSharedTaskCenter(t, t.TempDir())
+	ws := tc.svr.(*types.MockWorkerServer)
+	entity := types.PathToEntity{Project: "shop", Version: "v1", Context: "order", Entity: "order"}
+	ws.SetEntityEvents(entity, []core.EntityEvent{{Code: "sync", Timeout: 30}, {Code: "notify"}})
+
+	event := &core.Event{Project: "shop", Version: "v1", Context: "order", Entity: "order", Event: "sync"}
+	if got := tc.taskTimeout(event); got != 30*time.Second+taskTimeoutGrace {
+		t.Errorf("应使用事件配置的超时时间，实际 %v", got)
+	}
+	event.Event = "notify"
+	if got := tc.taskTimeout(event); got != DEFAULT_TASK_TIMEOUT*time.Second+taskTimeoutGrace {
+		t.Errorf("未配置超时时应使用默认超时时间，实际 %v", got)
+	}
+}
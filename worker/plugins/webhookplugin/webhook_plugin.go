@@ -0,0 +1,392 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhookplugin
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/types"
+	"gorm.io/gorm"
+)
+
+// WebhookPlugin 事件处理成功后将事件转发到外部HTTP地址，用于通知CRM、ERP等外部系统
+// 仅转发实体事件配置了 Logable=true 且执行成功的事件
+type WebhookPlugin struct {
+	worker *types.Worker
+	svr    types.WorkerServer
+	db     *gorm.DB
+	client *http.Client
+
+	regMu         sync.RWMutex           // 保护 registrations 的并发操作
+	registrations []*WebhookRegistration // 内存中的注册信息，避免每次事件都查询数据库
+
+	mu       sync.Mutex     // 保护 stopped 和 wg 的并发操作
+	stopped  bool           // 是否已关闭
+	stopChan chan struct{}  // 关闭信号通道
+	wg       sync.WaitGroup // 投递中的请求
+}
+
+// WebhookRegistration Webhook注册信息，存储在 webhook_registrations 表中
+// Event 为空时匹配实体下的所有事件
+type WebhookRegistration struct {
+	ID        string `json:"id" gorm:"primaryKey"`
+	Project   string `json:"project" gorm:"index"`
+	Context   string `json:"context"`
+	Entity    string `json:"entity"`
+	Event     string `json:"event"`
+	URL       string `json:"url"`
+	Secret    string `json:"secret"` // 签名密钥，不为空时在请求头中携带HMAC-SHA256签名
+	CreatedAt int64  `json:"createdAt"`
+	UpdatedAt int64  `json:"updatedAt"`
+}
+
+// TableName 指定Webhook注册表名
+func (WebhookRegistration) TableName() string {
+	return "webhook_registrations"
+}
+
+// matches 判断注册信息是否匹配事件
+func (r *WebhookRegistration) matches(event *core.Event) bool {
+	return r.Project == event.Project && r.Context == event.Context && r.Entity == event.Entity &&
+		(r.Event == "" || r.Event == event.Event)
+}
+
+// WebhookPayload 投递到外部地址的请求体，不包含访问令牌等敏感信息
+type WebhookPayload struct {
+	ID        string              `json:"id"`
+	Project   string              `json:"project"`
+	Version   string              `json:"version"`
+	Context   string              `json:"context"`
+	Entity    string              `json:"entity"`
+	Event     string              `json:"event"`
+	Source    string              `json:"source"`
+	Params    string              `json:"params"`
+	CreatedAt int64               `json:"createdAt"`
+	Response  *jsonx.JsonResponse `json:"response"`
+}
+
+const (
+	WebhookDB            = "webhooks"
+	WebhookWorkerContext = "gateway"
+	WebhookWorkerEntity  = "webhook"
+	SignatureHeader      = "X-Webhook-Signature"
+
+	WEBHOOK_SUCCESS                                   = string(constant.SUCCESS)
+	GW_T_W_WEBHOOK_REGISTER types.INTRANET_EVENT_TYPE = 36000
+	GW_T_W_WEBHOOK_DELETE   types.INTRANET_EVENT_TYPE = 36001
+
+	maxDeliveryAttempts = 3
+	deliveryTimeout     = 10 * time.Second
+)
+
+var (
+	webhookWorker = types.Worker{
+		Project:      core.INTERNAL_PROJECT,
+		VersionLabel: constant.INITIAL_VERSION,
+		Context:      WebhookWorkerContext,
+		Entity:       WebhookWorkerEntity,
+		SyncSchema:   false,
+	}
+
+	// retryBaseDelay 重试的基础延时，第n次重试等待 retryBaseDelay * 2^(n-1)
+	retryBaseDelay = time.Second
+)
+
+// filterRegistrar 支持注册过滤器的工作服务器
+type filterRegistrar interface {
+	RegisterFilter(filter types.Filter)
+}
+
+// NewWebhookPlugin 创建Webhook插件，cfgKey 为注册信息数据库的共享配置键
+func NewWebhookPlugin(svr types.WorkerServer, cfgKey string) *WebhookPlugin {
+	webhookWorker.CfgKey = cfgKey
+	return &WebhookPlugin{
+		worker:   &webhookWorker,
+		svr:      svr,
+		client:   &http.Client{Timeout: deliveryTimeout},
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Setup 初始化注册表，注册事件过滤器，并注册Webhook插件
+func (wp *WebhookPlugin) Setup() error {
+	registrar, ok := wp.svr.(filterRegistrar)
+	if !ok {
+		return errors.New("工作服务器不支持注册过滤器，无法启动Webhook服务")
+	}
+	if err := wp.svr.Repo().AddDBFromSharedConfig(wp.worker.CfgKey); err != nil {
+		return err
+	}
+	if !wp.svr.Repo().HasDB(WebhookDB) {
+		return fmt.Errorf("缺少数据库配置: [%s], 请检查配置，无法启动Webhook服务", WebhookDB)
+	}
+	if err := wp.init(wp.svr.Repo().Use(WebhookDB)); err != nil {
+		return err
+	}
+	if err := wp.svr.RegisterWorker(wp.worker); err != nil {
+		return err
+	}
+	wp.svr.RegisterPlugin(wp)
+	registrar.RegisterFilter(wp.Filter)
+	return nil
+}
+
+// init 迁移注册表并加载注册信息
+func (wp *WebhookPlugin) init(db *gorm.DB) error {
+	if err := db.AutoMigrate(&WebhookRegistration{}); err != nil {
+		return fmt.Errorf("数据库表迁移失败: %w", err)
+	}
+	wp.db = db
+	return wp.reload()
+}
+
+// reload 从数据库重新加载注册信息
+func (wp *WebhookPlugin) reload() error {
+	regs := []*WebhookRegistration{}
+	if err := wp.db.Find(&regs).Error; err != nil {
+		return fmt.Errorf("读取Webhook注册信息失败: %w", err)
+	}
+	wp.regMu.Lock()
+	wp.registrations = regs
+	wp.regMu.Unlock()
+	return nil
+}
+
+// ReceiveCodes 返回Webhook插件能够处理的事件类型列表
+func (wp *WebhookPlugin) ReceiveCodes() []types.INTRANET_EVENT_TYPE {
+	return []types.INTRANET_EVENT_TYPE{GW_T_W_WEBHOOK_REGISTER, GW_T_W_WEBHOOK_DELETE}
+}
+
+// Dependencies Webhook插件不依赖其他插件
+func (wp *WebhookPlugin) Dependencies() []types.INTRANET_EVENT_TYPE {
+	return nil
+}
+
+// Handle 根据接收到的事件类型调用相应的处理方法
+func (wp *WebhookPlugin) Handle(ctx types.WorkerContext, typz types.INTRANET_EVENT_TYPE) error {
+	switch typz {
+	case GW_T_W_WEBHOOK_REGISTER:
+		return wp.registerHandler(ctx)
+	case GW_T_W_WEBHOOK_DELETE:
+		return wp.deleteHandler(ctx)
+	default:
+		return ctx.SetStatus(http.StatusForbidden).Response([]byte(constant.UNSUPPORTED_EVENT))
+	}
+}
+
+// HealthCheck 检查Webhook插件的健康状态，汇报注册数
+func (wp *WebhookPlugin) HealthCheck() types.PluginHealth {
+	wp.regMu.RLock()
+	details := map[string]interface{}{"registrations": len(wp.registrations)}
+	wp.regMu.RUnlock()
+	if wp.isStopped() {
+		return types.PluginHealth{Healthy: false, Message: "Webhook插件已关闭", Details: details}
+	}
+	if wp.db == nil {
+		return types.PluginHealth{Healthy: false, Message: "Webhook数据库不可用", Details: details}
+	}
+	return types.PluginHealth{Healthy: true, Message: "ok", Details: details}
+}
+
+// Shutdown 关闭Webhook插件，取消重试等待并等待投递中的请求完成
+func (wp *WebhookPlugin) Shutdown() error {
+	wp.mu.Lock()
+	if wp.stopped {
+		wp.mu.Unlock()
+		return nil
+	}
+	wp.stopped = true
+	close(wp.stopChan)
+	wp.mu.Unlock()
+	wp.wg.Wait()
+	return nil
+}
+
+// isStopped 判断Webhook插件是否已关闭
+func (wp *WebhookPlugin) isStopped() bool {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+	return wp.stopped
+}
+
+// Register 添加Webhook注册信息
+func (wp *WebhookPlugin) Register(reg *WebhookRegistration) (string, error) {
+	if wp.db == nil {
+		return "", errors.New("Webhook插件未初始化")
+	}
+	if reg == nil || reg.URL == "" || reg.Project == "" || reg.Context == "" || reg.Entity == "" {
+		return "", errors.New("Webhook注册信息不完整，project、context、entity、url 均不能为空")
+	}
+	now := utils.GetNowMilli()
+	reg.ID = utils.GenID()
+	reg.CreatedAt = now
+	reg.UpdatedAt = now
+	if err := wp.db.Create(reg).Error; err != nil {
+		return "", fmt.Errorf("Webhook注册信息保存失败：%w", err)
+	}
+	return reg.ID, wp.reload()
+}
+
+// Delete 删除Webhook注册信息
+func (wp *WebhookPlugin) Delete(id string) error {
+	if wp.db == nil {
+		return errors.New("Webhook插件未初始化")
+	}
+	result := wp.db.Delete(&WebhookRegistration{ID: id})
+	if result.Error != nil {
+		return fmt.Errorf("Webhook注册信息删除失败：%w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("Webhook注册信息不存在：%s", id)
+	}
+	return wp.reload()
+}
+
+// Filter 事件过滤器，实体事件开启日志且执行成功时异步投递到匹配的Webhook
+func (wp *WebhookPlugin) Filter(ctx types.WorkerContext, jsResp *jsonx.JsonResponse) bool {
+	entityEvent := ctx.EntityEvent()
+	event := ctx.Event()
+	if entityEvent == nil || !entityEvent.Logable || event == nil {
+		return false
+	}
+	if jsResp == nil || jsResp.Code != string(constant.SUCCESS) {
+		return false
+	}
+	wp.regMu.RLock()
+	regs := []*WebhookRegistration{}
+	for _, reg := range wp.registrations {
+		if reg.matches(event) {
+			regs = append(regs, reg)
+		}
+	}
+	wp.regMu.RUnlock()
+	if len(regs) == 0 {
+		return false
+	}
+	body, err := jsonx.MarshalToBytes(&WebhookPayload{
+		ID:        event.ID,
+		Project:   event.Project,
+		Version:   event.Version,
+		Context:   event.Context,
+		Entity:    event.Entity,
+		Event:     event.Event,
+		Source:    event.Source,
+		Params:    event.Params,
+		CreatedAt: event.CreatedAt,
+		Response:  jsResp,
+	})
+	if err != nil {
+		logx.Error("Webhook请求体序列化失败：", err.Error())
+		return false
+	}
+	for _, reg := range regs {
+		wp.dispatch(reg, body)
+	}
+	return false
+}
+
+// dispatch 异步投递Webhook，插件关闭后不再投递
+func (wp *WebhookPlugin) dispatch(reg *WebhookRegistration, body []byte) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+	if wp.stopped {
+		return
+	}
+	wp.wg.Add(1)
+	go func() {
+		defer wp.wg.Done()
+		if err := wp.deliver(reg, body); err != nil {
+			logx.Error("Webhook投递失败：", reg.URL, " ", err.Error())
+		}
+	}()
+}
+
+// deliver 投递Webhook，失败时按指数回退重试，最多尝试3次
+func (wp *WebhookPlugin) deliver(reg *WebhookRegistration, body []byte) error {
+	var err error
+	for attempt := 1; attempt <= maxDeliveryAttempts; attempt++ {
+		if err = wp.post(reg, body); err == nil {
+			return nil
+		}
+		if attempt == maxDeliveryAttempts {
+			break
+		}
+		select {
+		case <-wp.stopChan:
+			return fmt.Errorf("插件已关闭，放弃重试：%w", err)
+		case <-time.After(retryBaseDelay << (attempt - 1)):
+		}
+	}
+	return err
+}
+
+// post 发送一次Webhook请求，2xx状态码视为成功
+func (wp *WebhookPlugin) post(reg *WebhookRegistration, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, reg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if reg.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(reg.Secret))
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := wp.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("状态码：%d", resp.StatusCode)
+	}
+	return nil
+}
+
+// registerHandler 处理添加Webhook注册的内网事件，返回注册ID
+func (wp *WebhookPlugin) registerHandler(ctx types.WorkerContext) error {
+	reg := WebhookRegistration{}
+	if err := jsonx.UnmarshalFromBytes(ctx.Body(), &reg); err != nil {
+		return ctx.SetStatus(http.StatusBadRequest).Response([]byte("Webhook注册参数解析失败：" + err.Error()))
+	}
+	id, err := wp.Register(&reg)
+	if err != nil {
+		return ctx.SetStatus(http.StatusInternalServerError).Response([]byte(err.Error()))
+	}
+	return ctx.SetStatus(http.StatusOK).Response([]byte(id))
+}
+
+// deleteHandler 处理删除Webhook注册的内网事件，请求体为注册ID
+func (wp *WebhookPlugin) deleteHandler(ctx types.WorkerContext) error {
+	if err := wp.Delete(string(ctx.Body())); err != nil {
+		return ctx.SetStatus(http.StatusInternalServerError).Response([]byte(err.Error()))
+	}
+	return ctx.SetStatus(http.StatusOK).Response([]byte(WEBHOOK_SUCCESS))
+}
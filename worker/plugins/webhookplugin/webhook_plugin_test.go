@@ -0,0 +1,176 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhookplugin
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/worker/types"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// fakeContext 仅提供事件信息的工作上下文
+type fakeContext struct {
+	types.WorkerContext
+	event       *core.Event
+	entityEvent *core.EntityEvent
+}
+
+func (c *fakeContext) Event() *core.Event             { return c.event }
+func (c *fakeContext) EntityEvent() *core.EntityEvent { return c.entityEvent }
+
+// recorder 记录收到的Webhook请求，前 failures 次返回500
+type recorder struct {
+	mu       sync.Mutex
+	failures int
+	attempts int
+	bodies   [][]byte
+	sigs     []string
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts++
+	if r.attempts <= r.failures {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	body, _ := io.ReadAll(req.Body)
+	r.bodies = append(r.bodies, body)
+	r.sigs = append(r.sigs, req.Header.Get(SignatureHeader))
+	w.WriteHeader(http.StatusOK)
+}
+
+func (r *recorder) snapshot() (int, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.attempts, len(r.bodies)
+}
+
+func newTestPlugin(t *testing.T) *WebhookPlugin {
+	origin := retryBaseDelay
+	retryBaseDelay = 10 * time.Millisecond
+	t.Cleanup(func() { retryBaseDelay = origin })
+
+	db, err := gorm.Open(sqlite.Open(t.TempDir()+"/webhooks.db"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open db: %v", err)
+	}
+	wp := NewWebhookPlugin(nil, "")
+	if err := wp.init(db); err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+	return wp
+}
+
+func newTestContext(logable bool) *fakeContext {
+	return &fakeContext{
+		event:       &core.Event{ID: "ev1", Project: "shop", Context: "order", Entity: "order", Event: "create", AccessToken: "secret-token"},
+		entityEvent: &core.EntityEvent{Logable: logable},
+	}
+}
+
+func TestFilterDeliversWithRetry(t *testing.T) {
+	rec := &recorder{failures: 2}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	wp := newTestPlugin(t)
+	if _, err := wp.Register(&WebhookRegistration{Project: "shop", Context: "order", Entity: "order", URL: server.URL, Secret: "s3"}); err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+	if stop := wp.Filter(newTestContext(true), jsonx.DefaultJson(constant.SUCCESS)); stop {
+		t.Fatal("filter should not stop the response")
+	}
+	wp.wg.Wait()
+
+	attempts, delivered := rec.snapshot()
+	if attempts != 3 || delivered != 1 {
+		t.Fatalf("unexpected attempts=%d delivered=%d", attempts, delivered)
+	}
+	payload := WebhookPayload{}
+	if err := jsonx.UnmarshalFromBytes(rec.bodies[0], &payload); err != nil || payload.Event != "create" || payload.Response == nil {
+		t.Fatalf("unexpected payload: %s, %v", rec.bodies[0], err)
+	}
+	mac := hmac.New(sha256.New, []byte("s3"))
+	mac.Write(rec.bodies[0])
+	if rec.sigs[0] != hex.EncodeToString(mac.Sum(nil)) {
+		t.Fatal("unexpected signature")
+	}
+	if strings.Contains(string(rec.bodies[0]), "secret-token") {
+		t.Fatal("payload should not contain access token")
+	}
+}
+
+func TestFilterGivesUpAfterMaxAttempts(t *testing.T) {
+	rec := &recorder{failures: 10}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	wp := newTestPlugin(t)
+	wp.Register(&WebhookRegistration{Project: "shop", Context: "order", Entity: "order", Event: "create", URL: server.URL})
+	wp.Filter(newTestContext(true), jsonx.DefaultJson(constant.SUCCESS))
+	wp.wg.Wait()
+
+	if attempts, delivered := rec.snapshot(); attempts != maxDeliveryAttempts || delivered != 0 {
+		t.Fatalf("unexpected attempts=%d delivered=%d", attempts, delivered)
+	}
+}
+
+func TestFilterSkipsUnmatchedEvents(t *testing.T) {
+	rec := &recorder{}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	wp := newTestPlugin(t)
+	id, _ := wp.Register(&WebhookRegistration{Project: "shop", Context: "order", Entity: "order", Event: "cancel", URL: server.URL})
+	wp.Register(&WebhookRegistration{Project: "shop", Context: "order", Entity: "order", URL: server.URL})
+
+	// 未开启日志、执行失败的事件均不投递
+	wp.Filter(newTestContext(false), jsonx.DefaultJson(constant.SUCCESS))
+	wp.Filter(newTestContext(true), jsonx.DefaultJson(constant.FAIL_TO_PROCESS))
+	wp.Filter(newTestContext(true), nil)
+
+	if err := wp.Delete(id); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if err := wp.Delete(id); err == nil {
+		t.Fatal("expected error deleting unknown registration")
+	}
+	// 仅匹配实体下所有事件的注册
+	wp.Filter(newTestContext(true), jsonx.DefaultJson(constant.SUCCESS))
+	wp.Shutdown()
+
+	if attempts, delivered := rec.snapshot(); attempts != 1 || delivered != 1 {
+		t.Fatalf("unexpected attempts=%d delivered=%d", attempts, delivered)
+	}
+	if h := wp.HealthCheck(); h.Details["registrations"] != 1 {
+		t.Fatalf("unexpected health: %+v", h)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hertzimpl
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+// SWAGGER_SPEC_PATH 开发模式下导出 OpenAPI 文档的路径，可通过 worker 参数指定工作者ID
+const SWAGGER_SPEC_PATH = "/debug/swagger.json"
+
+// ENTITY_ATTRS_PATH 开发模式下查看实体属性的路径，通过 project、version、context、entity 参数指定实体
+const ENTITY_ATTRS_PATH = "/debug/entity_attrs"
+
+// CACHE_EXPIRES_HEADER 返回元数据在领域缓存中过期时间的响应头，HTTP 日期格式
+const CACHE_EXPIRES_HEADER = "X-Cache-Expires"
+
+// entityAttrsExpiryReader 能返回实体属性缓存过期时间的领域缓存
+type entityAttrsExpiryReader interface {
+	EntityAttrsWithExpiry(e types.PathToEntity) ([]core.EntityAttribute, time.Time)
+}
+
+func swaggerSpec(s *WorkerPublicServer) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		spec, err := s.ws.ExportSwaggerSpec(c.Query("worker"))
+		if err != nil {
+			logx.Error("导出接口文档失败: " + err.Error())
+			c.String(consts.StatusNotFound, err.Error())
+			return
+		}
+		c.Data(consts.StatusOK, "application/json; charset=utf-8", spec)
+	}
+}
+
+// entityAttrs 返回实体属性，领域缓存支持时通过 X-Cache-Expires 响应头返回缓存的过期时间
+func entityAttrs(s *WorkerPublicServer) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		p := types.PathToEntity{
+			Project: c.Query("project"),
+			Version: c.Query("version"),
+			Context: c.Query("context"),
+			Entity:  c.Query("entity"),
+		}
+		if p.IsIncomplete() {
+			c.String(consts.StatusBadRequest, "缺少参数 project、version、context、entity")
+			return
+		}
+		var attrs []core.EntityAttribute
+		if reader, ok := s.ws.DomainCache().(entityAttrsExpiryReader); ok {
+			var expiry time.Time
+			attrs, expiry = reader.EntityAttrsWithExpiry(p)
+			if !expiry.IsZero() {
+				c.Header(CACHE_EXPIRES_HEADER, expiry.UTC().Format(http.TimeFormat))
+			}
+		} else {
+			attrs = s.ws.DomainCache().EntityAttrs(p)
+		}
+		c.JSON(consts.StatusOK, attrs)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hertzimpl
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+)
+
+// DEFAULT_HEALTH_PATH 默认的健康检查路径
+const DEFAULT_HEALTH_PATH = "/healthz"
+
+// HEALTH_STATUS_OK 健康检查通过时的状态
+const HEALTH_STATUS_OK = "ok"
+
+// HEALTH_STATUS_UNAVAILABLE 健康检查未通过时的状态
+const HEALTH_STATUS_UNAVAILABLE = "unavailable"
+
+// HealthReport 健康检查结果
+type HealthReport struct {
+	Status        string   `json:"status"`                   // 状态，ok 或 unavailable
+	Workers       int      `json:"workers"`                  // 已注册成功的工作者数
+	Version       string   `json:"version"`                  // 服务器版本号
+	Reason        string   `json:"reason,omitempty"`         // 不健康的原因
+	FailedWorkers []string `json:"failed_workers,omitempty"` // 注册失败的工作者ID
+}
+
+// Healthy 是否健康
+func (r *HealthReport) Healthy() bool {
+	return r.Status == HEALTH_STATUS_OK
+}
+
+// RegisterHealthEndpoint 在公网服务上注册健康检查路径，path 为空时使用 /healthz，
+// 健康时返回 200，否则返回 503，该路径不经过事件的拦截器和过滤器，需在服务启动前调用
+func (s *WorkerPublicServer) RegisterHealthEndpoint(path string, check func() *HealthReport) error {
+	hertzSvr, ok := s.Impl().(*server.Hertz)
+	if !ok || hertzSvr == nil {
+		return errors.New("hertz is not initialized")
+	}
+	if check == nil {
+		return errors.New("health check is nil")
+	}
+	if path == "" {
+		path = DEFAULT_HEALTH_PATH
+	}
+	hertzSvr.GET(path, func(ctx context.Context, c *app.RequestContext) {
+		report := check()
+		status := consts.StatusOK
+		if report == nil || !report.Healthy() {
+			status = consts.StatusServiceUnavailable
+		}
+		c.JSON(status, report)
+	})
+	return nil
+}
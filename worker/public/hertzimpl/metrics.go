@@ -0,0 +1,113 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hertzimpl
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/adaptor"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DEFAULT_METRICS_PATH 默认的 Prometheus 指标路径
+const DEFAULT_METRICS_PATH = "/metrics"
+
+// METRICS_NAMESPACE 指标名前缀
+const METRICS_NAMESPACE = "event_matrix_worker"
+
+// publicMetrics 公网服务的内置指标，服务创建时即开始采集，注册指标路径后才对外暴露
+type publicMetrics struct {
+	registry *prometheus.Registry
+	requests *prometheus.CounterVec   // 请求数，按方法和状态码区分
+	latency  *prometheus.HistogramVec // 请求耗时
+	inFlight prometheus.Gauge         // 处理中的请求数
+}
+
+func newPublicMetrics(serverId string) *publicMetrics {
+	labels := prometheus.Labels{"server_id": serverId}
+	m := &publicMetrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   METRICS_NAMESPACE,
+			Name:        "http_requests_total",
+			Help:        "公网请求总数",
+			ConstLabels: labels,
+		}, []string{"method", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   METRICS_NAMESPACE,
+			Name:        "http_request_duration_seconds",
+			Help:        "公网请求耗时",
+			ConstLabels: labels,
+			Buckets:     prometheus.DefBuckets,
+		}, []string{"method"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   METRICS_NAMESPACE,
+			Name:        "http_requests_in_flight",
+			Help:        "处理中的公网请求数",
+			ConstLabels: labels,
+		}),
+	}
+	m.registry.MustRegister(m.requests, m.latency, m.inFlight)
+	return m
+}
+
+// middleware 统计请求数、耗时和处理中的请求数
+func (m *publicMetrics) middleware() app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		start := time.Now()
+		m.inFlight.Inc()
+		c.Next(ctx)
+		m.inFlight.Dec()
+		method := string(c.Method())
+		m.requests.WithLabelValues(method, strconv.Itoa(c.Response.StatusCode())).Inc()
+		m.latency.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	}
+}
+
+// RegisterMetricsEndpoint 在公网服务上注册 Prometheus 指标路径，path 为空时使用 /metrics，
+// collectors 为额外需要暴露的指标，需在服务启动前调用
+func (s *WorkerPublicServer) RegisterMetricsEndpoint(path string, collectors ...prometheus.Collector) error {
+	hertzSvr, ok := s.Impl().(*server.Hertz)
+	if !ok || hertzSvr == nil {
+		return errors.New("hertz is not initialized")
+	}
+	if path == "" {
+		path = DEFAULT_METRICS_PATH
+	}
+	for _, c := range collectors {
+		if err := s.metrics.registry.Register(c); err != nil {
+			return err
+		}
+	}
+	handler := promhttp.HandlerFor(s.metrics.registry, promhttp.HandlerOpts{})
+	hertzSvr.GET(path, func(ctx context.Context, c *app.RequestContext) {
+		req, err := adaptor.GetCompatRequest(&c.Request)
+		if err != nil {
+			logx.Error("Failed to convert metrics request: " + err.Error())
+			c.AbortWithStatus(consts.StatusInternalServerError)
+			return
+		}
+		handler.ServeHTTP(adaptor.GetCompatResponseWriter(&c.Response), req)
+	})
+	return nil
+}
@@ -16,7 +16,10 @@ package hertzimpl
 
 import (
 	"context"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/protocol/consts"
@@ -24,12 +27,32 @@ import (
 	"github.com/garrickvan/event-matrix/serverx"
 	"github.com/garrickvan/event-matrix/worker/common"
 	"github.com/garrickvan/event-matrix/worker/types"
+	"github.com/spf13/cast"
 )
 
+// eventRateLimiter 支持按事件限流的工作服务器
+type eventRateLimiter interface {
+	AllowEvent(eventLabel string, ip string) (bool, time.Duration)
+}
+
+// SQL_PREVIEW_HEADER 请求SQL预览的请求头，值为true时SQL执行器只返回生成的SQL而不执行，也可使用 dry_run 查询参数
+const SQL_PREVIEW_HEADER = "X-Dry-Run"
+
+// EXPLAIN_HEADER 请求查询计划的请求头，值为true且配置开启 AllowExplain 时，查询执行器在响应中附带查询计划
+const EXPLAIN_HEADER = "X-Explain"
+
 // 构建适配框架的上下文
 func postEntrance(impl *WorkerPublicServer) app.HandlerFunc {
 	return func(ctx context.Context, c *app.RequestContext) {
 		reqCtx := NewWorkerPublicRequestContext(c, impl.ws)
+		// 生产模式下不开放SQL预览，避免泄露SQL定义
+		if !constant.IsProdMode(impl.cfg.Mode) {
+			reqCtx.sqlPreview = isSqlPreviewRequest(reqCtx)
+		}
+		// 查询计划需显式开启，避免误在生产环境暴露表结构和索引信息
+		if impl.cfg.AllowExplain {
+			reqCtx.explain = cast.ToBool(reqCtx.Header(EXPLAIN_HEADER))
+		}
 		err := route(reqCtx, impl.GetUnHandler())
 		if err != nil {
 			c.String(consts.StatusInternalServerError, "Internal Server Error: "+err.Error())
@@ -50,6 +73,16 @@ func route(ctx *WorkerPublicRequestContext, unHandle serverx.HandleFunc) error {
 	if status != constant.SUCCESS {
 		return ctx.SetStatus(http.StatusUnauthorized).ResponseBuiltinJson(status)
 	}
+	common.ResolveAliasEvent(ctx.Server(), event)
+
+	// 事件限流
+	eventUrl := event.GetUniqueLabel()
+	if rl, ok := ctx.Server().(eventRateLimiter); ok {
+		if allowed, wait := rl.AllowEvent(eventUrl, ctx.IP()); !allowed {
+			ctx.SetHeader("Retry-After", strconv.Itoa(int(math.Max(1, math.Ceil(wait.Seconds())))))
+			return ctx.SetStatus(http.StatusTooManyRequests).ResponseBuiltinJson(constant.TOO_MANY_REQUESTS)
+		}
+	}
 
 	// 获取实体事件
 	entityEvent := ctx.Server().DomainCache().EntityEvent(types.PathToEventFromEvent(event))
@@ -57,13 +90,17 @@ func route(ctx *WorkerPublicRequestContext, unHandle serverx.HandleFunc) error {
 		return ctx.SetStatus(http.StatusNotFound).ResponseBuiltinJson(constant.EVENT_NOT_EXIST)
 	}
 
+	// 参数超出大小上限
+	if entityEvent.ParamSizeExceeded(event.Params) {
+		return ctx.SetStatus(http.StatusRequestEntityTooLarge).ResponseBuiltinJson(constant.PAYLOAD_TOO_LARGE)
+	}
+
 	// 如果实体事件是任务执行器或是内部认证，直接禁止调用
 	if entityEvent.ExecutorType == constant.TASK_EXECUTOR || entityEvent.AuthType == constant.INTERNAL_AUTH {
 		return ctx.SetStatus(http.StatusForbidden).ResponseBuiltinJson(constant.FORBIDDEN_CALL)
 	}
 
-	// 获取事件URL，根据URL获取对应的执行器
-	eventUrl := event.GetUniqueLabel()
+	// 根据事件URL获取对应的执行器
 	if funz, found := ctx.Server().FindWorkerExecutor(eventUrl); found && funz != nil {
 		// 获取用户ID
 		userId, status := common.GetUserId(ctx, event, entityEvent.AuthType == constant.USER_AUTH, false)
@@ -79,6 +116,14 @@ func route(ctx *WorkerPublicRequestContext, unHandle serverx.HandleFunc) error {
 	return unHandle(ctx)
 }
 
+// isSqlPreviewRequest 判断请求是否通过请求头或查询参数要求SQL预览
+func isSqlPreviewRequest(ctx *WorkerPublicRequestContext) bool {
+	if v := ctx.Header(SQL_PREVIEW_HEADER); v != "" {
+		return cast.ToBool(v)
+	}
+	return cast.ToBool(ctx.Query("dry_run"))
+}
+
 func unHandle(s *WorkerPublicServer) app.HandlerFunc {
 	return func(ctx context.Context, c *app.RequestContext) {
 		reqCtx := NewWorkerPublicRequestContext(c, s.ws)
@@ -15,9 +15,12 @@
 package hertzimpl
 
 import (
+	"context"
+
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/garrickvan/event-matrix/core"
 	"github.com/garrickvan/event-matrix/serverx/hertzx"
+	"github.com/garrickvan/event-matrix/utils/i18n"
 	"github.com/garrickvan/event-matrix/utils/jsonx"
 	"github.com/garrickvan/event-matrix/worker/common"
 	"github.com/garrickvan/event-matrix/worker/types"
@@ -32,6 +35,9 @@ type WorkerPublicRequestContext struct {
 	attrs       []core.EntityAttribute // 实体属性列表
 	params      map[string]interface{} // 请求参数
 	eventParams []core.EventParam      // 事件参数列表
+	traceCtx    context.Context        // 链路追踪上下文，首次使用时从请求头提取
+	sqlPreview  bool                   // 是否为SQL预览请求，SQL执行器只返回生成的SQL而不执行
+	explain     bool                   // 是否需要返回查询计划
 }
 
 // NewWorkerPublicRequestContext 创建并返回一个新的 WorkerPublicRequestContext 实例
@@ -48,6 +54,11 @@ func (c *WorkerPublicRequestContext) UserId() string {
 	return c.uid
 }
 
+// Locale 返回请求头 Accept-Language 指定的语言
+func (c *WorkerPublicRequestContext) Locale() string {
+	return i18n.NormalizeLocale(c.Header("Accept-Language"))
+}
+
 // ValidatedParams 解析并验证请求参数，返回实体属性列表、事件参数列表、请求参数、解析结果
 func (c *WorkerPublicRequestContext) ValidatedParams() (
 	entityAttrs []core.EntityAttribute,
@@ -63,7 +74,32 @@ func (c *WorkerPublicRequestContext) ValidatedParams() (
 	return c.attrs, c.eventParams, c.params, result
 }
 
+// SqlPreview 返回当前请求是否为SQL预览请求
+func (c *WorkerPublicRequestContext) SqlPreview() bool {
+	return c.sqlPreview
+}
+
+// Explain 返回当前请求是否需要返回查询计划
+func (c *WorkerPublicRequestContext) Explain() bool {
+	return c.explain
+}
+
 // Server 返回关联的Worker服务器实例
 func (c *WorkerPublicRequestContext) Server() types.WorkerServer {
 	return c.ws
 }
+
+// TraceContext 返回当前的链路追踪上下文，首次使用时从 traceparent、baggage 请求头提取，
+// 存在未结束的 Span 时以该 Span 为当前 Span
+func (c *WorkerPublicRequestContext) TraceContext() context.Context {
+	if c.traceCtx == nil {
+		c.traceCtx = types.ExtractTraceContext(c)
+	}
+	return c.traceCtx
+}
+
+// Span 创建链路追踪片段，使用完毕后须调用 End
+func (c *WorkerPublicRequestContext) Span(name string) types.Span {
+	c.TraceContext()
+	return types.StartSpan(&c.traceCtx, name)
+}
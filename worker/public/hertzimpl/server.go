@@ -34,17 +34,28 @@ import (
 type WorkerPublicServer struct {
 	*hertzx.PublicServer
 
-	cfg *types.WorkerServerConfig
-	ws  types.WorkerServer
+	cfg     *types.WorkerServerConfig
+	ws      types.WorkerServer
+	metrics *publicMetrics
 }
 
 func NewWorkerPublicServer(cfg *types.WorkerServerConfig, ws types.WorkerServer) *WorkerPublicServer {
 	hlog.SetLevel(hlog.LevelWarn)
 	wps := &WorkerPublicServer{
-		ws:  ws,
-		cfg: cfg,
+		ws:      ws,
+		cfg:     cfg,
+		metrics: newPublicMetrics(cfg.ServerId),
+	}
+	opts, http2, err := tlsServerOptions(cfg)
+	if err != nil {
+		panic("公网服务TLS配置无效: " + err.Error())
+	}
+	wps.PublicServer = hertzx.NewPublicServer(cfg.PublicPort, cfg.ServerId, opts...)
+	if http2 {
+		if hz, ok := wps.Impl().(*server.Hertz); ok && hz != nil {
+			hz.AddProtocol(HTTP2_PROTOCOL, http2Factory)
+		}
 	}
-	wps.PublicServer = hertzx.NewPublicServer(cfg.PublicPort, cfg.ServerId)
 	wps.setMiddleware()
 	return wps
 }
@@ -57,6 +68,8 @@ func (s *WorkerPublicServer) setMiddleware() {
 	} else {
 		hertzSvr = hz
 	}
+	// 请求指标
+	hertzSvr.Use(s.metrics.middleware())
 	// 开发模式日志
 	if s.cfg.Mode == constant.DEV {
 		hertzSvr.Use(debugMiddleware())
@@ -80,6 +93,19 @@ func (s *WorkerPublicServer) setMiddleware() {
 			switch string(ctx.Request.Method()) {
 			case consts.MethodPost:
 				postEntrance(s)(c, ctx)
+			case consts.MethodGet:
+				// 开发模式下提供接口文档和实体属性查看
+				if s.cfg.Mode == constant.DEV {
+					switch string(ctx.Request.URI().Path()) {
+					case SWAGGER_SPEC_PATH:
+						swaggerSpec(s)(c, ctx)
+						return
+					case ENTITY_ATTRS_PATH:
+						entityAttrs(s)(c, ctx)
+						return
+					}
+				}
+				unHandle(s)(c, ctx)
 			default:
 				unHandle(s)(c, ctx)
 			}
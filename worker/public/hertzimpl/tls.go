@@ -0,0 +1,60 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hertzimpl
+
+import (
+	"crypto/tls"
+
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+// HTTP2_PROTOCOL ALPN 协商 HTTP/2 时使用的协议名
+const HTTP2_PROTOCOL = "h2"
+
+// http2Factory HTTP/2 协议的服务工厂
+var http2Factory interface{}
+
+// SetHTTP2ServerFactory 设置 HTTP/2 协议的服务工厂，如 hertz-contrib/http2 的 factory.NewServerFactory()，
+// 需在创建公网服务前调用。未设置时即使开启 EnableHTTP2 也只提供 HTTPS 上的 HTTP/1.1
+func SetHTTP2ServerFactory(factory interface{}) {
+	http2Factory = factory
+}
+
+// tlsServerOptions 按配置生成公网服务的TLS配置项，返回是否通过ALPN提供HTTP/2，
+// 未配置TLS时返回空配置，公网服务使用HTTP/1.1；配置了TLS但证书无效时返回错误，不回退到不加密的HTTP
+func tlsServerOptions(cfg *types.WorkerServerConfig) ([]config.Option, bool, error) {
+	cert, err := cfg.LoadPublicTLSCertificate()
+	if err != nil || cert == nil {
+		return nil, false, err
+	}
+	tlsCfg := &tls.Config{
+		Certificates: []tls.Certificate{*cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	opts := []config.Option{server.WithTLS(tlsCfg)}
+	if !cfg.EnableHTTP2 {
+		return opts, false, nil
+	}
+	if http2Factory == nil {
+		logx.Warn("未设置 HTTP/2 服务工厂，公网服务将使用 HTTPS 上的 HTTP/1.1")
+		return opts, false, nil
+	}
+	// Hertz 启动时会在 NextProtos 后追加 http/1.1，不支持 HTTP/2 的客户端自动回退
+	tlsCfg.NextProtos = []string{HTTP2_PROTOCOL}
+	return append(opts, server.WithALPN(true)), true, nil
+}
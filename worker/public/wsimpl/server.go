@@ -0,0 +1,206 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package wsimpl 提供支持 WebSocket 的公网服务，在 Hertz 公网服务的基础上增加 WebSocket 路由，
+// 用于向浏览器推送任务状态等实时事件
+package wsimpl
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/adaptor"
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/protocol/consts"
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/common"
+	"github.com/garrickvan/event-matrix/worker/public/hertzimpl"
+	"github.com/garrickvan/event-matrix/worker/types"
+	"github.com/gorilla/websocket"
+)
+
+// WebSocket 消息类型
+const (
+	TextMessage   = websocket.TextMessage
+	BinaryMessage = websocket.BinaryMessage
+)
+
+// WSConn WebSocket 连接，处理函数返回后连接自动关闭
+type WSConn interface {
+	// ReadMessage 读取一条消息，连接关闭时返回错误
+	ReadMessage() (messageType int, data []byte, err error)
+	// WriteMessage 发送一条消息
+	WriteMessage(messageType int, data []byte) error
+	// WriteJSON 以文本消息发送 JSON
+	WriteJSON(v interface{}) error
+	// Close 关闭连接
+	Close() error
+	// RemoteAddr 对端地址
+	RemoteAddr() net.Addr
+}
+
+// WSHandler WebSocket 连接处理函数，ctx 为握手请求的上下文
+type WSHandler func(conn WSConn, ctx types.WorkerContext)
+
+// WebSocketPublicServer 支持 WebSocket 的公网服务，普通事件请求仍由 Hertz 公网服务处理
+// 通过 TwoWayWorkerServerSettings.PublicServer 传入，工作服务器创建时自动绑定配置
+type WebSocketPublicServer struct {
+	*hertzimpl.WorkerPublicServer
+
+	ws       types.WorkerServer
+	upgrader websocket.Upgrader
+	mu       sync.Mutex
+	pending  map[string]WSHandler // 绑定前注册的处理函数
+}
+
+// NewWebSocketPublicServer 创建 WebSocket 公网服务，默认只允许同源的浏览器连接
+func NewWebSocketPublicServer() *WebSocketPublicServer {
+	s := &WebSocketPublicServer{pending: make(map[string]WSHandler)}
+	s.upgrader.CheckOrigin = sameOrigin
+	return s
+}
+
+// BindWorkerServer 绑定服务器配置和工作服务器，创建底层的 Hertz 公网服务，由工作服务器创建时调用
+func (s *WebSocketPublicServer) BindWorkerServer(cfg *types.WorkerServerConfig, ws types.WorkerServer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.WorkerPublicServer != nil {
+		logx.Warn("WebSocket public server has already been bound")
+		return
+	}
+	s.ws = ws
+	s.WorkerPublicServer = hertzimpl.NewWorkerPublicServer(cfg, ws)
+	for pattern, fn := range s.pending {
+		if err := s.register(pattern, fn); err != nil {
+			logx.Error("Failed to register websocket handler " + pattern + ": " + err.Error())
+		}
+	}
+	s.pending = nil
+}
+
+// SetCheckOrigin 设置握手时的来源校验，fn 为空时允许任意来源
+func (s *WebSocketPublicServer) SetCheckOrigin(fn func(r *http.Request) bool) {
+	if fn == nil {
+		fn = func(r *http.Request) bool { return true }
+	}
+	s.upgrader.CheckOrigin = fn
+}
+
+// RegisterWSHandler 注册 WebSocket 路由，升级连接前依次运行工作服务器的拦截器和过滤器，
+// 任一要求中止时不升级连接；未绑定工作服务器时，处理函数在绑定后注册
+func (s *WebSocketPublicServer) RegisterWSHandler(pattern string, fn func(conn WSConn, ctx types.WorkerContext)) error {
+	if pattern == "" || fn == nil {
+		return errors.New("websocket pattern and handler are required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.WorkerPublicServer == nil {
+		s.pending[pattern] = fn
+		return nil
+	}
+	return s.register(pattern, fn)
+}
+
+func (s *WebSocketPublicServer) register(pattern string, fn WSHandler) error {
+	hertzSvr, ok := s.Impl().(*server.Hertz)
+	if !ok || hertzSvr == nil {
+		return errors.New("hertz is not initialized")
+	}
+	hertzSvr.GET(pattern, s.wsEntrance(fn))
+	return nil
+}
+
+// wsEntrance 校验握手请求，运行拦截器和过滤器后接管连接并升级为 WebSocket
+func (s *WebSocketPublicServer) wsEntrance(fn WSHandler) app.HandlerFunc {
+	return func(c context.Context, ctx *app.RequestContext) {
+		req, err := adaptor.GetCompatRequest(&ctx.Request)
+		if err != nil || !websocket.IsWebSocketUpgrade(req) {
+			ctx.AbortWithStatus(consts.StatusBadRequest)
+			return
+		}
+		if !s.upgrader.CheckOrigin(req) {
+			ctx.AbortWithStatus(consts.StatusForbidden)
+			return
+		}
+		reqCtx := hertzimpl.NewWorkerPublicRequestContext(ctx, s.ws)
+		if stop := common.RunInterceptors(reqCtx); stop {
+			return
+		}
+		if stop := common.RunFilters(reqCtx, jsonx.DefaultJson(constant.SUCCESS)); stop {
+			return
+		}
+		// 握手响应由 websocket 库写入，跳过框架的响应
+		ctx.Response.HijackWriter(discardWriter{})
+		ctx.Hijack(func(conn network.Conn) {
+			wsConn, err := s.upgrader.Upgrade(newHijackedResponse(conn), req, nil)
+			if err != nil {
+				logx.Warn("Failed to upgrade websocket: " + err.Error())
+				return
+			}
+			defer wsConn.Close()
+			fn(wsConn, reqCtx)
+		})
+	}
+}
+
+// sameOrigin 没有 Origin 头或与 Host 相同时视为同源
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+// discardWriter 丢弃框架的响应输出
+type discardWriter struct{}
+
+func (discardWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (discardWriter) Flush() error                { return nil }
+func (discardWriter) Finalize() error             { return nil }
+
+// hijackedResponse 将被接管的连接适配为 http.Hijacker，供 websocket 库写入握手响应
+type hijackedResponse struct {
+	conn   network.Conn
+	header http.Header
+}
+
+func newHijackedResponse(conn network.Conn) *hijackedResponse {
+	return &hijackedResponse{conn: conn, header: http.Header{}}
+}
+
+func (r *hijackedResponse) Header() http.Header { return r.header }
+
+// Write 握手失败时 websocket 库会写入错误响应，此时连接随后关闭，直接丢弃
+func (r *hijackedResponse) Write(p []byte) (int, error) { return len(p), nil }
+
+func (r *hijackedResponse) WriteHeader(int) {}
+
+func (r *hijackedResponse) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return r.conn, bufio.NewReadWriter(bufio.NewReader(r.conn), bufio.NewWriter(r.conn)), nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wsimpl
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garrickvan/event-matrix/worker/types"
+	"github.com/gorilla/websocket"
+)
+
+func TestWebSocketPublicServer(t *testing.T) {
+	cfg := &types.WorkerServerConfig{ServerId: "ws-test", PublicPort: 18097}
+	ws := types.NewMockWorkerServer(cfg)
+	t.Cleanup(func() { ws.Stop() })
+	// 没有 token 的握手请求被拦截
+	ws.RegisterInterceptor(func(wc types.WorkerContext) bool {
+		if wc.Header("X-Token") != "ok" {
+			wc.SetStatus(http.StatusUnauthorized).ResponseString("unauthorized")
+			return true
+		}
+		return false
+	})
+
+	s := NewWebSocketPublicServer()
+	// 绑定前注册，绑定后生效
+	if err := s.RegisterWSHandler("/ws/echo", func(conn WSConn, ctx types.WorkerContext) {
+		for {
+			mt, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(mt, []byte(ctx.Header("X-Token")+":"+string(data)))
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	s.BindWorkerServer(cfg, ws)
+	go s.Start()
+	t.Cleanup(func() { s.Stop() })
+
+	url := "ws://127.0.0.1:18097/ws/echo"
+	var (
+		conn *websocket.Conn
+		resp *http.Response
+		err  error
+	)
+	for i := 0; i < 50; i++ {
+		_, resp, err = websocket.DefaultDialer.Dial(url, nil)
+		if resp != nil {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected handshake rejected by interceptor, got %v %v", resp, err)
+	}
+
+	conn, _, err = websocket.DefaultDialer.Dial(url, http.Header{"X-Token": []string{"ok"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "ok:hello" {
+		t.Fatalf("unexpected message %q", data)
+	}
+
+	// 非 WebSocket 请求
+	httpResp, err := http.Get("http://127.0.0.1:18097/ws/echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unexpected status %d", httpResp.StatusCode)
+	}
+}
+
+func TestSameOrigin(t *testing.T) {
+	cases := []struct {
+		origin string
+		want   bool
+	}{
+		{"", true},
+		{"http://example.com", true},
+		{"https://EXAMPLE.com", true},
+		{"http://evil.com", false},
+	}
+	for _, c := range cases {
+		r, _ := http.NewRequest("GET", "http://example.com/ws", strings.NewReader(""))
+		if c.origin != "" {
+			r.Header.Set("Origin", c.origin)
+		}
+		if got := sameOrigin(r); got != c.want {
+			t.Errorf("sameOrigin(%q) = %v, want %v", c.origin, got, c.want)
+		}
+	}
+}
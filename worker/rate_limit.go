@@ -0,0 +1,59 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"time"
+
+	"github.com/garrickvan/event-matrix/utils/limiter"
+)
+
+// eventRateLimit 事件的限流配置
+type eventRateLimit struct {
+	limiter limiter.RateLimiter
+	perIP   bool // 是否按调用方IP分别限流
+}
+
+// RegisterRateLimiter 为事件注册令牌桶限流，所有调用方共享同一个令牌桶
+// eventLabel 为事件的唯一标签，如 sys.user.avatar->update@0.1.0，重复注册时覆盖之前的配置
+// 超出限制的公网请求返回 429，并通过 Retry-After 头告知重试等待时间
+func (ws *TwoWayWorkerServer) RegisterRateLimiter(eventLabel string, rps int, burst int) {
+	ws.rateLimits.Store(eventLabel, &eventRateLimit{
+		limiter: limiter.NewTokenBucketLimiter(rps, burst),
+	})
+}
+
+// RegisterIPRateLimiter 为事件注册按调用方IP区分的令牌桶限流，每个IP使用独立的令牌桶
+func (ws *TwoWayWorkerServer) RegisterIPRateLimiter(eventLabel string, rps int, burst int) {
+	ws.rateLimits.Store(eventLabel, &eventRateLimit{
+		limiter: limiter.NewTokenBucketLimiter(rps, burst),
+		perIP:   true,
+	})
+}
+
+// AllowEvent 检查事件请求是否在限流范围内，未注册限流的事件总是允许
+// 返回: 是否允许通过，以及被拒绝时的重试等待时间
+func (ws *TwoWayWorkerServer) AllowEvent(eventLabel string, ip string) (bool, time.Duration) {
+	v, ok := ws.rateLimits.Load(eventLabel)
+	if !ok {
+		return true, 0
+	}
+	rl := v.(*eventRateLimit)
+	key := eventLabel
+	if rl.perIP {
+		key = eventLabel + ":" + ip
+	}
+	return rl.limiter.Allow(key)
+}
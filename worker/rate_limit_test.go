@@ -0,0 +1,65 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/garrickvan/event-matrix/core"
+)
+
+func TestAllowEvent(t *testing.T) {
+	ws := &TwoWayWorkerServer{}
+	if ok, _ := ws.AllowEvent("shop.user->query@1.0.0", "1.1.1.1"); !ok {
+		t.Fatal("event without limiter should be allowed")
+	}
+
+	ws.RegisterIPRateLimiter("shop.user->query@1.0.0", 1, 1)
+	if ok, _ := ws.AllowEvent("shop.user->query@1.0.0", "1.1.1.1"); !ok {
+		t.Fatal("first request should be allowed")
+	}
+	if ok, wait := ws.AllowEvent("shop.user->query@1.0.0", "1.1.1.1"); ok || wait <= 0 {
+		t.Fatalf("second request from same ip should be limited, got %v %v", ok, wait)
+	}
+	if ok, _ := ws.AllowEvent("shop.user->query@1.0.0", "2.2.2.2"); !ok {
+		t.Fatal("request from other ip should be allowed")
+	}
+
+	ws.RegisterRateLimiter("shop.user->query@1.0.0", 1, 1)
+	ws.AllowEvent("shop.user->query@1.0.0", "1.1.1.1")
+	if ok, _ := ws.AllowEvent("shop.user->query@1.0.0", "2.2.2.2"); ok {
+		t.Fatal("shared limiter should apply to all callers")
+	}
+}
+
+func TestPublicRateLimit(t *testing.T) {
+	ws, h := newMetricsTestServer(nil)
+	event := &core.Event{Project: "shop", Version: "1.0.0", Context: "v1", Entity: "user", Event: "query", Params: "{}"}
+	event.GenerateSign()
+	ws.RegisterRateLimiter(event.GetUniqueLabel(), 1, 1)
+	// 耗尽令牌
+	ws.AllowEvent(event.GetUniqueLabel(), "")
+
+	body := []byte(event.Raw())
+	resp := ut.PerformRequest(h.Engine, "POST", "/", &ut.Body{Body: bytes.NewReader(body), Len: len(body)}).Result()
+	if resp.StatusCode() != 429 {
+		t.Fatalf("unexpected status %d", resp.StatusCode())
+	}
+	if resp.Header.Get("Retry-After") != "1" {
+		t.Fatalf("unexpected Retry-After %q", resp.Header.Get("Retry-After"))
+	}
+}
@@ -17,10 +17,13 @@ package repo
 import (
 	"errors"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/garrickvan/event-matrix/core"
 	"github.com/garrickvan/event-matrix/database"
+	"github.com/garrickvan/event-matrix/utils"
 	"github.com/garrickvan/event-matrix/utils/jsonx"
 	"github.com/garrickvan/event-matrix/utils/logx"
 	"github.com/garrickvan/event-matrix/worker/types"
@@ -31,6 +34,9 @@ type RepositoryImpl struct {
 	database.DBManager
 	customFields map[string]types.CustomFieldParser
 	ws           types.WorkerServer
+	// structTypeCache 缓存自动迁移时动态生成的表结构体类型，避免重复注册时反复调用reflect.StructOf
+	// key为 实体版本路径 + 迁移字段的哈希，value为 reflect.Type
+	structTypeCache sync.Map
 }
 
 func NewRepository(ws types.WorkerServer) *RepositoryImpl {
@@ -111,6 +117,23 @@ func (rp *RepositoryImpl) SyncSchema(w *types.Worker) error {
 	return nil
 }
 
+func (rp *RepositoryImpl) MigrateData(src, dst types.PathToEntity, transform database.MigrateTransform, deleteSrc bool) error {
+	if src.Project == "" || src.Context == "" || src.Entity == "" || dst.Project == "" || dst.Context == "" || dst.Entity == "" {
+		return errors.New("迁移数据的源实体和目标实体路径不完整")
+	}
+	for _, dbName := range []string{src.Project, dst.Project} {
+		if !rp.HasDB(dbName) {
+			return errors.New("目标数据库: " + dbName + " 没有配置，请检查配置文件")
+		}
+	}
+	migrated, err := database.MigrateTableData(rp.Use(src.Project), src.TableName(), rp.Use(dst.Project), dst.TableName(), transform, deleteSrc)
+	if err != nil {
+		return err
+	}
+	logx.Info("迁移数据完成: " + src.Project + "." + src.TableName() + " -> " + dst.Project + "." + dst.TableName() + ", 共 " + cast.ToString(migrated) + " 行")
+	return nil
+}
+
 // 自动迁移表结构
 // 1. 遍历实体属性，构建表结构体
 // 2. 利用反射机制构建表结构体
@@ -131,38 +154,72 @@ func (rp *RepositoryImpl) autoMigrateTable(w *types.Worker, entityAttrs []core.E
 		return
 	}
 	table := db.Table(tableName)
-	// 利用反射机制构建表的结构体
-	tableStructs := []reflect.StructField{}
-	for i, v := range entityAttrs {
+	// 筛选需要迁移的字段
+	migrateAttrs := make([]core.EntityAttribute, 0, len(entityAttrs))
+	for _, v := range entityAttrs {
 		// 跳过已存在数据库中的自定义字段
 		exist := database.CheckFieldExists(table, tableName, v.Code)
 		if exist {
 			logx.Info("字段:【" + v.Code + "】已存在于数据库，跳过自动创建")
 			continue
 		}
-		structField := rp.getStrutFieldForAutoMigrate(v)
-		if structField == nil {
-			continue
-		}
-		structField.Name = "F_" + cast.ToString(i) // 临时命名，避免冲突，tag会覆盖
-		tableStructs = append(tableStructs, *structField)
+		migrateAttrs = append(migrateAttrs, v)
 	}
-	class := reflect.StructOf(tableStructs)
+	class := rp.tableStructType(w.GetVersionEntityLabel(), migrateAttrs)
 	tableClass := reflect.New(class).Interface()
 	err := table.AutoMigrate(tableClass)
 	if err != nil {
 		logx.Log().Error("自动迁移表错误: " + err.Error())
 	} else {
 		// 自定义字段处理
-		for _, v := range entityAttrs {
-			if v.FieldType == "custom" {
-				rp.handleCustomField(table, tableName, v)
-			}
+		for _, v := range core.FindAttrsByFieldType(string(core.CUSTOM_FIELD_TYPE), entityAttrs) {
+			rp.handleCustomField(table, tableName, v)
 		}
 		logx.Debug("自动迁移表成功: " + w.Project + "." + tableName)
 	}
 }
 
+// tableStructType 获取迁移字段对应的表结构体类型，命中缓存时跳过结构体构建
+func (rp *RepositoryImpl) tableStructType(entityLabel string, attrs []core.EntityAttribute) reflect.Type {
+	key := entityLabel + "#" + attrsHash(attrs)
+	if class, ok := rp.structTypeCache.Load(key); ok {
+		return class.(reflect.Type)
+	}
+	// 利用反射机制构建表的结构体
+	tableStructs := []reflect.StructField{}
+	for i, v := range attrs {
+		structField := rp.getStrutFieldForAutoMigrate(v)
+		if structField == nil {
+			continue
+		}
+		structField.Name = "F_" + cast.ToString(i) // 临时命名，避免冲突，tag会覆盖
+		tableStructs = append(tableStructs, *structField)
+	}
+	class := reflect.StructOf(tableStructs)
+	actual, _ := rp.structTypeCache.LoadOrStore(key, class)
+	return actual.(reflect.Type)
+}
+
+// attrsHash 计算影响表结构的属性摘要
+func attrsHash(attrs []core.EntityAttribute) string {
+	var sb strings.Builder
+	for _, v := range attrs {
+		sb.WriteString(v.Code)
+		sb.WriteByte('|')
+		sb.WriteString(v.FieldType)
+		sb.WriteByte('|')
+		sb.WriteString(cast.ToString(v.Unique))
+		sb.WriteByte('|')
+		sb.WriteString(cast.ToString(v.Indexed))
+		sb.WriteByte('|')
+		sb.WriteString(cast.ToString(v.MaxLength))
+		sb.WriteByte('|')
+		sb.WriteString(v.DefaultValue)
+		sb.WriteByte(';')
+	}
+	return utils.GetSha1FromStr(sb.String())
+}
+
 /*
 **
 
@@ -171,6 +228,7 @@ func (rp *RepositoryImpl) autoMigrateTable(w *types.Worker, entityAttrs []core.E
 	text: "文本",
 	ref: "实体引用",
 	int8: "短整数",
+	int16: "小整数",
 	int32: "整数",
 	int64: "长整数",
 	float32: "浮点数",
@@ -197,6 +255,8 @@ func (rp *RepositoryImpl) getStrutFieldForAutoMigrate(attr core.EntityAttribute)
 		f.Type = reflect.TypeOf("")
 	case "int8":
 		f.Type = reflect.TypeOf(int8(0))
+	case "int16":
+		f.Type = reflect.TypeOf(int16(0))
 	case "int32":
 		f.Type = reflect.TypeOf(int32(0))
 	case "int64":
@@ -230,9 +290,29 @@ func (rp *RepositoryImpl) getStrutFieldForAutoMigrate(attr core.EntityAttribute)
 		if entityAttr.Indexed {
 			tagParts = append(tagParts, "index")
 		}
-		return "gorm:\"" + strings.Join(tagParts, ";") + "\""
+		// 长度限制转换为 varchar(n)，文本字段不限制列长度
+		if entityAttr.MaxLength > 0 && f.Type.Kind() == reflect.String && entityAttr.FieldType != string(core.TEXT_FIELD_TYPE) {
+			tagParts = append(tagParts, "type:varchar("+cast.ToString(entityAttr.MaxLength)+")")
+		}
+		if def, ok := columnDefault(*entityAttr); ok {
+			tagParts = append(tagParts, "default:"+strings.ReplaceAll(cast.ToString(def), ";", "\\;"))
+		}
+		return "gorm:" + strconv.Quote(strings.Join(tagParts, ";"))
 	}
 
 	f.Tag = reflect.StructTag(getTag(&attr))
 	return &f
 }
+
+// columnDefault 返回迁移时写入列定义的默认值，默认值为当前时间、主键或自定义字段时不设置数据库默认值
+func columnDefault(attr core.EntityAttribute) (interface{}, bool) {
+	if attr.DefaultValue == "" || attr.DefaultValue == core.DEFAULT_VALUE_NOW ||
+		attr.FieldType == string(core.ID_FIELD_TYPE) || attr.FieldType == string(core.CUSTOM_FIELD_TYPE) {
+		return nil, false
+	}
+	def, err := attr.FixValue(attr.GetDefaultVal())
+	if err != nil || def == nil {
+		return nil, false
+	}
+	return def, true
+}
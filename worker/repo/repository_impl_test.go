@@ -0,0 +1,247 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/database"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/worker/types"
+	"github.com/spf13/cast"
+	"gorm.io/gorm"
+)
+
+var benchAttrs = []core.EntityAttribute{
+	{Code: "id", FieldType: "id"},
+	{Code: "name", FieldType: "string", Indexed: true},
+	{Code: "email", FieldType: "email", Unique: true},
+	{Code: "age", FieldType: "int32"},
+	{Code: "score", FieldType: "float64"},
+	{Code: "enabled", FieldType: "boolean"},
+	{Code: "created_at", FieldType: "datetime"},
+	{Code: "deleted_at", FieldType: "datetime"},
+}
+
+func TestTableStructTypeCache(t *testing.T) {
+	rp := NewRepository(nil)
+	w := types.Worker{Project: "p", Context: "c", Entity: "e", VersionLabel: "1.0.0"}
+
+	first := rp.tableStructType(w.GetVersionEntityLabel(), benchAttrs)
+	second := rp.tableStructType(w.GetVersionEntityLabel(), benchAttrs)
+	if first != second {
+		t.Fatal("expected cached struct type on repeated migration")
+	}
+	if first.NumField() != len(benchAttrs) {
+		t.Fatalf("expected %d fields, got %d", len(benchAttrs), first.NumField())
+	}
+
+	// 字段变化时应生成新的结构体类型
+	changed := rp.tableStructType(w.GetVersionEntityLabel(), benchAttrs[:3])
+	if changed == first || changed.NumField() != 3 {
+		t.Fatalf("expected new struct type for changed attrs, got %v", changed)
+	}
+}
+
+func BenchmarkTableStructTypeCached(b *testing.B) {
+	rp := NewRepository(nil)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		rp.tableStructType("p.c.e@1.0.0", benchAttrs)
+	}
+}
+
+func BenchmarkTableStructTypeUncached(b *testing.B) {
+	rp := NewRepository(nil)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		fields := []reflect.StructField{}
+		for j, v := range benchAttrs {
+			f := rp.getStrutFieldForAutoMigrate(v)
+			if f == nil {
+				continue
+			}
+			f.Name = "F_" + cast.ToString(j)
+			fields = append(fields, *f)
+		}
+		reflect.StructOf(fields)
+	}
+}
+
+func TestStructFieldMaxLength(t *testing.T) {
+	rp := NewRepository(nil)
+	cases := []struct {
+		attr core.EntityAttribute
+		tag  string
+	}{
+		{core.EntityAttribute{Code: "name", FieldType: "string", MaxLength: 32}, `gorm:"column:name;type:varchar(32)"`},
+		{core.EntityAttribute{Code: "email", FieldType: "email", Unique: true, MaxLength: 128}, `gorm:"column:email;unique;type:varchar(128)"`},
+		{core.EntityAttribute{Code: "intro", FieldType: "text", MaxLength: 2000}, `gorm:"column:intro"`},
+		{core.EntityAttribute{Code: "age", FieldType: "int32", MaxLength: 3}, `gorm:"column:age"`},
+		{core.EntityAttribute{Code: "nick", FieldType: "string"}, `gorm:"column:nick"`},
+	}
+	for _, c := range cases {
+		if f := rp.getStrutFieldForAutoMigrate(c.attr); f == nil || string(f.Tag) != c.tag {
+			t.Errorf("%s: expected tag %s, got %v", c.attr.Code, c.tag, f)
+		}
+	}
+	// 长度变化时应生成新的结构体类型
+	short := rp.tableStructType("p.c.e@1.0.0", []core.EntityAttribute{{Code: "name", FieldType: "string", MaxLength: 16}})
+	long := rp.tableStructType("p.c.e@1.0.0", []core.EntityAttribute{{Code: "name", FieldType: "string", MaxLength: 64}})
+	if short == long {
+		t.Fatal("expected new struct type when max length changes")
+	}
+}
+
+func TestAutoMigrateInt16(t *testing.T) {
+	rp := NewRepository(nil)
+	err := rp.RegisterDB(&database.DBConf{Type: database.SQLITE, Location: t.TempDir(), DBName: "p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := rp.getStrutFieldForAutoMigrate(core.EntityAttribute{Code: "level", FieldType: "int16", Indexed: true}); f == nil ||
+		f.Type.Kind() != reflect.Int16 || string(f.Tag) != `gorm:"column:level;index"` {
+		t.Fatalf("unexpected struct field for int16: %v", f)
+	}
+
+	w := &types.Worker{Project: "p", Context: "c", Entity: "e", VersionLabel: "1.0.0"}
+	rp.autoMigrateTable(w, []core.EntityAttribute{
+		{Code: "id", FieldType: "id"},
+		{Code: "level", FieldType: "int16"},
+	})
+	if !rp.Use("p").Migrator().HasColumn(w.GetTabelName(), "level") {
+		t.Fatal("expected int16 column to be created")
+	}
+}
+
+func TestMigrateData(t *testing.T) {
+	rp := NewRepository(nil)
+	for _, name := range []string{"shop", "shop_v2"} {
+		if err := rp.RegisterDB(&database.DBConf{Type: database.SQLITE, Location: t.TempDir(), DBName: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	src := types.PathToEntity{Project: "shop", Version: "1.0.0", Context: "order", Entity: "item"}
+	dst := types.PathToEntity{Project: "shop_v2", Version: "2.0.0", Context: "order", Entity: "goods"}
+	rp.Use("shop").Exec("CREATE TABLE order_item (id TEXT PRIMARY KEY, price TEXT)")
+	rp.Use("shop_v2").Exec("CREATE TABLE order_goods (id TEXT PRIMARY KEY, price INTEGER)")
+	rp.Use("shop").Exec("INSERT INTO order_item (id, price) VALUES ('1', '12'), ('2', '30')")
+
+	err := rp.MigrateData(src, dst, func(row map[string]interface{}) map[string]interface{} {
+		row["price"] = cast.ToInt(row["price"])
+		return row
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total int
+	rp.Use("shop_v2").Raw("SELECT SUM(price) FROM order_goods").Scan(&total)
+	if total != 42 {
+		t.Errorf("expected migrated price sum 42, got %d", total)
+	}
+
+	if err := rp.MigrateData(src, types.PathToEntity{Project: "missing", Context: "order", Entity: "item"}, nil, false); err == nil {
+		t.Error("expected error for unknown target database")
+	}
+}
+
+// tagsFieldParser 以JSON数组存储标签的测试自定义字段，查询结果展示为逗号分隔的标签
+type tagsFieldParser struct{}
+
+func (tagsFieldParser) FieldParserName() string         { return "tags" }
+func (tagsFieldParser) Validate(interface{}) error      { return nil }
+func (tagsFieldParser) ParseParam(s string) interface{} { return s }
+func (tagsFieldParser) CreateColumn(db *gorm.DB, tableName, columnName string) error {
+	return db.Exec("ALTER TABLE " + tableName + " ADD COLUMN " + columnName + " TEXT").Error
+}
+func (tagsFieldParser) DefaultValue() interface{} { return nil }
+func (tagsFieldParser) Description() string       { return "标签" }
+func (tagsFieldParser) Format(value interface{}) string {
+	var tags []string
+	if err := jsonx.UnmarshalFromStr(cast.ToString(value), &tags); err != nil {
+		return cast.ToString(value)
+	}
+	return strings.Join(tags, ",")
+}
+
+func TestCustomFieldParserFormat(t *testing.T) {
+	rp := NewRepository(nil)
+	rp.RegisterCustomFieldParser(tagsFieldParser{})
+	parser, ok := rp.GetCustomFieldParser("tags")
+	if !ok {
+		t.Fatal("expected custom field parser to be registered")
+	}
+	if _, ok := rp.GetCustomFieldParser("unknown"); ok {
+		t.Fatal("unexpected parser for unknown field type")
+	}
+
+	if err := rp.RegisterDB(&database.DBConf{Type: database.SQLITE, Location: t.TempDir(), DBName: "shop"}); err != nil {
+		t.Fatal(err)
+	}
+	db := rp.Use("shop")
+	db.Exec("CREATE TABLE shop_goods (id TEXT PRIMARY KEY)")
+	if err := parser.CreateColumn(db, "shop_goods", "tags"); err != nil {
+		t.Fatal(err)
+	}
+	db.Exec(`INSERT INTO shop_goods (id, tags) VALUES ('1', '["new","hot"]'), ('2', 'plain')`)
+
+	var rows []map[string]interface{}
+	db.Table("shop_goods").Order("id").Find(&rows)
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+	if got := parser.Format(rows[0]["tags"]); got != "new,hot" {
+		t.Errorf("expected stored JSON to be formatted, got %q", got)
+	}
+	if got := parser.Format(rows[1]["tags"]); got != "plain" {
+		t.Errorf("expected non-JSON value to be returned as is, got %q", got)
+	}
+}
+
+func TestAutoMigrateDefaultValue(t *testing.T) {
+	rp := NewRepository(nil)
+	if f := rp.getStrutFieldForAutoMigrate(core.EntityAttribute{Code: "nick", FieldType: "string", DefaultValue: "a;b"}); f == nil ||
+		f.Tag.Get("gorm") != `column:nick;default:a\;b` {
+		t.Fatalf("unexpected struct field for default value: %v", f)
+	}
+	if f := rp.getStrutFieldForAutoMigrate(core.EntityAttribute{Code: "born_at", FieldType: "datetime", DefaultValue: core.DEFAULT_VALUE_NOW}); f == nil ||
+		f.Tag.Get("gorm") != "column:born_at" {
+		t.Fatalf("$now should not be a column default: %v", f)
+	}
+
+	err := rp.RegisterDB(&database.DBConf{Type: database.SQLITE, Location: t.TempDir(), DBName: "p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &types.Worker{Project: "p", Context: "c", Entity: "e", VersionLabel: "1.0.0"}
+	rp.autoMigrateTable(w, []core.EntityAttribute{
+		{Code: "id", FieldType: "id"},
+		{Code: "nick", FieldType: "string", DefaultValue: "it's"},
+		{Code: "level", FieldType: "int32", DefaultValue: "3"},
+		{Code: "active", FieldType: "boolean", DefaultValue: "true"},
+	})
+	db := rp.Use("p")
+	if err := db.Exec("INSERT INTO " + w.GetTabelName() + " (id) VALUES ('1')").Error; err != nil {
+		t.Fatal(err)
+	}
+	row := map[string]interface{}{}
+	db.Table(w.GetTabelName()).Where("id = ?", "1").Take(&row)
+	if row["nick"] != "it's" || cast.ToInt(row["level"]) != 3 || !cast.ToBool(row["active"]) {
+		t.Fatalf("column defaults not applied: %v", row)
+	}
+}
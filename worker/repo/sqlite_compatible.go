@@ -21,6 +21,7 @@ import (
 	"github.com/garrickvan/event-matrix/core"
 	"github.com/garrickvan/event-matrix/database"
 	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/spf13/cast"
 	"gorm.io/gorm"
 )
 
@@ -134,17 +135,35 @@ func (rp *RepositoryImpl) buildColumnDefinition(attr core.EntityAttribute) (stri
 	if attr.FieldType == "id" && attr.Code == idColumn {
 		constraints = append(constraints, "NOT NULL")
 	}
+	if def, ok := columnDefault(attr); ok {
+		constraints = append(constraints, "DEFAULT "+sqliteLiteral(def))
+	}
 
 	return attr.Code + " " + columnType + " " + strings.Join(constraints, " "), nil
 }
 
+// sqliteLiteral 将默认值转换为 SQLite 字面量，字符串加引号转义，布尔值转为 1/0
+func sqliteLiteral(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return "'" + strings.ReplaceAll(val, "'", "''") + "'"
+	case bool:
+		if val {
+			return "1"
+		}
+		return "0"
+	default:
+		return cast.ToString(val)
+	}
+}
+
 func mapFieldType(fieldType string) (string, error) {
 	switch fieldType {
 	case "id":
 		return "TEXT PRIMARY KEY", nil
 	case "string", "constant", "text", "ref", "uid", "url", "email", "phone":
 		return "TEXT", nil
-	case "int8", "int32", "int64":
+	case "int8", "int16", "int32", "int64":
 		return "INTEGER", nil
 	case "float32", "float64":
 		return "REAL", nil
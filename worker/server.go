@@ -15,31 +15,44 @@
 package worker
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/garrickvan/event-matrix/core"
 	"github.com/garrickvan/event-matrix/serverx"
 	"github.com/garrickvan/event-matrix/utils/jsonx"
 	"github.com/garrickvan/event-matrix/utils/loadtool"
 	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/utils/secretx"
 	"github.com/garrickvan/event-matrix/worker/cache"
+	"github.com/garrickvan/event-matrix/worker/common/interceptor"
 	"github.com/garrickvan/event-matrix/worker/intranet/dispatcher"
 	"github.com/garrickvan/event-matrix/worker/intranet/gnetimpl"
+	"github.com/garrickvan/event-matrix/worker/plugins/logcenter"
 	"github.com/garrickvan/event-matrix/worker/public/hertzimpl"
 	"github.com/garrickvan/event-matrix/worker/repo"
 	"github.com/garrickvan/event-matrix/worker/ruleengine"
 	"github.com/garrickvan/event-matrix/worker/types"
 )
 
+// PLUGIN_SHUTDOWN_TIMEOUT 单个插件关闭的超时时间
+const PLUGIN_SHUTDOWN_TIMEOUT = 5 * time.Second
+
 // TwoWayWorkerServer 是一个双向工作服务器实现
 // 它同时支持公网和内域通信，管理工作节点、插件、路由和任务执行
 type TwoWayWorkerServer struct { // WILLDO: 检查字段的并发安全性
 	public   serverx.NetworkServer // 公网服务器实例
 	intranet serverx.NetworkServer // 内域服务器实例
 
-	cfg    *types.WorkerServerConfig // 服务器配置
-	cfgKey string                    // 配置键名
+	cfg    atomic.Pointer[types.WorkerServerConfig] // 服务器配置，热更新时整体替换，读取时通过 Cfg 获取
+	cfgKey string                                   // 配置键名
+
+	cfgWatcher *fsnotify.Watcher // 本地配置文件监听器，仅从配置文件创建时存在
 
 	sharedConfigures        sync.Map                          // 共享配置存储，线程安全
 	onSharedConfigureChange types.OnSharedConfigureChangeFunc // 配置变更回调函数
@@ -47,19 +60,37 @@ type TwoWayWorkerServer struct { // WILLDO: 检查字段的并发安全性
 	workerIds          map[string]bool          // 工作节点ID集合
 	entityMapToWorkers map[string]*types.Worker // 实体到工作节点的映射
 	failedWorkers      map[string]*types.Worker // 失败的工作节点
+	failedWorkersMu    sync.Mutex               // 保护 failedWorkers，守护协程重新注册时与健康检查并发访问
 
-	plugins      map[types.INTRANET_EVENT_TYPE]types.PluginWorker // 插件映射
-	interceptors []types.Intercept                                // 拦截器列表
-	filters      []types.Filter                                   // 过滤器列表
+	plugins         map[types.INTRANET_EVENT_TYPE]types.PluginWorker // 插件映射
+	interceptors    []types.Intercept                                // 拦截器列表
+	filters         []types.Filter                                   // 过滤器列表
+	middlewares     []types.WorkerMiddleware                         // 执行器中间件列表
+	eventHooks      []types.EventProcessedHook                       // 事件处理完成回调列表
+	eventFilters    []types.EventFilterEntry                         // 跨实体事件订阅列表
+	registeredHooks []func(*types.Worker)                            // 工作者注册成功回调列表
+	onEventHooks    []eventHook                                      // 内置执行器成功回调列表
+	eventHookPool   eventHookPool                                    // 内置执行器成功回调的执行协程池
 
 	routers map[string]types.WorkerExecutor     // 路由执行器映射
 	tasks   map[string]types.WorkerTaskExecutor // 任务执行器映射
 
+	rateLimits sync.Map // 事件限流配置，事件唯一标签 -> *eventRateLimit
+
+	heartbeatStop  chan struct{} // 心跳上报的停止信号，Start 时创建
+	heartbeatReset chan struct{} // 心跳间隔变更信号，配置热更新时通知心跳协程重置定时器
+
 	repo          types.Repository        // 数据仓库接口
 	ruleEngineMgr types.RuleEngineManager // 规则引擎管理器
 
-	cache       types.DefaultCache // 默认缓存
-	domainCache types.DomainCache  // 领域模型缓存
+	cache            types.DefaultCache     // 默认缓存
+	domainCache      types.DomainCache      // 领域模型缓存
+	distributedCache types.DistributedCache // 分布式缓存，由缓存插件设置
+}
+
+// workerBoundPublicServer 创建时需要绑定配置和工作服务器的公网服务
+type workerBoundPublicServer interface {
+	BindWorkerServer(cfg *types.WorkerServerConfig, ws types.WorkerServer)
 }
 
 // TwoWayWorkerServerSettings 包含创建TwoWayWorkerServer所需的基本配置
@@ -76,6 +107,8 @@ type TwoWayWorkerServerSettings struct {
 func NewTwoWayWorkerServer(s TwoWayWorkerServerSettings) *TwoWayWorkerServer {
 	// 临时日志
 	logx.InitRuntimeLogger("logs", "info", "", 20*time.Second)
+	// 内域通信密钥可为 vault://、ssm:// 等密钥引用，启动时从密钥管理服务读取
+	s.IntranetSecret = resolveIntranetSecret(s.IntranetSecret)
 	// 临时初始化内域服务客户端
 	dispatcher.InitClient(
 		1,
@@ -122,6 +155,9 @@ func initByCfgKey(s *TwoWayWorkerServerSettings) *TwoWayWorkerServer {
 		panic("WorkerServer配置文件中[public_host、public_port]不能为空")
 	}
 	types.PatchWorkerServerConfig(&cfg)
+	if err := cfg.CheckPublicTLS(); err != nil {
+		panic("WorkerServer配置文件中TLS配置无效: " + err.Error())
+	}
 	cfg.IntranetSecret = s.IntranetSecret
 	cfg.IntranetSecretAlgor = s.IntranetSecretAlgor
 	cfg.GatewayIntranetEndpoint = s.GatewayIntranetEndpoint
@@ -133,6 +169,12 @@ func initByCfgKey(s *TwoWayWorkerServerSettings) *TwoWayWorkerServer {
 func newWorkerServerFromConfig(
 	cfg *types.WorkerServerConfig, s *TwoWayWorkerServerSettings, perloads map[string]*core.SharedConfigure,
 ) *TwoWayWorkerServer {
+	// 初始化日志，内域客户端初始化时需要使用
+	logSlicePeriod := time.Duration(cfg.LogSlicePeriod) * time.Second
+	logx.InitEventLogger(cfg.LogLocation, cfg.ServerId, logSlicePeriod)
+	logx.InitAuditLogger(cfg.LogLocation, cfg.ServerId, logSlicePeriod)
+	logx.InitRuntimeLogger(cfg.LogLocation, cfg.LogLevel, cfg.ServerId, logSlicePeriod)
+	cfg.IntranetSecret = resolveIntranetSecret(cfg.IntranetSecret)
 	// 重新初始化内域服务客户端
 	dispatcher.InitClient(
 		cfg.IntranetClientMaxIdleConnsPerHost,
@@ -144,15 +186,11 @@ func newWorkerServerFromConfig(
 		cfg.IntranetSecretAlgor,
 		cfg.IntranetCompress,
 	)
-	// 初始化日志
-	logSlicePeriod := time.Duration(cfg.LogSlicePeriod) * time.Second
-	logx.InitEventLogger(cfg.LogLocation, cfg.ServerId, logSlicePeriod)
-	logx.InitRuntimeLogger(cfg.LogLocation, cfg.LogLevel, cfg.ServerId, logSlicePeriod)
+	dispatcher.SetMaxMessageSize(cfg.MaxMessageSizeBytes)
 	loadtool.Init(6) // 6*5s = 30s 采样周期
 	// 初始化两路Worker服务
 	ws := TwoWayWorkerServer{
 		cfgKey: s.CfgKey,
-		cfg:    cfg,
 
 		workerIds:          map[string]bool{},
 		entityMapToWorkers: make(map[string]*types.Worker),
@@ -165,10 +203,20 @@ func newWorkerServerFromConfig(
 
 		routers: make(map[string]types.WorkerExecutor),
 		tasks:   make(map[string]types.WorkerTaskExecutor),
+
+		heartbeatReset: make(chan struct{}, 1),
 	}
+	ws.cfg.Store(cfg)
 	for _, cfg := range perloads {
 		ws.sharedConfigures.Store(cfg.Key, cfg)
 	}
+	ws.onSharedConfigureChange = ws.applySharedConfigureChange
+	// 内置审计中间件，记录开启日志的更新事件修改前后的字段值
+	ws.middlewares = append(ws.middlewares, interceptor.AuditInterceptor)
+	// 预加载JWT签名密钥，加载失败时访问令牌不在本地验证
+	if cfg.JwtCfgKey != "" && ws.JwtSecret() == nil {
+		logx.Warn("JWT签名密钥配置不存在或无效: " + cfg.JwtCfgKey)
+	}
 	// 初始化 WorkerServer 内部组件
 	ws.ruleEngineMgr = ruleengine.NewRuleEngineManager(&ws)
 	// 初始化 WorkerServer 数据库组件
@@ -184,30 +232,52 @@ func newWorkerServerFromConfig(
 	if err != nil {
 		panic("初始化领域缓存失败: " + err.Error())
 	}
+	for kind, ttl := range cfg.DomainCacheTTLs {
+		domainCache.WithTTL(kind, ttl)
+	}
 	ws.domainCache = domainCache
 	if s.PublicServer == nil {
 		// 初始化公网服务
 		pSvr := hertzimpl.NewWorkerPublicServer(cfg, &ws)
 		ws.public = pSvr
 	} else {
+		// 需要工作服务器的自定义公网服务，如 wsimpl.WebSocketPublicServer
+		if b, ok := s.PublicServer.(workerBoundPublicServer); ok {
+			b.BindWorkerServer(cfg, &ws)
+		}
 		ws.public = s.PublicServer
 	}
 	// 初始化内域服务
 	iSvr := gnetimpl.NewWorkerIntranetServer(cfg, &ws)
 	ws.intranet = iSvr
+	// 注册健康检查路径，自定义的公网服务未实现时跳过
+	if _, ok := ws.public.(healthPublicServer); ok {
+		if err := ws.RegisterHealthEndpoint(""); err != nil {
+			logx.Warn("注册健康检查路径失败: " + err.Error())
+		}
+	}
 	return &ws
 }
 
+// resolveIntranetSecret 解析内域通信密钥引用，不是密钥引用时原样返回，读取失败时触发panic
+func resolveIntranetSecret(secret string) string {
+	resolved, err := secretx.Resolve(secret)
+	if err != nil {
+		panic("读取内域通信密钥失败: " + err.Error())
+	}
+	return resolved
+}
+
 // Cfg 获取服务器配置
-// 如果配置为空，会创建一个带有默认值的配置对象
+// 如果配置为空，会创建一个带有默认值的配置对象；配置热更新时整体替换，返回的配置不应修改
 func (s *TwoWayWorkerServer) Cfg() *types.WorkerServerConfig {
-	if s.cfg == nil {
-		cfg := types.WorkerServerConfig{}
-		types.PatchWorkerServerConfig(&cfg)
-		s.cfg = &cfg
-		return s.cfg
+	if cfg := s.cfg.Load(); cfg != nil {
+		return cfg
 	}
-	return s.cfg
+	cfg := types.WorkerServerConfig{}
+	types.PatchWorkerServerConfig(&cfg)
+	s.cfg.CompareAndSwap(nil, &cfg)
+	return s.cfg.Load()
 }
 
 // Start 启动工作服务器
@@ -226,6 +296,12 @@ func (s *TwoWayWorkerServer) Start() error {
 	if err != nil {
 		logx.Error("上报WorkerServer信息失败: " + err.Error())
 	}
+	// 预热领域缓存，避免启动后首批请求回源网关
+	s.warmUpDomainCache()
+	// 预热完成后延迟接受内域连接
+	s.delayIntranetReadiness()
+	// 定时上报负载率，网关据此优先路由到负载较低的工作者
+	s.startHeartbeat()
 	// 启动内域网络服务
 	go func() {
 		err := s.intranet.Start()
@@ -241,16 +317,161 @@ func (s *TwoWayWorkerServer) Start() error {
 	return nil
 }
 
+// warmUpDomainCache 使用已注册的工作者预热领域缓存，预热失败或超时不影响服务启动
+func (s *TwoWayWorkerServer) warmUpDomainCache() {
+	dc, ok := s.domainCache.(*cache.DomainCacheImpl)
+	if !ok {
+		return
+	}
+	workers := s.registeredWorkers()
+	timeout := time.Duration(s.Cfg().DomainCacheWarmUpTimeout) * time.Second
+	timedOut, err := waitWarmUp(func() error { return dc.WarmUp(workers) }, timeout)
+	if timedOut {
+		logx.Warn(fmt.Sprintf("领域缓存预热超过 %v 未完成，跳过等待继续启动，请检查网关是否可达", timeout))
+		return
+	}
+	if err != nil {
+		logx.Warn(err.Error())
+	}
+}
+
+// waitWarmUp 执行预热并最多等待 timeout，超时后预热继续在后台执行，timeout 小于等于0时一直等待
+func waitWarmUp(warmUp func() error, timeout time.Duration) (bool, error) {
+	if timeout <= 0 {
+		return false, warmUp()
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- warmUp()
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return false, err
+	case <-timer.C:
+		return true, nil
+	}
+}
+
+// intranetReadiness 支持设置就绪时间的内域服务
+type intranetReadiness interface {
+	SetReadyAt(t time.Time)
+}
+
+// delayIntranetReadiness 设置内域服务的就绪时间为当前时间加上 ReadinessProbeDelay，就绪前拒绝新连接，
+// ReadinessProbeDelay 小于等于0时立即就绪
+func (s *TwoWayWorkerServer) delayIntranetReadiness() {
+	r, ok := s.intranet.(intranetReadiness)
+	if !ok {
+		return
+	}
+	delay := s.Cfg().ReadinessProbeDelay
+	if delay < 0 {
+		delay = 0
+	}
+	r.SetReadyAt(time.Now().Add(time.Duration(delay) * time.Second))
+}
+
 // Stop 停止工作服务器
-// 依次停止公网和内域服务，任何一个停止失败都会返回错误
+// 依次停止公网服务、关闭插件、停止内域服务、关闭数据库连接，网络服务停止失败会返回错误
 func (s *TwoWayWorkerServer) Stop() error {
+	s.stopHeartbeat()
 	err := s.public.Stop()
 	if err != nil {
 		return err
 	}
+	s.shutdownPlugins()
 	err = s.intranet.Stop()
 	if err != nil {
 		return err
 	}
+	if s.cfgWatcher != nil {
+		s.cfgWatcher.Close()
+	}
+	// 关闭数据库连接，避免频繁重启时占满数据库的连接数
+	if s.repo != nil {
+		if err := s.repo.Close(); err != nil {
+			logx.Error("关闭数据库连接失败: " + err.Error())
+			return err
+		}
+	}
 	return nil
 }
+
+// intranetDrainer 支持停止接受新连接的内域服务
+type intranetDrainer interface {
+	StopAccepting()
+}
+
+// pluginDrainer 支持排空处理中任务的插件，如任务中心
+type pluginDrainer interface {
+	Drain(ctx context.Context) error
+}
+
+// Shutdown 优雅停止工作服务器
+// 先停止接受新的内域连接，等待插件中处理中的任务完成，提交剩余的日志切片，最后调用 Stop 停止服务。
+// ctx 到期时不再等待，仍会停止服务，并返回 ctx 的错误
+func (s *TwoWayWorkerServer) Shutdown(ctx context.Context) error {
+	if d, ok := s.intranet.(intranetDrainer); ok {
+		d.StopAccepting()
+	}
+	drainErr := s.drainPlugins(ctx)
+	if drainErr != nil {
+		logx.Warn("等待插件处理中的任务完成失败: " + drainErr.Error())
+	}
+	if err := logcenter.FlushLogSubmitter(ctx); err != nil {
+		logx.Warn("提交剩余日志失败: " + err.Error())
+	}
+	if err := s.Stop(); err != nil {
+		return err
+	}
+	return drainErr
+}
+
+// drainPlugins 依次等待支持排空的插件完成处理中的任务，ctx 到期时返回 ctx 的错误
+func (s *TwoWayWorkerServer) drainPlugins(ctx context.Context) error {
+	drained := make(map[types.PluginWorker]bool)
+	for _, plugin := range s.plugins {
+		if drained[plugin] {
+			continue
+		}
+		drained[plugin] = true
+		d, ok := plugin.(pluginDrainer)
+		if !ok {
+			continue
+		}
+		if err := d.Drain(ctx); err != nil {
+			return fmt.Errorf("插件 %T 排空失败: %w", plugin, err)
+		}
+	}
+	return nil
+}
+
+// shutdownPlugins 依次关闭已注册的插件，单个插件关闭失败或超时只记录错误
+func (s *TwoWayWorkerServer) shutdownPlugins() {
+	closed := make(map[types.PluginWorker]bool)
+	for _, plugin := range s.plugins {
+		if closed[plugin] {
+			continue
+		}
+		closed[plugin] = true
+		if err := shutdownPlugin(plugin, PLUGIN_SHUTDOWN_TIMEOUT); err != nil {
+			logx.Log().Error(fmt.Sprintf("关闭插件 %T 失败: %v", plugin, err))
+		}
+	}
+}
+
+// shutdownPlugin 在超时时间内关闭插件
+func shutdownPlugin(plugin types.PluginWorker, timeout time.Duration) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- plugin.Shutdown()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		return errors.New("插件关闭超时")
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/garrickvan/event-matrix/serverx"
+	"github.com/garrickvan/event-matrix/serverx/gnetx"
+	"github.com/garrickvan/event-matrix/worker/types"
+)
+
+// stopPublic 记录停止时间的测试公网服务
+type stopPublic struct {
+	serverx.NetworkServer
+	stoppedAt time.Time
+}
+
+func (s *stopPublic) Stop() error {
+	s.stoppedAt = time.Now()
+	return nil
+}
+
+// drainPlugin 模拟处理中任务的测试插件，每个任务在 delay 后完成
+type drainPlugin struct {
+	fakePlugin
+	wg       sync.WaitGroup
+	finished int64
+	doneAt   time.Time
+}
+
+func (p *drainPlugin) run(tasks int, delay time.Duration) {
+	for i := 0; i < tasks; i++ {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			time.Sleep(delay)
+			atomic.AddInt64(&p.finished, 1)
+		}()
+	}
+}
+
+func (p *drainPlugin) Drain(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		p.doneAt = time.Now()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func newShutdownTestServer(plugin types.PluginWorker) (*TwoWayWorkerServer, *stopPublic, *gnetx.IntranetServer) {
+	pub := &stopPublic{}
+	iSvr := gnetx.NewIntranetServer("shutdown-test", 0, "", "NONE", nil, nil)
+	ws := &TwoWayWorkerServer{
+		public:   pub,
+		intranet: iSvr,
+		plugins:  map[types.INTRANET_EVENT_TYPE]types.PluginWorker{32000: plugin, 32001: plugin},
+	}
+	return ws, pub, iSvr
+}
+
+func TestShutdownDrainsInFlightTasks(t *testing.T) {
+	plugin := &drainPlugin{}
+	ws, pub, iSvr := newShutdownTestServer(plugin)
+	plugin.run(20, 50*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := ws.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if iSvr.Ready() {
+		t.Error("停机后内域服务不应接受新连接")
+	}
+	if n := atomic.LoadInt64(&plugin.finished); n != 20 {
+		t.Fatalf("停机前应完成全部任务，实际完成 %d", n)
+	}
+	if pub.stoppedAt.Before(plugin.doneAt) {
+		t.Error("应在任务排空后才停止服务")
+	}
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	plugin := &drainPlugin{}
+	ws, pub, _ := newShutdownTestServer(plugin)
+	plugin.run(1, time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := ws.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("超过停机期限应返回 DeadlineExceeded，实际 %v", err)
+	}
+	if pub.stoppedAt.IsZero() {
+		t.Error("超过停机期限后仍应停止服务")
+	}
+}
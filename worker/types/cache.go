@@ -15,6 +15,10 @@
 package types
 
 import (
+	"errors"
+	"strings"
+	"time"
+
 	"github.com/garrickvan/event-matrix/core"
 	"github.com/garrickvan/event-matrix/utils/cachex"
 )
@@ -23,6 +27,29 @@ import (
 type DefaultCache interface {
 	// Impl 返回底层的 LocalCache 实例。
 	Impl() *cachex.LocalCache
+
+	// Get 获取缓存值，键形如 实体标识:业务键 且该实体注册了加载器时，未命中会自动调用加载器加载并缓存。
+	Get(key string) (interface{}, bool)
+
+	// RegisterLoader 注册实体的缓存加载器，entityLabel 为实体标识（如 Worker.GetVersionEntityLabel），
+	// 加载器的参数为去掉实体标识前缀的业务键，加载结果按默认缓存过期时间缓存，返回 nil 时不缓存。
+	RegisterLoader(entityLabel string, fn CacheLoader)
+}
+
+// CacheLoader 缓存加载器，在默认缓存未命中时按业务键加载数据。
+type CacheLoader func(key string) (interface{}, error)
+
+// CACHE_LOADER_KEY_SEP 读穿缓存键中实体标识与业务键的分隔符。
+const CACHE_LOADER_KEY_SEP = ":"
+
+// ReadThroughCacheKey 生成读穿缓存键，形如 实体标识:业务键。
+func ReadThroughCacheKey(entityLabel, key string) string {
+	return entityLabel + CACHE_LOADER_KEY_SEP + key
+}
+
+// SplitReadThroughCacheKey 拆分读穿缓存键，返回实体标识和业务键，键中不含分隔符时 ok 为 false。
+func SplitReadThroughCacheKey(cacheKey string) (entityLabel, key string, ok bool) {
+	return strings.Cut(cacheKey, CACHE_LOADER_KEY_SEP)
 }
 
 // DomainCache 定义了一个领域缓存接口，用于处理与项目和实体相关的缓存操作。
@@ -42,6 +69,35 @@ type DomainCache interface {
 	// EntityAttrs 根据路径获取实体的所有属性列表。
 	EntityAttrs(e PathToEntity) []core.EntityAttribute
 
+	// Invalidate 使指定键的缓存失效，key 为实体路径参数（PathToEntity.ToStrArg）时，
+	// 同时失效该实体的实体信息、属性和事件缓存。
+	Invalidate(key string)
+
+	// InvalidateConstants 使常量缓存失效，dict 为空时失效项目下的全部常量，project 也为空时失效全部常量。
+	InvalidateConstants(project, dict string)
+
 	// Impl 返回底层的 LocalCache 实例。
 	Impl() *cachex.LocalCache
 }
+
+// ConstantCacheReset 常量缓存失效请求的负载，随 G_T_W_RESET_DOMAIN_CACHE 事件以JSON发送，
+// Dict 为空时失效全部常量缓存（指定 Project 时仅失效该项目的常量）
+type ConstantCacheReset struct {
+	Project string `json:"project,omitempty"` // 项目
+	Dict    string `json:"dict,omitempty"`    // 常量字典名称
+}
+
+// ErrCacheMiss 表示分布式缓存中不存在指定的键。
+var ErrCacheMiss = errors.New("缓存不存在")
+
+// DistributedCache 定义了分布式缓存接口，用于在多个工作实例间共享数据，如会话令牌、限流计数等。
+type DistributedCache interface {
+	// Get 获取缓存值，键不存在时返回 ErrCacheMiss。
+	Get(key string) (string, error)
+
+	// Set 设置缓存值，ttl为0时表示永不过期。
+	Set(key, value string, ttl time.Duration) error
+
+	// Del 删除缓存值。
+	Del(key string) error
+}
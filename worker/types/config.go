@@ -15,9 +15,14 @@
 package types
 
 import (
+	"crypto/tls"
+	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/utils/encryptx"
+	"github.com/garrickvan/event-matrix/utils/logx"
 )
 
 // WorkerServerConfig 定义工作服务器的完整配置结构
@@ -34,16 +39,25 @@ type WorkerServerConfig struct {
 	PublicPort       int    `yaml:"public_port" json:"public_port"`               // 公网服务端口
 	HttpReadTimeout  int    `yaml:"http_read_timeout" json:"http_read_timeout"`   // HTTP请求读取超时时间（秒）
 	HttpWriteTimeout int    `yaml:"http_write_timeout" json:"http_write_timeout"` // HTTP响应写入超时时间（秒）
+	TLSCertFile      string `yaml:"tls_cert_file" json:"tls_cert_file"`           // 公网服务TLS证书文件路径，与私钥同时配置时启用HTTPS
+	TLSKeyFile       string `yaml:"tls_key_file" json:"tls_key_file"`             // 公网服务TLS私钥文件路径
+	EnableHTTP2      bool   `yaml:"enable_http2" json:"enable_http2"`             // 是否启用HTTP/2，需同时配置TLS证书，通过ALPN与客户端协商
+	AllowExplain     bool   `yaml:"allow_explain" json:"allow_explain"`           // 是否允许通过 X-Explain 请求头返回查询计划，用于排查慢查询，生产环境不要开启
 
 	// 内部服务相关配置（内域通信服务）
 	IntranetHost                      string `yaml:"intranet_host" json:"intranet_host"`                                                     // 内域服务主机地址
 	IntranetPort                      int    `yaml:"intranet_port" json:"intranet_port"`                                                     // 内域服务端口
 	IntranetSecret                    string `yaml:"intranet_secret" json:"intranet_secret"`                                                 // 内域通信加密密钥
-	IntranetSecretAlgor               string `yaml:"intranet_secret_algor" json:"intranet_secret_algor"`                                     // 内域通信加密算法
+	IntranetSecretAlgor               string `yaml:"intranet_secret_algor" json:"intranet_secret_algor"`                                     // 内域通信加密算法（NONE、AES-128、AES-192、AES-256、AES-256-GCM、HMAC-SHA256）
 	IntranetClientMaxIdleConnsPerHost int    `yaml:"intranet_client_max_idle_conns_per_host" json:"intranet_client_max_idle_conns_per_host"` // 内域客户端每个主机最大空闲连接数
 	IntranetClientConnectionExpired   int    `yaml:"intranet_client_connection_expired" json:"intranet_client_connection_expired"`           // 内域客户端连接过期时间（秒）
 	IntranetClientWriteTimeout        int    `yaml:"intranet_client_write_timeout" json:"intranet_client_write_timeout"`                     // 内域客户端写入超时时间（秒）
 	IntranetCompress                  bool   `yaml:"intranet_compress" json:"intranet_compress"`                                             // 内域通信是否启用压缩
+	IntranetMaxConnections            int    `yaml:"intranet_max_connections" json:"intranet_max_connections"`                               // 内域服务最大连接数
+	MaxMessageSizeBytes               int    `yaml:"max_message_size_bytes" json:"max_message_size_bytes"`                                   // 内域通信单条消息最大字节数，内域服务和客户端共用，默认1MB
+	PacketTimestampToleranceMs        int64  `yaml:"packet_timestamp_tolerance_ms" json:"packet_timestamp_tolerance_ms"`                     // 内域请求包时间戳的容忍窗口（毫秒），用于防重放，节点间时钟漂移较大时可调大
+	ReadinessProbeDelay               int    `yaml:"readiness_probe_delay" json:"readiness_probe_delay"`                                     // 领域缓存预热完成后延迟接受内域连接的时间（秒），默认0不延迟，小于等于0时不延迟
+	IntranetMetricsPort               int    `yaml:"intranet_metrics_port" json:"intranet_metrics_port"`                                     // 内域服务 Prometheus 指标端口，为0时不开启
 
 	// 日志相关配置
 	LogLevel       string `yaml:"log_level" json:"log_level"`               // 日志级别（debug/info/warn/error）
@@ -57,11 +71,16 @@ type WorkerServerConfig struct {
 	// 领域模型缓存配置
 	DomainCacheMaxMen int64 `yaml:"domain_cache_max_men" json:"domain_cache_max_men"` // 领域缓存最大内存占用（字节）
 	DomainCacheTTL    int   `yaml:"domain_cache_ttl" json:"domain_cache_ttl"`         // 领域缓存项过期时间（秒）
+	// 按数据类别设置的领域缓存过期时间（秒），key 为 entity、entity_attr、entity_event 或 constant，未设置时使用 DomainCacheTTL
+	DomainCacheTTLs map[string]int `yaml:"domain_cache_ttls" json:"domain_cache_ttls"`
+	// 启动时等待领域缓存预热的最长时间（秒），超时后不再等待，网关不可达时避免阻塞启动，小于0时一直等待预热完成
+	DomainCacheWarmUpTimeout int `yaml:"domain_cache_warm_up_timeout" json:"domain_cache_warm_up_timeout"`
 
 	// 其他配置
 	GatewayIntranetEndpoint               string `yaml:"gateway_intranet_endpoint" json:"gateway_intranet_endpoint"`                                     // 网关内域服务地址
 	HeartbeatReportGap                    int    `yaml:"heartbeat_report_gap" json:"heartbeat_report_gap"`                                               // 心跳上报间隔（秒）
 	NotAcceptUpdateRecordEventFromGateway bool   `yaml:"not_accept_update_record_event_from_gateway" json:"not_accept_update_record_event_from_gateway"` // 是否拒绝来自网关的更新记录事件
+	JwtCfgKey                             string `yaml:"jwt_cfg_key" json:"jwt_cfg_key"`                                                                 // JWT签名密钥的共享配置键，配置后在本地验证访问令牌
 }
 
 // PatchWorkerServerConfig 为WorkerServerConfig补充默认配置值
@@ -131,6 +150,57 @@ func PatchWorkerServerConfig(cfg *WorkerServerConfig) {
 		cfg.HeartbeatReportGap = 60
 	}
 	if cfg.IntranetSecretAlgor == "" {
-		cfg.IntranetSecretAlgor = "NONE"
+		cfg.IntranetSecretAlgor = encryptx.NONE
 	}
+	if !encryptx.IsSupportedAlgor(cfg.IntranetSecretAlgor) {
+		logx.Warn("不支持的内域通信加密算法: " + cfg.IntranetSecretAlgor + "，将按 AES-128 处理，可选值: NONE、AES-128、AES-192、AES-256、AES-256-GCM、HMAC-SHA256")
+	}
+	if cfg.IntranetMaxConnections <= 0 {
+		cfg.IntranetMaxConnections = 10000
+	}
+	if cfg.MaxMessageSizeBytes <= 0 {
+		cfg.MaxMessageSizeBytes = 1024 * 1024 // 1MB
+	}
+	if cfg.PacketTimestampToleranceMs <= 0 {
+		cfg.PacketTimestampToleranceMs = 5000
+	}
+	if cfg.DomainCacheWarmUpTimeout == 0 {
+		cfg.DomainCacheWarmUpTimeout = 30
+	}
+	patchPublicTLS(cfg)
+}
+
+// patchPublicTLS 未配置TLS时关闭HTTP/2，TLS配置是否有效由 CheckPublicTLS 校验
+func patchPublicTLS(cfg *WorkerServerConfig) {
+	if cfg.EnableHTTP2 && !cfg.PublicTLSEnabled() {
+		logx.Warn("HTTP/2 需要配置TLS证书，公网服务将使用 HTTP/1.1")
+		cfg.EnableHTTP2 = false
+	}
+}
+
+// CheckPublicTLS 校验公网服务的TLS证书和私钥，未配置TLS时返回nil
+// 配置了证书或私钥但不完整、文件不可用或无法加载时返回错误，避免服务以不加密的HTTP静默启动
+func (cfg *WorkerServerConfig) CheckPublicTLS() error {
+	_, err := cfg.LoadPublicTLSCertificate()
+	return err
+}
+
+// LoadPublicTLSCertificate 加载公网服务的TLS证书，未配置TLS时返回nil，配置无效时返回错误
+func (cfg *WorkerServerConfig) LoadPublicTLSCertificate() (*tls.Certificate, error) {
+	if cfg.TLSCertFile == "" && cfg.TLSKeyFile == "" {
+		return nil, nil
+	}
+	if cfg.TLSCertFile == "" || cfg.TLSKeyFile == "" {
+		return nil, errors.New("公网服务TLS证书[tls_cert_file]和私钥[tls_key_file]需同时配置")
+	}
+	cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("加载公网服务TLS证书失败: %w", err)
+	}
+	return &cert, nil
+}
+
+// PublicTLSEnabled 公网服务是否配置了TLS证书和私钥
+func (cfg *WorkerServerConfig) PublicTLSEnabled() bool {
+	return cfg.TLSCertFile != "" && cfg.TLSKeyFile != ""
 }
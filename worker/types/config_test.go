@@ -0,0 +1,94 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestCert 生成自签名证书和私钥文件
+func writeTestCert(t *testing.T, dir string) (string, string) {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert := filepath.Join(dir, "cert.pem")
+	key := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(key, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestPatchPublicTLS(t *testing.T) {
+	dir := t.TempDir()
+	cert, key := writeTestCert(t, dir)
+	invalid := filepath.Join(dir, "invalid.pem")
+	if err := os.WriteFile(invalid, []byte("test"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name      string
+		cfg       WorkerServerConfig
+		wantErr   bool
+		wantHTTP2 bool
+	}{
+		{"未配置TLS", WorkerServerConfig{EnableHTTP2: true}, false, false},
+		{"只配置证书", WorkerServerConfig{TLSCertFile: cert, EnableHTTP2: true}, true, false},
+		{"文件不存在", WorkerServerConfig{TLSCertFile: cert, TLSKeyFile: filepath.Join(dir, "missing.pem")}, true, false},
+		{"证书无效", WorkerServerConfig{TLSCertFile: invalid, TLSKeyFile: key}, true, false},
+		{"TLS不启用HTTP/2", WorkerServerConfig{TLSCertFile: cert, TLSKeyFile: key}, false, false},
+		{"TLS启用HTTP/2", WorkerServerConfig{TLSCertFile: cert, TLSKeyFile: key, EnableHTTP2: true}, false, true},
+	}
+	for _, c := range cases {
+		patchPublicTLS(&c.cfg)
+		err := c.cfg.CheckPublicTLS()
+		if (err != nil) != c.wantErr || c.cfg.EnableHTTP2 != c.wantHTTP2 {
+			t.Errorf("%s: err=%v http2=%v", c.name, err, c.cfg.EnableHTTP2)
+		}
+		// 配置了TLS时不回退到不加密的HTTP
+		if c.wantErr && c.cfg.TLSCertFile == "" && c.cfg.TLSKeyFile == "" {
+			t.Errorf("%s: TLS配置不应被清空", c.name)
+		}
+	}
+}
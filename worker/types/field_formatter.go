@@ -0,0 +1,67 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"unicode/utf8"
+)
+
+// MASK_PLACEHOLDER 脱敏替换内容
+const MASK_PLACEHOLDER = "***"
+
+// MaskFormatter 自定义字段解析器的脱敏装饰器，只改变 Format 的展示结果，其余方法沿用原解析器
+// 展示时保留首尾指定个数的字符，中间替换为 ***，字符数不足时整体替换
+type MaskFormatter struct {
+	CustomFieldParser
+
+	keepPrefix int // 保留的前缀字符数
+	keepSuffix int // 保留的后缀字符数
+}
+
+// NewMaskFormatter 创建脱敏装饰器，keepPrefix、keepSuffix 为展示时保留的首尾字符数
+func NewMaskFormatter(parser CustomFieldParser, keepPrefix, keepSuffix int) *MaskFormatter {
+	if keepPrefix < 0 {
+		keepPrefix = 0
+	}
+	if keepSuffix < 0 {
+		keepSuffix = 0
+	}
+	return &MaskFormatter{
+		CustomFieldParser: parser,
+		keepPrefix:        keepPrefix,
+		keepSuffix:        keepSuffix,
+	}
+}
+
+// Format 调用原解析器的 Format 后脱敏
+func (m *MaskFormatter) Format(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return MaskString(m.CustomFieldParser.Format(value), m.keepPrefix, m.keepSuffix)
+}
+
+// MaskString 保留首尾指定个数的字符，中间替换为 ***，字符数不足时整体替换
+func MaskString(s string, keepPrefix, keepSuffix int) string {
+	if s == "" {
+		return ""
+	}
+	size := utf8.RuneCountInString(s)
+	if size <= keepPrefix+keepSuffix {
+		return MASK_PLACEHOLDER
+	}
+	runes := []rune(s)
+	return string(runes[:keepPrefix]) + MASK_PLACEHOLDER + string(runes[size-keepSuffix:])
+}
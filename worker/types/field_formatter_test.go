@@ -0,0 +1,72 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"testing"
+
+	"github.com/spf13/cast"
+	"gorm.io/gorm"
+)
+
+type phoneFieldParser struct{}
+
+func (phoneFieldParser) FieldParserName() string                     { return "phone" }
+func (phoneFieldParser) Validate(interface{}) error                  { return nil }
+func (phoneFieldParser) ParseParam(s string) interface{}             { return s }
+func (phoneFieldParser) CreateColumn(*gorm.DB, string, string) error { return nil }
+func (phoneFieldParser) DefaultValue() interface{}                   { return nil }
+func (phoneFieldParser) Description() string                         { return "手机号" }
+func (phoneFieldParser) Format(value interface{}) string             { return "+86 " + cast.ToString(value) }
+
+func TestMaskFormatter(t *testing.T) {
+	f := NewMaskFormatter(phoneFieldParser{}, 6, 4)
+	if f.FieldParserName() != "phone" {
+		t.Fatalf("装饰器应沿用原解析器名称, got %s", f.FieldParserName())
+	}
+	cases := []struct {
+		value interface{}
+		want  string
+	}{
+		{"13800138000", "+86 13***8000"},
+		{13800138000, "+86 13***8000"},
+		{"12", "***"},
+		{nil, ""},
+	}
+	for _, c := range cases {
+		if got := f.Format(c.value); got != c.want {
+			t.Errorf("Format(%v) = %q, want %q", c.value, got, c.want)
+		}
+	}
+}
+
+func TestMaskString(t *testing.T) {
+	cases := []struct {
+		s                      string
+		keepPrefix, keepSuffix int
+		want                   string
+	}{
+		{"", 1, 1, ""},
+		{"abcdef", 1, 1, "a***f"},
+		{"abcdef", 0, 0, "***"},
+		{"张三丰", 1, 0, "张***"},
+		{"ab", 1, 1, "***"},
+	}
+	for _, c := range cases {
+		if got := MaskString(c.s, c.keepPrefix, c.keepSuffix); got != c.want {
+			t.Errorf("MaskString(%q, %d, %d) = %q, want %q", c.s, c.keepPrefix, c.keepSuffix, got, c.want)
+		}
+	}
+}
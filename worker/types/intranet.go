@@ -15,11 +15,15 @@
 package types
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/garrickvan/event-matrix/constant"
 	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/serverx/gnetx"
 	"github.com/garrickvan/event-matrix/utils/fastconv"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/spf13/cast"
 )
 
 type INTRANET_EVENT_TYPE uint16
@@ -51,6 +55,8 @@ const (
 	W_T_G_GET_USER_DETAIL              INTRANET_EVENT_TYPE = 10014 // 获取用户详情
 	W_T_G_SAVE_USER_SENSITIVE_INFO     INTRANET_EVENT_TYPE = 10015 // 保存用户敏感信息
 	W_T_G_GET_USER_SENSITIVE_INFO      INTRANET_EVENT_TYPE = 10016 //  获取用户敏感信息
+	W_T_G_REGISTER_ALIASES             INTRANET_EVENT_TYPE = 10017 // 注册工作端的实体别名
+	W_T_G_HEARTBEAT_WITH_LOAD          INTRANET_EVENT_TYPE = 10018 // 工作端心跳，携带负载率
 
 	G_T_W_CHECK_WORKER               INTRANET_EVENT_TYPE = 20000 // 来自网关的检查工作端是否存在
 	G_T_W_RULE_UPDATE                INTRANET_EVENT_TYPE = 20001 // 来自网关的规则更新
@@ -68,6 +74,62 @@ func IsIntranetEventType(typz int32) bool {
 	return typz >= 1 && typz <= 40000
 }
 
+// IntranetXData 内域请求的扩展数据
+// 只有事件类型时直接使用事件类型的数字字符串，兼容旧版本；需要携带语言或链路追踪上下文时使用JSON格式
+type IntranetXData struct {
+	Type   INTRANET_EVENT_TYPE `json:"type"`             // 事件类型
+	Locale string              `json:"locale,omitempty"` // 请求语言
+	// W3C 链路追踪上下文，对应 traceparent、tracestate 和 baggage 请求头
+	TraceParent string `json:"traceparent,omitempty"`
+	TraceState  string `json:"tracestate,omitempty"`
+	Baggage     string `json:"baggage,omitempty"`
+}
+
+// ParseIntranetXData 解析内域请求的扩展数据，无效的事件类型解析为 UNKNOWN_EVENT
+func ParseIntranetXData(xdata string) IntranetXData {
+	xd := IntranetXData{}
+	if strings.HasPrefix(strings.TrimSpace(xdata), "{") {
+		if err := jsonx.UnmarshalFromStr(xdata, &xd); err != nil {
+			return IntranetXData{}
+		}
+	} else {
+		xd.Type = INTRANET_EVENT_TYPE(cast.ToInt32(xdata))
+	}
+	if !IsIntranetEventType(int32(xd.Type)) {
+		xd.Type = UNKNOWN_EVENT
+	}
+	return xd
+}
+
+// String 序列化扩展数据，未设置语言和链路追踪上下文时使用旧格式
+func (x IntranetXData) String() string {
+	if x.Locale == "" && x.TraceParent == "" && x.Baggage == "" {
+		return strconv.Itoa(int(x.Type))
+	}
+	str, _ := jsonx.MarshalToStr(x)
+	return str
+}
+
+// TraceHeader 返回指定的链路追踪请求头，不区分大小写
+func (x IntranetXData) TraceHeader(key string) string {
+	switch strings.ToLower(key) {
+	case TRACEPARENT_HEADER:
+		return x.TraceParent
+	case TRACESTATE_HEADER:
+		return x.TraceState
+	case BAGGAGE_HEADER:
+		return x.Baggage
+	}
+	return ""
+}
+
+// SetTraceHeaders 设置链路追踪请求头，通常来自 InjectTraceContext
+func (x *IntranetXData) SetTraceHeaders(headers map[string]string) {
+	x.TraceParent = headers[TRACEPARENT_HEADER]
+	x.TraceState = headers[TRACESTATE_HEADER]
+	x.Baggage = headers[BAGGAGE_HEADER]
+}
+
 // 内部事件
 type IntranetEvent struct {
 	Type   INTRANET_EVENT_TYPE `json:"type"`   // 事件类型
@@ -76,9 +138,28 @@ type IntranetEvent struct {
 
 // 检查结果
 type WorkerCheckResult struct {
-	WorkerId string  `json:"wid"`
-	Exist    bool    `json:"exist"`
-	LoadRate float64 `json:"load_rate"`
+	WorkerId string                  `json:"wid"`
+	Exist    bool                    `json:"exist"`
+	LoadRate float64                 `json:"load_rate"`
+	Healthy  bool                    `json:"healthy"`           // 所有插件是否健康
+	Plugins  map[string]PluginHealth `json:"plugins,omitempty"` // 插件健康状态
+}
+
+// LOAD_RATE_REPORT_V2 获取负载率时携带该参数，工作端返回 LoadRateReport，否则仅返回负载率字符串
+const LOAD_RATE_REPORT_V2 = "v2"
+
+// 负载率查询结果
+type LoadRateReport struct {
+	LoadRate     float64                `json:"load_rate"`               // 设备负载率
+	RequestStats []gnetx.EventTypeStats `json:"request_stats,omitempty"` // 按事件类型统计的内域请求
+}
+
+// 工作端心跳上报，网关据此更新工作者的负载率，优先路由到负载较低的工作者
+type WorkerHeartbeat struct {
+	ServerId  string   `json:"server_id"`  // 工作服务器ID
+	WorkerIds []string `json:"worker_ids"` // 已注册的工作者ID列表
+	LoadRate  float64  `json:"load_rate"`  // 设备负载率
+	Timestamp int64    `json:"timestamp"`  // 上报时间戳（毫秒）
 }
 
 // 工作端公网地址信息
@@ -149,6 +230,11 @@ func PathToEntityFromPathToEvent(e PathToEvent) PathToEntity {
 	return p
 }
 
+// 获取实体对应的数据表名，与 Worker.GetTabelName 一致
+func (p *PathToEntity) TableName() string {
+	return p.Context + "_" + p.Entity
+}
+
 // 将 PathToEntity 结构体转换为字符串参数
 func (p *PathToEntity) ToStrArg() string {
 	return strings.Join([]string{p.Project, p.Version, p.Context, p.Entity}, constant.SPLIT_CHAR)
@@ -0,0 +1,43 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import "testing"
+
+func TestParseIntranetXData(t *testing.T) {
+	cases := []struct {
+		xdata string
+		want  IntranetXData
+	}{
+		{"1", IntranetXData{Type: W_T_W_EVENT_CALL}},
+		{"", IntranetXData{Type: UNKNOWN_EVENT}},
+		{"50000", IntranetXData{Type: UNKNOWN_EVENT}},
+		{`{"type":1,"locale":"en"}`, IntranetXData{Type: W_T_W_EVENT_CALL, Locale: "en"}},
+		{`{"type":1`, IntranetXData{Type: UNKNOWN_EVENT}},
+	}
+	for _, c := range cases {
+		if got := ParseIntranetXData(c.xdata); got != c.want {
+			t.Errorf("ParseIntranetXData(%q) = %+v, want %+v", c.xdata, got, c.want)
+		}
+	}
+	// 未设置语言时保持旧格式
+	if got := (IntranetXData{Type: W_T_W_EVENT_CALL}).String(); got != "1" {
+		t.Errorf("expected legacy format, got %s", got)
+	}
+	xd := IntranetXData{Type: G_T_W_RULE_UPDATE, Locale: "en"}
+	if got := ParseIntranetXData(xd.String()); got != xd {
+		t.Errorf("round trip failed: %+v", got)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/garrickvan/event-matrix/utils/logx"
+)
+
+// JwtHmacConfig JWT_HMAC 类型共享配置的内容
+type JwtHmacConfig struct {
+	Secret string `json:"secret"` // HMAC签名密钥
+}
+
+// JwtSecretFromSharedCfg 从共享配置中解析JWT签名密钥，配置不存在、类型不匹配或解析失败时返回nil
+func JwtSecretFromSharedCfg(sc *core.SharedConfigure) []byte {
+	if sc == nil || sc.Type != core.JWT_HMAC {
+		return nil
+	}
+	cfg := JwtHmacConfig{}
+	if err := jsonx.UnmarshalFromStr(sc.Value, &cfg); err != nil {
+		logx.Debug("解析JWT密钥配置失败: " + err.Error())
+		return nil
+	}
+	if cfg.Secret == "" {
+		return nil
+	}
+	return []byte(cfg.Secret)
+}
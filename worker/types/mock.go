@@ -0,0 +1,773 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/database"
+	"github.com/garrickvan/event-matrix/serverx"
+	"github.com/garrickvan/event-matrix/utils/cachex"
+	"github.com/garrickvan/event-matrix/utils/i18n"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"gorm.io/gorm"
+)
+
+/**
+ * MockWorkerServer 是用于单元测试的 WorkerServer 实现。
+ * 领域缓存数据通过 SetEntityAttrs、SetEntityEvents 等方法直接写入内存，
+ * 数据库统一使用临时目录下的 SQLite，按库名（一般为项目号）首次使用时自动创建，
+ * 不依赖网关和配置中心，便于在测试中直接调用自定义执行器。
+ */
+type MockWorkerServer struct {
+	cfg *WorkerServerConfig
+
+	mu            sync.RWMutex
+	sharedConfigs map[string]*core.SharedConfigure
+	workers       map[string]*Worker
+	workerIds     map[string]struct{}
+	executors     map[string]WorkerExecutor
+	tasks         map[string]WorkerTaskExecutor
+	plugins       map[INTRANET_EVENT_TYPE]PluginWorker
+	intercepts    []Intercept
+	filters       []Filter
+	middlewares   []WorkerMiddleware
+	hooks         []EventProcessedHook
+	eventFilters  []EventFilterEntry
+	registered    []func(*Worker)
+
+	repo             *mockRepository
+	cache            *mockDefaultCache
+	domainCache      *mockDomainCache
+	distributedCache DistributedCache
+}
+
+// NewMockWorkerServer 创建测试用的 WorkerServer，cfg 为空时使用默认配置
+func NewMockWorkerServer(cfg *WorkerServerConfig) *MockWorkerServer {
+	if cfg == nil {
+		cfg = &WorkerServerConfig{ServerId: "mock-worker"}
+	}
+	maxMen, ttl := cfg.DefaultCacheMaxMen, cfg.DefaultCacheTTL
+	if maxMen <= 0 {
+		maxMen = 1 << 20
+	}
+	if ttl <= 0 {
+		ttl = 60
+	}
+	cache := &cachex.LocalCache{}
+	if err := cache.InitCache(maxMen, ttl); err != nil {
+		panic("初始化测试缓存失败: " + err.Error())
+	}
+	return &MockWorkerServer{
+		cfg:           cfg,
+		sharedConfigs: make(map[string]*core.SharedConfigure),
+		workers:       make(map[string]*Worker),
+		workerIds:     make(map[string]struct{}),
+		executors:     make(map[string]WorkerExecutor),
+		tasks:         make(map[string]WorkerTaskExecutor),
+		plugins:       make(map[INTRANET_EVENT_TYPE]PluginWorker),
+		repo:          newMockRepository(),
+		cache:         &mockDefaultCache{cache: cache},
+		domainCache:   newMockDomainCache(cache),
+	}
+}
+
+// SetEntityAttrs 设置实体的属性列表，供 DomainCache().EntityAttrs 返回
+func (m *MockWorkerServer) SetEntityAttrs(path PathToEntity, attrs []core.EntityAttribute) {
+	m.domainCache.setAttrs(path, attrs)
+}
+
+// SetEntityEvents 设置实体的事件列表，供 DomainCache().EntityEvents 及 EntityEvent 返回
+func (m *MockWorkerServer) SetEntityEvents(path PathToEntity, events []core.EntityEvent) {
+	m.domainCache.setEvents(path, events)
+}
+
+// SetEntity 设置实体信息，供 DomainCache().Entity 返回
+func (m *MockWorkerServer) SetEntity(path PathToEntity, entity *core.Entity) {
+	m.domainCache.setEntity(path, entity)
+}
+
+// SetConstants 设置常量字典，供 DomainCache().Constants 返回
+func (m *MockWorkerServer) SetConstants(project, dict string, constants []core.ConstantDict) {
+	m.domainCache.setConstants(project, dict, constants)
+}
+
+// SetSharedConfigure 设置共享配置，供 SharedConfigure 返回
+func (m *MockWorkerServer) SetSharedConfigure(cfg *core.SharedConfigure) {
+	if cfg == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sharedConfigs[cfg.Key] = cfg
+}
+
+// RegisterExecutor 注册自定义执行器
+func (m *MockWorkerServer) RegisterExecutor(name string, executor WorkerExecutor) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.executors[name] = executor
+}
+
+// RegisterTaskExecutor 注册任务执行器
+func (m *MockWorkerServer) RegisterTaskExecutor(name string, executor WorkerTaskExecutor) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tasks[name] = executor
+}
+
+// RegisterInterceptor 注册拦截器
+func (m *MockWorkerServer) RegisterInterceptor(interceptor Intercept) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.intercepts = append(m.intercepts, interceptor)
+}
+
+// RegisterFilter 注册过滤器
+func (m *MockWorkerServer) RegisterFilter(filter Filter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.filters = append(m.filters, filter)
+}
+
+// RegisterMiddleware 注册执行器中间件
+func (m *MockWorkerServer) RegisterMiddleware(mw WorkerMiddleware) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.middlewares = append(m.middlewares, mw)
+}
+
+// RegisterEventProcessedHook 注册事件处理完成回调
+func (m *MockWorkerServer) RegisterEventProcessedHook(hook EventProcessedHook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook)
+}
+
+// OnWorkerRegistered 注册工作者注册成功回调
+func (m *MockWorkerServer) OnWorkerRegistered(hook func(w *Worker)) {
+	if hook == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.registered = append(m.registered, hook)
+}
+
+// RegisterEventFilter 注册跨实体事件订阅
+func (m *MockWorkerServer) RegisterEventFilter(filter core.EventFilter, handler EventFilterHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.eventFilters = append(m.eventFilters, EventFilterEntry{Filter: filter, Handler: handler})
+}
+
+// SetDistributedCache 设置分布式缓存实现
+func (m *MockWorkerServer) SetDistributedCache(c DistributedCache) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.distributedCache = c
+}
+
+func (m *MockWorkerServer) ServerId() string { return m.cfg.ServerId }
+
+func (m *MockWorkerServer) Start() error { return nil }
+
+// Stop 关闭测试数据库并清理临时目录
+func (m *MockWorkerServer) Stop() error { return m.repo.Close() }
+
+func (m *MockWorkerServer) IntranetSecret() string { return m.cfg.IntranetSecret }
+
+func (m *MockWorkerServer) IntranetSecretAlgor() string { return m.cfg.IntranetSecretAlgor }
+
+func (m *MockWorkerServer) GatewayIntranetEndpoint() string { return "" }
+
+func (m *MockWorkerServer) SharedConfigure(sid string) *core.SharedConfigure {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.sharedConfigs[sid]
+}
+
+// JwtSecret 返回配置 JwtCfgKey 对应共享配置中的JWT签名密钥
+func (m *MockWorkerServer) JwtSecret() []byte {
+	if m.cfg.JwtCfgKey == "" {
+		return nil
+	}
+	return JwtSecretFromSharedCfg(m.SharedConfigure(m.cfg.JwtCfgKey))
+}
+
+func (m *MockWorkerServer) GetSharedConfigureChangeHandler() OnSharedConfigureChangeFunc {
+	return nil
+}
+
+// RegisterWorker 仅在本地登记工作者，不会注册到网关
+func (m *MockWorkerServer) RegisterWorker(w *Worker) error {
+	if w == nil {
+		return errors.New("工作者不能为空")
+	}
+	m.mu.Lock()
+	m.workers[w.GetVersionEntityLabel()] = w
+	m.workerIds[w.ID] = struct{}{}
+	hooks := m.registered
+	m.mu.Unlock()
+	for _, hook := range hooks {
+		hook(w)
+	}
+	return nil
+}
+
+func (m *MockWorkerServer) HasWorker(workerId string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.workerIds[workerId]
+	return ok
+}
+
+func (m *MockWorkerServer) GetWorkerByEvent(p PathToEntity) *Worker {
+	w := Worker{Project: p.Project, VersionLabel: p.Version, Context: p.Context, Entity: p.Entity}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.workers[w.GetVersionEntityLabel()]
+}
+
+func (m *MockWorkerServer) FindWorkerExecutor(name string) (WorkerExecutor, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	executor, ok := m.executors[name]
+	return executor, ok
+}
+
+func (m *MockWorkerServer) FindWorkerTaskExecutor(name string) (WorkerTaskExecutor, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	executor, ok := m.tasks[name]
+	return executor, ok
+}
+
+func (m *MockWorkerServer) RegisterPlugin(plugin PluginWorker) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, code := range plugin.ReceiveCodes() {
+		m.plugins[code] = plugin
+	}
+}
+
+func (m *MockWorkerServer) FindPlugin(pluginType INTRANET_EVENT_TYPE) (PluginWorker, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	plugin, ok := m.plugins[pluginType]
+	return plugin, ok
+}
+
+func (m *MockWorkerServer) PluginsHealth() map[string]PluginHealth {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make(map[string]PluginHealth)
+	for _, plugin := range m.plugins {
+		name := fmt.Sprintf("%T", plugin)
+		if _, checked := result[name]; checked {
+			continue
+		}
+		result[name] = plugin.HealthCheck()
+	}
+	return result
+}
+
+func (m *MockWorkerServer) Intercepts() []Intercept {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.intercepts
+}
+
+func (m *MockWorkerServer) Filters() []Filter {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.filters
+}
+
+func (m *MockWorkerServer) Middlewares() []WorkerMiddleware {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.middlewares
+}
+
+func (m *MockWorkerServer) EventProcessedHooks() []EventProcessedHook {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.hooks
+}
+
+func (m *MockWorkerServer) EventFilters() []EventFilterEntry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.eventFilters
+}
+
+// RuleEngineMgr 测试环境不提供规则引擎，返回nil
+func (m *MockWorkerServer) RuleEngineMgr() RuleEngineManager { return nil }
+
+func (m *MockWorkerServer) Repo() Repository { return m.repo }
+
+func (m *MockWorkerServer) Cache() DefaultCache { return m.cache }
+
+func (m *MockWorkerServer) DomainCache() DomainCache { return m.domainCache }
+
+func (m *MockWorkerServer) DistributedCache() DistributedCache {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.distributedCache
+}
+
+// ExportSwaggerSpec 使用内存中的领域数据导出 OpenAPI 3.0 文档
+func (m *MockWorkerServer) ExportSwaggerSpec(workerID string) ([]byte, error) {
+	m.mu.RLock()
+	workers := make([]*Worker, 0, len(m.workers))
+	for _, w := range m.workers {
+		if workerID == "" || w.ID == workerID {
+			workers = append(workers, w)
+		}
+	}
+	m.mu.RUnlock()
+	if workerID != "" && len(workers) == 0 {
+		return nil, errors.New("工作者不存在: " + workerID)
+	}
+	return BuildOpenAPISpec(m.cfg.ServerId, m.cfg.Version, m.domainCache, workers)
+}
+
+// mockDefaultCache 默认缓存的测试实现，加载器同步调用，不做并发合并
+type mockDefaultCache struct {
+	cache   *cachex.LocalCache
+	loaders sync.Map
+}
+
+func (c *mockDefaultCache) Impl() *cachex.LocalCache { return c.cache }
+
+func (c *mockDefaultCache) Get(key string) (interface{}, bool) {
+	if data, ok := c.cache.Get(key); ok && data != nil {
+		return data, true
+	}
+	label, bizKey, ok := SplitReadThroughCacheKey(key)
+	if !ok {
+		return nil, false
+	}
+	fn, has := c.loaders.Load(label)
+	if !has {
+		return nil, false
+	}
+	data, err := fn.(CacheLoader)(bizKey)
+	if err != nil || data == nil {
+		return nil, false
+	}
+	c.cache.Put(key, data)
+	return data, true
+}
+
+func (c *mockDefaultCache) RegisterLoader(entityLabel string, fn CacheLoader) {
+	if fn == nil {
+		c.loaders.Delete(entityLabel)
+		return
+	}
+	c.loaders.Store(entityLabel, fn)
+}
+
+// mockDomainCache 领域缓存的测试实现，数据全部保存在内存中
+type mockDomainCache struct {
+	mu        sync.RWMutex
+	cache     *cachex.LocalCache
+	entities  map[string]*core.Entity
+	attrs     map[string][]core.EntityAttribute
+	events    map[string][]core.EntityEvent
+	constants map[string][]core.ConstantDict
+}
+
+func newMockDomainCache(cache *cachex.LocalCache) *mockDomainCache {
+	return &mockDomainCache{
+		cache:     cache,
+		entities:  make(map[string]*core.Entity),
+		attrs:     make(map[string][]core.EntityAttribute),
+		events:    make(map[string][]core.EntityEvent),
+		constants: make(map[string][]core.ConstantDict),
+	}
+}
+
+func (dc *mockDomainCache) setEntity(path PathToEntity, entity *core.Entity) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.entities[path.ToStrArg()] = entity
+}
+
+func (dc *mockDomainCache) setAttrs(path PathToEntity, attrs []core.EntityAttribute) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.attrs[path.ToStrArg()] = attrs
+}
+
+func (dc *mockDomainCache) setEvents(path PathToEntity, events []core.EntityEvent) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.events[path.ToStrArg()] = events
+}
+
+func (dc *mockDomainCache) setConstants(project, dict string, constants []core.ConstantDict) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.constants[project+constant.SPLIT_CHAR+dict] = constants
+}
+
+func (dc *mockDomainCache) Constants(project, dict string) []core.ConstantDict {
+	dc.mu.RLock()
+	defer dc.mu.RUnlock()
+	return dc.constants[project+constant.SPLIT_CHAR+dict]
+}
+
+func (dc *mockDomainCache) Entity(e PathToEntity) *core.Entity {
+	dc.mu.RLock()
+	defer dc.mu.RUnlock()
+	return dc.entities[e.ToStrArg()]
+}
+
+func (dc *mockDomainCache) EntityEvent(e PathToEvent) *core.EntityEvent {
+	for _, ev := range dc.EntityEvents(PathToEntityFromPathToEvent(e)) {
+		if ev.Code == e.Event {
+			event := ev
+			return &event
+		}
+	}
+	return nil
+}
+
+func (dc *mockDomainCache) EntityEvents(e PathToEntity) []core.EntityEvent {
+	dc.mu.RLock()
+	defer dc.mu.RUnlock()
+	return dc.events[e.ToStrArg()]
+}
+
+func (dc *mockDomainCache) EntityAttrs(e PathToEntity) []core.EntityAttribute {
+	dc.mu.RLock()
+	defer dc.mu.RUnlock()
+	return dc.attrs[e.ToStrArg()]
+}
+
+func (dc *mockDomainCache) Invalidate(key string) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	delete(dc.entities, key)
+	delete(dc.attrs, key)
+	delete(dc.events, key)
+	delete(dc.constants, key)
+	dc.cache.Del(key)
+}
+
+func (dc *mockDomainCache) InvalidateConstants(project, dict string) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	for key := range dc.constants {
+		keyProject, keyDict, _ := strings.Cut(key, constant.SPLIT_CHAR)
+		if (project == "" || keyProject == project) && (dict == "" || keyDict == dict) {
+			delete(dc.constants, key)
+		}
+	}
+}
+
+func (dc *mockDomainCache) Impl() *cachex.LocalCache { return dc.cache }
+
+/**
+ * mockRepository 仓库的测试实现。
+ * 所有数据库均为临时目录下的 SQLite 文件，Use 时不存在则自动创建，
+ * SyncSchema 不做任何处理，测试需自行建表。
+ */
+type mockRepository struct {
+	*database.GormDBManager
+
+	mu        sync.Mutex
+	dir       string
+	parsers   sync.Map
+	closeOnce sync.Once
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{GormDBManager: database.NewGormDBManager()}
+}
+
+// Use 获取数据库连接，不存在时自动创建 SQLite 数据库
+func (r *mockRepository) Use(name string) *gorm.DB {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.GormDBManager.HasDB(name) {
+		if r.dir == "" {
+			dir, err := os.MkdirTemp("", "event-matrix-mock-*")
+			if err != nil {
+				return nil
+			}
+			r.dir = dir
+		}
+		err := r.GormDBManager.RegisterDB(&database.DBConf{
+			Type:     database.SQLITE,
+			Location: r.dir,
+			DBName:   name,
+		})
+		if err != nil {
+			return nil
+		}
+	}
+	return r.GormDBManager.Use(name)
+}
+
+// HasDB 测试仓库总是可以按需创建数据库
+func (r *mockRepository) HasDB(name string) bool { return true }
+
+func (r *mockRepository) AddDBFromSharedConfig(sid string) error {
+	if r.Use(sid) == nil {
+		return errors.New("创建测试数据库失败: " + sid)
+	}
+	return nil
+}
+
+func (r *mockRepository) SyncSchema(w *Worker) error { return nil }
+
+// MigrateData 在测试数据库间迁移数据，数据库不存在时自动创建
+func (r *mockRepository) MigrateData(src, dst PathToEntity, transform database.MigrateTransform, deleteSrc bool) error {
+	_, err := database.MigrateTableData(r.Use(src.Project), src.TableName(), r.Use(dst.Project), dst.TableName(), transform, deleteSrc)
+	return err
+}
+
+func (r *mockRepository) RegisterCustomFieldParser(parser CustomFieldParser) {
+	if parser == nil {
+		return
+	}
+	r.parsers.Store(parser.FieldParserName(), parser)
+}
+
+func (r *mockRepository) GetCustomFieldParser(fieldType string) (CustomFieldParser, bool) {
+	v, ok := r.parsers.Load(fieldType)
+	if !ok {
+		return nil, false
+	}
+	return v.(CustomFieldParser), true
+}
+
+// Close 关闭所有数据库并删除临时目录
+func (r *mockRepository) Close() error {
+	var err error
+	r.closeOnce.Do(func() { err = r.GormDBManager.Close() })
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.dir != "" {
+		os.RemoveAll(r.dir)
+		r.dir = ""
+	}
+	return err
+}
+
+/**
+ * MockRequestContext 是用于单元测试的 WorkerContext 实现。
+ * 响应内容记录在上下文中，可通过 StatusCode、ResponseBody 读取。
+ * ValidatedParams 默认只做参数解析，不做校验；需要完整校验时可设置 ParamsValidator，
+ * 如 common.ParseAndValidateParams。
+ */
+type MockRequestContext struct {
+	// ParamsValidator 自定义参数解析校验方法，为空时使用简单解析
+	ParamsValidator func(ctx WorkerContext) ([]core.EntityAttribute, []core.EventParam, map[string]interface{}, *jsonx.JsonResponse)
+
+	svr         WorkerServer
+	event       *core.Event
+	entityEvent *core.EntityEvent
+	uid         string
+	ip          string
+	callChain   []string
+	data        interface{}
+	traceCtx    context.Context
+
+	reqHeaders  map[string]string
+	respHeaders map[string]string
+	status      int
+	body        []byte
+	streamed    bool
+}
+
+// NewMockRequestContext 创建测试用的请求上下文，实体事件从服务器的领域缓存中查找
+func NewMockRequestContext(svr WorkerServer, event *core.Event) *MockRequestContext {
+	ctx := &MockRequestContext{
+		svr:         svr,
+		event:       event,
+		ip:          "127.0.0.1",
+		reqHeaders:  make(map[string]string),
+		respHeaders: make(map[string]string),
+		status:      200,
+	}
+	if svr != nil && event != nil {
+		ctx.entityEvent = svr.DomainCache().EntityEvent(PathToEventFromEvent(event))
+	}
+	return ctx
+}
+
+// SetUserId 设置当前请求的用户ID
+func (c *MockRequestContext) SetUserId(uid string) { c.uid = uid }
+
+// SetRequestHeader 设置请求头
+func (c *MockRequestContext) SetRequestHeader(key, value string) { c.reqHeaders[key] = value }
+
+// SetEntityEvent 直接指定实体事件，覆盖从领域缓存查找的结果
+func (c *MockRequestContext) SetEntityEvent(e *core.EntityEvent) { c.entityEvent = e }
+
+// SetCallChain 设置调用链
+func (c *MockRequestContext) SetCallChain(chain []string) { c.callChain = chain }
+
+// StatusCode 返回已设置的响应状态码
+func (c *MockRequestContext) StatusCode() int { return c.status }
+
+// ResponseBody 返回已写入的响应内容
+func (c *MockRequestContext) ResponseBody() []byte { return c.body }
+
+// ResponseHeader 返回已设置的响应头
+func (c *MockRequestContext) ResponseHeader(key string) string { return c.respHeaders[key] }
+
+func (c *MockRequestContext) ValidatedParams() (
+	entityAttrs []core.EntityAttribute,
+	entityEventParams []core.EventParam,
+	params map[string]interface{},
+	result *jsonx.JsonResponse,
+) {
+	if c.ParamsValidator != nil {
+		return c.ParamsValidator(c)
+	}
+	if c.event == nil {
+		return nil, nil, nil, jsonx.DefaultJson(constant.EVENT_NOT_EXIST)
+	}
+	if c.entityEvent == nil {
+		return nil, nil, nil, jsonx.DefaultJson(constant.ENTITY_NOT_EXIST)
+	}
+	entityAttrs = c.svr.DomainCache().EntityAttrs(PathToEntityFromEvent(c.event))
+	params = map[string]interface{}{}
+	if c.event.Params != "" {
+		if err := jsonx.UnmarshalFromStr(c.event.Params, &params); err != nil {
+			return nil, nil, nil, jsonx.DefaultJson(constant.INVALID_PARAM)
+		}
+	}
+	entityEventParams = []core.EventParam{}
+	if c.entityEvent.Params != "" {
+		if err := jsonx.UnmarshalFromStr(c.entityEvent.Params, &entityEventParams); err != nil {
+			return nil, nil, nil, jsonx.DefaultJson(constant.INVALID_PARAM)
+		}
+	}
+	return entityAttrs, entityEventParams, params, nil
+}
+
+func (c *MockRequestContext) UserId() string { return c.uid }
+
+// Locale 返回请求头 Accept-Language 指定的语言
+func (c *MockRequestContext) Locale() string {
+	return i18n.NormalizeLocale(c.reqHeaders["Accept-Language"])
+}
+
+func (c *MockRequestContext) Server() WorkerServer { return c.svr }
+
+// TraceContext 返回当前的链路追踪上下文，首次使用时从请求头提取，存在未结束的 Span 时以该 Span 为当前 Span
+func (c *MockRequestContext) TraceContext() context.Context {
+	if c.traceCtx == nil {
+		c.traceCtx = ExtractTraceContext(c)
+	}
+	return c.traceCtx
+}
+
+func (c *MockRequestContext) Span(name string) Span {
+	c.TraceContext()
+	return StartSpan(&c.traceCtx, name)
+}
+
+func (c *MockRequestContext) IP() string { return c.ip }
+
+func (c *MockRequestContext) Path() string { return "" }
+
+func (c *MockRequestContext) Body() []byte {
+	if c.event == nil {
+		return nil
+	}
+	return []byte(c.event.Params)
+}
+
+func (c *MockRequestContext) SetStatus(status int) serverx.RequestContext {
+	c.status = status
+	return c
+}
+
+func (c *MockRequestContext) BodyType() serverx.CONTENT_TYPE { return serverx.CONTENT_TYPE_JSON }
+
+func (c *MockRequestContext) IsJsonBody() bool { return true }
+
+func (c *MockRequestContext) Header(key string) string { return c.reqHeaders[key] }
+
+func (c *MockRequestContext) SetHeader(key, value string) { c.respHeaders[key] = value }
+
+func (c *MockRequestContext) Response(bytes []byte) error {
+	c.body = bytes
+	return nil
+}
+
+func (c *MockRequestContext) ResponseString(s string) error {
+	c.body = []byte(s)
+	return nil
+}
+
+func (c *MockRequestContext) ResponseJson(v interface{}) error {
+	b, err := jsonx.MarshalToBytes(v)
+	if err != nil {
+		return err
+	}
+	c.body = b
+	return nil
+}
+
+// BatchResponse 将逐行序列化的NDJSON写入响应体
+func (c *MockRequestContext) BatchResponse(iter func(yield func(row map[string]interface{}) bool)) error {
+	var err error
+	iter(func(row map[string]interface{}) bool {
+		line, e := jsonx.MarshalToBytes(row)
+		if e != nil {
+			err = e
+			return false
+		}
+		c.body = append(append(c.body, line...), '\n')
+		return true
+	})
+	c.streamed = true
+	return err
+}
+
+// Streamed 返回是否已通过 BatchResponse 写出响应
+func (c *MockRequestContext) Streamed() bool { return c.streamed }
+
+func (c *MockRequestContext) ResponseBuiltinJson(code constant.RESPONSE_CODE) error {
+	c.body = []byte(jsonx.GetStaticJsonResponseStr(code))
+	return nil
+}
+
+func (c *MockRequestContext) Event() *core.Event { return c.event }
+
+func (c *MockRequestContext) EntityEvent() *core.EntityEvent { return c.entityEvent }
+
+func (c *MockRequestContext) CallChain() []string { return c.callChain }
+
+func (c *MockRequestContext) Data() interface{} { return c.data }
+
+func (c *MockRequestContext) SetData(data interface{}) { c.data = data }
+
+func (c *MockRequestContext) CtxImpl() interface{} { return c }
@@ -0,0 +1,184 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/garrickvan/event-matrix/constant"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+)
+
+// OPENAPI_VERSION 导出接口文档使用的 OpenAPI 版本
+const OPENAPI_VERSION = "3.0.3"
+
+/**
+ * BuildOpenAPISpec 根据工作者及其实体事件生成 OpenAPI 3.0 文档。
+ * 每个事件对应一个 POST 路径，格式为 /{project}/{context}/{entity}/{event}，
+ * 请求体为事件参数的 JSON Schema，响应体为 JsonResponse，list 元素为实体的非保密属性。
+ * @param title 文档标题
+ * @param version 文档版本
+ * @param dc 领域缓存，用于获取实体事件和属性
+ * @param workers 需要导出的工作者列表
+ * @return []byte JSON格式的文档
+ */
+func BuildOpenAPISpec(title, version string, dc DomainCache, workers []*Worker) ([]byte, error) {
+	if title == "" {
+		title = "event-matrix"
+	}
+	if version == "" {
+		version = constant.INITIAL_VERSION
+	}
+	sorted := make([]*Worker, 0, len(workers))
+	for _, w := range workers {
+		if w != nil {
+			sorted = append(sorted, w)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].GetVersionEntityLabel() < sorted[j].GetVersionEntityLabel()
+	})
+
+	paths := map[string]interface{}{}
+	tags := []interface{}{}
+	for _, w := range sorted {
+		p := PathToEntityFromWorker(w)
+		tag := w.Project + "." + w.Context + "." + w.Entity
+		tagInfo := map[string]interface{}{"name": tag}
+		if entity := dc.Entity(p); entity != nil && entity.Description != "" {
+			tagInfo["description"] = entity.Description
+		}
+		tags = append(tags, tagInfo)
+
+		listItem := entityListSchema(dc, p)
+		events := dc.EntityEvents(p)
+		sort.Slice(events, func(i, j int) bool { return events[i].Code < events[j].Code })
+		for _, e := range events {
+			if e.DeletedAt != 0 || e.Code == "" {
+				continue
+			}
+			path := "/" + strings.Join([]string{w.Project, w.Context, w.Entity, e.Code}, "/")
+			op := map[string]interface{}{
+				"tags":        []string{tag},
+				"operationId": strings.Join([]string{w.Project, w.Context, w.Entity, e.Code}, "_"),
+				"requestBody": map[string]interface{}{
+					"required": true,
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{"schema": toOpenAPI30Schema(e.ToParamSchema())},
+					},
+				},
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "请求结果",
+						"content": map[string]interface{}{
+							"application/json": map[string]interface{}{
+								"schema": map[string]interface{}{
+									"allOf": []interface{}{
+										map[string]interface{}{"$ref": "#/components/schemas/JsonResponse"},
+										map[string]interface{}{
+											"type": "object",
+											"properties": map[string]interface{}{
+												"list": map[string]interface{}{"type": "array", "items": listItem},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			if e.Name != "" {
+				op["summary"] = e.Name
+			}
+			if e.Description != "" {
+				op["description"] = e.Description
+			}
+			paths[path] = map[string]interface{}{"post": op}
+		}
+	}
+
+	spec := map[string]interface{}{
+		"openapi": OPENAPI_VERSION,
+		"info": map[string]interface{}{
+			"title":   title,
+			"version": version,
+		},
+		"tags":  tags,
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": map[string]interface{}{
+				"JsonResponse": jsonResponseSchema(),
+			},
+		},
+	}
+	return jsonx.MarshalToBytes(spec)
+}
+
+// entityListSchema 生成实体数据的 Schema，保密字段不会在查询结果中返回，因此不导出
+func entityListSchema(dc DomainCache, p PathToEntity) map[string]interface{} {
+	properties := map[string]interface{}{}
+	for _, attr := range dc.EntityAttrs(p) {
+		if attr.IsSecrecy || attr.Code == "" {
+			continue
+		}
+		properties[attr.Code] = toOpenAPI30Schema(attr.ToJSONSchema())
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+}
+
+// toOpenAPI30Schema 将 JSON Schema 转换为 OpenAPI 3.0 的写法，
+// OpenAPI 3.0 中 exclusiveMinimum/exclusiveMaximum 为布尔值，需要配合 minimum/maximum 使用
+func toOpenAPI30Schema(schema map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(schema))
+	for k, v := range schema {
+		switch k {
+		case "exclusiveMinimum":
+			result["minimum"] = v
+			result[k] = true
+		case "exclusiveMaximum":
+			result["maximum"] = v
+			result[k] = true
+		default:
+			if sub, ok := v.(map[string]interface{}); ok {
+				result[k] = toOpenAPI30Schema(sub)
+			} else {
+				result[k] = v
+			}
+		}
+	}
+	return result
+}
+
+// jsonResponseSchema 与 jsonx.JsonResponse 的结构保持一致
+func jsonResponseSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"code":      map[string]interface{}{"type": "string"},
+			"createdAt": map[string]interface{}{"type": "integer", "format": "int64"},
+			"message":   map[string]interface{}{"type": "string"},
+			"list":      map[string]interface{}{"type": "array", "items": map[string]interface{}{}},
+			"total":     map[string]interface{}{"type": "integer", "format": "int64"},
+			"size":      map[string]interface{}{"type": "integer"},
+			"page":      map[string]interface{}{"type": "integer"},
+		},
+		"required": []string{"code", "message"},
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"context"
+	"testing"
+
+	"github.com/garrickvan/event-matrix/core"
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func newSwaggerTestServer(t *testing.T) (*MockWorkerServer, *Worker) {
+	t.Helper()
+	ws := NewMockWorkerServer(&WorkerServerConfig{ServerId: "swagger-test", Version: "1.2.0"})
+	t.Cleanup(func() { ws.Stop() })
+
+	w := &Worker{ID: "w1", Project: "demo", VersionLabel: "v1", Context: "shop", Entity: "user"}
+	if err := ws.RegisterWorker(w); err != nil {
+		t.Fatal(err)
+	}
+	p := PathToEntityFromWorker(w)
+	ws.SetEntity(p, &core.Entity{Name: "用户", Description: "商城用户"})
+	ws.SetEntityAttrs(p, []core.EntityAttribute{
+		{Code: "id", Name: "ID", FieldType: "id"},
+		{Code: "name", Name: "名称", FieldType: "string"},
+		{Code: "age", Name: "年龄", FieldType: "int32", DefaultValue: "18"},
+		{Code: "password", Name: "密码", FieldType: "string", IsSecrecy: true},
+	})
+	ws.SetEntityEvents(p, []core.EntityEvent{
+		{
+			Code:   "create",
+			Name:   "创建用户",
+			Params: `[{"name":"name","type":"string","range":"length","rangeValue":"1,32","required":true},{"name":"age","type":"int32","range":"range","rangeValue":"0,150"}]`,
+		},
+		{
+			Code:   "query",
+			Name:   "查询用户",
+			Params: `[{"name":"page","type":"int32","range":"gte","rangeValue":"1","required":true},{"name":"page_size","type":"int32","range":"eq_range","rangeValue":"1,100","required":true},{"name":"name","type":"and_query","range":"r_like"}]`,
+		},
+		{Code: "removed", DeletedAt: 1},
+	})
+	return ws, w
+}
+
+func TestExportSwaggerSpecIsValidOpenAPI(t *testing.T) {
+	ws, _ := newSwaggerTestServer(t)
+
+	data, err := ws.ExportSwaggerSpec("")
+	if err != nil {
+		t.Fatalf("导出文档失败: %v", err)
+	}
+	doc, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		t.Fatalf("文档解析失败: %v", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatalf("文档不符合 OpenAPI 3.0 规范: %v\n%s", err, data)
+	}
+	if doc.Info.Title != "swagger-test" || doc.Info.Version != "1.2.0" {
+		t.Errorf("文档信息错误: %+v", doc.Info)
+	}
+	if doc.Paths.Len() != 2 {
+		t.Fatalf("期望2个路径，实际 %d", doc.Paths.Len())
+	}
+
+	create := doc.Paths.Find("/demo/shop/user/create")
+	if create == nil || create.Post == nil {
+		t.Fatal("缺少创建事件的路径")
+	}
+	body := create.Post.RequestBody.Value.Content.Get("application/json").Schema.Value
+	if len(body.Required) != 1 || body.Required[0] != "name" {
+		t.Errorf("必填参数错误: %v", body.Required)
+	}
+	age := body.Properties["age"].Value
+	if age.Min == nil || *age.Min != 0 || !age.ExclusiveMin || age.Max == nil || *age.Max != 150 || !age.ExclusiveMax {
+		t.Errorf("开区间约束转换错误: %+v", age)
+	}
+
+	resp := create.Post.Responses.Status(200).Value.Content.Get("application/json").Schema.Value
+	if len(resp.AllOf) != 2 || resp.AllOf[0].Ref != "#/components/schemas/JsonResponse" {
+		t.Fatalf("响应结构错误: %+v", resp)
+	}
+	item := resp.AllOf[1].Value.Properties["list"].Value.Items.Value
+	if _, ok := item.Properties["password"]; ok {
+		t.Error("保密字段不应出现在响应结构中")
+	}
+	if _, ok := item.Properties["name"]; !ok {
+		t.Error("响应结构缺少实体属性")
+	}
+}
+
+func TestExportSwaggerSpecByWorker(t *testing.T) {
+	ws, w := newSwaggerTestServer(t)
+
+	data, err := ws.ExportSwaggerSpec(w.ID)
+	if err != nil {
+		t.Fatalf("导出文档失败: %v", err)
+	}
+	spec := jsonx.UnmarshalToMap(string(data))
+	if spec["openapi"] != OPENAPI_VERSION {
+		t.Errorf("OpenAPI 版本错误: %v", spec["openapi"])
+	}
+
+	if _, err := ws.ExportSwaggerSpec("not-exist"); err == nil {
+		t.Error("不存在的工作者应返回错误")
+	}
+}
@@ -42,6 +42,12 @@ type Repository interface {
 	// 参数 fieldType 是自定义字段的类型。
 	// 返回值 cf 是匹配的自定义字段解析器，ok 表示是否找到对应的解析器。
 	GetCustomFieldParser(fieldType string) (cf CustomFieldParser, ok bool)
+
+	// MigrateData 将源实体表的数据分页迁移到目标实体表，用于字段重命名或类型变更后的数据搬迁。
+	// 参数 transform 用于转换每一行数据，为 nil 时原样写入，返回 nil 时跳过该行。
+	// 参数 deleteSrc 为 true 时每页写入成功后删除源表中对应的行。
+	// 源库和目标库各在一个事务中执行，任意一页失败时整体回滚。
+	MigrateData(src, dst PathToEntity, transform database.MigrateTransform, deleteSrc bool) error
 }
 
 /*
@@ -62,4 +68,5 @@ type CustomFieldParser interface {
 	CreateColumn(db *gorm.DB, tableName, columnName string) error // 必选, 创建字段
 	DefaultValue() interface{}                                    // 可选, 默认值，不设置则使用nil
 	Description() string                                          // 可选, 字段描述
+	Format(value interface{}) string                              // 可选, 查询结果的展示格式，不需要时返回 cast.ToString(value)
 }
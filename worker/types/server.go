@@ -56,11 +56,19 @@ type WorkerServer interface {
 	RegisterPlugin(plugin PluginWorker)
 	// FindPlugin 根据事件类型查找插件工作实例。
 	FindPlugin(pluginType INTRANET_EVENT_TYPE) (PluginWorker, bool)
+	// PluginsHealth 返回所有已注册插件的健康状态，key为插件类型名称。
+	PluginsHealth() map[string]PluginHealth
 
 	// Intercepts 返回所有拦截器列表。
 	Intercepts() []Intercept
 	// Filters 返回所有过滤器列表。
 	Filters() []Filter
+	// Middlewares 返回所有执行器中间件列表，按注册顺序由外到内调用。
+	Middlewares() []WorkerMiddleware
+	// EventProcessedHooks 返回所有事件处理完成回调列表。
+	EventProcessedHooks() []EventProcessedHook
+	// EventFilters 返回所有跨实体事件订阅列表。
+	EventFilters() []EventFilterEntry
 
 	// RuleEngineMgr 返回规则引擎管理器。
 	RuleEngineMgr() RuleEngineManager
@@ -70,6 +78,11 @@ type WorkerServer interface {
 	Cache() DefaultCache
 	// DomainCache 返回域名缓存实例。
 	DomainCache() DomainCache
+	// DistributedCache 返回分布式缓存实例，未安装缓存插件时返回nil。
+	DistributedCache() DistributedCache
+
+	// ExportSwaggerSpec 根据已注册的工作者及其实体事件生成 OpenAPI 3.0 文档，workerID 为空时导出全部工作者。
+	ExportSwaggerSpec(workerID string) ([]byte, error)
 }
 
 // WorkerContext 定义了工作上下文的核心接口。
@@ -88,8 +101,15 @@ type WorkerContext interface {
 	// UserId 返回用户ID。
 	UserId() string
 
+	// Locale 返回请求的语言，用于本地化提示信息，未指定时返回默认语言。
+	Locale() string
+
 	// WorkerServer 返回工作服务器实例。
 	Server() WorkerServer
+
+	// Span 创建链路追踪片段，父上下文来自请求的 traceparent 或未结束的 Span，调用链作为 Baggage 传递，使用完毕后须调用 End。
+	// Span 结束前，内域调用向下游传递的链路追踪上下文以该 Span 为父级。
+	Span(name string) Span
 }
 
 /**
@@ -123,6 +143,48 @@ type Intercept func(wc WorkerContext) (stop bool)
  */
 type Filter func(wc WorkerContext, r *jsonx.JsonResponse) (stop bool)
 
+/**
+ * WorkerMiddleware 是执行器中间件的类型定义。
+ * 中间件包裹执行器调用，用于以可组合的方式实现认证、审计日志、链路追踪等横切逻辑。
+ * @param wc WorkerContext 工作上下文
+ * @param next func() (*jsonx.JsonResponse, int) 调用下一个中间件或执行器，不调用时直接返回中间件的结果
+ * @return *jsonx.JsonResponse JSON响应结果
+ * @return int HTTP状态码
+ */
+type WorkerMiddleware func(wc WorkerContext, next func() (*jsonx.JsonResponse, int)) (*jsonx.JsonResponse, int)
+
+/**
+ * EventProcessedHook 是事件处理完成回调的类型定义。
+ * 回调在执行器返回后异步调用，用于对接自定义的监控系统。
+ * @param event *core.Event 已处理的事件
+ * @param statusCode int 响应状态码，同HTTP协议
+ * @param durationMs int64 处理耗时，单位毫秒
+ */
+type EventProcessedHook func(event *core.Event, statusCode int, durationMs int64)
+
+/**
+ * EventFilterHandler 是跨实体事件处理方法的类型定义。
+ * 源事件执行成功后同步调用，上下文中的事件为源事件，处理失败只记录日志，不影响源事件的响应。
+ * @param wc WorkerContext 工作上下文
+ * @return error 处理错误
+ */
+type EventFilterHandler func(wc WorkerContext) error
+
+// EventFilterEntry 已注册的跨实体事件订阅
+type EventFilterEntry struct {
+	Filter  core.EventFilter   // 订阅条件
+	Handler EventFilterHandler // 处理方法
+}
+
+// EventHookSnapshot 内置执行器成功回调收到的事件快照，与请求上下文分离，回调异步执行时请求可能已经结束
+type EventHookSnapshot struct {
+	Event       *core.Event         // 事件副本
+	EntityEvent *core.EntityEvent   // 实体事件副本
+	UserId      string              // 发起请求的用户ID
+	Response    *jsonx.JsonResponse // 执行器响应的副本
+	StatusCode  int                 // 执行器返回的状态码
+}
+
 // RuleFunc 自定义规则函数
 type RuleFunc func(ctx types.RuleContext, msg types.RuleMsg, ws WorkerServer)
 
@@ -153,9 +215,33 @@ type PluginWorker interface {
 	// 该列表用于确定插件能够处理哪些类型的事件。
 	ReceiveCodes() []INTRANET_EVENT_TYPE
 
+	// Dependencies 返回插件依赖的内部事件类型列表。
+	// 依赖的事件类型需由其他插件的 ReceiveCodes 提供，批量安装时会按依赖顺序调用 Setup。
+	Dependencies() []INTRANET_EVENT_TYPE
+
 	// Handle 处理接收到的内部事件。
 	// ctx 是工作上下文，提供了与当前事件相关的上下文信息。
 	// typz 是事件的类型，用于确定如何处理该事件。
 	// 返回一个错误，如果处理过程中发生错误，则返回非 nil 的错误。
 	Handle(ctx WorkerContext, typz INTRANET_EVENT_TYPE) error
+
+	// Shutdown 关闭插件，释放插件持有的后台任务和资源。
+	// 工作服务器停止时会依次调用，返回一个错误，如果关闭过程中发生错误，则返回非 nil 的错误。
+	Shutdown() error
+
+	// HealthCheck 检查插件的健康状态。
+	// 网关检查工作端时会汇总所有插件的健康状态。
+	HealthCheck() PluginHealth
+}
+
+// SharedConfigureWatcher 关注共享配置变更的插件，网关推送共享配置变更时调用
+type SharedConfigureWatcher interface {
+	OnSharedConfigureChange(cfg *core.SharedConfigure) error
+}
+
+// PluginHealth 插件健康状态
+type PluginHealth struct {
+	Healthy bool                   `json:"healthy"`           // 是否健康
+	Message string                 `json:"message"`           // 状态说明
+	Details map[string]interface{} `json:"details,omitempty"` // 详细指标
 }
@@ -0,0 +1,135 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"context"
+	"strings"
+
+	"github.com/garrickvan/event-matrix/serverx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// TRACER_NAME 创建 Span 使用的 Tracer 名称
+	TRACER_NAME = "github.com/garrickvan/event-matrix/worker"
+	// TRACEPARENT_HEADER W3C 链路追踪上下文请求头
+	TRACEPARENT_HEADER = "traceparent"
+	// TRACESTATE_HEADER W3C 链路追踪状态请求头
+	TRACESTATE_HEADER = "tracestate"
+	// BAGGAGE_HEADER W3C Baggage 请求头
+	BAGGAGE_HEADER = "baggage"
+	// CALL_CHAIN_BAGGAGE_KEY 调用链在 Baggage 中的键，值为以逗号分隔的事件标签
+	CALL_CHAIN_BAGGAGE_KEY = "event_matrix.call_chain"
+)
+
+// traceHeaders 链路追踪使用的请求头
+var traceHeaders = []string{TRACEPARENT_HEADER, TRACESTATE_HEADER, BAGGAGE_HEADER}
+
+// tracePropagator 按 W3C 规范提取和注入链路追踪上下文及 Baggage
+var tracePropagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// Span 链路追踪的一个片段，由 WorkerContext.Span 创建，使用完毕后必须调用 End
+type Span interface {
+	// End 结束 Span
+	End()
+	// SetAttribute 设置 Span 的属性
+	SetAttribute(k, v string)
+	// RecordError 记录错误，并将 Span 标记为失败
+	RecordError(err error)
+}
+
+// otelSpan 基于 OpenTelemetry 的 Span 实现，未配置 TracerProvider 时不产生任何数据
+type otelSpan struct {
+	span trace.Span
+	end  func() // Span 结束时恢复父上下文
+}
+
+func (s *otelSpan) End() {
+	s.span.End()
+	if s.end != nil {
+		s.end()
+	}
+}
+
+func (s *otelSpan) SetAttribute(k, v string) { s.span.SetAttributes(attribute.String(k, v)) }
+
+func (s *otelSpan) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+// requestCarrier 从请求上下文的请求头读取链路追踪上下文
+type requestCarrier struct {
+	req serverx.RequestContext
+}
+
+func (c requestCarrier) Get(key string) string { return c.req.Header(key) }
+
+func (c requestCarrier) Set(key, value string) {}
+
+func (c requestCarrier) Keys() []string { return traceHeaders }
+
+// ExtractTraceContext 从请求的 traceparent、tracestate 和 baggage 请求头提取链路追踪上下文，
+// 并将请求的调用链加入 Baggage
+func ExtractTraceContext(req serverx.RequestContext) context.Context {
+	ctx := tracePropagator.Extract(context.Background(), requestCarrier{req: req})
+	chain := req.CallChain()
+	if len(chain) == 0 {
+		return ctx
+	}
+	member, err := baggage.NewMemberRaw(CALL_CHAIN_BAGGAGE_KEY, strings.Join(chain, ","))
+	if err != nil {
+		return ctx
+	}
+	bag, err := baggage.FromContext(ctx).SetMember(member)
+	if err != nil {
+		return ctx
+	}
+	return baggage.ContextWithBaggage(ctx, bag)
+}
+
+// StartSpan 以 *traceCtx 为父上下文创建 Span，使用 otel 全局的 TracerProvider。
+// 创建后 *traceCtx 替换为包含该 Span 的上下文，之后创建的 Span 和向下游传递的链路追踪上下文均以该 Span 为父级，
+// Span 结束时恢复为父上下文
+func StartSpan(traceCtx *context.Context, name string) Span {
+	parent := *traceCtx
+	ctx, span := otel.Tracer(TRACER_NAME).Start(parent, name)
+	*traceCtx = ctx
+	return &otelSpan{span: span, end: func() {
+		// Span 未按创建的逆序结束时，不覆盖之后创建的 Span
+		if *traceCtx == ctx {
+			*traceCtx = parent
+		}
+	}}
+}
+
+// InjectTraceContext 将链路追踪上下文序列化为 W3C 请求头，用于向下游传递，上下文不属于任何链路时返回nil
+func InjectTraceContext(ctx context.Context) map[string]string {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return nil
+	}
+	carrier := propagation.MapCarrier{}
+	tracePropagator.Inject(ctx, carrier)
+	return carrier
+}
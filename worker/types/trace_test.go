@@ -0,0 +1,127 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/garrickvan/event-matrix/core"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+)
+
+const testTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestExtractTraceContext(t *testing.T) {
+	ctx := NewMockRequestContext(nil, &core.Event{})
+	ctx.SetRequestHeader(TRACEPARENT_HEADER, testTraceParent)
+	ctx.SetCallChain([]string{"shop.v1.order.create", "shop.v1.stock.lock"})
+
+	traceCtx := ctx.TraceContext()
+	sc := trace.SpanContextFromContext(traceCtx)
+	if !sc.IsRemote() || sc.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("unexpected span context: %+v", sc)
+	}
+	chain := baggage.FromContext(traceCtx).Member(CALL_CHAIN_BAGGAGE_KEY).Value()
+	if chain != "shop.v1.order.create,shop.v1.stock.lock" {
+		t.Errorf("unexpected call chain baggage: %q", chain)
+	}
+
+	span := ctx.Span("lock-stock")
+	span.SetAttribute("sku", "1001")
+	span.RecordError(errors.New("out of stock"))
+	span.RecordError(nil)
+	span.End()
+
+	// 向下游传递时保持同一链路
+	headers := InjectTraceContext(traceCtx)
+	if !strings.Contains(headers[TRACEPARENT_HEADER], sc.TraceID().String()) {
+		t.Errorf("traceparent not propagated: %v", headers)
+	}
+	if !strings.Contains(headers[BAGGAGE_HEADER], CALL_CHAIN_BAGGAGE_KEY) {
+		t.Errorf("call chain baggage not propagated: %v", headers)
+	}
+	xd := IntranetXData{Type: W_T_W_EVENT_CALL}
+	xd.SetTraceHeaders(headers)
+	if got := ParseIntranetXData(xd.String()); got != xd || got.TraceHeader("TraceParent") != headers[TRACEPARENT_HEADER] {
+		t.Errorf("trace headers round trip failed: %+v", got)
+	}
+}
+
+func TestInjectTraceContextWithoutTrace(t *testing.T) {
+	ctx := NewMockRequestContext(nil, &core.Event{})
+	ctx.SetCallChain([]string{"shop.v1.order.create"})
+	if headers := InjectTraceContext(ctx.TraceContext()); headers != nil {
+		t.Errorf("request without traceparent should not propagate trace headers: %v", headers)
+	}
+	// 不属于任何链路时仍可安全创建 Span
+	ctx.Span("noop").End()
+}
+
+// testTracerProvider 为每个 Span 生成递增的 SpanID，用于验证父子关系
+type testTracerProvider struct {
+	embedded.TracerProvider
+	tracer *testTracer
+}
+
+func (p *testTracerProvider) Tracer(string, ...trace.TracerOption) trace.Tracer { return p.tracer }
+
+type testTracer struct {
+	embedded.Tracer
+	next byte
+}
+
+func (t *testTracer) Start(ctx context.Context, _ string, _ ...trace.SpanStartOption) (context.Context, trace.Span) {
+	t.next++
+	sc := trace.SpanContextFromContext(ctx).WithSpanID(trace.SpanID{7: t.next}).WithRemote(false)
+	ctx = trace.ContextWithSpanContext(ctx, sc)
+	return ctx, trace.SpanFromContext(ctx)
+}
+
+func TestSpanBecomesTraceParent(t *testing.T) {
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(&testTracerProvider{tracer: &testTracer{}})
+	defer otel.SetTracerProvider(prev)
+
+	ctx := NewMockRequestContext(nil, &core.Event{})
+	ctx.SetRequestHeader(TRACEPARENT_HEADER, testTraceParent)
+	spanIdOf := func() string {
+		return trace.SpanContextFromContext(ctx.TraceContext()).SpanID().String()
+	}
+	inbound := spanIdOf()
+
+	outer := ctx.Span("outer")
+	if spanIdOf() != "0000000000000001" {
+		t.Fatalf("Span 未成为当前 Span: %s", spanIdOf())
+	}
+	inner := ctx.Span("inner")
+	// 向下游传递的是当前未结束的 Span
+	if headers := InjectTraceContext(ctx.TraceContext()); !strings.Contains(headers[TRACEPARENT_HEADER], "0000000000000002") {
+		t.Fatalf("traceparent 应以当前 Span 为父级: %v", headers)
+	}
+	inner.End()
+	if spanIdOf() != "0000000000000001" {
+		t.Fatalf("子 Span 结束后应恢复父 Span: %s", spanIdOf())
+	}
+	outer.End()
+	if spanIdOf() != inbound {
+		t.Fatalf("Span 全部结束后应恢复请求的链路追踪上下文: %s", spanIdOf())
+	}
+}
@@ -16,7 +16,9 @@ package types
 
 import (
 	"crypto/md5"
+	"database/sql/driver"
 	"encoding/hex"
+	"fmt"
 	"strings"
 	"sync"
 
@@ -62,6 +64,10 @@ type Worker struct {
 	UpdatedAt int64 `json:"updatedAt"`
 	// 当前服务器的时区偏移量
 	UtcOffset int `json:"utcOffset"`
+	// 标签列表，用于分组和灰度路由，如 canary、region:us-east，数据库中以逗号分隔存储
+	Tags WorkerTags `json:"tags" gorm:"column:tags;type:text"`
+	// 实体别名列表，同一工作者可同时处理这些实体名的事件，如重命名前的旧实体名，不存储到数据库
+	AliasEntities []string `json:"aliasEntities,omitempty" gorm:"-"`
 
 	// 负载均衡权重，不存储到数据库，参与 JSON 序列化
 	LoadRate float64 `gorm:"-" json:"loadRate"`
@@ -76,6 +82,56 @@ type Worker struct {
 	taskExecutorMap *sync.Map `gorm:"-" json:"-"`
 }
 
+// WorkerTags 工作者标签列表，JSON 中为数组，数据库中以逗号分隔的字符串存储
+type WorkerTags []string
+
+// Value 实现 driver.Valuer 接口，将标签以逗号拼接后存储
+func (t WorkerTags) Value() (driver.Value, error) {
+	return strings.Join(t, constant.SPLIT_CHAR), nil
+}
+
+// Scan 实现 sql.Scanner 接口，将逗号分隔的字符串解析为标签列表
+func (t *WorkerTags) Scan(value interface{}) error {
+	var str string
+	switch v := value.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case string:
+		str = v
+	case []byte:
+		str = string(v)
+	default:
+		return fmt.Errorf("无法将 %T 转换为工作者标签", value)
+	}
+	*t = normalizeTags(strings.Split(str, constant.SPLIT_CHAR))
+	return nil
+}
+
+// normalizeTags 去除标签首尾空白、空标签和重复标签，标签中不能包含逗号
+func normalizeTags(tags []string) WorkerTags {
+	if len(tags) == 0 {
+		return nil
+	}
+	seen := make(map[string]struct{}, len(tags))
+	result := WorkerTags{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(strings.ReplaceAll(tag, constant.SPLIT_CHAR, ""))
+		if tag == "" {
+			continue
+		}
+		if _, has := seen[tag]; has {
+			continue
+		}
+		seen[tag] = struct{}{}
+		result = append(result, tag)
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
 // NewWorker 函数用于创建一个新的 Worker 实例
 //
 // 参数:
@@ -85,10 +141,11 @@ type Worker struct {
 // - entity: 实体信息
 // - cfgKey: 配置键
 // - rebalanceTime: 负载均衡时间间隔（秒），如果小于等于0则默认设置为3秒
+// - tags: 标签列表，可选，供网关做分组和灰度路由
 //
 // 返回值:
 // - *Worker: 返回新创建的 Worker 实例
-func NewWorker(project, versionLabel, context, entity, cfgKey string, rebalanceTime int) *Worker {
+func NewWorker(project, versionLabel, context, entity, cfgKey string, rebalanceTime int, tags ...string) *Worker {
 	if rebalanceTime <= 0 {
 		rebalanceTime = 3 // 默认负载均衡每3秒一次
 	}
@@ -99,6 +156,7 @@ func NewWorker(project, versionLabel, context, entity, cfgKey string, rebalanceT
 		Entity:            entity,
 		CfgKey:            cfgKey,
 		RebalanceTime:     rebalanceTime,
+		Tags:              normalizeTags(tags),
 		SyncSchema:        true,
 		customExecutorMap: &sync.Map{},
 		taskExecutorMap:   &sync.Map{},
@@ -199,7 +257,18 @@ func (w *Worker) FindTaskExecutor(name string) (WorkerTaskExecutor, bool) {
 	return nil, false
 }
 
-// BuildExecutors 构建并生成自定义执行器和任务执行器
+// HasTag 判断工作者是否带有指定标签
+func (w *Worker) HasTag(tag string) bool {
+	tag = strings.TrimSpace(tag)
+	for _, t := range w.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildExecutors 构建并生成自定义执行器和任务执行器，同时规范化标签
 //
 // 参数:
 //
@@ -212,6 +281,7 @@ func (w *Worker) FindTaskExecutor(name string) (WorkerTaskExecutor, bool) {
 // 说明:
 //
 //	该方法用于生成并设置自定义执行器（CustomExecutors）和任务执行器（TaskExecutors）。
+//	标签会去除空白、空值和重复项，保证注册到网关和存储的标签一致。
 //	首先检查自定义执行器映射（customExecutorMap）是否为空，如果为空，则将CustomExecutors设置为空字符串并返回。
 //	否则，遍历customExecutorMap并将键（执行器名称）添加到executors切片中，
 //	然后使用constant.SPLIT_CHAR将executors切片连接成字符串并赋值给CustomExecutors。
@@ -219,6 +289,7 @@ func (w *Worker) FindTaskExecutor(name string) (WorkerTaskExecutor, bool) {
 //	否则，遍历taskExecutorMap并将键（任务名称）添加到taskExecutors切片中，
 //	然后使用constant.SPLIT_CHAR将taskExecutors切片连接成字符串并赋值给TaskExecutors。
 func (w *Worker) BuildExecutors() {
+	w.Tags = normalizeTags(w.Tags)
 	// 生成customExecutors
 	if w.customExecutorMap == nil {
 		w.CustomExecutors = ""
@@ -256,7 +327,7 @@ func (w *Worker) IsIncomplete() bool {
 
 // GetVersionEntityLabel 返回包含项目、上下文、实体和版本标签的字符串
 // 形如 sys.user.avatar@0.1.0 的唯一标识
-// 格式为：项目名.上下文.实体@版本标签
+// 格式为：项目名.上下文.实体@版本标签，Tags 不参与拼接，灰度工作者与正式工作者的标识相同
 //
 // 参数：
 // - 无
@@ -268,6 +339,48 @@ func (w *Worker) GetVersionEntityLabel() string {
 	return strings.Join(parts, "")
 }
 
+// GetAliasEntities 返回去重后的实体别名，空白别名、与实体名相同的别名和重复别名会被忽略
+func (w *Worker) GetAliasEntities() []string {
+	if len(w.AliasEntities) == 0 {
+		return nil
+	}
+	seen := map[string]struct{}{w.Entity: {}}
+	aliases := []string{}
+	for _, alias := range w.AliasEntities {
+		alias = strings.TrimSpace(alias)
+		if alias == "" {
+			continue
+		}
+		if _, has := seen[alias]; has {
+			continue
+		}
+		seen[alias] = struct{}{}
+		aliases = append(aliases, alias)
+	}
+	return aliases
+}
+
+// GetAliasEntityLabels 返回实体别名对应的版本实体标识，项目、上下文和版本与工作者相同
+func (w *Worker) GetAliasEntityLabels() []string {
+	aliases := w.GetAliasEntities()
+	if len(aliases) == 0 {
+		return nil
+	}
+	labels := make([]string, 0, len(aliases))
+	for _, alias := range aliases {
+		parts := []string{w.Project, ".", w.Context, ".", alias, "@", w.VersionLabel}
+		labels = append(labels, strings.Join(parts, ""))
+	}
+	return labels
+}
+
+// WorkerAliases 工作者向网关注册实体别名的请求体
+type WorkerAliases struct {
+	WorkerId string   `json:"workerId"` // 工作者ID
+	Label    string   `json:"label"`    // 工作者自身的版本实体标识
+	Aliases  []string `json:"aliases"`  // 别名对应的版本实体标识
+}
+
 // BuildWorkerFromVersionEntityLabel 根据给定的版本实体标签生成Worker对象
 //
 // 参数：
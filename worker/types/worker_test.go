@@ -0,0 +1,111 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/garrickvan/event-matrix/utils/jsonx"
+)
+
+func TestWorkerTags(t *testing.T) {
+	w := NewWorker("shop", "1.0.0", "order", "item", "", 0, "canary", " region:us-east ", "", "canary")
+	if !reflect.DeepEqual(w.Tags, WorkerTags{"canary", "region:us-east"}) {
+		t.Fatalf("标签应去除空白和重复项: %v", w.Tags)
+	}
+	if !w.HasTag("canary") || !w.HasTag("region:us-east") || w.HasTag("stable") {
+		t.Errorf("HasTag 判断错误: %v", w.Tags)
+	}
+	if label := w.GetVersionEntityLabel(); label != "shop.order.item@1.0.0" {
+		t.Errorf("标签不应参与实体标识: %s", label)
+	}
+
+	// JSON 序列化为数组，供网关注册使用
+	str, err := jsonx.MarshalToStr(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := Worker{}
+	if err := jsonx.UnmarshalFromStr(str, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded.Tags, w.Tags) {
+		t.Errorf("JSON 反序列化后标签不一致: %v", decoded.Tags)
+	}
+
+	w.Tags = append(w.Tags, "canary", "a,b")
+	w.BuildExecutors()
+	if !reflect.DeepEqual(w.Tags, WorkerTags{"canary", "region:us-east", "ab"}) {
+		t.Errorf("BuildExecutors 应规范化标签: %v", w.Tags)
+	}
+}
+
+func TestGetAliasEntityLabels(t *testing.T) {
+	w := NewWorker("shop", "1.0.0", "order", "item", "", 0)
+	if labels := w.GetAliasEntityLabels(); labels != nil {
+		t.Fatalf("未设置别名时应返回 nil: %v", labels)
+	}
+	w.AliasEntities = []string{"goods", "", " item ", "product", "goods"}
+	want := []string{"shop.order.goods@1.0.0", "shop.order.product@1.0.0"}
+	if labels := w.GetAliasEntityLabels(); !reflect.DeepEqual(labels, want) {
+		t.Errorf("别名标识错误: %v", labels)
+	}
+}
+
+func TestWorkerTagsStorage(t *testing.T) {
+	value, err := WorkerTags{"canary", "region:us-east"}.Value()
+	if err != nil || value != "canary,region:us-east" {
+		t.Fatalf("标签应以逗号分隔存储: %v %v", value, err)
+	}
+
+	ws := NewMockWorkerServer(nil)
+	defer ws.Stop()
+	db := ws.Repo().Use("worker_tags")
+	if err := db.AutoMigrate(&Worker{}); err != nil {
+		t.Fatal(err)
+	}
+	w := NewWorker("shop", "1.0.0", "order", "item", "", 0, "canary", "beta")
+	w.GenID()
+	if err := db.Create(w).Error; err != nil {
+		t.Fatal(err)
+	}
+	var raw string
+	if err := db.Raw("SELECT tags FROM workers WHERE id = ?", w.ID).Scan(&raw).Error; err != nil {
+		t.Fatal(err)
+	}
+	if raw != "canary,beta" {
+		t.Errorf("数据库中的标签格式错误: %q", raw)
+	}
+	loaded := Worker{}
+	if err := db.First(&loaded, "id = ?", w.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.Tags, WorkerTags{"canary", "beta"}) {
+		t.Errorf("从数据库读取的标签错误: %v", loaded.Tags)
+	}
+
+	empty := NewWorker("shop", "1.0.0", "order", "other", "", 0)
+	empty.GenID()
+	if err := db.Create(empty).Error; err != nil {
+		t.Fatal(err)
+	}
+	loaded = Worker{}
+	if err := db.First(&loaded, "id = ?", empty.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Tags) != 0 {
+		t.Errorf("未设置标签时应为空: %v", loaded.Tags)
+	}
+}
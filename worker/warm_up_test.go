@@ -0,0 +1,47 @@
+// Copyright 2025 eventmatrix.cn
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package worker
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitWarmUp(t *testing.T) {
+	// 预热按时完成时返回预热结果
+	timedOut, err := waitWarmUp(func() error { return errors.New("partial") }, time.Second)
+	if timedOut || err == nil {
+		t.Fatalf("expected warm-up error without timeout, got %v %v", timedOut, err)
+	}
+
+	// 网关不可达导致预热阻塞时，超时后继续启动
+	release := make(chan struct{})
+	defer close(release)
+	start := time.Now()
+	timedOut, err = waitWarmUp(func() error { <-release; return nil }, 50*time.Millisecond)
+	if !timedOut || err != nil {
+		t.Fatalf("expected timeout, got %v %v", timedOut, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("warm-up wait should stop at the deadline, took %v", elapsed)
+	}
+
+	// 不限制超时时等待预热完成
+	timedOut, err = waitWarmUp(func() error { time.Sleep(20 * time.Millisecond); return nil }, 0)
+	if timedOut || err != nil {
+		t.Fatalf("expected warm-up to finish, got %v %v", timedOut, err)
+	}
+}
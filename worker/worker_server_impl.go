@@ -25,6 +25,7 @@ import (
 	"github.com/garrickvan/event-matrix/core"
 	"github.com/garrickvan/event-matrix/utils"
 	"github.com/garrickvan/event-matrix/utils/logx"
+	"github.com/garrickvan/event-matrix/worker/common"
 	"github.com/garrickvan/event-matrix/worker/common/controller"
 	"github.com/garrickvan/event-matrix/worker/intranet/dispatcher"
 	"github.com/garrickvan/event-matrix/worker/types"
@@ -32,22 +33,22 @@ import (
 
 // ServerId 返回服务器ID
 func (ws *TwoWayWorkerServer) ServerId() string {
-	return ws.cfg.ServerId
+	return ws.Cfg().ServerId
 }
 
 // IntranetSecret 返回内域密钥
 func (ws *TwoWayWorkerServer) IntranetSecret() string {
-	return ws.cfg.IntranetSecret
+	return ws.Cfg().IntranetSecret
 }
 
 // IntranetSecretAlgor 返回内域密钥算法
 func (ws *TwoWayWorkerServer) IntranetSecretAlgor() string {
-	return ws.cfg.IntranetSecretAlgor
+	return ws.Cfg().IntranetSecretAlgor
 }
 
 // GatewayIntranetEndpoint 返回网关内域端点
 func (ws *TwoWayWorkerServer) GatewayIntranetEndpoint() string {
-	return ws.cfg.GatewayIntranetEndpoint
+	return ws.Cfg().GatewayIntranetEndpoint
 }
 
 // SharedConfigure 获取共享配置
@@ -63,11 +64,40 @@ func (ws *TwoWayWorkerServer) SharedConfigure(sid string) *core.SharedConfigure
 	return cfgs[sid]
 }
 
+// JwtSecret 返回 JwtCfgKey 对应共享配置中的JWT签名密钥，未配置时返回nil
+func (ws *TwoWayWorkerServer) JwtSecret() []byte {
+	if ws.Cfg().JwtCfgKey == "" {
+		return nil
+	}
+	return types.JwtSecretFromSharedCfg(ws.SharedConfigure(ws.Cfg().JwtCfgKey))
+}
+
 // GetSharedConfigureChangeHandler 获取共享配置变更处理器
 func (ws *TwoWayWorkerServer) GetSharedConfigureChangeHandler() types.OnSharedConfigureChangeFunc {
 	return ws.onSharedConfigureChange
 }
 
+// applySharedConfigureChange 更新本地缓存的共享配置，并通知关注配置变更的插件
+func (ws *TwoWayWorkerServer) applySharedConfigureChange(_ types.WorkerServer, cfg *core.SharedConfigure) error {
+	if cfg == nil || cfg.Key == "" {
+		return errors.New("共享配置为空")
+	}
+	ws.sharedConfigures.Store(cfg.Key, cfg)
+	var errs []error
+	notified := map[types.PluginWorker]bool{}
+	for _, plugin := range ws.plugins {
+		watcher, ok := plugin.(types.SharedConfigureWatcher)
+		if !ok || notified[plugin] {
+			continue
+		}
+		notified[plugin] = true
+		if err := watcher.OnSharedConfigureChange(cfg); err != nil {
+			errs = append(errs, fmt.Errorf("%T: %w", plugin, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // Repo 返回存储库实例
 func (ws *TwoWayWorkerServer) Repo() types.Repository {
 	return ws.repo
@@ -83,8 +113,24 @@ func (ws *TwoWayWorkerServer) DomainCache() types.DomainCache {
 	return ws.domainCache
 }
 
+// DistributedCache 返回分布式缓存实例，未安装缓存插件时返回nil
+func (ws *TwoWayWorkerServer) DistributedCache() types.DistributedCache {
+	return ws.distributedCache
+}
+
+// SetDistributedCache 设置分布式缓存实例，通常由缓存插件在安装时调用
+func (ws *TwoWayWorkerServer) SetDistributedCache(c types.DistributedCache) {
+	ws.distributedCache = c
+}
+
 // RegisterPlugin 注册插件
+// 插件依赖的事件类型尚未注册时记录警告，需要保证安装顺序时使用 SetupPlugins
 func (ws *TwoWayWorkerServer) RegisterPlugin(plugin types.PluginWorker) {
+	for _, dep := range plugin.Dependencies() {
+		if _, has := ws.plugins[dep]; !has {
+			logx.Log().Warn(fmt.Sprintf("插件 %T 依赖的事件类型 %d 尚未注册", plugin, dep))
+		}
+	}
 	pluginCodes := plugin.ReceiveCodes()
 	for _, code := range pluginCodes {
 		ws.plugins[code] = plugin
@@ -137,6 +183,8 @@ func (ws *TwoWayWorkerServer) RegisterWorker(w *types.Worker) error {
 		ws.remvoeFailedWorker(w.ID)
 		dispatcher.ReportConfigUsedBy(w.CfgKey, w.ID)
 		dispatcher.ReportConfigUsedBy(ws.cfgKey, w.ID)
+		ws.registerAliasesToGateway(w)
+		ws.notifyWorkerRegistered(w)
 	} else {
 		ws.addFailedWorker(w)
 		return errors.New(resp)
@@ -144,6 +192,28 @@ func (ws *TwoWayWorkerServer) RegisterWorker(w *types.Worker) error {
 	return nil
 }
 
+// OnWorkerRegistered 注册工作者注册成功回调，每次 RegisterWorker 成功后按注册顺序同步调用
+func (ws *TwoWayWorkerServer) OnWorkerRegistered(hook func(w *types.Worker)) {
+	if hook == nil {
+		return
+	}
+	ws.registeredHooks = append(ws.registeredHooks, hook)
+}
+
+// notifyWorkerRegistered 调用工作者注册成功回调，回调异常只记录日志，不影响工作者注册和其他回调
+func (ws *TwoWayWorkerServer) notifyWorkerRegistered(w *types.Worker) {
+	for _, hook := range ws.registeredHooks {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					logx.Error(fmt.Sprintf("工作者注册回调异常: %s, %v", w.ID, r))
+				}
+			}()
+			hook(w)
+		}()
+	}
+}
+
 // hasWorkerId 判断是否已存在该工作者ID
 func (ws *TwoWayWorkerServer) hasWorkerId(workerId string) bool {
 	_, has := ws.workerIds[workerId]
@@ -152,6 +222,8 @@ func (ws *TwoWayWorkerServer) hasWorkerId(workerId string) bool {
 
 // addFailedWorker 添加失败的工作者
 func (ws *TwoWayWorkerServer) addFailedWorker(w *types.Worker) {
+	ws.failedWorkersMu.Lock()
+	defer ws.failedWorkersMu.Unlock()
 	if _, has := ws.failedWorkers[w.ID]; has {
 		return
 	}
@@ -160,18 +232,31 @@ func (ws *TwoWayWorkerServer) addFailedWorker(w *types.Worker) {
 
 // remvoeFailedWorker 移除失败的工作者
 func (ws *TwoWayWorkerServer) remvoeFailedWorker(workerID string) {
+	ws.failedWorkersMu.Lock()
+	defer ws.failedWorkersMu.Unlock()
 	if _, has := ws.failedWorkers[workerID]; has {
 		delete(ws.failedWorkers, workerID)
 	}
 }
 
+// failedWorkersSnapshot 返回失败工作者的副本，遍历时无需持有锁
+func (ws *TwoWayWorkerServer) failedWorkersSnapshot() []*types.Worker {
+	ws.failedWorkersMu.Lock()
+	defer ws.failedWorkersMu.Unlock()
+	workers := make([]*types.Worker, 0, len(ws.failedWorkers))
+	for _, w := range ws.failedWorkers {
+		workers = append(workers, w)
+	}
+	return workers
+}
+
 // startFailedWorkersDaemon 启动失败工作者守护进程
 func (ws *TwoWayWorkerServer) startFailedWorkersDaemon() {
 	go func() {
 		// 每隔5秒重新注册失败的worker
 		for {
 			time.Sleep(5 * time.Second)
-			for _, w := range ws.failedWorkers {
+			for _, w := range ws.failedWorkersSnapshot() {
 				err := ws.RegisterWorker(w)
 				if err != nil {
 					logx.Log().Error(err.Error())
@@ -181,20 +266,58 @@ func (ws *TwoWayWorkerServer) startFailedWorkersDaemon() {
 	}()
 }
 
-// addWorker 添加工作者
+// addWorker 添加工作者，实体别名与实体名映射到同一个工作者
 func (ws *TwoWayWorkerServer) addWorker(worker *types.Worker) {
 	ws.workerIds[worker.ID] = true
 	ws.entityMapToWorkers[worker.GetVersionEntityLabel()] = worker
+	for _, label := range worker.GetAliasEntityLabels() {
+		if exist, has := ws.entityMapToWorkers[label]; has && exist != worker {
+			logx.Warn("实体别名已被其他工作者占用，将被覆盖: " + label + " " + exist.ID)
+		}
+		ws.entityMapToWorkers[label] = worker
+	}
+}
+
+// registeredWorkers 返回已注册的工作者，别名映射不会重复返回同一个工作者
+func (ws *TwoWayWorkerServer) registeredWorkers() []*types.Worker {
+	workers := make([]*types.Worker, 0, len(ws.entityMapToWorkers))
+	for label, w := range ws.entityMapToWorkers {
+		if label == w.GetVersionEntityLabel() {
+			workers = append(workers, w)
+		}
+	}
+	return workers
+}
+
+// registerAliasesToGateway 向网关注册工作者的实体别名，失败只记录日志，不影响工作者注册
+func (ws *TwoWayWorkerServer) registerAliasesToGateway(w *types.Worker) {
+	aliases := w.GetAliasEntityLabels()
+	if len(aliases) == 0 {
+		return
+	}
+	params := types.WorkerAliases{
+		WorkerId: w.ID,
+		Label:    w.GetVersionEntityLabel(),
+		Aliases:  aliases,
+	}
+	resp, err := dispatcher.Event(ws.Cfg().GatewayIntranetEndpoint, types.W_T_G_REGISTER_ALIASES, params, nil)
+	if err != nil {
+		logx.Error("注册实体别名到网关失败: " + w.ID + " " + err.Error())
+		return
+	}
+	if resp.Status() != http.StatusOK {
+		logx.Error("注册实体别名到网关失败: " + w.ID + " " + resp.TemporaryData())
+	}
 }
 
 // rigsterWorkerToGateway 注册工作者到网关
 func (ws *TwoWayWorkerServer) rigsterWorkerToGateway(w *types.Worker) (string, error) {
 	w.ServerId = ws.ServerId()
-	w.PublicEndpoint = fmt.Sprintf("%s:%d", ws.cfg.PublicHost, ws.cfg.PublicPort)
-	w.IntranetEndpoint = fmt.Sprintf("%s:%d", ws.cfg.IntranetHost, ws.cfg.IntranetPort)
-	w.HeartbeatGap = ws.cfg.HeartbeatReportGap
+	w.PublicEndpoint = fmt.Sprintf("%s:%d", ws.Cfg().PublicHost, ws.Cfg().PublicPort)
+	w.IntranetEndpoint = fmt.Sprintf("%s:%d", ws.Cfg().IntranetHost, ws.Cfg().IntranetPort)
+	w.HeartbeatGap = ws.Cfg().HeartbeatReportGap
 	w.UtcOffset = utils.GetCurrentTimezoneOffset()
-	mode := strings.ToUpper(ws.cfg.WorkMode)
+	mode := strings.ToUpper(ws.Cfg().WorkMode)
 	if mode == "C" || mode == "COMMAND" {
 		w.Mode = constant.COMMAND_MODE
 	} else if mode == "Q" || mode == "QUERY" {
@@ -207,7 +330,8 @@ func (ws *TwoWayWorkerServer) rigsterWorkerToGateway(w *types.Worker) (string, e
 	if w.HeartbeatGap < 3 {
 		w.HeartbeatGap = 30 // 不设就默认30秒
 	}
-	resp, err := dispatcher.Event(ws.cfg.GatewayIntranetEndpoint, types.W_T_G_REGISTER, w, nil)
+	// 注册信息包含执行器列表和标签，网关据此做分组和灰度路由
+	resp, err := dispatcher.Event(ws.Cfg().GatewayIntranetEndpoint, types.W_T_G_REGISTER, w, nil)
 	if err != nil || resp.Status() != http.StatusOK {
 		return "", err
 	}
@@ -222,6 +346,19 @@ func (ws *TwoWayWorkerServer) FindPlugin(pluginType types.INTRANET_EVENT_TYPE) (
 	return nil, false
 }
 
+// PluginsHealth 返回所有已注册插件的健康状态
+func (ws *TwoWayWorkerServer) PluginsHealth() map[string]types.PluginHealth {
+	result := make(map[string]types.PluginHealth)
+	for _, plugin := range ws.plugins {
+		name := fmt.Sprintf("%T", plugin)
+		if _, checked := result[name]; checked {
+			continue
+		}
+		result[name] = plugin.HealthCheck()
+	}
+	return result
+}
+
 // FindWorkerExecutor 查找工作者执行器
 func (ws *TwoWayWorkerServer) FindWorkerExecutor(name string) (types.WorkerExecutor, bool) {
 	if executor, has := ws.routers[name]; has {
@@ -248,7 +385,12 @@ func (ws *TwoWayWorkerServer) Filters() []types.Filter {
 	return ws.filters
 }
 
-// setupRouter 设置工作者路由
+// Middlewares 返回执行器中间件列表
+func (ws *TwoWayWorkerServer) Middlewares() []types.WorkerMiddleware {
+	return ws.middlewares
+}
+
+// setupRouter 设置工作者路由，实体别名使用与实体名相同的执行器
 func (ws *TwoWayWorkerServer) setupRouter(w *types.Worker) {
 	events := ws.domainCache.EntityEvents(types.PathToEntityFromWorker(w))
 	if len(events) < 1 && w.VersionLabel != constant.INITIAL_VERSION {
@@ -256,55 +398,70 @@ func (ws *TwoWayWorkerServer) setupRouter(w *types.Worker) {
 		logx.Debug("没有找到事件: " + w.Project + "." + w.Context + "." + w.Entity + "@" + w.VersionLabel)
 		return
 	}
-	for _, event := range events {
-		var builder strings.Builder
-		builder.Write([]byte(w.Project))
-		builder.Write([]byte("."))
-		builder.Write([]byte(w.Context))
-		builder.Write([]byte("."))
-		builder.Write([]byte(w.Entity))
-		builder.Write([]byte("->"))
-		builder.Write([]byte(event.Code))
-		builder.Write([]byte("@"))
-		builder.Write([]byte(w.VersionLabel))
-		url := builder.String()
-		if event.ExecutorType == constant.BUILD_IN_EXECUTOR {
-			switch event.Executor {
-			case "query":
-				ws.routers[url] = controller.QueryExecutor
-			case "create":
-				ws.routers[url] = controller.CreateExecutor
-			case "update":
-				ws.routers[url] = controller.UpdateExecutor
-			case "delete":
-				ws.routers[url] = controller.DeleteExecutor
-			case "restore":
-				ws.routers[url] = controller.RestoreExecutor
-			case "sql":
-				ws.routers[url] = controller.SqlExecutor
-			default:
-				logx.Log().Warn("没有找到内置执行器: " + event.Executor)
-			}
-		} else if event.ExecutorType == constant.CUSTOM_EXECUTOR {
-			fnz, found := w.FindCustomExecutor(event.Executor)
-			if !found {
-				logx.Log().Error("没有找到自定义执行器: " + event.Executor)
-				continue
-			}
-			ws.routers[url] = fnz
-		} else if event.ExecutorType == constant.TASK_EXECUTOR {
-			fnz, found := w.FindTaskExecutor(event.Executor)
-			if !found {
-				logx.Log().Error("没有找到自定义执行器: " + event.Executor)
-				continue
-			}
-			ws.tasks[url] = fnz
-		} else {
-			logx.Log().Error("没有找到执行器: " + event.Executor)
+	entities := append([]string{w.Entity}, w.GetAliasEntities()...)
+	for _, entity := range entities {
+		for _, event := range events {
+			ws.setupEventRouter(w, entity, event)
 		}
 	}
 }
 
+// setupEventRouter 按实体名设置单个事件的路由
+func (ws *TwoWayWorkerServer) setupEventRouter(w *types.Worker, entity string, event core.EntityEvent) {
+	var builder strings.Builder
+	builder.Write([]byte(w.Project))
+	builder.Write([]byte("."))
+	builder.Write([]byte(w.Context))
+	builder.Write([]byte("."))
+	builder.Write([]byte(entity))
+	builder.Write([]byte("->"))
+	builder.Write([]byte(event.Code))
+	builder.Write([]byte("@"))
+	builder.Write([]byte(w.VersionLabel))
+	url := builder.String()
+	if event.ExecutorType == constant.BUILD_IN_EXECUTOR {
+		switch event.Executor {
+		case "query":
+			ws.routers[url] = ws.withEventHooks(controller.QueryExecutor)
+		case "create":
+			ws.routers[url] = ws.withEventHooks(controller.CreateExecutor)
+		case "bulk_create":
+			ws.routers[url] = ws.withEventHooks(controller.BulkCreateExecutor)
+		case "update":
+			ws.routers[url] = ws.withEventHooks(controller.UpdateExecutor)
+		case "upsert":
+			ws.routers[url] = ws.withEventHooks(controller.UpsertExecutor)
+		case "delete":
+			ws.routers[url] = ws.withEventHooks(controller.DeleteExecutor)
+		case "restore":
+			ws.routers[url] = ws.withEventHooks(controller.RestoreExecutor)
+		case "sql":
+			ws.routers[url] = ws.withEventHooks(controller.SqlExecutor)
+		case "aggregate":
+			ws.routers[url] = ws.withEventHooks(controller.AggregateExecutor)
+		default:
+			logx.Log().Warn("没有找到内置执行器: " + event.Executor)
+		}
+	} else if event.ExecutorType == constant.CUSTOM_EXECUTOR {
+		fnz, found := w.FindCustomExecutor(event.Executor)
+		if !found {
+			logx.Log().Error("没有找到自定义执行器: " + event.Executor)
+			return
+		}
+		// 自定义执行器按事件配置的超时时间包装，超时返回 504
+		ws.routers[url] = common.WithTimeout(fnz, common.ExecutorTimeout(&event))
+	} else if event.ExecutorType == constant.TASK_EXECUTOR {
+		fnz, found := w.FindTaskExecutor(event.Executor)
+		if !found {
+			logx.Log().Error("没有找到自定义执行器: " + event.Executor)
+			return
+		}
+		ws.tasks[url] = fnz
+	} else {
+		logx.Log().Error("没有找到执行器: " + event.Executor)
+	}
+}
+
 // RegisterInterceptor 注册拦截器
 func (ws *TwoWayWorkerServer) RegisterInterceptor(interceptor types.Intercept) {
 	ws.interceptors = append(ws.interceptors, interceptor)
@@ -315,6 +472,51 @@ func (ws *TwoWayWorkerServer) RegisterFilter(filter types.Filter) {
 	ws.filters = append(ws.filters, filter)
 }
 
+// RegisterMiddleware 注册执行器中间件，公共入口和内域入口的执行器调用都会经过中间件链，需在服务启动前调用
+func (ws *TwoWayWorkerServer) RegisterMiddleware(mw types.WorkerMiddleware) {
+	if mw == nil {
+		return
+	}
+	ws.middlewares = append(ws.middlewares, mw)
+}
+
+// RegisterEventProcessedHook 注册事件处理完成回调，用于推送自定义监控指标
+func (ws *TwoWayWorkerServer) RegisterEventProcessedHook(hook types.EventProcessedHook) {
+	ws.eventHooks = append(ws.eventHooks, hook)
+}
+
+// EventProcessedHooks 返回所有事件处理完成回调
+func (ws *TwoWayWorkerServer) EventProcessedHooks() []types.EventProcessedHook {
+	return ws.eventHooks
+}
+
+// RegisterEventFilter 注册跨实体事件订阅，源事件在内域执行成功后调用处理方法，需在服务启动前调用
+func (ws *TwoWayWorkerServer) RegisterEventFilter(filter core.EventFilter, handler types.EventFilterHandler) {
+	if handler == nil {
+		return
+	}
+	ws.eventFilters = append(ws.eventFilters, types.EventFilterEntry{Filter: filter, Handler: handler})
+}
+
+// EventFilters 返回所有跨实体事件订阅
+func (ws *TwoWayWorkerServer) EventFilters() []types.EventFilterEntry {
+	return ws.eventFilters
+}
+
+// ExportSwaggerSpec 导出 OpenAPI 3.0 文档，workerID 为空时导出全部工作者
+func (ws *TwoWayWorkerServer) ExportSwaggerSpec(workerID string) ([]byte, error) {
+	workers := make([]*types.Worker, 0, len(ws.entityMapToWorkers))
+	for _, w := range ws.registeredWorkers() {
+		if workerID == "" || w.ID == workerID {
+			workers = append(workers, w)
+		}
+	}
+	if workerID != "" && len(workers) == 0 {
+		return nil, errors.New("工作者不存在: " + workerID)
+	}
+	return types.BuildOpenAPISpec(ws.Cfg().ServerId, ws.Cfg().Version, ws.domainCache, workers)
+}
+
 // HasWorker 判断是否存在指定ID的工作者
 func (ws *TwoWayWorkerServer) HasWorker(workerId string) bool {
 	_, exists := ws.workerIds[workerId]
@@ -16,6 +16,9 @@ package worker
 
 import (
 	"testing"
+
+	"github.com/garrickvan/event-matrix/serverx/gnetx"
+	"github.com/garrickvan/event-matrix/worker/types"
 )
 
 func TestGetTag(t *testing.T) {
@@ -43,3 +46,25 @@ func TestInitWorkerServer(t *testing.T) {
 	}
 	_ = NewTwoWayWorkerServer(s)
 }
+
+func TestDelayIntranetReadiness(t *testing.T) {
+	iSvr := gnetx.NewIntranetServer("readiness-test", 0, "", "NONE", nil, nil)
+	ws := &TwoWayWorkerServer{intranet: iSvr}
+	ws.cfg.Store(&types.WorkerServerConfig{ReadinessProbeDelay: 1})
+	ws.delayIntranetReadiness()
+	if iSvr.Ready() {
+		t.Fatal("预热完成后延迟期内不应就绪")
+	}
+
+	ws.Cfg().ReadinessProbeDelay = -1
+	ws.delayIntranetReadiness()
+	if !iSvr.Ready() {
+		t.Fatal("延迟小于0时应立即就绪")
+	}
+
+	cfg := &types.WorkerServerConfig{}
+	types.PatchWorkerServerConfig(cfg)
+	if cfg.ReadinessProbeDelay != 0 {
+		t.Errorf("默认不应延迟就绪，实际 %d", cfg.ReadinessProbeDelay)
+	}
+}